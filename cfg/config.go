@@ -1,9 +1,14 @@
 package cfg
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -50,13 +55,70 @@ func (r RConfig) RCluster() bool {
 }
 
 type Config struct {
-	AppEnv   string `json:"APP_ENV"`
-	BasePath string `json:"BASE_PATH"`
+	AppEnv           string `json:"APP_ENV"`
+	BasePath         string `json:"BASE_PATH"`
+	EventFormat      string `json:"EVENT_FORMAT"`       // json, msgpack hoặc protobuf
+	PIIMasterKey     string `json:"PII_MASTER_KEY"`     // base64 của khóa 32 byte, để trống sẽ không mã hóa dữ liệu cá nhân
+	IDGenerator      string `json:"ID_GENERATOR"`       // uuid, uuidv7 hoặc ulid
+	ETARulesFile     string `json:"ETA_RULES_FILE"`     // file JSON ghi đè quy tắc tính ETA mặc định
+	PricingRulesFile string `json:"PRICING_RULES_FILE"` // file JSON ghi đè bảng phí vận chuyển mặc định
+	SLAInterval      string `json:"SLA_CHECK_INTERVAL"`
+	GeoIPFile        string `json:"GEOIP_DB_FILE"`   // file MaxMind GeoLite2/GeoIP2 City (.mmdb), để trống chỉ dùng bảng thành phố đi kèm
+	TrustedProxies   string `json:"TRUSTED_PROXIES"` // IP hoặc CIDR của reverse proxy, cách nhau bởi dấu phẩy
 	DB
 	RConfig
 	Server
 }
 
+// MasterKey giải mã PII_MASTER_KEY, trả về nil nếu chưa cấu hình
+func (c Config) MasterKey() ([]byte, error) {
+	if c.PIIMasterKey == "" {
+		return nil, nil
+	}
+	return base64.StdEncoding.DecodeString(c.PIIMasterKey)
+}
+
+// SLACheckInterval là chu kỳ kiểm tra đơn hàng quá hạn, mặc định 5 phút, 0 để tắt
+func (c Config) SLACheckInterval() time.Duration {
+	if c.SLAInterval == "" {
+		return 5 * time.Minute
+	}
+	interval, err := time.ParseDuration(c.SLAInterval)
+	if err != nil || interval < 0 {
+		return 5 * time.Minute
+	}
+	return interval
+}
+
+// TrustedProxyNets phân tích TRUSTED_PROXIES, IP đơn được coi là mạng chỉ gồm IP đó
+func (c Config) TrustedProxyNets() ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(c.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("TRUSTED_PROXIES không hợp lệ: %s", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES không hợp lệ: %w", err)
+		}
+		nets = append(nets, network)
+	}
+	return nets, nil
+}
+
 type Server struct {
 	Port string `json:"SERVER_PORT"`
 }
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/quyenle-97/init/cfg"
+	"github.com/quyenle-97/init/internal/kit/services"
 	"github.com/quyenle-97/init/internal/models"
 	"github.com/quyenle-97/init/migrations"
 	"github.com/quyenle-97/init/pkgs/rdbms"
+	"github.com/quyenle-97/init/server"
+	"github.com/uptrace/bun"
 	"os"
 )
 
@@ -35,5 +39,102 @@ func main() {
 	case "migrate:reset":
 		migration.MigrateReset(lists)
 		fmt.Printf("Migrate reset successfully !!! \n")
+	case "import":
+		// import <file> [csv|jsonl]
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: cmd import <file> [csv|jsonl]")
+			os.Exit(2)
+		}
+		if err := importOrders(c, db, os.Args[2:]); err != nil {
+			fmt.Printf("Import failed: %v\n", err)
+			os.Exit(1)
+		}
+	case "sla:check":
+		s, err := server.NewServices(db, c, nil)
+		if err != nil {
+			panic(err)
+		}
+		breached, err := s.SLA.CheckOverdue(context.Background())
+		if err != nil {
+			fmt.Printf("SLA check failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("SLA check finished: %d orders breached !!! \n", breached)
+	case "tracking:rebuild":
+		s, err := server.NewServices(db, c, nil)
+		if err != nil {
+			panic(err)
+		}
+		replayed, err := s.Tracking.RebuildTracking(context.Background())
+		if err != nil {
+			fmt.Printf("Tracking rebuild failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Tracking rebuild finished: %d events replayed !!! \n", replayed)
+	case "analytics:rebuild":
+		s, err := server.NewServices(db, c, nil)
+		if err != nil {
+			panic(err)
+		}
+		replayed, err := s.Statistics.RebuildAnalytics(context.Background())
+		if err != nil {
+			fmt.Printf("Analytics rebuild failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Analytics rebuild finished: %d events replayed !!! \n", replayed)
+	case "cod:rebuild":
+		s, err := server.NewServices(db, c, nil)
+		if err != nil {
+			panic(err)
+		}
+		replayed, err := s.Payment.RebuildReconciliation(context.Background())
+		if err != nil {
+			fmt.Printf("COD reconciliation rebuild failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("COD reconciliation rebuild finished: %d events replayed !!! \n", replayed)
 	}
 }
+
+// importOrders nhập đơn hàng từ file và in kết quả từng dòng
+func importOrders(c cfg.Config, db *bun.DB, args []string) error {
+	path := args[0]
+	format := services.ImportFormatFromPath(path)
+	if len(args) > 1 {
+		format = args[1]
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := services.ParseImport(file, format)
+	if err != nil {
+		return err
+	}
+
+	s, err := server.NewServices(db, c, nil)
+	if err != nil {
+		return err
+	}
+
+	results, err := s.Import.ImportOrders(context.Background(), rows)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			fmt.Printf("%d\t%s\tFAILED\t%s\n", result.Line, result.Reference, result.Error)
+			continue
+		}
+		fmt.Printf("%d\t%s\tCREATED\t%s\t%s\n", result.Line, result.Reference, result.OrderID, result.TrackingNumber)
+	}
+	fmt.Printf("Import finished: %d created, %d failed !!! \n", len(results)-failed, failed)
+
+	return nil
+}
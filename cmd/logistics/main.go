@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/quyenle-97/init/cfg"
+	"github.com/quyenle-97/init/internal/models"
 	"github.com/quyenle-97/init/migrations"
 	"github.com/quyenle-97/init/pkgs/log"
 	"github.com/quyenle-97/init/pkgs/rdbms"
@@ -34,6 +35,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	models.Init(db)
 
 	// Chạy migrations
 	migration := rdbms.NewMigrationTool(db)
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+// AssignToDriver phân công đơn hàng cho tài xế và phương tiện tài xế đang sử dụng.
+// loadKg là tổng khối lượng các đơn hàng chưa hoàn thành đã phân công cho tài xế,
+// cộng thêm khối lượng của đơn hàng này không được vượt quá tải trọng của phương tiện.
+func (o *Order) AssignToDriver(driver *Driver, vehicle *Vehicle, loadKg decimal.Decimal) error {
+	if o.Status == OrderStatusDelivered || o.Status == OrderStatusCancelled {
+		return errors.New("không thể phân công đơn hàng đã hoàn thành hoặc đã hủy")
+	}
+	if o.DriverID != "" {
+		return fmt.Errorf("đơn hàng đã được phân công cho tài xế %s", o.DriverID)
+	}
+	if driver == nil || driver.Status != DriverStatusActive {
+		return errors.New("tài xế không tồn tại hoặc không còn hoạt động")
+	}
+	if driver.VehicleID == "" {
+		return fmt.Errorf("tài xế %s chưa được gán phương tiện", driver.ID)
+	}
+	if vehicle == nil || vehicle.ID != driver.VehicleID {
+		return fmt.Errorf("không tìm thấy phương tiện %s của tài xế", driver.VehicleID)
+	}
+	if vehicle.Status != VehicleStatusActive {
+		return fmt.Errorf("phương tiện %s đã ngừng sử dụng", vehicle.ID)
+	}
+	if total := loadKg.Add(o.Totals.TotalWeight); total.GreaterThan(vehicle.CapacityKg) {
+		return fmt.Errorf("vượt tải trọng phương tiện: %s kg đã nhận cộng %s kg của đơn hàng lớn hơn %s kg",
+			loadKg, o.Totals.TotalWeight, vehicle.CapacityKg)
+	}
+
+	o.raise(NewOrderAssignedToDriverEvent(o.newBaseEvent(OrderAssignedToDriverType), driver.ID, vehicle.ID))
+
+	return nil
+}
+
+// Unassign hủy phân công tài xế của đơn hàng chưa được đưa đi giao
+func (o *Order) Unassign(reason string) error {
+	if o.DriverID == "" {
+		return errors.New("đơn hàng chưa được phân công")
+	}
+	if o.Status == OrderStatusOutForDelivery || o.Status == OrderStatusDelivered {
+		return fmt.Errorf("không thể hủy phân công đơn hàng ở trạng thái %s", o.Status)
+	}
+
+	o.raise(NewOrderUnassignedEvent(o.newBaseEvent(OrderUnassignedType), o.DriverID, reason))
+
+	return nil
+}
+
+// RecordLocation ghi điểm hành trình của đơn hàng từ vị trí tài xế được phân công gửi lên lúc recordedAt
+func (o *Order) RecordLocation(driverID string, point geo.Point, recordedAt time.Time) error {
+	if o.Status == OrderStatusDelivered || o.Status == OrderStatusCancelled {
+		return fmt.Errorf("không thể ghi vị trí cho đơn hàng ở trạng thái %s", o.Status)
+	}
+	if o.DriverID == "" || o.DriverID != driverID {
+		return fmt.Errorf("đơn hàng không được phân công cho tài xế %s", driverID)
+	}
+	if point.IsZero() || !point.Valid() {
+		return errors.New("tọa độ không hợp lệ")
+	}
+
+	o.raise(NewOrderLocationRecordedEvent(o.newBaseEvent(OrderLocationRecordedType), driverID, point.Latitude, point.Longitude, o.Status, recordedAt))
+
+	return nil
+}
+
+// CountsTowardsLoad kiểm tra đơn hàng có được tính vào tải trọng của tài xế được phân công hay không
+func (o *Order) CountsTowardsLoad() bool {
+	return o.DriverID != "" && o.Status != OrderStatusDelivered && o.Status != OrderStatusCancelled
+}
@@ -0,0 +1,87 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+func TestRecordLocation(t *testing.T) {
+	point := geo.Point{Latitude: 10.8231, Longitude: 106.6297}
+	recordedAt := domaintest.Now.Add(-30 * time.Second)
+
+	domaintest.Given(t, domaintest.Created(), domaintest.Assigned(2)).
+		When(func(order *domain.Order) error {
+			return order.RecordLocation("DRV-001", point, recordedAt)
+		}).
+		Then(domain.OrderLocationRecordedEvent{
+			BaseEvent:  domaintest.Emitted(domain.OrderLocationRecordedType, 3, "id-1"),
+			DriverID:   "DRV-001",
+			Latitude:   point.Latitude,
+			Longitude:  point.Longitude,
+			Status:     domain.OrderStatusCreated,
+			RecordedAt: recordedAt,
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.CurrentLocation == nil || order.CurrentLocation.Point() != point {
+				t.Fatalf("CurrentLocation = %+v", order.CurrentLocation)
+			}
+		}).
+		ThenRebuilds()
+
+	// Tài xế đã rời Hà Nội của lần cập nhật trạng thái trước nên thành phố và địa chỉ bị xóa
+	hub := domain.Location{Address: "Kho Long Biên", City: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542}
+	inTransit := domain.NewOrderStatusUpdatedEvent(domaintest.Base(domain.OrderStatusUpdatedType, 3), domain.OrderStatusCreated, domain.OrderStatusInTransit, &hub, "")
+	domaintest.Given(t, domaintest.Created(), domaintest.Assigned(2), inTransit).
+		When(func(order *domain.Order) error {
+			return order.RecordLocation("DRV-001", point, recordedAt)
+		}).
+		Then(domain.OrderLocationRecordedEvent{
+			BaseEvent:  domaintest.Emitted(domain.OrderLocationRecordedType, 4, "id-1"),
+			DriverID:   "DRV-001",
+			Latitude:   point.Latitude,
+			Longitude:  point.Longitude,
+			Status:     domain.OrderStatusInTransit,
+			RecordedAt: recordedAt,
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			want := domain.Location{Latitude: point.Latitude, Longitude: point.Longitude}
+			if order.CurrentLocation == nil || *order.CurrentLocation != want {
+				t.Fatalf("CurrentLocation = %+v, muốn %+v", order.CurrentLocation, want)
+			}
+		}).
+		ThenRebuilds()
+
+	// Tài xế vẫn ở đúng vị trí của lần cập nhật trạng thái trước thì thành phố và địa chỉ được giữ lại
+	domaintest.Given(t, domaintest.Created(), domaintest.Assigned(2), inTransit).
+		When(func(order *domain.Order) error {
+			return order.RecordLocation("DRV-001", hub.Point(), recordedAt)
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.CurrentLocation == nil || *order.CurrentLocation != hub {
+				t.Fatalf("CurrentLocation = %+v, muốn %+v", order.CurrentLocation, hub)
+			}
+		}).
+		ThenRebuilds()
+
+	domaintest.Given(t, domaintest.Created(), domaintest.Assigned(2)).
+		When(func(order *domain.Order) error {
+			return order.RecordLocation("DRV-002", point, recordedAt)
+		}).
+		ThenError("không được phân công cho tài xế DRV-002")
+
+	domaintest.Given(t, domaintest.Created(), domaintest.Assigned(2)).
+		When(func(order *domain.Order) error {
+			return order.RecordLocation("DRV-001", geo.Point{Latitude: 91}, recordedAt)
+		}).
+		ThenError("tọa độ không hợp lệ")
+
+	domaintest.Given(t, domaintest.Created(), domaintest.Assigned(2), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error {
+			return order.RecordLocation("DRV-001", point, recordedAt)
+		}).
+		ThenError("không thể ghi vị trí")
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid"
+)
+
+// Clock cung cấp thời gian hiện tại cho aggregate
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc cho phép dùng một hàm như Clock
+type ClockFunc func() time.Time
+
+// Now trả về thời gian hiện tại
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock luôn trả về cùng một thời điểm, dùng cho kiểm thử
+type FixedClock time.Time
+
+// Now trả về thời điểm cố định
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// IDGenerator sinh ID cho aggregate và sự kiện
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc cho phép dùng một hàm như IDGenerator
+type IDGeneratorFunc func() string
+
+// NewID sinh ID mới
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+var (
+	// SystemClock dùng thời gian hệ thống
+	SystemClock Clock = ClockFunc(time.Now)
+
+	// UUIDGenerator sinh UUID ngẫu nhiên
+	UUIDGenerator IDGenerator = IDGeneratorFunc(func() string {
+		return uuid.New().String()
+	})
+)
+
+// OrderOption cấu hình các phụ thuộc của Order
+type OrderOption func(order *Order)
+
+// WithClock chỉ định nguồn thời gian cho đơn hàng
+func WithClock(clock Clock) OrderOption {
+	return func(order *Order) {
+		order.clock = clock
+	}
+}
+
+// WithIDGenerator chỉ định cách sinh ID cho đơn hàng và sự kiện
+func WithIDGenerator(ids IDGenerator) OrderOption {
+	return func(order *Order) {
+		order.ids = ids
+	}
+}
+
+// Các bộ sinh ID được hỗ trợ trong cấu hình
+const (
+	IDGeneratorUUID   = "uuid"
+	IDGeneratorUUIDv7 = "uuidv7"
+	IDGeneratorULID   = "ulid"
+)
+
+// NewIDGenerator tạo bộ sinh ID theo tên, ULID lấy thời gian từ clock
+func NewIDGenerator(name string, clock Clock) (IDGenerator, error) {
+	switch name {
+	case "", IDGeneratorUUID:
+		return UUIDGenerator, nil
+	case IDGeneratorUUIDv7:
+		return UUIDv7Generator, nil
+	case IDGeneratorULID:
+		return NewULIDGenerator(clock), nil
+	default:
+		return nil, fmt.Errorf("bộ sinh ID không được hỗ trợ: %s", name)
+	}
+}
+
+// UUIDv7Generator sinh UUID phiên bản 7, sắp xếp được theo thời gian tạo
+var UUIDv7Generator IDGenerator = IDGeneratorFunc(func() string {
+	return uuid.Must(uuid.NewV7()).String()
+})
+
+// ULIDGenerator sinh ULID theo thời gian của clock, các ID trong cùng một mili giây vẫn tăng dần
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	clock   Clock
+	entropy io.Reader
+}
+
+// NewULIDGenerator tạo bộ sinh ULID, clock nil thì dùng thời gian hệ thống
+func NewULIDGenerator(clock Clock) *ULIDGenerator {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &ULIDGenerator{
+		clock:   clock,
+		entropy: ulid.Monotonic(rand.Reader, 0),
+	}
+}
+
+// NewID sinh ULID mới
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return ulid.MustNew(ulid.Timestamp(g.clock.Now()), g.entropy).String()
+}
@@ -0,0 +1,104 @@
+package domain_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+func TestTimeOrderedIDGenerators(t *testing.T) {
+	clock := &steppingClock{now: time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)}
+
+	for _, name := range []string{domain.IDGeneratorULID, domain.IDGeneratorUUIDv7} {
+		t.Run(name, func(t *testing.T) {
+			ids, err := domain.NewIDGenerator(name, clock)
+			if err != nil {
+				t.Fatalf("NewIDGenerator: %v", err)
+			}
+
+			generated := make([]string, 0, 200)
+			for i := 0; i < 200; i++ {
+				// Một nửa số ID được sinh trong cùng mili giây để kiểm tra thứ tự tăng dần
+				if i%2 == 0 {
+					clock.step()
+					time.Sleep(time.Microsecond)
+				}
+				generated = append(generated, ids.NewID())
+			}
+
+			if !sort.StringsAreSorted(generated) {
+				t.Fatalf("ID không tăng dần theo thời gian: %v", generated[:10])
+			}
+			seen := make(map[string]bool, len(generated))
+			for _, id := range generated {
+				if seen[id] {
+					t.Fatalf("ID bị trùng: %s", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestULIDGeneratorUsesClock(t *testing.T) {
+	now := time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)
+	first := domain.NewULIDGenerator(domain.FixedClock(now)).NewID()
+	later := domain.NewULIDGenerator(domain.FixedClock(now.Add(time.Second))).NewID()
+
+	// 10 ký tự đầu của ULID mã hóa thời gian
+	if first[:10] == later[:10] || first > later {
+		t.Fatalf("ULID không phản ánh thời gian của clock: %s, %s", first, later)
+	}
+}
+
+func TestNewIDGeneratorRejectsUnknown(t *testing.T) {
+	if _, err := domain.NewIDGenerator("snowflake", nil); err == nil {
+		t.Fatal("bộ sinh ID không hỗ trợ phải trả về lỗi")
+	}
+	if ids, err := domain.NewIDGenerator("", nil); err != nil || len(ids.NewID()) != 36 {
+		t.Fatalf("bộ sinh ID mặc định phải là UUID: %v", err)
+	}
+}
+
+func TestOrderUsesInjectedClockAndIDs(t *testing.T) {
+	createdAt := time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC)
+	ids := domain.NewULIDGenerator(domain.FixedClock(createdAt))
+
+	// Nhập đơn hàng lịch sử với thời gian thực của nó
+	order, err := domain.NewOrder("CUS-001", origin, destination, items,
+		domain.WithClock(domain.FixedClock(createdAt)),
+		domain.WithIDGenerator(ids),
+	)
+	if err != nil {
+		t.Fatalf("NewOrder: %v", err)
+	}
+	if !order.CreatedAt.Equal(createdAt) || len(order.ID) != 26 {
+		t.Fatalf("CreatedAt = %v, ID = %s", order.CreatedAt, order.ID)
+	}
+
+	// Đơn hàng dựng lại từ sự kiện dùng clock mới cho các lệnh tiếp theo
+	deliveredAt := createdAt.Add(48 * time.Hour)
+	rebuilt := domain.RebuildFromEvents(order.GetUncommittedEvents(), domain.WithClock(domain.FixedClock(deliveredAt)))
+	if err := rebuilt.UpdateStatus(domain.OrderStatusDelivered, nil, ""); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	event := rebuilt.GetUncommittedEvents()[0]
+	if !event.GetTimestamp().Equal(deliveredAt) || event.GetVersion() != 2 {
+		t.Fatalf("sự kiện = %+v", event)
+	}
+}
+
+// steppingClock tăng một mili giây mỗi lần step
+type steppingClock struct {
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	return c.now
+}
+
+func (c *steppingClock) step() {
+	c.now = c.now.Add(time.Millisecond)
+}
@@ -0,0 +1,249 @@
+// Package domaintest cung cấp bộ kiểm thử given/when/then cho aggregate Order.
+//
+//	domaintest.Given(t, domaintest.Created()).
+//		When(func(o *domain.Order) error { return o.AddNote("gọi trước") }).
+//		Then(domain.OrderNoteAddedEvent{BaseEvent: domaintest.Emitted(domain.OrderNoteAddedType, 2, "id-1"), Note: "gọi trước"})
+//
+// Lệnh chạy với FixedClock tại Now và ID sinh tuần tự "id-1", "id-2"... nên sự kiện phát ra là xác định.
+package domaintest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// OrderID là ID của đơn hàng trong các sự kiện lịch sử do Base tạo
+	OrderID = "order-1"
+
+	// CustomerID là khách hàng của đơn hàng do Created tạo
+	CustomerID = "CUS-001"
+
+	// TrackingNumber là số theo dõi của đơn hàng do Created tạo
+	TrackingNumber = "TRK-0001"
+)
+
+// Now là thời điểm lệnh được thực thi trong kịch bản
+var Now = time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)
+
+// SequenceIDs sinh ID tuần tự "id-1", "id-2"...
+type SequenceIDs struct {
+	mu   sync.Mutex
+	next int
+}
+
+// NewID sinh ID tiếp theo
+func (s *SequenceIDs) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	return fmt.Sprintf("id-%d", s.next)
+}
+
+// Options trả về các tùy chọn giúp đơn hàng sinh sự kiện xác định
+func Options() []domain.OrderOption {
+	return []domain.OrderOption{
+		domain.WithClock(domain.FixedClock(Now)),
+		domain.WithIDGenerator(&SequenceIDs{}),
+	}
+}
+
+// Base tạo phần chung cho sự kiện lịch sử thứ version của OrderID, xảy ra trước Now
+func Base(eventType domain.EventType, version int) domain.BaseEvent {
+	return domain.BaseEvent{
+		ID:          fmt.Sprintf("given-%d", version),
+		AggregateID: OrderID,
+		Type:        eventType,
+		Timestamp:   Now.Add(-time.Hour).Add(time.Duration(version) * time.Minute),
+		Version:     version,
+	}
+}
+
+// Emitted tạo phần chung mong đợi cho sự kiện do lệnh phát ra trên OrderID
+func Emitted(eventType domain.EventType, version int, id string) domain.BaseEvent {
+	return domain.BaseEvent{
+		ID:          id,
+		AggregateID: OrderID,
+		Type:        eventType,
+		Timestamp:   Now,
+		Version:     version,
+	}
+}
+
+// Created tạo sự kiện OrderCreated lịch sử (phiên bản 1) của OrderID
+func Created() domain.OrderCreatedEvent {
+	return domain.NewOrderCreatedEvent(
+		Base(domain.OrderCreatedType, 1),
+		CustomerID,
+		TrackingNumber,
+		domain.Location{Address: "12 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009},
+		domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412},
+		[]domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 2, Weight: decimal.RequireFromString("0.5"), Price: decimal.NewFromInt(120000)}},
+		domain.ServiceLevelStandard,
+		domain.DefaultCurrency,
+	)
+}
+
+// StatusUpdated tạo sự kiện OrderStatusUpdated lịch sử ở phiên bản version
+func StatusUpdated(version int, from, to domain.OrderStatus) domain.OrderStatusUpdatedEvent {
+	return domain.NewOrderStatusUpdatedEvent(Base(domain.OrderStatusUpdatedType, version), from, to, nil, "")
+}
+
+// Assigned tạo sự kiện OrderAssignedToDriver lịch sử ở phiên bản version, phân công cho DRV-001 với xe VEH-001
+func Assigned(version int) domain.OrderAssignedToDriverEvent {
+	return domain.NewOrderAssignedToDriverEvent(Base(domain.OrderAssignedToDriverType, version), "DRV-001", "VEH-001")
+}
+
+// ScannedIn tạo sự kiện ParcelScannedIn lịch sử ở phiên bản version, quét vào hub hubCode tại TP.HCM
+func ScannedIn(version int, hubCode string) domain.ParcelScannedInEvent {
+	return domain.NewParcelScannedInEvent(Base(domain.ParcelScannedInType, version), hubCode, "", 10.8231, 106.6297)
+}
+
+// Scenario là một kịch bản given/when/then trên aggregate Order
+type Scenario struct {
+	t      testing.TB
+	opts   []domain.OrderOption
+	given  []domain.Event
+	order  *domain.Order
+	events []domain.Event
+	err    error
+	ran    bool
+}
+
+// Given bắt đầu kịch bản với các sự kiện đã xảy ra của đơn hàng
+func Given(t testing.TB, events ...domain.Event) *Scenario {
+	t.Helper()
+	return &Scenario{t: t, given: events}
+}
+
+// With thêm các tùy chọn cho đơn hàng trong kịch bản, ví dụ domain.WithDeliveryEstimator
+func (s *Scenario) With(opts ...domain.OrderOption) *Scenario {
+	s.opts = append(s.opts, opts...)
+	return s
+}
+
+// When dựng lại đơn hàng từ các sự kiện đã cho rồi chạy lệnh trên đó
+func (s *Scenario) When(command func(order *domain.Order) error) *Scenario {
+	s.t.Helper()
+	if len(s.given) == 0 {
+		s.t.Fatal("When cần ít nhất một sự kiện trong Given, dùng WhenCreate để tạo đơn hàng mới")
+	}
+
+	s.order = domain.RebuildFromEvents(s.given, s.options()...)
+	if s.order == nil {
+		s.t.Fatal("không dựng lại được đơn hàng từ các sự kiện trong Given")
+	}
+
+	s.err = command(s.order)
+	s.events = s.order.GetUncommittedEvents()
+	s.ran = true
+	return s
+}
+
+// WhenCreate chạy lệnh tạo đơn hàng mới, opts phải được truyền vào domain.NewOrder
+func (s *Scenario) WhenCreate(command func(opts ...domain.OrderOption) (*domain.Order, error)) *Scenario {
+	s.t.Helper()
+	if len(s.given) > 0 {
+		s.t.Fatal("WhenCreate không dùng cùng sự kiện trong Given")
+	}
+
+	s.order, s.err = command(s.options()...)
+	if s.order != nil {
+		s.events = s.order.GetUncommittedEvents()
+	}
+	s.ran = true
+	return s
+}
+
+// Then khẳng định lệnh thành công và phát ra đúng các sự kiện theo thứ tự
+func (s *Scenario) Then(want ...domain.Event) *Scenario {
+	s.t.Helper()
+	s.mustHaveRun()
+	if s.err != nil {
+		s.t.Fatalf("lệnh trả về lỗi %q, muốn thành công", s.err)
+	}
+
+	if len(s.events) != len(want) {
+		s.t.Fatalf("lệnh phát ra %d sự kiện, muốn %d:\n got: %s", len(s.events), len(want), formatEvents(s.events))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(s.events[i], want[i]) {
+			s.t.Fatalf("sự kiện %d khác nhau:\n got: %#v\nwant: %#v", i, s.events[i], want[i])
+		}
+	}
+	return s
+}
+
+// ThenError khẳng định lệnh thất bại với lỗi chứa message và không phát ra sự kiện nào
+func (s *Scenario) ThenError(message string) *Scenario {
+	s.t.Helper()
+	s.mustHaveRun()
+	if s.err == nil {
+		s.t.Fatalf("lệnh thành công với các sự kiện %s, muốn lỗi %q", formatEvents(s.events), message)
+	}
+	if !strings.Contains(s.err.Error(), message) {
+		s.t.Fatalf("lỗi = %q, muốn chứa %q", s.err, message)
+	}
+	if len(s.events) > 0 {
+		s.t.Fatalf("lệnh lỗi nhưng vẫn phát ra sự kiện: %s", formatEvents(s.events))
+	}
+	return s
+}
+
+// ThenState kiểm tra trạng thái đơn hàng sau khi chạy lệnh
+func (s *Scenario) ThenState(check func(t testing.TB, order *domain.Order)) *Scenario {
+	s.t.Helper()
+	s.mustHaveRun()
+	check(s.t, s.order)
+	return s
+}
+
+// ThenRebuilds khẳng định dựng lại đơn hàng từ Given cộng các sự kiện mới cho cùng trạng thái với đơn hàng sau lệnh
+func (s *Scenario) ThenRebuilds() *Scenario {
+	s.t.Helper()
+	s.mustHaveRun()
+
+	history := append(append([]domain.Event{}, s.given...), s.events...)
+	rebuilt := domain.RebuildFromEvents(history)
+	if rebuilt == nil || s.order == nil {
+		s.t.Fatalf("không dựng lại được đơn hàng từ %s", formatEvents(history))
+	}
+
+	changes, err := domain.DiffOrders(s.order, rebuilt)
+	if err != nil {
+		s.t.Fatalf("DiffOrders: %v", err)
+	}
+	if len(changes) > 0 {
+		s.t.Fatalf("đơn hàng dựng lại khác đơn hàng sau lệnh: %+v", changes)
+	}
+	if rebuilt.Version() != s.order.Version() {
+		s.t.Fatalf("phiên bản dựng lại = %d, muốn %d", rebuilt.Version(), s.order.Version())
+	}
+	return s
+}
+
+func (s *Scenario) options() []domain.OrderOption {
+	return append(Options(), s.opts...)
+}
+
+func (s *Scenario) mustHaveRun() {
+	s.t.Helper()
+	if !s.ran {
+		s.t.Fatal("cần gọi When hoặc WhenCreate trước Then")
+	}
+}
+
+func formatEvents(events []domain.Event) string {
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = string(event.GetType())
+	}
+	return "[" + strings.Join(types, ", ") + "]"
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ServiceLevel là mức dịch vụ giao hàng khách hàng chọn khi tạo đơn
+type ServiceLevel string
+
+const (
+	ServiceLevelStandard ServiceLevel = "STANDARD"
+	ServiceLevelExpress  ServiceLevel = "EXPRESS"
+)
+
+// Valid kiểm tra mức dịch vụ có được hỗ trợ hay không
+func (l ServiceLevel) Valid() bool {
+	return l == ServiceLevelStandard || l == ServiceLevelExpress
+}
+
+// DeliveryEstimate là kết quả ước tính thời gian giao hàng.
+// Promised chỉ được dùng ở lần ước tính đầu tiên và không đổi sau đó.
+type DeliveryEstimate struct {
+	Estimated time.Time
+	Promised  time.Time
+}
+
+// DeliveryEstimator ước tính thời gian giao hàng của đơn hàng tại thời điểm at,
+// trả về false khi đơn hàng không cần ước tính (đã giao hoặc đã hủy)
+type DeliveryEstimator interface {
+	EstimateDelivery(order *Order, at time.Time) (DeliveryEstimate, bool)
+}
+
+// WithDeliveryEstimator chỉ định cách ước tính thời gian giao hàng, ETA được tính lại sau mỗi lần đổi trạng thái
+func WithDeliveryEstimator(estimator DeliveryEstimator) OrderOption {
+	return func(order *Order) {
+		order.estimator = estimator
+	}
+}
+
+// WithServiceLevel chỉ định mức dịch vụ khi tạo đơn hàng, không có tác dụng với đơn hàng đã tồn tại
+func WithServiceLevel(level ServiceLevel) OrderOption {
+	return func(order *Order) {
+		if order.ID == "" {
+			order.ServiceLevel = level
+		}
+	}
+}
+
+// IsOverdue kiểm tra đơn hàng chưa hoàn thành đã quá thời gian cam kết tại thời điểm at
+func (o *Order) IsOverdue(at time.Time) bool {
+	if o.PromisedDelivery == nil || o.Status == OrderStatusDelivered || o.Status == OrderStatusCancelled {
+		return false
+	}
+	return at.After(*o.PromisedDelivery)
+}
+
+// MarkSLABreached ghi nhận đơn hàng đã quá thời gian cam kết mà chưa được giao
+func (o *Order) MarkSLABreached() error {
+	if o.SLABreached {
+		return errors.New("đơn hàng đã được ghi nhận vi phạm SLA")
+	}
+
+	now := o.now()
+	if !o.IsOverdue(now) {
+		return errors.New("đơn hàng chưa quá thời gian giao hàng cam kết")
+	}
+
+	o.raise(NewSLABreachedEvent(o.newBaseEvent(SLABreachedType), *o.PromisedDelivery, o.Status))
+
+	return nil
+}
+
+// estimateDelivery tính lại ETA và chỉ tạo sự kiện khi ETA thay đổi
+func (o *Order) estimateDelivery() {
+	if o.estimator == nil {
+		return
+	}
+
+	estimate, ok := o.estimator.EstimateDelivery(o, o.now())
+	if !ok {
+		return
+	}
+	if o.EstimatedDelivery != nil && o.EstimatedDelivery.Equal(estimate.Estimated) {
+		return
+	}
+
+	promised := estimate.Promised
+	if o.PromisedDelivery != nil {
+		promised = *o.PromisedDelivery
+	}
+
+	o.raise(NewDeliveryEstimatedEvent(o.newBaseEvent(DeliveryEstimatedType), estimate.Estimated, promised))
+}
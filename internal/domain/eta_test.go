@@ -0,0 +1,193 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+)
+
+// hoursEstimator ước tính ETA bằng số giờ cố định theo trạng thái, cam kết thêm 6 giờ
+type hoursEstimator map[domain.OrderStatus]int
+
+func (e hoursEstimator) EstimateDelivery(order *domain.Order, at time.Time) (domain.DeliveryEstimate, bool) {
+	hours, ok := e[order.Status]
+	if !ok {
+		return domain.DeliveryEstimate{}, false
+	}
+	estimated := at.Add(time.Duration(hours) * time.Hour)
+	return domain.DeliveryEstimate{Estimated: estimated, Promised: estimated.Add(6 * time.Hour)}, true
+}
+
+var estimator = hoursEstimator{
+	domain.OrderStatusCreated:        48,
+	domain.OrderStatusInTransit:      24,
+	domain.OrderStatusOutForDelivery: 4,
+}
+
+func TestNewOrderEstimatesDelivery(t *testing.T) {
+	domaintest.Given(t).
+		With(domain.WithDeliveryEstimator(estimator), domain.WithServiceLevel(domain.ServiceLevelExpress)).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		Then(
+			domain.OrderCreatedEvent{
+				BaseEvent: domain.BaseEvent{
+					ID:          "id-3",
+					AggregateID: "id-1",
+					Type:        domain.OrderCreatedType,
+					Timestamp:   domaintest.Now,
+					Version:     1,
+				},
+				CustomerID:     "CUS-001",
+				TrackingNumber: "TRK-ID2",
+				Origin:         origin,
+				Destination:    destination,
+				Items:          items,
+				ServiceLevel:   domain.ServiceLevelExpress,
+				Currency:       domain.DefaultCurrency,
+			},
+			domain.DeliveryEstimatedEvent{
+				BaseEvent: domain.BaseEvent{
+					ID:          "id-4",
+					AggregateID: "id-1",
+					Type:        domain.DeliveryEstimatedType,
+					Timestamp:   domaintest.Now,
+					Version:     2,
+				},
+				EstimatedDelivery: domaintest.Now.Add(48 * time.Hour),
+				PromisedDelivery:  domaintest.Now.Add(54 * time.Hour),
+			},
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.ServiceLevel != domain.ServiceLevelExpress || order.Version() != 2 {
+				t.Fatalf("ServiceLevel = %s, version %d", order.ServiceLevel, order.Version())
+			}
+			if !order.EstimatedDelivery.Equal(domaintest.Now.Add(48*time.Hour)) || !order.PromisedDelivery.Equal(domaintest.Now.Add(54*time.Hour)) {
+				t.Fatalf("EstimatedDelivery = %v, PromisedDelivery = %v", order.EstimatedDelivery, order.PromisedDelivery)
+			}
+		})
+}
+
+func TestNewOrderRejectsUnknownServiceLevel(t *testing.T) {
+	domaintest.Given(t).
+		With(domain.WithServiceLevel("OVERNIGHT")).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenError("mức dịch vụ không hợp lệ")
+}
+
+func TestServiceLevelDefaultsToStandard(t *testing.T) {
+	created := domaintest.Created()
+	created.ServiceLevel = ""
+
+	order := domain.RebuildFromEvents([]domain.Event{created})
+	if order.ServiceLevel != domain.ServiceLevelStandard {
+		t.Fatalf("ServiceLevel = %q, muốn STANDARD", order.ServiceLevel)
+	}
+}
+
+func TestUpdateStatusRecalculatesETAAndKeepsPromise(t *testing.T) {
+	promised := domaintest.Now.Add(30 * time.Hour)
+	estimated := domain.NewDeliveryEstimatedEvent(domaintest.Base(domain.DeliveryEstimatedType, 2), domaintest.Now.Add(20*time.Hour), promised)
+
+	domaintest.Given(t, domaintest.Created(), estimated).
+		With(domain.WithDeliveryEstimator(estimator)).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusInTransit, hub, "")
+		}).
+		Then(
+			domain.OrderStatusUpdatedEvent{
+				BaseEvent:       domaintest.Emitted(domain.OrderStatusUpdatedType, 3, "id-1"),
+				OldStatus:       domain.OrderStatusCreated,
+				NewStatus:       domain.OrderStatusInTransit,
+				CurrentLocation: hub,
+			},
+			domain.DeliveryEstimatedEvent{
+				BaseEvent:         domaintest.Emitted(domain.DeliveryEstimatedType, 4, "id-2"),
+				EstimatedDelivery: domaintest.Now.Add(24 * time.Hour),
+				PromisedDelivery:  promised,
+			},
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if !order.PromisedDelivery.Equal(promised) {
+				t.Fatalf("PromisedDelivery = %v, muốn %v", order.PromisedDelivery, promised)
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestUpdateStatusSkipsUnchangedETA(t *testing.T) {
+	estimated := domain.NewDeliveryEstimatedEvent(domaintest.Base(domain.DeliveryEstimatedType, 2), domaintest.Now.Add(48*time.Hour), domaintest.Now.Add(54*time.Hour))
+
+	domaintest.Given(t, domaintest.Created(), estimated).
+		With(domain.WithDeliveryEstimator(estimator)).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusCreated, nil, "Chờ lấy hàng")
+		}).
+		Then(domain.OrderStatusUpdatedEvent{
+			BaseEvent: domaintest.Emitted(domain.OrderStatusUpdatedType, 3, "id-1"),
+			OldStatus: domain.OrderStatusCreated,
+			NewStatus: domain.OrderStatusCreated,
+			Note:      "Chờ lấy hàng",
+		})
+}
+
+func TestLateDeliveryBreachesSLA(t *testing.T) {
+	promised := domaintest.Now.Add(-time.Minute)
+	estimated := domain.NewDeliveryEstimatedEvent(domaintest.Base(domain.DeliveryEstimatedType, 2), promised, promised)
+
+	domaintest.Given(t, domaintest.Created(), estimated).
+		With(domain.WithDeliveryEstimator(estimator)).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusDelivered, nil, "")
+		}).
+		Then(
+			domain.OrderStatusUpdatedEvent{
+				BaseEvent: domaintest.Emitted(domain.OrderStatusUpdatedType, 3, "id-1"),
+				OldStatus: domain.OrderStatusCreated,
+				NewStatus: domain.OrderStatusDelivered,
+			},
+			domain.SLABreachedEvent{
+				BaseEvent:        domaintest.Emitted(domain.SLABreachedType, 4, "id-2"),
+				PromisedDelivery: promised,
+				Status:           domain.OrderStatusDelivered,
+			},
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if !order.SLABreached {
+				t.Fatal("SLABreached = false, muốn true")
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestMarkSLABreached(t *testing.T) {
+	overdue := domain.NewDeliveryEstimatedEvent(domaintest.Base(domain.DeliveryEstimatedType, 2), domaintest.Now.Add(-2*time.Hour), domaintest.Now.Add(-time.Hour))
+
+	domaintest.Given(t, domaintest.Created(), overdue).
+		When(func(order *domain.Order) error { return order.MarkSLABreached() }).
+		Then(domain.SLABreachedEvent{
+			BaseEvent:        domaintest.Emitted(domain.SLABreachedType, 3, "id-1"),
+			PromisedDelivery: domaintest.Now.Add(-time.Hour),
+			Status:           domain.OrderStatusCreated,
+		}).
+		ThenRebuilds()
+
+	breached := domain.NewSLABreachedEvent(domaintest.Base(domain.SLABreachedType, 3), domaintest.Now.Add(-time.Hour), domain.OrderStatusCreated)
+	domaintest.Given(t, domaintest.Created(), overdue, breached).
+		When(func(order *domain.Order) error { return order.MarkSLABreached() }).
+		ThenError("đã được ghi nhận vi phạm SLA")
+
+	onTime := domain.NewDeliveryEstimatedEvent(domaintest.Base(domain.DeliveryEstimatedType, 2), domaintest.Now.Add(time.Hour), domaintest.Now.Add(2*time.Hour))
+	domaintest.Given(t, domaintest.Created(), onTime).
+		When(func(order *domain.Order) error { return order.MarkSLABreached() }).
+		ThenError("chưa quá thời gian giao hàng cam kết")
+
+	domaintest.Given(t, domaintest.Created(), overdue, domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error { return order.MarkSLABreached() }).
+		ThenError("chưa quá thời gian giao hàng cam kết")
+}
@@ -12,9 +12,37 @@ const (
 	OrderStatusUpdatedType EventType = "ORDER_STATUS_UPDATED"
 	OrderCancelledType     EventType = "ORDER_CANCELLED"
 	OrderNoteAddedType     EventType = "ORDER_NOTE_ADDED"
+	DeliveryEstimatedType  EventType = "DELIVERY_ESTIMATED"
+	SLABreachedType        EventType = "SLA_BREACHED"
+	OrderPricedType        EventType = "ORDER_PRICED"
+
+	PaymentPendingType        EventType = "PAYMENT_PENDING"
+	PaymentCollectedType      EventType = "PAYMENT_COLLECTED"
+	PaymentRemittedType       EventType = "PAYMENT_REMITTED"
+	PaymentRefundRequiredType EventType = "PAYMENT_REFUND_REQUIRED"
+	PaymentRefundedType       EventType = "PAYMENT_REFUNDED"
+
+	OrderAssignedToDriverType EventType = "ORDER_ASSIGNED_TO_DRIVER"
+	OrderUnassignedType       EventType = "ORDER_UNASSIGNED"
+	OrderLocationRecordedType EventType = "ORDER_LOCATION_RECORDED"
+	ParcelScannedInType       EventType = "PARCEL_SCANNED_IN"
+	ParcelScannedOutType      EventType = "PARCEL_SCANNED_OUT"
+
+	DriverRegisteredType      EventType = "DRIVER_REGISTERED"
+	DriverVehicleAssignedType EventType = "DRIVER_VEHICLE_ASSIGNED"
+	DriverDeactivatedType     EventType = "DRIVER_DEACTIVATED"
+	DriverActivatedType       EventType = "DRIVER_ACTIVATED"
+	RoutePlannedType          EventType = "ROUTE_PLANNED"
+	VehicleRegisteredType     EventType = "VEHICLE_REGISTERED"
+	VehicleRetiredType        EventType = "VEHICLE_RETIRED"
 )
 
-// Event là interface cho tất cả các sự kiện domain
+// RedactedValue thay thế dữ liệu cá nhân của khách hàng đã yêu cầu xóa dữ liệu
+const RedactedValue = "[REDACTED]"
+
+// Event là interface cho tất cả các sự kiện domain.
+// Trường chứa dữ liệu cá nhân được đánh dấu bằng tag `pii:"true"` để được mã hóa khi lưu,
+// trường `pii:"subject"` xác định khách hàng sở hữu dữ liệu và cũng được mã hóa.
 type Event interface {
 	GetID() string
 	GetAggregateID() string
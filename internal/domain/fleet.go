@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+// DriverStatus là trạng thái làm việc của tài xế
+type DriverStatus string
+
+const (
+	DriverStatusActive   DriverStatus = "ACTIVE"
+	DriverStatusInactive DriverStatus = "INACTIVE" // không nhận đơn hàng mới
+)
+
+// VehicleStatus là trạng thái sử dụng của phương tiện
+type VehicleStatus string
+
+const (
+	VehicleStatusActive  VehicleStatus = "ACTIVE"
+	VehicleStatusRetired VehicleStatus = "RETIRED" // ngừng sử dụng, không thể gán lại
+)
+
+// fleetAggregate chứa phần chung của tài xế và phương tiện: clock, bộ sinh ID và sự kiện chưa commit
+type fleetAggregate struct {
+	clock   Clock
+	ids     IDGenerator
+	events  []Event
+	version int // số sự kiện đã áp dụng, kể cả sự kiện chưa commit
+}
+
+// configure lấy clock và bộ sinh ID từ các tùy chọn của đơn hàng, các tùy chọn khác bị bỏ qua
+func (a *fleetAggregate) configure(opts []OrderOption) {
+	var order Order
+	order.apply(opts...)
+	if order.clock != nil {
+		a.clock = order.clock
+	}
+	if order.ids != nil {
+		a.ids = order.ids
+	}
+}
+
+// Version trả về phiên bản hiện tại, bằng số sự kiện đã áp dụng
+func (a *fleetAggregate) Version() int {
+	return a.version
+}
+
+// GetUncommittedEvents trả về các sự kiện chưa được commit
+func (a *fleetAggregate) GetUncommittedEvents() []Event {
+	return a.events
+}
+
+// ClearUncommittedEvents xóa các sự kiện chưa được commit
+func (a *fleetAggregate) ClearUncommittedEvents() {
+	a.events = []Event{}
+}
+
+func (a *fleetAggregate) newBaseEvent(aggregateID string, eventType EventType) BaseEvent {
+	return BaseEvent{
+		ID:          a.newID(),
+		AggregateID: aggregateID,
+		Type:        eventType,
+		Timestamp:   a.now(),
+		Version:     a.version + 1,
+	}
+}
+
+func (a *fleetAggregate) now() time.Time {
+	if a.clock == nil {
+		return SystemClock.Now()
+	}
+	return a.clock.Now()
+}
+
+func (a *fleetAggregate) newID() string {
+	if a.ids == nil {
+		return UUIDGenerator.NewID()
+	}
+	return a.ids.NewID()
+}
+
+// Driver là aggregate tài xế
+type Driver struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Status    DriverStatus `json:"status"`
+	VehicleID string       `json:"vehicle_id,omitempty"` // phương tiện đang sử dụng, rỗng khi chưa được gán
+	Route     *Route       `json:"route,omitempty"`      // lộ trình giao hàng được lập gần nhất
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+
+	fleetAggregate
+}
+
+// Vehicle là aggregate phương tiện vận chuyển
+type Vehicle struct {
+	ID          string          `json:"id"`
+	PlateNumber string          `json:"plate_number"`
+	CapacityKg  decimal.Decimal `json:"capacity_kg"` // tổng khối lượng hàng tối đa
+	Status      VehicleStatus   `json:"status"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+
+	fleetAggregate
+}
+
+// NewDriver đăng ký tài xế mới, opts giống như của đơn hàng nhưng chỉ dùng clock và bộ sinh ID
+func NewDriver(name string, opts ...OrderOption) (*Driver, error) {
+	if name == "" {
+		return nil, errors.New("tên tài xế không được để trống")
+	}
+
+	driver := &Driver{}
+	driver.configure(opts)
+	driver.ID = driver.newID()
+	driver.raise(NewDriverRegisteredEvent(driver.newBaseEvent(driver.ID, DriverRegisteredType), name))
+
+	return driver, nil
+}
+
+// AssignVehicle gán phương tiện cho tài xế, thay cho phương tiện đang dùng nếu có
+func (d *Driver) AssignVehicle(vehicle *Vehicle) error {
+	if d.Status != DriverStatusActive {
+		return fmt.Errorf("tài xế %s không còn hoạt động", d.ID)
+	}
+	if vehicle == nil || vehicle.Status != VehicleStatusActive {
+		return errors.New("phương tiện không tồn tại hoặc đã ngừng sử dụng")
+	}
+	if d.VehicleID == vehicle.ID {
+		return fmt.Errorf("tài xế đang sử dụng phương tiện %s", vehicle.ID)
+	}
+
+	d.raise(NewDriverVehicleAssignedEvent(d.newBaseEvent(d.ID, DriverVehicleAssignedType), vehicle.ID))
+
+	return nil
+}
+
+// Deactivate ngừng nhận đơn hàng mới cho tài xế, đơn hàng đã phân công không bị ảnh hưởng
+func (d *Driver) Deactivate(reason string) error {
+	if d.Status == DriverStatusInactive {
+		return errors.New("tài xế đã ngừng hoạt động trước đó")
+	}
+
+	d.raise(NewDriverDeactivatedEvent(d.newBaseEvent(d.ID, DriverDeactivatedType), reason))
+
+	return nil
+}
+
+// Activate cho tài xế hoạt động trở lại
+func (d *Driver) Activate() error {
+	if d.Status == DriverStatusActive {
+		return errors.New("tài xế đang hoạt động")
+	}
+
+	d.raise(NewDriverActivatedEvent(d.newBaseEvent(d.ID, DriverActivatedType)))
+
+	return nil
+}
+
+// PlanRoute lập lộ trình giao các điểm destinations từ start theo tải trọng của phương tiện tài xế đang dùng.
+// unrouted là các đơn hàng không thể đưa vào lộ trình, được ghi lại cùng lộ trình.
+func (d *Driver) PlanRoute(vehicle *Vehicle, start geo.Point, destinations []RouteDestination, unrouted []string) error {
+	if vehicle == nil || d.VehicleID == "" || vehicle.ID != d.VehicleID {
+		return fmt.Errorf("tài xế %s chưa được gán phương tiện", d.ID)
+	}
+	if len(destinations) == 0 {
+		return errors.New("không có đơn hàng nào có tọa độ điểm giao để lập lộ trình")
+	}
+
+	stops, distance, err := PlanRoute(start, destinations, vehicle.CapacityKg)
+	if err != nil {
+		return err
+	}
+
+	d.raise(NewRoutePlannedEvent(d.newBaseEvent(d.ID, RoutePlannedType), vehicle.ID, start, stops, distance, unrouted))
+
+	return nil
+}
+
+func (d *Driver) raise(event Event) {
+	if apply, ok := driverAppliers[event.GetType()]; ok {
+		if applied := apply(d, event); applied != nil && applied != d {
+			*d = *applied
+		}
+	}
+	d.events = append(d.events, event)
+	d.version++
+}
+
+// NewVehicle đăng ký phương tiện mới với tải trọng tính theo kg
+func NewVehicle(plateNumber string, capacityKg decimal.Decimal, opts ...OrderOption) (*Vehicle, error) {
+	if plateNumber == "" {
+		return nil, errors.New("biển số không được để trống")
+	}
+	if !capacityKg.IsPositive() {
+		return nil, errors.New("tải trọng phải lớn hơn 0")
+	}
+
+	vehicle := &Vehicle{}
+	vehicle.configure(opts)
+	vehicle.ID = vehicle.newID()
+	vehicle.raise(NewVehicleRegisteredEvent(vehicle.newBaseEvent(vehicle.ID, VehicleRegisteredType), plateNumber, capacityKg))
+
+	return vehicle, nil
+}
+
+// Retire ngừng sử dụng phương tiện, tài xế đang dùng phương tiện không thể nhận đơn hàng mới
+func (v *Vehicle) Retire(reason string) error {
+	if v.Status == VehicleStatusRetired {
+		return errors.New("phương tiện đã ngừng sử dụng trước đó")
+	}
+
+	v.raise(NewVehicleRetiredEvent(v.newBaseEvent(v.ID, VehicleRetiredType), reason))
+
+	return nil
+}
+
+func (v *Vehicle) raise(event Event) {
+	if apply, ok := vehicleAppliers[event.GetType()]; ok {
+		if applied := apply(v, event); applied != nil && applied != v {
+			*v = *applied
+		}
+	}
+	v.events = append(v.events, event)
+	v.version++
+}
+
+// RebuildDriver xây dựng lại tài xế từ luồng sự kiện, nil nếu luồng không phải của tài xế.
+// opts giống như của NewDriver.
+func RebuildDriver(events []Event, opts ...OrderOption) *Driver {
+	var driver *Driver
+	for _, event := range events {
+		if apply, ok := driverAppliers[event.GetType()]; ok {
+			driver = apply(driver, event)
+		}
+	}
+	if driver == nil {
+		return nil
+	}
+
+	driver.version = len(events)
+	driver.configure(opts)
+	return driver
+}
+
+// RebuildVehicle xây dựng lại phương tiện từ luồng sự kiện, nil nếu luồng không phải của phương tiện
+func RebuildVehicle(events []Event, opts ...OrderOption) *Vehicle {
+	var vehicle *Vehicle
+	for _, event := range events {
+		if apply, ok := vehicleAppliers[event.GetType()]; ok {
+			vehicle = apply(vehicle, event)
+		}
+	}
+	if vehicle == nil {
+		return nil
+	}
+
+	vehicle.version = len(events)
+	vehicle.configure(opts)
+	return vehicle
+}
@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+// driverAppliers và vehicleAppliers là hàm áp dụng sự kiện của tài xế và phương tiện theo loại sự kiện
+var (
+	driverAppliers  = make(map[EventType]func(*Driver, Event) *Driver)
+	vehicleAppliers = make(map[EventType]func(*Vehicle, Event) *Vehicle)
+)
+
+func init() {
+	registerAggregateEvent(DriverRegisteredType, AggregateDriver, driverAppliers, applyDriverRegistered)
+	registerAggregateEvent(DriverVehicleAssignedType, AggregateDriver, driverAppliers, onExisting(applyDriverVehicleAssigned))
+	registerAggregateEvent(DriverDeactivatedType, AggregateDriver, driverAppliers, onExisting(applyDriverDeactivated))
+	registerAggregateEvent(DriverActivatedType, AggregateDriver, driverAppliers, onExisting(applyDriverActivated))
+	registerAggregateEvent(RoutePlannedType, AggregateDriver, driverAppliers, onExisting(applyRoutePlanned))
+	registerAggregateEvent(VehicleRegisteredType, AggregateVehicle, vehicleAppliers, applyVehicleRegistered)
+	registerAggregateEvent(VehicleRetiredType, AggregateVehicle, vehicleAppliers, onExisting(applyVehicleRetired))
+}
+
+// onExisting bọc hàm áp dụng cho các sự kiện chỉ có ý nghĩa khi aggregate đã tồn tại
+func onExisting[A any, E Event](apply func(a *A, event E)) func(a *A, event E) *A {
+	return func(a *A, event E) *A {
+		if a == nil {
+			return nil
+		}
+		apply(a, event)
+		return a
+	}
+}
+
+// DriverRegisteredEvent là sự kiện khi tài xế được đăng ký
+type DriverRegisteredEvent struct {
+	BaseEvent
+	Name string `json:"name"`
+}
+
+// NewDriverRegisteredEvent tạo một DriverRegisteredEvent mới
+func NewDriverRegisteredEvent(base BaseEvent, name string) DriverRegisteredEvent {
+	base.Type = DriverRegisteredType
+	return DriverRegisteredEvent{
+		BaseEvent: base,
+		Name:      name,
+	}
+}
+
+func applyDriverRegistered(driver *Driver, e DriverRegisteredEvent) *Driver {
+	if driver == nil {
+		driver = &Driver{}
+	}
+	driver.ID = e.AggregateID
+	driver.Name = e.Name
+	driver.Status = DriverStatusActive
+	driver.CreatedAt = e.Timestamp
+	driver.UpdatedAt = e.Timestamp
+	return driver
+}
+
+// DriverVehicleAssignedEvent là sự kiện khi tài xế được gán phương tiện
+type DriverVehicleAssignedEvent struct {
+	BaseEvent
+	VehicleID string `json:"vehicle_id"`
+}
+
+// NewDriverVehicleAssignedEvent tạo một DriverVehicleAssignedEvent mới
+func NewDriverVehicleAssignedEvent(base BaseEvent, vehicleID string) DriverVehicleAssignedEvent {
+	base.Type = DriverVehicleAssignedType
+	return DriverVehicleAssignedEvent{
+		BaseEvent: base,
+		VehicleID: vehicleID,
+	}
+}
+
+func applyDriverVehicleAssigned(driver *Driver, e DriverVehicleAssignedEvent) {
+	driver.VehicleID = e.VehicleID
+	driver.UpdatedAt = e.Timestamp
+}
+
+// DriverDeactivatedEvent là sự kiện khi tài xế ngừng hoạt động
+type DriverDeactivatedEvent struct {
+	BaseEvent
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewDriverDeactivatedEvent tạo một DriverDeactivatedEvent mới
+func NewDriverDeactivatedEvent(base BaseEvent, reason string) DriverDeactivatedEvent {
+	base.Type = DriverDeactivatedType
+	return DriverDeactivatedEvent{
+		BaseEvent: base,
+		Reason:    reason,
+	}
+}
+
+func applyDriverDeactivated(driver *Driver, e DriverDeactivatedEvent) {
+	driver.Status = DriverStatusInactive
+	driver.UpdatedAt = e.Timestamp
+}
+
+// DriverActivatedEvent là sự kiện khi tài xế hoạt động trở lại
+type DriverActivatedEvent struct {
+	BaseEvent
+}
+
+// NewDriverActivatedEvent tạo một DriverActivatedEvent mới
+func NewDriverActivatedEvent(base BaseEvent) DriverActivatedEvent {
+	base.Type = DriverActivatedType
+	return DriverActivatedEvent{
+		BaseEvent: base,
+	}
+}
+
+func applyDriverActivated(driver *Driver, e DriverActivatedEvent) {
+	driver.Status = DriverStatusActive
+	driver.UpdatedAt = e.Timestamp
+}
+
+// RoutePlannedEvent là sự kiện khi lộ trình giao hàng của tài xế được lập
+type RoutePlannedEvent struct {
+	BaseEvent
+	VehicleID      string      `json:"vehicle_id"`
+	StartLatitude  float64     `json:"start_latitude"`
+	StartLongitude float64     `json:"start_longitude"`
+	Stops          []RouteStop `json:"stops"`
+	DistanceKm     float64     `json:"distance_km"`
+	Unrouted       []string    `json:"unrouted,omitempty"`
+}
+
+// NewRoutePlannedEvent tạo một RoutePlannedEvent mới
+func NewRoutePlannedEvent(base BaseEvent, vehicleID string, start geo.Point, stops []RouteStop, distanceKm float64, unrouted []string) RoutePlannedEvent {
+	base.Type = RoutePlannedType
+	return RoutePlannedEvent{
+		BaseEvent:      base,
+		VehicleID:      vehicleID,
+		StartLatitude:  start.Latitude,
+		StartLongitude: start.Longitude,
+		Stops:          stops,
+		DistanceKm:     distanceKm,
+		Unrouted:       unrouted,
+	}
+}
+
+func applyRoutePlanned(driver *Driver, e RoutePlannedEvent) {
+	driver.Route = &Route{
+		VehicleID:  e.VehicleID,
+		Start:      geo.Point{Latitude: e.StartLatitude, Longitude: e.StartLongitude},
+		Stops:      e.Stops,
+		DistanceKm: e.DistanceKm,
+		Unrouted:   e.Unrouted,
+		PlannedAt:  e.Timestamp,
+	}
+	driver.UpdatedAt = e.Timestamp
+}
+
+// VehicleRegisteredEvent là sự kiện khi phương tiện được đăng ký
+type VehicleRegisteredEvent struct {
+	BaseEvent
+	PlateNumber string          `json:"plate_number"`
+	CapacityKg  decimal.Decimal `json:"capacity_kg"`
+}
+
+// NewVehicleRegisteredEvent tạo một VehicleRegisteredEvent mới
+func NewVehicleRegisteredEvent(base BaseEvent, plateNumber string, capacityKg decimal.Decimal) VehicleRegisteredEvent {
+	base.Type = VehicleRegisteredType
+	return VehicleRegisteredEvent{
+		BaseEvent:   base,
+		PlateNumber: plateNumber,
+		CapacityKg:  capacityKg,
+	}
+}
+
+func applyVehicleRegistered(vehicle *Vehicle, e VehicleRegisteredEvent) *Vehicle {
+	if vehicle == nil {
+		vehicle = &Vehicle{}
+	}
+	vehicle.ID = e.AggregateID
+	vehicle.PlateNumber = e.PlateNumber
+	vehicle.CapacityKg = e.CapacityKg
+	vehicle.Status = VehicleStatusActive
+	vehicle.CreatedAt = e.Timestamp
+	vehicle.UpdatedAt = e.Timestamp
+	return vehicle
+}
+
+// VehicleRetiredEvent là sự kiện khi phương tiện ngừng sử dụng
+type VehicleRetiredEvent struct {
+	BaseEvent
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewVehicleRetiredEvent tạo một VehicleRetiredEvent mới
+func NewVehicleRetiredEvent(base BaseEvent, reason string) VehicleRetiredEvent {
+	base.Type = VehicleRetiredType
+	return VehicleRetiredEvent{
+		BaseEvent: base,
+		Reason:    reason,
+	}
+}
+
+func applyVehicleRetired(vehicle *Vehicle, e VehicleRetiredEvent) {
+	vehicle.Status = VehicleStatusRetired
+	vehicle.UpdatedAt = e.Timestamp
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// AsOf xác định một thời điểm trong lịch sử của aggregate theo thời gian hoặc theo phiên bản.
+// Phiên bản là vị trí của sự kiện trong luồng, bắt đầu từ 1. Giá trị rỗng là trạng thái mới nhất.
+type AsOf struct {
+	Time    time.Time
+	Version int
+}
+
+// IsZero kiểm tra thời điểm có được chỉ định hay không
+func (a AsOf) IsZero() bool {
+	return a.Time.IsZero() && a.Version == 0
+}
+
+// EventsAsOf trả về các sự kiện đã xảy ra tính đến thời điểm asOf
+func EventsAsOf(events []Event, asOf AsOf) []Event {
+	if asOf.IsZero() {
+		return events
+	}
+
+	for i, event := range events {
+		if asOf.Version > 0 && i+1 > asOf.Version {
+			return events[:i]
+		}
+		if !asOf.Time.IsZero() && event.GetTimestamp().After(asOf.Time) {
+			return events[:i]
+		}
+	}
+
+	return events
+}
+
+// FieldChange mô tả thay đổi của một trường giữa hai trạng thái đơn hàng
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffOrders so sánh hai trạng thái đơn hàng theo từng trường JSON, nil nghĩa là đơn hàng chưa tồn tại
+func DiffOrders(from, to *Order) ([]FieldChange, error) {
+	fromFields, err := orderFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := orderFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(toFields))
+	for name := range fromFields {
+		names[name] = struct{}{}
+	}
+	for name := range toFields {
+		names[name] = struct{}{}
+	}
+
+	changes := make([]FieldChange, 0)
+	for name := range names {
+		if reflect.DeepEqual(fromFields[name], toFields[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Field: name,
+			From:  fromFields[name],
+			To:    toFields[name],
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes, nil
+}
+
+// orderFields chuyển đơn hàng thành map các trường JSON để so sánh
+func orderFields(order *Order) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if order == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
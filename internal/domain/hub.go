@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// ScanIn ghi nhận đơn hàng được quét vào hub hubCode tên hubName đặt tại point.
+// Đơn hàng phải được quét ra khỏi hub trước đó mới được quét vào hub khác.
+func (o *Order) ScanIn(hubCode, hubName string, point geo.Point) error {
+	if hubCode == "" {
+		return errors.New("mã hub không được để trống")
+	}
+	if o.Status == OrderStatusDelivered {
+		return errors.New("không thể quét đơn hàng đã giao")
+	}
+	if o.CurrentHub == hubCode {
+		return fmt.Errorf("đơn hàng đã ở hub %s", hubCode)
+	}
+	if o.CurrentHub != "" {
+		return fmt.Errorf("đơn hàng đang ở hub %s, cần quét ra trước", o.CurrentHub)
+	}
+	if !point.Valid() {
+		return errors.New("tọa độ của hub không hợp lệ")
+	}
+
+	o.raise(NewParcelScannedInEvent(o.newBaseEvent(ParcelScannedInType), hubCode, hubName, point.Latitude, point.Longitude))
+
+	return nil
+}
+
+// ScanOut ghi nhận đơn hàng được quét ra khỏi hub hubCode
+func (o *Order) ScanOut(hubCode string) error {
+	if o.CurrentHub == "" || o.CurrentHub != hubCode {
+		return fmt.Errorf("đơn hàng không ở hub %s", hubCode)
+	}
+
+	o.raise(NewParcelScannedOutEvent(o.newBaseEvent(ParcelScannedOutType), hubCode))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+func TestScanIn(t *testing.T) {
+	point := geo.Point{Latitude: 21.0285, Longitude: 105.8542}
+
+	domaintest.Given(t, domaintest.Created()).
+		When(func(order *domain.Order) error {
+			return order.ScanIn("HN-01", "Kho Hà Nội", point)
+		}).
+		Then(domain.ParcelScannedInEvent{
+			BaseEvent: domaintest.Emitted(domain.ParcelScannedInType, 2, "id-1"),
+			HubCode:   "HN-01",
+			HubName:   "Kho Hà Nội",
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			want := domain.Location{Address: "Kho Hà Nội", Latitude: point.Latitude, Longitude: point.Longitude}
+			if order.CurrentHub != "HN-01" || order.CurrentLocation == nil || *order.CurrentLocation != want {
+				t.Fatalf("CurrentHub = %q, CurrentLocation = %+v", order.CurrentHub, order.CurrentLocation)
+			}
+		}).
+		ThenRebuilds()
+
+	// Địa chỉ là tên hub, thành phố giữ theo vị trí trước đó
+	inHanoi := domain.Location{Address: "Bưu cục Hoàn Kiếm", City: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542}
+	domaintest.Given(t, domaintest.Created(),
+		domain.NewOrderStatusUpdatedEvent(domaintest.Base(domain.OrderStatusUpdatedType, 2), domain.OrderStatusCreated, domain.OrderStatusInTransit, &inHanoi, "")).
+		When(func(order *domain.Order) error {
+			return order.ScanIn("HN-01", "Kho Hà Nội", point)
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			want := domain.Location{Address: "Kho Hà Nội", City: "Hà Nội", Latitude: point.Latitude, Longitude: point.Longitude}
+			if order.CurrentLocation == nil || *order.CurrentLocation != want {
+				t.Fatalf("CurrentLocation = %+v", order.CurrentLocation)
+			}
+		}).
+		ThenRebuilds()
+
+	// Sự kiện cũ không có tên hub dùng mã hub làm địa chỉ
+	legacy := domain.RebuildFromEvents([]domain.Event{domaintest.Created(), domaintest.ScannedIn(2, "HCM-01")})
+	if legacy.CurrentLocation == nil || legacy.CurrentLocation.Address != "Hub HCM-01" {
+		t.Fatalf("CurrentLocation = %+v", legacy.CurrentLocation)
+	}
+
+	domaintest.Given(t, domaintest.Created(), domaintest.ScannedIn(2, "HCM-01")).
+		When(func(order *domain.Order) error {
+			return order.ScanIn("HCM-01", "Kho Hồ Chí Minh", point)
+		}).
+		ThenError("đã ở hub HCM-01")
+
+	domaintest.Given(t, domaintest.Created(), domaintest.ScannedIn(2, "HCM-01")).
+		When(func(order *domain.Order) error {
+			return order.ScanIn("HN-01", "Kho Hà Nội", point)
+		}).
+		ThenError("cần quét ra trước")
+
+	domaintest.Given(t, domaintest.Created(), domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error {
+			return order.ScanIn("HN-01", "Kho Hà Nội", point)
+		}).
+		ThenError("đơn hàng đã giao")
+}
+
+func TestScanOut(t *testing.T) {
+	domaintest.Given(t, domaintest.Created(), domaintest.ScannedIn(2, "HCM-01")).
+		When(func(order *domain.Order) error {
+			return order.ScanOut("HCM-01")
+		}).
+		Then(domain.ParcelScannedOutEvent{
+			BaseEvent: domaintest.Emitted(domain.ParcelScannedOutType, 3, "id-1"),
+			HubCode:   "HCM-01",
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.CurrentHub != "" {
+				t.Fatalf("CurrentHub = %q", order.CurrentHub)
+			}
+		}).
+		ThenRebuilds()
+
+	domaintest.Given(t, domaintest.Created(), domaintest.ScannedIn(2, "HCM-01")).
+		When(func(order *domain.Order) error {
+			return order.ScanOut("HN-01")
+		}).
+		ThenError("không ở hub HN-01")
+
+	// Đơn hàng đã giao được coi là đã rời hub
+	domaintest.Given(t, domaintest.Created(), domaintest.ScannedIn(2, "HCM-01"),
+		domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error {
+			return order.ScanOut("HCM-01")
+		}).
+		ThenError("không ở hub HCM-01")
+}
@@ -2,9 +2,11 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // OrderStatus định nghĩa trạng thái của đơn hàng logistics
@@ -22,7 +24,7 @@ const (
 
 // Location đại diện cho vị trí địa lý
 type Location struct {
-	Address   string  `json:"address"`
+	Address   string  `json:"address" pii:"true"`
 	City      string  `json:"city"`
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
@@ -42,20 +44,62 @@ type Order struct {
 	Items           []OrderItem `json:"items"`
 	Notes           []string    `json:"notes"`
 	Events          []Event     `json:"-"` // Events không được serialize
+
+	ServiceLevel      ServiceLevel `json:"service_level"`
+	EstimatedDelivery *time.Time   `json:"estimated_delivery,omitempty"`
+	PromisedDelivery  *time.Time   `json:"promised_delivery,omitempty"` // thời gian cam kết, cố định từ lần ước tính đầu tiên
+	SLABreached       bool         `json:"sla_breached"`
+
+	// Currency là mã tiền tệ ISO 4217 của giá mặt hàng và phí vận chuyển
+	Currency    string           `json:"currency"`
+	Totals      OrderTotals      `json:"totals"`
+	ShippingFee *decimal.Decimal `json:"shipping_fee,omitempty"` // nil khi đơn hàng chưa được tính phí vận chuyển
+	Payment     *Payment         `json:"payment,omitempty"`      // nil với đơn hàng không thu hộ
+
+	// DriverID và VehicleID là tài xế và phương tiện đang được phân công, rỗng khi chưa phân công
+	DriverID   string     `json:"driver_id,omitempty"`
+	VehicleID  string     `json:"vehicle_id,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+
+	// CurrentHub là mã hub đơn hàng đã quét vào nhưng chưa quét ra, rỗng khi không ở hub nào
+	CurrentHub string `json:"current_hub,omitempty"`
+
+	clock     Clock
+	ids       IDGenerator
+	estimator DeliveryEstimator
+	pricer    ShippingFeeCalculator
+	terms     PaymentTerms // chỉ dùng khi tạo đơn hàng
+	version   int          // số sự kiện đã áp dụng, kể cả sự kiện chưa commit
 }
 
 // OrderItem đại diện cho một mục trong đơn hàng
 type OrderItem struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Quantity    int     `json:"quantity"`
-	Weight      float64 `json:"weight"`
-	Price       float64 `json:"price"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Quantity    int             `json:"quantity"`
+	Weight      decimal.Decimal `json:"weight"` // kg cho một đơn vị
+	Price       decimal.Decimal `json:"price"`  // đơn giá theo tiền tệ của đơn hàng
+
+	// ProductUID là sản phẩm trong danh mục, rỗng với mặt hàng nhập tay
+	ProductUID string `json:"product_uid,omitempty"`
+	// Product là ảnh chụp sản phẩm khi tạo đơn, không thay đổi khi danh mục được cập nhật
+	Product *ProductSnapshot `json:"product,omitempty"`
+}
+
+// ProductSnapshot là thông tin sản phẩm trong danh mục tại thời điểm tạo đơn hàng
+type ProductSnapshot struct {
+	Reference    string          `json:"reference"`
+	Name         string          `json:"name"`
+	Price        decimal.Decimal `json:"price"`
+	Weight       decimal.Decimal `json:"weight"`
+	CityID       string          `json:"city_id"`
+	SupplierID   string          `json:"supplier_id"`
+	SupplierName string          `json:"supplier_name"`
 }
 
 // NewOrder tạo đơn hàng mới
-func NewOrder(customerID string, origin, destination Location, items []OrderItem) (*Order, error) {
+func NewOrder(customerID string, origin, destination Location, items []OrderItem, opts ...OrderOption) (*Order, error) {
 	if customerID == "" {
 		return nil, errors.New("customer ID không được để trống")
 	}
@@ -64,26 +108,35 @@ func NewOrder(customerID string, origin, destination Location, items []OrderItem
 		return nil, errors.New("đơn hàng phải có ít nhất một mục")
 	}
 
-	now := time.Now()
-	orderID := uuid.New().String()
-	trackingNumber := generateTrackingNumber()
+	for _, item := range items {
+		if item.Price.IsNegative() || item.Weight.IsNegative() {
+			return nil, fmt.Errorf("giá và khối lượng của mặt hàng %s không được âm", item.ID)
+		}
+	}
 
-	order := &Order{
-		ID:             orderID,
-		CustomerID:     customerID,
-		TrackingNumber: trackingNumber,
-		Status:         OrderStatusCreated,
-		Origin:         origin,
-		Destination:    destination,
-		CreatedAt:      now,
-		UpdatedAt:      now,
-		Items:          items,
-		Notes:          []string{},
+	order := &Order{}
+	order.apply(opts...)
+	if order.ServiceLevel != "" && !order.ServiceLevel.Valid() {
+		return nil, fmt.Errorf("mức dịch vụ không hợp lệ: %s", order.ServiceLevel)
+	}
+	if order.Currency == "" {
+		order.Currency = DefaultCurrency
 	}
+	if !ValidCurrency(order.Currency) {
+		return nil, fmt.Errorf("mã tiền tệ không hợp lệ: %s", order.Currency)
+	}
+	if err := order.terms.validate(); err != nil {
+		return nil, err
+	}
+	serviceLevel, currency, terms := order.ServiceLevel, order.Currency, order.terms
+	order.ID = order.newID()
+	trackingNumber := generateTrackingNumber(order.newID())
 
 	// Tạo event OrderCreated
-	event := NewOrderCreatedEvent(orderID, customerID, trackingNumber, origin, destination, items)
-	order.Events = append(order.Events, event)
+	order.raise(NewOrderCreatedEvent(order.newBaseEvent(OrderCreatedType), customerID, trackingNumber, origin, destination, items, serviceLevel, currency))
+	order.priceShipping()
+	order.requestPayment(terms)
+	order.estimateDelivery()
 
 	return order, nil
 }
@@ -93,22 +146,18 @@ func (o *Order) UpdateStatus(newStatus OrderStatus, location *Location, note str
 	if o.Status == OrderStatusDelivered || o.Status == OrderStatusCancelled {
 		return errors.New("không thể cập nhật trạng thái cho đơn hàng đã hoàn thành hoặc đã hủy")
 	}
-
-	oldStatus := o.Status
-	o.Status = newStatus
-	o.UpdatedAt = time.Now()
-
-	if location != nil {
-		o.CurrentLocation = location
-	}
-
-	if note != "" {
-		o.Notes = append(o.Notes, note)
+	if newStatus == OrderStatusOutForDelivery && o.DriverID == "" {
+		return errors.New("cần phân công tài xế trước khi giao hàng")
 	}
 
 	// Tạo event OrderStatusUpdated
-	event := NewOrderStatusUpdatedEvent(o.ID, oldStatus, newStatus, location, note)
-	o.Events = append(o.Events, event)
+	o.raise(NewOrderStatusUpdatedEvent(o.newBaseEvent(OrderStatusUpdatedType), o.Status, newStatus, location, note))
+
+	// Giao muộn hơn thời gian cam kết mà chưa được ghi nhận thì ghi nhận vi phạm SLA ngay khi giao
+	if newStatus == OrderStatusDelivered && !o.SLABreached && o.PromisedDelivery != nil && o.UpdatedAt.After(*o.PromisedDelivery) {
+		o.raise(NewSLABreachedEvent(o.newBaseEvent(SLABreachedType), *o.PromisedDelivery, newStatus))
+	}
+	o.estimateDelivery()
 
 	return nil
 }
@@ -123,17 +172,11 @@ func (o *Order) CancelOrder(reason string) error {
 		return errors.New("đơn hàng đã bị hủy trước đó")
 	}
 
-	oldStatus := o.Status
-	o.Status = OrderStatusCancelled
-	o.UpdatedAt = time.Now()
-
-	if reason != "" {
-		o.Notes = append(o.Notes, reason)
-	}
-
 	// Tạo event OrderCancelled
-	event := NewOrderCancelledEvent(o.ID, oldStatus, reason)
-	o.Events = append(o.Events, event)
+	o.raise(NewOrderCancelledEvent(o.newBaseEvent(OrderCancelledType), o.Status, reason))
+
+	// Người nhận đã trả tiền thu hộ thì cần được hoàn tiền
+	o.requireRefund()
 
 	return nil
 }
@@ -144,16 +187,17 @@ func (o *Order) AddNote(note string) error {
 		return errors.New("ghi chú không được để trống")
 	}
 
-	o.Notes = append(o.Notes, note)
-	o.UpdatedAt = time.Now()
-
 	// Tạo event OrderNoteAdded
-	event := NewOrderNoteAddedEvent(o.ID, note)
-	o.Events = append(o.Events, event)
+	o.raise(NewOrderNoteAddedEvent(o.newBaseEvent(OrderNoteAddedType), note))
 
 	return nil
 }
 
+// Version trả về phiên bản hiện tại của đơn hàng, bằng số sự kiện đã áp dụng
+func (o *Order) Version() int {
+	return o.version
+}
+
 // GetUncommittedEvents trả về các sự kiện chưa được commit
 func (o *Order) GetUncommittedEvents() []Event {
 	return o.Events
@@ -164,9 +208,60 @@ func (o *Order) ClearUncommittedEvents() {
 	o.Events = []Event{}
 }
 
-// generateTrackingNumber tạo số theo dõi đơn hàng
-func generateTrackingNumber() string {
-	// Trong thực tế, bạn sẽ muốn tạo một số theo dõi duy nhất và có ý nghĩa
-	// Đây là một cách đơn giản để minh họa
-	return "TRK-" + uuid.New().String()[0:8]
+// apply áp dụng các tùy chọn cho đơn hàng
+func (o *Order) apply(opts ...OrderOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// raise áp dụng sự kiện mới lên đơn hàng và ghi nhận là sự kiện chưa commit
+func (o *Order) raise(event Event) {
+	clock, ids, estimator, pricer, events, version := o.clock, o.ids, o.estimator, o.pricer, o.Events, o.version
+
+	if descriptor, ok := LookupEvent(event.GetType()); ok {
+		if applied := descriptor.Apply(o, event); applied != nil && applied != o {
+			*o = *applied
+		}
+	}
+
+	o.clock, o.ids, o.estimator, o.pricer = clock, ids, estimator, pricer
+	o.Events = append(events, event)
+	o.version = version + 1
+}
+
+// newBaseEvent tạo phần chung cho sự kiện tiếp theo của đơn hàng
+func (o *Order) newBaseEvent(eventType EventType) BaseEvent {
+	return BaseEvent{
+		ID:          o.newID(),
+		AggregateID: o.ID,
+		Type:        eventType,
+		Timestamp:   o.now(),
+		Version:     o.version + 1,
+	}
+}
+
+// now trả về thời gian hiện tại theo clock của đơn hàng
+func (o *Order) now() time.Time {
+	if o.clock == nil {
+		return SystemClock.Now()
+	}
+	return o.clock.Now()
+}
+
+// newID sinh ID mới theo bộ sinh ID của đơn hàng
+func (o *Order) newID() string {
+	if o.ids == nil {
+		return UUIDGenerator.NewID()
+	}
+	return o.ids.NewID()
+}
+
+// generateTrackingNumber tạo số theo dõi đơn hàng từ 8 ký tự cuối của một ID ngẫu nhiên
+func generateTrackingNumber(id string) string {
+	id = strings.ToUpper(strings.ReplaceAll(id, "-", ""))
+	if len(id) > 8 {
+		id = id[len(id)-8:]
+	}
+	return "TRK-" + id
 }
@@ -1,35 +1,88 @@
 package domain
 
 import (
-	"github.com/google/uuid"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+func init() {
+	RegisterEvent(OrderCreatedType, applyOrderCreated, describeOrderCreated)
+	RegisterEvent(OrderStatusUpdatedType, onExistingOrder(applyOrderStatusUpdated), describeOrderStatusUpdated)
+	RegisterEvent(OrderCancelledType, onExistingOrder(applyOrderCancelled), describeOrderCancelled)
+	RegisterEvent(OrderNoteAddedType, onExistingOrder(applyOrderNoteAdded), describeOrderNoteAdded)
+	RegisterEvent(DeliveryEstimatedType, onExistingOrder(applyDeliveryEstimated), describeDeliveryEstimated)
+	RegisterEvent(SLABreachedType, onExistingOrder(applySLABreached), describeSLABreached)
+	RegisterEvent(OrderPricedType, onExistingOrder(applyOrderPriced), describeOrderPriced)
+	RegisterEvent(PaymentPendingType, onExistingOrder(applyPaymentPending), describePaymentPending)
+	RegisterEvent(PaymentCollectedType, onExistingOrder(applyPaymentCollected), describePaymentCollected)
+	RegisterEvent(PaymentRemittedType, onExistingOrder(applyPaymentRemitted), describePaymentRemitted)
+	RegisterEvent(PaymentRefundRequiredType, onExistingOrder(applyPaymentRefundRequired), describePaymentRefundRequired)
+	RegisterEvent(PaymentRefundedType, onExistingOrder(applyPaymentRefunded), describePaymentRefunded)
+	RegisterEvent(OrderAssignedToDriverType, onExistingOrder(applyOrderAssignedToDriver), describeOrderAssignedToDriver)
+	RegisterEvent(OrderUnassignedType, onExistingOrder(applyOrderUnassigned), describeOrderUnassigned)
+	RegisterEvent(OrderLocationRecordedType, onExistingOrder(applyOrderLocationRecorded), describeOrderLocationRecorded)
+	RegisterEvent(ParcelScannedInType, onExistingOrder(applyParcelScannedIn), describeParcelScannedIn)
+	RegisterEvent(ParcelScannedOutType, onExistingOrder(applyParcelScannedOut), describeParcelScannedOut)
+}
+
 // OrderCreatedEvent là sự kiện khi đơn hàng được tạo
 type OrderCreatedEvent struct {
 	BaseEvent
-	CustomerID     string      `json:"customer_id"`
+	CustomerID     string      `json:"customer_id" pii:"subject"`
 	TrackingNumber string      `json:"tracking_number"`
 	Origin         Location    `json:"origin"`
 	Destination    Location    `json:"destination"`
 	Items          []OrderItem `json:"items"`
+
+	// ServiceLevel rỗng với các sự kiện được tạo trước khi có mức dịch vụ, tương đương STANDARD
+	ServiceLevel ServiceLevel `json:"service_level,omitempty"`
+	Currency     string       `json:"currency"`
 }
 
 // NewOrderCreatedEvent tạo một OrderCreatedEvent mới
-func NewOrderCreatedEvent(orderID, customerID, trackingNumber string, origin, destination Location, items []OrderItem) OrderCreatedEvent {
+func NewOrderCreatedEvent(base BaseEvent, customerID, trackingNumber string, origin, destination Location, items []OrderItem, serviceLevel ServiceLevel, currency string) OrderCreatedEvent {
+	base.Type = OrderCreatedType
 	return OrderCreatedEvent{
-		BaseEvent: BaseEvent{
-			ID:          uuid.New().String(),
-			AggregateID: orderID,
-			Type:        OrderCreatedType,
-			Timestamp:   time.Now(),
-			Version:     1,
-		},
+		BaseEvent:      base,
 		CustomerID:     customerID,
 		TrackingNumber: trackingNumber,
 		Origin:         origin,
 		Destination:    destination,
 		Items:          items,
+		ServiceLevel:   serviceLevel,
+		Currency:       currency,
+	}
+}
+
+// applyOrderCreated tạo đơn hàng mới từ sự kiện đầu tiên phải là OrderCreated
+func applyOrderCreated(_ *Order, e OrderCreatedEvent) *Order {
+	serviceLevel := e.ServiceLevel
+	if serviceLevel == "" {
+		serviceLevel = ServiceLevelStandard
+	}
+
+	return &Order{
+		ID:             e.AggregateID,
+		CustomerID:     e.CustomerID,
+		TrackingNumber: e.TrackingNumber,
+		Status:         OrderStatusCreated,
+		Origin:         e.Origin,
+		Destination:    e.Destination,
+		Items:          e.Items,
+		CreatedAt:      e.Timestamp,
+		UpdatedAt:      e.Timestamp,
+		Notes:          []string{},
+		ServiceLevel:   serviceLevel,
+		Currency:       e.Currency,
+		Totals:         CalculateTotals(e.Items),
+	}
+}
+
+func describeOrderCreated(_ OrderCreatedEvent) EventDescription {
+	return EventDescription{
+		Status: OrderStatusCreated,
+		Note:   "Đơn hàng được tạo",
 	}
 }
 
@@ -39,19 +92,14 @@ type OrderStatusUpdatedEvent struct {
 	OldStatus       OrderStatus `json:"old_status"`
 	NewStatus       OrderStatus `json:"new_status"`
 	CurrentLocation *Location   `json:"current_location,omitempty"`
-	Note            string      `json:"note,omitempty"`
+	Note            string      `json:"note,omitempty" pii:"true"`
 }
 
 // NewOrderStatusUpdatedEvent tạo một OrderStatusUpdatedEvent mới
-func NewOrderStatusUpdatedEvent(orderID string, oldStatus, newStatus OrderStatus, location *Location, note string) OrderStatusUpdatedEvent {
+func NewOrderStatusUpdatedEvent(base BaseEvent, oldStatus, newStatus OrderStatus, location *Location, note string) OrderStatusUpdatedEvent {
+	base.Type = OrderStatusUpdatedType
 	return OrderStatusUpdatedEvent{
-		BaseEvent: BaseEvent{
-			ID:          uuid.New().String(),
-			AggregateID: orderID,
-			Type:        OrderStatusUpdatedType,
-			Timestamp:   time.Now(),
-			Version:     1, // Trong thực tế, phiên bản nên tăng dần
-		},
+		BaseEvent:       base,
 		OldStatus:       oldStatus,
 		NewStatus:       newStatus,
 		CurrentLocation: location,
@@ -59,6 +107,30 @@ func NewOrderStatusUpdatedEvent(orderID string, oldStatus, newStatus OrderStatus
 	}
 }
 
+func applyOrderStatusUpdated(order *Order, e OrderStatusUpdatedEvent) {
+	order.Status = e.NewStatus
+	order.UpdatedAt = e.Timestamp
+	// Đơn hàng đã giao không còn ở hub, kể cả khi thiếu lần quét ra
+	if e.NewStatus == OrderStatusDelivered {
+		order.CurrentHub = ""
+	}
+	if e.CurrentLocation != nil {
+		order.CurrentLocation = e.CurrentLocation
+	}
+	if e.Note != "" {
+		order.Notes = append(order.Notes, e.Note)
+	}
+}
+
+func describeOrderStatusUpdated(e OrderStatusUpdatedEvent) EventDescription {
+	return EventDescription{
+		Status:     e.NewStatus,
+		PrevStatus: e.OldStatus,
+		Location:   e.CurrentLocation,
+		Note:       e.Note,
+	}
+}
+
 // OrderCancelledEvent là sự kiện khi đơn hàng bị hủy
 type OrderCancelledEvent struct {
 	BaseEvent
@@ -67,36 +139,510 @@ type OrderCancelledEvent struct {
 }
 
 // NewOrderCancelledEvent tạo một OrderCancelledEvent mới
-func NewOrderCancelledEvent(orderID string, previousStatus OrderStatus, reason string) OrderCancelledEvent {
+func NewOrderCancelledEvent(base BaseEvent, previousStatus OrderStatus, reason string) OrderCancelledEvent {
+	base.Type = OrderCancelledType
 	return OrderCancelledEvent{
-		BaseEvent: BaseEvent{
-			ID:          uuid.New().String(),
-			AggregateID: orderID,
-			Type:        OrderCancelledType,
-			Timestamp:   time.Now(),
-			Version:     1, // Trong thực tế, phiên bản nên tăng dần
-		},
+		BaseEvent:      base,
 		PreviousStatus: previousStatus,
 		Reason:         reason,
 	}
 }
 
+func applyOrderCancelled(order *Order, e OrderCancelledEvent) {
+	order.Status = OrderStatusCancelled
+	order.UpdatedAt = e.Timestamp
+	if e.Reason != "" {
+		order.Notes = append(order.Notes, e.Reason)
+	}
+}
+
+func describeOrderCancelled(e OrderCancelledEvent) EventDescription {
+	return EventDescription{
+		Status:     OrderStatusCancelled,
+		PrevStatus: e.PreviousStatus,
+		Note:       e.Reason,
+	}
+}
+
 // OrderNoteAddedEvent là sự kiện khi ghi chú được thêm vào đơn hàng
 type OrderNoteAddedEvent struct {
 	BaseEvent
-	Note string `json:"note"`
+	Note string `json:"note" pii:"true"`
 }
 
 // NewOrderNoteAddedEvent tạo một OrderNoteAddedEvent mới
-func NewOrderNoteAddedEvent(orderID string, note string) OrderNoteAddedEvent {
+func NewOrderNoteAddedEvent(base BaseEvent, note string) OrderNoteAddedEvent {
+	base.Type = OrderNoteAddedType
 	return OrderNoteAddedEvent{
-		BaseEvent: BaseEvent{
-			ID:          uuid.New().String(),
-			AggregateID: orderID,
-			Type:        OrderNoteAddedType,
-			Timestamp:   time.Now(),
-			Version:     1, // Trong thực tế, phiên bản nên tăng dần
-		},
-		Note: note,
+		BaseEvent: base,
+		Note:      note,
+	}
+}
+
+func applyOrderNoteAdded(order *Order, e OrderNoteAddedEvent) {
+	order.Notes = append(order.Notes, e.Note)
+	order.UpdatedAt = e.Timestamp
+}
+
+func describeOrderNoteAdded(e OrderNoteAddedEvent) EventDescription {
+	return EventDescription{
+		Note: e.Note,
+	}
+}
+
+// DeliveryEstimatedEvent là sự kiện khi thời gian giao hàng dự kiến được tính lại
+type DeliveryEstimatedEvent struct {
+	BaseEvent
+	EstimatedDelivery time.Time `json:"estimated_delivery"`
+	PromisedDelivery  time.Time `json:"promised_delivery"`
+}
+
+// NewDeliveryEstimatedEvent tạo một DeliveryEstimatedEvent mới
+func NewDeliveryEstimatedEvent(base BaseEvent, estimated, promised time.Time) DeliveryEstimatedEvent {
+	base.Type = DeliveryEstimatedType
+	return DeliveryEstimatedEvent{
+		BaseEvent:         base,
+		EstimatedDelivery: estimated,
+		PromisedDelivery:  promised,
+	}
+}
+
+// applyDeliveryEstimated không đổi UpdatedAt vì ETA là dữ liệu suy ra từ các thay đổi khác
+func applyDeliveryEstimated(order *Order, e DeliveryEstimatedEvent) {
+	estimated := e.EstimatedDelivery
+	order.EstimatedDelivery = &estimated
+	if order.PromisedDelivery == nil {
+		promised := e.PromisedDelivery
+		order.PromisedDelivery = &promised
+	}
+}
+
+func describeDeliveryEstimated(e DeliveryEstimatedEvent) EventDescription {
+	return EventDescription{
+		Note: "Dự kiến giao hàng lúc " + e.EstimatedDelivery.Format(time.RFC3339),
+	}
+}
+
+// SLABreachedEvent là sự kiện khi đơn hàng quá thời gian giao hàng cam kết
+type SLABreachedEvent struct {
+	BaseEvent
+	PromisedDelivery time.Time   `json:"promised_delivery"`
+	Status           OrderStatus `json:"status"` // trạng thái của đơn hàng khi vi phạm
+}
+
+// NewSLABreachedEvent tạo một SLABreachedEvent mới
+func NewSLABreachedEvent(base BaseEvent, promised time.Time, status OrderStatus) SLABreachedEvent {
+	base.Type = SLABreachedType
+	return SLABreachedEvent{
+		BaseEvent:        base,
+		PromisedDelivery: promised,
+		Status:           status,
+	}
+}
+
+func applySLABreached(order *Order, _ SLABreachedEvent) {
+	order.SLABreached = true
+}
+
+func describeSLABreached(e SLABreachedEvent) EventDescription {
+	return EventDescription{
+		Note: "Quá thời gian giao hàng cam kết " + e.PromisedDelivery.Format(time.RFC3339),
+	}
+}
+
+// OrderPricedEvent là sự kiện khi phí vận chuyển của đơn hàng được tính
+type OrderPricedEvent struct {
+	BaseEvent
+	ShippingFee      decimal.Decimal `json:"shipping_fee"`
+	Currency         string          `json:"currency"`
+	ChargeableWeight decimal.Decimal `json:"chargeable_weight"` // kg, khối lượng dùng để tính phí
+	DistanceKm       float64         `json:"distance_km"`
+}
+
+// NewOrderPricedEvent tạo một OrderPricedEvent mới
+func NewOrderPricedEvent(base BaseEvent, quote ShippingQuote) OrderPricedEvent {
+	base.Type = OrderPricedType
+	return OrderPricedEvent{
+		BaseEvent:        base,
+		ShippingFee:      quote.Fee,
+		Currency:         quote.Currency,
+		ChargeableWeight: quote.ChargeableWeight,
+		DistanceKm:       quote.DistanceKm,
+	}
+}
+
+// applyOrderPriced không đổi UpdatedAt vì phí vận chuyển được tính ngay khi tạo đơn hàng
+func applyOrderPriced(order *Order, e OrderPricedEvent) {
+	fee := e.ShippingFee
+	order.ShippingFee = &fee
+}
+
+func describeOrderPriced(e OrderPricedEvent) EventDescription {
+	return EventDescription{
+		Note: "Phí vận chuyển " + e.ShippingFee.String() + " " + e.Currency,
+	}
+}
+
+// PaymentPendingEvent là sự kiện khi đơn hàng COD được tạo và chờ thu tiền
+type PaymentPendingEvent struct {
+	BaseEvent
+	Method   PaymentMethod   `json:"method"`
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// NewPaymentPendingEvent tạo một PaymentPendingEvent mới
+func NewPaymentPendingEvent(base BaseEvent, method PaymentMethod, amount decimal.Decimal, currency string) PaymentPendingEvent {
+	base.Type = PaymentPendingType
+	return PaymentPendingEvent{
+		BaseEvent: base,
+		Method:    method,
+		Amount:    amount,
+		Currency:  currency,
+	}
+}
+
+// applyPaymentPending không đổi UpdatedAt vì sự kiện được tạo cùng lúc với đơn hàng
+func applyPaymentPending(order *Order, e PaymentPendingEvent) {
+	order.Payment = &Payment{
+		Method:    e.Method,
+		Status:    PaymentStatusPending,
+		Amount:    e.Amount,
+		Currency:  e.Currency,
+		Collected: decimal.Zero,
+	}
+}
+
+func describePaymentPending(e PaymentPendingEvent) EventDescription {
+	return EventDescription{
+		Note: "Thu hộ " + e.Amount.String() + " " + e.Currency,
+	}
+}
+
+// PaymentCollectedEvent là sự kiện khi tài xế thu tiền của người nhận
+type PaymentCollectedEvent struct {
+	BaseEvent
+	DriverID string          `json:"driver_id"`
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// NewPaymentCollectedEvent tạo một PaymentCollectedEvent mới
+func NewPaymentCollectedEvent(base BaseEvent, driverID string, amount decimal.Decimal, currency string) PaymentCollectedEvent {
+	base.Type = PaymentCollectedType
+	return PaymentCollectedEvent{
+		BaseEvent: base,
+		DriverID:  driverID,
+		Amount:    amount,
+		Currency:  currency,
+	}
+}
+
+func applyPaymentCollected(order *Order, e PaymentCollectedEvent) {
+	payment := order.paymentCopy()
+	collectedAt := e.Timestamp
+	payment.Status = PaymentStatusCollected
+	payment.Collected = e.Amount
+	payment.DriverID = e.DriverID
+	payment.CollectedAt = &collectedAt
+	order.Payment = payment
+	order.UpdatedAt = e.Timestamp
+}
+
+func describePaymentCollected(e PaymentCollectedEvent) EventDescription {
+	return EventDescription{
+		Note: "Tài xế " + e.DriverID + " đã thu " + e.Amount.String() + " " + e.Currency,
+	}
+}
+
+// PaymentRemittedEvent là sự kiện khi tài xế nộp tiền thu hộ của đơn hàng
+type PaymentRemittedEvent struct {
+	BaseEvent
+	DriverID  string          `json:"driver_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	Currency  string          `json:"currency"`
+	Reference string          `json:"reference,omitempty"` // mã phiếu nộp tiền
+}
+
+// NewPaymentRemittedEvent tạo một PaymentRemittedEvent mới
+func NewPaymentRemittedEvent(base BaseEvent, driverID string, amount decimal.Decimal, currency, reference string) PaymentRemittedEvent {
+	base.Type = PaymentRemittedType
+	return PaymentRemittedEvent{
+		BaseEvent: base,
+		DriverID:  driverID,
+		Amount:    amount,
+		Currency:  currency,
+		Reference: reference,
+	}
+}
+
+// applyPaymentRemitted giữ trạng thái cần hoàn tiền nếu đơn hàng đã bị hủy trước khi tài xế nộp tiền
+func applyPaymentRemitted(order *Order, e PaymentRemittedEvent) {
+	payment := order.paymentCopy()
+	remittedAt := e.Timestamp
+	if payment.Status == PaymentStatusCollected {
+		payment.Status = PaymentStatusRemitted
+	}
+	payment.RemittedAt = &remittedAt
+	order.Payment = payment
+	order.UpdatedAt = e.Timestamp
+}
+
+func describePaymentRemitted(e PaymentRemittedEvent) EventDescription {
+	return EventDescription{
+		Note: "Tài xế " + e.DriverID + " đã nộp " + e.Amount.String() + " " + e.Currency,
+	}
+}
+
+// PaymentRefundRequiredEvent là sự kiện khi đơn hàng đã thanh toán bị hủy
+type PaymentRefundRequiredEvent struct {
+	BaseEvent
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+
+	// Method là PREPAID khi hoàn phí vận chuyển người gửi đã trả trước, rỗng khi hoàn tiền thu hộ của người nhận
+	Method PaymentMethod `json:"method,omitempty"`
+}
+
+// NewPaymentRefundRequiredEvent tạo một PaymentRefundRequiredEvent mới
+func NewPaymentRefundRequiredEvent(base BaseEvent, amount decimal.Decimal, currency string) PaymentRefundRequiredEvent {
+	base.Type = PaymentRefundRequiredType
+	return PaymentRefundRequiredEvent{
+		BaseEvent: base,
+		Amount:    amount,
+		Currency:  currency,
+	}
+}
+
+func applyPaymentRefundRequired(order *Order, e PaymentRefundRequiredEvent) {
+	payment := order.paymentCopy()
+	// Đơn hàng trả trước không có trạng thái thanh toán, số tiền đã trả là số tiền cần hoàn
+	if order.Payment == nil && e.Method == PaymentMethodPrepaid {
+		payment = &Payment{Method: PaymentMethodPrepaid, Amount: e.Amount, Currency: e.Currency, Collected: e.Amount}
+	}
+	payment.Status = PaymentStatusRefundRequired
+	order.Payment = payment
+	order.UpdatedAt = e.Timestamp
+}
+
+func describePaymentRefundRequired(e PaymentRefundRequiredEvent) EventDescription {
+	recipient := "người nhận"
+	if e.Method == PaymentMethodPrepaid {
+		recipient = "người gửi"
+	}
+	return EventDescription{
+		Note: "Cần hoàn " + e.Amount.String() + " " + e.Currency + " cho " + recipient,
+	}
+}
+
+// PaymentRefundedEvent là sự kiện khi tiền thu hộ đã được hoàn cho người nhận
+type PaymentRefundedEvent struct {
+	BaseEvent
+	Amount    decimal.Decimal `json:"amount"`
+	Currency  string          `json:"currency"`
+	Reference string          `json:"reference,omitempty"` // mã giao dịch hoàn tiền
+}
+
+// NewPaymentRefundedEvent tạo một PaymentRefundedEvent mới
+func NewPaymentRefundedEvent(base BaseEvent, amount decimal.Decimal, currency, reference string) PaymentRefundedEvent {
+	base.Type = PaymentRefundedType
+	return PaymentRefundedEvent{
+		BaseEvent: base,
+		Amount:    amount,
+		Currency:  currency,
+		Reference: reference,
+	}
+}
+
+func applyPaymentRefunded(order *Order, e PaymentRefundedEvent) {
+	payment := order.paymentCopy()
+	refundedAt := e.Timestamp
+	payment.Status = PaymentStatusRefunded
+	payment.RefundedAt = &refundedAt
+	order.Payment = payment
+	order.UpdatedAt = e.Timestamp
+}
+
+func describePaymentRefunded(e PaymentRefundedEvent) EventDescription {
+	return EventDescription{
+		Note: "Đã hoàn " + e.Amount.String() + " " + e.Currency + " cho người nhận",
+	}
+}
+
+// OrderAssignedToDriverEvent là sự kiện khi đơn hàng được phân công cho tài xế
+type OrderAssignedToDriverEvent struct {
+	BaseEvent
+	DriverID  string `json:"driver_id"`
+	VehicleID string `json:"vehicle_id"`
+}
+
+// NewOrderAssignedToDriverEvent tạo một OrderAssignedToDriverEvent mới
+func NewOrderAssignedToDriverEvent(base BaseEvent, driverID, vehicleID string) OrderAssignedToDriverEvent {
+	base.Type = OrderAssignedToDriverType
+	return OrderAssignedToDriverEvent{
+		BaseEvent: base,
+		DriverID:  driverID,
+		VehicleID: vehicleID,
+	}
+}
+
+func applyOrderAssignedToDriver(order *Order, e OrderAssignedToDriverEvent) {
+	assignedAt := e.Timestamp
+	order.DriverID = e.DriverID
+	order.VehicleID = e.VehicleID
+	order.AssignedAt = &assignedAt
+	order.UpdatedAt = e.Timestamp
+}
+
+func describeOrderAssignedToDriver(e OrderAssignedToDriverEvent) EventDescription {
+	return EventDescription{
+		Note: "Phân công cho tài xế " + e.DriverID + ", phương tiện " + e.VehicleID,
+	}
+}
+
+// OrderUnassignedEvent là sự kiện khi đơn hàng bị hủy phân công tài xế
+type OrderUnassignedEvent struct {
+	BaseEvent
+	DriverID string `json:"driver_id"` // tài xế trước đó
+	Reason   string `json:"reason,omitempty"`
+}
+
+// NewOrderUnassignedEvent tạo một OrderUnassignedEvent mới
+func NewOrderUnassignedEvent(base BaseEvent, driverID, reason string) OrderUnassignedEvent {
+	base.Type = OrderUnassignedType
+	return OrderUnassignedEvent{
+		BaseEvent: base,
+		DriverID:  driverID,
+		Reason:    reason,
+	}
+}
+
+func applyOrderUnassigned(order *Order, e OrderUnassignedEvent) {
+	order.DriverID = ""
+	order.VehicleID = ""
+	order.AssignedAt = nil
+	order.UpdatedAt = e.Timestamp
+}
+
+func describeOrderUnassigned(e OrderUnassignedEvent) EventDescription {
+	note := "Hủy phân công tài xế " + e.DriverID
+	if e.Reason != "" {
+		note += ": " + e.Reason
+	}
+	return EventDescription{Note: note}
+}
+
+// OrderLocationRecordedEvent là điểm hành trình của đơn hàng lấy từ vị trí của tài xế được phân công.
+// Chỉ được ghi khi đơn hàng di chuyển đáng kể hoặc đổi trạng thái, các vị trí khác nằm trong projection vị trí.
+type OrderLocationRecordedEvent struct {
+	BaseEvent
+	DriverID   string      `json:"driver_id"`
+	Latitude   float64     `json:"latitude"`
+	Longitude  float64     `json:"longitude"`
+	Status     OrderStatus `json:"status"`      // trạng thái của đơn hàng khi ghi
+	RecordedAt time.Time   `json:"recorded_at"` // thời điểm thiết bị của tài xế ghi nhận vị trí
+}
+
+// NewOrderLocationRecordedEvent tạo một OrderLocationRecordedEvent mới
+func NewOrderLocationRecordedEvent(base BaseEvent, driverID string, latitude, longitude float64, status OrderStatus, recordedAt time.Time) OrderLocationRecordedEvent {
+	base.Type = OrderLocationRecordedType
+	return OrderLocationRecordedEvent{
+		BaseEvent:  base,
+		DriverID:   driverID,
+		Latitude:   latitude,
+		Longitude:  longitude,
+		Status:     status,
+		RecordedAt: recordedAt,
+	}
+}
+
+// applyOrderLocationRecorded cập nhật tọa độ, thành phố và địa chỉ chỉ được giữ lại khi tọa độ không đổi
+// vì tài xế có thể đã rời thành phố của lần cập nhật trạng thái trước
+func applyOrderLocationRecorded(order *Order, e OrderLocationRecordedEvent) {
+	location := Location{Latitude: e.Latitude, Longitude: e.Longitude}
+	if current := order.CurrentLocation; current != nil && current.Point() == location.Point() {
+		location = *current
+	}
+	order.CurrentLocation = &location
+	order.UpdatedAt = e.Timestamp
+}
+
+func describeOrderLocationRecorded(e OrderLocationRecordedEvent) EventDescription {
+	return EventDescription{
+		Location: &Location{Latitude: e.Latitude, Longitude: e.Longitude},
+		Note:     "Vị trí của tài xế " + e.DriverID,
+	}
+}
+
+// ParcelScannedInEvent là sự kiện khi đơn hàng được quét vào hub
+type ParcelScannedInEvent struct {
+	BaseEvent
+	HubCode   string  `json:"hub_code"`
+	HubName   string  `json:"hub_name,omitempty"` // rỗng với các sự kiện được tạo trước khi lưu tên hub
+	Latitude  float64 `json:"latitude"`           // vị trí của hub khi quét
+	Longitude float64 `json:"longitude"`
+}
+
+// NewParcelScannedInEvent tạo một ParcelScannedInEvent mới
+func NewParcelScannedInEvent(base BaseEvent, hubCode, hubName string, latitude, longitude float64) ParcelScannedInEvent {
+	base.Type = ParcelScannedInType
+	return ParcelScannedInEvent{
+		BaseEvent: base,
+		HubCode:   hubCode,
+		HubName:   hubName,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+}
+
+func applyParcelScannedIn(order *Order, e ParcelScannedInEvent) {
+	order.CurrentHub = e.HubCode
+	location := e.location()
+	// Hub không lưu thành phố nên giữ thành phố của vị trí trước đó
+	if order.CurrentLocation != nil {
+		location.City = order.CurrentLocation.City
+	}
+	order.CurrentLocation = &location
+	order.UpdatedAt = e.Timestamp
+}
+
+// location là vị trí của hub, địa chỉ là tên hub hoặc mã hub với sự kiện cũ
+func (e ParcelScannedInEvent) location() Location {
+	address := e.HubName
+	if address == "" {
+		address = "Hub " + e.HubCode
 	}
+	return Location{Address: address, Latitude: e.Latitude, Longitude: e.Longitude}
+}
+
+func describeParcelScannedIn(e ParcelScannedInEvent) EventDescription {
+	location := e.location()
+	return EventDescription{
+		Location: &location,
+		Note:     "Quét vào hub " + e.HubCode,
+	}
+}
+
+// ParcelScannedOutEvent là sự kiện khi đơn hàng được quét ra khỏi hub
+type ParcelScannedOutEvent struct {
+	BaseEvent
+	HubCode string `json:"hub_code"`
+}
+
+// NewParcelScannedOutEvent tạo một ParcelScannedOutEvent mới
+func NewParcelScannedOutEvent(base BaseEvent, hubCode string) ParcelScannedOutEvent {
+	base.Type = ParcelScannedOutType
+	return ParcelScannedOutEvent{
+		BaseEvent: base,
+		HubCode:   hubCode,
+	}
+}
+
+func applyParcelScannedOut(order *Order, e ParcelScannedOutEvent) {
+	order.CurrentHub = ""
+	order.UpdatedAt = e.Timestamp
+}
+
+func describeParcelScannedOut(e ParcelScannedOutEvent) EventDescription {
+	return EventDescription{Note: "Quét ra khỏi hub " + e.HubCode}
 }
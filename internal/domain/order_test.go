@@ -0,0 +1,288 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	origin      = domain.Location{Address: "12 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destination = domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+	items       = []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 2, Weight: decimal.RequireFromString("0.5"), Price: decimal.NewFromInt(120000)}}
+	hub         = &domain.Location{Address: "Kho Đà Nẵng", City: "Đà Nẵng", Latitude: 16.0544, Longitude: 108.2022}
+)
+
+func TestNewOrder(t *testing.T) {
+	domaintest.Given(t).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		Then(domain.OrderCreatedEvent{
+			BaseEvent: domain.BaseEvent{
+				ID:          "id-3",
+				AggregateID: "id-1",
+				Type:        domain.OrderCreatedType,
+				Timestamp:   domaintest.Now,
+				Version:     1,
+			},
+			CustomerID:     "CUS-001",
+			TrackingNumber: "TRK-ID2",
+			Origin:         origin,
+			Destination:    destination,
+			Items:          items,
+			Currency:       domain.DefaultCurrency,
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.ID != "id-1" || order.Status != domain.OrderStatusCreated || order.Version() != 1 {
+				t.Fatalf("order = %+v, version %d", order, order.Version())
+			}
+			if !order.CreatedAt.Equal(domaintest.Now) || !order.UpdatedAt.Equal(domaintest.Now) {
+				t.Fatalf("CreatedAt = %v, UpdatedAt = %v", order.CreatedAt, order.UpdatedAt)
+			}
+			if order.Notes == nil || len(order.Notes) != 0 {
+				t.Fatalf("Notes = %#v", order.Notes)
+			}
+		})
+}
+
+func TestNewOrderValidation(t *testing.T) {
+	domaintest.Given(t).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("", origin, destination, items, opts...)
+		}).
+		ThenError("customer ID không được để trống")
+
+	domaintest.Given(t).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, nil, opts...)
+		}).
+		ThenError("ít nhất một mục")
+}
+
+func TestUpdateStatus(t *testing.T) {
+	domaintest.Given(t, domaintest.Created()).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusInTransit, hub, "Đã rời kho")
+		}).
+		Then(domain.OrderStatusUpdatedEvent{
+			BaseEvent:       domaintest.Emitted(domain.OrderStatusUpdatedType, 2, "id-1"),
+			OldStatus:       domain.OrderStatusCreated,
+			NewStatus:       domain.OrderStatusInTransit,
+			CurrentLocation: hub,
+			Note:            "Đã rời kho",
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Status != domain.OrderStatusInTransit || order.CurrentLocation != hub {
+				t.Fatalf("order = %+v", order)
+			}
+			if len(order.Notes) != 1 || order.Notes[0] != "Đã rời kho" {
+				t.Fatalf("Notes = %v", order.Notes)
+			}
+			if !order.UpdatedAt.Equal(domaintest.Now) || order.Version() != 2 {
+				t.Fatalf("UpdatedAt = %v, version %d", order.UpdatedAt, order.Version())
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestUpdateStatusKeepsLocationAndNotesWhenEmpty(t *testing.T) {
+	withLocation := domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusInTransit)
+	withLocation.CurrentLocation = hub
+
+	domaintest.Given(t, domaintest.Created(), withLocation, domaintest.Assigned(3)).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusOutForDelivery, nil, "")
+		}).
+		Then(domain.OrderStatusUpdatedEvent{
+			BaseEvent: domaintest.Emitted(domain.OrderStatusUpdatedType, 4, "id-1"),
+			OldStatus: domain.OrderStatusInTransit,
+			NewStatus: domain.OrderStatusOutForDelivery,
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.CurrentLocation == nil || *order.CurrentLocation != *hub {
+				t.Fatalf("CurrentLocation = %+v", order.CurrentLocation)
+			}
+			if len(order.Notes) != 0 {
+				t.Fatalf("Notes = %v", order.Notes)
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestUpdateStatusRejectedForFinishedOrders(t *testing.T) {
+	domaintest.Given(t, domaintest.Created(), domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusInTransit, nil, "")
+		}).
+		ThenError("đã hoàn thành hoặc đã hủy")
+
+	cancelled := domain.NewOrderCancelledEvent(domaintest.Base(domain.OrderCancelledType, 2), domain.OrderStatusCreated, "")
+	domaintest.Given(t, domaintest.Created(), cancelled).
+		When(func(order *domain.Order) error {
+			return order.UpdateStatus(domain.OrderStatusInTransit, nil, "")
+		}).
+		ThenError("đã hoàn thành hoặc đã hủy")
+}
+
+func TestCancelOrder(t *testing.T) {
+	domaintest.Given(t, domaintest.Created(), domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusProcessing)).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("Khách hàng yêu cầu hủy")
+		}).
+		Then(domain.OrderCancelledEvent{
+			BaseEvent:      domaintest.Emitted(domain.OrderCancelledType, 3, "id-1"),
+			PreviousStatus: domain.OrderStatusProcessing,
+			Reason:         "Khách hàng yêu cầu hủy",
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Status != domain.OrderStatusCancelled || len(order.Notes) != 1 {
+				t.Fatalf("order = %+v", order)
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestCancelOrderRejected(t *testing.T) {
+	domaintest.Given(t, domaintest.Created(), domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("")
+		}).
+		ThenError("không thể hủy đơn hàng đã giao")
+
+	cancelled := domain.NewOrderCancelledEvent(domaintest.Base(domain.OrderCancelledType, 2), domain.OrderStatusCreated, "")
+	domaintest.Given(t, domaintest.Created(), cancelled).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("lần nữa")
+		}).
+		ThenError("đã bị hủy trước đó")
+}
+
+func TestAddNote(t *testing.T) {
+	domaintest.Given(t, domaintest.Created()).
+		When(func(order *domain.Order) error {
+			return order.AddNote("Gọi trước khi giao")
+		}).
+		Then(domain.OrderNoteAddedEvent{
+			BaseEvent: domaintest.Emitted(domain.OrderNoteAddedType, 2, "id-1"),
+			Note:      "Gọi trước khi giao",
+		}).
+		ThenRebuilds()
+
+	domaintest.Given(t, domaintest.Created()).
+		When(func(order *domain.Order) error {
+			return order.AddNote("")
+		}).
+		ThenError("ghi chú không được để trống")
+}
+
+func TestCommandsIncrementVersions(t *testing.T) {
+	domaintest.Given(t, domaintest.Created()).
+		When(func(order *domain.Order) error {
+			if err := order.UpdateStatus(domain.OrderStatusInTransit, nil, ""); err != nil {
+				return err
+			}
+			if err := order.AddNote("Giao giờ hành chính"); err != nil {
+				return err
+			}
+			return order.UpdateStatus(domain.OrderStatusDelivered, nil, "")
+		}).
+		Then(
+			domain.OrderStatusUpdatedEvent{
+				BaseEvent: domaintest.Emitted(domain.OrderStatusUpdatedType, 2, "id-1"),
+				OldStatus: domain.OrderStatusCreated,
+				NewStatus: domain.OrderStatusInTransit,
+			},
+			domain.OrderNoteAddedEvent{
+				BaseEvent: domaintest.Emitted(domain.OrderNoteAddedType, 3, "id-2"),
+				Note:      "Giao giờ hành chính",
+			},
+			domain.OrderStatusUpdatedEvent{
+				BaseEvent: domaintest.Emitted(domain.OrderStatusUpdatedType, 4, "id-3"),
+				OldStatus: domain.OrderStatusInTransit,
+				NewStatus: domain.OrderStatusDelivered,
+			},
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Version() != 4 || order.Status != domain.OrderStatusDelivered {
+				t.Fatalf("version %d, status %s", order.Version(), order.Status)
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestRebuildFromEvents(t *testing.T) {
+	if order := domain.RebuildFromEvents(nil); order != nil {
+		t.Fatalf("RebuildFromEvents(nil) = %+v", order)
+	}
+
+	// Sự kiện trước OrderCreated không có đơn hàng để áp dụng
+	orphan := domain.NewOrderNoteAddedEvent(domaintest.Base(domain.OrderNoteAddedType, 1), "mồ côi")
+	if order := domain.RebuildFromEvents([]domain.Event{orphan}); order != nil {
+		t.Fatalf("RebuildFromEvents chỉ có sự kiện mồ côi = %+v", order)
+	}
+
+	created := domaintest.Created()
+	moved := domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusInTransit)
+	moved.CurrentLocation = hub
+	moved.Note = "Đã rời kho"
+	unknown := domain.BaseEvent{ID: "given-3", AggregateID: domaintest.OrderID, Type: "ORDER_UNKNOWN", Timestamp: moved.Timestamp, Version: 3}
+	note := domain.NewOrderNoteAddedEvent(domaintest.Base(domain.OrderNoteAddedType, 4), "Gọi trước")
+
+	order := domain.RebuildFromEvents([]domain.Event{created, moved, unknown, note})
+	if order == nil {
+		t.Fatal("RebuildFromEvents trả về nil")
+	}
+	if order.ID != domaintest.OrderID || order.CustomerID != domaintest.CustomerID || order.TrackingNumber != domaintest.TrackingNumber {
+		t.Fatalf("order = %+v", order)
+	}
+	if order.Status != domain.OrderStatusInTransit || order.CurrentLocation != hub {
+		t.Fatalf("Status = %s, CurrentLocation = %+v", order.Status, order.CurrentLocation)
+	}
+	if len(order.Notes) != 2 || order.Notes[0] != "Đã rời kho" || order.Notes[1] != "Gọi trước" {
+		t.Fatalf("Notes = %v", order.Notes)
+	}
+	if !order.CreatedAt.Equal(created.Timestamp) || !order.UpdatedAt.Equal(note.Timestamp) {
+		t.Fatalf("CreatedAt = %v, UpdatedAt = %v", order.CreatedAt, order.UpdatedAt)
+	}
+	// Phiên bản tính cả sự kiện không được đăng ký vì nó vẫn chiếm một vị trí trong luồng
+	if order.Version() != 4 || len(order.GetUncommittedEvents()) != 0 {
+		t.Fatalf("version %d, uncommitted %d", order.Version(), len(order.GetUncommittedEvents()))
+	}
+
+	asOf := domain.RebuildFromEvents(domain.EventsAsOf([]domain.Event{created, moved, unknown, note}, domain.AsOf{Time: created.Timestamp}))
+	if asOf.Status != domain.OrderStatusCreated || asOf.Version() != 1 {
+		t.Fatalf("trạng thái tại lúc tạo = %s, version %d", asOf.Status, asOf.Version())
+	}
+}
+
+func TestDiffOrders(t *testing.T) {
+	before := domain.RebuildFromEvents([]domain.Event{domaintest.Created()})
+	after := domain.RebuildFromEvents([]domain.Event{
+		domaintest.Created(),
+		domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusInTransit),
+	})
+
+	changes, err := domain.DiffOrders(before, after)
+	if err != nil {
+		t.Fatalf("DiffOrders: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Field != "status" || changes[1].Field != "updated_at" {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if changes[0].From != string(domain.OrderStatusCreated) || changes[0].To != string(domain.OrderStatusInTransit) {
+		t.Fatalf("status change = %+v", changes[0])
+	}
+
+	changes, err = domain.DiffOrders(nil, before)
+	if err != nil {
+		t.Fatalf("DiffOrders từ nil: %v", err)
+	}
+	for _, change := range changes {
+		if change.From != nil {
+			t.Fatalf("trường %s từ đơn hàng chưa tồn tại phải là nil: %+v", change.Field, change)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentMethod là cách người nhận thanh toán cho đơn hàng
+type PaymentMethod string
+
+const (
+	PaymentMethodPrepaid PaymentMethod = "PREPAID" // người gửi đã thanh toán, đơn vị vận chuyển không thu tiền
+	PaymentMethodCOD     PaymentMethod = "COD"     // tài xế thu hộ khi giao hàng
+)
+
+// Valid kiểm tra cách thanh toán có được hỗ trợ hay không
+func (m PaymentMethod) Valid() bool {
+	return m == PaymentMethodPrepaid || m == PaymentMethodCOD
+}
+
+// PaymentStatus là trạng thái thu hộ của đơn hàng
+type PaymentStatus string
+
+const (
+	PaymentStatusPending        PaymentStatus = "PENDING"         // chờ thu khi giao hàng
+	PaymentStatusCollected      PaymentStatus = "COLLECTED"       // tài xế đã thu, chưa nộp về
+	PaymentStatusRemitted       PaymentStatus = "REMITTED"        // tài xế đã nộp tiền thu hộ
+	PaymentStatusRefundRequired PaymentStatus = "REFUND_REQUIRED" // đơn hàng đã thanh toán bị hủy, cần hoàn tiền
+	PaymentStatusRefunded       PaymentStatus = "REFUNDED"
+)
+
+// Payment là trạng thái thu hộ của đơn hàng COD
+type Payment struct {
+	Method   PaymentMethod   `json:"method"`
+	Status   PaymentStatus   `json:"status"`
+	Amount   decimal.Decimal `json:"amount"` // số tiền cần thu
+	Currency string          `json:"currency"`
+
+	Collected   decimal.Decimal `json:"collected"`           // số tiền tài xế đã thu, 0 khi chưa thu
+	DriverID    string          `json:"driver_id,omitempty"` // tài xế đã thu tiền
+	CollectedAt *time.Time      `json:"collected_at,omitempty"`
+	RemittedAt  *time.Time      `json:"remitted_at,omitempty"` // vẫn được ghi nhận khi đơn hàng cần hoàn tiền
+	RefundedAt  *time.Time      `json:"refunded_at,omitempty"`
+}
+
+// Outstanding kiểm tra tài xế đã thu tiền nhưng chưa nộp về
+func (p *Payment) Outstanding() bool {
+	return p != nil && p.CollectedAt != nil && p.RemittedAt == nil
+}
+
+// paymentCopy trả về bản sao trạng thái thu hộ để sự kiện không sửa dữ liệu dùng chung với bản sao khác của đơn hàng.
+// Sự kiện thanh toán của luồng thiếu PaymentPending vẫn được áp dụng lên trạng thái rỗng.
+func (o *Order) paymentCopy() *Payment {
+	if o.Payment == nil {
+		return &Payment{Method: PaymentMethodCOD, Currency: o.Currency}
+	}
+	payment := *o.Payment
+	return &payment
+}
+
+// PaymentTerms là cách thanh toán chọn khi tạo đơn hàng
+type PaymentTerms struct {
+	Method PaymentMethod
+	// Amount là số tiền thu hộ, nil thì thu tổng tiền hàng và phí vận chuyển
+	Amount *decimal.Decimal
+}
+
+// WithPaymentTerms chỉ định cách thanh toán khi tạo đơn hàng, không có tác dụng với đơn hàng đã tồn tại.
+// Đơn hàng COD nhận sự kiện PaymentPending ngay sau khi được tạo và tính phí.
+func WithPaymentTerms(terms PaymentTerms) OrderOption {
+	return func(order *Order) {
+		if order.ID == "" {
+			order.terms = terms
+		}
+	}
+}
+
+// validate kiểm tra cách thanh toán và số tiền thu hộ
+func (t PaymentTerms) validate() error {
+	if t.Method != "" && !t.Method.Valid() {
+		return fmt.Errorf("cách thanh toán không hợp lệ: %s", t.Method)
+	}
+	if t.Amount == nil {
+		return nil
+	}
+	if t.Method != PaymentMethodCOD {
+		return errors.New("chỉ đơn hàng COD mới có số tiền thu hộ")
+	}
+	if !t.Amount.IsPositive() {
+		return errors.New("số tiền thu hộ phải lớn hơn 0")
+	}
+	return nil
+}
+
+// requestPayment tạo sự kiện PaymentPending cho đơn hàng COD mới tạo
+func (o *Order) requestPayment(terms PaymentTerms) {
+	if terms.Method != PaymentMethodCOD {
+		return
+	}
+
+	amount, ok := o.Total()
+	if !ok {
+		amount = o.Totals.Subtotal
+	}
+	if terms.Amount != nil {
+		amount = *terms.Amount
+	}
+	amount = amount.Round(CurrencyDecimals(o.Currency))
+
+	o.raise(NewPaymentPendingEvent(o.newBaseEvent(PaymentPendingType), terms.Method, amount, o.Currency))
+}
+
+// CollectPayment ghi nhận số tiền tài xế thu của người nhận khi giao hàng
+func (o *Order) CollectPayment(driverID string, amount decimal.Decimal) error {
+	if o.Payment == nil {
+		return errors.New("đơn hàng không thu hộ")
+	}
+	if o.Payment.Status != PaymentStatusPending {
+		return fmt.Errorf("đơn hàng đã được thu tiền, trạng thái thanh toán %s", o.Payment.Status)
+	}
+	if o.Status != OrderStatusOutForDelivery && o.Status != OrderStatusDelivered {
+		return fmt.Errorf("chỉ thu tiền khi đơn hàng đang giao hoặc đã giao, trạng thái hiện tại %s", o.Status)
+	}
+	if driverID == "" {
+		return errors.New("driver ID không được để trống")
+	}
+	// Tiền thu hộ được đối soát theo tài xế nên chỉ tài xế được phân công mới được thu
+	if o.DriverID != driverID {
+		return fmt.Errorf("đơn hàng không được phân công cho tài xế %s", driverID)
+	}
+	if !amount.IsPositive() {
+		return errors.New("số tiền thu phải lớn hơn 0")
+	}
+
+	o.raise(NewPaymentCollectedEvent(o.newBaseEvent(PaymentCollectedType), driverID, amount, o.Payment.Currency))
+
+	return nil
+}
+
+// RemitPayment ghi nhận tài xế đã nộp tiền thu hộ của đơn hàng, reference là mã phiếu nộp tiền
+func (o *Order) RemitPayment(driverID, reference string) error {
+	if o.Payment == nil {
+		return errors.New("đơn hàng không thu hộ")
+	}
+	if !o.Payment.Outstanding() {
+		return errors.New("đơn hàng không có tiền thu hộ chờ nộp")
+	}
+	if driverID != o.Payment.DriverID {
+		return fmt.Errorf("tiền thu hộ do tài xế %s thu, không phải %s", o.Payment.DriverID, driverID)
+	}
+
+	o.raise(NewPaymentRemittedEvent(o.newBaseEvent(PaymentRemittedType), driverID, o.Payment.Collected, o.Payment.Currency, reference))
+
+	return nil
+}
+
+// RefundPayment ghi nhận đã hoàn tiền của đơn hàng đã thanh toán bị hủy
+func (o *Order) RefundPayment(reference string) error {
+	if o.Payment == nil || o.Payment.Status != PaymentStatusRefundRequired {
+		return errors.New("đơn hàng không cần hoàn tiền")
+	}
+
+	o.raise(NewPaymentRefundedEvent(o.newBaseEvent(PaymentRefundedType), o.Payment.Collected, o.Payment.Currency, reference))
+
+	return nil
+}
+
+// requireRefund tạo sự kiện PaymentRefundRequired khi đơn hàng đã thanh toán bị hủy:
+// đơn hàng COD tài xế đã thu tiền, hoặc đơn hàng trả trước mà người gửi đã trả phí vận chuyển
+func (o *Order) requireRefund() {
+	if o.Payment == nil {
+		if o.ShippingFee == nil || !o.ShippingFee.IsPositive() {
+			return
+		}
+		event := NewPaymentRefundRequiredEvent(o.newBaseEvent(PaymentRefundRequiredType), *o.ShippingFee, o.Currency)
+		event.Method = PaymentMethodPrepaid
+		o.raise(event)
+		return
+	}
+	if o.Payment.CollectedAt == nil {
+		return
+	}
+
+	o.raise(NewPaymentRefundRequiredEvent(o.newBaseEvent(PaymentRefundRequiredType), o.Payment.Collected, o.Payment.Currency))
+}
@@ -0,0 +1,281 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/shopspring/decimal"
+)
+
+// pending là sự kiện PaymentPending lịch sử ở phiên bản 2 với số tiền thu hộ 250.000
+func pending() domain.PaymentPendingEvent {
+	return domain.NewPaymentPendingEvent(domaintest.Base(domain.PaymentPendingType, 2), domain.PaymentMethodCOD, decimal.NewFromInt(250000), domain.DefaultCurrency)
+}
+
+// collected là sự kiện PaymentCollected lịch sử ở phiên bản version
+func collected(version int) domain.PaymentCollectedEvent {
+	return domain.NewPaymentCollectedEvent(domaintest.Base(domain.PaymentCollectedType, version), "DRV-001", decimal.NewFromInt(250000), domain.DefaultCurrency)
+}
+
+func TestNewOrderRequestsCashOnDelivery(t *testing.T) {
+	cod := domain.PaymentTerms{Method: domain.PaymentMethodCOD}
+	domaintest.Given(t).
+		With(
+			domain.WithShippingFeeCalculator(flatPricer{currency: "VND", perKg: decimal.NewFromInt(10000)}),
+			domain.WithPaymentTerms(cod),
+		).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			events := order.GetUncommittedEvents()
+			if len(events) != 3 || events[2].GetType() != domain.PaymentPendingType {
+				t.Fatalf("events = %v", events)
+			}
+			// Thu tổng tiền hàng 240.000 và phí vận chuyển 10.000
+			payment := order.Payment
+			if payment == nil || payment.Status != domain.PaymentStatusPending || !payment.Amount.Equal(decimal.NewFromInt(250000)) || payment.Currency != "VND" {
+				t.Fatalf("Payment = %+v", payment)
+			}
+		}).
+		ThenRebuilds()
+
+	amount := decimal.NewFromInt(99000)
+	domaintest.Given(t).
+		With(domain.WithPaymentTerms(domain.PaymentTerms{Method: domain.PaymentMethodCOD, Amount: &amount})).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Payment == nil || !order.Payment.Amount.Equal(amount) {
+				t.Fatalf("Payment = %+v", order.Payment)
+			}
+		})
+
+	domaintest.Given(t).
+		With(domain.WithPaymentTerms(domain.PaymentTerms{Method: domain.PaymentMethodPrepaid})).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Payment != nil || order.Version() != 1 {
+				t.Fatalf("đơn hàng trả trước không có trạng thái thu hộ, Payment = %+v", order.Payment)
+			}
+		})
+
+	domaintest.Given(t).
+		With(domain.WithPaymentTerms(domain.PaymentTerms{Method: domain.PaymentMethodPrepaid, Amount: &amount})).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenError("chỉ đơn hàng COD")
+}
+
+func TestCollectPayment(t *testing.T) {
+	outForDelivery := []domain.Event{domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusOutForDelivery), domaintest.Assigned(4)}
+
+	domaintest.Given(t, outForDelivery...).
+		When(func(order *domain.Order) error {
+			return order.CollectPayment("DRV-001", decimal.NewFromInt(250000))
+		}).
+		Then(domain.PaymentCollectedEvent{
+			BaseEvent: domaintest.Emitted(domain.PaymentCollectedType, 5, "id-1"),
+			DriverID:  "DRV-001",
+			Amount:    decimal.NewFromInt(250000),
+			Currency:  domain.DefaultCurrency,
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Payment.Status != domain.PaymentStatusCollected || !order.Payment.Outstanding() || order.Payment.DriverID != "DRV-001" {
+				t.Fatalf("Payment = %+v", order.Payment)
+			}
+		}).
+		ThenRebuilds()
+
+	domaintest.Given(t, domaintest.Created(), pending()).
+		When(func(order *domain.Order) error {
+			return order.CollectPayment("DRV-001", decimal.NewFromInt(250000))
+		}).
+		ThenError("chỉ thu tiền khi đơn hàng đang giao hoặc đã giao")
+
+	// Tài xế khác với tài xế được phân công không được thu tiền
+	domaintest.Given(t, outForDelivery...).
+		When(func(order *domain.Order) error {
+			return order.CollectPayment("DRV-002", decimal.NewFromInt(250000))
+		}).
+		ThenError("không được phân công cho tài xế DRV-002")
+
+	domaintest.Given(t, domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusOutForDelivery)).
+		When(func(order *domain.Order) error {
+			return order.CollectPayment("DRV-001", decimal.NewFromInt(250000))
+		}).
+		ThenError("không được phân công cho tài xế DRV-001")
+
+	domaintest.Given(t, domaintest.Created(), domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusDelivered)).
+		When(func(order *domain.Order) error {
+			return order.CollectPayment("DRV-001", decimal.NewFromInt(250000))
+		}).
+		ThenError("đơn hàng không thu hộ")
+
+	domaintest.Given(t, domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusDelivered), collected(4)).
+		When(func(order *domain.Order) error {
+			return order.CollectPayment("DRV-002", decimal.NewFromInt(1))
+		}).
+		ThenError("đã được thu tiền")
+}
+
+func TestRemitPayment(t *testing.T) {
+	given := []domain.Event{domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusDelivered), collected(4)}
+
+	domaintest.Given(t, given...).
+		When(func(order *domain.Order) error {
+			return order.RemitPayment("DRV-001", "PN-0001")
+		}).
+		Then(domain.PaymentRemittedEvent{
+			BaseEvent: domaintest.Emitted(domain.PaymentRemittedType, 5, "id-1"),
+			DriverID:  "DRV-001",
+			Amount:    decimal.NewFromInt(250000),
+			Currency:  domain.DefaultCurrency,
+			Reference: "PN-0001",
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Payment.Status != domain.PaymentStatusRemitted || order.Payment.Outstanding() {
+				t.Fatalf("Payment = %+v", order.Payment)
+			}
+		}).
+		ThenRebuilds()
+
+	domaintest.Given(t, given...).
+		When(func(order *domain.Order) error {
+			return order.RemitPayment("DRV-002", "")
+		}).
+		ThenError("do tài xế DRV-001 thu")
+
+	domaintest.Given(t, domaintest.Created(), pending()).
+		When(func(order *domain.Order) error {
+			return order.RemitPayment("DRV-001", "")
+		}).
+		ThenError("không có tiền thu hộ chờ nộp")
+}
+
+func TestCancelCollectedOrderRequiresRefund(t *testing.T) {
+	domaintest.Given(t, domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusOutForDelivery), collected(4)).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("Người nhận trả hàng")
+		}).
+		Then(
+			domain.OrderCancelledEvent{
+				BaseEvent:      domaintest.Emitted(domain.OrderCancelledType, 5, "id-1"),
+				PreviousStatus: domain.OrderStatusOutForDelivery,
+				Reason:         "Người nhận trả hàng",
+			},
+			domain.PaymentRefundRequiredEvent{
+				BaseEvent: domaintest.Emitted(domain.PaymentRefundRequiredType, 6, "id-2"),
+				Amount:    decimal.NewFromInt(250000),
+				Currency:  domain.DefaultCurrency,
+			},
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			// Tài xế vẫn giữ tiền nên vẫn phải nộp về
+			if order.Payment.Status != domain.PaymentStatusRefundRequired || !order.Payment.Outstanding() {
+				t.Fatalf("Payment = %+v", order.Payment)
+			}
+		}).
+		ThenRebuilds()
+
+	// Đơn hàng COD chưa thu tiền bị hủy thì không cần hoàn tiền
+	domaintest.Given(t, domaintest.Created(), pending()).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("")
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if len(order.GetUncommittedEvents()) != 1 || order.Payment.Status != domain.PaymentStatusPending {
+				t.Fatalf("events = %v, Payment = %+v", order.GetUncommittedEvents(), order.Payment)
+			}
+		})
+}
+
+func TestCancelPrepaidOrderRequiresRefund(t *testing.T) {
+	priced := domain.NewOrderPricedEvent(domaintest.Base(domain.OrderPricedType, 2), domain.ShippingQuote{Fee: decimal.NewFromInt(35000), Currency: domain.DefaultCurrency})
+	refundRequired := domain.PaymentRefundRequiredEvent{
+		BaseEvent: domaintest.Emitted(domain.PaymentRefundRequiredType, 4, "id-2"),
+		Amount:    decimal.NewFromInt(35000),
+		Currency:  domain.DefaultCurrency,
+		Method:    domain.PaymentMethodPrepaid,
+	}
+
+	// Người gửi đã trả phí vận chuyển nên đơn hàng trả trước bị hủy cần được hoàn phí
+	domaintest.Given(t, domaintest.Created(), priced).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("Người gửi hủy đơn")
+		}).
+		Then(
+			domain.OrderCancelledEvent{
+				BaseEvent:      domaintest.Emitted(domain.OrderCancelledType, 3, "id-1"),
+				PreviousStatus: domain.OrderStatusCreated,
+				Reason:         "Người gửi hủy đơn",
+			},
+			refundRequired,
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			payment := order.Payment
+			if payment == nil || payment.Method != domain.PaymentMethodPrepaid || payment.Status != domain.PaymentStatusRefundRequired ||
+				!payment.Collected.Equal(decimal.NewFromInt(35000)) || payment.Outstanding() {
+				t.Fatalf("Payment = %+v", payment)
+			}
+		}).
+		ThenRebuilds()
+
+	refundRequired.BaseEvent = domaintest.Base(domain.PaymentRefundRequiredType, 4)
+	cancelled := domain.NewOrderCancelledEvent(domaintest.Base(domain.OrderCancelledType, 3), domain.OrderStatusCreated, "")
+	domaintest.Given(t, domaintest.Created(), priced, cancelled, refundRequired).
+		When(func(order *domain.Order) error {
+			return order.RefundPayment("RF-0002")
+		}).
+		Then(domain.PaymentRefundedEvent{
+			BaseEvent: domaintest.Emitted(domain.PaymentRefundedType, 5, "id-1"),
+			Amount:    decimal.NewFromInt(35000),
+			Currency:  domain.DefaultCurrency,
+			Reference: "RF-0002",
+		}).
+		ThenRebuilds()
+
+	// Đơn hàng chưa được tính phí thì người gửi chưa trả gì
+	domaintest.Given(t, domaintest.Created()).
+		When(func(order *domain.Order) error {
+			return order.CancelOrder("")
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if len(order.GetUncommittedEvents()) != 1 || order.Payment != nil {
+				t.Fatalf("events = %v, Payment = %+v", order.GetUncommittedEvents(), order.Payment)
+			}
+		})
+}
+
+func TestRefundPayment(t *testing.T) {
+	refundRequired := domain.NewPaymentRefundRequiredEvent(domaintest.Base(domain.PaymentRefundRequiredType, 6), decimal.NewFromInt(250000), domain.DefaultCurrency)
+	cancelled := domain.NewOrderCancelledEvent(domaintest.Base(domain.OrderCancelledType, 5), domain.OrderStatusOutForDelivery, "")
+
+	domaintest.Given(t, domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusOutForDelivery), collected(4), cancelled, refundRequired).
+		When(func(order *domain.Order) error {
+			return order.RefundPayment("RF-0001")
+		}).
+		Then(domain.PaymentRefundedEvent{
+			BaseEvent: domaintest.Emitted(domain.PaymentRefundedType, 7, "id-1"),
+			Amount:    decimal.NewFromInt(250000),
+			Currency:  domain.DefaultCurrency,
+			Reference: "RF-0001",
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Payment.Status != domain.PaymentStatusRefunded || order.Payment.RefundedAt == nil {
+				t.Fatalf("Payment = %+v", order.Payment)
+			}
+		}).
+		ThenRebuilds()
+
+	domaintest.Given(t, domaintest.Created(), pending(), domaintest.StatusUpdated(3, domain.OrderStatusCreated, domain.OrderStatusDelivered), collected(4)).
+		When(func(order *domain.Order) error {
+			return order.RefundPayment("")
+		}).
+		ThenError("không cần hoàn tiền")
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency là tiền tệ của đơn hàng khi không chỉ định, cũng là tiền tệ của các sự kiện tạo trước khi có mã tiền tệ
+const DefaultCurrency = "VND"
+
+// currencyDecimals là số chữ số thập phân của các tiền tệ không dùng 2 chữ số
+var currencyDecimals = map[string]int32{
+	"VND": 0,
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// ValidCurrency kiểm tra mã tiền tệ gồm 3 chữ cái in hoa theo ISO 4217
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// CurrencyDecimals trả về số chữ số thập phân nhỏ nhất của tiền tệ, mặc định 2
+func CurrencyDecimals(code string) int32 {
+	if places, ok := currencyDecimals[code]; ok {
+		return places
+	}
+	return 2
+}
+
+// OrderTotals là các giá trị tổng hợp từ mặt hàng của đơn hàng
+type OrderTotals struct {
+	Subtotal    decimal.Decimal `json:"subtotal"`     // tổng đơn giá nhân số lượng
+	TotalWeight decimal.Decimal `json:"total_weight"` // kg
+	// DeclaredValue là giá trị hàng hóa khai báo với đơn vị vận chuyển để tính phí bảo hiểm,
+	// mặt hàng lấy từ danh mục khai báo theo giá niêm yết trong ảnh chụp sản phẩm
+	DeclaredValue decimal.Decimal `json:"declared_value"`
+}
+
+// CalculateTotals tính tổng giá trị, khối lượng và giá trị khai báo của các mặt hàng
+func CalculateTotals(items []OrderItem) OrderTotals {
+	totals := OrderTotals{Subtotal: decimal.Zero, TotalWeight: decimal.Zero, DeclaredValue: decimal.Zero}
+	for _, item := range items {
+		quantity := decimal.NewFromInt(int64(item.Quantity))
+		totals.Subtotal = totals.Subtotal.Add(item.Price.Mul(quantity))
+		totals.TotalWeight = totals.TotalWeight.Add(item.Weight.Mul(quantity))
+
+		declared := item.Price
+		if item.Product != nil {
+			declared = item.Product.Price
+		}
+		totals.DeclaredValue = totals.DeclaredValue.Add(declared.Mul(quantity))
+	}
+	return totals
+}
+
+// ShippingQuote là phí vận chuyển của đơn hàng
+type ShippingQuote struct {
+	Fee              decimal.Decimal
+	Currency         string
+	ChargeableWeight decimal.Decimal
+	DistanceKm       float64
+}
+
+// ShippingFeeCalculator tính phí vận chuyển của đơn hàng mới tạo,
+// trả về false khi không tính được phí (ví dụ tiền tệ không được hỗ trợ)
+type ShippingFeeCalculator interface {
+	CalculateShippingFee(order *Order) (ShippingQuote, bool)
+}
+
+// WithShippingFeeCalculator chỉ định cách tính phí vận chuyển, phí được tính một lần khi tạo đơn hàng
+func WithShippingFeeCalculator(calculator ShippingFeeCalculator) OrderOption {
+	return func(order *Order) {
+		order.pricer = calculator
+	}
+}
+
+// WithCurrency chỉ định tiền tệ khi tạo đơn hàng, không có tác dụng với đơn hàng đã tồn tại
+func WithCurrency(code string) OrderOption {
+	return func(order *Order) {
+		if order.ID == "" {
+			order.Currency = code
+		}
+	}
+}
+
+// Total là tổng tiền hàng cộng phí vận chuyển, false khi đơn hàng chưa được tính phí vận chuyển
+func (o *Order) Total() (decimal.Decimal, bool) {
+	if o.ShippingFee == nil {
+		return decimal.Decimal{}, false
+	}
+	return o.Totals.Subtotal.Add(*o.ShippingFee), true
+}
+
+// priceShipping tính phí vận chuyển và tạo sự kiện OrderPriced
+func (o *Order) priceShipping() {
+	if o.pricer == nil {
+		return
+	}
+
+	quote, ok := o.pricer.CalculateShippingFee(o)
+	if !ok {
+		return
+	}
+
+	o.raise(NewOrderPricedEvent(o.newBaseEvent(OrderPricedType), quote))
+}
@@ -0,0 +1,125 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/shopspring/decimal"
+)
+
+// flatPricer tính phí cố định trên mỗi kg, chỉ hỗ trợ một tiền tệ
+type flatPricer struct {
+	currency string
+	perKg    decimal.Decimal
+}
+
+func (p flatPricer) CalculateShippingFee(order *domain.Order) (domain.ShippingQuote, bool) {
+	if order.Currency != p.currency {
+		return domain.ShippingQuote{}, false
+	}
+	return domain.ShippingQuote{
+		Fee:              order.Totals.TotalWeight.Mul(p.perKg).Round(0),
+		Currency:         p.currency,
+		ChargeableWeight: order.Totals.TotalWeight,
+		DistanceKm:       1137.4,
+	}, true
+}
+
+func TestCalculateTotals(t *testing.T) {
+	totals := domain.CalculateTotals([]domain.OrderItem{
+		{Quantity: 3, Weight: decimal.RequireFromString("0.1"), Price: decimal.RequireFromString("0.1")},
+		{Quantity: 1, Weight: decimal.RequireFromString("1.25"), Price: decimal.NewFromInt(85000),
+			Product: &domain.ProductSnapshot{Price: decimal.NewFromInt(99000)}},
+	})
+
+	// 3 × 0.1 phải đúng bằng 0.3, không có sai số của số thực
+	if !totals.Subtotal.Equal(decimal.RequireFromString("85000.3")) {
+		t.Fatalf("Subtotal = %s", totals.Subtotal)
+	}
+	if !totals.TotalWeight.Equal(decimal.RequireFromString("1.55")) {
+		t.Fatalf("TotalWeight = %s", totals.TotalWeight)
+	}
+	if !totals.DeclaredValue.Equal(decimal.RequireFromString("99000.3")) {
+		t.Fatalf("DeclaredValue = %s, muốn theo giá niêm yết của sản phẩm", totals.DeclaredValue)
+	}
+}
+
+func TestNewOrderPricesShipping(t *testing.T) {
+	domaintest.Given(t).
+		With(domain.WithShippingFeeCalculator(flatPricer{currency: "VND", perKg: decimal.NewFromInt(10000)})).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		Then(
+			domain.OrderCreatedEvent{
+				BaseEvent: domain.BaseEvent{
+					ID:          "id-3",
+					AggregateID: "id-1",
+					Type:        domain.OrderCreatedType,
+					Timestamp:   domaintest.Now,
+					Version:     1,
+				},
+				CustomerID:     "CUS-001",
+				TrackingNumber: "TRK-ID2",
+				Origin:         origin,
+				Destination:    destination,
+				Items:          items,
+				Currency:       "VND",
+			},
+			domain.OrderPricedEvent{
+				BaseEvent: domain.BaseEvent{
+					ID:          "id-4",
+					AggregateID: "id-1",
+					Type:        domain.OrderPricedType,
+					Timestamp:   domaintest.Now,
+					Version:     2,
+				},
+				ShippingFee:      decimal.NewFromInt(10000),
+				Currency:         "VND",
+				ChargeableWeight: decimal.RequireFromString("1.0"), // 2 × 0.5
+				DistanceKm:       1137.4,
+			},
+		).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if !order.Totals.Subtotal.Equal(decimal.NewFromInt(240000)) || !order.Totals.TotalWeight.Equal(decimal.NewFromInt(1)) {
+				t.Fatalf("Totals = %+v", order.Totals)
+			}
+			if total, ok := order.Total(); !ok || !total.Equal(decimal.NewFromInt(250000)) {
+				t.Fatalf("Total = %s, %v", total, ok)
+			}
+		}).
+		ThenRebuilds()
+}
+
+func TestNewOrderSkipsUnsupportedCurrency(t *testing.T) {
+	domaintest.Given(t).
+		With(domain.WithShippingFeeCalculator(flatPricer{currency: "VND"}), domain.WithCurrency("USD")).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenState(func(t testing.TB, order *domain.Order) {
+			if order.Currency != "USD" || order.ShippingFee != nil || order.Version() != 1 {
+				t.Fatalf("Currency = %s, ShippingFee = %v, version %d", order.Currency, order.ShippingFee, order.Version())
+			}
+			if _, ok := order.Total(); ok {
+				t.Fatal("đơn hàng chưa tính phí vận chuyển không có tổng tiền")
+			}
+		})
+}
+
+func TestNewOrderValidatesAmounts(t *testing.T) {
+	domaintest.Given(t).
+		With(domain.WithCurrency("vnd")).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, items, opts...)
+		}).
+		ThenError("mã tiền tệ không hợp lệ")
+
+	negative := []domain.OrderItem{{ID: "ITEM-1", Quantity: 1, Weight: decimal.NewFromInt(1), Price: decimal.NewFromInt(-1)}}
+	domaintest.Given(t).
+		WhenCreate(func(opts ...domain.OrderOption) (*domain.Order, error) {
+			return domain.NewOrder("CUS-001", origin, destination, negative, opts...)
+		}).
+		ThenError("không được âm")
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// EventDescription là mô tả dễ đọc của một sự kiện trong lịch sử đơn hàng
+type EventDescription struct {
+	Status     OrderStatus
+	PrevStatus OrderStatus
+	Location   *Location
+	Note       string
+}
+
+// AggregateType là loại aggregate phát ra sự kiện
+type AggregateType string
+
+const (
+	AggregateOrder   AggregateType = "ORDER"
+	AggregateDriver  AggregateType = "DRIVER"
+	AggregateVehicle AggregateType = "VEHICLE"
+)
+
+// EventDescriptor chứa mọi thông tin cần thiết để xử lý một loại sự kiện
+type EventDescriptor struct {
+	Type      EventType
+	Aggregate AggregateType
+	GoType    reflect.Type
+
+	// Decode giải mã payload thành sự kiện, unmarshal đổ dữ liệu vào con trỏ được truyền vào
+	Decode func(unmarshal func(v interface{}) error) (Event, error)
+
+	// Apply áp dụng sự kiện lên đơn hàng và trả về đơn hàng sau khi áp dụng,
+	// sự kiện của aggregate khác không làm thay đổi đơn hàng
+	Apply func(order *Order, event Event) *Order
+
+	// Describe trả về mô tả của sự kiện dùng cho lịch sử đơn hàng
+	Describe func(event Event) EventDescription
+}
+
+// eventRegistry lưu các loại sự kiện theo thứ tự đăng ký
+var eventRegistry = struct {
+	descriptors map[EventType]EventDescriptor
+	types       []EventType
+}{
+	descriptors: make(map[EventType]EventDescriptor),
+}
+
+// RegisterEvent đăng ký một loại sự kiện của đơn hàng cùng kiểu Go, hàm áp dụng và hàm mô tả của nó
+func RegisterEvent[E Event](eventType EventType, apply func(order *Order, event E) *Order, describe func(event E) EventDescription) {
+	register(EventDescriptor{
+		Type:      eventType,
+		Aggregate: AggregateOrder,
+		GoType:    reflect.TypeOf((*E)(nil)).Elem(),
+		Decode:    decodeAs[E],
+		Apply: func(order *Order, event Event) *Order {
+			return apply(order, event.(E))
+		},
+		Describe: func(event Event) EventDescription {
+			return describe(event.(E))
+		},
+	})
+}
+
+// registerAggregateEvent đăng ký sự kiện của aggregate khác đơn hàng,
+// hàm áp dụng được lưu vào appliers của aggregate đó
+func registerAggregateEvent[E Event, A any](eventType EventType, aggregate AggregateType, appliers map[EventType]func(*A, Event) *A, apply func(a *A, event E) *A) {
+	register(EventDescriptor{
+		Type:      eventType,
+		Aggregate: aggregate,
+		GoType:    reflect.TypeOf((*E)(nil)).Elem(),
+		Decode:    decodeAs[E],
+		Apply: func(order *Order, _ Event) *Order {
+			return order
+		},
+		Describe: func(Event) EventDescription {
+			return EventDescription{}
+		},
+	})
+	appliers[eventType] = func(a *A, event Event) *A {
+		return apply(a, event.(E))
+	}
+}
+
+func register(descriptor EventDescriptor) {
+	if _, ok := eventRegistry.descriptors[descriptor.Type]; ok {
+		panic(fmt.Sprintf("sự kiện %s đã được đăng ký", descriptor.Type))
+	}
+	eventRegistry.descriptors[descriptor.Type] = descriptor
+	eventRegistry.types = append(eventRegistry.types, descriptor.Type)
+}
+
+func decodeAs[E Event](unmarshal func(v interface{}) error) (Event, error) {
+	var e E
+	if err := unmarshal(&e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// LookupEvent tìm thông tin đăng ký của một loại sự kiện
+func LookupEvent(eventType EventType) (EventDescriptor, bool) {
+	descriptor, ok := eventRegistry.descriptors[eventType]
+	return descriptor, ok
+}
+
+// RegisteredEventTypes trả về tất cả các loại sự kiện đã đăng ký
+func RegisteredEventTypes() []EventType {
+	types := make([]EventType, len(eventRegistry.types))
+	copy(types, eventRegistry.types)
+	return types
+}
+
+// EventTypesOf trả về các loại sự kiện của một loại aggregate theo thứ tự đăng ký
+func EventTypesOf(aggregate AggregateType) []EventType {
+	var types []EventType
+	for _, eventType := range eventRegistry.types {
+		if eventRegistry.descriptors[eventType].Aggregate == aggregate {
+			types = append(types, eventType)
+		}
+	}
+	return types
+}
+
+// DecodeEvent giải mã payload của một loại sự kiện đã đăng ký
+func DecodeEvent(eventType EventType, unmarshal func(v interface{}) error) (Event, error) {
+	descriptor, ok := LookupEvent(eventType)
+	if !ok {
+		return nil, fmt.Errorf("loại sự kiện không được hỗ trợ: %s", eventType)
+	}
+	return descriptor.Decode(unmarshal)
+}
+
+// DescribeEvent trả về mô tả của một sự kiện, rỗng nếu loại sự kiện chưa được đăng ký
+func DescribeEvent(event Event) EventDescription {
+	descriptor, ok := LookupEvent(event.GetType())
+	if !ok {
+		return EventDescription{}
+	}
+	return descriptor.Describe(event)
+}
+
+// onExistingOrder bọc hàm áp dụng cho các sự kiện chỉ có ý nghĩa khi đơn hàng đã tồn tại
+func onExistingOrder[E Event](apply func(order *Order, event E)) func(order *Order, event E) *Order {
+	return func(order *Order, event E) *Order {
+		if order == nil {
+			return nil
+		}
+		apply(order, event)
+		return order
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// Point trả về tọa độ của vị trí
+func (l Location) Point() geo.Point {
+	return geo.Point{Latitude: l.Latitude, Longitude: l.Longitude}
+}
+
+// HasCoordinates kiểm tra vị trí có tọa độ hợp lệ, (0, 0) được xem là chưa khai báo
+func (l Location) HasCoordinates() bool {
+	p := l.Point()
+	return !p.IsZero() && p.Valid()
+}
+
+// Position là vị trí hiện tại của đơn hàng, điểm gửi nếu chưa có vị trí cập nhật có tọa độ
+func (o *Order) Position() Location {
+	if o.CurrentLocation != nil && o.CurrentLocation.HasCoordinates() {
+		return *o.CurrentLocation
+	}
+	return o.Origin
+}
+
+// DistanceRemainingKm tính quãng đường đường chim bay còn lại tới điểm nhận.
+// Trả về false khi thiếu tọa độ hoặc đơn hàng đã bị hủy.
+func (o *Order) DistanceRemainingKm() (float64, bool) {
+	switch {
+	case o.Status == OrderStatusCancelled:
+		return 0, false
+	case o.Status == OrderStatusDelivered:
+		return 0, true
+	}
+
+	position := o.Position()
+	if !position.HasCoordinates() || !o.Destination.HasCoordinates() {
+		return 0, false
+	}
+	return round1(geo.DistanceKm(position.Point(), o.Destination.Point())), true
+}
+
+// ProgressPct tính phần trăm hành trình đã đi từ điểm gửi tới điểm nhận.
+// Trả về false khi thiếu tọa độ hoặc đơn hàng đã bị hủy.
+func (o *Order) ProgressPct() (float64, bool) {
+	switch {
+	case o.Status == OrderStatusCancelled:
+		return 0, false
+	case o.Status == OrderStatusDelivered:
+		return 100, true
+	}
+
+	if !o.Origin.HasCoordinates() || !o.Destination.HasCoordinates() {
+		return 0, false
+	}
+	return round1(geo.ProgressPct(o.Origin.Point(), o.Position().Point(), o.Destination.Point())), true
+}
+
+// MarshalJSON bổ sung quãng đường còn lại và tiến độ được tính từ tọa độ của đơn hàng
+func (o *Order) MarshalJSON() ([]byte, error) {
+	type order Order
+	view := struct {
+		order
+		DistanceRemainingKm *float64 `json:"distance_remaining_km,omitempty"`
+		ProgressPct         *float64 `json:"progress_pct,omitempty"`
+	}{order: order(*o)}
+
+	if distance, ok := o.DistanceRemainingKm(); ok {
+		view.DistanceRemainingKm = &distance
+	}
+	if progress, ok := o.ProgressPct(); ok {
+		view.ProgressPct = &progress
+	}
+	return json.Marshal(view)
+}
+
+// round1 làm tròn tới một chữ số thập phân
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// round2 làm tròn tới hai chữ số thập phân
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+// RouteDestination là điểm giao của một đơn hàng cần lập lộ trình
+type RouteDestination struct {
+	OrderID  string
+	Point    geo.Point
+	WeightKg decimal.Decimal
+}
+
+// RouteStop là một điểm dừng trong lộ trình giao hàng.
+// Trip đánh số chuyến từ 1, xe quay về điểm xuất phát để lấy hàng giữa hai chuyến.
+type RouteStop struct {
+	OrderID    string  `json:"order_id"`
+	Trip       int     `json:"trip"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	DistanceKm float64 `json:"distance_km"` // quãng đường từ điểm dừng trước hoặc từ điểm xuất phát
+}
+
+// Point trả về tọa độ của điểm dừng
+func (s RouteStop) Point() geo.Point {
+	return geo.Point{Latitude: s.Latitude, Longitude: s.Longitude}
+}
+
+// Route là lộ trình giao hàng của tài xế
+type Route struct {
+	VehicleID  string      `json:"vehicle_id"`
+	Start      geo.Point   `json:"start"`
+	Stops      []RouteStop `json:"stops"`
+	DistanceKm float64     `json:"distance_km"`        // tổng quãng đường, kể cả quãng quay về giữa các chuyến
+	Unrouted   []string    `json:"unrouted,omitempty"` // đơn hàng không có tọa độ điểm giao
+	PlannedAt  time.Time   `json:"planned_at"`
+}
+
+// PlanRoute sắp xếp thứ tự giao các điểm từ start bằng thuật toán láng giềng gần nhất,
+// sau đó cải thiện từng chuyến bằng 2-opt. Khi hàng còn lại không vừa tải trọng capacityKg,
+// xe quay về start bắt đầu chuyến mới. Lộ trình không tính quãng quay về sau chuyến cuối.
+func PlanRoute(start geo.Point, destinations []RouteDestination, capacityKg decimal.Decimal) ([]RouteStop, float64, error) {
+	if start.IsZero() || !start.Valid() {
+		return nil, 0, errors.New("điểm xuất phát không hợp lệ")
+	}
+	if !capacityKg.IsPositive() {
+		return nil, 0, errors.New("tải trọng phải lớn hơn 0")
+	}
+	for _, d := range destinations {
+		if d.Point.IsZero() || !d.Point.Valid() {
+			return nil, 0, fmt.Errorf("đơn hàng %s có tọa độ điểm giao không hợp lệ", d.OrderID)
+		}
+		if d.WeightKg.GreaterThan(capacityKg) {
+			return nil, 0, fmt.Errorf("đơn hàng %s nặng hơn tải trọng của phương tiện", d.OrderID)
+		}
+	}
+
+	trips := nearestNeighbourTrips(start, destinations, capacityKg)
+
+	var stops []RouteStop
+	var total float64
+	for i, trip := range trips {
+		last := i == len(trips)-1
+		path := twoOpt(start, trip, !last)
+
+		from := start
+		for _, d := range path {
+			leg := geo.DistanceKm(from, d.Point)
+			total += leg
+			stops = append(stops, RouteStop{
+				OrderID:    d.OrderID,
+				Trip:       i + 1,
+				Latitude:   d.Point.Latitude,
+				Longitude:  d.Point.Longitude,
+				DistanceKm: round2(leg),
+			})
+			from = d.Point
+		}
+		if !last {
+			total += geo.DistanceKm(from, start)
+		}
+	}
+
+	return stops, round2(total), nil
+}
+
+// nearestNeighbourTrips chia các điểm giao thành các chuyến, mỗi lần đi tới điểm gần nhất
+// còn vừa tải trọng. Điểm có khoảng cách bằng nhau được chọn theo thứ tự đầu vào.
+func nearestNeighbourTrips(start geo.Point, destinations []RouteDestination, capacityKg decimal.Decimal) [][]RouteDestination {
+	remaining := make([]RouteDestination, len(destinations))
+	copy(remaining, destinations)
+
+	var trips [][]RouteDestination
+	var trip []RouteDestination
+	from := start
+	load := capacityKg
+	for len(remaining) > 0 {
+		next := -1
+		var best float64
+		for i, d := range remaining {
+			if d.WeightKg.GreaterThan(load) {
+				continue
+			}
+			if distance := geo.DistanceKm(from, d.Point); next < 0 || distance < best {
+				next, best = i, distance
+			}
+		}
+		// Không còn điểm nào vừa xe, quay về điểm xuất phát bắt đầu chuyến mới
+		if next < 0 {
+			trips = append(trips, trip)
+			trip, from, load = nil, start, capacityKg
+			continue
+		}
+
+		d := remaining[next]
+		trip = append(trip, d)
+		from = d.Point
+		load = load.Sub(d.WeightKg)
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+	if len(trip) > 0 {
+		trips = append(trips, trip)
+	}
+	return trips
+}
+
+// twoOpt đảo ngược các đoạn của chuyến cho tới khi không còn cách đảo nào rút ngắn quãng đường.
+// closed cho biết chuyến kết thúc bằng việc quay về start.
+func twoOpt(start geo.Point, trip []RouteDestination, closed bool) []RouteDestination {
+	path := make([]RouteDestination, len(trip))
+	copy(path, trip)
+
+	// point(i) là điểm thứ i của chuyến tính cả điểm xuất phát ở vị trí 0
+	point := func(i int) geo.Point {
+		if i == 0 {
+			return start
+		}
+		return path[i-1].Point
+	}
+	n := len(path) + 1
+
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < n-1; i++ {
+			for k := i + 1; k < n; k++ {
+				// Đảo đoạn [i, k] thay cạnh (i-1, i) và (k, k+1) bằng (i-1, k) và (i, k+1)
+				delta := geo.DistanceKm(point(i-1), point(k)) - geo.DistanceKm(point(i-1), point(i))
+				switch {
+				case k+1 < n:
+					delta += geo.DistanceKm(point(i), point(k+1)) - geo.DistanceKm(point(k), point(k+1))
+				case closed:
+					delta += geo.DistanceKm(point(i), start) - geo.DistanceKm(point(k), start)
+				}
+				if delta < -1e-9 {
+					for a, b := i-1, k-1; a < b; a, b = a+1, b-1 {
+						path[a], path[b] = path[b], path[a]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+	return path
+}
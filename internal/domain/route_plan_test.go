@@ -0,0 +1,139 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+var depot = geo.Point{Latitude: 10.77, Longitude: 106.70}
+
+func routeDestination(id string, lat, lng float64, weightKg int64) domain.RouteDestination {
+	return domain.RouteDestination{OrderID: id, Point: geo.Point{Latitude: lat, Longitude: lng}, WeightKg: decimal.NewFromInt(weightKg)}
+}
+
+func stopIDs(stops []domain.RouteStop) []string {
+	ids := make([]string, len(stops))
+	for i, stop := range stops {
+		ids[i] = stop.OrderID
+	}
+	return ids
+}
+
+func TestPlanRouteImprovesNearestNeighbour(t *testing.T) {
+	// Láng giềng gần nhất đi o2, o4, o3, o1 (khoảng 10,2 km), 2-opt đảo đoạn o4, o3
+	stops, distance, err := domain.PlanRoute(depot, []domain.RouteDestination{
+		routeDestination("o1", 10.80, 106.71, 1),
+		routeDestination("o2", 10.78, 106.72, 1),
+		routeDestination("o3", 10.77, 106.73, 1),
+		routeDestination("o4", 10.79, 106.73, 1),
+	}, decimal.NewFromInt(100))
+	if err != nil {
+		t.Fatalf("PlanRoute: %v", err)
+	}
+
+	if got := stopIDs(stops); len(got) != 4 || got[0] != "o2" || got[1] != "o3" || got[2] != "o4" || got[3] != "o1" {
+		t.Fatalf("thứ tự = %v", got)
+	}
+	if distance < 8.6 || distance > 8.8 {
+		t.Fatalf("distance = %v", distance)
+	}
+	var legs float64
+	for _, stop := range stops {
+		if stop.Trip != 1 {
+			t.Fatalf("stop %s thuộc chuyến %d", stop.OrderID, stop.Trip)
+		}
+		legs += stop.DistanceKm
+	}
+	if legs < distance-0.05 || legs > distance+0.05 {
+		t.Fatalf("tổng các chặng %v khác distance %v", legs, distance)
+	}
+}
+
+func TestPlanRouteSplitsTripsByCapacity(t *testing.T) {
+	stops, distance, err := domain.PlanRoute(depot, []domain.RouteDestination{
+		routeDestination("near", 10.78, 106.70, 6),
+		routeDestination("mid", 10.79, 106.70, 6),
+		routeDestination("far", 10.80, 106.70, 4),
+	}, decimal.NewFromInt(10))
+	if err != nil {
+		t.Fatalf("PlanRoute: %v", err)
+	}
+
+	// Chuyến đầu chở near và far (10 kg), mid không vừa nên đi chuyến thứ hai
+	if got := stopIDs(stops); len(got) != 3 || got[0] != "near" || got[1] != "far" || got[2] != "mid" {
+		t.Fatalf("thứ tự = %v", got)
+	}
+	if stops[0].Trip != 1 || stops[1].Trip != 1 || stops[2].Trip != 2 {
+		t.Fatalf("chuyến = %+v", stops)
+	}
+	// Quãng đường gồm đi tới far, quay về điểm xuất phát rồi tới mid
+	if distance < 8.8 || distance > 8.9 {
+		t.Fatalf("distance = %v", distance)
+	}
+	if stops[2].DistanceKm < 2.2 || stops[2].DistanceKm > 2.3 {
+		t.Fatalf("chặng đầu chuyến 2 = %v, phải tính từ điểm xuất phát", stops[2].DistanceKm)
+	}
+}
+
+func TestPlanRouteRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name         string
+		start        geo.Point
+		destinations []domain.RouteDestination
+		capacityKg   decimal.Decimal
+	}{
+		{"StartMissing", geo.Point{}, []domain.RouteDestination{routeDestination("o1", 10.78, 106.70, 1)}, decimal.NewFromInt(10)},
+		{"NoCapacity", depot, []domain.RouteDestination{routeDestination("o1", 10.78, 106.70, 1)}, decimal.Zero},
+		{"Overweight", depot, []domain.RouteDestination{routeDestination("o1", 10.78, 106.70, 11)}, decimal.NewFromInt(10)},
+		{"InvalidPoint", depot, []domain.RouteDestination{routeDestination("o1", 91, 106.70, 1)}, decimal.NewFromInt(10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := domain.PlanRoute(tt.start, tt.destinations, tt.capacityKg); err == nil {
+				t.Fatal("PlanRoute phải lỗi")
+			}
+		})
+	}
+}
+
+func TestDriverPlanRoute(t *testing.T) {
+	opts := domaintest.Options()
+	vehicle, err := domain.NewVehicle("51C-123.45", decimal.NewFromInt(100), opts...)
+	if err != nil {
+		t.Fatalf("NewVehicle: %v", err)
+	}
+	driver, err := domain.NewDriver("Nguyễn Văn A", opts...)
+	if err != nil {
+		t.Fatalf("NewDriver: %v", err)
+	}
+	destinations := []domain.RouteDestination{routeDestination("o1", 10.79, 106.70, 1), routeDestination("o2", 10.78, 106.70, 1)}
+
+	if err := driver.PlanRoute(vehicle, depot, destinations, nil); err == nil {
+		t.Fatal("PlanRoute khi chưa gán phương tiện phải lỗi")
+	}
+	if err := driver.AssignVehicle(vehicle); err != nil {
+		t.Fatalf("AssignVehicle: %v", err)
+	}
+	if err := driver.PlanRoute(vehicle, depot, nil, []string{"o3"}); err == nil {
+		t.Fatal("PlanRoute không có điểm giao phải lỗi")
+	}
+	if err := driver.PlanRoute(vehicle, depot, destinations, []string{"o3"}); err != nil {
+		t.Fatalf("PlanRoute: %v", err)
+	}
+
+	rebuilt := domain.RebuildDriver(driver.GetUncommittedEvents())
+	route := rebuilt.Route
+	if route == nil || route.VehicleID != vehicle.ID || route.Start != depot || len(route.Unrouted) != 1 || !route.PlannedAt.Equal(domaintest.Now) {
+		t.Fatalf("Route = %+v", route)
+	}
+	if got := stopIDs(route.Stops); len(got) != 2 || got[0] != "o2" || got[1] != "o1" {
+		t.Fatalf("thứ tự = %v", got)
+	}
+	if rebuilt.Version() != 3 {
+		t.Fatalf("Version = %d", rebuilt.Version())
+	}
+}
@@ -0,0 +1,82 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+)
+
+func TestOrderRouteProgress(t *testing.T) {
+	order := domain.RebuildFromEvents([]domain.Event{domaintest.Created()})
+
+	distance, ok := order.DistanceRemainingKm()
+	if !ok || distance < 1100 || distance > 1200 {
+		t.Fatalf("DistanceRemainingKm tại điểm gửi = %v, %v", distance, ok)
+	}
+	if progress, ok := order.ProgressPct(); !ok || progress != 0 {
+		t.Fatalf("ProgressPct tại điểm gửi = %v, %v", progress, ok)
+	}
+
+	inTransit := domain.NewOrderStatusUpdatedEvent(domaintest.Base(domain.OrderStatusUpdatedType, 2), domain.OrderStatusCreated, domain.OrderStatusInTransit, hub, "")
+	order = domain.RebuildFromEvents([]domain.Event{domaintest.Created(), inTransit})
+	progress, ok := order.ProgressPct()
+	if !ok || progress < 40 || progress > 60 {
+		t.Fatalf("ProgressPct tại Đà Nẵng = %v, %v", progress, ok)
+	}
+	remaining, _ := order.DistanceRemainingKm()
+	if remaining >= distance {
+		t.Fatalf("DistanceRemainingKm tại Đà Nẵng = %v, không nhỏ hơn %v", remaining, distance)
+	}
+
+	delivered := domaintest.StatusUpdated(3, domain.OrderStatusInTransit, domain.OrderStatusDelivered)
+	order = domain.RebuildFromEvents([]domain.Event{domaintest.Created(), inTransit, delivered})
+	if progress, ok := order.ProgressPct(); !ok || progress != 100 {
+		t.Fatalf("ProgressPct khi đã giao = %v, %v", progress, ok)
+	}
+	if distance, ok := order.DistanceRemainingKm(); !ok || distance != 0 {
+		t.Fatalf("DistanceRemainingKm khi đã giao = %v, %v", distance, ok)
+	}
+}
+
+func TestOrderRouteProgressWithoutCoordinates(t *testing.T) {
+	created := domaintest.Created()
+	created.Destination = domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội"}
+	order := domain.RebuildFromEvents([]domain.Event{created})
+
+	if _, ok := order.DistanceRemainingKm(); ok {
+		t.Fatal("DistanceRemainingKm không được tính khi điểm nhận không có tọa độ")
+	}
+	if _, ok := order.ProgressPct(); ok {
+		t.Fatal("ProgressPct không được tính khi điểm nhận không có tọa độ")
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if _, ok := fields["progress_pct"]; ok {
+		t.Fatalf("JSON có progress_pct khi không có tọa độ: %s", data)
+	}
+}
+
+func TestOrderJSONIncludesRouteProgress(t *testing.T) {
+	order := domain.RebuildFromEvents([]domain.Event{domaintest.Created()})
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if fields["id"] != domaintest.OrderID || fields["progress_pct"] != 0.0 || fields["distance_remaining_km"] == nil {
+		t.Fatalf("JSON = %s", data)
+	}
+}
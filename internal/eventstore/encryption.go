@@ -0,0 +1,262 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+const (
+	// RedactedValue thay thế dữ liệu cá nhân không thể giải mã do khóa của khách hàng đã bị xóa
+	RedactedValue = domain.RedactedValue
+
+	// encryptedPrefix đánh dấu giá trị đã được mã hóa, giúp phân biệt với sự kiện cũ lưu dạng rõ
+	encryptedPrefix = "pii:v1:"
+)
+
+// EncryptingSerializer mã hóa các trường dữ liệu cá nhân của sự kiện bằng khóa riêng của từng khách hàng
+// trước khi chuyển cho serializer bên trong. Khi khóa bị xóa, các trường này được đọc ra là RedactedValue
+// còn phần còn lại của sự kiện vẫn giải mã bình thường.
+type EncryptingSerializer struct {
+	inner EventSerializer
+	keys  KeyStore
+}
+
+// NewEncryptingSerializer bọc một serializer để mã hóa dữ liệu cá nhân
+func NewEncryptingSerializer(inner EventSerializer, keys KeyStore) *EncryptingSerializer {
+	return &EncryptingSerializer{
+		inner: inner,
+		keys:  keys,
+	}
+}
+
+// EncryptSerializers bọc tất cả serializer trong danh sách để mã hóa dữ liệu cá nhân
+func EncryptSerializers(serializers map[string]EventSerializer, keys KeyStore) map[string]EventSerializer {
+	encrypted := make(map[string]EventSerializer, len(serializers))
+	for format, serializer := range serializers {
+		encrypted[format] = NewEncryptingSerializer(serializer, keys)
+	}
+	return encrypted
+}
+
+// Format trả về định dạng của serializer bên trong
+func (s *EncryptingSerializer) Format() string {
+	return s.inner.Format()
+}
+
+// SchemaVersion trả về phiên bản schema của serializer bên trong
+func (s *EncryptingSerializer) SchemaVersion(eventType domain.EventType) int {
+	return s.inner.SchemaVersion(eventType)
+}
+
+// Serialize mã hóa dữ liệu cá nhân rồi serialize sự kiện.
+// Sự kiện của aggregate chưa gắn với khách hàng nào được lưu nguyên vẹn.
+// Liên kết aggregate và khóa mới được ghi qua ctx nên nằm trong transaction ghi sự kiện.
+func (s *EncryptingSerializer) Serialize(ctx context.Context, event domain.Event) ([]byte, error) {
+	aggregateID := event.GetAggregateID()
+
+	customerID := piiSubject(event)
+	if customerID != "" {
+		if err := s.keys.BindAggregate(ctx, aggregateID, customerID); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		customerID, err = s.keys.CustomerOf(ctx, aggregateID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if customerID == "" {
+		return s.inner.Serialize(ctx, event)
+	}
+
+	key, err := s.keys.DataKey(ctx, customerID)
+	if err != nil && !errors.Is(err, ErrKeyShredded) {
+		return nil, err
+	}
+
+	encrypted, err := transformPII(event, func(value string) (string, error) {
+		// Khách hàng đã yêu cầu xóa dữ liệu thì không lưu thêm dữ liệu cá nhân mới
+		if key == nil {
+			return RedactedValue, nil
+		}
+		return encryptValue(key, aggregateID, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.inner.Serialize(ctx, encrypted)
+}
+
+// Deserialize giải mã sự kiện rồi giải mã các trường dữ liệu cá nhân
+func (s *EncryptingSerializer) Deserialize(ctx context.Context, eventType domain.EventType, schemaVersion int, data []byte) (domain.Event, error) {
+	event, err := s.inner.Deserialize(ctx, eventType, schemaVersion, data)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregateID := event.GetAggregateID()
+
+	// Chỉ tra cứu khóa khi sự kiện thực sự chứa giá trị đã mã hóa
+	var key []byte
+	var loaded bool
+	loadKey := func() error {
+		if loaded {
+			return nil
+		}
+		loaded = true
+
+		customerID, err := s.keys.CustomerOf(ctx, aggregateID)
+		if err != nil || customerID == "" {
+			return err
+		}
+		key, err = s.keys.LookupKey(ctx, customerID)
+		if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrKeyShredded) {
+			return nil
+		}
+		return err
+	}
+
+	return transformPII(event, func(value string) (string, error) {
+		if !strings.HasPrefix(value, encryptedPrefix) {
+			return value, nil
+		}
+		if err := loadKey(); err != nil {
+			return "", err
+		}
+		if key == nil {
+			return RedactedValue, nil
+		}
+
+		plaintext, err := decryptValue(key, aggregateID, value)
+		if err != nil {
+			return RedactedValue, nil
+		}
+		return plaintext, nil
+	})
+}
+
+// encryptValue mã hóa một giá trị, aggregate ID được dùng làm dữ liệu xác thực
+// để bản mã không thể bị chép sang sự kiện của aggregate khác
+func encryptValue(key []byte, aggregateID, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", fmt.Errorf("lỗi khi khởi tạo mã hóa: %w", err)
+	}
+	ciphertext := seal(aead, []byte(value), []byte(aggregateID))
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptValue giải mã một giá trị được tạo bởi encryptValue
+func decryptValue(key []byte, aggregateID, value string) (string, error) {
+	ciphertext, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, ciphertext, []byte(aggregateID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// piiSubject trả về giá trị trường được đánh dấu `pii:"subject"` của sự kiện
+func piiSubject(event domain.Event) string {
+	v := reflect.ValueOf(event)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("pii") == "subject" && v.Field(i).Kind() == reflect.String {
+			return v.Field(i).String()
+		}
+	}
+	return ""
+}
+
+// transformPII trả về bản sao của sự kiện với các trường dữ liệu cá nhân đã được biến đổi bởi fn.
+// Con trỏ và slice được sao chép trước khi sửa để sự kiện gốc không bị thay đổi.
+func transformPII(event domain.Event, fn func(value string) (string, error)) (domain.Event, error) {
+	v := reflect.New(reflect.TypeOf(event)).Elem()
+	v.Set(reflect.ValueOf(event))
+	if err := walkPII(v, fn); err != nil {
+		return nil, err
+	}
+	return v.Interface().(domain.Event), nil
+}
+
+func walkPII(v reflect.Value, fn func(value string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			if t.Field(i).Tag.Get("pii") != "" && field.Kind() == reflect.String {
+				if field.String() == "" {
+					continue
+				}
+				value, err := fn(field.String())
+				if err != nil {
+					return err
+				}
+				field.SetString(value)
+				continue
+			}
+
+			if err := walkPII(field, fn); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		clone := reflect.New(v.Elem().Type())
+		clone.Elem().Set(v.Elem())
+		if err := walkPII(clone.Elem(), fn); err != nil {
+			return err
+		}
+		v.Set(clone)
+
+	case reflect.Slice:
+		elem := v.Type().Elem().Kind()
+		if v.IsNil() || (elem != reflect.Struct && elem != reflect.Ptr) {
+			return nil
+		}
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(clone, v)
+		for i := 0; i < clone.Len(); i++ {
+			if err := walkPII(clone.Index(i), fn); err != nil {
+				return err
+			}
+		}
+		v.Set(clone)
+	}
+
+	return nil
+}
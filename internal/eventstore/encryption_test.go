@@ -0,0 +1,213 @@
+package eventstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+func TestEncryptingSerializerRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for format, serializer := range EncryptSerializers(DefaultSerializers(DefaultUpcasters()), NewInMemoryKeyStore()) {
+		t.Run(format, func(t *testing.T) {
+			for _, event := range sampleEvents(2) {
+				data, err := serializer.Serialize(ctx, event)
+				if err != nil {
+					t.Fatalf("Serialize %s: %v", event.GetType(), err)
+				}
+
+				decoded, err := serializer.Deserialize(ctx, event.GetType(), serializer.SchemaVersion(event.GetType()), data)
+				if err != nil {
+					t.Fatalf("Deserialize %s: %v", event.GetType(), err)
+				}
+				assertEventEqual(t, decoded, event)
+			}
+		})
+	}
+}
+
+func TestEncryptingSerializerHidesPII(t *testing.T) {
+	ctx := context.Background()
+	serializer := NewEncryptingSerializer(NewJSONEventSerializer(DefaultUpcasters()), NewInMemoryKeyStore())
+	events := sampleEvents(1)
+
+	created := events[0].(domain.OrderCreatedEvent)
+	updated := events[1].(domain.OrderStatusUpdatedEvent)
+	cancelled := events[2].(domain.OrderCancelledEvent)
+
+	checks := []struct {
+		event  domain.Event
+		hidden []string
+		plain  []string
+	}{
+		{created, []string{created.CustomerID, created.Origin.Address, created.Destination.Address}, []string{created.TrackingNumber, created.Origin.City}},
+		{updated, []string{updated.Note, updated.CurrentLocation.Address}, []string{updated.CurrentLocation.City}},
+		{cancelled, nil, []string{cancelled.Reason}},
+	}
+
+	for _, check := range checks {
+		data, err := serializer.Serialize(ctx, check.event)
+		if err != nil {
+			t.Fatalf("Serialize %s: %v", check.event.GetType(), err)
+		}
+		for _, value := range check.hidden {
+			if bytes.Contains(data, []byte(value)) {
+				t.Errorf("%s: dữ liệu cá nhân %q xuất hiện dạng rõ: %s", check.event.GetType(), value, data)
+			}
+		}
+		for _, value := range check.plain {
+			if !bytes.Contains(data, []byte(value)) {
+				t.Errorf("%s: thiếu giá trị %q: %s", check.event.GetType(), value, data)
+			}
+		}
+	}
+
+	// Sự kiện gốc không bị thay đổi khi mã hóa
+	if updated.CurrentLocation.Address != "Kho Đà Nẵng" || created.Origin.Address != "12 Nguyễn Huệ" {
+		t.Fatal("Serialize đã sửa sự kiện gốc")
+	}
+}
+
+func TestEncryptingSerializerShredding(t *testing.T) {
+	ctx := context.Background()
+	keys := NewInMemoryKeyStore()
+	serializer := NewEncryptingSerializer(NewJSONEventSerializer(DefaultUpcasters()), keys)
+	events := sampleEvents(1)
+
+	stored := make([][]byte, len(events))
+	for i, event := range events {
+		data, err := serializer.Serialize(ctx, event)
+		if err != nil {
+			t.Fatalf("Serialize %s: %v", event.GetType(), err)
+		}
+		stored[i] = data
+	}
+
+	aggregates, err := keys.AggregatesOf(ctx, "CUS-001")
+	if err != nil || len(aggregates) != 1 || aggregates[0] != events[0].GetAggregateID() {
+		t.Fatalf("AggregatesOf = %v, %v", aggregates, err)
+	}
+	if err := keys.Shred(ctx, "CUS-001"); err != nil {
+		t.Fatalf("Shred: %v", err)
+	}
+
+	decoded := make([]domain.Event, len(events))
+	for i, event := range events {
+		decoded[i], err = serializer.Deserialize(ctx, event.GetType(), serializer.SchemaVersion(event.GetType()), stored[i])
+		if err != nil {
+			t.Fatalf("Deserialize sau khi xóa khóa %s: %v", event.GetType(), err)
+		}
+	}
+
+	created := decoded[0].(domain.OrderCreatedEvent)
+	if created.CustomerID != RedactedValue || created.Origin.Address != RedactedValue || created.Destination.Address != RedactedValue {
+		t.Fatalf("OrderCreated chưa được ẩn: %+v", created)
+	}
+	if created.TrackingNumber != "TRK-5f1d3c2b" || created.Origin.City != "Hồ Chí Minh" || len(created.Items) != 1 {
+		t.Fatalf("dữ liệu không nhạy cảm bị mất: %+v", created)
+	}
+
+	updated := decoded[1].(domain.OrderStatusUpdatedEvent)
+	if updated.Note != RedactedValue || updated.CurrentLocation.Address != RedactedValue {
+		t.Fatalf("OrderStatusUpdated chưa được ẩn: %+v", updated)
+	}
+
+	// Luồng sự kiện vẫn xây dựng lại được đơn hàng
+	order := domain.RebuildFromEvents(decoded)
+	if order == nil || order.CustomerID != RedactedValue || order.Status != domain.OrderStatusCancelled {
+		t.Fatalf("RebuildFromEvents = %+v", order)
+	}
+
+	// Sự kiện mới của khách hàng đã bị xóa không lưu lại dữ liệu cá nhân
+	data, err := serializer.Serialize(ctx, domain.NewOrderNoteAddedEvent(domain.BaseEvent{ID: "note-new", AggregateID: events[0].GetAggregateID()}, "Số điện thoại mới 0909"))
+	if err != nil {
+		t.Fatalf("Serialize sau khi xóa khóa: %v", err)
+	}
+	if bytes.Contains(data, []byte("0909")) || !bytes.Contains(data, []byte(RedactedValue)) {
+		t.Fatalf("ghi chú mới phải được ẩn: %s", data)
+	}
+}
+
+func TestEncryptingSerializerReadsLegacyPlaintext(t *testing.T) {
+	ctx := context.Background()
+	plain := NewJSONEventSerializer(DefaultUpcasters())
+	serializer := NewEncryptingSerializer(plain, NewInMemoryKeyStore())
+
+	for _, event := range sampleEvents(1) {
+		data, err := plain.Serialize(ctx, event)
+		if err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		decoded, err := serializer.Deserialize(ctx, event.GetType(), plain.SchemaVersion(event.GetType()), data)
+		if err != nil {
+			t.Fatalf("Deserialize %s: %v", event.GetType(), err)
+		}
+		assertEventEqual(t, decoded, event)
+	}
+}
+
+func TestEncryptedSaveBindsKeysInTransaction(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := NewSQLiteEventStore(ctx, "file:encrypted_save_tx?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("NewSQLiteEventStore: %v", err)
+	}
+	defer sqlite.Close()
+
+	db := sqlite.DB()
+	for _, model := range []interface{}{(*CustomerKeyRecord)(nil), (*CustomerKeySubjectRecord)(nil)} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			t.Fatalf("CreateTable: %v", err)
+		}
+	}
+	keys, err := NewPostgresKeyStore(db, bytes.Repeat([]byte{7}, dataKeySize))
+	if err != nil {
+		t.Fatalf("NewPostgresKeyStore: %v", err)
+	}
+	store, err := NewPostgresEventStoreWithSerializers(db, FormatJSON, EncryptSerializers(DefaultSerializers(DefaultUpcasters()), keys))
+	if err != nil {
+		t.Fatalf("NewPostgresEventStoreWithSerializers: %v", err)
+	}
+
+	created := sampleEvents(1)[0].(domain.OrderCreatedEvent)
+	if err := store.SaveEvents(ctx, created.AggregateID, []domain.Event{created}); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+
+	// Trùng ID sự kiện khiến việc ghi thất bại, liên kết và khóa của khách hàng mới phải bị hủy theo
+	duplicate := created
+	duplicate.AggregateID, duplicate.CustomerID = "order-duplicate", "CUS-002"
+	if err := store.SaveEvents(ctx, duplicate.AggregateID, []domain.Event{duplicate}); err == nil {
+		t.Fatal("SaveEvents với ID trùng phải lỗi")
+	}
+
+	// ctx đã hủy thì không ghi gì
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	late := created
+	late.ID, late.AggregateID, late.CustomerID = "event-late", "order-late", "CUS-003"
+	if err := store.SaveEvents(cancelled, late.AggregateID, []domain.Event{late}); err == nil {
+		t.Fatal("SaveEvents với ctx đã hủy phải lỗi")
+	}
+
+	for _, customerID := range []string{"CUS-002", "CUS-003"} {
+		if aggregates, err := keys.AggregatesOf(ctx, customerID); err != nil || len(aggregates) != 0 {
+			t.Fatalf("AggregatesOf(%s) = %v, %v, muốn không còn liên kết", customerID, aggregates, err)
+		}
+		if _, err := keys.LookupKey(ctx, customerID); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("LookupKey(%s) = %v, muốn ErrKeyNotFound", customerID, err)
+		}
+	}
+	if customerID, err := keys.CustomerOf(ctx, duplicate.AggregateID); err != nil || customerID != "" {
+		t.Fatalf("CustomerOf = %q, %v", customerID, err)
+	}
+
+	events, err := store.GetEvents(ctx, created.AggregateID)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("GetEvents = %v, %v", events, err)
+	}
+	assertEventEqual(t, events[0], created)
+}
@@ -0,0 +1,4 @@
+// Package eventpb chứa schema Protobuf của các sự kiện domain.
+package eventpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative events.proto
@@ -0,0 +1,441 @@
+// Package eventstoretest chứa bộ kiểm thử tuân thủ mà mọi triển khai eventstore.EventStore phải vượt qua
+package eventstoretest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/shopspring/decimal"
+)
+
+// baseTime là mốc thời gian của các sự kiện mẫu, mỗi sự kiện cách nhau một giây
+var baseTime = time.Date(2025, 3, 16, 8, 0, 0, 0, time.UTC)
+
+// Run chạy bộ kiểm thử tuân thủ, newStore phải trả về một store rỗng cho mỗi lần gọi
+func Run(t *testing.T, newStore func(t *testing.T) eventstore.EventStore) {
+	t.Run("SaveAndLoad", func(t *testing.T) { testSaveAndLoad(t, newStore(t)) })
+	t.Run("AppendAcrossSaves", func(t *testing.T) { testAppendAcrossSaves(t, newStore(t)) })
+	t.Run("EmptySaveAndUnknownAggregate", func(t *testing.T) { testEmpty(t, newStore(t)) })
+	t.Run("AggregatesAreIsolated", func(t *testing.T) { testIsolation(t, newStore(t)) })
+	t.Run("SaveEventsBatch", func(t *testing.T) { testSaveBatch(t, newStore(t)) })
+	t.Run("GetEventsBatch", func(t *testing.T) { testGetBatch(t, newStore(t)) })
+	t.Run("GetEventsByType", func(t *testing.T) { testByType(t, newStore(t)) })
+	t.Run("GetAllEventsPagination", func(t *testing.T) { testPagination(t, newStore(t)) })
+	t.Run("GetEventStream", func(t *testing.T) { testStream(t, newStore(t)) })
+	t.Run("EventMetadata", func(t *testing.T) { testMetadata(t, newStore(t)) })
+	t.Run("LegacyVersions", func(t *testing.T) { testLegacyVersions(t, newStore(t)) })
+}
+
+func testSaveAndLoad(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	events := orderStream("order-1", 0)
+
+	if err := store.SaveEvents(ctx, "order-1", events); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+
+	got, err := store.GetEvents(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	assertEvents(t, got, events)
+
+	order := domain.RebuildFromEvents(got)
+	if order == nil || order.Status != domain.OrderStatusInTransit || len(order.Notes) != 2 {
+		t.Fatalf("RebuildFromEvents = %+v", order)
+	}
+}
+
+func testAppendAcrossSaves(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	events := orderStream("order-1", 0)
+
+	// Lưu từng phần, phiên bản phải nối tiếp theo thứ tự lưu
+	if err := store.SaveEvents(ctx, "order-1", events[:1]); err != nil {
+		t.Fatalf("SaveEvents lần 1: %v", err)
+	}
+	if err := store.SaveEvents(ctx, "order-1", events[1:]); err != nil {
+		t.Fatalf("SaveEvents lần 2: %v", err)
+	}
+
+	got, err := store.GetEvents(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	assertEvents(t, got, events)
+
+	asOf := domain.EventsAsOf(got, domain.AsOf{Version: 2})
+	assertEvents(t, asOf, events[:2])
+}
+
+func testEmpty(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+
+	if err := store.SaveEvents(ctx, "order-1", nil); err != nil {
+		t.Fatalf("SaveEvents rỗng: %v", err)
+	}
+
+	got, err := store.GetEvents(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("GetEvents của aggregate chưa có sự kiện trả về %d sự kiện", len(got))
+	}
+
+	all, err := store.GetAllEvents(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetAllEvents: %v", err)
+	}
+	if len(all) != 0 {
+		t.Fatalf("GetAllEvents trên store rỗng trả về %d sự kiện", len(all))
+	}
+}
+
+func testIsolation(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	first := orderStream("order-1", 0)
+	second := orderStream("order-2", 10)
+
+	if err := store.SaveEvents(ctx, "order-1", first[:2]); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEvents(ctx, "order-2", second); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEvents(ctx, "order-1", first[2:]); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+
+	for aggregateID, want := range map[string][]domain.Event{"order-1": first, "order-2": second} {
+		got, err := store.GetEvents(ctx, aggregateID)
+		if err != nil {
+			t.Fatalf("GetEvents %s: %v", aggregateID, err)
+		}
+		assertEvents(t, got, want)
+	}
+}
+
+func testSaveBatch(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	first := orderStream("order-1", 0)
+	second := orderStream("order-2", 10)
+
+	if err := store.SaveEvents(ctx, "order-1", first[:1]); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+
+	// Sự kiện của hai aggregate xen kẽ nhau, luồng đã có sự kiện được nối tiếp
+	batch := []domain.Event{second[0], first[1], second[1], first[2], second[2]}
+	if err := store.SaveEventsBatch(ctx, batch); err != nil {
+		t.Fatalf("SaveEventsBatch: %v", err)
+	}
+	if err := store.SaveEventsBatch(ctx, nil); err != nil {
+		t.Fatalf("SaveEventsBatch rỗng: %v", err)
+	}
+
+	for aggregateID, want := range map[string][]domain.Event{"order-1": first, "order-2": second} {
+		got, err := store.GetEvents(ctx, aggregateID)
+		if err != nil {
+			t.Fatalf("GetEvents %s: %v", aggregateID, err)
+		}
+		assertEvents(t, got, want)
+	}
+}
+
+func testGetBatch(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	first := orderStream("order-1", 0)
+	second := orderStream("order-2", 10)
+	third := orderStream("order-3", 20)
+
+	if err := store.SaveEventsBatch(ctx, append(append(append([]domain.Event{}, first...), second...), third...)); err != nil {
+		t.Fatalf("SaveEventsBatch: %v", err)
+	}
+
+	got, err := store.GetEventsBatch(ctx, []string{"order-2", "order-1", "missing"})
+	if err != nil {
+		t.Fatalf("GetEventsBatch: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GetEventsBatch trả về %d luồng, muốn 2", len(got))
+	}
+	assertEvents(t, got["order-1"], first)
+	assertEvents(t, got["order-2"], second)
+
+	empty, err := store.GetEventsBatch(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("GetEventsBatch rỗng = %v, %v", empty, err)
+	}
+}
+
+func testByType(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	first := orderStream("order-1", 0)
+	second := orderStream("order-2", 10)
+
+	// Lưu đơn hàng sau trước để kiểm tra kết quả được sắp theo thời gian chứ không theo thứ tự lưu
+	if err := store.SaveEvents(ctx, "order-2", second); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEvents(ctx, "order-1", first); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+
+	got, err := store.GetEventsByType(ctx, domain.OrderNoteAddedType)
+	if err != nil {
+		t.Fatalf("GetEventsByType: %v", err)
+	}
+	assertEvents(t, got, []domain.Event{first[2], second[2]})
+
+	got, err = store.GetEventsByType(ctx, domain.OrderCancelledType)
+	if err != nil {
+		t.Fatalf("GetEventsByType: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("GetEventsByType loại chưa có sự kiện trả về %d sự kiện", len(got))
+	}
+}
+
+func testPagination(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	first := orderStream("order-1", 0)
+	second := orderStream("order-2", 10)
+
+	if err := store.SaveEvents(ctx, "order-2", second); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEvents(ctx, "order-1", first); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	all := append(append([]domain.Event{}, first...), second...)
+
+	got, err := store.GetAllEvents(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("GetAllEvents: %v", err)
+	}
+	assertEvents(t, got, all)
+
+	got, err = store.GetAllEvents(ctx, 2, 3)
+	if err != nil {
+		t.Fatalf("GetAllEvents có phân trang: %v", err)
+	}
+	assertEvents(t, got, all[2:5])
+
+	got, err = store.GetAllEvents(ctx, len(all), 10)
+	if err != nil {
+		t.Fatalf("GetAllEvents vượt quá số sự kiện: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("GetAllEvents vượt quá số sự kiện trả về %d sự kiện", len(got))
+	}
+}
+
+func testStream(t *testing.T, store eventstore.EventStore) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := store.GetEventStream(ctx)
+	if err != nil {
+		t.Fatalf("GetEventStream: %v", err)
+	}
+
+	// Sự kiện mang thời gian hiện tại để store dạng polling cũng nhận ra là sự kiện mới
+	event := domain.OrderNoteAddedEvent{
+		BaseEvent: domain.BaseEvent{
+			ID:          "order-1-stream",
+			AggregateID: "order-1",
+			Type:        domain.OrderNoteAddedType,
+			Timestamp:   time.Now().Add(2 * time.Second).UTC().Truncate(time.Second),
+			Version:     1,
+		},
+		Note: "sự kiện mới",
+	}
+	if err := store.SaveEvents(ctx, "order-1", []domain.Event{event}); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+
+	select {
+	case got, ok := <-stream:
+		if !ok {
+			t.Fatal("kênh bị đóng trước khi nhận sự kiện")
+		}
+		assertEvents(t, []domain.Event{got}, []domain.Event{event})
+	case <-time.After(5 * time.Second):
+		t.Fatal("không nhận được sự kiện mới từ stream")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-stream:
+		for ok {
+			_, ok = <-stream
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("kênh không được đóng khi context kết thúc")
+	}
+}
+
+func testMetadata(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	events := orderStream("order-1", 0)
+	metadata := eventstore.Metadata{"ip": "203.0.113.10", "country": "VN", "city": "Hồ Chí Minh"}
+
+	if err := store.SaveEvents(eventstore.WithMetadata(ctx, metadata), "order-1", events[:1]); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEvents(ctx, "order-1", events[1:]); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEventsBatch(eventstore.WithMetadata(ctx, eventstore.Metadata{"ip": "198.51.100.7"}), orderStream("order-2", 10)[:1]); err != nil {
+		t.Fatalf("SaveEventsBatch: %v", err)
+	}
+
+	got, err := store.GetEventMetadata(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("GetEventMetadata: %v", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[events[0].GetID()], metadata) {
+		t.Fatalf("GetEventMetadata = %v, muốn chỉ %s có %v", got, events[0].GetID(), metadata)
+	}
+
+	got, err = store.GetEventMetadata(ctx, "order-2")
+	if err != nil {
+		t.Fatalf("GetEventMetadata: %v", err)
+	}
+	if len(got) != 1 || got["order-2-0"]["ip"] != "198.51.100.7" {
+		t.Fatalf("GetEventMetadata của lô = %v", got)
+	}
+
+	// Metadata không làm thay đổi nội dung sự kiện
+	loaded, err := store.GetEvents(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	assertEvents(t, loaded, events)
+}
+
+// testLegacyVersions kiểm tra sự kiện cũ lưu phiên bản 1 cho mọi sự kiện được đọc ra với phiên bản theo vị trí trong luồng
+func testLegacyVersions(t *testing.T, store eventstore.EventStore) {
+	ctx := context.Background()
+	legacy := func(events []domain.Event) []domain.Event {
+		for i, event := range events {
+			events[i] = withVersion(event, 1)
+		}
+		return events
+	}
+	first := orderStream("order-1", 0)
+	second := orderStream("order-2", 10)
+
+	if err := store.SaveEvents(ctx, "order-1", legacy(orderStream("order-1", 0))); err != nil {
+		t.Fatalf("SaveEvents: %v", err)
+	}
+	if err := store.SaveEventsBatch(ctx, legacy(orderStream("order-2", 10))); err != nil {
+		t.Fatalf("SaveEventsBatch: %v", err)
+	}
+
+	got, err := store.GetEvents(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	assertEvents(t, got, first)
+
+	batch, err := store.GetEventsBatch(ctx, []string{"order-2"})
+	if err != nil {
+		t.Fatalf("GetEventsBatch: %v", err)
+	}
+	assertEvents(t, batch["order-2"], second)
+
+	all, err := store.GetAllEvents(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("GetAllEvents: %v", err)
+	}
+	assertEvents(t, all, append(append([]domain.Event{}, first...), second...))
+
+	notes, err := store.GetEventsByType(ctx, domain.OrderNoteAddedType)
+	if err != nil {
+		t.Fatalf("GetEventsByType: %v", err)
+	}
+	assertEvents(t, notes, []domain.Event{first[2], second[2]})
+
+	order := domain.RebuildFromEvents(got)
+	if order == nil || order.Version() != 3 {
+		t.Fatalf("RebuildFromEvents = %+v", order)
+	}
+}
+
+// orderStream tạo luồng sự kiện mẫu của một đơn hàng, offset là số giây lệch so với baseTime
+func orderStream(orderID string, offset int) []domain.Event {
+	base := func(i int, eventType domain.EventType) domain.BaseEvent {
+		return domain.BaseEvent{
+			ID:          fmt.Sprintf("%s-%d", orderID, i),
+			AggregateID: orderID,
+			Type:        eventType,
+			Timestamp:   baseTime.Add(time.Duration(offset+i) * time.Second),
+			Version:     i + 1,
+		}
+	}
+	hub := &domain.Location{Address: "Kho Đà Nẵng", City: "Đà Nẵng", Latitude: 16.0544, Longitude: 108.2022}
+
+	return []domain.Event{
+		domain.OrderCreatedEvent{
+			BaseEvent:      base(0, domain.OrderCreatedType),
+			CustomerID:     "CUS-" + orderID,
+			TrackingNumber: "TRK-" + orderID,
+			Origin:         domain.Location{Address: "12 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009},
+			Destination:    domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412},
+			Items:          []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 2, Weight: decimal.RequireFromString("0.5"), Price: decimal.NewFromInt(120000)}},
+		},
+		domain.OrderStatusUpdatedEvent{
+			BaseEvent:       base(1, domain.OrderStatusUpdatedType),
+			OldStatus:       domain.OrderStatusCreated,
+			NewStatus:       domain.OrderStatusInTransit,
+			CurrentLocation: hub,
+			Note:            "Đã rời kho",
+		},
+		domain.OrderNoteAddedEvent{
+			BaseEvent: base(2, domain.OrderNoteAddedType),
+			Note:      "Gọi trước khi giao",
+		},
+	}
+}
+
+// assertEvents so sánh hai danh sách sự kiện theo thứ tự và nội dung
+func assertEvents(t *testing.T, got, want []domain.Event) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("số sự kiện = %d, muốn %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].GetTimestamp().Equal(want[i].GetTimestamp()) {
+			t.Fatalf("sự kiện %d: Timestamp = %v, muốn %v", i, got[i].GetTimestamp(), want[i].GetTimestamp())
+		}
+		if !reflect.DeepEqual(normalize(got[i]), normalize(want[i])) {
+			t.Fatalf("sự kiện %d:\n got: %+v\nwant: %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// withVersion trả về bản sao của sự kiện với phiên bản trong dữ liệu là version
+func withVersion(event domain.Event, version int) domain.Event {
+	v := reflect.New(reflect.TypeOf(event)).Elem()
+	v.Set(reflect.ValueOf(event))
+	v.FieldByName("BaseEvent").FieldByName("Version").SetInt(int64(version))
+	return v.Interface().(domain.Event)
+}
+
+// normalize bỏ múi giờ của timestamp để so sánh nội dung sự kiện sau khi serialize
+func normalize(event domain.Event) domain.Event {
+	v := reflect.New(reflect.TypeOf(event)).Elem()
+	v.Set(reflect.ValueOf(event))
+	if base := v.FieldByName("BaseEvent"); base.IsValid() {
+		timestamp := base.FieldByName("Timestamp")
+		timestamp.Set(reflect.ValueOf(timestamp.Interface().(time.Time).UTC()))
+	}
+	return v.Interface().(domain.Event)
+}
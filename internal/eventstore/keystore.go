@@ -0,0 +1,347 @@
+package eventstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+var (
+	// ErrKeyNotFound được trả về khi khách hàng chưa có khóa dữ liệu
+	ErrKeyNotFound = errors.New("không tìm thấy khóa dữ liệu của khách hàng")
+
+	// ErrKeyShredded được trả về khi khóa dữ liệu của khách hàng đã bị xóa
+	ErrKeyShredded = errors.New("khóa dữ liệu của khách hàng đã bị xóa")
+)
+
+// dataKeySize là độ dài khóa AES-256 dùng để mã hóa dữ liệu cá nhân
+const dataKeySize = 32
+
+// KeyStore quản lý khóa dữ liệu theo từng khách hàng và liên kết aggregate với khách hàng
+type KeyStore interface {
+	// DataKey trả về khóa dữ liệu của khách hàng, tạo mới nếu chưa có
+	DataKey(ctx context.Context, customerID string) ([]byte, error)
+
+	// LookupKey trả về khóa dữ liệu đã có của khách hàng, không tạo mới
+	LookupKey(ctx context.Context, customerID string) ([]byte, error)
+
+	// BindAggregate ghi nhận aggregate thuộc về khách hàng
+	BindAggregate(ctx context.Context, aggregateID, customerID string) error
+
+	// CustomerOf trả về khách hàng sở hữu aggregate, rỗng nếu chưa được liên kết
+	CustomerOf(ctx context.Context, aggregateID string) (string, error)
+
+	// AggregatesOf trả về các aggregate thuộc về khách hàng
+	AggregatesOf(ctx context.Context, customerID string) ([]string, error)
+
+	// Shred xóa vĩnh viễn khóa dữ liệu của khách hàng
+	Shred(ctx context.Context, customerID string) error
+}
+
+// CustomerKeyRecord đại diện cho khóa dữ liệu của một khách hàng trong cơ sở dữ liệu
+type CustomerKeyRecord struct {
+	bun.BaseModel `bun:"table:customer_keys,alias:ck"`
+
+	CustomerID string     `bun:"customer_id,pk"`
+	WrappedKey []byte     `bun:"wrapped_key"`
+	CreatedAt  time.Time  `bun:"created_at,notnull,default:current_timestamp"`
+	ShreddedAt *time.Time `bun:"shredded_at"`
+}
+
+// CustomerKeySubjectRecord liên kết một aggregate với khách hàng sở hữu dữ liệu cá nhân của nó
+type CustomerKeySubjectRecord struct {
+	bun.BaseModel `bun:"table:customer_key_subjects,alias:cks"`
+
+	AggregateID string `bun:"aggregate_id,pk"`
+	CustomerID  string `bun:"customer_id,notnull"`
+}
+
+// PostgresKeyStore lưu khóa dữ liệu trong PostgreSQL, khóa được bọc bởi master key.
+// Khi được gọi trong lúc ghi sự kiện, khóa và liên kết được ghi trong cùng transaction với sự kiện.
+type PostgresKeyStore struct {
+	db     *bun.DB
+	master cipher.AEAD
+
+	// bindings lưu tạm liên kết aggregate - khách hàng vì liên kết không bao giờ thay đổi
+	mu       sync.RWMutex
+	bindings map[string]string
+}
+
+// NewPostgresKeyStore tạo key store mới, masterKey phải dài 32 byte
+func NewPostgresKeyStore(db *bun.DB, masterKey []byte) (*PostgresKeyStore, error) {
+	master, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("master key không hợp lệ: %w", err)
+	}
+
+	return &PostgresKeyStore{
+		db:       db,
+		master:   master,
+		bindings: make(map[string]string),
+	}, nil
+}
+
+// DataKey trả về khóa dữ liệu của khách hàng, tạo mới nếu chưa có
+func (s *PostgresKeyStore) DataKey(ctx context.Context, customerID string) ([]byte, error) {
+	key, err := s.LookupKey(ctx, customerID)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return key, err
+	}
+
+	key = make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("lỗi khi sinh khóa dữ liệu: %w", err)
+	}
+
+	record := CustomerKeyRecord{
+		CustomerID: customerID,
+		WrappedKey: seal(s.master, key, []byte(customerID)),
+	}
+	_, err = conn(ctx, s.db).NewInsert().
+		Model(&record).
+		On("CONFLICT (customer_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lưu khóa dữ liệu: %w", err)
+	}
+
+	// Đọc lại để dùng chung khóa nếu một tiến trình khác vừa tạo trước
+	return s.LookupKey(ctx, customerID)
+}
+
+// LookupKey trả về khóa dữ liệu đã có của khách hàng, không tạo mới
+func (s *PostgresKeyStore) LookupKey(ctx context.Context, customerID string) ([]byte, error) {
+	record := &CustomerKeyRecord{}
+	err := conn(ctx, s.db).NewSelect().
+		Model(record).
+		Where("customer_id = ?", customerID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("lỗi khi truy vấn khóa dữ liệu: %w", err)
+	}
+
+	if record.ShreddedAt != nil {
+		return nil, ErrKeyShredded
+	}
+
+	key, err := open(s.master, record.WrappedKey, []byte(customerID))
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi mở khóa dữ liệu: %w", err)
+	}
+	return key, nil
+}
+
+// BindAggregate ghi nhận aggregate thuộc về khách hàng
+func (s *PostgresKeyStore) BindAggregate(ctx context.Context, aggregateID, customerID string) error {
+	record := CustomerKeySubjectRecord{
+		AggregateID: aggregateID,
+		CustomerID:  customerID,
+	}
+	_, err := conn(ctx, s.db).NewInsert().
+		Model(&record).
+		On("CONFLICT (aggregate_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi liên kết aggregate với khách hàng: %w", err)
+	}
+	return nil
+}
+
+// CustomerOf trả về khách hàng sở hữu aggregate, rỗng nếu chưa được liên kết
+func (s *PostgresKeyStore) CustomerOf(ctx context.Context, aggregateID string) (string, error) {
+	s.mu.RLock()
+	customerID, ok := s.bindings[aggregateID]
+	s.mu.RUnlock()
+	if ok {
+		return customerID, nil
+	}
+
+	record := &CustomerKeySubjectRecord{}
+	err := conn(ctx, s.db).NewSelect().
+		Model(record).
+		Where("aggregate_id = ?", aggregateID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("lỗi khi truy vấn khách hàng của aggregate: %w", err)
+	}
+
+	// Liên kết đọc trong transaction chưa commit có thể bị hủy nên không được lưu tạm
+	if _, inTx := conn(ctx, s.db).(bun.Tx); !inTx {
+		s.mu.Lock()
+		s.bindings[aggregateID] = record.CustomerID
+		s.mu.Unlock()
+	}
+	return record.CustomerID, nil
+}
+
+// AggregatesOf trả về các aggregate thuộc về khách hàng
+func (s *PostgresKeyStore) AggregatesOf(ctx context.Context, customerID string) ([]string, error) {
+	var aggregateIDs []string
+	err := conn(ctx, s.db).NewSelect().
+		Model((*CustomerKeySubjectRecord)(nil)).
+		Column("aggregate_id").
+		Where("customer_id = ?", customerID).
+		Order("aggregate_id ASC").
+		Scan(ctx, &aggregateIDs)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn aggregate của khách hàng: %w", err)
+	}
+	return aggregateIDs, nil
+}
+
+// Shred xóa vĩnh viễn khóa dữ liệu của khách hàng.
+// Bản ghi được giữ lại với wrapped_key rỗng để các sự kiện mới của khách hàng không tạo lại khóa.
+func (s *PostgresKeyStore) Shred(ctx context.Context, customerID string) error {
+	now := time.Now()
+	record := CustomerKeyRecord{
+		CustomerID: customerID,
+		ShreddedAt: &now,
+	}
+	_, err := conn(ctx, s.db).NewInsert().
+		Model(&record).
+		On("CONFLICT (customer_id) DO UPDATE").
+		Set("wrapped_key = NULL").
+		Set("shredded_at = EXCLUDED.shredded_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi xóa khóa dữ liệu: %w", err)
+	}
+	return nil
+}
+
+// InMemoryKeyStore lưu khóa dữ liệu trong bộ nhớ, dùng cho kiểm thử và môi trường phát triển
+type InMemoryKeyStore struct {
+	mu       sync.RWMutex
+	keys     map[string][]byte
+	shredded map[string]bool
+	bindings map[string]string
+}
+
+// NewInMemoryKeyStore tạo key store trong bộ nhớ
+func NewInMemoryKeyStore() *InMemoryKeyStore {
+	return &InMemoryKeyStore{
+		keys:     make(map[string][]byte),
+		shredded: make(map[string]bool),
+		bindings: make(map[string]string),
+	}
+}
+
+// DataKey trả về khóa dữ liệu của khách hàng, tạo mới nếu chưa có
+func (s *InMemoryKeyStore) DataKey(_ context.Context, customerID string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shredded[customerID] {
+		return nil, ErrKeyShredded
+	}
+	if key, ok := s.keys[customerID]; ok {
+		return key, nil
+	}
+
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("lỗi khi sinh khóa dữ liệu: %w", err)
+	}
+	s.keys[customerID] = key
+	return key, nil
+}
+
+// LookupKey trả về khóa dữ liệu đã có của khách hàng, không tạo mới
+func (s *InMemoryKeyStore) LookupKey(_ context.Context, customerID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.shredded[customerID] {
+		return nil, ErrKeyShredded
+	}
+	key, ok := s.keys[customerID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// BindAggregate ghi nhận aggregate thuộc về khách hàng
+func (s *InMemoryKeyStore) BindAggregate(_ context.Context, aggregateID, customerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bindings[aggregateID]; !ok {
+		s.bindings[aggregateID] = customerID
+	}
+	return nil
+}
+
+// CustomerOf trả về khách hàng sở hữu aggregate, rỗng nếu chưa được liên kết
+func (s *InMemoryKeyStore) CustomerOf(_ context.Context, aggregateID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bindings[aggregateID], nil
+}
+
+// AggregatesOf trả về các aggregate thuộc về khách hàng
+func (s *InMemoryKeyStore) AggregatesOf(_ context.Context, customerID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var aggregateIDs []string
+	for aggregateID, owner := range s.bindings {
+		if owner == customerID {
+			aggregateIDs = append(aggregateIDs, aggregateID)
+		}
+	}
+	sort.Strings(aggregateIDs)
+	return aggregateIDs, nil
+}
+
+// Shred xóa vĩnh viễn khóa dữ liệu của khách hàng
+func (s *InMemoryKeyStore) Shred(_ context.Context, customerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, customerID)
+	s.shredded[customerID] = true
+	return nil
+}
+
+// newAEAD tạo bộ mã hóa AES-GCM từ khóa
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal mã hóa plaintext, nonce được đặt ở đầu kết quả
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("lỗi khi sinh nonce: %v", err))
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+// open giải mã dữ liệu được tạo bởi seal
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("dữ liệu mã hóa quá ngắn")
+	}
+	nonce, data := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, data, additionalData)
+}
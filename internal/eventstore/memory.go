@@ -0,0 +1,239 @@
+package eventstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// streamBufferSize là số sự kiện tối đa chờ trong kênh của mỗi người nghe trước khi SaveEvents bị chặn
+const streamBufferSize = 256
+
+// InMemoryEventStore lưu trữ sự kiện trong bộ nhớ, dùng cho kiểm thử và chạy local.
+// Thứ tự và cách đánh phiên bản giống PostgresEventStore.
+type InMemoryEventStore struct {
+	mu          sync.RWMutex
+	events      []domain.Event
+	streams     map[string][]domain.Event
+	metadata    map[string]Metadata // theo ID sự kiện
+	subscribers map[*memorySubscriber]struct{}
+}
+
+type memorySubscriber struct {
+	ctx    context.Context
+	events chan domain.Event
+}
+
+// NewInMemoryEventStore tạo một event store rỗng trong bộ nhớ
+func NewInMemoryEventStore() *InMemoryEventStore {
+	return &InMemoryEventStore{
+		streams:     make(map[string][]domain.Event),
+		metadata:    make(map[string]Metadata),
+		subscribers: make(map[*memorySubscriber]struct{}),
+	}
+}
+
+// SaveEvents lưu danh sách sự kiện vào cuối luồng của aggregate
+func (s *InMemoryEventStore) SaveEvents(ctx context.Context, aggregateID string, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	// Phiên bản nối tiếp luồng của aggregate giống PostgresEventStore
+	stream := s.streams[aggregateID]
+	stamped := make([]domain.Event, len(events))
+	for i, event := range events {
+		stamped[i] = withVersion(event, len(stream)+i+1)
+	}
+	events = stamped
+	s.streams[aggregateID] = append(stream, events...)
+	s.events = append(s.events, events...)
+	s.saveMetadata(ctx, events)
+	subscribers := s.subscriberList()
+	s.mu.Unlock()
+
+	return notify(ctx, subscribers, events)
+}
+
+// SaveEventsBatch lưu sự kiện của nhiều aggregate, mỗi sự kiện vào cuối luồng của aggregate của nó
+func (s *InMemoryEventStore) SaveEventsBatch(ctx context.Context, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	stamped := make([]domain.Event, len(events))
+	for i, event := range events {
+		stream := s.streams[event.GetAggregateID()]
+		stamped[i] = withVersion(event, len(stream)+1)
+		s.streams[event.GetAggregateID()] = append(stream, stamped[i])
+	}
+	events = stamped
+	s.events = append(s.events, events...)
+	s.saveMetadata(ctx, events)
+	subscribers := s.subscriberList()
+	s.mu.Unlock()
+
+	return notify(ctx, subscribers, events)
+}
+
+// saveMetadata lưu metadata trong ctx cho các sự kiện, cần giữ khóa khi gọi
+func (s *InMemoryEventStore) saveMetadata(ctx context.Context, events []domain.Event) {
+	md := MetadataFromContext(ctx)
+	if len(md) == 0 {
+		return
+	}
+	metadata := make(Metadata, len(md))
+	for key, value := range md {
+		metadata[key] = value
+	}
+	for _, event := range events {
+		s.metadata[event.GetID()] = metadata
+	}
+}
+
+// GetEventMetadata lấy metadata của các sự kiện của một aggregate theo ID sự kiện
+func (s *InMemoryEventStore) GetEventMetadata(_ context.Context, aggregateID string) (map[string]Metadata, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]Metadata)
+	for _, event := range s.streams[aggregateID] {
+		if metadata, ok := s.metadata[event.GetID()]; ok {
+			result[event.GetID()] = metadata
+		}
+	}
+	return result, nil
+}
+
+// subscriberList sao chép danh sách người nghe, cần giữ khóa khi gọi
+func (s *InMemoryEventStore) subscriberList() []*memorySubscriber {
+	subscribers := make([]*memorySubscriber, 0, len(s.subscribers))
+	for subscriber := range s.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	return subscribers
+}
+
+// notify gửi sự kiện mới tới các kênh đang lắng nghe
+func notify(ctx context.Context, subscribers []*memorySubscriber, events []domain.Event) error {
+	for _, subscriber := range subscribers {
+		for _, event := range events {
+			select {
+			case subscriber.events <- event:
+			case <-subscriber.ctx.Done():
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// GetEvents lấy tất cả sự kiện cho một aggregate theo thứ tự phiên bản
+func (s *InMemoryEventStore) GetEvents(_ context.Context, aggregateID string) ([]domain.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]domain.Event, len(s.streams[aggregateID]))
+	copy(events, s.streams[aggregateID])
+	return events, nil
+}
+
+// GetEventsBatch lấy sự kiện của nhiều aggregate theo thứ tự phiên bản
+func (s *InMemoryEventStore) GetEventsBatch(_ context.Context, aggregateIDs []string) (map[string][]domain.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	streams := make(map[string][]domain.Event, len(aggregateIDs))
+	for _, aggregateID := range aggregateIDs {
+		if stream := s.streams[aggregateID]; len(stream) > 0 {
+			streams[aggregateID] = append([]domain.Event(nil), stream...)
+		}
+	}
+	return streams, nil
+}
+
+// GetEventsByType lấy tất cả sự kiện của một loại cụ thể theo thứ tự thời gian
+func (s *InMemoryEventStore) GetEventsByType(_ context.Context, eventType domain.EventType) ([]domain.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]domain.Event, 0)
+	for _, event := range s.events {
+		if event.GetType() == eventType {
+			events = append(events, event)
+		}
+	}
+	sortByTimestamp(events)
+	return events, nil
+}
+
+// GetAllEvents lấy tất cả sự kiện theo thứ tự thời gian với phân trang
+func (s *InMemoryEventStore) GetAllEvents(_ context.Context, offset, limit int) ([]domain.Event, error) {
+	s.mu.RLock()
+	events := make([]domain.Event, len(s.events))
+	copy(events, s.events)
+	s.mu.RUnlock()
+
+	sortByTimestamp(events)
+
+	if offset > 0 {
+		if offset >= len(events) {
+			return []domain.Event{}, nil
+		}
+		events = events[offset:]
+	}
+	if limit > 0 && limit < len(events) {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// GetEventStream trả về kênh nhận các sự kiện được lưu sau khi bắt đầu lắng nghe.
+// Kênh được đóng khi ctx kết thúc.
+func (s *InMemoryEventStore) GetEventStream(ctx context.Context) (<-chan domain.Event, error) {
+	subscriber := &memorySubscriber{
+		ctx:    ctx,
+		events: make(chan domain.Event, streamBufferSize),
+	}
+
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+
+	out := make(chan domain.Event)
+	go func() {
+		defer close(out)
+		defer func() {
+			s.mu.Lock()
+			delete(s.subscribers, subscriber)
+			s.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-subscriber.events:
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// sortByTimestamp sắp xếp sự kiện theo thời gian, giữ nguyên thứ tự lưu khi trùng thời gian
+func sortByTimestamp(events []domain.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].GetTimestamp().Before(events[j].GetTimestamp())
+	})
+}
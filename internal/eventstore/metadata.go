@@ -0,0 +1,20 @@
+package eventstore
+
+import "context"
+
+// Metadata là thông tin ngữ cảnh của lệnh tạo ra sự kiện (ví dụ IP của request),
+// lưu trong cột metadata tách khỏi dữ liệu sự kiện nên không ảnh hưởng tới serializer và upcaster
+type Metadata map[string]string
+
+type metadataKey struct{}
+
+// WithMetadata gắn metadata vào ctx, các sự kiện được lưu với ctx này mang metadata đó
+func WithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext lấy metadata đã gắn vào ctx, nil nếu không có
+func MetadataFromContext(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(metadataKey{}).(Metadata)
+	return metadata
+}
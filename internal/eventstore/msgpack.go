@@ -0,0 +1,102 @@
+package eventstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// decimal.Decimal được lưu dạng chuỗi giống JSON thay vì dạng nhị phân,
+// để payload giải mã thành map vẫn dùng được với upcaster
+func init() {
+	msgpack.Register(decimal.Decimal{}, encodeDecimal, decodeDecimal)
+}
+
+func encodeDecimal(e *msgpack.Encoder, v reflect.Value) error {
+	return e.EncodeString(v.Interface().(decimal.Decimal).String())
+}
+
+func decodeDecimal(d *msgpack.Decoder, v reflect.Value) error {
+	value, err := d.DecodeInterface()
+	if err != nil {
+		return err
+	}
+
+	var result decimal.Decimal
+	switch x := value.(type) {
+	case nil:
+	case string:
+		result, err = decimal.NewFromString(x)
+	default:
+		result, err = decimal.NewFromString(fmt.Sprint(x))
+	}
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(result))
+	return nil
+}
+
+// MsgPackEventSerializer serializer sử dụng MessagePack.
+// Tên field lấy theo JSON tag để upcaster dùng chung với định dạng JSON.
+type MsgPackEventSerializer struct {
+	upcasters *UpcasterRegistry
+}
+
+// NewMsgPackEventSerializer tạo serializer MessagePack với registry upcaster cho các schema cũ
+func NewMsgPackEventSerializer(upcasters *UpcasterRegistry) *MsgPackEventSerializer {
+	return &MsgPackEventSerializer{
+		upcasters: upcasters,
+	}
+}
+
+// Format trả về tên định dạng MessagePack
+func (s *MsgPackEventSerializer) Format() string {
+	return FormatMsgPack
+}
+
+// SchemaVersion trả về phiên bản schema hiện tại của một loại sự kiện
+func (s *MsgPackEventSerializer) SchemaVersion(eventType domain.EventType) int {
+	return s.upcasters.CurrentVersion(eventType)
+}
+
+// Serialize một sự kiện thành MessagePack
+func (s *MsgPackEventSerializer) Serialize(_ context.Context, event domain.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	encoder.UseCompactInts(true)
+	encoder.UseCompactFloats(true)
+
+	if err := encoder.Encode(event); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Deserialize MessagePack thành sự kiện
+func (s *MsgPackEventSerializer) Deserialize(_ context.Context, eventType domain.EventType, schemaVersion int, data []byte) (domain.Event, error) {
+	if schemaVersion != s.SchemaVersion(eventType) {
+		var payload map[string]interface{}
+		if err := s.unmarshal(data, &payload); err != nil {
+			return nil, err
+		}
+		return decodeUpcasted(s.upcasters, eventType, schemaVersion, payload)
+	}
+
+	return domain.DecodeEvent(eventType, func(v interface{}) error {
+		return s.unmarshal(data, v)
+	})
+}
+
+func (s *MsgPackEventSerializer) unmarshal(data []byte, v interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
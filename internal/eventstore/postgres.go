@@ -1,9 +1,9 @@
 package eventstore
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
@@ -13,16 +13,34 @@ import (
 
 // PostgresEventStore lưu trữ sự kiện sử dụng PostgreSQL
 type PostgresEventStore struct {
-	db         *bun.DB
-	serializer EventSerializer
+	db          *bun.DB
+	serializer  EventSerializer
+	serializers map[string]EventSerializer
 }
 
 // NewPostgresEventStore tạo một event store mới sử dụng PostgreSQL
 func NewPostgresEventStore(db *bun.DB) *PostgresEventStore {
-	return &PostgresEventStore{
-		db:         db,
-		serializer: &JSONEventSerializer{},
+	store, _ := NewPostgresEventStoreWithSerializers(db, FormatJSON, DefaultSerializers(DefaultUpcasters()))
+	return store
+}
+
+// NewPostgresEventStoreWithSerializers tạo event store ghi sự kiện mới theo định dạng format.
+// Sự kiện đã lưu ở bất kỳ định dạng nào trong serializers vẫn đọc được dựa trên cột format.
+func NewPostgresEventStoreWithSerializers(db *bun.DB, format string, serializers map[string]EventSerializer) (*PostgresEventStore, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+
+	serializer, ok := serializers[format]
+	if !ok {
+		return nil, fmt.Errorf("định dạng sự kiện không được hỗ trợ: %s", format)
 	}
+
+	return &PostgresEventStore{
+		db:          db,
+		serializer:  serializer,
+		serializers: serializers,
+	}, nil
 }
 
 // SaveEvents lưu danh sách sự kiện vào cơ sở dữ liệu
@@ -31,60 +49,132 @@ func (s *PostgresEventStore) SaveEvents(ctx context.Context, aggregateID string,
 		return nil
 	}
 
-	// Sử dụng transaction để đảm bảo tất cả hoặc không có sự kiện nào được lưu
-	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		// Kiểm tra phiên bản hiện tại
-		var currentVersion int
-		err := tx.NewSelect().
-			Table("events").
-			ColumnExpr("MAX(version)").
-			Where("aggregate_id = ?", aggregateID).
-			Scan(ctx, &currentVersion)
-
+	// Sử dụng transaction để đảm bảo tất cả hoặc không có sự kiện nào được lưu.
+	// Serializer chạy trong transaction nên dữ liệu nó ghi (ví dụ liên kết khóa mã hóa) bị hủy cùng sự kiện.
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		ctx = withTx(ctx, tx)
+		records, err := s.serializeRecords(ctx, events)
 		if err != nil {
-			// Xử lý trường hợp không có sự kiện nào cho aggregate này
-			if err.Error() == "sql: no rows in result set" {
-				currentVersion = 0
-			} else {
-				return fmt.Errorf("lỗi khi kiểm tra phiên bản: %w", err)
-			}
+			return err
 		}
+		return appendRecords(ctx, tx, aggregateID, records)
+	})
+}
 
-		// Lưu từng sự kiện
-		for i, event := range events {
-			// Tính phiên bản mới
-			newVersion := currentVersion + i + 1
+// SaveEventsBatch lưu sự kiện của nhiều aggregate trong cùng một transaction
+func (s *PostgresEventStore) SaveEventsBatch(ctx context.Context, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
 
-			// Serialize sự kiện
-			data, err := s.serializer.Serialize(event)
-			if err != nil {
-				return fmt.Errorf("lỗi khi serialize sự kiện: %w", err)
-			}
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		ctx = withTx(ctx, tx)
+		records, err := s.serializeRecords(ctx, events)
+		if err != nil {
+			return err
+		}
 
-			// Tạo record
-			record := EventRecord{
-				ID:          event.GetID(),
-				AggregateID: event.GetAggregateID(),
-				Type:        event.GetType(),
-				Version:     newVersion,
-				Data:        data,
-				Timestamp:   event.GetTimestamp().Unix(),
+		// Nhóm bản ghi theo aggregate, giữ thứ tự xuất hiện
+		var aggregateIDs []string
+		streams := make(map[string][]EventRecord)
+		for _, record := range records {
+			if _, ok := streams[record.AggregateID]; !ok {
+				aggregateIDs = append(aggregateIDs, record.AggregateID)
 			}
+			streams[record.AggregateID] = append(streams[record.AggregateID], record)
+		}
 
-			// Lưu vào cơ sở dữ liệu
-			_, err = tx.NewInsert().
-				Model(&record).
-				Exec(ctx)
-
-			if err != nil {
-				return fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+		for _, aggregateID := range aggregateIDs {
+			if err := appendRecords(ctx, tx, aggregateID, streams[aggregateID]); err != nil {
+				return err
 			}
 		}
-
 		return nil
 	})
+}
+
+type txKey struct{}
 
-	return err
+// withTx gắn transaction đang ghi sự kiện vào ctx
+func withTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// conn trả về transaction đang ghi sự kiện trong ctx nếu có, ngược lại là db.
+// Dùng cho dữ liệu phải được ghi cùng sự kiện, db và event store phải dùng chung cơ sở dữ liệu.
+func conn(ctx context.Context, db *bun.DB) bun.IDB {
+	if tx, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// serializeRecords chuyển sự kiện thành bản ghi theo định dạng ghi của store, kèm metadata trong ctx nếu có
+func (s *PostgresEventStore) serializeRecords(ctx context.Context, events []domain.Event) ([]EventRecord, error) {
+	var metadata []byte
+	if md := MetadataFromContext(ctx); len(md) > 0 {
+		var err error
+		if metadata, err = json.Marshal(md); err != nil {
+			return nil, fmt.Errorf("lỗi khi serialize metadata: %w", err)
+		}
+	}
+
+	records := make([]EventRecord, len(events))
+	for i, event := range events {
+		data, err := s.serializer.Serialize(ctx, event)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi serialize sự kiện: %w", err)
+		}
+
+		records[i] = EventRecord{
+			ID:            event.GetID(),
+			AggregateID:   event.GetAggregateID(),
+			Type:          event.GetType(),
+			SchemaVersion: s.serializer.SchemaVersion(event.GetType()),
+			Format:        s.serializer.Format(),
+			Data:          data,
+			Metadata:      metadata,
+			Timestamp:     event.GetTimestamp().Unix(),
+		}
+	}
+	return records, nil
+}
+
+// appendRecords nối các bản ghi vào cuối luồng của aggregate trong transaction
+func appendRecords(ctx context.Context, tx bun.Tx, aggregateID string, records []EventRecord) error {
+	// Kiểm tra phiên bản hiện tại
+	var currentVersion int
+	err := tx.NewSelect().
+		Table("events").
+		ColumnExpr("MAX(version)").
+		Where("aggregate_id = ?", aggregateID).
+		Scan(ctx, &currentVersion)
+
+	if err != nil {
+		// Xử lý trường hợp không có sự kiện nào cho aggregate này
+		if err.Error() == "sql: no rows in result set" {
+			currentVersion = 0
+		} else {
+			return fmt.Errorf("lỗi khi kiểm tra phiên bản: %w", err)
+		}
+	}
+
+	// Lưu từng sự kiện
+	for i := range records {
+		// Tính phiên bản mới
+		records[i].Version = currentVersion + i + 1
+
+		// Lưu vào cơ sở dữ liệu
+		_, err = tx.NewInsert().
+			Model(&records[i]).
+			Exec(ctx)
+
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // GetEvents lấy tất cả sự kiện cho một aggregate
@@ -103,7 +193,7 @@ func (s *PostgresEventStore) GetEvents(ctx context.Context, aggregateID string)
 
 	events := make([]domain.Event, len(records))
 	for i, record := range records {
-		event, err := s.serializer.Deserialize(record.Type, record.Data)
+		event, err := s.deserialize(ctx, record)
 		if err != nil {
 			return nil, fmt.Errorf("lỗi khi deserialize sự kiện: %w", err)
 		}
@@ -113,6 +203,61 @@ func (s *PostgresEventStore) GetEvents(ctx context.Context, aggregateID string)
 	return events, nil
 }
 
+// GetEventsBatch lấy sự kiện của nhiều aggregate, mỗi luồng theo thứ tự phiên bản
+func (s *PostgresEventStore) GetEventsBatch(ctx context.Context, aggregateIDs []string) (map[string][]domain.Event, error) {
+	streams := make(map[string][]domain.Event, len(aggregateIDs))
+	if len(aggregateIDs) == 0 {
+		return streams, nil
+	}
+
+	var records []EventRecord
+	err := s.db.NewSelect().
+		Table("events").
+		Where("aggregate_id IN (?)", bun.In(aggregateIDs)).
+		Order("aggregate_id ASC", "version ASC").
+		Scan(ctx, &records)
+
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn sự kiện: %w", err)
+	}
+
+	for _, record := range records {
+		event, err := s.deserialize(ctx, record)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi deserialize sự kiện: %w", err)
+		}
+		streams[record.AggregateID] = append(streams[record.AggregateID], event)
+	}
+
+	return streams, nil
+}
+
+// GetEventMetadata lấy metadata của các sự kiện của một aggregate theo ID sự kiện
+func (s *PostgresEventStore) GetEventMetadata(ctx context.Context, aggregateID string) (map[string]Metadata, error) {
+	var records []EventRecord
+	err := s.db.NewSelect().
+		Table("events").
+		Column("id", "metadata").
+		Where("aggregate_id = ?", aggregateID).
+		Where("metadata IS NOT NULL").
+		Scan(ctx, &records)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn metadata sự kiện: %w", err)
+	}
+
+	result := make(map[string]Metadata, len(records))
+	for _, record := range records {
+		var metadata Metadata
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("lỗi khi deserialize metadata sự kiện %s: %w", record.ID, err)
+		}
+		if len(metadata) > 0 {
+			result[record.ID] = metadata
+		}
+	}
+	return result, nil
+}
+
 // GetEventsByType lấy tất cả sự kiện của một loại cụ thể
 func (s *PostgresEventStore) GetEventsByType(ctx context.Context, eventType domain.EventType) ([]domain.Event, error) {
 	var records []EventRecord
@@ -129,7 +274,7 @@ func (s *PostgresEventStore) GetEventsByType(ctx context.Context, eventType doma
 
 	events := make([]domain.Event, len(records))
 	for i, record := range records {
-		event, err := s.serializer.Deserialize(record.Type, record.Data)
+		event, err := s.deserialize(ctx, record)
 		if err != nil {
 			return nil, fmt.Errorf("lỗi khi deserialize sự kiện: %w", err)
 		}
@@ -145,7 +290,8 @@ func (s *PostgresEventStore) GetAllEvents(ctx context.Context, offset, limit int
 
 	query := s.db.NewSelect().
 		Table("events").
-		Order("timestamp ASC")
+		// Sự kiện cùng thời điểm được sắp theo aggregate và version để phân trang ổn định
+		Order("timestamp ASC", "aggregate_id ASC", "version ASC")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -162,7 +308,7 @@ func (s *PostgresEventStore) GetAllEvents(ctx context.Context, offset, limit int
 
 	events := make([]domain.Event, len(records))
 	for i, record := range records {
-		event, err := s.serializer.Deserialize(record.Type, record.Data)
+		event, err := s.deserialize(ctx, record)
 		if err != nil {
 			return nil, fmt.Errorf("lỗi khi deserialize sự kiện: %w", err)
 		}
@@ -207,7 +353,7 @@ func (s *PostgresEventStore) GetEventStream(ctx context.Context) (<-chan domain.
 				}
 
 				for _, record := range records {
-					event, err := s.serializer.Deserialize(record.Type, record.Data)
+					event, err := s.deserialize(ctx, record)
 					if err != nil {
 						fmt.Printf("lỗi khi deserialize sự kiện: %v\n", err)
 						continue
@@ -228,46 +374,81 @@ func (s *PostgresEventStore) GetEventStream(ctx context.Context) (<-chan domain.
 	return eventChan, nil
 }
 
+// deserialize giải mã một bản ghi bằng serializer tương ứng với định dạng đã lưu,
+// phiên bản của sự kiện lấy theo cột version của bản ghi
+func (s *PostgresEventStore) deserialize(ctx context.Context, record EventRecord) (domain.Event, error) {
+	format := record.Format
+	if format == "" {
+		format = FormatJSON
+	}
+
+	serializer, ok := s.serializers[format]
+	if !ok {
+		return nil, fmt.Errorf("định dạng sự kiện không được hỗ trợ: %s", format)
+	}
+
+	event, err := serializer.Deserialize(ctx, record.Type, record.SchemaVersion, record.Data)
+	if err != nil {
+		return nil, err
+	}
+	return withVersion(event, record.Version), nil
+}
+
 // JSONEventSerializer serializer sử dụng JSON
-type JSONEventSerializer struct{}
+type JSONEventSerializer struct {
+	upcasters *UpcasterRegistry
+}
+
+// NewJSONEventSerializer tạo serializer JSON với registry upcaster cho các schema cũ
+func NewJSONEventSerializer(upcasters *UpcasterRegistry) *JSONEventSerializer {
+	return &JSONEventSerializer{
+		upcasters: upcasters,
+	}
+}
+
+// Format trả về tên định dạng JSON
+func (s *JSONEventSerializer) Format() string {
+	return FormatJSON
+}
 
 // Serialize một sự kiện thành JSON
-func (s *JSONEventSerializer) Serialize(event domain.Event) ([]byte, error) {
+func (s *JSONEventSerializer) Serialize(_ context.Context, event domain.Event) ([]byte, error) {
 	return json.Marshal(event)
 }
 
-// Deserialize JSON thành sự kiện
-func (s *JSONEventSerializer) Deserialize(eventType domain.EventType, data []byte) (domain.Event, error) {
-	var event domain.Event
+// SchemaVersion trả về phiên bản schema hiện tại của một loại sự kiện
+func (s *JSONEventSerializer) SchemaVersion(eventType domain.EventType) int {
+	return s.upcasters.CurrentVersion(eventType)
+}
 
-	switch eventType {
-	case domain.OrderCreatedType:
-		var e domain.OrderCreatedEvent
-		if err := json.Unmarshal(data, &e); err != nil {
-			return nil, err
-		}
-		event = e
-	case domain.OrderStatusUpdatedType:
-		var e domain.OrderStatusUpdatedEvent
-		if err := json.Unmarshal(data, &e); err != nil {
-			return nil, err
-		}
-		event = e
-	case domain.OrderCancelledType:
-		var e domain.OrderCancelledEvent
-		if err := json.Unmarshal(data, &e); err != nil {
-			return nil, err
-		}
-		event = e
-	case domain.OrderNoteAddedType:
-		var e domain.OrderNoteAddedEvent
-		if err := json.Unmarshal(data, &e); err != nil {
+// Deserialize JSON thành sự kiện
+func (s *JSONEventSerializer) Deserialize(_ context.Context, eventType domain.EventType, schemaVersion int, data []byte) (domain.Event, error) {
+	if schemaVersion != s.SchemaVersion(eventType) {
+		upcasted, err := s.upcast(eventType, schemaVersion, data)
+		if err != nil {
 			return nil, err
 		}
-		event = e
-	default:
-		return nil, errors.New("loại sự kiện không được hỗ trợ")
+		data = upcasted
+	}
+
+	return domain.DecodeEvent(eventType, func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+}
+
+// upcast chuyển payload JSON từ phiên bản schema đã lưu lên phiên bản hiện tại
+func (s *JSONEventSerializer) upcast(eventType domain.EventType, schemaVersion int, data []byte) ([]byte, error) {
+	var payload map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	payload, err := s.upcasters.Upcast(eventType, schemaVersion, payload)
+	if err != nil {
+		return nil, err
 	}
 
-	return event, nil
+	return json.Marshal(payload)
 }
@@ -0,0 +1,128 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore/eventpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// protobufBodies ánh xạ loại sự kiện sang field tương ứng trong oneof body của Envelope
+var protobufBodies = map[domain.EventType]protoreflect.Name{
+	domain.OrderCreatedType:       "order_created",
+	domain.OrderStatusUpdatedType: "order_status_updated",
+	domain.OrderCancelledType:     "order_cancelled",
+	domain.OrderNoteAddedType:     "order_note_added",
+	domain.DeliveryEstimatedType:  "delivery_estimated",
+	domain.SLABreachedType:        "sla_breached",
+	domain.OrderPricedType:        "order_priced",
+
+	domain.PaymentPendingType:        "payment_pending",
+	domain.PaymentCollectedType:      "payment_collected",
+	domain.PaymentRemittedType:       "payment_remitted",
+	domain.PaymentRefundRequiredType: "payment_refund_required",
+	domain.PaymentRefundedType:       "payment_refunded",
+
+	domain.OrderAssignedToDriverType: "order_assigned_to_driver",
+	domain.OrderUnassignedType:       "order_unassigned",
+	domain.OrderLocationRecordedType: "order_location_recorded",
+	domain.ParcelScannedInType:       "parcel_scanned_in",
+	domain.ParcelScannedOutType:      "parcel_scanned_out",
+	domain.DriverRegisteredType:      "driver_registered",
+	domain.DriverVehicleAssignedType: "driver_vehicle_assigned",
+	domain.DriverDeactivatedType:     "driver_deactivated",
+	domain.DriverActivatedType:       "driver_activated",
+	domain.RoutePlannedType:          "route_planned",
+	domain.VehicleRegisteredType:     "vehicle_registered",
+	domain.VehicleRetiredType:        "vehicle_retired",
+}
+
+// ProtobufEventSerializer serializer sử dụng schema Protobuf trong eventpb/events.proto.
+// Sự kiện chưa có message riêng hoặc có field chưa được khai báo trong schema
+// được lưu nguyên JSON trong json_payload để không mất dữ liệu.
+// Việc chuyển đổi đi qua JSON nên chậm hơn các định dạng khác, đổi lại dữ liệu lưu nhỏ nhất.
+type ProtobufEventSerializer struct {
+	upcasters *UpcasterRegistry
+}
+
+// NewProtobufEventSerializer tạo serializer Protobuf với registry upcaster cho các schema cũ
+func NewProtobufEventSerializer(upcasters *UpcasterRegistry) *ProtobufEventSerializer {
+	return &ProtobufEventSerializer{
+		upcasters: upcasters,
+	}
+}
+
+// Format trả về tên định dạng Protobuf
+func (s *ProtobufEventSerializer) Format() string {
+	return FormatProtobuf
+}
+
+// SchemaVersion trả về phiên bản schema hiện tại của một loại sự kiện
+func (s *ProtobufEventSerializer) SchemaVersion(eventType domain.EventType) int {
+	return s.upcasters.CurrentVersion(eventType)
+}
+
+// Serialize một sự kiện thành Protobuf
+func (s *ProtobufEventSerializer) Serialize(_ context.Context, event domain.Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &eventpb.Envelope{Type: string(event.GetType())}
+	if name, ok := protobufBodies[event.GetType()]; ok {
+		field := envelope.ProtoReflect().Descriptor().Fields().ByName(name)
+		body := envelope.ProtoReflect().NewField(field).Message()
+		if err := protojson.Unmarshal(data, body.Interface()); err == nil {
+			envelope.ProtoReflect().Set(field, protoreflect.ValueOfMessage(body))
+			return proto.Marshal(envelope)
+		}
+	}
+
+	envelope.Body = &eventpb.Envelope_JsonPayload{JsonPayload: data}
+	return proto.Marshal(envelope)
+}
+
+// Deserialize Protobuf thành sự kiện
+func (s *ProtobufEventSerializer) Deserialize(_ context.Context, eventType domain.EventType, schemaVersion int, data []byte) (domain.Event, error) {
+	var envelope eventpb.Envelope
+	if err := proto.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	payload, err := s.payload(&envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	if schemaVersion != s.SchemaVersion(eventType) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return nil, err
+		}
+		return decodeUpcasted(s.upcasters, eventType, schemaVersion, fields)
+	}
+
+	return domain.DecodeEvent(eventType, func(v interface{}) error {
+		return json.Unmarshal(payload, v)
+	})
+}
+
+// payload trả về body của Envelope dưới dạng JSON với tên field giống JSON tag của domain
+func (s *ProtobufEventSerializer) payload(envelope *eventpb.Envelope) ([]byte, error) {
+	if body, ok := envelope.Body.(*eventpb.Envelope_JsonPayload); ok {
+		return body.JsonPayload, nil
+	}
+
+	message := envelope.ProtoReflect()
+	field := message.WhichOneof(message.Descriptor().Oneofs().ByName("body"))
+	if field == nil {
+		return []byte("{}"), nil
+	}
+
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(message.Get(field).Message().Interface())
+}
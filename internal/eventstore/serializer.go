@@ -0,0 +1,33 @@
+package eventstore
+
+import (
+	"encoding/json"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// DefaultSerializers trả về các serializer dùng để đọc sự kiện, theo tên định dạng
+func DefaultSerializers(upcasters *UpcasterRegistry) map[string]EventSerializer {
+	return map[string]EventSerializer{
+		FormatJSON:     NewJSONEventSerializer(upcasters),
+		FormatMsgPack:  NewMsgPackEventSerializer(upcasters),
+		FormatProtobuf: NewProtobufEventSerializer(upcasters),
+	}
+}
+
+// decodeUpcasted upcast payload dạng map lên phiên bản schema hiện tại rồi giải mã thành sự kiện
+func decodeUpcasted(upcasters *UpcasterRegistry, eventType domain.EventType, schemaVersion int, payload map[string]interface{}) (domain.Event, error) {
+	payload, err := upcasters.Upcast(eventType, schemaVersion, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.DecodeEvent(eventType, func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+}
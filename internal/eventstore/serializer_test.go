@@ -0,0 +1,386 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore/eventpb"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/proto"
+)
+
+func sampleEvents(itemCount int) []domain.Event {
+	timestamp := time.Date(2025, 3, 16, 10, 11, 46, 0, time.UTC)
+	location := &domain.Location{Address: "Kho Đà Nẵng", City: "Đà Nẵng", Latitude: 16.0544, Longitude: 108.2022}
+
+	items := make([]domain.OrderItem, itemCount)
+	for i := range items {
+		items[i] = domain.OrderItem{
+			ID:          fmt.Sprintf("ITEM-%04d", i),
+			Name:        fmt.Sprintf("Sản phẩm %d", i),
+			Description: "Hàng dễ vỡ, xin nhẹ tay",
+			Quantity:    i%5 + 1,
+			Weight:      decimal.RequireFromString("1.25"),
+			Price:       decimal.RequireFromString("199000.5"),
+		}
+	}
+	if itemCount > 0 {
+		items[0].ProductUID = "c5ec2b98-b9c6-41d3-9514-2c7c4575c4a1"
+		items[0].Product = &domain.ProductSnapshot{
+			Reference:    "PROD-202503-001",
+			Name:         "Sản phẩm 0",
+			Price:        decimal.RequireFromString("199000.5"),
+			Weight:       decimal.RequireFromString("1.25"),
+			CityID:       "dd6bec56-e7cf-4ef8-8488-0e41281c91e8",
+			SupplierID:   "324e390b-d895-40b6-9f61-fb3339aa3a47",
+			SupplierName: "Nhà sách Fahasa",
+		}
+	}
+
+	base := func(eventType domain.EventType) domain.BaseEvent {
+		return domain.BaseEvent{
+			ID:          "0b8f4c1e-2d7a-4f3b-9c61-5a0e2d9b7c11",
+			AggregateID: "5f1d3c2b-8e4a-4b6f-a1d2-c3e4f5a6b7c8",
+			Type:        eventType,
+			Timestamp:   timestamp,
+			Version:     1,
+		}
+	}
+
+	return []domain.Event{
+		domain.OrderCreatedEvent{
+			BaseEvent:      base(domain.OrderCreatedType),
+			CustomerID:     "CUS-001",
+			TrackingNumber: "TRK-5f1d3c2b",
+			Origin:         domain.Location{Address: "12 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009},
+			Destination:    domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412},
+			Items:          items,
+			ServiceLevel:   domain.ServiceLevelExpress,
+			Currency:       "VND",
+		},
+		domain.OrderStatusUpdatedEvent{
+			BaseEvent:       base(domain.OrderStatusUpdatedType),
+			OldStatus:       domain.OrderStatusCreated,
+			NewStatus:       domain.OrderStatusInTransit,
+			CurrentLocation: location,
+			Note:            "Đã rời kho",
+		},
+		domain.OrderCancelledEvent{
+			BaseEvent:      base(domain.OrderCancelledType),
+			PreviousStatus: domain.OrderStatusInTransit,
+			Reason:         "Khách hàng yêu cầu hủy",
+		},
+		domain.OrderNoteAddedEvent{
+			BaseEvent: base(domain.OrderNoteAddedType),
+			Note:      "Gọi trước khi giao",
+		},
+		domain.DeliveryEstimatedEvent{
+			BaseEvent:         base(domain.DeliveryEstimatedType),
+			EstimatedDelivery: timestamp.Add(36 * time.Hour),
+			PromisedDelivery:  timestamp.Add(42 * time.Hour),
+		},
+		domain.SLABreachedEvent{
+			BaseEvent:        base(domain.SLABreachedType),
+			PromisedDelivery: timestamp.Add(-time.Hour),
+			Status:           domain.OrderStatusInTransit,
+		},
+		domain.OrderPricedEvent{
+			BaseEvent:        base(domain.OrderPricedType),
+			ShippingFee:      decimal.NewFromInt(32000),
+			Currency:         "VND",
+			ChargeableWeight: decimal.RequireFromString("2.5"),
+			DistanceKm:       1137.4,
+		},
+		domain.PaymentPendingEvent{
+			BaseEvent: base(domain.PaymentPendingType),
+			Method:    domain.PaymentMethodCOD,
+			Amount:    decimal.RequireFromString("631000.5"),
+			Currency:  "VND",
+		},
+		domain.PaymentCollectedEvent{
+			BaseEvent: base(domain.PaymentCollectedType),
+			DriverID:  "DRV-001",
+			Amount:    decimal.RequireFromString("631000.5"),
+			Currency:  "VND",
+		},
+		domain.PaymentRemittedEvent{
+			BaseEvent: base(domain.PaymentRemittedType),
+			DriverID:  "DRV-001",
+			Amount:    decimal.RequireFromString("631000.5"),
+			Currency:  "VND",
+			Reference: "PN-20250316-001",
+		},
+		domain.PaymentRefundRequiredEvent{
+			BaseEvent: base(domain.PaymentRefundRequiredType),
+			Amount:    decimal.RequireFromString("631000.5"),
+			Currency:  "VND",
+		},
+		domain.PaymentRefundRequiredEvent{
+			BaseEvent: base(domain.PaymentRefundRequiredType),
+			Amount:    decimal.RequireFromString("35000"),
+			Currency:  "VND",
+			Method:    domain.PaymentMethodPrepaid,
+		},
+		domain.PaymentRefundedEvent{
+			BaseEvent: base(domain.PaymentRefundedType),
+			Amount:    decimal.RequireFromString("631000.5"),
+			Currency:  "VND",
+			Reference: "RF-20250316-001",
+		},
+		domain.OrderAssignedToDriverEvent{
+			BaseEvent: base(domain.OrderAssignedToDriverType),
+			DriverID:  "DRV-001",
+			VehicleID: "VEH-001",
+		},
+		domain.OrderUnassignedEvent{
+			BaseEvent: base(domain.OrderUnassignedType),
+			DriverID:  "DRV-001",
+			Reason:    "Đổi tuyến",
+		},
+		domain.OrderLocationRecordedEvent{
+			BaseEvent:  base(domain.OrderLocationRecordedType),
+			DriverID:   "DRV-001",
+			Latitude:   10.8231,
+			Longitude:  106.6297,
+			Status:     domain.OrderStatusOutForDelivery,
+			RecordedAt: timestamp.Add(-time.Minute),
+		},
+		domain.ParcelScannedInEvent{
+			BaseEvent: base(domain.ParcelScannedInType),
+			HubCode:   "HCM-01",
+			Latitude:  10.8231,
+			Longitude: 106.6297,
+		},
+		domain.ParcelScannedOutEvent{
+			BaseEvent: base(domain.ParcelScannedOutType),
+			HubCode:   "HCM-01",
+		},
+		domain.DriverRegisteredEvent{
+			BaseEvent: base(domain.DriverRegisteredType),
+			Name:      "Nguyễn Văn A",
+		},
+		domain.DriverVehicleAssignedEvent{
+			BaseEvent: base(domain.DriverVehicleAssignedType),
+			VehicleID: "VEH-001",
+		},
+		domain.DriverDeactivatedEvent{
+			BaseEvent: base(domain.DriverDeactivatedType),
+			Reason:    "Nghỉ phép",
+		},
+		domain.DriverActivatedEvent{
+			BaseEvent: base(domain.DriverActivatedType),
+		},
+		domain.RoutePlannedEvent{
+			BaseEvent:      base(domain.RoutePlannedType),
+			VehicleID:      "VEH-001",
+			StartLatitude:  10.77,
+			StartLongitude: 106.7,
+			Stops: []domain.RouteStop{
+				{OrderID: "order-1", Trip: 1, Latitude: 10.78, Longitude: 106.72, DistanceKm: 2.41},
+				{OrderID: "order-2", Trip: 2, Latitude: 10.8, Longitude: 106.71, DistanceKm: 3.51},
+			},
+			DistanceKm: 8.76,
+			Unrouted:   []string{"order-3"},
+		},
+		domain.VehicleRegisteredEvent{
+			BaseEvent:   base(domain.VehicleRegisteredType),
+			PlateNumber: "51C-123.45",
+			CapacityKg:  decimal.RequireFromString("1500.5"),
+		},
+		domain.VehicleRetiredEvent{
+			BaseEvent: base(domain.VehicleRetiredType),
+			Reason:    "Hết niên hạn",
+		},
+	}
+}
+
+func TestSerializersRoundTrip(t *testing.T) {
+	for format, serializer := range DefaultSerializers(DefaultUpcasters()) {
+		for _, event := range sampleEvents(3) {
+			t.Run(format+"/"+string(event.GetType()), func(t *testing.T) {
+				data, err := serializer.Serialize(context.Background(), event)
+				if err != nil {
+					t.Fatalf("Serialize: %v", err)
+				}
+
+				got, err := serializer.Deserialize(context.Background(), event.GetType(), serializer.SchemaVersion(event.GetType()), data)
+				if err != nil {
+					t.Fatalf("Deserialize: %v", err)
+				}
+				assertEventEqual(t, got, event)
+			})
+		}
+	}
+}
+
+func TestProtobufStoresOrderCreatedInSchema(t *testing.T) {
+	data, err := NewProtobufEventSerializer(DefaultUpcasters()).Serialize(context.Background(), sampleEvents(2)[0])
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	// Mặt hàng có ảnh chụp sản phẩm phải khớp schema, không rơi về json_payload
+	var envelope eventpb.Envelope
+	if err := proto.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	created := envelope.GetOrderCreated()
+	if created == nil {
+		t.Fatalf("body = %T, muốn OrderCreated", envelope.Body)
+	}
+	if product := created.Items[0].GetProduct(); product.GetReference() != "PROD-202503-001" || product.GetSupplierName() != "Nhà sách Fahasa" {
+		t.Fatalf("items[0].product = %v", product)
+	}
+	if item := created.Items[1]; item.GetPrice() != "199000.5" || item.GetWeight() != "1.25" || created.GetCurrency() != "VND" {
+		t.Fatalf("items[1] = %v, currency = %q", item, created.GetCurrency())
+	}
+}
+
+// TestProtobufUpcastsLegacyAmounts đọc OrderCreated v1 lưu giá và khối lượng dạng double
+func TestProtobufUpcastsLegacyAmounts(t *testing.T) {
+	data, err := proto.Marshal(&eventpb.Envelope{
+		Type: string(domain.OrderCreatedType),
+		Body: &eventpb.Envelope_OrderCreated{OrderCreated: &eventpb.OrderCreated{
+			Type:       string(domain.OrderCreatedType),
+			CustomerId: "CUS-001",
+			Items: []*eventpb.OrderItem{{
+				Id:           "ITEM-1",
+				Quantity:     2,
+				LegacyWeight: 0.3,
+				LegacyPrice:  85000.5,
+				Product:      &eventpb.ProductSnapshot{Reference: "PROD-1", LegacyPrice: 85000.5, LegacyWeight: 0.3},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	event, err := NewProtobufEventSerializer(DefaultUpcasters()).Deserialize(context.Background(), domain.OrderCreatedType, 1, data)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	created := event.(domain.OrderCreatedEvent)
+	item := created.Items[0]
+	if item.Price.String() != "85000.5" || item.Weight.String() != "0.3" || created.Currency != domain.DefaultCurrency {
+		t.Fatalf("items[0] = %+v, currency = %q", item, created.Currency)
+	}
+	if item.Product.Price.String() != "85000.5" || item.Product.Weight.String() != "0.3" {
+		t.Fatalf("items[0].product = %+v", item.Product)
+	}
+}
+
+func TestSerializersUpcastOldSchema(t *testing.T) {
+	upcasters := NewUpcasterRegistry()
+	upcasters.Register(domain.OrderNoteAddedType, 1, func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["note"] = "[v1] " + payload["note"].(string)
+		return payload, nil
+	})
+
+	event := sampleEvents(0)[3]
+	for format, serializer := range DefaultSerializers(upcasters) {
+		t.Run(format, func(t *testing.T) {
+			data, err := serializer.Serialize(context.Background(), event)
+			if err != nil {
+				t.Fatalf("Serialize: %v", err)
+			}
+
+			got, err := serializer.Deserialize(context.Background(), event.GetType(), 1, data)
+			if err != nil {
+				t.Fatalf("Deserialize: %v", err)
+			}
+			if note := got.(domain.OrderNoteAddedEvent).Note; note != "[v1] Gọi trước khi giao" {
+				t.Fatalf("Note = %q", note)
+			}
+		})
+	}
+}
+
+func TestPostgresEventStoreDecodesMixedFormats(t *testing.T) {
+	store, err := NewPostgresEventStoreWithSerializers(nil, FormatMsgPack, DefaultSerializers(DefaultUpcasters()))
+	if err != nil {
+		t.Fatalf("NewPostgresEventStoreWithSerializers: %v", err)
+	}
+	event := sampleEvents(2)[0]
+
+	for format, serializer := range DefaultSerializers(DefaultUpcasters()) {
+		data, err := serializer.Serialize(context.Background(), event)
+		if err != nil {
+			t.Fatalf("%s Serialize: %v", format, err)
+		}
+
+		got, err := store.deserialize(context.Background(), EventRecord{Type: event.GetType(), Version: event.GetVersion(), SchemaVersion: 1, Format: format, Data: data})
+		if err != nil {
+			t.Fatalf("%s deserialize: %v", format, err)
+		}
+		assertEventEqual(t, got, event)
+	}
+
+	if _, err := store.deserialize(context.Background(), EventRecord{Type: event.GetType(), Format: "xml"}); err == nil {
+		t.Fatal("định dạng không hỗ trợ phải trả về lỗi")
+	}
+	if _, err := NewPostgresEventStoreWithSerializers(nil, "xml", DefaultSerializers(DefaultUpcasters())); err == nil {
+		t.Fatal("ghi với định dạng không hỗ trợ phải trả về lỗi")
+	}
+}
+
+func assertEventEqual(t *testing.T, got, want domain.Event) {
+	t.Helper()
+	if !got.GetTimestamp().Equal(want.GetTimestamp()) {
+		t.Fatalf("Timestamp = %v, muốn %v", got.GetTimestamp(), want.GetTimestamp())
+	}
+
+	// Các định dạng có thể giải mã thời gian theo múi giờ khác nhau nên mọi trường thời gian được đưa về UTC
+	normalize := func(event domain.Event) domain.Event {
+		value := reflect.New(reflect.TypeOf(event)).Elem()
+		value.Set(reflect.ValueOf(event))
+		value.FieldByName("BaseEvent").FieldByName("Timestamp").Set(reflect.ValueOf(time.Time{}))
+		for i := 0; i < value.NumField(); i++ {
+			if field, ok := value.Field(i).Interface().(time.Time); ok {
+				value.Field(i).Set(reflect.ValueOf(field.UTC()))
+			}
+		}
+		return value.Interface().(domain.Event)
+	}
+	if !reflect.DeepEqual(normalize(got), normalize(want)) {
+		t.Fatalf("sự kiện khác nhau:\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+// BenchmarkSerializers so sánh kích thước và tốc độ của các định dạng với OrderCreated có nhiều mục
+func BenchmarkSerializers(b *testing.B) {
+	event := sampleEvents(200)[0]
+	serializers := DefaultSerializers(DefaultUpcasters())
+
+	for _, format := range []string{FormatJSON, FormatMsgPack, FormatProtobuf} {
+		serializer := serializers[format]
+		data, err := serializer.Serialize(context.Background(), event)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(format+"/serialize", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := serializer.Serialize(context.Background(), event); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "bytes/event")
+		})
+
+		b.Run(format+"/deserialize", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := serializer.Deserialize(context.Background(), event.GetType(), 1, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "bytes/event")
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// SQLiteEventStore lưu trữ sự kiện trong SQLite, dùng cho kiểm thử và chạy local.
+// Truy vấn giống hệt PostgresEventStore vì cả hai đều dùng bun.
+type SQLiteEventStore struct {
+	*PostgresEventStore
+	db *bun.DB
+}
+
+// NewSQLiteEventStore mở cơ sở dữ liệu SQLite theo dsn và tạo bảng events nếu chưa có.
+// Dùng "file::memory:?cache=shared" cho cơ sở dữ liệu trong bộ nhớ hoặc đường dẫn file cho dữ liệu lâu dài.
+func NewSQLiteEventStore(ctx context.Context, dsn string) (*SQLiteEventStore, error) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi mở SQLite: %w", err)
+	}
+	// SQLite chỉ cho phép một kết nối ghi tại một thời điểm
+	sqldb.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	if err := CreateEventsTable(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store, err := NewPostgresEventStoreWithSerializers(db, FormatJSON, DefaultSerializers(DefaultUpcasters()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteEventStore{
+		PostgresEventStore: store,
+		db:                 db,
+	}, nil
+}
+
+// DB trả về kết nối bun của store
+func (s *SQLiteEventStore) DB() *bun.DB {
+	return s.db
+}
+
+// Close đóng kết nối tới SQLite
+func (s *SQLiteEventStore) Close() error {
+	return s.db.Close()
+}
+
+// CreateEventsTable tạo bảng events cùng các index nếu chưa có, dùng khi không chạy migrations
+func CreateEventsTable(ctx context.Context, db *bun.DB) error {
+	_, err := db.NewCreateTable().
+		Model((*EventRecord)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi tạo bảng events: %w", err)
+	}
+
+	indexes := map[string]string{
+		"idx_events_aggregate_id": "aggregate_id",
+		"idx_events_type":         "type",
+		"idx_events_timestamp":    "timestamp",
+	}
+	for name, column := range indexes {
+		_, err = db.NewCreateIndex().
+			Model((*EventRecord)(nil)).
+			Index(name).
+			Column(column).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi tạo index %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
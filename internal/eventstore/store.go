@@ -3,6 +3,7 @@ package eventstore
 import (
 	"context"
 	"github.com/uptrace/bun"
+	"reflect"
 	"time"
 
 	"github.com/quyenle-97/init/internal/domain"
@@ -13,38 +14,82 @@ type EventStore interface {
 	// SaveEvents lưu các sự kiện mới cho một aggregate
 	SaveEvents(ctx context.Context, aggregateID string, events []domain.Event) error
 
+	// SaveEventsBatch lưu sự kiện của nhiều aggregate cùng lúc, tất cả hoặc không sự kiện nào được lưu
+	SaveEventsBatch(ctx context.Context, events []domain.Event) error
+
 	// GetEvents lấy tất cả các sự kiện cho một aggregate
 	GetEvents(ctx context.Context, aggregateID string) ([]domain.Event, error)
 
+	// GetEventsBatch lấy sự kiện của nhiều aggregate trong một lần truy vấn, aggregate chưa có sự kiện không có trong kết quả
+	GetEventsBatch(ctx context.Context, aggregateIDs []string) (map[string][]domain.Event, error)
+
 	// GetEventsByType lấy tất cả các sự kiện của một loại cụ thể
 	GetEventsByType(ctx context.Context, eventType domain.EventType) ([]domain.Event, error)
 
 	// GetAllEvents lấy tất cả các sự kiện trong hệ thống, có thể phân trang
 	GetAllEvents(ctx context.Context, offset, limit int) ([]domain.Event, error)
 
+	// GetEventMetadata lấy metadata của các sự kiện của một aggregate theo ID sự kiện, sự kiện không có metadata bị bỏ qua
+	GetEventMetadata(ctx context.Context, aggregateID string) (map[string]Metadata, error)
+
 	// GetEventStream trả về một kênh để lắng nghe các sự kiện mới
 	GetEventStream(ctx context.Context) (<-chan domain.Event, error)
 }
 
+// Các định dạng serialize sự kiện được hỗ trợ
+const (
+	FormatJSON     = "json"
+	FormatMsgPack  = "msgpack"
+	FormatProtobuf = "protobuf"
+)
+
 // EventSerializer interface để serialize và deserialize các sự kiện
 type EventSerializer interface {
-	// Serialize chuyển đổi một sự kiện thành dữ liệu nhị phân
-	Serialize(event domain.Event) ([]byte, error)
+	// Format trả về tên định dạng được lưu cùng mỗi sự kiện
+	Format() string
 
-	// Deserialize chuyển đổi dữ liệu nhị phân thành sự kiện
-	Deserialize(eventType domain.EventType, data []byte) (domain.Event, error)
+	// Serialize chuyển đổi một sự kiện thành dữ liệu nhị phân.
+	// Khi được gọi từ SaveEvents, ctx mang transaction đang ghi sự kiện.
+	Serialize(ctx context.Context, event domain.Event) ([]byte, error)
+
+	// Deserialize chuyển đổi dữ liệu nhị phân thành sự kiện, upcast từ phiên bản schema đã lưu
+	Deserialize(ctx context.Context, eventType domain.EventType, schemaVersion int, data []byte) (domain.Event, error)
+
+	// SchemaVersion trả về phiên bản schema hiện tại của một loại sự kiện
+	SchemaVersion(eventType domain.EventType) int
 }
 
 // EventRecord đại diện cho một bản ghi sự kiện trong cơ sở dữ liệu
 type EventRecord struct {
 	bun.BaseModel `bun:"table:events,alias:e"`
 
-	ID          string           `bun:"id,pk"`
-	AggregateID string           `bun:"aggregate_id,notnull"`
-	Type        domain.EventType `json:"type"`
-	Version     int              `bun:"version,notnull"`
-	Data        []byte           `bun:"data,notnull"`
-	Metadata    []byte           `bun:"metadata"`
-	Timestamp   int64            `bun:"timestamp,notnull"`
-	CreatedAt   time.Time        `bun:"created_at,notnull,default:current_timestamp"`
+	ID            string           `bun:"id,pk"`
+	AggregateID   string           `bun:"aggregate_id,notnull"`
+	Type          domain.EventType `json:"type"`
+	Version       int              `bun:"version,notnull"`
+	SchemaVersion int              `bun:"schema_version,notnull,default:1"`
+	Format        string           `bun:"format,notnull,default:'json'"`
+	Data          []byte           `bun:"data,notnull"`
+	Metadata      []byte           `bun:"metadata"`
+	Timestamp     int64            `bun:"timestamp,notnull"`
+	CreatedAt     time.Time        `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+// withVersion trả về bản sao của sự kiện mang phiên bản theo vị trí trong luồng.
+// Sự kiện cũ lưu phiên bản cố định là 1 trong dữ liệu nên phiên bản phải lấy từ store khi đọc.
+func withVersion(event domain.Event, version int) domain.Event {
+	if event.GetVersion() == version {
+		return event
+	}
+	v := reflect.New(reflect.TypeOf(event)).Elem()
+	v.Set(reflect.ValueOf(event))
+	if v.Kind() != reflect.Struct {
+		return event
+	}
+	field := v.FieldByName("BaseEvent")
+	if !field.IsValid() {
+		return event
+	}
+	field.FieldByName("Version").SetInt(int64(version))
+	return v.Interface().(domain.Event)
 }
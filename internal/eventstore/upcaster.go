@@ -0,0 +1,148 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// UpcastFunc chuyển payload của một sự kiện từ một phiên bản schema lên phiên bản kế tiếp
+type UpcastFunc func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// UpcasterRegistry quản lý các upcaster theo loại sự kiện và phiên bản schema
+type UpcasterRegistry struct {
+	upcasters map[domain.EventType]map[int]UpcastFunc
+}
+
+// NewUpcasterRegistry tạo một registry upcaster rỗng
+func NewUpcasterRegistry() *UpcasterRegistry {
+	return &UpcasterRegistry{
+		upcasters: make(map[domain.EventType]map[int]UpcastFunc),
+	}
+}
+
+// DefaultUpcasters trả về các upcaster của hệ thống.
+// Khi thay đổi cấu trúc một sự kiện, đăng ký upcaster từ phiên bản cũ tại đây
+// và thêm golden fixture tương ứng trong testdata/upcast.
+func DefaultUpcasters() *UpcasterRegistry {
+	registry := NewUpcasterRegistry()
+	registry.Register(domain.OrderCreatedType, 1, upcastOrderCreatedDecimals)
+	return registry
+}
+
+// upcastOrderCreatedDecimals chuyển OrderCreated v1 lên v2: giá và khối lượng của mặt hàng
+// và ảnh chụp sản phẩm từ số thực sang chuỗi thập phân, đơn hàng cũ dùng tiền tệ mặc định.
+// Bản ghi Protobuf v1 giữ giá và khối lượng trong các field legacy_price, legacy_weight.
+func upcastOrderCreatedDecimals(payload map[string]interface{}) (map[string]interface{}, error) {
+	if currency, _ := payload["currency"].(string); currency == "" {
+		payload["currency"] = domain.DefaultCurrency
+	}
+
+	items, _ := payload["items"].([]interface{})
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("items[%d] không phải object", i)
+		}
+		if err := upcastDecimalFields(fields); err != nil {
+			return nil, fmt.Errorf("items[%d]: %w", i, err)
+		}
+		if product, ok := fields["product"].(map[string]interface{}); ok {
+			if err := upcastDecimalFields(product); err != nil {
+				return nil, fmt.Errorf("items[%d].product: %w", i, err)
+			}
+		}
+	}
+
+	return payload, nil
+}
+
+// upcastDecimalFields đổi price và weight sang chuỗi thập phân
+func upcastDecimalFields(fields map[string]interface{}) error {
+	for _, name := range []string{"price", "weight"} {
+		legacy := "legacy_" + name
+		if value, ok := fields[legacy]; ok {
+			if _, exists := fields[name]; !exists {
+				fields[name] = value
+			}
+			delete(fields, legacy)
+		}
+
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		converted, err := decimalString(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fields[name] = converted
+	}
+	return nil
+}
+
+// decimalString biểu diễn số đọc từ JSON hoặc MessagePack dưới dạng chuỗi thập phân ngắn nhất
+func decimalString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case nil:
+		return "0", nil
+	default:
+		return "", fmt.Errorf("giá trị %v không phải số", value)
+	}
+}
+
+// Register đăng ký upcaster chuyển sự kiện từ fromVersion lên fromVersion+1
+func (r *UpcasterRegistry) Register(eventType domain.EventType, fromVersion int, fn UpcastFunc) {
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[int]UpcastFunc)
+	}
+	r.upcasters[eventType][fromVersion] = fn
+}
+
+// CurrentVersion trả về phiên bản schema hiện tại của một loại sự kiện
+func (r *UpcasterRegistry) CurrentVersion(eventType domain.EventType) int {
+	version := 1
+	if r == nil {
+		return version
+	}
+	for {
+		if _, ok := r.upcasters[eventType][version]; !ok {
+			return version
+		}
+		version++
+	}
+}
+
+// Upcast chuyển payload từ phiên bản schema đã lưu lên phiên bản hiện tại
+func (r *UpcasterRegistry) Upcast(eventType domain.EventType, version int, payload map[string]interface{}) (map[string]interface{}, error) {
+	if version < 1 {
+		version = 1
+	}
+
+	current := r.CurrentVersion(eventType)
+	if version > current {
+		return nil, fmt.Errorf("sự kiện %s có phiên bản schema %d mới hơn phiên bản hỗ trợ %d", eventType, version, current)
+	}
+
+	for ; version < current; version++ {
+		var err error
+		payload, err = r.upcasters[eventType][version](payload)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi upcast sự kiện %s từ phiên bản %d: %w", eventType, version, err)
+		}
+	}
+
+	return payload, nil
+}
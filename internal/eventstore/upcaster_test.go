@@ -0,0 +1,169 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+var fixtureVersionPattern = regexp.MustCompile(`^v(\d+)\.json$`)
+
+// TestUpcastGoldenFixtures giải mã mọi fixture lịch sử trong testdata/upcast/<TYPE>/v<N>.json
+// và so sánh với hình dạng hiện tại trong current.json
+func TestUpcastGoldenFixtures(t *testing.T) {
+	upcasters := DefaultUpcasters()
+	serializer := NewJSONEventSerializer(upcasters)
+
+	for _, eventType := range domain.RegisteredEventTypes() {
+		t.Run(string(eventType), func(t *testing.T) {
+			dir := filepath.Join("testdata", "upcast", string(eventType))
+			want := readFixture(t, filepath.Join(dir, "current.json"))
+
+			fixtures := fixtureVersions(t, dir)
+			for version := 1; version <= upcasters.CurrentVersion(eventType); version++ {
+				if _, ok := fixtures[version]; !ok {
+					t.Fatalf("thiếu golden fixture v%d.json cho %s", version, eventType)
+				}
+			}
+
+			for version, path := range fixtures {
+				t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+					event, err := serializer.Deserialize(context.Background(), eventType, version, readFixture(t, path))
+					if err != nil {
+						t.Fatalf("Deserialize: %v", err)
+					}
+					if event.GetType() != eventType {
+						t.Fatalf("loại sự kiện = %s, muốn %s", event.GetType(), eventType)
+					}
+
+					got, err := serializer.Serialize(context.Background(), event)
+					if err != nil {
+						t.Fatalf("Serialize: %v", err)
+					}
+					assertJSONEqual(t, got, want)
+				})
+			}
+		})
+	}
+}
+
+func TestUpcasterRegistryChain(t *testing.T) {
+	registry := NewUpcasterRegistry()
+	registry.Register(domain.OrderStatusUpdatedType, 1, func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["comment"] = payload["note"]
+		delete(payload, "note")
+		return payload, nil
+	})
+	registry.Register(domain.OrderStatusUpdatedType, 2, func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["channel"] = "legacy"
+		return payload, nil
+	})
+
+	if got := registry.CurrentVersion(domain.OrderStatusUpdatedType); got != 3 {
+		t.Fatalf("CurrentVersion = %d, muốn 3", got)
+	}
+	if got := registry.CurrentVersion(domain.OrderCreatedType); got != 1 {
+		t.Fatalf("CurrentVersion của sự kiện chưa có upcaster = %d, muốn 1", got)
+	}
+
+	payload, err := registry.Upcast(domain.OrderStatusUpdatedType, 1, map[string]interface{}{"note": "hello"})
+	if err != nil {
+		t.Fatalf("Upcast: %v", err)
+	}
+	want := map[string]interface{}{"comment": "hello", "channel": "legacy"}
+	if !reflect.DeepEqual(payload, want) {
+		t.Fatalf("Upcast = %v, muốn %v", payload, want)
+	}
+
+	payload, err = registry.Upcast(domain.OrderStatusUpdatedType, 2, map[string]interface{}{"comment": "hi"})
+	if err != nil {
+		t.Fatalf("Upcast từ v2: %v", err)
+	}
+	if payload["channel"] != "legacy" || payload["comment"] != "hi" {
+		t.Fatalf("Upcast từ v2 = %v", payload)
+	}
+
+	if _, err := registry.Upcast(domain.OrderStatusUpdatedType, 4, map[string]interface{}{}); err == nil {
+		t.Fatal("Upcast phiên bản mới hơn phiên bản hỗ trợ phải trả về lỗi")
+	}
+}
+
+func TestJSONEventSerializerUpcastsOldSchema(t *testing.T) {
+	registry := NewUpcasterRegistry()
+	registry.Register(domain.OrderNoteAddedType, 1, func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["note"] = "[v1] " + payload["text"].(string)
+		delete(payload, "text")
+		return payload, nil
+	})
+	serializer := NewJSONEventSerializer(registry)
+
+	if got := serializer.SchemaVersion(domain.OrderNoteAddedType); got != 2 {
+		t.Fatalf("SchemaVersion = %d, muốn 2", got)
+	}
+
+	event, err := serializer.Deserialize(context.Background(), domain.OrderNoteAddedType, 1, []byte(`{"type":"ORDER_NOTE_ADDED","text":"gọi trước"}`))
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if note := event.(domain.OrderNoteAddedEvent).Note; note != "[v1] gọi trước" {
+		t.Fatalf("Note = %q", note)
+	}
+
+	event, err = serializer.Deserialize(context.Background(), domain.OrderNoteAddedType, 2, []byte(`{"type":"ORDER_NOTE_ADDED","note":"mới"}`))
+	if err != nil {
+		t.Fatalf("Deserialize phiên bản hiện tại: %v", err)
+	}
+	if note := event.(domain.OrderNoteAddedEvent).Note; note != "mới" {
+		t.Fatalf("Note = %q", note)
+	}
+}
+
+func fixtureVersions(t *testing.T, dir string) map[int]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("đọc thư mục fixture: %v", err)
+	}
+
+	fixtures := make(map[int]string)
+	for _, entry := range entries {
+		match := fixtureVersionPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		fixtures[version] = filepath.Join(dir, entry.Name())
+	}
+	return fixtures
+}
+
+func readFixture(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("đọc fixture: %v", err)
+	}
+	return data
+}
+
+func assertJSONEqual(t *testing.T, got, want []byte) {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("unmarshal got: %v", err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("unmarshal want: %v", err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("JSON khác nhau:\n got: %s\nwant: %s", got, want)
+	}
+}
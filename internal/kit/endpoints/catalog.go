@@ -0,0 +1,26 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// catalogResponse bọc kết quả theo định dạng meta/data của các API danh mục
+func catalogResponse(ctx context.Context, result interface{}, paging *utils.Pagination) interface{} {
+	return utils.SetHttpResponse(ctx, utils.Message{Code: http.StatusOK, Message: "success"}, result, paging)
+}
+
+// catalogError chuyển lỗi của service thành mã phản hồi: 404 khi không tìm thấy, 422 khi dữ liệu không hợp lệ
+func catalogError(message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrRecordNotFound):
+		return utils.Message{Code: http.StatusNotFound, Message: message + err.Error()}
+	case errors.Is(err, services.ErrInvalidRecord):
+		return utils.Message{Code: http.StatusUnprocessableEntity, Message: message + err.Error()}
+	}
+	return errors.New(message + err.Error())
+}
@@ -0,0 +1,75 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// CategoryEndpoints chứa các endpoint quản lý danh mục
+type CategoryEndpoints struct {
+	ListCategories endpoint.Endpoint
+	CreateCategory endpoint.Endpoint
+	UpdateCategory endpoint.Endpoint
+	DeleteCategory endpoint.Endpoint
+}
+
+// NewCategoryEndpoints tạo các endpoint quản lý danh mục
+func NewCategoryEndpoints(s services.CategoryService) CategoryEndpoints {
+	return CategoryEndpoints{
+		ListCategories: makeListCategoriesEndpoint(s),
+		CreateCategory: makeCreateCategoryEndpoint(s),
+		UpdateCategory: makeUpdateCategoryEndpoint(s),
+		DeleteCategory: makeDeleteCategoryEndpoint(s),
+	}
+}
+
+func makeListCategoriesEndpoint(s services.CategoryService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.SearchRequest)
+		categories, err := s.ListCategories(ctx, req.Search, req.Offset, req.Limit)
+		if err != nil {
+			return nil, catalogError("Lỗi khi lấy danh sách danh mục: ", err)
+		}
+
+		return catalogResponse(ctx, categories, &utils.Pagination{Limit: req.Limit, Offset: req.Offset}), nil
+	}
+}
+
+func makeCreateCategoryEndpoint(s services.CategoryService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.CreateCategoryRequest)
+		category, err := s.CreateCategory(ctx, req.Name)
+		if err != nil {
+			return nil, catalogError("Lỗi khi tạo danh mục: ", err)
+		}
+
+		return catalogResponse(ctx, category, nil), nil
+	}
+}
+
+func makeUpdateCategoryEndpoint(s services.CategoryService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.UpdateCategoryRequest)
+		category, err := s.UpdateCategory(ctx, req.ID, req.Name, req.Status)
+		if err != nil {
+			return nil, catalogError("Lỗi khi cập nhật danh mục: ", err)
+		}
+
+		return catalogResponse(ctx, category, nil), nil
+	}
+}
+
+func makeDeleteCategoryEndpoint(s services.CategoryService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RecordRequest)
+		if err := s.DeleteCategory(ctx, req.ID); err != nil {
+			return nil, catalogError("Lỗi khi xóa danh mục: ", err)
+		}
+
+		return catalogResponse(ctx, nil, nil), nil
+	}
+}
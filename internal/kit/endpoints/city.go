@@ -0,0 +1,76 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// CityEndpoints chứa các endpoint quản lý thành phố
+type CityEndpoints struct {
+	ListCities endpoint.Endpoint
+	CreateCity endpoint.Endpoint
+	UpdateCity endpoint.Endpoint
+	DeleteCity endpoint.Endpoint
+}
+
+// NewCityEndpoints tạo các endpoint quản lý thành phố
+func NewCityEndpoints(s services.CityService) CityEndpoints {
+	return CityEndpoints{
+		ListCities: makeListCitiesEndpoint(s),
+		CreateCity: makeCreateCityEndpoint(s),
+		UpdateCity: makeUpdateCityEndpoint(s),
+		DeleteCity: makeDeleteCityEndpoint(s),
+	}
+}
+
+func makeListCitiesEndpoint(s services.CityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.SearchRequest)
+		cities, err := s.ListCities(ctx, req.Search, req.Offset, req.Limit)
+		if err != nil {
+			return nil, catalogError("Lỗi khi lấy danh sách thành phố: ", err)
+		}
+
+		return catalogResponse(ctx, cities, &utils.Pagination{Limit: req.Limit, Offset: req.Offset}), nil
+	}
+}
+
+func makeCreateCityEndpoint(s services.CityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.CreateCityRequest)
+		city, err := s.CreateCity(ctx, models.GEO{Name: req.Name, Latitude: *req.Latitude, Longitude: *req.Longitude})
+		if err != nil {
+			return nil, catalogError("Lỗi khi tạo thành phố: ", err)
+		}
+
+		return catalogResponse(ctx, city, nil), nil
+	}
+}
+
+func makeUpdateCityEndpoint(s services.CityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.UpdateCityRequest)
+		city, err := s.UpdateCity(ctx, req.ID, req.Name, req.Latitude, req.Longitude)
+		if err != nil {
+			return nil, catalogError("Lỗi khi cập nhật thành phố: ", err)
+		}
+
+		return catalogResponse(ctx, city, nil), nil
+	}
+}
+
+func makeDeleteCityEndpoint(s services.CityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RecordRequest)
+		if err := s.DeleteCity(ctx, req.ID); err != nil {
+			return nil, catalogError("Lỗi khi xóa thành phố: ", err)
+		}
+
+		return catalogResponse(ctx, nil, nil), nil
+	}
+}
@@ -0,0 +1,230 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+type FleetEndpoints struct {
+	RegisterDriver   endpoint.Endpoint
+	GetDriver        endpoint.Endpoint
+	AssignVehicle    endpoint.Endpoint
+	DeactivateDriver endpoint.Endpoint
+	ActivateDriver   endpoint.Endpoint
+	DriverOrders     endpoint.Endpoint
+	RegisterVehicle  endpoint.Endpoint
+	GetVehicle       endpoint.Endpoint
+	RetireVehicle    endpoint.Endpoint
+	AssignOrder      endpoint.Endpoint
+	UnassignOrder    endpoint.Endpoint
+}
+
+// NewFleetEndpoints tạo các endpoints cho fleet service
+func NewFleetEndpoints(s services.FleetService) FleetEndpoints {
+	return FleetEndpoints{
+		RegisterDriver:   makeRegisterDriverEndpoint(s),
+		GetDriver:        makeGetDriverEndpoint(s),
+		AssignVehicle:    makeAssignVehicleEndpoint(s),
+		DeactivateDriver: makeDeactivateDriverEndpoint(s),
+		ActivateDriver:   makeActivateDriverEndpoint(s),
+		DriverOrders:     makeDriverOrdersEndpoint(s),
+		RegisterVehicle:  makeRegisterVehicleEndpoint(s),
+		GetVehicle:       makeGetVehicleEndpoint(s),
+		RetireVehicle:    makeRetireVehicleEndpoint(s),
+		AssignOrder:      makeAssignOrderEndpoint(s),
+		UnassignOrder:    makeUnassignOrderEndpoint(s),
+	}
+}
+
+func makeRegisterDriverEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RegisterDriverRequest)
+		id, err := s.RegisterDriver(ctx, req.Name)
+		if err != nil {
+			return nil, errors.New("Lỗi khi đăng ký tài xế: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			ID:      id,
+			Status:  "success",
+			Message: "Đã đăng ký tài xế",
+		}, nil
+	}
+}
+
+func makeGetDriverEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.FleetIDRequest)
+		driver, err := s.GetDriver(ctx, req.ID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy thông tin tài xế: " + err.Error())
+		}
+
+		return driverResponse(driver), nil
+	}
+}
+
+func makeAssignVehicleEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.AssignVehicleRequest)
+		if err := s.AssignVehicle(ctx, req.DriverID, req.VehicleID); err != nil {
+			return nil, errors.New("Lỗi khi gán phương tiện: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			Status:  "success",
+			Message: "Đã gán phương tiện cho tài xế",
+		}, nil
+	}
+}
+
+func makeDeactivateDriverEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.FleetReasonRequest)
+		if err := s.DeactivateDriver(ctx, req.ID, req.Reason); err != nil {
+			return nil, errors.New("Lỗi khi ngừng hoạt động tài xế: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			Status:  "success",
+			Message: "Tài xế đã ngừng hoạt động",
+		}, nil
+	}
+}
+
+func makeActivateDriverEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.FleetIDRequest)
+		if err := s.ActivateDriver(ctx, req.ID); err != nil {
+			return nil, errors.New("Lỗi khi kích hoạt tài xế: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			Status:  "success",
+			Message: "Tài xế đã hoạt động trở lại",
+		}, nil
+	}
+}
+
+func makeDriverOrdersEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.DriverOrdersRequest)
+		result, err := s.DriverOrders(ctx, req.DriverID, req.Status)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy đơn hàng của tài xế: " + err.Error())
+		}
+
+		response := &transforms.DriverOrdersResponse{
+			Driver: driverResponse(result.Driver),
+			LoadKg: result.LoadKg,
+			Items:  make([]transforms.OrderSummaryResponse, len(result.Orders)),
+		}
+		if result.Vehicle != nil {
+			vehicle := vehicleResponse(result.Vehicle)
+			response.Vehicle = &vehicle
+			response.CapacityKg = &result.Vehicle.CapacityKg
+		}
+		for i, order := range result.Orders {
+			response.Items[i] = orderSummary(order)
+		}
+		return response, nil
+	}
+}
+
+func makeRegisterVehicleEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RegisterVehicleRequest)
+		id, err := s.RegisterVehicle(ctx, req.PlateNumber, req.CapacityKg)
+		if err != nil {
+			return nil, errors.New("Lỗi khi đăng ký phương tiện: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			ID:      id,
+			Status:  "success",
+			Message: "Đã đăng ký phương tiện",
+		}, nil
+	}
+}
+
+func makeGetVehicleEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.FleetIDRequest)
+		vehicle, err := s.GetVehicle(ctx, req.ID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy thông tin phương tiện: " + err.Error())
+		}
+
+		return vehicleResponse(vehicle), nil
+	}
+}
+
+func makeRetireVehicleEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.FleetReasonRequest)
+		if err := s.RetireVehicle(ctx, req.ID, req.Reason); err != nil {
+			return nil, errors.New("Lỗi khi ngừng sử dụng phương tiện: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			Status:  "success",
+			Message: "Phương tiện đã ngừng sử dụng",
+		}, nil
+	}
+}
+
+func makeAssignOrderEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.AssignOrderRequest)
+		if err := s.AssignOrder(ctx, req.OrderID, req.DriverID); err != nil {
+			return nil, errors.New("Lỗi khi phân công đơn hàng: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			Status:  "success",
+			Message: "Đã phân công đơn hàng cho tài xế",
+		}, nil
+	}
+}
+
+func makeUnassignOrderEndpoint(s services.FleetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.FleetReasonRequest)
+		if err := s.UnassignOrder(ctx, req.ID, req.Reason); err != nil {
+			return nil, errors.New("Lỗi khi hủy phân công đơn hàng: " + err.Error())
+		}
+
+		return transforms.FleetResponse{
+			Status:  "success",
+			Message: "Đã hủy phân công đơn hàng",
+		}, nil
+	}
+}
+
+func driverResponse(driver *domain.Driver) transforms.DriverResponse {
+	return transforms.DriverResponse{
+		ID:        driver.ID,
+		Name:      driver.Name,
+		Status:    driver.Status,
+		VehicleID: driver.VehicleID,
+		CreatedAt: driver.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: driver.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func vehicleResponse(vehicle *domain.Vehicle) transforms.VehicleResponse {
+	return transforms.VehicleResponse{
+		ID:          vehicle.ID,
+		PlateNumber: vehicle.PlateNumber,
+		CapacityKg:  vehicle.CapacityKg,
+		Status:      vehicle.Status,
+		CreatedAt:   vehicle.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   vehicle.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,189 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+type HubEndpoints struct {
+	ListHubs    endpoint.Endpoint
+	GetHub      endpoint.Endpoint
+	CreateHub   endpoint.Endpoint
+	UpdateHub   endpoint.Endpoint
+	ScanIn      endpoint.Endpoint
+	ScanOut     endpoint.Endpoint
+	Inventory   endpoint.Endpoint
+	DwellAlerts endpoint.Endpoint
+}
+
+// NewHubEndpoints tạo các endpoints cho hub service
+func NewHubEndpoints(s services.HubService) HubEndpoints {
+	return HubEndpoints{
+		ListHubs:    makeListHubsEndpoint(s),
+		GetHub:      makeGetHubEndpoint(s),
+		CreateHub:   makeCreateHubEndpoint(s),
+		UpdateHub:   makeUpdateHubEndpoint(s),
+		ScanIn:      makeScanInEndpoint(s),
+		ScanOut:     makeScanOutEndpoint(s),
+		Inventory:   makeHubInventoryEndpoint(s),
+		DwellAlerts: makeDwellAlertsEndpoint(s),
+	}
+}
+
+func makeListHubsEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		hubs, err := s.ListHubs(ctx)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy danh sách hub: " + err.Error())
+		}
+
+		response := &transforms.HubListResponse{Items: make([]transforms.HubResponse, len(hubs))}
+		for i := range hubs {
+			response.Items[i] = hubResponse(&hubs[i])
+		}
+		return response, nil
+	}
+}
+
+func makeGetHubEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.HubCodeRequest)
+		hub, err := s.GetHub(ctx, req.Code)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy thông tin hub: " + err.Error())
+		}
+
+		return hubResponse(hub), nil
+	}
+}
+
+func makeCreateHubEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.CreateHubRequest)
+		hub, err := s.CreateHub(ctx, models.Hub{
+			Code:              req.Code,
+			Name:              req.Name,
+			Latitude:          req.Latitude,
+			Longitude:         req.Longitude,
+			OpensAt:           req.OpensAt,
+			ClosesAt:          req.ClosesAt,
+			DwellLimitMinutes: req.DwellLimitMinutes,
+		})
+		if err != nil {
+			return nil, errors.New("Lỗi khi tạo hub: " + err.Error())
+		}
+
+		return hubResponse(hub), nil
+	}
+}
+
+func makeUpdateHubEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.UpdateHubRequest)
+		hub, err := s.UpdateHub(ctx, req.Code, services.HubUpdate{
+			Name:              req.Name,
+			Latitude:          req.Latitude,
+			Longitude:         req.Longitude,
+			OpensAt:           req.OpensAt,
+			ClosesAt:          req.ClosesAt,
+			DwellLimitMinutes: req.DwellLimitMinutes,
+			Status:            req.Status,
+		})
+		if err != nil {
+			return nil, errors.New("Lỗi khi cập nhật hub: " + err.Error())
+		}
+
+		return hubResponse(hub), nil
+	}
+}
+
+func makeScanInEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.HubScanRequest)
+		if err := s.ScanIn(ctx, req.HubCode, req.OrderID); err != nil {
+			return nil, errors.New("Lỗi khi quét đơn hàng vào hub: " + err.Error())
+		}
+
+		return transforms.HubCommandResponse{
+			Status:  "success",
+			Message: "Đã quét đơn hàng vào hub",
+		}, nil
+	}
+}
+
+func makeScanOutEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.HubScanRequest)
+		if err := s.ScanOut(ctx, req.HubCode, req.OrderID); err != nil {
+			return nil, errors.New("Lỗi khi quét đơn hàng ra khỏi hub: " + err.Error())
+		}
+
+		return transforms.HubCommandResponse{
+			Status:  "success",
+			Message: "Đã quét đơn hàng ra khỏi hub",
+		}, nil
+	}
+}
+
+func makeHubInventoryEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.HubCodeRequest)
+		stays, err := s.Inventory(ctx, req.Code)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy tồn kho hub: " + err.Error())
+		}
+
+		return hubStaysResponse(stays), nil
+	}
+}
+
+func makeDwellAlertsEndpoint(s services.HubService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.HubAlertsRequest)
+		alerts, err := s.DwellAlerts(ctx, req.HubCode)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy cảnh báo hub: " + err.Error())
+		}
+
+		return hubStaysResponse(alerts), nil
+	}
+}
+
+func hubResponse(hub *models.Hub) transforms.HubResponse {
+	return transforms.HubResponse{
+		Code:              hub.Code,
+		Name:              hub.Name,
+		Latitude:          hub.Latitude,
+		Longitude:         hub.Longitude,
+		OpensAt:           hub.OpensAt,
+		ClosesAt:          hub.ClosesAt,
+		DwellLimitMinutes: int(hub.DwellLimit() / time.Minute),
+		Status:            hub.Status,
+		CreatedAt:         hub.CreatedTime.Format(time.RFC3339),
+		UpdatedAt:         hub.UpdatedTime.Format(time.RFC3339),
+	}
+}
+
+func hubStaysResponse(stays []services.HubStay) *transforms.HubStaysResponse {
+	response := &transforms.HubStaysResponse{
+		Count: len(stays),
+		Items: make([]transforms.HubStayResponse, len(stays)),
+	}
+	for i, stay := range stays {
+		response.Items[i] = transforms.HubStayResponse{
+			OrderID:      stay.OrderID,
+			HubCode:      stay.HubCode,
+			ScannedInAt:  stay.ScannedInAt.Format(time.RFC3339),
+			DwellMinutes: int(stay.Dwell / time.Minute),
+			LimitMinutes: int(stay.Limit / time.Minute),
+			Overdue:      stay.Overdue,
+		}
+	}
+	return response
+}
@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"time"
+)
+
+type ImportEndpoints struct {
+	StartImport  endpoint.Endpoint
+	GetImportJob endpoint.Endpoint
+}
+
+// NewImportEndpoints tạo các endpoints cho import service
+func NewImportEndpoints(s services.ImportService) ImportEndpoints {
+	return ImportEndpoints{
+		StartImport:  makeStartImportEndpoint(s),
+		GetImportJob: makeGetImportJobEndpoint(s),
+	}
+}
+
+func makeStartImportEndpoint(s services.ImportService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.StartImportRequest)
+		job, err := s.StartImport(ctx, req.Format, req.Data)
+		if err != nil {
+			return nil, errors.New("Lỗi khi nhập đơn hàng: " + err.Error())
+		}
+
+		return importJobResponse(job), nil
+	}
+}
+
+func makeGetImportJobEndpoint(s services.ImportService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.GetImportJobRequest)
+		job, err := s.GetImportJob(ctx, req.JobID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy trạng thái nhập đơn hàng: " + err.Error())
+		}
+
+		return importJobResponse(job), nil
+	}
+}
+
+// importJobResponse chuyển job thành view model kèm kết quả từng dòng đã xử lý
+func importJobResponse(job *services.ImportJob) transforms.ImportJobResponse {
+	response := transforms.ImportJobResponse{
+		JobID:     job.ID,
+		Format:    job.Format,
+		Status:    string(job.Status),
+		Total:     job.Total,
+		Processed: job.Processed,
+		Succeeded: job.Succeeded,
+		Failed:    job.Failed,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.Format(time.RFC3339),
+		Results:   make([]transforms.ImportRowResultResponse, len(job.Results)),
+	}
+	if job.FinishedAt != nil {
+		response.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+
+	for i, result := range job.Results {
+		response.Results[i] = importRowResult(result)
+	}
+	return response
+}
+
+// importRowResult chuyển kết quả một dòng thành view model
+func importRowResult(result services.ImportRowResult) transforms.ImportRowResultResponse {
+	status := "CREATED"
+	if result.Error != "" {
+		status = "FAILED"
+	}
+	return transforms.ImportRowResultResponse{
+		Line:           result.Line,
+		Reference:      result.Reference,
+		Status:         status,
+		OrderID:        result.OrderID,
+		TrackingNumber: result.TrackingNumber,
+		Error:          result.Error,
+	}
+}
@@ -0,0 +1,104 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+type LocationEndpoints struct {
+	RecordPings    endpoint.Endpoint
+	DriverLocation endpoint.Endpoint
+	OrderLocation  endpoint.Endpoint
+}
+
+// NewLocationEndpoints tạo các endpoints cho location service
+func NewLocationEndpoints(s services.LocationService) LocationEndpoints {
+	return LocationEndpoints{
+		RecordPings:    makeRecordPingsEndpoint(s),
+		DriverLocation: makeDriverLocationEndpoint(s),
+		OrderLocation:  makeOrderLocationEndpoint(s),
+	}
+}
+
+func makeRecordPingsEndpoint(s services.LocationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RecordPingsRequest)
+		pings := make([]services.LocationPing, len(req.Pings))
+		for i, ping := range req.Pings {
+			pings[i] = services.LocationPing{Point: geo.Point{Latitude: ping.Latitude, Longitude: ping.Longitude}}
+			if ping.RecordedAt != nil {
+				pings[i].RecordedAt = *ping.RecordedAt
+			}
+		}
+
+		result, err := s.RecordPings(ctx, req.DriverID, pings)
+		if err != nil {
+			return nil, errors.New("Lỗi khi nhận vị trí tài xế: " + err.Error())
+		}
+
+		response := &transforms.RecordPingsResponse{
+			Received: result.Received,
+			Accepted: result.Accepted,
+			Orders:   result.Orders,
+			Trails:   result.Trails,
+		}
+		if result.Location != nil {
+			location := locationResponse(result.Location.Point, result.Location.RecordedAt)
+			response.Location = &location
+		}
+		return response, nil
+	}
+}
+
+func makeDriverLocationEndpoint(s services.LocationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.DriverLocationRequest)
+		location, err := s.DriverLocation(ctx, req.DriverID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy vị trí tài xế: " + err.Error())
+		}
+
+		return transforms.DriverLocationResponse{
+			DriverID:         location.DriverID,
+			LocationResponse: locationResponse(location.Point, location.RecordedAt),
+			ReceivedAt:       location.ReceivedAt.Format(time.RFC3339),
+		}, nil
+	}
+}
+
+func makeOrderLocationEndpoint(s services.LocationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.OrderLocationRequest)
+		location, err := s.OrderLocation(ctx, req.OrderID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy vị trí đơn hàng: " + err.Error())
+		}
+
+		response := &transforms.OrderLocationResponse{
+			OrderID:          location.OrderID,
+			DriverID:         location.DriverID,
+			LocationResponse: locationResponse(location.Point, location.RecordedAt),
+		}
+		if trail := location.Trail; trail != nil {
+			response.Trail = &transforms.TrailPointResponse{
+				LocationResponse: locationResponse(trail.Point, trail.RecordedAt),
+				Status:           trail.Status,
+			}
+		}
+		return response, nil
+	}
+}
+
+func locationResponse(point geo.Point, recordedAt time.Time) transforms.LocationResponse {
+	return transforms.LocationResponse{
+		Latitude:   point.Latitude,
+		Longitude:  point.Longitude,
+		RecordedAt: recordedAt.Format(time.RFC3339),
+	}
+}
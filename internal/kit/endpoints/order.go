@@ -3,10 +3,14 @@ package endpoints
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/go-kit/kit/endpoint"
 	"github.com/quyenle-97/init/internal/domain"
 	"github.com/quyenle-97/init/internal/kit/services"
 	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"io"
+	"math"
 	"time"
 )
 
@@ -14,11 +18,15 @@ type OrderEndpoints struct {
 	CreateOrder        endpoint.Endpoint
 	GetOrder           endpoint.Endpoint
 	ListOrders         endpoint.Endpoint
+	ListOrdersNear     endpoint.Endpoint
 	UpdateOrderStatus  endpoint.Endpoint
+	BatchUpdateStatus  endpoint.Endpoint
 	CancelOrder        endpoint.Endpoint
 	AddOrderNote       endpoint.Endpoint
 	GetOrderHistory    endpoint.Endpoint
 	GetOrderByTracking endpoint.Endpoint
+	DiffOrder          endpoint.Endpoint
+	ExportOrders       endpoint.Endpoint
 }
 
 // NewOrderEndpoints tạo các endpoints cho order service
@@ -27,11 +35,15 @@ func NewOrderEndpoints(s services.OrderService) OrderEndpoints {
 		CreateOrder:        makeCreateOrderEndpoint(s),
 		GetOrder:           makeGetOrderEndpoint(s),
 		ListOrders:         makeListOrdersEndpoint(s),
+		ListOrdersNear:     makeListOrdersNearEndpoint(s),
 		UpdateOrderStatus:  makeUpdateOrderStatusEndpoint(s),
+		BatchUpdateStatus:  makeBatchUpdateStatusEndpoint(s),
 		CancelOrder:        makeCancelOrderEndpoint(s),
 		AddOrderNote:       makeAddOrderNoteEndpoint(s),
 		GetOrderHistory:    makeGetOrderHistoryEndpoint(s),
 		GetOrderByTracking: makeGetOrderByTrackingEndpoint(s),
+		DiffOrder:          makeDiffOrderEndpoint(s),
+		ExportOrders:       makeExportOrdersEndpoint(s),
 	}
 }
 
@@ -39,7 +51,7 @@ func NewOrderEndpoints(s services.OrderService) OrderEndpoints {
 func makeCreateOrderEndpoint(s services.OrderService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(transforms.CreateOrderRequest)
-		orderID, trackingNumber, err := s.CreateOrder(ctx, req.CustomerID, req.Origin, req.Destination, req.Items)
+		orderID, trackingNumber, err := s.CreateOrder(ctx, req.CustomerID, req.Origin, req.Destination, req.Items, req.ServiceLevel, req.PaymentTerms())
 		if err != nil {
 			return nil, errors.New("Lỗi khi tạo đơn hàng: " + err.Error())
 		}
@@ -54,6 +66,16 @@ func makeCreateOrderEndpoint(s services.OrderService) endpoint.Endpoint {
 func makeGetOrderEndpoint(s services.OrderService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(transforms.GetOrderRequest)
+
+		// Có as_of thì xây dựng lại trạng thái tại thời điểm đó từ event store
+		if !req.AsOf.IsZero() {
+			order, err := s.GetOrderAsOf(ctx, req.OrderID, req.AsOf)
+			if err != nil {
+				return nil, errors.New("Lỗi khi lấy thông tin đơn hàng: " + err.Error())
+			}
+			return order, nil
+		}
+
 		order, err := s.GetOrder(ctx, req.OrderID)
 
 		if err != nil {
@@ -82,18 +104,71 @@ func makeListOrdersEndpoint(s services.OrderService) endpoint.Endpoint {
 
 		// Map từng đơn hàng sang summary view model
 		for i, order := range orders {
-			response.Items[i] = transforms.OrderSummaryResponse{
-				ID:             order.ID,
-				TrackingNumber: order.TrackingNumber,
-				Status:         order.Status,
-				CreatedAt:      order.CreatedAt.Format(time.RFC3339),
-				UpdatedAt:      order.UpdatedAt.Format(time.RFC3339),
+			response.Items[i] = orderSummary(order)
+		}
+		return response, nil
+	}
+}
+
+func makeListOrdersNearEndpoint(s services.OrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ListOrdersNearRequest)
+		center := geo.Point{Latitude: req.Latitude, Longitude: req.Longitude}
+		nearby, err := s.ListOrdersNear(ctx, center, req.RadiusKm, req.Status, req.Limit)
+		if err != nil {
+			return nil, errors.New("Lỗi khi tìm đơn hàng lân cận: " + err.Error())
+		}
+
+		response := transforms.ListOrdersNearResponse{
+			Items: make([]transforms.NearbyOrderResponse, len(nearby)),
+			Count: len(nearby),
+		}
+		for i, item := range nearby {
+			response.Items[i] = transforms.NearbyOrderResponse{
+				OrderSummaryResponse: orderSummary(item.Order),
+				DistanceKm:           math.Round(item.DistanceKm*100) / 100,
 			}
 		}
 		return response, nil
 	}
 }
 
+// orderSummary chuyển đơn hàng sang summary view model
+func orderSummary(order *domain.Order) transforms.OrderSummaryResponse {
+	summary := transforms.OrderSummaryResponse{
+		ID:             order.ID,
+		TrackingNumber: order.TrackingNumber,
+		Status:         order.Status,
+		ServiceLevel:   order.ServiceLevel,
+		SLABreached:    order.SLABreached,
+		CreatedAt:      order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      order.UpdatedAt.Format(time.RFC3339),
+		Currency:       order.Currency,
+		Subtotal:       order.Totals.Subtotal,
+		ShippingFee:    order.ShippingFee,
+		DriverID:       order.DriverID,
+	}
+	if total, ok := order.Total(); ok {
+		summary.Total = &total
+	}
+	if order.Payment != nil {
+		summary.PaymentStatus = order.Payment.Status
+		if order.Payment.Method == domain.PaymentMethodCOD {
+			summary.CODAmount = &order.Payment.Amount
+		}
+	}
+	if order.EstimatedDelivery != nil {
+		summary.EstimatedDelivery = order.EstimatedDelivery.Format(time.RFC3339)
+	}
+	if distance, ok := order.DistanceRemainingKm(); ok {
+		summary.DistanceRemainingKm = &distance
+	}
+	if progress, ok := order.ProgressPct(); ok {
+		summary.ProgressPct = &progress
+	}
+	return summary
+}
+
 func makeUpdateOrderStatusEndpoint(s services.OrderService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(transforms.UpdateOrderStatusRequest)
@@ -109,6 +184,62 @@ func makeUpdateOrderStatusEndpoint(s services.OrderService) endpoint.Endpoint {
 	}
 }
 
+func makeBatchUpdateStatusEndpoint(s services.OrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.BatchUpdateOrderStatusRequest)
+		updates := make([]services.StatusUpdate, len(req.Updates))
+		for i, entry := range req.Updates {
+			updates[i] = services.StatusUpdate(entry)
+		}
+
+		results, err := s.UpdateOrderStatusBatch(ctx, updates)
+		if err != nil {
+			return nil, errors.New("Lỗi khi cập nhật trạng thái đơn hàng: " + err.Error())
+		}
+
+		response := &transforms.BatchUpdateOrderStatusResponse{
+			Total:   len(results),
+			Results: make([]transforms.BatchUpdateOrderStatusResult, len(results)),
+		}
+		for i, result := range results {
+			status := "UPDATED"
+			if result.Error != "" {
+				status = "FAILED"
+				response.Failed++
+			} else {
+				response.Succeeded++
+			}
+			response.Results[i] = transforms.BatchUpdateOrderStatusResult{
+				Index:          i,
+				OrderID:        result.OrderID,
+				TrackingNumber: result.TrackingNumber,
+				Status:         status,
+				Error:          result.Error,
+			}
+		}
+
+		return response, nil
+	}
+}
+
+// makeExportOrdersEndpoint trả về hàm ghi file, việc đọc đơn hàng diễn ra khi transport ghi response
+func makeExportOrdersEndpoint(s services.OrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ExportOrdersRequest)
+
+		return transforms.ExportOrdersResponse{
+			ContentType: transforms.ExportContentType(req.Format),
+			Filename:    fmt.Sprintf("orders-%s.%s", time.Now().Format("20060102-150405"), req.Format),
+			Write: func(ctx context.Context, w io.Writer) error {
+				if err := s.ExportOrders(ctx, req.Format, req.Filter, w); err != nil {
+					return errors.New("Lỗi khi xuất đơn hàng: " + err.Error())
+				}
+				return nil
+			},
+		}, nil
+	}
+}
+
 func makeCancelOrderEndpoint(s services.OrderService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(transforms.CancelOrderRequest)
@@ -148,44 +279,63 @@ func makeGetOrderHistoryEndpoint(s services.OrderService) endpoint.Endpoint {
 		if err != nil {
 			return nil, errors.New("Lỗi khi lấy lịch sử đơn hàng: " + err.Error())
 		}
+		metadata, err := s.GetOrderMetadata(ctx, req.OrderID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy lịch sử đơn hàng: " + err.Error())
+		}
 
 		response := &transforms.GetOrderHistoryResponse{
 			OrderID: req.OrderID,
 			Entries: make([]transforms.GetOrderHistoryEntryResponse, 0, len(events)),
 		}
 
-		// Chuyển đổi mỗi sự kiện thành một mục lịch sử
+		// Chuyển đổi mỗi sự kiện thành một mục lịch sử kèm nguồn request đã ghi lại
 		for _, event := range events {
-			entry := transforms.GetOrderHistoryEntryResponse{
-				Timestamp: event.GetTimestamp().Format(time.RFC3339),
-				EventType: string(event.GetType()),
-			}
+			entry := historyEntry(event)
+			entry.Metadata = metadata[event.GetID()]
+			response.Entries = append(response.Entries, entry)
+		}
 
-			// Xử lý từng loại sự kiện cụ thể
-			switch e := event.(type) {
-			case domain.OrderCreatedEvent:
-				entry.Status = domain.OrderStatusCreated
-				entry.Note = "Đơn hàng được tạo"
-			case domain.OrderStatusUpdatedEvent:
-				entry.Status = e.NewStatus
-				entry.PrevStatus = e.OldStatus
-				entry.Location = e.CurrentLocation
-				entry.Note = e.Note
-			case domain.OrderCancelledEvent:
-				entry.Status = domain.OrderStatusCancelled
-				entry.PrevStatus = e.PreviousStatus
-				entry.Note = e.Reason
-			case domain.OrderNoteAddedEvent:
-				entry.Note = e.Note
-			}
+		return response, nil
+	}
+}
 
-			response.Entries = append(response.Entries, entry)
+func makeDiffOrderEndpoint(s services.OrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.DiffOrderRequest)
+		diff, err := s.DiffOrder(ctx, req.OrderID, req.From, req.To)
+		if err != nil {
+			return nil, errors.New("Lỗi khi so sánh đơn hàng: " + err.Error())
+		}
+
+		response := &transforms.DiffOrderResponse{
+			OrderID: req.OrderID,
+			From:    diff.From,
+			To:      diff.To,
+			Changes: diff.Changes,
+			Events:  make([]transforms.GetOrderHistoryEntryResponse, 0, len(diff.Events)),
+		}
+		for _, event := range diff.Events {
+			response.Events = append(response.Events, historyEntry(event))
 		}
 
 		return response, nil
 	}
 }
 
+// historyEntry chuyển một sự kiện thành mục lịch sử, mô tả lấy từ registry của domain
+func historyEntry(event domain.Event) transforms.GetOrderHistoryEntryResponse {
+	description := domain.DescribeEvent(event)
+	return transforms.GetOrderHistoryEntryResponse{
+		Timestamp:  event.GetTimestamp().Format(time.RFC3339),
+		EventType:  string(event.GetType()),
+		Status:     description.Status,
+		PrevStatus: description.PrevStatus,
+		Location:   description.Location,
+		Note:       description.Note,
+	}
+}
+
 func makeGetOrderByTrackingEndpoint(s services.OrderService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(transforms.GetOrderByTrackingRequest)
@@ -0,0 +1,143 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/shopspring/decimal"
+)
+
+type PaymentEndpoints struct {
+	CollectPayment endpoint.Endpoint
+	RefundPayment  endpoint.Endpoint
+	RemitPayments  endpoint.Endpoint
+	CODBalances    endpoint.Endpoint
+	OutstandingCOD endpoint.Endpoint
+}
+
+// NewPaymentEndpoints tạo các endpoints cho payment service
+func NewPaymentEndpoints(s services.PaymentService) PaymentEndpoints {
+	return PaymentEndpoints{
+		CollectPayment: makeCollectPaymentEndpoint(s),
+		RefundPayment:  makeRefundPaymentEndpoint(s),
+		RemitPayments:  makeRemitPaymentsEndpoint(s),
+		CODBalances:    makeCODBalancesEndpoint(s),
+		OutstandingCOD: makeOutstandingCODEndpoint(s),
+	}
+}
+
+func makeCollectPaymentEndpoint(s services.PaymentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.CollectPaymentRequest)
+		if err := s.CollectPayment(ctx, req.OrderID, req.DriverID, req.Amount); err != nil {
+			return nil, errors.New("Lỗi khi thu tiền: " + err.Error())
+		}
+
+		return transforms.PaymentResponse{
+			Status:  "success",
+			Message: "Đã ghi nhận tiền thu hộ",
+		}, nil
+	}
+}
+
+func makeRefundPaymentEndpoint(s services.PaymentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RefundPaymentRequest)
+		if err := s.RefundPayment(ctx, req.OrderID, req.Reference); err != nil {
+			return nil, errors.New("Lỗi khi hoàn tiền: " + err.Error())
+		}
+
+		return transforms.PaymentResponse{
+			Status:  "success",
+			Message: "Đã ghi nhận hoàn tiền",
+		}, nil
+	}
+}
+
+func makeRemitPaymentsEndpoint(s services.PaymentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RemitPaymentsRequest)
+		results, err := s.RemitPayments(ctx, req.DriverID, req.Reference, req.OrderIDs)
+		if err != nil {
+			return nil, errors.New("Lỗi khi nộp tiền thu hộ: " + err.Error())
+		}
+
+		response := &transforms.RemitPaymentsResponse{
+			Total:    len(results),
+			Remitted: make(map[string]decimal.Decimal),
+			Results:  make([]transforms.RemitPaymentResult, len(results)),
+		}
+		for i, result := range results {
+			entry := transforms.RemitPaymentResult{OrderID: result.OrderID, Status: "REMITTED", Error: result.Error}
+			if result.Error != "" {
+				entry.Status = "FAILED"
+				response.Failed++
+			} else {
+				amount := result.Amount
+				entry.Amount = &amount
+				entry.Currency = result.Currency
+				response.Succeeded++
+				response.Remitted[result.Currency] = response.Remitted[result.Currency].Add(amount)
+			}
+			response.Results[i] = entry
+		}
+
+		return response, nil
+	}
+}
+
+func makeCODBalancesEndpoint(s services.PaymentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.CODBalancesRequest)
+		balances, err := s.CODBalances(ctx, req.DriverID, req.From, req.To, req.IncludeSettled)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy đối soát thu hộ: " + err.Error())
+		}
+
+		response := make([]transforms.CODBalanceResponse, len(balances))
+		for i, balance := range balances {
+			response[i] = transforms.CODBalanceResponse{
+				DriverID:        balance.DriverID,
+				Day:             balance.Day,
+				Currency:        balance.Currency,
+				CollectedOrders: balance.CollectedOrders,
+				Collected:       balance.Collected,
+				RemittedOrders:  balance.RemittedOrders,
+				Remitted:        balance.Remitted,
+				Outstanding:     balance.Outstanding(),
+			}
+		}
+		return response, nil
+	}
+}
+
+func makeOutstandingCODEndpoint(s services.PaymentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.OutstandingCODRequest)
+		collections, err := s.OutstandingCOD(ctx, req.DriverID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy tiền thu hộ chưa nộp: " + err.Error())
+		}
+
+		response := &transforms.OutstandingCODResponse{
+			DriverID:    req.DriverID,
+			Outstanding: make(map[string]decimal.Decimal),
+			Items:       make([]transforms.CODCollectionResponse, len(collections)),
+		}
+		for i, collection := range collections {
+			response.Outstanding[collection.Currency] = response.Outstanding[collection.Currency].Add(collection.Amount)
+			response.Items[i] = transforms.CODCollectionResponse{
+				OrderID:     collection.OrderID,
+				Day:         collection.Day,
+				Amount:      collection.Amount,
+				Currency:    collection.Currency,
+				CollectedAt: collection.CollectedAt.Format(time.RFC3339),
+			}
+		}
+		return response, nil
+	}
+}
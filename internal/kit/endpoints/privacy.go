@@ -0,0 +1,36 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+type PrivacyEndpoints struct {
+	ErasePersonalData endpoint.Endpoint
+}
+
+// NewPrivacyEndpoints tạo các endpoints cho privacy service
+func NewPrivacyEndpoints(s services.PrivacyService) PrivacyEndpoints {
+	return PrivacyEndpoints{
+		ErasePersonalData: makeErasePersonalDataEndpoint(s),
+	}
+}
+
+func makeErasePersonalDataEndpoint(s services.PrivacyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ErasePersonalDataRequest)
+		rebuilt, err := s.ErasePersonalData(ctx, req.CustomerID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi xóa dữ liệu cá nhân: " + err.Error())
+		}
+
+		return transforms.ErasePersonalDataResponse{
+			Status:        "success",
+			Message:       "Dữ liệu cá nhân của khách hàng đã được xóa",
+			OrdersRebuilt: rebuilt,
+		}, nil
+	}
+}
@@ -0,0 +1,140 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// ProductEndpoints chứa các endpoint quản lý sản phẩm
+type ProductEndpoints struct {
+	ListProducts  endpoint.Endpoint
+	GetProduct    endpoint.Endpoint
+	CreateProduct endpoint.Endpoint
+	UpdateProduct endpoint.Endpoint
+	DeleteProduct endpoint.Endpoint
+
+	ProductDistance endpoint.Endpoint
+}
+
+// NewProductEndpoints tạo các endpoint quản lý sản phẩm
+func NewProductEndpoints(s services.ProductService) ProductEndpoints {
+	return ProductEndpoints{
+		ListProducts:  makeListProductsEndpoint(s),
+		GetProduct:    makeGetProductEndpoint(s),
+		CreateProduct: makeCreateProductEndpoint(s),
+		UpdateProduct: makeUpdateProductEndpoint(s),
+		DeleteProduct: makeDeleteProductEndpoint(s),
+
+		ProductDistance: makeProductDistanceEndpoint(s),
+	}
+}
+
+func makeListProductsEndpoint(s services.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ProductSearchRequest)
+		products, err := s.ListProducts(ctx, services.ProductFilter{
+			References: req.References,
+			Names:      req.Names,
+			AddFrom:    req.AddFromTime(),
+			AddTo:      req.AddToTime(),
+			Statuses:   req.Status,
+			Categories: req.Categories,
+			Cities:     req.Cities,
+			Offset:     req.Offset,
+			Limit:      req.Limit,
+		})
+		if err != nil {
+			return nil, catalogError("Lỗi khi lấy danh sách sản phẩm: ", err)
+		}
+
+		return catalogResponse(ctx, products, &utils.Pagination{Limit: req.Limit, Offset: req.Offset}), nil
+	}
+}
+
+func makeGetProductEndpoint(s services.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RecordRequest)
+		product, err := s.GetProduct(ctx, req.ID)
+		if err != nil {
+			return nil, catalogError("Lỗi khi lấy sản phẩm: ", err)
+		}
+
+		return catalogResponse(ctx, product, nil), nil
+	}
+}
+
+func makeCreateProductEndpoint(s services.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ProductRequest)
+		product, err := s.CreateProduct(ctx, productInput(req))
+		if err != nil {
+			return nil, catalogError("Lỗi khi tạo sản phẩm: ", err)
+		}
+
+		return catalogResponse(ctx, product, nil), nil
+	}
+}
+
+func makeUpdateProductEndpoint(s services.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ProductRequest)
+		product, err := s.UpdateProduct(ctx, req.ID, productInput(req))
+		if err != nil {
+			return nil, catalogError("Lỗi khi cập nhật sản phẩm: ", err)
+		}
+
+		return catalogResponse(ctx, product, nil), nil
+	}
+}
+
+func makeDeleteProductEndpoint(s services.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RecordRequest)
+		if err := s.DeleteProduct(ctx, req.ID); err != nil {
+			return nil, catalogError("Lỗi khi xóa sản phẩm: ", err)
+		}
+
+		return catalogResponse(ctx, nil, nil), nil
+	}
+}
+
+func makeProductDistanceEndpoint(s services.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.ProductDistanceRequest)
+		from := services.DistanceFrom{CityID: req.CityID}
+		if req.Latitude != nil {
+			from.Point = &geo.Point{Latitude: *req.Latitude, Longitude: *req.Longitude}
+		}
+
+		distance, err := s.ProductDistance(ctx, req.ID, from)
+		if err != nil {
+			return nil, catalogError("Lỗi khi tính khoảng cách tới sản phẩm: ", err)
+		}
+
+		return catalogResponse(ctx, transforms.ProductDistanceResponse{
+			ProductID:  distance.ProductID,
+			From:       distance.From,
+			To:         distance.To,
+			DistanceKm: distance.DistanceKm,
+		}, nil), nil
+	}
+}
+
+// productInput chuyển request đã validate thành dữ liệu ghi của service
+func productInput(req transforms.ProductRequest) services.ProductInput {
+	return services.ProductInput{
+		Name:       req.Name,
+		Price:      *req.Price,
+		Quantity:   *req.Quantity,
+		Weight:     req.Weight,
+		Status:     req.Status,
+		Categories: req.Categories,
+		CityID:     req.CityID,
+		SupplierID: req.SupplierID,
+	}
+}
@@ -0,0 +1,80 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+type RouteEndpoints struct {
+	PlanRoute endpoint.Endpoint
+	GetRoute  endpoint.Endpoint
+}
+
+// NewRouteEndpoints tạo các endpoints cho route service
+func NewRouteEndpoints(s services.RouteService) RouteEndpoints {
+	return RouteEndpoints{
+		PlanRoute: makePlanRouteEndpoint(s),
+		GetRoute:  makeGetRouteEndpoint(s),
+	}
+}
+
+func makePlanRouteEndpoint(s services.RouteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.PlanRouteRequest)
+		opts := services.RoutePlanOptions{Status: req.Status}
+		if req.Latitude != nil && req.Longitude != nil {
+			opts.Start = &geo.Point{Latitude: *req.Latitude, Longitude: *req.Longitude}
+		}
+
+		route, err := s.PlanRoute(ctx, req.DriverID, opts)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lập lộ trình: " + err.Error())
+		}
+
+		return routeResponse(req.DriverID, route), nil
+	}
+}
+
+func makeGetRouteEndpoint(s services.RouteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.DriverRouteRequest)
+		route, err := s.GetRoute(ctx, req.DriverID)
+		if err != nil {
+			return nil, errors.New("Lỗi khi lấy lộ trình: " + err.Error())
+		}
+
+		return routeResponse(req.DriverID, route), nil
+	}
+}
+
+func routeResponse(driverID string, route *domain.Route) transforms.RouteResponse {
+	response := transforms.RouteResponse{
+		DriverID:       driverID,
+		VehicleID:      route.VehicleID,
+		StartLatitude:  route.Start.Latitude,
+		StartLongitude: route.Start.Longitude,
+		DistanceKm:     route.DistanceKm,
+		Stops:          make([]transforms.RouteStopResponse, len(route.Stops)),
+		Unrouted:       route.Unrouted,
+		PlannedAt:      route.PlannedAt.Format(time.RFC3339),
+	}
+	for i, stop := range route.Stops {
+		response.Stops[i] = transforms.RouteStopResponse{
+			Sequence:   i + 1,
+			OrderID:    stop.OrderID,
+			Trip:       stop.Trip,
+			Latitude:   stop.Latitude,
+			Longitude:  stop.Longitude,
+			DistanceKm: stop.DistanceKm,
+		}
+		response.Trips = stop.Trip
+	}
+	return response
+}
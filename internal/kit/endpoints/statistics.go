@@ -0,0 +1,107 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// StatisticsEndpoints chứa các endpoint thống kê sản phẩm và đơn hàng
+type StatisticsEndpoints struct {
+	ProductsPerCategory endpoint.Endpoint
+	ProductsPerSupplier endpoint.Endpoint
+	OrdersPerDay        endpoint.Endpoint
+	OrderStatuses       endpoint.Endpoint
+	Cancellations       endpoint.Endpoint
+}
+
+// NewStatisticsEndpoints tạo các endpoint thống kê
+func NewStatisticsEndpoints(s services.StatisticsService) StatisticsEndpoints {
+	return StatisticsEndpoints{
+		ProductsPerCategory: makeProductsPerCategoryEndpoint(s),
+		ProductsPerSupplier: makeProductsPerSupplierEndpoint(s),
+		OrdersPerDay:        makeOrdersPerDayEndpoint(s),
+		OrderStatuses:       makeOrderStatusesEndpoint(s),
+		Cancellations:       makeCancellationsEndpoint(s),
+	}
+}
+
+func makeProductsPerCategoryEndpoint(s services.StatisticsService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		counts, err := s.ProductsPerCategory(ctx)
+		if err != nil {
+			return nil, catalogError("Lỗi khi thống kê sản phẩm theo danh mục: ", err)
+		}
+
+		return catalogResponse(ctx, counts, nil), nil
+	}
+}
+
+func makeProductsPerSupplierEndpoint(s services.StatisticsService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		counts, err := s.ProductsPerSupplier(ctx)
+		if err != nil {
+			return nil, catalogError("Lỗi khi thống kê sản phẩm theo nhà cung cấp: ", err)
+		}
+
+		return catalogResponse(ctx, counts, nil), nil
+	}
+}
+
+func makeOrdersPerDayEndpoint(s services.StatisticsService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.OrdersPerDayRequest)
+		days, err := s.OrdersPerDay(ctx, req.From, req.To)
+		if err != nil {
+			return nil, catalogError("Lỗi khi thống kê đơn hàng theo ngày: ", err)
+		}
+
+		response := make([]transforms.DailyOrdersResponse, len(days))
+		for i, day := range days {
+			response[i] = transforms.DailyOrdersResponse{Day: day.Day, Orders: day.Orders}
+		}
+		return catalogResponse(ctx, response, nil), nil
+	}
+}
+
+func makeOrderStatusesEndpoint(s services.StatisticsService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		stats, err := s.OrderStatuses(ctx)
+		if err != nil {
+			return nil, catalogError("Lỗi khi thống kê trạng thái đơn hàng: ", err)
+		}
+
+		response := make([]transforms.OrderStatusStatsResponse, len(stats))
+		for i, stat := range stats {
+			response[i] = transforms.OrderStatusStatsResponse{
+				Status:         string(stat.Status),
+				Orders:         stat.Orders,
+				Exits:          stat.Exits,
+				AverageSeconds: stat.AverageSeconds,
+			}
+		}
+		return catalogResponse(ctx, response, nil), nil
+	}
+}
+
+func makeCancellationsEndpoint(s services.StatisticsService) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		stats, err := s.Cancellations(ctx)
+		if err != nil {
+			return nil, catalogError("Lỗi khi thống kê hủy đơn hàng: ", err)
+		}
+
+		response := transforms.CancellationStatsResponse{
+			TotalOrders: stats.TotalOrders,
+			Cancelled:   stats.Cancelled,
+			Rate:        stats.Rate,
+			Reasons:     make([]transforms.CancellationReasonResponse, len(stats.Reasons)),
+		}
+		for i, reason := range stats.Reasons {
+			response.Reasons[i] = transforms.CancellationReasonResponse{Reason: reason.Reason, Orders: reason.Orders, Rate: reason.Rate}
+		}
+		return catalogResponse(ctx, response, nil), nil
+	}
+}
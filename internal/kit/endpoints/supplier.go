@@ -0,0 +1,75 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// SupplierEndpoints chứa các endpoint quản lý nhà cung cấp
+type SupplierEndpoints struct {
+	ListSuppliers  endpoint.Endpoint
+	CreateSupplier endpoint.Endpoint
+	UpdateSupplier endpoint.Endpoint
+	DeleteSupplier endpoint.Endpoint
+}
+
+// NewSupplierEndpoints tạo các endpoint quản lý nhà cung cấp
+func NewSupplierEndpoints(s services.SupplierService) SupplierEndpoints {
+	return SupplierEndpoints{
+		ListSuppliers:  makeListSuppliersEndpoint(s),
+		CreateSupplier: makeCreateSupplierEndpoint(s),
+		UpdateSupplier: makeUpdateSupplierEndpoint(s),
+		DeleteSupplier: makeDeleteSupplierEndpoint(s),
+	}
+}
+
+func makeListSuppliersEndpoint(s services.SupplierService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.SearchRequest)
+		suppliers, err := s.ListSuppliers(ctx, req.Search, req.Offset, req.Limit)
+		if err != nil {
+			return nil, catalogError("Lỗi khi lấy danh sách nhà cung cấp: ", err)
+		}
+
+		return catalogResponse(ctx, suppliers, &utils.Pagination{Limit: req.Limit, Offset: req.Offset}), nil
+	}
+}
+
+func makeCreateSupplierEndpoint(s services.SupplierService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.CreateSupplierRequest)
+		supplier, err := s.CreateSupplier(ctx, req.Name, req.Location)
+		if err != nil {
+			return nil, catalogError("Lỗi khi tạo nhà cung cấp: ", err)
+		}
+
+		return catalogResponse(ctx, supplier, nil), nil
+	}
+}
+
+func makeUpdateSupplierEndpoint(s services.SupplierService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.UpdateSupplierRequest)
+		supplier, err := s.UpdateSupplier(ctx, req.ID, req.Name, req.Status, req.Location)
+		if err != nil {
+			return nil, catalogError("Lỗi khi cập nhật nhà cung cấp: ", err)
+		}
+
+		return catalogResponse(ctx, supplier, nil), nil
+	}
+}
+
+func makeDeleteSupplierEndpoint(s services.SupplierService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.RecordRequest)
+		if err := s.DeleteSupplier(ctx, req.ID); err != nil {
+			return nil, catalogError("Lỗi khi xóa nhà cung cấp: ", err)
+		}
+
+		return catalogResponse(ctx, nil, nil), nil
+	}
+}
@@ -0,0 +1,58 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+type TrackingEndpoints struct {
+	GetTracking endpoint.Endpoint
+}
+
+// NewTrackingEndpoints tạo các endpoints cho tracking service
+func NewTrackingEndpoints(s services.TrackingService) TrackingEndpoints {
+	return TrackingEndpoints{
+		GetTracking: makeGetTrackingEndpoint(s),
+	}
+}
+
+func makeGetTrackingEndpoint(s services.TrackingService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transforms.GetTrackingRequest)
+		info, err := s.GetTracking(ctx, req.TrackingNumber)
+		if err != nil {
+			// Giữ lỗi gốc để transport trả về 404 khi không tìm thấy
+			return nil, fmt.Errorf("Lỗi khi lấy thông tin theo dõi: %w", err)
+		}
+
+		response := transforms.TrackingResponse{
+			TrackingNumber:  info.TrackingNumber,
+			Status:          info.Status,
+			ServiceLevel:    info.ServiceLevel,
+			OriginCity:      info.OriginCity,
+			DestinationCity: info.DestinationCity,
+			CurrentCity:     info.CurrentCity,
+			Delayed:         info.Delayed,
+			CreatedAt:       info.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:       info.UpdatedAt.Format(time.RFC3339),
+			Updates:         make([]transforms.TrackingUpdateResponse, len(info.Updates)),
+		}
+		if info.EstimatedDelivery != nil {
+			response.EstimatedDelivery = info.EstimatedDelivery.Format(time.RFC3339)
+		}
+		for i, update := range info.Updates {
+			response.Updates[i] = transforms.TrackingUpdateResponse{
+				Timestamp: update.Timestamp.Format(time.RFC3339),
+				Status:    update.Status,
+				Location:  update.Location,
+				Message:   update.Message,
+			}
+		}
+		return response, nil
+	}
+}
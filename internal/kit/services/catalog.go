@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	defaultCatalogLimit = 20
+	maxCatalogLimit     = 100
+)
+
+var (
+	// ErrRecordNotFound được bọc khi bản ghi danh mục, thành phố, nhà cung cấp hoặc sản phẩm không tồn tại hoặc đã xóa
+	ErrRecordNotFound = errors.New("không tìm thấy bản ghi")
+	// ErrInvalidRecord được bọc khi dữ liệu ghi tham chiếu tới bản ghi không tồn tại hoặc sai giá trị
+	ErrInvalidRecord = errors.New("dữ liệu không hợp lệ")
+)
+
+// catalogPage chuẩn hóa offset và limit của danh sách
+func catalogPage(offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultCatalogLimit
+	}
+	if limit > maxCatalogLimit {
+		limit = maxCatalogLimit
+	}
+	return offset, limit
+}
+
+// containsPattern tạo mẫu LIKE tìm chuỗi con không phân biệt hoa thường, dùng với LOWER(cột) LIKE ? ESCAPE '!'
+// (ký tự escape không phải \ vì MySQL xử lý \ trong chuỗi SQL)
+func containsPattern(search string) string {
+	search = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(strings.ToLower(search))
+	return "%" + search + "%"
+}
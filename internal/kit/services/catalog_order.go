@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// catalogOrderService điền mặt hàng có product_uid từ danh mục sản phẩm trước khi tạo đơn hàng
+type catalogOrderService struct {
+	OrderService
+	products ProductService
+}
+
+// NewCatalogOrderService bọc OrderService:
+//   - mặt hàng có product_uid lấy tên, giá và khối lượng (nếu sản phẩm có khai báo) từ danh mục,
+//     kèm ảnh chụp sản phẩm lưu trong sự kiện OrderCreated
+//   - đơn hàng không truyền điểm gửi dùng vị trí kho của nhà cung cấp, hoặc thành phố của sản phẩm
+//     khi nhà cung cấp chưa khai báo kho
+func NewCatalogOrderService(next OrderService, products ProductService) OrderService {
+	return &catalogOrderService{
+		OrderService: next,
+		products:     products,
+	}
+}
+
+// CreateOrder tạo đơn hàng sau khi điền mặt hàng và điểm gửi từ danh mục sản phẩm
+func (s *catalogOrderService) CreateOrder(
+	ctx context.Context,
+	customerID string,
+	origin, destination domain.Location,
+	items []domain.OrderItem,
+	serviceLevel domain.ServiceLevel,
+	payment domain.PaymentTerms,
+) (string, string, error) {
+	items, products, err := s.resolveItems(ctx, items)
+	if err != nil {
+		return "", "", err
+	}
+
+	if origin == (domain.Location{}) && len(products) > 0 {
+		if origin, err = supplierOrigin(products); err != nil {
+			return "", "", err
+		}
+	}
+
+	return s.OrderService.CreateOrder(ctx, customerID, origin, destination, items, serviceLevel, payment)
+}
+
+// resolveItems trả về bản sao của items đã điền thông tin sản phẩm, cùng các sản phẩm theo thứ tự xuất hiện.
+// Ảnh chụp sản phẩm do client gửi lên luôn bị bỏ qua.
+func (s *catalogOrderService) resolveItems(ctx context.Context, items []domain.OrderItem) ([]domain.OrderItem, []*models.Product, error) {
+	resolved := make([]domain.OrderItem, len(items))
+	var products []*models.Product
+	seen := make(map[string]*models.Product)
+
+	for i, item := range items {
+		item.Product = nil
+		if item.ProductUID != "" {
+			product, ok := seen[item.ProductUID]
+			if !ok {
+				var err error
+				if product, err = s.orderableProduct(ctx, item.ProductUID); err != nil {
+					return nil, nil, err
+				}
+				seen[item.ProductUID] = product
+				products = append(products, product)
+			}
+			item = applyProduct(item, product)
+		}
+		resolved[i] = item
+	}
+	return resolved, products, nil
+}
+
+// orderableProduct lấy sản phẩm chưa xóa và đang kinh doanh
+func (s *catalogOrderService) orderableProduct(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.products.GetProduct(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, fmt.Errorf("sản phẩm %s không tồn tại: %w", id, ErrInvalidRecord)
+		}
+		return nil, err
+	}
+	if product.Status != models.MSActive {
+		return nil, fmt.Errorf("sản phẩm %s đã ngừng kinh doanh: %w", id, ErrInvalidRecord)
+	}
+	return product, nil
+}
+
+// applyProduct điền mặt hàng từ sản phẩm, khối lượng nhập tay được giữ khi sản phẩm chưa khai báo
+func applyProduct(item domain.OrderItem, product *models.Product) domain.OrderItem {
+	snapshot := &domain.ProductSnapshot{
+		Reference:  product.Reference,
+		Name:       product.Name,
+		Price:      product.Price,
+		Weight:     decimal.NewFromFloat(product.Weight),
+		CityID:     product.CityID,
+		SupplierID: product.SupplierID,
+	}
+	if product.Supplier != nil {
+		snapshot.SupplierName = product.Supplier.Name
+	}
+
+	if item.ID == "" {
+		item.ID = product.Reference
+	}
+	item.Name = snapshot.Name
+	item.Price = snapshot.Price
+	if snapshot.Weight.IsPositive() {
+		item.Weight = snapshot.Weight
+	}
+	item.Product = snapshot
+	return item
+}
+
+// supplierOrigin lấy điểm gửi từ nhà cung cấp chung của các sản phẩm
+func supplierOrigin(products []*models.Product) (domain.Location, error) {
+	product := products[0]
+	for _, other := range products[1:] {
+		if other.SupplierID != product.SupplierID {
+			return domain.Location{}, fmt.Errorf("sản phẩm thuộc nhiều nhà cung cấp, cần truyền điểm gửi: %w", ErrInvalidRecord)
+		}
+	}
+
+	origin := domain.Location{}
+	if product.City != nil {
+		origin = domain.Location{City: product.City.Name, Latitude: product.City.Latitude, Longitude: product.City.Longitude}
+	}
+	if product.Supplier != nil {
+		if warehouse, ok := product.Supplier.Location(); ok {
+			origin.Address = warehouse.Name
+			origin.Latitude = warehouse.Latitude
+			origin.Longitude = warehouse.Longitude
+		}
+	}
+	if origin == (domain.Location{}) {
+		return origin, fmt.Errorf("không xác định được điểm gửi của nhà cung cấp %s, cần truyền điểm gửi: %w", product.SupplierID, ErrInvalidRecord)
+	}
+	return origin, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+func TestCatalogOrderService(t *testing.T) {
+	ctx := context.Background()
+	db := newCatalogDB(t)
+	categories := NewCategoryService(db)
+	cities := NewCityService(db, nil)
+	suppliers := NewSupplierService(db)
+	products := NewProductService(db, cities)
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	orders := NewCatalogOrderService(NewOrderService(store, repo, bus,
+		domain.WithClock(domain.FixedClock(domaintest.Now)),
+		domain.WithIDGenerator(&domaintest.SequenceIDs{}),
+	), products)
+
+	books, _ := categories.CreateCategory(ctx, "Sách")
+	hanoi, _ := cities.CreateCity(ctx, models.GEO{Name: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542})
+	fahasa, err := suppliers.CreateSupplier(ctx, "Nhà sách Fahasa", &models.GEO{Name: "Kho Long Biên", Latitude: 21.05, Longitude: 105.88})
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+	tiki, _ := suppliers.CreateSupplier(ctx, "Tiki", nil)
+
+	create := func(name string, supplierID string, weight float64, status models.ModelStatus) *models.Product {
+		product, err := products.CreateProduct(ctx, ProductInput{
+			Name:       name,
+			Price:      decimal.RequireFromString("85000.50"),
+			Quantity:   10,
+			Weight:     weight,
+			Status:     status,
+			Categories: []string{books.ID},
+			CityID:     hanoi.ID,
+			SupplierID: supplierID,
+		})
+		if err != nil {
+			t.Fatalf("CreateProduct %s: %v", name, err)
+		}
+		return product
+	}
+	book := create("Dế mèn phiêu lưu ký", fahasa.ID, 0.4, models.MSActive)
+	unweighed := create("Tắt đèn", fahasa.ID, 0, models.MSActive)
+	other := create("Số đỏ", tiki.ID, 0.3, models.MSActive)
+	retired := create("Truyện Kiều", fahasa.ID, 0.2, models.MSDeActive)
+	destination := domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+
+	t.Run("ResolvesItemsAndOrigin", func(t *testing.T) {
+		items := []domain.OrderItem{
+			{ProductUID: book.ID, Name: "tên nhập tay", Quantity: 2, Weight: decimal.NewFromInt(9), Price: decimal.NewFromInt(1)},
+			{ProductUID: unweighed.ID, Quantity: 1, Weight: decimal.RequireFromString("0.7")},
+			// Ảnh chụp sản phẩm do client gửi lên bị bỏ qua
+			{ID: "GIFT", Name: "Thiệp", Quantity: 1, Price: decimal.NewFromInt(5000), Product: &domain.ProductSnapshot{Name: "giả mạo"}},
+		}
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, destination, items, domain.ServiceLevelStandard, domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		if items[0].Name != "tên nhập tay" || items[2].Product == nil {
+			t.Fatal("items của caller bị sửa")
+		}
+
+		order, err := orders.GetOrder(ctx, id)
+		if err != nil {
+			t.Fatalf("GetOrder: %v", err)
+		}
+		wantOrigin := domain.Location{Address: "Kho Long Biên", City: "Hà Nội", Latitude: 21.05, Longitude: 105.88}
+		if order.Origin != wantOrigin {
+			t.Fatalf("origin = %+v, muốn %+v", order.Origin, wantOrigin)
+		}
+
+		first := order.Items[0]
+		if first.ID != book.Reference || first.Name != book.Name || first.Price.String() != "85000.5" || first.Weight.String() != "0.4" || first.Quantity != 2 {
+			t.Fatalf("items[0] = %+v", first)
+		}
+		wantSnapshot := domain.ProductSnapshot{
+			Reference:    book.Reference,
+			Name:         book.Name,
+			Price:        decimal.RequireFromString("85000.5"),
+			Weight:       decimal.RequireFromString("0.4"),
+			CityID:       hanoi.ID,
+			SupplierID:   fahasa.ID,
+			SupplierName: "Nhà sách Fahasa",
+		}
+		if first.Product == nil || !reflect.DeepEqual(*first.Product, wantSnapshot) {
+			t.Fatalf("items[0].Product = %+v, muốn %+v", first.Product, wantSnapshot)
+		}
+		// Sản phẩm chưa khai báo khối lượng giữ khối lượng nhập tay
+		if order.Items[1].Weight.String() != "0.7" || order.Items[1].Name != unweighed.Name {
+			t.Fatalf("items[1] = %+v", order.Items[1])
+		}
+		if order.Items[2].Product != nil || order.Items[2].Name != "Thiệp" {
+			t.Fatalf("items[2] = %+v", order.Items[2])
+		}
+
+		// Sửa danh mục không làm thay đổi lịch sử đơn hàng
+		_, err = products.UpdateProduct(ctx, book.ID, ProductInput{
+			Name:       "Dế mèn (tái bản)",
+			Price:      decimal.NewFromInt(99000),
+			Quantity:   10,
+			Status:     models.MSActive,
+			Categories: []string{books.ID},
+			CityID:     hanoi.ID,
+			SupplierID: fahasa.ID,
+		})
+		if err != nil {
+			t.Fatalf("UpdateProduct: %v", err)
+		}
+		events, err := orders.GetOrderHistory(ctx, id)
+		if err != nil {
+			t.Fatalf("GetOrderHistory: %v", err)
+		}
+		created := events[0].(domain.OrderCreatedEvent)
+		if !reflect.DeepEqual(*created.Items[0].Product, wantSnapshot) {
+			t.Fatalf("snapshot trong sự kiện = %+v", created.Items[0].Product)
+		}
+	})
+
+	t.Run("SupplierWithoutWarehouse", func(t *testing.T) {
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, destination,
+			[]domain.OrderItem{{ProductUID: other.ID, Quantity: 1}}, domain.ServiceLevelStandard, domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		order, _ := orders.GetOrder(ctx, id)
+		if order.Origin != (domain.Location{City: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542}) {
+			t.Fatalf("origin = %+v, muốn thành phố của sản phẩm", order.Origin)
+		}
+	})
+
+	t.Run("Rejects", func(t *testing.T) {
+		for name, items := range map[string][]domain.OrderItem{
+			"unknown":   {{ProductUID: "khong-ton-tai", Quantity: 1}},
+			"retired":   {{ProductUID: retired.ID, Quantity: 1}},
+			"suppliers": {{ProductUID: book.ID, Quantity: 1}, {ProductUID: other.ID, Quantity: 1}},
+		} {
+			if _, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, destination, items, domain.ServiceLevelStandard, domain.PaymentTerms{}); !errors.Is(err, ErrInvalidRecord) {
+				t.Fatalf("%s: err = %v, muốn ErrInvalidRecord", name, err)
+			}
+		}
+
+		// Truyền điểm gửi thì sản phẩm của nhiều nhà cung cấp vẫn được chấp nhận
+		origin := domain.Location{Address: "Kho tổng", City: "Hà Nội", Latitude: 21, Longitude: 105.8}
+		items := []domain.OrderItem{{ProductUID: book.ID, Quantity: 1}, {ProductUID: other.ID, Quantity: 1}}
+		if _, _, err := orders.CreateOrder(ctx, "CUS-001", origin, destination, items, domain.ServiceLevelStandard, domain.PaymentTerms{}); err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// CategoryService quản lý danh mục sản phẩm
+type CategoryService interface {
+	ListCategories(ctx context.Context, search string, offset, limit int) ([]models.Category, error)
+	CreateCategory(ctx context.Context, name string) (*models.Category, error)
+	UpdateCategory(ctx context.Context, id string, name *string, status *models.ModelStatus) (*models.Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+}
+
+type categoryService struct {
+	db *bun.DB
+}
+
+// NewCategoryService tạo service danh mục
+func NewCategoryService(db *bun.DB) CategoryService {
+	return &categoryService{db: db}
+}
+
+// ListCategories lấy danh sách danh mục có tên chứa search, mới nhất trước
+func (s *categoryService) ListCategories(ctx context.Context, search string, offset, limit int) ([]models.Category, error) {
+	offset, limit = catalogPage(offset, limit)
+	categories := make([]models.Category, 0)
+	query := s.db.NewSelect().
+		Model(&categories).
+		OrderExpr("cat.created_time DESC, cat.id ASC").
+		Offset(offset).
+		Limit(limit)
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where(`LOWER(cat.name) LIKE ? ESCAPE '!'`, containsPattern(search))
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn danh mục: %w", err)
+	}
+	return categories, nil
+}
+
+// CreateCategory tạo danh mục đang hoạt động
+func (s *categoryService) CreateCategory(ctx context.Context, name string) (*models.Category, error) {
+	now := time.Now()
+	category := &models.Category{
+		ID:          uuid.NewString(),
+		Name:        strings.TrimSpace(name),
+		Status:      models.MSActive,
+		CreatedTime: now,
+		UpdatedTime: now,
+	}
+	if _, err := s.db.NewInsert().Model(category).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi tạo danh mục: %w", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory cập nhật tên và trạng thái của danh mục, trường nil giữ nguyên
+func (s *categoryService) UpdateCategory(ctx context.Context, id string, name *string, status *models.ModelStatus) (*models.Category, error) {
+	category, err := s.getCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		category.Name = strings.TrimSpace(*name)
+	}
+	if status != nil {
+		category.Status = *status
+	}
+	category.UpdatedTime = time.Now()
+
+	_, err = s.db.NewUpdate().
+		Model(category).
+		Column("name", "status", "updated_time").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi cập nhật danh mục: %w", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory xóa mềm danh mục
+func (s *categoryService) DeleteCategory(ctx context.Context, id string) error {
+	res, err := s.db.NewDelete().
+		Model((*models.Category)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi xóa danh mục: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("danh mục %s: %w", id, ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *categoryService) getCategory(ctx context.Context, id string) (*models.Category, error) {
+	category := &models.Category{}
+	err := s.db.NewSelect().
+		Model(category).
+		Where("cat.id = ?", id).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("danh mục %s: %w", id, ErrRecordNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn danh mục: %w", err)
+	}
+	return category, nil
+}
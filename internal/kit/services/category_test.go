@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/quyenle-97/init/pkgs/rdbms"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// newCatalogDB tạo cơ sở dữ liệu SQLite trong bộ nhớ đã có các bảng danh mục
+func newCatalogDB(t *testing.T) *bun.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { db.Close() })
+	models.Init(db)
+
+	for _, m := range []rdbms.MFile{migrations.CatalogTables{}, migrations.SuppliersLocation{}, migrations.ProductsWeight{}} {
+		if err := m.Up(db); err != nil {
+			t.Fatalf("%s.Up: %v", m.GetStructName(), err)
+		}
+	}
+	return db
+}
+
+func TestCategoryService(t *testing.T) {
+	ctx := context.Background()
+	s := NewCategoryService(newCatalogDB(t))
+
+	electronics, err := s.CreateCategory(ctx, " Điện tử ")
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	if electronics.Name != "Điện tử" || electronics.Status != models.MSActive {
+		t.Fatalf("category = %+v", electronics)
+	}
+	if _, err := s.CreateCategory(ctx, "Sách 100%"); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	// Tìm kiếm không phân biệt hoa thường, ký tự % được tìm như chữ thường
+	found, err := s.ListCategories(ctx, "SÁCH 100%", 0, 0)
+	if err != nil {
+		t.Fatalf("ListCategories: %v", err)
+	}
+	if len(found) != 1 || found[0].Name != "Sách 100%" {
+		t.Fatalf("found = %+v", found)
+	}
+	if found, _ = s.ListCategories(ctx, "0%x", 0, 0); len(found) != 0 {
+		t.Fatalf("found = %+v, muốn rỗng", found)
+	}
+	all, err := s.ListCategories(ctx, "", 0, 1)
+	if err != nil || len(all) != 1 {
+		t.Fatalf("ListCategories limit 1 = %+v, %v", all, err)
+	}
+
+	status := models.MSDeActive
+	updated, err := s.UpdateCategory(ctx, electronics.ID, nil, &status)
+	if err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+	if updated.Name != "Điện tử" || updated.Status != models.MSDeActive {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	if err := s.DeleteCategory(ctx, electronics.ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	if err := s.DeleteCategory(ctx, electronics.ID); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("xóa lần hai: err = %v, muốn ErrRecordNotFound", err)
+	}
+	if _, err := s.UpdateCategory(ctx, electronics.ID, nil, nil); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("cập nhật bản ghi đã xóa: err = %v, muốn ErrRecordNotFound", err)
+	}
+	if all, _ = s.ListCategories(ctx, "", 0, 0); len(all) != 1 {
+		t.Fatalf("danh sách sau khi xóa = %+v", all)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/pkgs/cache"
+	"github.com/uptrace/bun"
+)
+
+// cityCacheTTL là thời gian giữ tọa độ thành phố trong cache, cập nhật hoặc xóa thành phố sẽ xóa cache ngay
+const cityCacheTTL = 24 * time.Hour
+
+// CityService quản lý thành phố nơi đặt sản phẩm
+type CityService interface {
+	ListCities(ctx context.Context, search string, offset, limit int) ([]models.City, error)
+	CreateCity(ctx context.Context, geo models.GEO) (*models.City, error)
+	UpdateCity(ctx context.Context, id string, name *string, latitude, longitude *float64) (*models.City, error)
+	DeleteCity(ctx context.Context, id string) error
+
+	// LocateCity lấy tên và tọa độ của thành phố, đọc qua cache nếu có
+	LocateCity(ctx context.Context, id string) (models.GEO, error)
+}
+
+type cityService struct {
+	db    *bun.DB
+	cache cache.Cache // nil khi không dùng cache
+}
+
+// NewCityService tạo service thành phố, geoCache có thể nil
+func NewCityService(db *bun.DB, geoCache cache.Cache) CityService {
+	return &cityService{db: db, cache: geoCache}
+}
+
+// ListCities lấy danh sách thành phố có tên chứa search, mới nhất trước
+func (s *cityService) ListCities(ctx context.Context, search string, offset, limit int) ([]models.City, error) {
+	offset, limit = catalogPage(offset, limit)
+	cities := make([]models.City, 0)
+	query := s.db.NewSelect().
+		Model(&cities).
+		OrderExpr("ci.created_time DESC, ci.id ASC").
+		Offset(offset).
+		Limit(limit)
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where(`LOWER(ci.name) LIKE ? ESCAPE '!'`, containsPattern(search))
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn thành phố: %w", err)
+	}
+	return cities, nil
+}
+
+// CreateCity tạo thành phố với tên và tọa độ
+func (s *cityService) CreateCity(ctx context.Context, geo models.GEO) (*models.City, error) {
+	geo.Name = strings.TrimSpace(geo.Name)
+	if err := validateCoordinates(geo.Latitude, geo.Longitude); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	city := &models.City{
+		ID:          uuid.NewString(),
+		GEO:         geo,
+		CreatedTime: now,
+		UpdatedTime: now,
+	}
+	if _, err := s.db.NewInsert().Model(city).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi tạo thành phố: %w", err)
+	}
+	return city, nil
+}
+
+// UpdateCity cập nhật tên và tọa độ của thành phố, trường nil giữ nguyên
+func (s *cityService) UpdateCity(ctx context.Context, id string, name *string, latitude, longitude *float64) (*models.City, error) {
+	city, err := s.getCity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		city.Name = strings.TrimSpace(*name)
+	}
+	if latitude != nil {
+		city.Latitude = *latitude
+	}
+	if longitude != nil {
+		city.Longitude = *longitude
+	}
+	if err := validateCoordinates(city.Latitude, city.Longitude); err != nil {
+		return nil, err
+	}
+	city.UpdatedTime = time.Now()
+
+	_, err = s.db.NewUpdate().
+		Model(city).
+		Column("name", "latitude", "longitude", "updated_time").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi cập nhật thành phố: %w", err)
+	}
+	s.forget(ctx, id)
+	return city, nil
+}
+
+// DeleteCity xóa mềm thành phố
+func (s *cityService) DeleteCity(ctx context.Context, id string) error {
+	res, err := s.db.NewDelete().
+		Model((*models.City)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi xóa thành phố: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("thành phố %s: %w", id, ErrRecordNotFound)
+	}
+	s.forget(ctx, id)
+	return nil
+}
+
+// LocateCity lấy tên và tọa độ của thành phố. Cache lỗi thì đọc thẳng từ cơ sở dữ liệu.
+func (s *cityService) LocateCity(ctx context.Context, id string) (models.GEO, error) {
+	var location models.GEO
+	if s.cache != nil {
+		if ok, err := s.cache.Get(ctx, cityCacheKey(id), &location); err == nil && ok {
+			return location, nil
+		}
+	}
+
+	city, err := s.getCity(ctx, id)
+	if err != nil {
+		return models.GEO{}, err
+	}
+	if s.cache != nil {
+		_ = s.cache.Set(ctx, cityCacheKey(id), city.GEO, cityCacheTTL)
+	}
+	return city.GEO, nil
+}
+
+// forget xóa tọa độ đã cache của thành phố
+func (s *cityService) forget(ctx context.Context, id string) {
+	if s.cache != nil {
+		_ = s.cache.Delete(ctx, cityCacheKey(id))
+	}
+}
+
+// cityCacheKey là key cache tọa độ của thành phố
+func cityCacheKey(id string) string {
+	return "catalog:city:" + id
+}
+
+func (s *cityService) getCity(ctx context.Context, id string) (*models.City, error) {
+	city := &models.City{}
+	err := s.db.NewSelect().
+		Model(city).
+		Where("ci.id = ?", id).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("thành phố %s: %w", id, ErrRecordNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn thành phố: %w", err)
+	}
+	return city, nil
+}
+
+// validateCoordinates kiểm tra vĩ độ và kinh độ nằm trong phạm vi hợp lệ
+func validateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return fmt.Errorf("tọa độ (%v, %v) ngoài phạm vi: %w", latitude, longitude, ErrInvalidRecord)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// ServiceLevelRule là thời gian vận chuyển theo mức dịch vụ
+type ServiceLevelRule struct {
+	HandlingHours  float64 `json:"handling_hours"`    // thời gian xử lý tại kho trước khi xuất hàng
+	SpeedKmPerHour float64 `json:"speed_km_per_hour"` // tốc độ vận chuyển trung bình, đã tính thời gian dừng
+	LastMileHours  float64 `json:"last_mile_hours"`   // thời gian giao chặng cuối từ bưu cục tới người nhận
+	BufferHours    float64 `json:"buffer_hours"`      // dự phòng cộng vào ETA đầu tiên để ra thời gian cam kết
+}
+
+// StatusRule xác định các chặng còn lại của đơn hàng ở một trạng thái
+type StatusRule struct {
+	Handling   bool    `json:"handling"`
+	Transit    bool    `json:"transit"` // tính từ vị trí hiện tại nếu có tọa độ, ngược lại từ điểm gửi
+	LastMile   bool    `json:"last_mile"`
+	ExtraHours float64 `json:"extra_hours"` // thời gian cộng thêm, ví dụ khi đơn hàng gặp sự cố
+}
+
+// ETARules là cấu hình tính thời gian giao hàng dự kiến.
+// Trạng thái không có trong Statuses (DELIVERED, CANCELLED) không được tính ETA.
+type ETARules struct {
+	ServiceLevels map[domain.ServiceLevel]ServiceLevelRule `json:"service_levels"`
+	Statuses      map[domain.OrderStatus]StatusRule        `json:"statuses"`
+
+	// UnknownDistanceKm là quãng đường giả định khi điểm gửi hoặc điểm nhận không có tọa độ
+	UnknownDistanceKm float64 `json:"unknown_distance_km"`
+}
+
+// DefaultETARules trả về cấu hình mặc định cho vận chuyển đường bộ nội địa
+func DefaultETARules() ETARules {
+	return ETARules{
+		ServiceLevels: map[domain.ServiceLevel]ServiceLevelRule{
+			domain.ServiceLevelStandard: {HandlingHours: 24, SpeedKmPerHour: 25, LastMileHours: 12, BufferHours: 24},
+			domain.ServiceLevelExpress:  {HandlingHours: 4, SpeedKmPerHour: 50, LastMileHours: 4, BufferHours: 6},
+		},
+		Statuses: map[domain.OrderStatus]StatusRule{
+			domain.OrderStatusCreated:        {Handling: true, Transit: true, LastMile: true},
+			domain.OrderStatusProcessing:     {Handling: true, Transit: true, LastMile: true},
+			domain.OrderStatusInTransit:      {Transit: true, LastMile: true},
+			domain.OrderStatusOutForDelivery: {LastMile: true},
+			domain.OrderStatusException:      {Transit: true, LastMile: true, ExtraHours: 24},
+		},
+		UnknownDistanceKm: 300,
+	}
+}
+
+// LoadETARules đọc cấu hình từ file JSON, các mục không khai báo giữ giá trị mặc định
+func LoadETARules(path string) (ETARules, error) {
+	rules := DefaultETARules()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, fmt.Errorf("lỗi khi đọc cấu hình ETA: %w", err)
+	}
+
+	var override ETARules
+	if err := json.Unmarshal(data, &override); err != nil {
+		return rules, fmt.Errorf("lỗi khi đọc cấu hình ETA: %w", err)
+	}
+
+	for level, rule := range override.ServiceLevels {
+		if !level.Valid() {
+			return rules, fmt.Errorf("mức dịch vụ không hợp lệ trong cấu hình ETA: %s", level)
+		}
+		if rule.SpeedKmPerHour <= 0 {
+			return rules, fmt.Errorf("tốc độ vận chuyển của %s phải lớn hơn 0", level)
+		}
+		rules.ServiceLevels[level] = rule
+	}
+	for status, rule := range override.Statuses {
+		rules.Statuses[status] = rule
+	}
+	if override.UnknownDistanceKm > 0 {
+		rules.UnknownDistanceKm = override.UnknownDistanceKm
+	}
+
+	return rules, nil
+}
+
+// ETAService ước tính thời gian giao hàng theo khoảng cách, mức dịch vụ và trạng thái hiện tại của đơn hàng
+type ETAService struct {
+	rules ETARules
+}
+
+// NewETAService tạo ETAService với cấu hình cho trước
+func NewETAService(rules ETARules) *ETAService {
+	return &ETAService{rules: rules}
+}
+
+// EstimateDelivery triển khai domain.DeliveryEstimator
+func (s *ETAService) EstimateDelivery(order *domain.Order, at time.Time) (domain.DeliveryEstimate, bool) {
+	status, ok := s.rules.Statuses[order.Status]
+	if !ok {
+		return domain.DeliveryEstimate{}, false
+	}
+
+	level, ok := s.rules.ServiceLevels[order.ServiceLevel]
+	if !ok {
+		level = s.rules.ServiceLevels[domain.ServiceLevelStandard]
+	}
+
+	hours := status.ExtraHours
+	if status.Handling {
+		hours += level.HandlingHours
+	}
+	if status.Transit && level.SpeedKmPerHour > 0 {
+		hours += s.remainingDistanceKm(order) / level.SpeedKmPerHour
+	}
+	if status.LastMile {
+		hours += level.LastMileHours
+	}
+
+	// Làm tròn lên phút để ETA không đổi vì sai số của phép tính
+	estimated := at.Add(time.Duration(math.Ceil(hours*60)) * time.Minute).Truncate(time.Minute)
+
+	return domain.DeliveryEstimate{
+		Estimated: estimated,
+		Promised:  estimated.Add(time.Duration(level.BufferHours * float64(time.Hour))),
+	}, true
+}
+
+// remainingDistanceKm là quãng đường còn lại tới điểm nhận
+func (s *ETAService) remainingDistanceKm(order *domain.Order) float64 {
+	from, to := order.Position(), order.Destination
+	if !from.HasCoordinates() || !to.HasCoordinates() {
+		return s.rules.UnknownDistanceKm
+	}
+	return geo.DistanceKm(from.Point(), to.Point())
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+var etaNow = time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)
+
+// etaOrder là đơn hàng từ TP.HCM đi Hà Nội, khoảng 1140 km
+func etaOrder(level domain.ServiceLevel, status domain.OrderStatus) *domain.Order {
+	return &domain.Order{
+		Status:       status,
+		ServiceLevel: level,
+		Origin:       domain.Location{City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009},
+		Destination:  domain.Location{City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412},
+	}
+}
+
+func TestETAServiceEstimateDelivery(t *testing.T) {
+	eta := NewETAService(DefaultETARules())
+
+	standard, ok := eta.EstimateDelivery(etaOrder(domain.ServiceLevelStandard, domain.OrderStatusCreated), etaNow)
+	if !ok {
+		t.Fatal("không ước tính được ETA cho đơn hàng mới")
+	}
+	// 24 giờ xử lý + 1140 km / 25 km/h + 12 giờ giao chặng cuối
+	if hours := standard.Estimated.Sub(etaNow).Hours(); hours < 80 || hours > 83 {
+		t.Fatalf("ETA STANDARD sau %.1f giờ, muốn khoảng 81,6 giờ", hours)
+	}
+	if got := standard.Promised.Sub(standard.Estimated); got != 24*time.Hour {
+		t.Fatalf("thời gian dự phòng = %v, muốn 24h", got)
+	}
+
+	express, _ := eta.EstimateDelivery(etaOrder(domain.ServiceLevelExpress, domain.OrderStatusCreated), etaNow)
+	if !express.Estimated.Before(standard.Estimated) {
+		t.Fatalf("ETA EXPRESS %v không sớm hơn STANDARD %v", express.Estimated, standard.Estimated)
+	}
+
+	// Đang vận chuyển thì quãng đường tính từ vị trí hiện tại
+	inTransit := etaOrder(domain.ServiceLevelStandard, domain.OrderStatusInTransit)
+	inTransit.CurrentLocation = &domain.Location{City: "Đà Nẵng", Latitude: 16.0544, Longitude: 108.2022}
+	nearer, _ := eta.EstimateDelivery(inTransit, etaNow)
+	inTransit.CurrentLocation = nil
+	fromOrigin, _ := eta.EstimateDelivery(inTransit, etaNow)
+	if !nearer.Estimated.Before(fromOrigin.Estimated) {
+		t.Fatalf("ETA từ Đà Nẵng %v không sớm hơn ETA từ điểm gửi %v", nearer.Estimated, fromOrigin.Estimated)
+	}
+
+	outForDelivery, _ := eta.EstimateDelivery(etaOrder(domain.ServiceLevelStandard, domain.OrderStatusOutForDelivery), etaNow)
+	if !outForDelivery.Estimated.Equal(etaNow.Add(12 * time.Hour)) {
+		t.Fatalf("ETA khi đang giao = %v, muốn sau 12 giờ", outForDelivery.Estimated)
+	}
+
+	for _, status := range []domain.OrderStatus{domain.OrderStatusDelivered, domain.OrderStatusCancelled} {
+		if _, ok := eta.EstimateDelivery(etaOrder(domain.ServiceLevelStandard, status), etaNow); ok {
+			t.Fatalf("đơn hàng %s không được ước tính ETA", status)
+		}
+	}
+}
+
+func TestETAServiceUnknownDistance(t *testing.T) {
+	eta := NewETAService(DefaultETARules())
+	order := etaOrder(domain.ServiceLevelStandard, domain.OrderStatusInTransit)
+	order.Destination = domain.Location{City: "Hà Nội"}
+
+	estimate, _ := eta.EstimateDelivery(order, etaNow)
+	// 300 km / 25 km/h + 12 giờ giao chặng cuối
+	if !estimate.Estimated.Equal(etaNow.Add(24 * time.Hour)) {
+		t.Fatalf("ETA khi thiếu tọa độ = %v, muốn sau 24 giờ", estimate.Estimated)
+	}
+}
+
+func TestLoadETARules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eta.json")
+	config := `{"service_levels": {"EXPRESS": {"handling_hours": 1, "speed_km_per_hour": 60, "last_mile_hours": 2}}, "statuses": {"EXCEPTION": {"extra_hours": 48, "last_mile": true}}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadETARules(path)
+	if err != nil {
+		t.Fatalf("LoadETARules: %v", err)
+	}
+	if rules.ServiceLevels[domain.ServiceLevelExpress].SpeedKmPerHour != 60 {
+		t.Fatalf("EXPRESS = %+v", rules.ServiceLevels[domain.ServiceLevelExpress])
+	}
+	if rules.ServiceLevels[domain.ServiceLevelStandard] != DefaultETARules().ServiceLevels[domain.ServiceLevelStandard] {
+		t.Fatalf("STANDARD không giữ giá trị mặc định: %+v", rules.ServiceLevels[domain.ServiceLevelStandard])
+	}
+	if rules.Statuses[domain.OrderStatusException].ExtraHours != 48 || !rules.Statuses[domain.OrderStatusCreated].Transit {
+		t.Fatalf("Statuses = %+v", rules.Statuses)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"service_levels": {"EXPRESS": {"speed_km_per_hour": 0}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadETARules(path); err == nil {
+		t.Fatal("LoadETARules chấp nhận tốc độ bằng 0")
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/pkgs/xlsx"
+)
+
+// Các định dạng xuất đơn hàng được hỗ trợ
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatXLSX   = "xlsx"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// exportEventsBatch là số đơn hàng được tải lịch sử sự kiện trong một lần truy vấn
+const exportEventsBatch = 200
+
+// ExportFilter là bộ lọc đơn hàng khi xuất, giống ListOrders
+type ExportFilter struct {
+	CustomerID    string
+	Status        domain.OrderStatus
+	IncludeEvents bool // kèm lịch sử sự kiện của từng đơn hàng, chỉ với NDJSON
+}
+
+// exportColumns là các cột của file CSV và XLSX, mỗi mặt hàng của đơn hàng là một dòng
+var exportColumns = []string{
+	"order_id", "customer_id", "tracking_number", "status", "created_at", "updated_at",
+	"origin_address", "origin_city", "origin_latitude", "origin_longitude",
+	"destination_address", "destination_city", "destination_latitude", "destination_longitude",
+	"current_city", "notes",
+	"currency", "subtotal", "total_weight", "declared_value", "shipping_fee",
+	"item_index", "item_id", "item_name", "item_quantity", "item_weight", "item_price",
+}
+
+// rowWriter ghi từng dòng của file CSV hoặc XLSX
+type rowWriter interface {
+	WriteRow(values ...interface{}) error
+	Close() error
+}
+
+// ExportOrders ghi các đơn hàng theo bộ lọc vào w, đọc lần lượt từ read model thay vì tải tất cả vào bộ nhớ
+func (s *orderService) ExportOrders(ctx context.Context, format string, filter ExportFilter, w io.Writer) error {
+	switch format {
+	case ExportFormatNDJSON:
+		return s.exportNDJSON(ctx, filter, w)
+	case ExportFormatCSV, ExportFormatXLSX:
+		if filter.IncludeEvents {
+			return fmt.Errorf("lịch sử sự kiện chỉ được xuất với định dạng %s", ExportFormatNDJSON)
+		}
+	default:
+		return fmt.Errorf("định dạng xuất không được hỗ trợ: %q", format)
+	}
+
+	var rw rowWriter
+	if format == ExportFormatXLSX {
+		xw, err := xlsx.NewWriter(w, "orders")
+		if err != nil {
+			return err
+		}
+		rw = xw
+	} else {
+		rw = &csvRowWriter{w: csv.NewWriter(w)}
+	}
+
+	header := make([]interface{}, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	if err := rw.WriteRow(header...); err != nil {
+		return err
+	}
+
+	err := s.orderRepo.StreamOrders(ctx, filter.CustomerID, filter.Status, func(order *domain.Order) error {
+		for _, row := range exportRows(order) {
+			if err := rw.WriteRow(row...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("lỗi khi xuất đơn hàng: %w", err)
+	}
+
+	return rw.Close()
+}
+
+// exportedOrder là một dòng NDJSON, Events chỉ có khi xuất kèm lịch sử
+type exportedOrder struct {
+	*domain.Order
+	Events []domain.Event `json:"events,omitempty"`
+}
+
+// MarshalJSON thêm events vào JSON của đơn hàng, cần thiết vì domain.Order tự định nghĩa MarshalJSON
+func (e exportedOrder) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(e.Order)
+	if err != nil || len(e.Events) == 0 {
+		return data, err
+	}
+
+	events, err := json.Marshal(e.Events)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data[:len(data)-1], `,"events":`...)
+	data = append(data, events...)
+	return append(data, '}'), nil
+}
+
+// exportNDJSON ghi mỗi đơn hàng trên một dòng JSON, lịch sử sự kiện được tải theo lô
+func (s *orderService) exportNDJSON(ctx context.Context, filter ExportFilter, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	pending := make([]*domain.Order, 0, exportEventsBatch)
+
+	flush := func() error {
+		var streams map[string][]domain.Event
+		if filter.IncludeEvents && len(pending) > 0 {
+			ids := make([]string, len(pending))
+			for i, order := range pending {
+				ids[i] = order.ID
+			}
+			var err error
+			if streams, err = s.eventStore.GetEventsBatch(ctx, ids); err != nil {
+				return fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+			}
+		}
+
+		for _, order := range pending {
+			line := exportedOrder{Order: order}
+			if filter.IncludeEvents {
+				line.Events = streams[order.ID]
+			}
+			if err := encoder.Encode(line); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	err := s.orderRepo.StreamOrders(ctx, filter.CustomerID, filter.Status, func(order *domain.Order) error {
+		pending = append(pending, order)
+		if len(pending) < exportEventsBatch {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return fmt.Errorf("lỗi khi xuất đơn hàng: %w", err)
+	}
+
+	return flush()
+}
+
+// exportRows trải phẳng đơn hàng thành các dòng theo exportColumns, đơn hàng không có mặt hàng vẫn có một dòng
+func exportRows(order *domain.Order) [][]interface{} {
+	var currentCity, notes string
+	if order.CurrentLocation != nil {
+		currentCity = order.CurrentLocation.City
+	}
+	for i, note := range order.Notes {
+		if i > 0 {
+			notes += "\n"
+		}
+		notes += note
+	}
+
+	base := []interface{}{
+		order.ID, order.CustomerID, order.TrackingNumber, string(order.Status), order.CreatedAt, order.UpdatedAt,
+		order.Origin.Address, order.Origin.City, order.Origin.Latitude, order.Origin.Longitude,
+		order.Destination.Address, order.Destination.City, order.Destination.Latitude, order.Destination.Longitude,
+		currentCity, notes,
+		order.Currency, order.Totals.Subtotal, order.Totals.TotalWeight, order.Totals.DeclaredValue, nil,
+	}
+	if order.ShippingFee != nil {
+		base[len(base)-1] = *order.ShippingFee
+	}
+
+	if len(order.Items) == 0 {
+		return [][]interface{}{append(base, nil, nil, nil, nil, nil, nil)}
+	}
+
+	rows := make([][]interface{}, len(order.Items))
+	for i, item := range order.Items {
+		row := append([]interface{}{}, base...)
+		rows[i] = append(row, i+1, item.ID, item.Name, item.Quantity, item.Weight, item.Price)
+	}
+	return rows
+}
+
+// csvRowWriter ghi dòng CSV, thời gian theo RFC3339
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case int:
+			record[i] = strconv.Itoa(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			if !v.IsZero() {
+				record[i] = v.Format(time.RFC3339)
+			}
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
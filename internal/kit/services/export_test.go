@@ -0,0 +1,214 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+func newTestOrderService(t *testing.T) OrderService {
+	t.Helper()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	return NewOrderService(eventstore.NewInMemoryEventStore(), repo, bus, domaintest.Options()...)
+}
+
+// seedExportOrders tạo hai đơn hàng của CUS-001 (một đơn hai mặt hàng, đã vận chuyển) và một đơn của CUS-002
+func seedExportOrders(t *testing.T, service OrderService) []string {
+	t.Helper()
+	ctx := context.Background()
+	origin := domain.Location{Address: "12 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destination := domain.Location{Address: "1 Tràng Tiền, \"tầng 2\"", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+
+	orders := []struct {
+		customerID string
+		items      []domain.OrderItem
+	}{
+		{"CUS-001", []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 2, Weight: decimal.RequireFromString("0.5"), Price: decimal.NewFromInt(120000)}, {ID: "ITEM-2", Name: "Bút <xanh>", Quantity: 1, Weight: decimal.Zero, Price: decimal.NewFromInt(5000)}}},
+		{"CUS-001", []domain.OrderItem{{ID: "ITEM-3", Name: "Vở", Quantity: 5}}},
+		{"CUS-002", []domain.OrderItem{{ID: "ITEM-4", Name: "Thước", Quantity: 1}}},
+	}
+
+	var ids []string
+	for _, o := range orders {
+		id, _, err := service.CreateOrder(ctx, o.customerID, origin, destination, o.items, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	hub := &domain.Location{City: "Đà Nẵng"}
+	if err := service.UpdateOrderStatus(ctx, ids[0], domain.OrderStatusInTransit, hub, "Đã rời kho"); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	return ids
+}
+
+func TestExportOrdersCSV(t *testing.T) {
+	service := newTestOrderService(t)
+	ids := seedExportOrders(t, service)
+
+	var buf bytes.Buffer
+	if err := service.ExportOrders(context.Background(), ExportFormatCSV, ExportFilter{CustomerID: "CUS-001"}, &buf); err != nil {
+		t.Fatalf("ExportOrders: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("đọc CSV: %v", err)
+	}
+	if len(records) != 4 || strings.Join(records[0], ",") != strings.Join(exportColumns, ",") {
+		t.Fatalf("CSV có %d dòng, header = %v", len(records), records[0])
+	}
+
+	column := func(record []string, name string) string {
+		for i, c := range exportColumns {
+			if c == name {
+				return record[i]
+			}
+		}
+		t.Fatalf("không có cột %s", name)
+		return ""
+	}
+
+	// Mỗi mặt hàng là một dòng, thông tin đơn hàng được lặp lại
+	var first [][]string
+	for _, record := range records[1:] {
+		if column(record, "order_id") == ids[0] {
+			first = append(first, record)
+		}
+	}
+	if len(first) != 2 {
+		t.Fatalf("đơn hàng hai mặt hàng có %d dòng", len(first))
+	}
+	row := first[1]
+	if column(row, "item_index") != "2" || column(row, "item_name") != "Bút <xanh>" || column(row, "item_price") != "5000" {
+		t.Fatalf("dòng mặt hàng = %v", row)
+	}
+	if column(row, "status") != string(domain.OrderStatusInTransit) || column(row, "current_city") != "Đà Nẵng" || column(row, "notes") != "Đã rời kho" {
+		t.Fatalf("dòng đơn hàng = %v", row)
+	}
+	if column(row, "destination_address") != "1 Tràng Tiền, \"tầng 2\"" || column(row, "destination_latitude") != "21.0245" {
+		t.Fatalf("địa chỉ giao = %v", row)
+	}
+	if column(row, "created_at") != domaintest.Now.Format("2006-01-02T15:04:05Z07:00") {
+		t.Fatalf("created_at = %s", column(row, "created_at"))
+	}
+
+	if err := service.ExportOrders(context.Background(), ExportFormatCSV, ExportFilter{IncludeEvents: true}, io.Discard); err == nil {
+		t.Fatal("xuất CSV kèm sự kiện phải trả về lỗi")
+	}
+	if err := service.ExportOrders(context.Background(), "pdf", ExportFilter{}, io.Discard); err == nil {
+		t.Fatal("định dạng không hỗ trợ phải trả về lỗi")
+	}
+}
+
+func TestExportOrdersXLSX(t *testing.T) {
+	service := newTestOrderService(t)
+	seedExportOrders(t, service)
+
+	var buf bytes.Buffer
+	if err := service.ExportOrders(context.Background(), ExportFormatXLSX, ExportFilter{Status: domain.OrderStatusCreated}, &buf); err != nil {
+		t.Fatalf("ExportOrders: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("file xlsx không phải zip hợp lệ: %v", err)
+	}
+
+	var sheet []byte
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("mở %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+
+		// Mọi phần của workbook phải là XML hợp lệ
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s không phải XML hợp lệ: %v", f.Name, err)
+			}
+		}
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = data
+		}
+	}
+
+	// Header và hai đơn hàng đang ở trạng thái CREATED, mỗi đơn một mặt hàng
+	if got := bytes.Count(sheet, []byte("<row ")); got != 3 {
+		t.Fatalf("sheet có %d dòng, muốn 3", got)
+	}
+	for _, want := range []string{"Vở", "Thước", "1 Tràng Tiền, &#34;tầng 2&#34;", `r="V3"`} {
+		if !bytes.Contains(sheet, []byte(want)) {
+			t.Fatalf("sheet thiếu %q", want)
+		}
+	}
+}
+
+func TestExportOrdersNDJSONWithEvents(t *testing.T) {
+	service := newTestOrderService(t)
+	ids := seedExportOrders(t, service)
+
+	var buf bytes.Buffer
+	if err := service.ExportOrders(context.Background(), ExportFormatNDJSON, ExportFilter{IncludeEvents: true}, &buf); err != nil {
+		t.Fatalf("ExportOrders: %v", err)
+	}
+
+	events := map[string][]string{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line struct {
+			ID     string `json:"id"`
+			Items  []domain.OrderItem
+			Events []struct {
+				Type    string `json:"type"`
+				Version int    `json:"version"`
+			} `json:"events"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("dòng NDJSON không hợp lệ: %v", err)
+		}
+		for _, event := range line.Events {
+			events[line.ID] = append(events[line.ID], event.Type)
+		}
+		if len(line.Items) == 0 {
+			t.Fatalf("đơn hàng %s thiếu mặt hàng", line.ID)
+		}
+	}
+
+	if len(events) != 3 || strings.Join(events[ids[0]], ",") != "ORDER_CREATED,ORDER_STATUS_UPDATED" {
+		t.Fatalf("sự kiện = %v", events)
+	}
+
+	buf.Reset()
+	if err := service.ExportOrders(context.Background(), ExportFormatNDJSON, ExportFilter{}, &buf); err != nil {
+		t.Fatalf("ExportOrders: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"events"`)) {
+		t.Fatal("xuất không kèm sự kiện không được có trường events")
+	}
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+// DriverOrders là các đơn hàng được phân công cho tài xế kèm tải trọng hiện tại của phương tiện
+type DriverOrders struct {
+	Driver  *domain.Driver
+	Vehicle *domain.Vehicle // nil khi tài xế chưa được gán phương tiện
+	Orders  []*domain.Order
+	LoadKg  decimal.Decimal // tổng khối lượng các đơn hàng chưa hoàn thành, không phụ thuộc bộ lọc trạng thái
+}
+
+// FleetService quản lý tài xế, phương tiện và việc phân công đơn hàng cho tài xế
+type FleetService interface {
+	// RegisterDriver đăng ký tài xế mới, trả về ID tài xế
+	RegisterDriver(ctx context.Context, name string) (string, error)
+
+	// GetDriver lấy tài xế theo ID
+	GetDriver(ctx context.Context, id string) (*domain.Driver, error)
+
+	// AssignVehicle gán phương tiện cho tài xế
+	AssignVehicle(ctx context.Context, driverID, vehicleID string) error
+
+	// DeactivateDriver ngừng nhận đơn hàng mới cho tài xế
+	DeactivateDriver(ctx context.Context, driverID, reason string) error
+
+	// ActivateDriver cho tài xế hoạt động trở lại
+	ActivateDriver(ctx context.Context, driverID string) error
+
+	// RegisterVehicle đăng ký phương tiện mới với tải trọng tính theo kg, trả về ID phương tiện
+	RegisterVehicle(ctx context.Context, plateNumber string, capacityKg decimal.Decimal) (string, error)
+
+	// GetVehicle lấy phương tiện theo ID
+	GetVehicle(ctx context.Context, id string) (*domain.Vehicle, error)
+
+	// RetireVehicle ngừng sử dụng phương tiện
+	RetireVehicle(ctx context.Context, vehicleID, reason string) error
+
+	// AssignOrder phân công đơn hàng cho tài xế nếu phương tiện của tài xế còn đủ tải trọng
+	AssignOrder(ctx context.Context, orderID, driverID string) error
+
+	// UnassignOrder hủy phân công tài xế của đơn hàng
+	UnassignOrder(ctx context.Context, orderID, reason string) error
+
+	// DriverOrders lấy các đơn hàng được phân công cho tài xế, lọc theo trạng thái nếu có
+	DriverOrders(ctx context.Context, driverID string, status domain.OrderStatus) (*DriverOrders, error)
+}
+
+type fleetService struct {
+	eventStore eventstore.EventStore
+	repository repository.OrderRepository
+	eventBus   eventbus.EventBus
+	orderOpts  []domain.OrderOption
+}
+
+// NewFleetService tạo service quản lý tài xế và phương tiện, orderOpts giống như của OrderService
+func NewFleetService(
+	eventStore eventstore.EventStore,
+	repository repository.OrderRepository,
+	eventBus eventbus.EventBus,
+	orderOpts ...domain.OrderOption,
+) FleetService {
+	return &fleetService{
+		eventStore: eventStore,
+		repository: repository,
+		eventBus:   eventBus,
+		orderOpts:  orderOpts,
+	}
+}
+
+// RegisterDriver đăng ký tài xế mới
+func (s *fleetService) RegisterDriver(ctx context.Context, name string) (string, error) {
+	driver, err := domain.NewDriver(name, s.orderOpts...)
+	if err != nil {
+		return "", fmt.Errorf("lỗi khi đăng ký tài xế: %w", err)
+	}
+
+	if err := s.commit(ctx, driver.ID, driver.GetUncommittedEvents()); err != nil {
+		return "", err
+	}
+	driver.ClearUncommittedEvents()
+
+	return driver.ID, nil
+}
+
+// GetDriver xây dựng lại tài xế từ event store
+func (s *fleetService) GetDriver(ctx context.Context, id string) (*domain.Driver, error) {
+	events, err := s.eventStore.GetEvents(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	driver := domain.RebuildDriver(events, s.orderOpts...)
+	if driver == nil {
+		return nil, errors.New("không tìm thấy tài xế")
+	}
+	return driver, nil
+}
+
+// AssignVehicle gán phương tiện đang hoạt động cho tài xế
+func (s *fleetService) AssignVehicle(ctx context.Context, driverID, vehicleID string) error {
+	vehicle, err := s.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+
+	return s.executeDriver(ctx, driverID, func(driver *domain.Driver) error {
+		if err := driver.AssignVehicle(vehicle); err != nil {
+			return fmt.Errorf("không thể gán phương tiện: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeactivateDriver ngừng nhận đơn hàng mới cho tài xế
+func (s *fleetService) DeactivateDriver(ctx context.Context, driverID, reason string) error {
+	return s.executeDriver(ctx, driverID, func(driver *domain.Driver) error {
+		if err := driver.Deactivate(reason); err != nil {
+			return fmt.Errorf("không thể ngừng hoạt động tài xế: %w", err)
+		}
+		return nil
+	})
+}
+
+// ActivateDriver cho tài xế hoạt động trở lại
+func (s *fleetService) ActivateDriver(ctx context.Context, driverID string) error {
+	return s.executeDriver(ctx, driverID, func(driver *domain.Driver) error {
+		if err := driver.Activate(); err != nil {
+			return fmt.Errorf("không thể kích hoạt tài xế: %w", err)
+		}
+		return nil
+	})
+}
+
+// executeDriver xây dựng lại tài xế, áp dụng lệnh rồi lưu và phát các sự kiện mới
+func (s *fleetService) executeDriver(ctx context.Context, driverID string, command func(driver *domain.Driver) error) error {
+	driver, err := s.GetDriver(ctx, driverID)
+	if err != nil {
+		return err
+	}
+
+	if err := command(driver); err != nil {
+		return err
+	}
+
+	if err := s.commit(ctx, driver.ID, driver.GetUncommittedEvents()); err != nil {
+		return err
+	}
+	driver.ClearUncommittedEvents()
+
+	return nil
+}
+
+// RegisterVehicle đăng ký phương tiện mới
+func (s *fleetService) RegisterVehicle(ctx context.Context, plateNumber string, capacityKg decimal.Decimal) (string, error) {
+	vehicle, err := domain.NewVehicle(plateNumber, capacityKg, s.orderOpts...)
+	if err != nil {
+		return "", fmt.Errorf("lỗi khi đăng ký phương tiện: %w", err)
+	}
+
+	if err := s.commit(ctx, vehicle.ID, vehicle.GetUncommittedEvents()); err != nil {
+		return "", err
+	}
+	vehicle.ClearUncommittedEvents()
+
+	return vehicle.ID, nil
+}
+
+// GetVehicle xây dựng lại phương tiện từ event store
+func (s *fleetService) GetVehicle(ctx context.Context, id string) (*domain.Vehicle, error) {
+	events, err := s.eventStore.GetEvents(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	vehicle := domain.RebuildVehicle(events, s.orderOpts...)
+	if vehicle == nil {
+		return nil, errors.New("không tìm thấy phương tiện")
+	}
+	return vehicle, nil
+}
+
+// RetireVehicle ngừng sử dụng phương tiện
+func (s *fleetService) RetireVehicle(ctx context.Context, vehicleID, reason string) error {
+	vehicle, err := s.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+
+	if err := vehicle.Retire(reason); err != nil {
+		return fmt.Errorf("không thể ngừng sử dụng phương tiện: %w", err)
+	}
+
+	if err := s.commit(ctx, vehicle.ID, vehicle.GetUncommittedEvents()); err != nil {
+		return err
+	}
+	vehicle.ClearUncommittedEvents()
+
+	return nil
+}
+
+// AssignOrder phân công đơn hàng, tải trọng hiện tại được tính từ read model đơn hàng của tài xế
+func (s *fleetService) AssignOrder(ctx context.Context, orderID, driverID string) error {
+	load, err := s.DriverOrders(ctx, driverID, "")
+	if err != nil {
+		return err
+	}
+
+	return s.executeOrder(ctx, orderID, func(order *domain.Order) error {
+		if err := order.AssignToDriver(load.Driver, load.Vehicle, load.LoadKg); err != nil {
+			return fmt.Errorf("không thể phân công đơn hàng: %w", err)
+		}
+		return nil
+	})
+}
+
+// UnassignOrder hủy phân công tài xế của đơn hàng
+func (s *fleetService) UnassignOrder(ctx context.Context, orderID, reason string) error {
+	return s.executeOrder(ctx, orderID, func(order *domain.Order) error {
+		if err := order.Unassign(reason); err != nil {
+			return fmt.Errorf("không thể hủy phân công: %w", err)
+		}
+		return nil
+	})
+}
+
+// executeOrder xây dựng lại đơn hàng, áp dụng lệnh rồi lưu và phát các sự kiện mới
+func (s *fleetService) executeOrder(ctx context.Context, orderID string, command func(order *domain.Order) error) error {
+	events, err := s.eventStore.GetEvents(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("không tìm thấy đơn hàng")
+	}
+
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
+	if order == nil {
+		return fmt.Errorf("không thể xây dựng lại đơn hàng từ sự kiện")
+	}
+
+	if err := command(order); err != nil {
+		return err
+	}
+
+	if err := s.commit(ctx, order.ID, order.GetUncommittedEvents()); err != nil {
+		return err
+	}
+	order.ClearUncommittedEvents()
+
+	return nil
+}
+
+// DriverOrders lấy tài xế, phương tiện đang dùng và các đơn hàng được phân công
+func (s *fleetService) DriverOrders(ctx context.Context, driverID string, status domain.OrderStatus) (*DriverOrders, error) {
+	driver, err := s.GetDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DriverOrders{Driver: driver, LoadKg: decimal.Zero}
+	if driver.VehicleID != "" {
+		if result.Vehicle, err = s.GetVehicle(ctx, driver.VehicleID); err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := s.repository.ListByDriver(ctx, driverID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result.Orders = make([]*domain.Order, 0, len(orders))
+	for _, order := range orders {
+		if order.CountsTowardsLoad() {
+			result.LoadKg = result.LoadKg.Add(order.Totals.TotalWeight)
+		}
+		if status == "" || order.Status == status {
+			result.Orders = append(result.Orders, order)
+		}
+	}
+
+	return result, nil
+}
+
+// commit lưu các sự kiện mới của một aggregate rồi phát lên event bus
+func (s *fleetService) commit(ctx context.Context, aggregateID string, events []domain.Event) error {
+	if err := s.eventStore.SaveEvents(ctx, aggregateID, events); err != nil {
+		return fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+	}
+
+	for _, event := range events {
+		if err := s.eventBus.Publish(event); err != nil {
+			fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+// registerDriver đăng ký tài xế có phương tiện với tải trọng capacityKg, trả về ID tài xế
+func registerDriver(t *testing.T, fleet FleetService, capacityKg decimal.Decimal) string {
+	t.Helper()
+	ctx := context.Background()
+
+	driverID, err := fleet.RegisterDriver(ctx, "Nguyễn Văn A")
+	if err != nil {
+		t.Fatalf("RegisterDriver: %v", err)
+	}
+	vehicleID, err := fleet.RegisterVehicle(ctx, "51C-123.45", capacityKg)
+	if err != nil {
+		t.Fatalf("RegisterVehicle: %v", err)
+	}
+	if err := fleet.AssignVehicle(ctx, driverID, vehicleID); err != nil {
+		t.Fatalf("AssignVehicle: %v", err)
+	}
+	return driverID
+}
+
+func TestFleetServiceAssignment(t *testing.T) {
+	ctx := context.Background()
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.EventTypesOf(domain.AggregateOrder)...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := domaintest.Options()
+	orders := NewOrderService(store, repo, bus, opts...)
+	fleet := NewFleetService(store, repo, bus, opts...)
+
+	driverID := registerDriver(t, fleet, decimal.NewFromInt(10))
+	items := []domain.OrderItem{{ID: "ITEM-1", Quantity: 2, Weight: decimal.NewFromInt(2)}}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, items, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// Chưa phân công thì không thể đưa đi giao
+	if err := orders.UpdateOrderStatus(ctx, ids[0], domain.OrderStatusOutForDelivery, nil, ""); err == nil {
+		t.Fatalf("UpdateOrderStatus OUT_FOR_DELIVERY khi chưa phân công phải lỗi")
+	}
+
+	for _, id := range ids[:2] {
+		if err := fleet.AssignOrder(ctx, id, driverID); err != nil {
+			t.Fatalf("AssignOrder %s: %v", id, err)
+		}
+	}
+	// Hai đơn hàng 4 kg, thêm 4 kg nữa vượt tải trọng 10 kg
+	if err := fleet.AssignOrder(ctx, ids[2], driverID); err == nil || !strings.Contains(err.Error(), "vượt tải trọng") {
+		t.Fatalf("AssignOrder vượt tải trọng: err = %v", err)
+	}
+	if err := orders.UpdateOrderStatus(ctx, ids[0], domain.OrderStatusOutForDelivery, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+
+	// Đơn hàng đã giao không còn tính vào tải trọng
+	if err := orders.UpdateOrderStatus(ctx, ids[0], domain.OrderStatusDelivered, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	if err := fleet.AssignOrder(ctx, ids[2], driverID); err != nil {
+		t.Fatalf("AssignOrder sau khi giao: %v", err)
+	}
+
+	assigned, err := fleet.DriverOrders(ctx, driverID, "")
+	if err != nil {
+		t.Fatalf("DriverOrders: %v", err)
+	}
+	if len(assigned.Orders) != 3 || !assigned.LoadKg.Equal(decimal.NewFromInt(8)) || assigned.Vehicle == nil {
+		t.Fatalf("DriverOrders = %+v", assigned)
+	}
+	delivered, err := fleet.DriverOrders(ctx, driverID, domain.OrderStatusDelivered)
+	if err != nil {
+		t.Fatalf("DriverOrders: %v", err)
+	}
+	if len(delivered.Orders) != 1 || delivered.Orders[0].ID != ids[0] || !delivered.LoadKg.Equal(assigned.LoadKg) {
+		t.Fatalf("DriverOrders DELIVERED = %+v", delivered)
+	}
+
+	// Hủy phân công trả lại tải trọng, đơn hàng đã giao không thể hủy phân công
+	if err := fleet.UnassignOrder(ctx, ids[2], "Đổi tuyến"); err != nil {
+		t.Fatalf("UnassignOrder: %v", err)
+	}
+	if err := fleet.UnassignOrder(ctx, ids[0], ""); err == nil {
+		t.Fatalf("UnassignOrder đơn hàng đã giao phải lỗi")
+	}
+	order, err := orders.GetOrder(ctx, ids[2])
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.DriverID != "" || order.VehicleID != "" || order.AssignedAt != nil {
+		t.Fatalf("đơn hàng vẫn còn phân công: %+v", order)
+	}
+
+	// Tài xế ngừng hoạt động không nhận đơn hàng mới
+	if err := fleet.DeactivateDriver(ctx, driverID, "Nghỉ phép"); err != nil {
+		t.Fatalf("DeactivateDriver: %v", err)
+	}
+	if err := fleet.AssignOrder(ctx, ids[2], driverID); err == nil {
+		t.Fatalf("AssignOrder cho tài xế ngừng hoạt động phải lỗi")
+	}
+	if err := fleet.ActivateDriver(ctx, driverID); err != nil {
+		t.Fatalf("ActivateDriver: %v", err)
+	}
+
+	// Phương tiện ngừng sử dụng thì tài xế không nhận đơn hàng mới
+	if err := fleet.RetireVehicle(ctx, assigned.Vehicle.ID, "Hết niên hạn"); err != nil {
+		t.Fatalf("RetireVehicle: %v", err)
+	}
+	if err := fleet.AssignOrder(ctx, ids[2], driverID); err == nil || !strings.Contains(err.Error(), "ngừng sử dụng") {
+		t.Fatalf("AssignOrder với phương tiện ngừng sử dụng: err = %v", err)
+	}
+
+	if _, err := fleet.GetDriver(ctx, ids[0]); err == nil {
+		t.Fatalf("GetDriver với ID đơn hàng phải lỗi")
+	}
+	if _, err := fleet.GetVehicle(ctx, driverID); err == nil {
+		t.Fatalf("GetVehicle với ID tài xế phải lỗi")
+	}
+}
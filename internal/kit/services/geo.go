@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"net"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/pkgs/geoip"
+)
+
+// RequestSource là nguồn của request HTTP, được ghi vào metadata sự kiện để phục vụ kiểm tra gian lận
+type RequestSource struct {
+	IP         string // IP của client, lấy từ X-Forwarded-For hoặc X-Real-IP nếu request đi qua proxy tin cậy
+	RemoteAddr string // IP kết nối trực tiếp tới server, có thể là proxy
+}
+
+type requestSourceKey struct{}
+
+// WithRequestSource gắn nguồn request vào ctx
+func WithRequestSource(ctx context.Context, source RequestSource) context.Context {
+	return context.WithValue(ctx, requestSourceKey{}, source)
+}
+
+// requestSource lấy nguồn request đã gắn vào ctx
+func requestSource(ctx context.Context) (RequestSource, bool) {
+	source, ok := ctx.Value(requestSourceKey{}).(RequestSource)
+	return source, ok && source.IP != ""
+}
+
+// IPLocator tra cứu vị trí theo địa chỉ IP, *geoip.Reader triển khai interface này
+type IPLocator interface {
+	LookupIP(ip net.IP) (geoip.Place, bool)
+}
+
+// geoOrderService bổ sung tọa độ còn thiếu cho vị trí và ghi nguồn request vào metadata sự kiện
+type geoOrderService struct {
+	OrderService
+	ips IPLocator // nil khi không có cơ sở dữ liệu GeoIP
+}
+
+// NewGeoOrderService bọc OrderService:
+//   - vị trí có tên thành phố nhưng thiếu tọa độ được tra trong bảng thành phố đi kèm
+//   - điểm gửi không có cả thành phố lẫn tọa độ lấy vị trí của IP request (cần ips)
+//   - sự kiện do lệnh tạo ra mang metadata ip, country, city của request
+func NewGeoOrderService(next OrderService, ips IPLocator) OrderService {
+	return &geoOrderService{
+		OrderService: next,
+		ips:          ips,
+	}
+}
+
+// CreateOrder tạo đơn hàng sau khi bổ sung vị trí điểm gửi và điểm nhận
+func (s *geoOrderService) CreateOrder(
+	ctx context.Context,
+	customerID string,
+	origin, destination domain.Location,
+	items []domain.OrderItem,
+	serviceLevel domain.ServiceLevel,
+	payment domain.PaymentTerms,
+) (string, string, error) {
+	ctx, place := s.withRequestMetadata(ctx)
+
+	// Vị trí của IP là nơi người bán gửi request nên chỉ dùng cho điểm gửi
+	origin = enrichLocation(origin, place)
+	destination = enrichLocation(destination, nil)
+
+	return s.OrderService.CreateOrder(ctx, customerID, origin, destination, items, serviceLevel, payment)
+}
+
+// UpdateOrderStatus cập nhật trạng thái sau khi bổ sung tọa độ cho vị trí hiện tại
+func (s *geoOrderService) UpdateOrderStatus(
+	ctx context.Context,
+	orderID string,
+	newStatus domain.OrderStatus,
+	location *domain.Location,
+	note string,
+) error {
+	ctx, _ = s.withRequestMetadata(ctx)
+	if location != nil {
+		enriched := enrichLocation(*location, nil)
+		location = &enriched
+	}
+
+	return s.OrderService.UpdateOrderStatus(ctx, orderID, newStatus, location, note)
+}
+
+// UpdateOrderStatusBatch cập nhật trạng thái nhiều đơn hàng sau khi bổ sung tọa độ cho từng vị trí
+func (s *geoOrderService) UpdateOrderStatusBatch(ctx context.Context, updates []StatusUpdate) ([]StatusUpdateResult, error) {
+	ctx, _ = s.withRequestMetadata(ctx)
+	enriched := make([]StatusUpdate, len(updates))
+	for i, update := range updates {
+		if update.Location != nil {
+			location := enrichLocation(*update.Location, nil)
+			update.Location = &location
+		}
+		enriched[i] = update
+	}
+
+	return s.OrderService.UpdateOrderStatusBatch(ctx, enriched)
+}
+
+// CancelOrder hủy đơn hàng, ghi nguồn request vào metadata
+func (s *geoOrderService) CancelOrder(ctx context.Context, orderID string, reason string) error {
+	ctx, _ = s.withRequestMetadata(ctx)
+	return s.OrderService.CancelOrder(ctx, orderID, reason)
+}
+
+// AddOrderNote thêm ghi chú, ghi nguồn request vào metadata
+func (s *geoOrderService) AddOrderNote(ctx context.Context, orderID string, note string) error {
+	ctx, _ = s.withRequestMetadata(ctx)
+	return s.OrderService.AddOrderNote(ctx, orderID, note)
+}
+
+// withRequestMetadata gắn IP của request cùng quốc gia, thành phố tra được vào metadata sự kiện,
+// trả về vị trí của IP nếu tra được
+func (s *geoOrderService) withRequestMetadata(ctx context.Context) (context.Context, *geoip.Place) {
+	source, ok := requestSource(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	metadata := eventstore.Metadata{"ip": source.IP}
+	if source.RemoteAddr != "" && source.RemoteAddr != source.IP {
+		metadata["remote_addr"] = source.RemoteAddr
+	}
+
+	var place *geoip.Place
+	if s.ips != nil {
+		if found, ok := s.ips.LookupIP(net.ParseIP(source.IP)); ok {
+			place = &found
+			metadata["country"] = found.Country
+			if found.City != "" {
+				metadata["city"] = found.City
+			}
+		}
+	}
+
+	return eventstore.WithMetadata(ctx, metadata), place
+}
+
+// enrichLocation bổ sung tọa độ cho vị trí chưa có: theo tên thành phố nếu có,
+// ngược lại theo fallback (vị trí của IP). Vị trí đã có tọa độ được giữ nguyên.
+func enrichLocation(location domain.Location, fallback *geoip.Place) domain.Location {
+	if location.HasCoordinates() {
+		return location
+	}
+
+	if location.City != "" {
+		if place, ok := geoip.LookupCity(location.City); ok {
+			location.Latitude, location.Longitude = place.Latitude, place.Longitude
+		}
+		return location
+	}
+
+	if fallback != nil && fallback.HasCoordinates() {
+		location.City = fallback.City
+		location.Latitude, location.Longitude = fallback.Latitude, fallback.Longitude
+	}
+	return location
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geoip"
+)
+
+func TestGeoOrderServiceEnrichesLocationsAndMetadata(t *testing.T) {
+	ips, err := geoip.Open("../../../pkgs/geoip/testdata/GeoIP2-City-Test.mmdb")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer ips.Close()
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	orders := NewGeoOrderService(NewOrderService(store, repo, bus,
+		domain.WithClock(domain.FixedClock(domaintest.Now)),
+		domain.WithIDGenerator(&domaintest.SequenceIDs{}),
+	), ips)
+
+	ctx := WithRequestSource(context.Background(), RequestSource{IP: "203.0.113.10", RemoteAddr: "10.0.0.1"})
+	items := []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}}
+
+	// Điểm gửi không có thông tin lấy vị trí của IP, điểm nhận chỉ có tên thành phố tra theo bảng
+	id, _, err := orders.CreateOrder(ctx, "CUS-001",
+		domain.Location{Address: "Kho Quận 1"},
+		domain.Location{Address: "12 Tràng Tiền", City: "TP. Hà Nội"},
+		items, domain.ServiceLevelStandard, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	order, err := orders.GetOrder(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.Origin.City != "Thành phố Hồ Chí Minh" || order.Origin.Latitude != 10.8231 || order.Origin.Longitude != 106.6297 {
+		t.Fatalf("origin = %+v, muốn vị trí của IP", order.Origin)
+	}
+	if order.Destination.City != "TP. Hà Nội" || !order.Destination.HasCoordinates() {
+		t.Fatalf("destination = %+v, muốn tọa độ theo bảng thành phố", order.Destination)
+	}
+
+	// Vị trí đã có tọa độ được giữ nguyên
+	current := domain.Location{City: "Đà Nẵng", Latitude: 1, Longitude: 2}
+	if err := orders.UpdateOrderStatus(ctx, id, domain.OrderStatusInTransit, &current, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	order, err = orders.GetOrder(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.CurrentLocation == nil || order.CurrentLocation.Latitude != 1 || order.CurrentLocation.Longitude != 2 {
+		t.Fatalf("current location = %+v, muốn giữ nguyên tọa độ", order.CurrentLocation)
+	}
+
+	events, err := orders.GetOrderHistory(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrderHistory: %v", err)
+	}
+	metadata, err := orders.GetOrderMetadata(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrderMetadata: %v", err)
+	}
+	for _, event := range events {
+		md := metadata[event.GetID()]
+		if md["ip"] != "203.0.113.10" || md["remote_addr"] != "10.0.0.1" || md["country"] != "VN" || md["city"] != "Thành phố Hồ Chí Minh" {
+			t.Fatalf("metadata của %s = %v", event.GetType(), md)
+		}
+	}
+}
+
+func TestGeoOrderServiceWithoutRequestSource(t *testing.T) {
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	// Không có cơ sở dữ liệu GeoIP và request (chẳng hạn CLI): chỉ dùng bảng thành phố, không ghi metadata
+	orders := NewGeoOrderService(NewOrderService(store, repo, bus,
+		domain.WithClock(domain.FixedClock(domaintest.Now)),
+		domain.WithIDGenerator(&domaintest.SequenceIDs{}),
+	), nil)
+
+	ctx := context.Background()
+	id, _, err := orders.CreateOrder(ctx, "CUS-001",
+		domain.Location{Address: "Kho"},
+		domain.Location{City: "Thành phố không tồn tại"},
+		[]domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}}, domain.ServiceLevelStandard, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	order, err := orders.GetOrder(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.Origin.HasCoordinates() || order.Destination.HasCoordinates() {
+		t.Fatalf("origin = %+v, destination = %+v, muốn không có tọa độ", order.Origin, order.Destination)
+	}
+
+	metadata, err := orders.GetOrderMetadata(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrderMetadata: %v", err)
+	}
+	if len(metadata) != 0 {
+		t.Fatalf("metadata = %v, muốn rỗng", metadata)
+	}
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/uptrace/bun"
+)
+
+// hubCodePattern là định dạng mã hub sau khi chuẩn hóa, ví dụ HCM-01
+var hubCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{0,19}$`)
+
+// hubHoursLayout là định dạng giờ mở cửa và đóng cửa của hub
+const hubHoursLayout = "15:04"
+
+// HubUpdate là các thông tin cập nhật của hub, trường nil giữ nguyên
+type HubUpdate struct {
+	Name              *string
+	Latitude          *float64
+	Longitude         *float64
+	OpensAt           *string
+	ClosesAt          *string
+	DwellLimitMinutes *int
+	Status            *models.ModelStatus
+}
+
+// HubStay là một đơn hàng đang nằm tại hub cùng thời gian đã nằm
+type HubStay struct {
+	projection.HubParcel
+	Dwell   time.Duration
+	Limit   time.Duration // giới hạn thời gian nằm tại hub
+	Overdue bool          // đã nằm quá giới hạn
+}
+
+// HubService quản lý hub và ghi nhận đơn hàng quét vào, quét ra khỏi hub
+type HubService interface {
+	ListHubs(ctx context.Context) ([]models.Hub, error)
+	GetHub(ctx context.Context, code string) (*models.Hub, error)
+	CreateHub(ctx context.Context, hub models.Hub) (*models.Hub, error)
+	UpdateHub(ctx context.Context, code string, update HubUpdate) (*models.Hub, error)
+
+	// ScanIn ghi nhận đơn hàng được quét vào hub đang hoạt động
+	ScanIn(ctx context.Context, hubCode, orderID string) error
+
+	// ScanOut ghi nhận đơn hàng được quét ra khỏi hub
+	ScanOut(ctx context.Context, hubCode, orderID string) error
+
+	// Inventory lấy các đơn hàng đang nằm tại hub, nằm lâu nhất trước
+	Inventory(ctx context.Context, hubCode string) ([]HubStay, error)
+
+	// DwellAlerts lấy các đơn hàng nằm tại hub quá giới hạn của hub, hubCode rỗng để lấy trên mọi hub
+	DwellAlerts(ctx context.Context, hubCode string) ([]HubStay, error)
+}
+
+type hubService struct {
+	db         *bun.DB
+	eventStore eventstore.EventStore
+	eventBus   eventbus.EventBus
+	inventory  projection.HubInventoryProjection
+	clock      domain.Clock
+	orderOpts  []domain.OrderOption
+}
+
+// NewHubService tạo service hub, orderOpts giống như của OrderService
+func NewHubService(
+	db *bun.DB,
+	eventStore eventstore.EventStore,
+	eventBus eventbus.EventBus,
+	inventory projection.HubInventoryProjection,
+	clock domain.Clock,
+	orderOpts ...domain.OrderOption,
+) HubService {
+	return &hubService{
+		db:         db,
+		eventStore: eventStore,
+		eventBus:   eventBus,
+		inventory:  inventory,
+		clock:      clock,
+		orderOpts:  orderOpts,
+	}
+}
+
+// ListHubs lấy tất cả hub theo mã
+func (s *hubService) ListHubs(ctx context.Context) ([]models.Hub, error) {
+	hubs := make([]models.Hub, 0)
+	if err := s.db.NewSelect().Model(&hubs).Order("hub.code ASC").Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn hub: %w", err)
+	}
+	return hubs, nil
+}
+
+// GetHub lấy hub theo mã, không phân biệt hoa thường
+func (s *hubService) GetHub(ctx context.Context, code string) (*models.Hub, error) {
+	hub := &models.Hub{}
+	err := s.db.NewSelect().
+		Model(hub).
+		Where("hub.code = ?", normalizeHubCode(code)).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("hub %s: %w", code, ErrRecordNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn hub: %w", err)
+	}
+	return hub, nil
+}
+
+// CreateHub tạo hub đang hoạt động, mã hub được chuẩn hóa thành chữ hoa
+func (s *hubService) CreateHub(ctx context.Context, hub models.Hub) (*models.Hub, error) {
+	hub.Code = normalizeHubCode(hub.Code)
+	hub.Name = strings.TrimSpace(hub.Name)
+	if !hubCodePattern.MatchString(hub.Code) {
+		return nil, fmt.Errorf("mã hub không hợp lệ: %q", hub.Code)
+	}
+	if err := validateHub(&hub); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetHub(ctx, hub.Code); err == nil {
+		return nil, fmt.Errorf("hub %s đã tồn tại", hub.Code)
+	} else if !errors.Is(err, ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	hub.Status = models.MSActive
+	hub.CreatedTime = now
+	hub.UpdatedTime = now
+	if _, err := s.db.NewInsert().Model(&hub).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi tạo hub: %w", err)
+	}
+	return &hub, nil
+}
+
+// UpdateHub cập nhật thông tin của hub, mã hub không thay đổi được vì đã được dùng trong các sự kiện quét
+func (s *hubService) UpdateHub(ctx context.Context, code string, update HubUpdate) (*models.Hub, error) {
+	hub, err := s.GetHub(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		hub.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Latitude != nil {
+		hub.Latitude = *update.Latitude
+	}
+	if update.Longitude != nil {
+		hub.Longitude = *update.Longitude
+	}
+	if update.OpensAt != nil {
+		hub.OpensAt = *update.OpensAt
+	}
+	if update.ClosesAt != nil {
+		hub.ClosesAt = *update.ClosesAt
+	}
+	if update.DwellLimitMinutes != nil {
+		hub.DwellLimitMinutes = *update.DwellLimitMinutes
+	}
+	if update.Status != nil {
+		if *update.Status != models.MSActive && *update.Status != models.MSDeActive {
+			return nil, fmt.Errorf("trạng thái hub không hợp lệ: %d", *update.Status)
+		}
+		hub.Status = *update.Status
+	}
+	if err := validateHub(hub); err != nil {
+		return nil, err
+	}
+	hub.UpdatedTime = s.clock.Now()
+
+	if _, err := s.db.NewUpdate().Model(hub).WherePK().Exec(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi cập nhật hub: %w", err)
+	}
+	return hub, nil
+}
+
+// ScanIn ghi nhận đơn hàng được quét vào hub, vị trí hiện tại của đơn hàng là vị trí của hub
+func (s *hubService) ScanIn(ctx context.Context, hubCode, orderID string) error {
+	hub, err := s.GetHub(ctx, hubCode)
+	if err != nil {
+		return err
+	}
+	if hub.Status != models.MSActive {
+		return fmt.Errorf("hub %s đã ngừng hoạt động", hub.Code)
+	}
+
+	return s.executeOrder(ctx, orderID, func(order *domain.Order) error {
+		return order.ScanIn(hub.Code, hub.Name, hub.Point())
+	})
+}
+
+// ScanOut ghi nhận đơn hàng được quét ra khỏi hub, kể cả hub đã ngừng hoạt động
+func (s *hubService) ScanOut(ctx context.Context, hubCode, orderID string) error {
+	hub, err := s.GetHub(ctx, hubCode)
+	if err != nil {
+		return err
+	}
+
+	return s.executeOrder(ctx, orderID, func(order *domain.Order) error {
+		return order.ScanOut(hub.Code)
+	})
+}
+
+// executeOrder xây dựng lại đơn hàng, thực hiện command rồi lưu và phát các sự kiện mới
+func (s *hubService) executeOrder(ctx context.Context, orderID string, command func(order *domain.Order) error) error {
+	events, err := s.eventStore.GetEvents(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
+	if order == nil {
+		return fmt.Errorf("không tìm thấy đơn hàng")
+	}
+
+	if err := command(order); err != nil {
+		return err
+	}
+
+	if err := s.eventStore.SaveEvents(ctx, order.ID, order.GetUncommittedEvents()); err != nil {
+		return fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+	}
+
+	for _, event := range order.GetUncommittedEvents() {
+		if err := s.eventBus.Publish(event); err != nil {
+			fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+	order.ClearUncommittedEvents()
+
+	return nil
+}
+
+// Inventory lấy các đơn hàng đang nằm tại hub, nằm lâu nhất trước
+func (s *hubService) Inventory(ctx context.Context, hubCode string) ([]HubStay, error) {
+	hub, err := s.GetHub(ctx, hubCode)
+	if err != nil {
+		return nil, err
+	}
+
+	parcels, err := s.inventory.Parcels(ctx, projection.HubParcelFilter{HubCode: hub.Code})
+	if err != nil {
+		return nil, err
+	}
+	return s.stays(parcels, hub.DwellLimit()), nil
+}
+
+// DwellAlerts lấy các đơn hàng nằm quá giới hạn của hub đang nằm tại, nằm lâu nhất trước.
+// Giới hạn khác nhau giữa các hub nên mỗi hub được truy vấn riêng.
+func (s *hubService) DwellAlerts(ctx context.Context, hubCode string) ([]HubStay, error) {
+	var hubs []models.Hub
+	if hubCode != "" {
+		hub, err := s.GetHub(ctx, hubCode)
+		if err != nil {
+			return nil, err
+		}
+		hubs = []models.Hub{*hub}
+	} else {
+		var err error
+		if hubs, err = s.ListHubs(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.clock.Now()
+	alerts := make([]HubStay, 0)
+	for _, hub := range hubs {
+		parcels, err := s.inventory.Parcels(ctx, projection.HubParcelFilter{
+			HubCode:       hub.Code,
+			ScannedBefore: now.Add(-hub.DwellLimit()),
+		})
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, s.stays(parcels, hub.DwellLimit())...)
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].ScannedInAt.Before(alerts[j].ScannedInAt)
+	})
+	return alerts, nil
+}
+
+// stays tính thời gian đã nằm tại hub của các đơn hàng theo đồng hồ của service
+func (s *hubService) stays(parcels []projection.HubParcel, limit time.Duration) []HubStay {
+	now := s.clock.Now()
+	stays := make([]HubStay, len(parcels))
+	for i, parcel := range parcels {
+		dwell := now.Sub(parcel.ScannedInAt)
+		stays[i] = HubStay{HubParcel: parcel, Dwell: dwell, Limit: limit, Overdue: dwell > limit}
+	}
+	return stays
+}
+
+func normalizeHubCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validateHub kiểm tra tên, tọa độ, giờ hoạt động và giới hạn thời gian nằm tại hub
+func validateHub(hub *models.Hub) error {
+	if hub.Name == "" {
+		return errors.New("tên hub không được để trống")
+	}
+	if err := validateCoordinates(hub.Latitude, hub.Longitude); err != nil {
+		return err
+	}
+	if hub.Point().IsZero() {
+		return errors.New("hub phải có tọa độ")
+	}
+	if (hub.OpensAt == "") != (hub.ClosesAt == "") {
+		return errors.New("cần cả giờ mở cửa và giờ đóng cửa, hoặc bỏ trống cả hai")
+	}
+	if hub.OpensAt != "" {
+		opens, err := time.Parse(hubHoursLayout, hub.OpensAt)
+		if err != nil {
+			return fmt.Errorf("giờ mở cửa không hợp lệ, cần định dạng HH:MM: %q", hub.OpensAt)
+		}
+		closes, err := time.Parse(hubHoursLayout, hub.ClosesAt)
+		if err != nil {
+			return fmt.Errorf("giờ đóng cửa không hợp lệ, cần định dạng HH:MM: %q", hub.ClosesAt)
+		}
+		if opens.Equal(closes) {
+			return errors.New("giờ mở cửa và giờ đóng cửa phải khác nhau, bỏ trống nếu hub hoạt động cả ngày")
+		}
+		hub.OpensAt, hub.ClosesAt = opens.Format(hubHoursLayout), closes.Format(hubHoursLayout)
+	}
+	if hub.DwellLimitMinutes < 0 {
+		return errors.New("giới hạn thời gian nằm tại hub không được âm")
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestHubServiceRegistry(t *testing.T) {
+	ctx := context.Background()
+	s := NewHubService(newHubDB(t), nil, nil, projection.NewInMemoryHubInventoryProjection(), domain.SystemClock)
+
+	hub, err := s.CreateHub(ctx, models.Hub{Code: " hcm-01 ", Name: " Hub Thủ Đức ", Latitude: 10.8231, Longitude: 106.6297, OpensAt: "6:00", ClosesAt: "22:00"})
+	if err != nil {
+		t.Fatalf("CreateHub: %v", err)
+	}
+	if hub.Code != "HCM-01" || hub.Name != "Hub Thủ Đức" || hub.OpensAt != "06:00" || hub.Status != models.MSActive || hub.DwellLimit() != models.DefaultHubDwellLimit {
+		t.Fatalf("hub = %+v", hub)
+	}
+
+	for _, invalid := range []models.Hub{
+		{Code: "HCM-01", Name: "Trùng mã", Latitude: 10, Longitude: 106},
+		{Code: "HCM 02", Name: "Mã có khoảng trắng", Latitude: 10, Longitude: 106},
+		{Code: "HCM-02", Name: "Thiếu tọa độ"},
+		{Code: "HCM-02", Name: "Thiếu giờ đóng cửa", Latitude: 10, Longitude: 106, OpensAt: "06:00"},
+		{Code: "HCM-02", Name: "Giờ sai", Latitude: 10, Longitude: 106, OpensAt: "25:00", ClosesAt: "06:00"},
+	} {
+		if _, err := s.CreateHub(ctx, invalid); err == nil {
+			t.Fatalf("CreateHub(%s) phải lỗi", invalid.Name)
+		}
+	}
+
+	// Hub hoạt động qua đêm và giới hạn riêng
+	opens, closes, limit := "22:00", "06:00", 90
+	hub, err = s.UpdateHub(ctx, "hcm-01", HubUpdate{OpensAt: &opens, ClosesAt: &closes, DwellLimitMinutes: &limit})
+	if err != nil {
+		t.Fatalf("UpdateHub: %v", err)
+	}
+	if hub.Name != "Hub Thủ Đức" || hub.OpensAt != "22:00" || hub.DwellLimit() != 90*time.Minute {
+		t.Fatalf("hub = %+v", hub)
+	}
+
+	hubs, err := s.ListHubs(ctx)
+	if err != nil {
+		t.Fatalf("ListHubs: %v", err)
+	}
+	if len(hubs) != 1 || hubs[0].ClosesAt != "06:00" || hubs[0].DwellLimitMinutes != 90 {
+		t.Fatalf("ListHubs = %+v", hubs)
+	}
+}
+
+func TestHubServiceScans(t *testing.T) {
+	ctx := context.Background()
+	now := domaintest.Now
+	clock := domain.ClockFunc(func() time.Time { return now })
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	inventory := projection.NewInMemoryHubInventoryProjection()
+	bus := eventbus.NewInMemoryEventBus()
+	for _, handler := range []eventbus.EventHandler{repo, inventory} {
+		if err := bus.Subscribe(handler, domain.EventTypesOf(domain.AggregateOrder)...); err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+	}
+	opts := []domain.OrderOption{domain.WithClock(clock), domain.WithIDGenerator(&domaintest.SequenceIDs{})}
+	orders := NewOrderService(store, repo, bus, opts...)
+	hubs := NewHubService(newHubDB(t), store, bus, inventory, clock, opts...)
+
+	limit := 120
+	for _, hub := range []models.Hub{
+		{Code: "HCM-01", Name: "Hub Thủ Đức", Latitude: 10.8231, Longitude: 106.6297, DwellLimitMinutes: limit},
+		{Code: "HN-01", Name: "Hub Long Biên", Latitude: 21.0285, Longitude: 105.8542},
+	} {
+		if _, err := hubs.CreateHub(ctx, hub); err != nil {
+			t.Fatalf("CreateHub: %v", err)
+		}
+	}
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, []domain.OrderItem{{ID: "ITEM-1", Quantity: 1}}, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		if err := hubs.ScanIn(ctx, "hcm-01", id); err != nil {
+			t.Fatalf("ScanIn: %v", err)
+		}
+		ids = append(ids, id)
+		now = now.Add(time.Hour)
+	}
+
+	if err := hubs.ScanIn(ctx, "HN-01", ids[0]); err == nil || !strings.Contains(err.Error(), "cần quét ra trước") {
+		t.Fatalf("ScanIn khi đang ở hub khác = %v", err)
+	}
+	if err := hubs.ScanIn(ctx, "DN-01", ids[0]); err == nil {
+		t.Fatalf("ScanIn vào hub không tồn tại phải lỗi")
+	}
+
+	order, err := orders.GetOrder(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.CurrentLocation == nil || order.CurrentLocation.Latitude != 10.8231 {
+		t.Fatalf("CurrentLocation = %+v", order.CurrentLocation)
+	}
+
+	stays, err := hubs.Inventory(ctx, "HCM-01")
+	if err != nil {
+		t.Fatalf("Inventory: %v", err)
+	}
+	if len(stays) != 2 || stays[0].OrderID != ids[0] || stays[0].Dwell != 2*time.Hour || stays[0].Overdue || stays[1].Dwell != time.Hour {
+		t.Fatalf("Inventory = %+v", stays)
+	}
+
+	// Quá 2 giờ thì đơn hàng đầu tiên bị cảnh báo
+	now = now.Add(30 * time.Minute)
+	alerts, err := hubs.DwellAlerts(ctx, "")
+	if err != nil {
+		t.Fatalf("DwellAlerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].OrderID != ids[0] || !alerts[0].Overdue || alerts[0].Limit != 2*time.Hour {
+		t.Fatalf("DwellAlerts = %+v", alerts)
+	}
+
+	// Quét ra rồi quét vào hub khác, hub cũ ngừng hoạt động vẫn cho quét ra
+	inactive := models.MSDeActive
+	if _, err := hubs.UpdateHub(ctx, "HCM-01", HubUpdate{Status: &inactive}); err != nil {
+		t.Fatalf("UpdateHub: %v", err)
+	}
+	if err := hubs.ScanIn(ctx, "HCM-01", ids[0]); err == nil || !strings.Contains(err.Error(), "ngừng hoạt động") {
+		t.Fatalf("ScanIn vào hub ngừng hoạt động = %v", err)
+	}
+	if err := hubs.ScanOut(ctx, "HCM-01", ids[0]); err != nil {
+		t.Fatalf("ScanOut: %v", err)
+	}
+	if err := hubs.ScanIn(ctx, "HN-01", ids[0]); err != nil {
+		t.Fatalf("ScanIn: %v", err)
+	}
+
+	if alerts, err := hubs.DwellAlerts(ctx, "HCM-01"); err != nil || len(alerts) != 0 {
+		t.Fatalf("DwellAlerts sau khi quét ra = %+v, %v", alerts, err)
+	}
+	stays, err = hubs.Inventory(ctx, "HN-01")
+	if err != nil {
+		t.Fatalf("Inventory: %v", err)
+	}
+	if len(stays) != 1 || stays[0].OrderID != ids[0] || stays[0].Dwell != 0 {
+		t.Fatalf("Inventory HN-01 = %+v", stays)
+	}
+}
+
+func TestHubServiceScansPersistCurrentHub(t *testing.T) {
+	ctx := context.Background()
+	db := newHubDB(t)
+	for _, migration := range []interface{ Up(db *bun.DB) error }{
+		migrations.ProjectionsTable{}, migrations.OrdersDeliveryEstimate{}, migrations.OrdersPosition{},
+		migrations.OrdersTotals{}, migrations.OrdersPayment{}, migrations.OrdersDriver{}, migrations.OrdersCurrentHub{},
+	} {
+		if err := migration.Up(db); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+	}
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewOrderRepository(db)
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.EventTypesOf(domain.AggregateOrder)...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := domaintest.Options()
+	orders := NewOrderService(store, repo, bus, opts...)
+	hubs := NewHubService(db, store, bus, projection.NewInMemoryHubInventoryProjection(), domain.SystemClock, opts...)
+	if _, err := hubs.CreateHub(ctx, models.Hub{Code: "HCM-01", Name: "Hub Thủ Đức", Latitude: 10.8231, Longitude: 106.6297}); err != nil {
+		t.Fatalf("CreateHub: %v", err)
+	}
+
+	id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, []domain.OrderItem{{ID: "ITEM-1", Quantity: 1}}, "", domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if err := hubs.ScanIn(ctx, "HCM-01", id); err != nil {
+		t.Fatalf("ScanIn: %v", err)
+	}
+
+	order, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	want := domain.Location{Address: "Hub Thủ Đức", Latitude: 10.8231, Longitude: 106.6297}
+	if order.CurrentHub != "HCM-01" || order.CurrentLocation == nil || *order.CurrentLocation != want {
+		t.Fatalf("CurrentHub = %q, CurrentLocation = %+v", order.CurrentHub, order.CurrentLocation)
+	}
+
+	if err := hubs.ScanOut(ctx, "HCM-01", id); err != nil {
+		t.Fatalf("ScanOut: %v", err)
+	}
+	if order, err := repo.GetByID(ctx, id); err != nil || order.CurrentHub != "" {
+		t.Fatalf("đơn hàng sau khi quét ra = %+v, %v", order, err)
+	}
+}
+
+// newHubDB tạo cơ sở dữ liệu SQLite trong bộ nhớ đã có bảng hub
+func newHubDB(t *testing.T) *bun.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { db.Close() })
+
+	if err := (migrations.HubTables{}).Up(db); err != nil {
+		t.Fatalf("HubTables.Up: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,537 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+// Các định dạng file nhập đơn hàng được hỗ trợ
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+const (
+	// importBatchSize là số đơn hàng được lưu trong mỗi transaction
+	importBatchSize = 500
+
+	// importJobRetention là thời gian giữ kết quả của job đã kết thúc để truy vấn
+	importJobRetention = 24 * time.Hour
+
+	// maxImportLineSize là độ dài tối đa của một dòng JSONL
+	maxImportLineSize = 1 << 20
+)
+
+// ImportJobStatus là trạng thái của job nhập đơn hàng
+type ImportJobStatus string
+
+const (
+	ImportJobPending   ImportJobStatus = "PENDING"
+	ImportJobRunning   ImportJobStatus = "RUNNING"
+	ImportJobCompleted ImportJobStatus = "COMPLETED"
+	ImportJobFailed    ImportJobStatus = "FAILED"
+)
+
+// ImportRow là một đơn hàng đọc được từ file nhập
+type ImportRow struct {
+	Line         int                 `json:"-"`
+	Reference    string              `json:"reference"` // mã đơn của merchant, trả lại trong báo cáo
+	CustomerID   string              `json:"customer_id"`
+	Origin       domain.Location     `json:"origin"`
+	Destination  domain.Location     `json:"destination"`
+	Items        []domain.OrderItem  `json:"items"`
+	ServiceLevel domain.ServiceLevel `json:"service_level,omitempty"` // mặc định STANDARD
+	CreatedAt    *time.Time          `json:"created_at,omitempty"`    // thời gian tạo gốc, mặc định là lúc nhập
+	Err          error               `json:"-"`                       // lỗi khi đọc dòng
+}
+
+// ImportRowResult là kết quả nhập một dòng, Error rỗng nghĩa là đơn hàng đã được tạo
+type ImportRowResult struct {
+	Line           int
+	Reference      string
+	OrderID        string
+	TrackingNumber string
+	Error          string
+}
+
+// ImportJob là một lần nhập đơn hàng chạy nền
+type ImportJob struct {
+	ID         string
+	Format     string
+	Status     ImportJobStatus
+	Total      int
+	Processed  int
+	Succeeded  int
+	Failed     int
+	Error      string
+	Results    []ImportRowResult
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ImportService nhập hàng loạt đơn hàng từ file CSV hoặc JSONL
+type ImportService interface {
+	// ImportOrders tạo đơn hàng cho từng dòng theo lô, mỗi lô trong một transaction
+	ImportOrders(ctx context.Context, rows []ImportRow) ([]ImportRowResult, error)
+
+	// StartImport đọc file rồi nhập đơn hàng trong một job chạy nền
+	StartImport(ctx context.Context, format string, data []byte) (*ImportJob, error)
+
+	// GetImportJob lấy trạng thái và kết quả hiện tại của job
+	GetImportJob(ctx context.Context, jobID string) (*ImportJob, error)
+}
+
+// importService triển khai ImportService
+type importService struct {
+	eventStore eventstore.EventStore
+	eventBus   eventbus.EventBus
+	orderOpts  []domain.OrderOption
+
+	mu   sync.RWMutex
+	jobs map[string]*ImportJob
+}
+
+// NewImportService tạo một instance mới của ImportService.
+// orderOpts giống như của OrderService, dòng có created_at được tạo với clock cố định tại thời điểm đó.
+func NewImportService(
+	eventStore eventstore.EventStore,
+	eventBus eventbus.EventBus,
+	orderOpts ...domain.OrderOption,
+) ImportService {
+	return &importService{
+		eventStore: eventStore,
+		eventBus:   eventBus,
+		orderOpts:  orderOpts,
+		jobs:       make(map[string]*ImportJob),
+	}
+}
+
+// ImportOrders tạo đơn hàng cho từng dòng, dòng lỗi không ảnh hưởng tới các dòng khác
+func (s *importService) ImportOrders(ctx context.Context, rows []ImportRow) ([]ImportRowResult, error) {
+	results := make([]ImportRowResult, 0, len(rows))
+	err := s.importRows(ctx, rows, func(batch []ImportRowResult) {
+		results = append(results, batch...)
+	})
+	return results, err
+}
+
+// StartImport đọc file và khởi chạy job, lỗi định dạng file được trả về ngay
+func (s *importService) StartImport(_ context.Context, format string, data []byte) (*ImportJob, error) {
+	rows, err := ParseImport(bytes.NewReader(data), format)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file nhập không có đơn hàng nào")
+	}
+
+	job := &ImportJob{
+		ID:        uuid.New().String(),
+		Format:    format,
+		Status:    ImportJobPending,
+		Total:     len(rows),
+		Results:   make([]ImportRowResult, 0, len(rows)),
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	s.pruneJobs(job.CreatedAt)
+	s.jobs[job.ID] = job
+	s.mu.Unlock()
+
+	// Job tiếp tục chạy sau khi request kết thúc
+	go s.runJob(job, rows)
+
+	return s.snapshot(job), nil
+}
+
+// GetImportJob lấy bản sao trạng thái hiện tại của job
+func (s *importService) GetImportJob(_ context.Context, jobID string) (*ImportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("không tìm thấy job nhập đơn hàng")
+	}
+	return s.snapshotLocked(job), nil
+}
+
+// runJob nhập các dòng và cập nhật tiến độ của job sau mỗi lô
+func (s *importService) runJob(job *ImportJob, rows []ImportRow) {
+	s.mu.Lock()
+	job.Status = ImportJobRunning
+	s.mu.Unlock()
+
+	err := s.importRows(context.Background(), rows, func(batch []ImportRowResult) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, result := range batch {
+			if result.Error == "" {
+				job.Succeeded++
+			} else {
+				job.Failed++
+			}
+		}
+		job.Processed += len(batch)
+		job.Results = append(job.Results, batch...)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = ImportJobCompleted
+	if err != nil {
+		job.Status = ImportJobFailed
+		job.Error = err.Error()
+	}
+}
+
+// importRows nhập các dòng theo lô và gọi onBatch với kết quả của từng lô
+func (s *importService) importRows(ctx context.Context, rows []ImportRow, onBatch func([]ImportRowResult)) error {
+	for start := 0; start < len(rows); start += importBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + importBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		onBatch(s.importBatch(ctx, rows[start:end]))
+	}
+	return nil
+}
+
+// checkImportItems từ chối mặt hàng theo danh mục vì nhập hàng loạt không tra cứu sản phẩm,
+// ảnh chụp sản phẩm chỉ được tạo khi tạo đơn qua API
+func checkImportItems(items []domain.OrderItem) error {
+	for _, item := range items {
+		if item.ProductUID != "" || item.Product != nil {
+			return fmt.Errorf("mặt hàng %s: nhập hàng loạt chưa hỗ trợ product_uid", item.ID)
+		}
+	}
+	return nil
+}
+
+// importBatch kiểm tra và tạo đơn hàng của một lô rồi lưu tất cả sự kiện trong một transaction.
+// Nếu lưu thất bại thì mọi dòng hợp lệ trong lô đều bị đánh dấu lỗi.
+func (s *importService) importBatch(ctx context.Context, rows []ImportRow) []ImportRowResult {
+	results := make([]ImportRowResult, len(rows))
+	var events []domain.Event
+	var created []int
+
+	for i, row := range rows {
+		results[i] = ImportRowResult{Line: row.Line, Reference: row.Reference}
+		if row.Err != nil {
+			results[i].Error = row.Err.Error()
+			continue
+		}
+
+		if err := checkImportItems(row.Items); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		order, err := domain.NewOrder(row.CustomerID, row.Origin, row.Destination, row.Items, s.rowOptions(row)...)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		results[i].OrderID = order.ID
+		results[i].TrackingNumber = order.TrackingNumber
+		events = append(events, order.GetUncommittedEvents()...)
+		created = append(created, i)
+	}
+
+	if err := s.eventStore.SaveEventsBatch(ctx, events); err != nil {
+		for _, i := range created {
+			results[i].OrderID = ""
+			results[i].TrackingNumber = ""
+			results[i].Error = fmt.Sprintf("lỗi khi lưu sự kiện: %v", err)
+		}
+		return results
+	}
+
+	// Phát các sự kiện tới event bus để cập nhật read models
+	for _, event := range events {
+		if err := s.eventBus.Publish(event); err != nil {
+			// Chỉ log lỗi, đơn hàng đã được lưu
+			fmt.Printf("Lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+
+	return results
+}
+
+// rowOptions dùng mức dịch vụ và thời gian tạo gốc của dòng nếu có.
+// Đơn hàng có thời gian tạo gốc không được ước tính ETA khi nhập vì thời gian cam kết tính từ quá khứ
+// sẽ khiến SLAMonitor đánh dấu vi phạm ngay, ETA và thời gian cam kết được tính ở lần đổi trạng thái đầu tiên.
+func (s *importService) rowOptions(row ImportRow) []domain.OrderOption {
+	if row.CreatedAt == nil && row.ServiceLevel == "" {
+		return s.orderOpts
+	}
+	opts := append([]domain.OrderOption{}, s.orderOpts...)
+	if row.ServiceLevel != "" {
+		opts = append(opts, domain.WithServiceLevel(row.ServiceLevel))
+	}
+	if row.CreatedAt != nil {
+		opts = append(opts, domain.WithClock(domain.FixedClock(*row.CreatedAt)), domain.WithDeliveryEstimator(nil))
+	}
+	return opts
+}
+
+// pruneJobs xóa các job đã kết thúc quá thời gian lưu giữ, cần giữ khóa khi gọi
+func (s *importService) pruneJobs(now time.Time) {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > importJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *importService) snapshot(job *ImportJob) *ImportJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshotLocked(job)
+}
+
+// snapshotLocked sao chép job để người gọi đọc an toàn khi job vẫn đang chạy
+func (s *importService) snapshotLocked(job *ImportJob) *ImportJob {
+	copied := *job
+	copied.Results = append([]ImportRowResult(nil), job.Results...)
+	return &copied
+}
+
+// ImportFormatFromPath đoán định dạng file nhập theo phần mở rộng
+func ImportFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return ImportFormatJSONL
+	default:
+		return ""
+	}
+}
+
+// ParseImport đọc các đơn hàng từ file nhập.
+// Lỗi của từng dòng được ghi vào ImportRow.Err, chỉ lỗi của cả file (định dạng, header) mới được trả về.
+func ParseImport(r io.Reader, format string) ([]ImportRow, error) {
+	switch format {
+	case ImportFormatCSV:
+		return parseImportCSV(r)
+	case ImportFormatJSONL:
+		return parseImportJSONL(r)
+	default:
+		return nil, fmt.Errorf("định dạng file nhập không được hỗ trợ: %q", format)
+	}
+}
+
+// parseImportJSONL đọc mỗi dòng là một đối tượng JSON, bỏ qua dòng trống
+func parseImportJSONL(r io.Reader) ([]ImportRow, error) {
+	var rows []ImportRow
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var row ImportRow
+		if err := json.Unmarshal(text, &row); err != nil {
+			row = ImportRow{Err: fmt.Errorf("JSON không hợp lệ: %w", err)}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("lỗi khi đọc file JSONL: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Các cột của file CSV. Đơn hàng nhiều mặt hàng dùng cột items chứa mảng JSON,
+// đơn hàng một mặt hàng có thể dùng các cột item_*.
+var importCSVColumns = []string{
+	"reference", "customer_id", "service_level", "created_at",
+	"origin_address", "origin_city", "origin_latitude", "origin_longitude",
+	"destination_address", "destination_city", "destination_latitude", "destination_longitude",
+	"items", "item_id", "item_name", "item_description", "item_quantity", "item_weight", "item_price",
+}
+
+// parseImportCSV đọc file CSV có dòng header
+func parseImportCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file CSV không có header")
+		}
+		return nil, fmt.Errorf("lỗi khi đọc header CSV: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["customer_id"]; !ok {
+		return nil, fmt.Errorf("header CSV thiếu cột customer_id, các cột hỗ trợ: %s", strings.Join(importCSVColumns, ", "))
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("lỗi khi đọc file CSV: %w", err)
+			}
+			rows = append(rows, ImportRow{Line: parseErr.StartLine, Err: fmt.Errorf("CSV không hợp lệ: %w", parseErr.Err)})
+			continue
+		}
+
+		row, err := parseCSVRecord(record, columns)
+		if err != nil {
+			row = ImportRow{Reference: csvField(record, columns, "reference"), Err: err}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseCSVRecord chuyển một bản ghi CSV thành ImportRow
+func parseCSVRecord(record []string, columns map[string]int) (ImportRow, error) {
+	field := func(name string) string {
+		return csvField(record, columns, name)
+	}
+	p := &csvNumberParser{field: field}
+
+	row := ImportRow{
+		Reference:    field("reference"),
+		CustomerID:   field("customer_id"),
+		ServiceLevel: domain.ServiceLevel(strings.ToUpper(field("service_level"))),
+		Origin: domain.Location{
+			Address:   field("origin_address"),
+			City:      field("origin_city"),
+			Latitude:  p.float("origin_latitude"),
+			Longitude: p.float("origin_longitude"),
+		},
+		Destination: domain.Location{
+			Address:   field("destination_address"),
+			City:      field("destination_city"),
+			Latitude:  p.float("destination_latitude"),
+			Longitude: p.float("destination_longitude"),
+		},
+	}
+
+	if value := field("created_at"); value != "" {
+		createdAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return ImportRow{}, fmt.Errorf("created_at phải theo định dạng RFC3339: %q", value)
+		}
+		row.CreatedAt = &createdAt
+	}
+
+	if value := field("items"); value != "" {
+		if err := json.Unmarshal([]byte(value), &row.Items); err != nil {
+			return ImportRow{}, fmt.Errorf("cột items phải là mảng JSON: %w", err)
+		}
+	} else if field("item_name") != "" || field("item_id") != "" {
+		row.Items = []domain.OrderItem{{
+			ID:          field("item_id"),
+			Name:        field("item_name"),
+			Description: field("item_description"),
+			Quantity:    p.int("item_quantity"),
+			Weight:      p.decimal("item_weight"),
+			Price:       p.decimal("item_price"),
+		}}
+	}
+
+	if p.err != nil {
+		return ImportRow{}, p.err
+	}
+	return row, nil
+}
+
+func csvField(record []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// csvNumberParser đọc các cột số, giữ lại lỗi đầu tiên
+type csvNumberParser struct {
+	field func(name string) string
+	err   error
+}
+
+func (p *csvNumberParser) float(name string) float64 {
+	value := p.field(name)
+	if value == "" || p.err != nil {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.err = fmt.Errorf("cột %s phải là số: %q", name, value)
+	}
+	return f
+}
+
+func (p *csvNumberParser) decimal(name string) decimal.Decimal {
+	value := p.field(name)
+	if value == "" || p.err != nil {
+		return decimal.Zero
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		p.err = fmt.Errorf("cột %s phải là số: %q", name, value)
+	}
+	return d
+}
+
+func (p *csvNumberParser) int(name string) int {
+	value := p.field(name)
+	if value == "" || p.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.err = fmt.Errorf("cột %s phải là số nguyên: %q", name, value)
+	}
+	return n
+}
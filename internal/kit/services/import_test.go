@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+const importCSV = "\ufeffreference,customer_id,created_at,origin_city,destination_city,destination_latitude,item_name,item_quantity,item_price,items\n" +
+	"A-1,CUS-001,2024-12-01T09:30:00Z,Hồ Chí Minh,Hà Nội,21.0245,Sách,2,120000,\n" +
+	"A-2,CUS-002,,Hồ Chí Minh,Đà Nẵng,16.05,,,,\"[{\"\"id\"\":\"\"ITEM-1\"\",\"\"quantity\"\":1},{\"\"id\"\":\"\"ITEM-2\"\",\"\"quantity\"\":3}]\"\n" +
+	"A-3,,,Hồ Chí Minh,Hà Nội,,Bút,1,5000,\n" +
+	"A-4,CUS-004,,Hồ Chí Minh,Hà Nội,abc,Bút,1,5000,\n" +
+	"A-5,CUS-005,,Hồ Chí Minh,Hà Nội,,,,,\"[{\"\"id\"\":\"\"ITEM-1\"\",\"\"product_uid\"\":\"\"c5ec2b98\"\",\"\"quantity\"\":1}]\"\n"
+
+const importJSONL = `{"reference":"B-1","customer_id":"CUS-001","destination":{"city":"Hà Nội"},"items":[{"id":"ITEM-1","quantity":1}]}
+
+{"reference":"B-2","customer_id":"CUS-002","items":[]}
+{not json}
+`
+
+func TestParseImportCSV(t *testing.T) {
+	rows, err := ParseImport(strings.NewReader(importCSV), ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("số dòng = %d, muốn 5", len(rows))
+	}
+
+	first := rows[0]
+	if first.Line != 2 || first.Reference != "A-1" || first.Destination.Latitude != 21.0245 || first.Err != nil {
+		t.Fatalf("dòng 1 = %+v", first)
+	}
+	if len(first.Items) != 1 || first.Items[0].Name != "Sách" || first.Items[0].Quantity != 2 || !first.Items[0].Price.Equal(decimal.NewFromInt(120000)) {
+		t.Fatalf("mặt hàng dòng 1 = %+v", first.Items)
+	}
+	if first.CreatedAt == nil || !first.CreatedAt.Equal(time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Fatalf("created_at dòng 1 = %v", first.CreatedAt)
+	}
+
+	if len(rows[1].Items) != 2 || rows[1].Items[1].Quantity != 3 || rows[1].CreatedAt != nil {
+		t.Fatalf("dòng 2 = %+v", rows[1])
+	}
+	if rows[2].Err != nil {
+		t.Fatalf("dòng thiếu customer_id được kiểm tra khi tạo đơn hàng, không phải khi đọc: %v", rows[2].Err)
+	}
+	if rows[3].Err == nil || !strings.Contains(rows[3].Err.Error(), "destination_latitude") || rows[3].Reference != "A-4" {
+		t.Fatalf("dòng 4 phải lỗi ở destination_latitude: %+v", rows[3])
+	}
+}
+
+func TestParseImportRejectsBadFile(t *testing.T) {
+	if _, err := ParseImport(strings.NewReader("reference,name\nA,B\n"), ImportFormatCSV); err == nil {
+		t.Fatal("header thiếu customer_id phải trả về lỗi")
+	}
+	if _, err := ParseImport(strings.NewReader(""), "xml"); err == nil {
+		t.Fatal("định dạng không hỗ trợ phải trả về lỗi")
+	}
+	if got := ImportFormatFromPath("orders.NDJSON"); got != ImportFormatJSONL {
+		t.Fatalf("ImportFormatFromPath = %q", got)
+	}
+}
+
+func TestImportOrders(t *testing.T) {
+	ctx := context.Background()
+	service, store, repo := newTestImportService(t)
+
+	rows, err := ParseImport(strings.NewReader(importCSV), ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	results, err := service.ImportOrders(ctx, rows)
+	if err != nil {
+		t.Fatalf("ImportOrders: %v", err)
+	}
+
+	wantErrors := []string{"", "", "customer ID không được để trống", "destination_latitude", "product_uid"}
+	for i, want := range wantErrors {
+		result := results[i]
+		if result.Line != rows[i].Line || result.Reference != rows[i].Reference {
+			t.Fatalf("kết quả %d không khớp dòng: %+v", i, result)
+		}
+		if want == "" && (result.Error != "" || result.OrderID == "") {
+			t.Fatalf("dòng %d phải thành công: %+v", result.Line, result)
+		}
+		if want != "" && (!strings.Contains(result.Error, want) || result.OrderID != "") {
+			t.Fatalf("dòng %d: lỗi = %q, muốn chứa %q", result.Line, result.Error, want)
+		}
+	}
+
+	// Đơn hàng được lưu vào event store và read model với thời gian tạo gốc
+	events, err := store.GetEvents(ctx, results[0].OrderID)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("GetEvents = %d sự kiện, %v", len(events), err)
+	}
+	order, err := repo.GetByID(ctx, results[0].OrderID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !order.CreatedAt.Equal(time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC)) || order.TrackingNumber != results[0].TrackingNumber {
+		t.Fatalf("đơn hàng nhập = %+v", order)
+	}
+	if order, _ := repo.GetByID(ctx, results[1].OrderID); order == nil || !order.CreatedAt.Equal(domaintest.Now) {
+		t.Fatalf("dòng không có created_at phải dùng clock của service: %+v", order)
+	}
+}
+
+func TestImportHistoricalOrderSkipsSLA(t *testing.T) {
+	ctx := context.Background()
+	now := domaintest.Now
+	clock := domain.ClockFunc(func() time.Time { return now })
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := []domain.OrderOption{
+		domain.WithClock(clock),
+		domain.WithIDGenerator(&domaintest.SequenceIDs{}),
+		domain.WithDeliveryEstimator(NewETAService(DefaultETARules())),
+	}
+	service := NewImportService(store, bus, opts...)
+	orders := NewOrderService(store, repo, bus, opts...)
+	monitor := NewSLAMonitor(store, repo, bus, clock, opts...)
+
+	rows, err := ParseImport(strings.NewReader(importCSV), ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	results, err := service.ImportOrders(ctx, rows[:2])
+	if err != nil {
+		t.Fatalf("ImportOrders: %v", err)
+	}
+
+	// Đơn hàng tạo từ tháng 12/2024 không có thời gian cam kết nên không bị đánh dấu vi phạm
+	historical, err := repo.GetByID(ctx, results[0].OrderID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if historical.PromisedDelivery != nil || historical.EstimatedDelivery != nil {
+		t.Fatalf("đơn hàng nhập có thời gian tạo gốc = %+v", historical)
+	}
+	if current, _ := repo.GetByID(ctx, results[1].OrderID); current == nil || current.PromisedDelivery == nil {
+		t.Fatalf("đơn hàng nhập không có created_at phải được ước tính ETA: %+v", current)
+	}
+	if breached, err := monitor.CheckOverdue(ctx); err != nil || breached != 0 {
+		t.Fatalf("CheckOverdue = %d, %v, muốn 0", breached, err)
+	}
+
+	// Lần đổi trạng thái đầu tiên tính thời gian cam kết theo clock của service
+	if err := orders.UpdateOrderStatus(ctx, results[0].OrderID, domain.OrderStatusInTransit, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	historical, err = orders.GetOrder(ctx, results[0].OrderID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if historical.PromisedDelivery == nil || !historical.PromisedDelivery.After(now) || historical.SLABreached {
+		t.Fatalf("đơn hàng sau khi đổi trạng thái = %+v", historical)
+	}
+	if breached, err := monitor.CheckOverdue(ctx); err != nil || breached != 0 {
+		t.Fatalf("CheckOverdue sau khi đổi trạng thái = %d, %v, muốn 0", breached, err)
+	}
+}
+
+func TestImportJob(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestImportService(t)
+
+	if _, err := service.StartImport(ctx, ImportFormatJSONL, []byte("\n\n")); err == nil {
+		t.Fatal("file rỗng phải trả về lỗi")
+	}
+
+	job, err := service.StartImport(ctx, ImportFormatJSONL, []byte(importJSONL))
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+	if job.Total != 3 {
+		t.Fatalf("Total = %d, muốn 3", job.Total)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != ImportJobCompleted {
+		if time.Now().After(deadline) {
+			t.Fatalf("job chưa hoàn thành: %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if job, err = service.GetImportJob(ctx, job.ID); err != nil {
+			t.Fatalf("GetImportJob: %v", err)
+		}
+	}
+
+	if job.Processed != 3 || job.Succeeded != 1 || job.Failed != 2 || job.FinishedAt == nil {
+		t.Fatalf("job = %+v", job)
+	}
+	// Dòng trống được bỏ qua nhưng vẫn tính vào số dòng
+	lines := [3]int{job.Results[0].Line, job.Results[1].Line, job.Results[2].Line}
+	if lines != [3]int{1, 3, 4} {
+		t.Fatalf("số dòng = %v, muốn [1 3 4]", lines)
+	}
+	if !strings.Contains(job.Results[1].Error, "ít nhất một mục") || !strings.Contains(job.Results[2].Error, "JSON") {
+		t.Fatalf("kết quả = %+v", job.Results)
+	}
+
+	if _, err := service.GetImportJob(ctx, "missing"); err == nil {
+		t.Fatal("job không tồn tại phải trả về lỗi")
+	}
+}
+
+func newTestImportService(t *testing.T) (ImportService, eventstore.EventStore, repository.OrderRepository) {
+	t.Helper()
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	return NewImportService(store, bus, domaintest.Options()...), store, repo
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// MaxPingBatch là số vị trí tối đa tài xế gửi trong một request
+const MaxPingBatch = 100
+
+// maxClockSkew là độ lệch tối đa cho phép giữa đồng hồ thiết bị của tài xế và máy chủ
+const maxClockSkew = time.Minute
+
+// LocationPing là một vị trí thiết bị của tài xế ghi nhận, RecordedAt rỗng nghĩa là lúc máy chủ nhận
+type LocationPing struct {
+	Point      geo.Point
+	RecordedAt time.Time
+}
+
+// LocationSampling là quy tắc lấy mẫu vị trí tài xế
+type LocationSampling struct {
+	// Vị trí cách vị trí đã nhận trước đó dưới MinInterval và dưới MinDistanceKm bị bỏ qua
+	MinInterval   time.Duration
+	MinDistanceKm float64
+	// TrailDistanceKm là quãng di chuyển tối thiểu so với điểm hành trình trước để ghi sự kiện vào luồng đơn hàng
+	TrailDistanceKm float64
+}
+
+// DefaultLocationSampling nhận tối đa hai vị trí mỗi phút khi tài xế đứng yên
+// và ghi điểm hành trình khi đơn hàng di chuyển từ 1 km
+func DefaultLocationSampling() LocationSampling {
+	return LocationSampling{
+		MinInterval:     30 * time.Second,
+		MinDistanceKm:   0.05,
+		TrailDistanceKm: 1,
+	}
+}
+
+// keep kiểm tra vị trí có được nhận sau vị trí last hay không
+func (s LocationSampling) keep(last *projection.DriverLocation, ping LocationPing) bool {
+	if last == nil {
+		return true
+	}
+	if !ping.RecordedAt.After(last.RecordedAt) {
+		return false
+	}
+	return ping.RecordedAt.Sub(last.RecordedAt) >= s.MinInterval || geo.DistanceKm(last.Point, ping.Point) >= s.MinDistanceKm
+}
+
+// significant kiểm tra đơn hàng có cần ghi điểm hành trình mới tại point hay không
+func (s LocationSampling) significant(trail *projection.TrailPoint, status domain.OrderStatus, point geo.Point) bool {
+	return trail == nil || trail.Status != status || geo.DistanceKm(trail.Point, point) >= s.TrailDistanceKm
+}
+
+// PingResult là kết quả nhận vị trí của tài xế
+type PingResult struct {
+	Received int // số vị trí trong request
+	Accepted int // số vị trí được nhận sau khi lấy mẫu
+	Orders   int // số đơn hàng được cập nhật vị trí
+	Trails   int // số điểm hành trình được ghi vào luồng đơn hàng
+	Location *projection.DriverLocation
+}
+
+// LocationService nhận vị trí GPS của tài xế và cập nhật vị trí các đơn hàng được phân công
+type LocationService interface {
+	// RecordPings nhận các vị trí của tài xế, vị trí cũ hoặc quá gần vị trí trước bị bỏ qua
+	RecordPings(ctx context.Context, driverID string, pings []LocationPing) (*PingResult, error)
+
+	// DriverLocation lấy vị trí mới nhất của tài xế
+	DriverLocation(ctx context.Context, driverID string) (*projection.DriverLocation, error)
+
+	// OrderLocation lấy vị trí hiện tại của đơn hàng theo vị trí của tài xế được phân công
+	OrderLocation(ctx context.Context, orderID string) (*projection.OrderLocation, error)
+}
+
+type locationService struct {
+	eventStore eventstore.EventStore
+	repository repository.OrderRepository
+	eventBus   eventbus.EventBus
+	locations  projection.LocationProjection
+	clock      domain.Clock
+	sampling   LocationSampling
+	orderOpts  []domain.OrderOption
+}
+
+// NewLocationService tạo service vị trí tài xế, orderOpts giống như của OrderService
+func NewLocationService(
+	eventStore eventstore.EventStore,
+	repository repository.OrderRepository,
+	eventBus eventbus.EventBus,
+	locations projection.LocationProjection,
+	clock domain.Clock,
+	sampling LocationSampling,
+	orderOpts ...domain.OrderOption,
+) LocationService {
+	return &locationService{
+		eventStore: eventStore,
+		repository: repository,
+		eventBus:   eventBus,
+		locations:  locations,
+		clock:      clock,
+		sampling:   sampling,
+		orderOpts:  orderOpts,
+	}
+}
+
+// RecordPings lấy mẫu các vị trí theo thời điểm ghi nhận, chỉ vị trí được nhận mới nhất
+// được dùng để cập nhật projection và quyết định ghi điểm hành trình cho đơn hàng
+func (s *locationService) RecordPings(ctx context.Context, driverID string, pings []LocationPing) (*PingResult, error) {
+	if driverID == "" {
+		return nil, errors.New("driver ID không được để trống")
+	}
+	if len(pings) == 0 || len(pings) > MaxPingBatch {
+		return nil, fmt.Errorf("cần từ 1 đến %d vị trí trong một lần gửi", MaxPingBatch)
+	}
+
+	now := s.clock.Now()
+	sorted := make([]LocationPing, len(pings))
+	for i, ping := range pings {
+		if ping.Point.IsZero() || !ping.Point.Valid() {
+			return nil, fmt.Errorf("vị trí thứ %d có tọa độ không hợp lệ", i+1)
+		}
+		if ping.RecordedAt.IsZero() {
+			ping.RecordedAt = now
+		}
+		if ping.RecordedAt.After(now.Add(maxClockSkew)) {
+			return nil, fmt.Errorf("vị trí thứ %d có thời điểm ghi nhận ở tương lai", i+1)
+		}
+		sorted[i] = ping
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+
+	last, err := s.locations.DriverLocation(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	// Chỉ kiểm tra tài xế tồn tại ở lần gửi đầu tiên, các lần sau đã có vị trí trong projection
+	if last == nil {
+		events, err := s.eventStore.GetEvents(ctx, driverID)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+		}
+		if domain.RebuildDriver(events, s.orderOpts...) == nil {
+			return nil, errors.New("không tìm thấy tài xế")
+		}
+	}
+
+	result := &PingResult{Received: len(pings), Location: last}
+	for _, ping := range sorted {
+		if !s.sampling.keep(result.Location, ping) {
+			continue
+		}
+		result.Accepted++
+		result.Location = &projection.DriverLocation{
+			DriverID:   driverID,
+			Point:      ping.Point,
+			RecordedAt: ping.RecordedAt,
+			ReceivedAt: now,
+		}
+	}
+	if result.Accepted == 0 {
+		return result, nil
+	}
+
+	orders, err := s.repository.ListByDriver(ctx, driverID, "")
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		if order.CountsTowardsLoad() {
+			ids = append(ids, order.ID)
+		}
+	}
+	current, err := s.locations.OrderLocations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	location := result.Location
+	updated := make([]projection.OrderLocation, 0, len(ids))
+	for _, order := range orders {
+		if !order.CountsTowardsLoad() {
+			continue
+		}
+		trail := current[order.ID].Trail
+		if s.sampling.significant(trail, order.Status, location.Point) {
+			// Lỗi ghi điểm hành trình không làm mất vị trí, điểm hành trình được thử lại ở lần gửi sau
+			if recorded, err := s.recordTrail(ctx, order.ID, location); err != nil {
+				fmt.Printf("lỗi khi ghi điểm hành trình của đơn hàng %s: %v\n", order.ID, err)
+			} else {
+				trail = recorded
+				result.Trails++
+			}
+		}
+		updated = append(updated, projection.OrderLocation{
+			OrderID:    order.ID,
+			DriverID:   driverID,
+			Point:      location.Point,
+			RecordedAt: location.RecordedAt,
+			Trail:      trail,
+		})
+	}
+
+	if err := s.locations.Save(ctx, *location, updated); err != nil {
+		return nil, err
+	}
+	result.Orders = len(updated)
+
+	return result, nil
+}
+
+// recordTrail ghi sự kiện điểm hành trình vào luồng của đơn hàng
+func (s *locationService) recordTrail(ctx context.Context, orderID string, location *projection.DriverLocation) (*projection.TrailPoint, error) {
+	events, err := s.eventStore.GetEvents(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
+	if order == nil {
+		return nil, fmt.Errorf("không tìm thấy đơn hàng")
+	}
+
+	if err := order.RecordLocation(location.DriverID, location.Point, location.RecordedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.eventStore.SaveEvents(ctx, order.ID, order.GetUncommittedEvents()); err != nil {
+		return nil, fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+	}
+
+	for _, event := range order.GetUncommittedEvents() {
+		if err := s.eventBus.Publish(event); err != nil {
+			fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+	order.ClearUncommittedEvents()
+
+	return &projection.TrailPoint{Point: location.Point, Status: order.Status, RecordedAt: location.RecordedAt}, nil
+}
+
+// DriverLocation lấy vị trí mới nhất của tài xế
+func (s *locationService) DriverLocation(ctx context.Context, driverID string) (*projection.DriverLocation, error) {
+	location, err := s.locations.DriverLocation(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, errors.New("chưa có vị trí của tài xế")
+	}
+	return location, nil
+}
+
+// OrderLocation lấy vị trí hiện tại của đơn hàng
+func (s *locationService) OrderLocation(ctx context.Context, orderID string) (*projection.OrderLocation, error) {
+	locations, err := s.locations.OrderLocations(ctx, []string{orderID})
+	if err != nil {
+		return nil, err
+	}
+	location, ok := locations[orderID]
+	if !ok {
+		return nil, errors.New("chưa có vị trí của đơn hàng")
+	}
+	return &location, nil
+}
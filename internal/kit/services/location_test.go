@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+func TestLocationServiceRecordPings(t *testing.T) {
+	ctx := context.Background()
+	now := domaintest.Now
+	clock := domain.ClockFunc(func() time.Time { return now })
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.EventTypesOf(domain.AggregateOrder)...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := []domain.OrderOption{domain.WithClock(clock), domain.WithIDGenerator(&domaintest.SequenceIDs{})}
+	orders := NewOrderService(store, repo, bus, opts...)
+	fleet := NewFleetService(store, repo, bus, opts...)
+	locations := NewLocationService(store, repo, bus, projection.NewInMemoryLocationProjection(), clock, DefaultLocationSampling(), opts...)
+
+	driverID := registerDriver(t, fleet, decimal.NewFromInt(100))
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, []domain.OrderItem{{ID: "ITEM-1", Quantity: 1}}, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		if err := fleet.AssignOrder(ctx, id, driverID); err != nil {
+			t.Fatalf("AssignOrder: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	// Đơn hàng đã giao không còn nhận vị trí của tài xế
+	if err := orders.UpdateOrderStatus(ctx, ids[1], domain.OrderStatusDelivered, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+
+	if _, err := locations.RecordPings(ctx, "missing", []LocationPing{{Point: geo.Point{Latitude: 10.8, Longitude: 106.6}}}); err == nil {
+		t.Fatalf("RecordPings với tài xế không tồn tại phải lỗi")
+	}
+	if _, err := locations.RecordPings(ctx, driverID, []LocationPing{{Point: geo.Point{Latitude: 91}}}); err == nil {
+		t.Fatalf("RecordPings với tọa độ không hợp lệ phải lỗi")
+	}
+
+	start := geo.Point{Latitude: 10.8231, Longitude: 106.6297}
+	// Các vị trí gửi không theo thứ tự, vị trí cách 10 giây và vài mét bị bỏ qua
+	result, err := locations.RecordPings(ctx, driverID, []LocationPing{
+		{Point: geo.Point{Latitude: 10.82311, Longitude: 106.6297}, RecordedAt: now.Add(-50 * time.Second)},
+		{Point: start, RecordedAt: now.Add(-time.Minute)},
+	})
+	if err != nil {
+		t.Fatalf("RecordPings: %v", err)
+	}
+	if result.Received != 2 || result.Accepted != 1 || result.Orders != 1 || result.Trails != 1 || result.Location.Point != start {
+		t.Fatalf("result = %+v", result)
+	}
+
+	// Di chuyển khoảng 300 m chỉ cập nhật projection, không ghi sự kiện
+	nearby := geo.Point{Latitude: 10.8258, Longitude: 106.6297}
+	result, err = locations.RecordPings(ctx, driverID, []LocationPing{{Point: nearby}})
+	if err != nil {
+		t.Fatalf("RecordPings: %v", err)
+	}
+	if result.Accepted != 1 || result.Trails != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	location, err := locations.OrderLocation(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("OrderLocation: %v", err)
+	}
+	if location.Point != nearby || location.Trail == nil || location.Trail.Point != start {
+		t.Fatalf("OrderLocation = %+v", location)
+	}
+	if _, err := locations.OrderLocation(ctx, ids[1]); err == nil {
+		t.Fatalf("đơn hàng đã giao không được có vị trí")
+	}
+
+	// Vị trí cũ hơn vị trí đã nhận bị bỏ qua
+	if result, err := locations.RecordPings(ctx, driverID, []LocationPing{{Point: start, RecordedAt: now.Add(-time.Hour)}}); err != nil || result.Accepted != 0 {
+		t.Fatalf("RecordPings vị trí cũ = %+v, %v", result, err)
+	}
+
+	// Đổi trạng thái thì ghi điểm hành trình dù chưa di chuyển đáng kể
+	if err := orders.UpdateOrderStatus(ctx, ids[0], domain.OrderStatusOutForDelivery, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if result, err := locations.RecordPings(ctx, driverID, []LocationPing{{Point: nearby}}); err != nil || result.Trails != 1 {
+		t.Fatalf("RecordPings sau khi đổi trạng thái = %+v, %v", result, err)
+	}
+
+	// Di chuyển hơn 1 km so với điểm hành trình trước thì ghi sự kiện
+	now = now.Add(time.Minute)
+	far := geo.Point{Latitude: 10.7769, Longitude: 106.7009}
+	if result, err := locations.RecordPings(ctx, driverID, []LocationPing{{Point: far}}); err != nil || result.Trails != 1 {
+		t.Fatalf("RecordPings di chuyển xa = %+v, %v", result, err)
+	}
+
+	events, err := store.GetEvents(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	var trail []domain.OrderLocationRecordedEvent
+	for _, event := range events {
+		if e, ok := event.(domain.OrderLocationRecordedEvent); ok {
+			trail = append(trail, e)
+		}
+	}
+	if len(trail) != 3 || trail[1].Status != domain.OrderStatusOutForDelivery || trail[2].Latitude != far.Latitude {
+		t.Fatalf("trail = %+v", trail)
+	}
+
+	order, err := orders.GetOrder(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.CurrentLocation == nil || order.CurrentLocation.Point() != far {
+		t.Fatalf("CurrentLocation = %+v", order.CurrentLocation)
+	}
+
+	driver, err := locations.DriverLocation(ctx, driverID)
+	if err != nil {
+		t.Fatalf("DriverLocation: %v", err)
+	}
+	if driver.Point != far || !driver.RecordedAt.Equal(now) {
+		t.Fatalf("DriverLocation = %+v", driver)
+	}
+}
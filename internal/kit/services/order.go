@@ -7,13 +7,23 @@ import (
 	"github.com/quyenle-97/init/internal/eventstore"
 	"github.com/quyenle-97/init/internal/repository"
 	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"io"
+)
+
+const (
+	// maxNearRadiusKm là bán kính lớn nhất khi tìm đơn hàng lân cận
+	maxNearRadiusKm = 200
+	// maxNearLimit là số đơn hàng lân cận tối đa trả về trong một lần
+	maxNearLimit = 500
 )
 
 // OrderService định nghĩa các thao tác có thể thực hiện với đơn hàng
 type OrderService interface {
 	// Command side (write)
-	CreateOrder(ctx context.Context, customerID string, origin, destination domain.Location, items []domain.OrderItem) (string, string, error)
+	CreateOrder(ctx context.Context, customerID string, origin, destination domain.Location, items []domain.OrderItem, serviceLevel domain.ServiceLevel, payment domain.PaymentTerms) (string, string, error)
 	UpdateOrderStatus(ctx context.Context, orderID string, newStatus domain.OrderStatus, location *domain.Location, note string) error
+	UpdateOrderStatusBatch(ctx context.Context, updates []StatusUpdate) ([]StatusUpdateResult, error)
 	CancelOrder(ctx context.Context, orderID string, reason string) error
 	AddOrderNote(ctx context.Context, orderID string, note string) error
 
@@ -21,7 +31,20 @@ type OrderService interface {
 	GetOrder(ctx context.Context, orderID string) (*domain.Order, error)
 	GetOrderByTracking(ctx context.Context, trackingNumber string) (*domain.Order, error)
 	ListOrders(ctx context.Context, customerID string, status domain.OrderStatus, offset, limit int) ([]*domain.Order, int, error)
+	ListOrdersNear(ctx context.Context, center geo.Point, radiusKm float64, status domain.OrderStatus, limit int) ([]repository.NearbyOrder, error)
 	GetOrderHistory(ctx context.Context, orderID string) ([]domain.Event, error)
+	GetOrderMetadata(ctx context.Context, orderID string) (map[string]eventstore.Metadata, error)
+	GetOrderAsOf(ctx context.Context, orderID string, asOf domain.AsOf) (*domain.Order, error)
+	DiffOrder(ctx context.Context, orderID string, from, to domain.AsOf) (*OrderDiff, error)
+	ExportOrders(ctx context.Context, format string, filter ExportFilter, w io.Writer) error
+}
+
+// OrderDiff là sự khác biệt của đơn hàng giữa hai thời điểm
+type OrderDiff struct {
+	From    *domain.Order
+	To      *domain.Order
+	Changes []domain.FieldChange
+	Events  []domain.Event // các sự kiện xảy ra giữa hai thời điểm
 }
 
 // orderService triển khai OrderService
@@ -29,18 +52,22 @@ type orderService struct {
 	eventStore eventstore.EventStore
 	orderRepo  repository.OrderRepository
 	eventBus   eventbus.EventBus
+	orderOpts  []domain.OrderOption
 }
 
-// NewOrderService tạo một instance mới của OrderService
+// NewOrderService tạo một instance mới của OrderService.
+// orderOpts cấu hình clock, bộ sinh ID và cách ước tính ETA cho mọi đơn hàng do service tạo hoặc cập nhật.
 func NewOrderService(
 	eventStore eventstore.EventStore,
 	orderRepo repository.OrderRepository,
 	eventBus eventbus.EventBus,
+	orderOpts ...domain.OrderOption,
 ) OrderService {
 	return &orderService{
 		eventStore: eventStore,
 		orderRepo:  orderRepo,
 		eventBus:   eventBus,
+		orderOpts:  orderOpts,
 	}
 }
 
@@ -51,9 +78,18 @@ func (s *orderService) CreateOrder(
 	origin domain.Location,
 	destination domain.Location,
 	items []domain.OrderItem,
+	serviceLevel domain.ServiceLevel,
+	payment domain.PaymentTerms,
 ) (string, string, error) {
-	// Tạo đơn hàng mới (command handling)
-	order, err := domain.NewOrder(customerID, origin, destination, items)
+	// Tạo đơn hàng mới (command handling), mức dịch vụ rỗng là STANDARD, cách thanh toán rỗng là trả trước
+	opts := append([]domain.OrderOption{}, s.orderOpts...)
+	if serviceLevel != "" {
+		opts = append(opts, domain.WithServiceLevel(serviceLevel))
+	}
+	if payment.Method != "" {
+		opts = append(opts, domain.WithPaymentTerms(payment))
+	}
+	order, err := domain.NewOrder(customerID, origin, destination, items, opts...)
 	if err != nil {
 		return "", "", fmt.Errorf("không thể tạo đơn hàng: %w", err)
 	}
@@ -102,6 +138,27 @@ func (s *orderService) ListOrders(
 	return s.orderRepo.ListOrders(ctx, customerID, status, offset, limit)
 }
 
+// ListOrdersNear lấy các đơn hàng có vị trí hiện tại trong bán kính radiusKm quanh center, gần nhất trước
+func (s *orderService) ListOrdersNear(
+	ctx context.Context,
+	center geo.Point,
+	radiusKm float64,
+	status domain.OrderStatus,
+	limit int,
+) ([]repository.NearbyOrder, error) {
+	if !center.Valid() {
+		return nil, fmt.Errorf("tọa độ không hợp lệ: %v, %v", center.Latitude, center.Longitude)
+	}
+	if radiusKm <= 0 || radiusKm > maxNearRadiusKm {
+		return nil, fmt.Errorf("bán kính phải lớn hơn 0 và không quá %d km", maxNearRadiusKm)
+	}
+	if limit <= 0 || limit > maxNearLimit {
+		return nil, fmt.Errorf("limit phải lớn hơn 0 và không quá %d", maxNearLimit)
+	}
+
+	return s.orderRepo.ListNear(ctx, center, radiusKm, status, limit)
+}
+
 // UpdateOrderStatus cập nhật trạng thái đơn hàng
 func (s *orderService) UpdateOrderStatus(
 	ctx context.Context,
@@ -121,7 +178,7 @@ func (s *orderService) UpdateOrderStatus(
 	}
 
 	// Xây dựng lại trạng thái đơn hàng từ các sự kiện
-	order := domain.RebuildFromEvents(events)
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
 	if order == nil {
 		return fmt.Errorf("không thể xây dựng lại đơn hàng từ sự kiện")
 	}
@@ -164,7 +221,7 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID string, reason s
 	}
 
 	// Xây dựng lại trạng thái đơn hàng từ các sự kiện
-	order := domain.RebuildFromEvents(events)
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
 	if order == nil {
 		return fmt.Errorf("không thể xây dựng lại đơn hàng từ sự kiện")
 	}
@@ -207,7 +264,7 @@ func (s *orderService) AddOrderNote(ctx context.Context, orderID string, note st
 	}
 
 	// Xây dựng lại trạng thái đơn hàng từ các sự kiện
-	order := domain.RebuildFromEvents(events)
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
 	if order == nil {
 		return fmt.Errorf("không thể xây dựng lại đơn hàng từ sự kiện")
 	}
@@ -251,3 +308,54 @@ func (s *orderService) GetOrderHistory(ctx context.Context, orderID string) ([]d
 
 	return events, nil
 }
+
+// GetOrderMetadata lấy metadata (IP, quốc gia, thành phố của request) theo ID sự kiện của đơn hàng
+func (s *orderService) GetOrderMetadata(ctx context.Context, orderID string) (map[string]eventstore.Metadata, error) {
+	metadata, err := s.eventStore.GetEventMetadata(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy metadata sự kiện: %w", err)
+	}
+	return metadata, nil
+}
+
+// GetOrderAsOf xây dựng lại đơn hàng từ các sự kiện tính đến thời điểm asOf
+func (s *orderService) GetOrderAsOf(ctx context.Context, orderID string, asOf domain.AsOf) (*domain.Order, error) {
+	events, err := s.GetOrderHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := domain.RebuildFromEvents(domain.EventsAsOf(events, asOf))
+	if order == nil {
+		return nil, fmt.Errorf("đơn hàng chưa tồn tại tại thời điểm được chọn")
+	}
+
+	return order, nil
+}
+
+// DiffOrder so sánh trạng thái đơn hàng giữa hai thời điểm
+func (s *orderService) DiffOrder(ctx context.Context, orderID string, from, to domain.AsOf) (*OrderDiff, error) {
+	events, err := s.GetOrderHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromEvents := domain.EventsAsOf(events, from)
+	toEvents := domain.EventsAsOf(events, to)
+	if len(fromEvents) > len(toEvents) {
+		return nil, fmt.Errorf("thời điểm bắt đầu phải trước thời điểm kết thúc")
+	}
+
+	diff := &OrderDiff{
+		From:   domain.RebuildFromEvents(fromEvents),
+		To:     domain.RebuildFromEvents(toEvents),
+		Events: toEvents[len(fromEvents):],
+	}
+
+	diff.Changes, err = domain.DiffOrders(diff.From, diff.To)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi so sánh đơn hàng: %w", err)
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// MaxStatusUpdateBatch là số cập nhật trạng thái tối đa trong một lần gọi
+const MaxStatusUpdateBatch = 1000
+
+// StatusUpdate là một cập nhật trạng thái trong lô, đơn hàng xác định bằng OrderID hoặc TrackingNumber
+type StatusUpdate struct {
+	OrderID        string
+	TrackingNumber string
+	NewStatus      domain.OrderStatus
+	Location       *domain.Location
+	Note           string
+}
+
+// StatusUpdateResult là kết quả của một cập nhật, Error rỗng nghĩa là thành công
+type StatusUpdateResult struct {
+	OrderID        string
+	TrackingNumber string
+	Error          string
+}
+
+// UpdateOrderStatusBatch cập nhật trạng thái nhiều đơn hàng theo quy tắc của aggregate.
+// Sự kiện được tải và lưu theo lô, cập nhật lỗi không ảnh hưởng tới các cập nhật khác.
+// Nhiều cập nhật cho cùng một đơn hàng được áp dụng lần lượt theo thứ tự trong lô.
+func (s *orderService) UpdateOrderStatusBatch(ctx context.Context, updates []StatusUpdate) ([]StatusUpdateResult, error) {
+	if len(updates) > MaxStatusUpdateBatch {
+		return nil, fmt.Errorf("tối đa %d cập nhật trong một lần gọi", MaxStatusUpdateBatch)
+	}
+
+	results := make([]StatusUpdateResult, len(updates))
+	for i, update := range updates {
+		results[i] = StatusUpdateResult{OrderID: update.OrderID, TrackingNumber: update.TrackingNumber}
+	}
+
+	// Tìm ID đơn hàng của các cập nhật theo số theo dõi
+	var trackingNumbers []string
+	for _, update := range updates {
+		if update.OrderID == "" && update.TrackingNumber != "" {
+			trackingNumbers = append(trackingNumbers, update.TrackingNumber)
+		}
+	}
+	resolved, err := s.orderRepo.ResolveTrackingNumbers(ctx, trackingNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	orderIDs := make([]string, 0, len(updates))
+	seen := make(map[string]bool, len(updates))
+	for i, update := range updates {
+		switch {
+		case update.OrderID == "" && update.TrackingNumber == "":
+			results[i].Error = "cần order_id hoặc tracking_number"
+		case update.NewStatus == "":
+			results[i].Error = "thiếu trạng thái mới"
+		case update.OrderID != "":
+		case resolved[update.TrackingNumber] == "":
+			results[i].Error = "không tìm thấy đơn hàng"
+		default:
+			results[i].OrderID = resolved[update.TrackingNumber]
+		}
+
+		if results[i].Error == "" && !seen[results[i].OrderID] {
+			seen[results[i].OrderID] = true
+			orderIDs = append(orderIDs, results[i].OrderID)
+		}
+	}
+
+	// Tải lịch sử của tất cả đơn hàng trong một lần truy vấn
+	streams, err := s.eventStore.GetEventsBatch(ctx, orderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	orders := make(map[string]*domain.Order, len(streams))
+	var events []domain.Event
+	var updated []int
+	for i, update := range updates {
+		if results[i].Error != "" {
+			continue
+		}
+
+		orderID := results[i].OrderID
+		order, ok := orders[orderID]
+		if !ok {
+			order = domain.RebuildFromEvents(streams[orderID], s.orderOpts...)
+			if order == nil {
+				results[i].Error = "không tìm thấy đơn hàng"
+				continue
+			}
+			orders[orderID] = order
+		}
+
+		// Chỉ lấy sự kiện do cập nhật này phát ra
+		pending := len(order.GetUncommittedEvents())
+		if err := order.UpdateStatus(update.NewStatus, update.Location, update.Note); err != nil {
+			results[i].Error = fmt.Sprintf("không thể cập nhật trạng thái đơn hàng: %v", err)
+			continue
+		}
+
+		results[i].TrackingNumber = order.TrackingNumber
+		events = append(events, order.GetUncommittedEvents()[pending:]...)
+		updated = append(updated, i)
+	}
+
+	// Lưu sự kiện của tất cả đơn hàng trong một transaction
+	if err := s.eventStore.SaveEventsBatch(ctx, events); err != nil {
+		for _, i := range updated {
+			results[i].Error = fmt.Sprintf("lỗi khi lưu sự kiện: %v", err)
+		}
+		return results, nil
+	}
+
+	// Phát các sự kiện
+	for _, event := range events {
+		if err := s.eventBus.Publish(event); err != nil {
+			fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+
+	return results, nil
+}
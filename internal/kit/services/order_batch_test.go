@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+)
+
+// countingStore đếm số lần truy cập event store
+type countingStore struct {
+	eventstore.EventStore
+	calls map[string]int
+}
+
+func (s *countingStore) GetEvents(ctx context.Context, aggregateID string) ([]domain.Event, error) {
+	s.calls["GetEvents"]++
+	return s.EventStore.GetEvents(ctx, aggregateID)
+}
+
+func (s *countingStore) SaveEvents(ctx context.Context, aggregateID string, events []domain.Event) error {
+	s.calls["SaveEvents"]++
+	return s.EventStore.SaveEvents(ctx, aggregateID, events)
+}
+
+func (s *countingStore) GetEventsBatch(ctx context.Context, aggregateIDs []string) (map[string][]domain.Event, error) {
+	s.calls["GetEventsBatch"]++
+	return s.EventStore.GetEventsBatch(ctx, aggregateIDs)
+}
+
+func (s *countingStore) SaveEventsBatch(ctx context.Context, events []domain.Event) error {
+	s.calls["SaveEventsBatch"]++
+	return s.EventStore.SaveEventsBatch(ctx, events)
+}
+
+func TestUpdateOrderStatusBatch(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{EventStore: eventstore.NewInMemoryEventStore(), calls: map[string]int{}}
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	service := NewOrderService(store, repo, bus, domaintest.Options()...)
+
+	items := []domain.OrderItem{{ID: "ITEM-1", Quantity: 1}}
+	var ids, tracking []string
+	for i := 0; i < 3; i++ {
+		id, trackingNumber, err := service.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, items, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		ids = append(ids, id)
+		tracking = append(tracking, trackingNumber)
+	}
+	if err := service.CancelOrder(ctx, ids[2], "khách hủy"); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	for key := range store.calls {
+		delete(store.calls, key)
+	}
+
+	hub := &domain.Location{Address: "Kho Đà Nẵng", City: "Đà Nẵng"}
+	results, err := service.UpdateOrderStatusBatch(ctx, []StatusUpdate{
+		{OrderID: ids[0], NewStatus: domain.OrderStatusInTransit, Location: hub},
+		{TrackingNumber: tracking[1], NewStatus: domain.OrderStatusInTransit, Location: hub, Note: "đến hub"},
+		{OrderID: ids[2], NewStatus: domain.OrderStatusInTransit},
+		{TrackingNumber: "TRK-MISSING", NewStatus: domain.OrderStatusInTransit},
+		{OrderID: ids[0]},
+		{},
+		{OrderID: ids[0], NewStatus: domain.OrderStatusException},
+	})
+	if err != nil {
+		t.Fatalf("UpdateOrderStatusBatch: %v", err)
+	}
+
+	wantErrors := []string{"", "", "đã hoàn thành hoặc đã hủy", "không tìm thấy đơn hàng", "thiếu trạng thái mới", "cần order_id hoặc tracking_number", ""}
+	for i, want := range wantErrors {
+		if want == "" && results[i].Error != "" || !strings.Contains(results[i].Error, want) {
+			t.Fatalf("kết quả %d: lỗi = %q, muốn %q", i, results[i].Error, want)
+		}
+	}
+	if results[1].OrderID != ids[1] || results[0].TrackingNumber != tracking[0] {
+		t.Fatalf("kết quả phải có cả order ID và số theo dõi: %+v", results[:2])
+	}
+
+	// Một lần tải và một lần lưu cho cả lô
+	if store.calls["GetEventsBatch"] != 1 || store.calls["SaveEventsBatch"] != 1 || store.calls["GetEvents"] != 0 || store.calls["SaveEvents"] != 0 {
+		t.Fatalf("số lần gọi event store = %v", store.calls)
+	}
+
+	// Cập nhật cùng đơn hàng được áp dụng lần lượt
+	order, err := repo.GetByID(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if order.Status != domain.OrderStatusException || order.CurrentLocation == nil || order.CurrentLocation.City != "Đà Nẵng" {
+		t.Fatalf("đơn hàng 1 = %+v", order)
+	}
+	history, err := service.GetOrderHistory(ctx, ids[0])
+	if err != nil || len(history) != 3 || history[2].GetVersion() != 3 {
+		t.Fatalf("lịch sử đơn hàng 1 = %d sự kiện, %v", len(history), err)
+	}
+
+	if order, _ := repo.GetByID(ctx, ids[1]); order.Status != domain.OrderStatusInTransit || len(order.Notes) != 1 {
+		t.Fatalf("đơn hàng 2 = %+v", order)
+	}
+
+	if _, err := service.UpdateOrderStatusBatch(ctx, make([]StatusUpdate, MaxStatusUpdateBatch+1)); err == nil {
+		t.Fatal("lô vượt quá giới hạn phải trả về lỗi")
+	}
+}
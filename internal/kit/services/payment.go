@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+// MaxRemittanceBatch là số đơn hàng tối đa trong một lần nộp tiền thu hộ
+const MaxRemittanceBatch = 1000
+
+// RemittanceResult là kết quả nộp tiền của một đơn hàng, Error rỗng nghĩa là thành công
+type RemittanceResult struct {
+	OrderID  string
+	Amount   decimal.Decimal
+	Currency string
+	Error    string
+}
+
+// PaymentService quản lý tiền thu hộ (COD) của đơn hàng và đối soát với tài xế
+type PaymentService interface {
+	// CollectPayment ghi nhận số tiền tài xế thu của người nhận khi giao hàng
+	CollectPayment(ctx context.Context, orderID, driverID string, amount decimal.Decimal) error
+
+	// RefundPayment ghi nhận đã hoàn tiền cho người nhận của đơn hàng đã thu tiền bị hủy
+	RefundPayment(ctx context.Context, orderID, reference string) error
+
+	// RemitPayments ghi nhận tài xế nộp tiền thu hộ của các đơn hàng theo một phiếu nộp tiền.
+	// orderIDs rỗng nghĩa là tất cả các khoản tài xế chưa nộp.
+	RemitPayments(ctx context.Context, driverID, reference string, orderIDs []string) ([]RemittanceResult, error)
+
+	// CODBalances lấy đối soát thu hộ theo tài xế và ngày thu (UTC) trong khoảng [from, to], nil nghĩa là không giới hạn
+	CODBalances(ctx context.Context, driverID string, from, to *time.Time, includeSettled bool) ([]projection.CODBalance, error)
+
+	// OutstandingCOD lấy các khoản tài xế đã thu nhưng chưa nộp
+	OutstandingCOD(ctx context.Context, driverID string) ([]projection.CODCollection, error)
+
+	// RebuildReconciliation phát lại toàn bộ sự kiện vào projection đối soát, trả về số sự kiện đã phát lại
+	RebuildReconciliation(ctx context.Context) (int, error)
+}
+
+type paymentService struct {
+	eventStore     eventstore.EventStore
+	eventBus       eventbus.EventBus
+	reconciliation projection.ReconciliationProjection
+	orderOpts      []domain.OrderOption
+}
+
+// NewPaymentService tạo service thu hộ, orderOpts giống như của OrderService
+func NewPaymentService(
+	eventStore eventstore.EventStore,
+	eventBus eventbus.EventBus,
+	reconciliation projection.ReconciliationProjection,
+	orderOpts ...domain.OrderOption,
+) PaymentService {
+	return &paymentService{
+		eventStore:     eventStore,
+		eventBus:       eventBus,
+		reconciliation: reconciliation,
+		orderOpts:      orderOpts,
+	}
+}
+
+// CollectPayment ghi nhận tiền thu hộ của đơn hàng
+func (s *paymentService) CollectPayment(ctx context.Context, orderID, driverID string, amount decimal.Decimal) error {
+	return s.execute(ctx, orderID, func(order *domain.Order) error {
+		if err := order.CollectPayment(driverID, amount); err != nil {
+			return fmt.Errorf("không thể thu tiền: %w", err)
+		}
+		return nil
+	})
+}
+
+// RefundPayment ghi nhận hoàn tiền của đơn hàng
+func (s *paymentService) RefundPayment(ctx context.Context, orderID, reference string) error {
+	return s.execute(ctx, orderID, func(order *domain.Order) error {
+		if err := order.RefundPayment(reference); err != nil {
+			return fmt.Errorf("không thể hoàn tiền: %w", err)
+		}
+		return nil
+	})
+}
+
+// execute xây dựng lại đơn hàng, áp dụng lệnh rồi lưu và phát các sự kiện mới
+func (s *paymentService) execute(ctx context.Context, orderID string, command func(order *domain.Order) error) error {
+	events, err := s.eventStore.GetEvents(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("không tìm thấy đơn hàng")
+	}
+
+	order := domain.RebuildFromEvents(events, s.orderOpts...)
+	if order == nil {
+		return fmt.Errorf("không thể xây dựng lại đơn hàng từ sự kiện")
+	}
+
+	if err := command(order); err != nil {
+		return err
+	}
+
+	if err := s.eventStore.SaveEvents(ctx, order.ID, order.GetUncommittedEvents()); err != nil {
+		return fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+	}
+
+	s.publish(order.GetUncommittedEvents())
+	order.ClearUncommittedEvents()
+
+	return nil
+}
+
+// RemitPayments nộp tiền thu hộ của nhiều đơn hàng, sự kiện được tải và lưu theo lô.
+// Đơn hàng lỗi không ảnh hưởng tới các đơn hàng khác.
+func (s *paymentService) RemitPayments(ctx context.Context, driverID, reference string, orderIDs []string) ([]RemittanceResult, error) {
+	if driverID == "" {
+		return nil, errors.New("driver ID không được để trống")
+	}
+
+	if len(orderIDs) == 0 {
+		outstanding, err := s.reconciliation.Outstanding(ctx, driverID)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi lấy tiền thu hộ chưa nộp: %w", err)
+		}
+		for _, collection := range outstanding {
+			orderIDs = append(orderIDs, collection.OrderID)
+		}
+	}
+	if len(orderIDs) > MaxRemittanceBatch {
+		return nil, fmt.Errorf("tối đa %d đơn hàng trong một lần nộp tiền", MaxRemittanceBatch)
+	}
+
+	streams, err := s.eventStore.GetEventsBatch(ctx, orderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	results := make([]RemittanceResult, len(orderIDs))
+	seen := make(map[string]bool, len(orderIDs))
+	var events []domain.Event
+	var remitted []int
+	for i, orderID := range orderIDs {
+		results[i].OrderID = orderID
+		if seen[orderID] {
+			results[i].Error = "đơn hàng bị lặp lại"
+			continue
+		}
+		seen[orderID] = true
+
+		order := domain.RebuildFromEvents(streams[orderID], s.orderOpts...)
+		if order == nil {
+			results[i].Error = "không tìm thấy đơn hàng"
+			continue
+		}
+		if err := order.RemitPayment(driverID, reference); err != nil {
+			results[i].Error = fmt.Sprintf("không thể nộp tiền: %v", err)
+			continue
+		}
+
+		results[i].Amount = order.Payment.Collected
+		results[i].Currency = order.Payment.Currency
+		events = append(events, order.GetUncommittedEvents()...)
+		remitted = append(remitted, i)
+	}
+
+	// Lưu sự kiện của tất cả đơn hàng trong một transaction
+	if err := s.eventStore.SaveEventsBatch(ctx, events); err != nil {
+		for _, i := range remitted {
+			results[i].Error = fmt.Sprintf("lỗi khi lưu sự kiện: %v", err)
+		}
+		return results, nil
+	}
+
+	s.publish(events)
+
+	return results, nil
+}
+
+// CODBalances lấy đối soát thu hộ, cũ nhất trước
+func (s *paymentService) CODBalances(ctx context.Context, driverID string, from, to *time.Time, includeSettled bool) ([]projection.CODBalance, error) {
+	filter := projection.CODBalanceFilter{DriverID: driverID, IncludeSettled: includeSettled}
+	if from != nil {
+		filter.From = from.UTC().Format(projection.DayLayout)
+	}
+	if to != nil {
+		filter.To = to.UTC().Format(projection.DayLayout)
+	}
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		return nil, fmt.Errorf("%w: from phải trước hoặc bằng to", ErrInvalidRecord)
+	}
+	return s.reconciliation.Balances(ctx, filter)
+}
+
+// OutstandingCOD lấy các khoản tài xế chưa nộp, cũ nhất trước
+func (s *paymentService) OutstandingCOD(ctx context.Context, driverID string) ([]projection.CODCollection, error) {
+	if driverID == "" {
+		return nil, fmt.Errorf("%w: driver ID không được để trống", ErrInvalidRecord)
+	}
+	return s.reconciliation.Outstanding(ctx, driverID)
+}
+
+// RebuildReconciliation phát lại sự kiện theo thứ tự thời gian, sự kiện đã áp dụng được projection bỏ qua
+func (s *paymentService) RebuildReconciliation(ctx context.Context) (int, error) {
+	replayed := 0
+	for {
+		events, err := s.eventStore.GetAllEvents(ctx, replayed, analyticsRebuildBatch)
+		if err != nil {
+			return replayed, fmt.Errorf("lỗi khi lấy sự kiện: %w", err)
+		}
+
+		for _, event := range events {
+			if err := s.reconciliation.HandleEvent(event); err != nil {
+				return replayed, fmt.Errorf("lỗi khi cập nhật projection đối soát: %w", err)
+			}
+			replayed++
+		}
+
+		if len(events) < analyticsRebuildBatch {
+			return replayed, nil
+		}
+	}
+}
+
+func (s *paymentService) publish(events []domain.Event) {
+	for _, event := range events {
+		if err := s.eventBus.Publish(event); err != nil {
+			fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+}
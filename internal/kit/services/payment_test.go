@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/shopspring/decimal"
+)
+
+func TestPaymentService(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{EventStore: eventstore.NewInMemoryEventStore(), calls: map[string]int{}}
+	repo := repository.NewInMemoryOrderRepository()
+	reconciliation := projection.NewInMemoryReconciliationProjection()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := bus.Subscribe(reconciliation, domain.PaymentCollectedType, domain.PaymentRemittedType); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := domaintest.Options()
+	orders := NewOrderService(store, repo, bus, opts...)
+	payments := NewPaymentService(store, bus, reconciliation, opts...)
+	fleet := NewFleetService(store, repo, bus, opts...)
+	driverID := registerDriver(t, fleet, decimal.NewFromInt(100))
+
+	items := []domain.OrderItem{{ID: "ITEM-1", Quantity: 2, Price: decimal.NewFromInt(60000)}}
+	cod := domain.PaymentTerms{Method: domain.PaymentMethodCOD}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, items, "", cod)
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		if err := fleet.AssignOrder(ctx, id, driverID); err != nil {
+			t.Fatalf("AssignOrder: %v", err)
+		}
+		if err := orders.UpdateOrderStatus(ctx, id, domain.OrderStatusOutForDelivery, nil, ""); err != nil {
+			t.Fatalf("UpdateOrderStatus: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	prepaid, _, err := orders.CreateOrder(ctx, "CUS-001", domain.Location{}, domain.Location{}, items, "", domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	order, err := orders.GetOrder(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.Payment == nil || order.Payment.Status != domain.PaymentStatusPending || !order.Payment.Amount.Equal(decimal.NewFromInt(120000)) {
+		t.Fatalf("Payment = %+v", order.Payment)
+	}
+
+	// Tài xế không được phân công không được thu tiền của đơn hàng
+	if err := payments.CollectPayment(ctx, ids[0], "DRV-002", decimal.NewFromInt(120000)); err == nil || !strings.Contains(err.Error(), "không được phân công") {
+		t.Fatalf("CollectPayment bởi tài xế khác: err = %v", err)
+	}
+	for _, id := range ids {
+		if err := payments.CollectPayment(ctx, id, driverID, decimal.NewFromInt(120000)); err != nil {
+			t.Fatalf("CollectPayment %s: %v", id, err)
+		}
+	}
+	if err := payments.CollectPayment(ctx, prepaid, driverID, decimal.NewFromInt(1)); err == nil || !strings.Contains(err.Error(), "không thu hộ") {
+		t.Fatalf("CollectPayment đơn trả trước: err = %v", err)
+	}
+
+	// Đơn hàng đã thu tiền bị hủy cần hoàn tiền nhưng tài xế vẫn phải nộp
+	if err := orders.CancelOrder(ctx, ids[2], "Người nhận trả hàng"); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if err := payments.RefundPayment(ctx, ids[2], "RF-0001"); err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+	if err := payments.RefundPayment(ctx, ids[1], ""); err == nil {
+		t.Fatalf("RefundPayment đơn hàng chưa hủy phải lỗi")
+	}
+
+	outstanding, err := payments.OutstandingCOD(ctx, driverID)
+	if err != nil {
+		t.Fatalf("OutstandingCOD: %v", err)
+	}
+	if len(outstanding) != 3 {
+		t.Fatalf("OutstandingCOD = %+v", outstanding)
+	}
+
+	// Nộp một đơn hàng cụ thể, đơn hàng của tài xế khác và đơn hàng lặp lại bị từ chối
+	for key := range store.calls {
+		delete(store.calls, key)
+	}
+	results, err := payments.RemitPayments(ctx, driverID, "PN-0001", []string{ids[0], ids[0], "missing"})
+	if err != nil {
+		t.Fatalf("RemitPayments: %v", err)
+	}
+	if results[0].Error != "" || !results[0].Amount.Equal(decimal.NewFromInt(120000)) || results[0].Currency != "VND" ||
+		results[1].Error == "" || results[2].Error != "không tìm thấy đơn hàng" {
+		t.Fatalf("results = %+v", results)
+	}
+	if store.calls["GetEventsBatch"] != 1 || store.calls["SaveEventsBatch"] != 1 || store.calls["GetEvents"] != 0 {
+		t.Fatalf("calls = %v, muốn tải và lưu theo lô", store.calls)
+	}
+	if results, _ := payments.RemitPayments(ctx, "DRV-002", "", []string{ids[1]}); results[0].Error == "" {
+		t.Fatalf("tài xế khác không được nộp tiền, results = %+v", results)
+	}
+
+	// Không truyền đơn hàng thì nộp tất cả các khoản còn lại
+	results, err = payments.RemitPayments(ctx, driverID, "PN-0002", nil)
+	if err != nil {
+		t.Fatalf("RemitPayments: %v", err)
+	}
+	if len(results) != 2 || results[0].Error != "" || results[1].Error != "" {
+		t.Fatalf("results = %+v", results)
+	}
+
+	balances, err := payments.CODBalances(ctx, driverID, nil, nil, true)
+	if err != nil {
+		t.Fatalf("CODBalances: %v", err)
+	}
+	if len(balances) != 1 || balances[0].CollectedOrders != 3 || balances[0].RemittedOrders != 3 || !balances[0].Outstanding().IsZero() {
+		t.Fatalf("balances = %+v", balances)
+	}
+	if balances, _ := payments.CODBalances(ctx, driverID, nil, nil, false); len(balances) != 0 {
+		t.Fatalf("đã nộp đủ thì không còn số dư, balances = %+v", balances)
+	}
+
+	order, err = orders.GetOrder(ctx, ids[2])
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.Payment.Status != domain.PaymentStatusRefunded || order.Payment.RemittedAt == nil {
+		t.Fatalf("Payment = %+v", order.Payment)
+	}
+
+	from, to := domaintest.Now, domaintest.Now.AddDate(0, 0, -1)
+	if _, err := payments.CODBalances(ctx, "", &from, &to, false); !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("CODBalances from > to: err = %v", err)
+	}
+
+	// Phát lại toàn bộ sự kiện cho kết quả như cũ
+	rebuilt := projection.NewInMemoryReconciliationProjection()
+	replayed, err := NewPaymentService(store, bus, rebuilt, opts...).RebuildReconciliation(ctx)
+	if err != nil || replayed == 0 {
+		t.Fatalf("RebuildReconciliation = %d, %v", replayed, err)
+	}
+	if balances, _ := rebuilt.Balances(ctx, projection.CODBalanceFilter{IncludeSettled: true}); len(balances) != 1 || balances[0].RemittedOrders != 3 {
+		t.Fatalf("balances sau khi phát lại = %+v", balances)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+// DistanceTier là phí cơ bản cho quãng đường tới UpToKm, UpToKm bằng 0 là không giới hạn
+type DistanceTier struct {
+	UpToKm float64         `json:"up_to_km"`
+	Fee    decimal.Decimal `json:"fee"`
+}
+
+// WeightTier là phụ phí cho tổng khối lượng tới UpToKg
+type WeightTier struct {
+	UpToKg decimal.Decimal `json:"up_to_kg"`
+	Fee    decimal.Decimal `json:"fee"`
+}
+
+// PricingRules là cấu hình tính phí vận chuyển:
+// phí = (phí theo quãng đường + phụ phí theo khối lượng) × hệ số mức dịch vụ + phí bảo hiểm.
+// Các bậc được sắp xếp tăng dần, đơn hàng dùng bậc đầu tiên chứa giá trị của nó.
+type PricingRules struct {
+	Currency      string         `json:"currency"` // tiền tệ của phí, cũng là tiền tệ của đơn hàng mới
+	DistanceTiers []DistanceTier `json:"distance_tiers"`
+	WeightTiers   []WeightTier   `json:"weight_tiers"`
+
+	// ExtraPerKg tính cho mỗi kg (làm tròn lên) vượt bậc khối lượng cuối cùng
+	ExtraPerKg decimal.Decimal `json:"extra_per_kg"`
+
+	ServiceLevels map[domain.ServiceLevel]decimal.Decimal `json:"service_levels"` // hệ số nhân theo mức dịch vụ, mặc định 1
+
+	// Phí bảo hiểm bằng InsuranceRate × giá trị khai báo khi giá trị khai báo vượt InsuranceThreshold
+	InsuranceRate      decimal.Decimal `json:"insurance_rate"`
+	InsuranceThreshold decimal.Decimal `json:"insurance_threshold"`
+
+	// UnknownDistanceKm là quãng đường giả định khi điểm gửi hoặc điểm nhận không có tọa độ
+	UnknownDistanceKm float64 `json:"unknown_distance_km"`
+}
+
+// DefaultPricingRules trả về bảng phí mặc định cho vận chuyển đường bộ nội địa
+func DefaultPricingRules() PricingRules {
+	return PricingRules{
+		Currency: domain.DefaultCurrency,
+		DistanceTiers: []DistanceTier{
+			{UpToKm: 20, Fee: decimal.NewFromInt(15000)},
+			{UpToKm: 100, Fee: decimal.NewFromInt(22000)},
+			{UpToKm: 300, Fee: decimal.NewFromInt(25000)},
+			{UpToKm: 800, Fee: decimal.NewFromInt(30000)},
+			{Fee: decimal.NewFromInt(35000)},
+		},
+		WeightTiers: []WeightTier{
+			{UpToKg: decimal.RequireFromString("0.5"), Fee: decimal.Zero},
+			{UpToKg: decimal.NewFromInt(1), Fee: decimal.NewFromInt(5000)},
+			{UpToKg: decimal.NewFromInt(2), Fee: decimal.NewFromInt(10000)},
+			{UpToKg: decimal.NewFromInt(5), Fee: decimal.NewFromInt(25000)},
+		},
+		ExtraPerKg: decimal.NewFromInt(5000),
+		ServiceLevels: map[domain.ServiceLevel]decimal.Decimal{
+			domain.ServiceLevelStandard: decimal.NewFromInt(1),
+			domain.ServiceLevelExpress:  decimal.RequireFromString("1.5"),
+		},
+		InsuranceRate:      decimal.RequireFromString("0.005"),
+		InsuranceThreshold: decimal.NewFromInt(1000000),
+		UnknownDistanceKm:  300,
+	}
+}
+
+// LoadPricingRules đọc cấu hình từ file JSON, các mục không khai báo giữ giá trị mặc định.
+// Danh sách bậc được thay thế toàn bộ, hệ số mức dịch vụ được ghi đè theo từng mức.
+func LoadPricingRules(path string) (PricingRules, error) {
+	rules := DefaultPricingRules()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, fmt.Errorf("lỗi khi đọc cấu hình phí vận chuyển: %w", err)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return DefaultPricingRules(), fmt.Errorf("lỗi khi đọc cấu hình phí vận chuyển: %w", err)
+	}
+	if err := rules.validate(); err != nil {
+		return DefaultPricingRules(), err
+	}
+
+	return rules, nil
+}
+
+// validate kiểm tra tiền tệ, thứ tự các bậc và giá trị không âm
+func (r PricingRules) validate() error {
+	if !domain.ValidCurrency(r.Currency) {
+		return fmt.Errorf("mã tiền tệ không hợp lệ trong cấu hình phí vận chuyển: %s", r.Currency)
+	}
+	if len(r.DistanceTiers) == 0 {
+		return fmt.Errorf("cấu hình phí vận chuyển cần ít nhất một bậc quãng đường")
+	}
+	for i, tier := range r.DistanceTiers {
+		last := i == len(r.DistanceTiers)-1
+		if tier.Fee.IsNegative() || (!last && tier.UpToKm <= 0) || (i > 0 && tier.UpToKm != 0 && tier.UpToKm <= r.DistanceTiers[i-1].UpToKm) {
+			return fmt.Errorf("bậc quãng đường %d không hợp lệ, cần tăng dần và phí không âm", i+1)
+		}
+	}
+	for i, tier := range r.WeightTiers {
+		if tier.Fee.IsNegative() || !tier.UpToKg.IsPositive() || (i > 0 && tier.UpToKg.LessThanOrEqual(r.WeightTiers[i-1].UpToKg)) {
+			return fmt.Errorf("bậc khối lượng %d không hợp lệ, cần tăng dần và phí không âm", i+1)
+		}
+	}
+	for level, multiplier := range r.ServiceLevels {
+		if !level.Valid() {
+			return fmt.Errorf("mức dịch vụ không hợp lệ trong cấu hình phí vận chuyển: %s", level)
+		}
+		if !multiplier.IsPositive() {
+			return fmt.Errorf("hệ số phí của %s phải lớn hơn 0", level)
+		}
+	}
+	if r.ExtraPerKg.IsNegative() || r.InsuranceRate.IsNegative() || r.InsuranceThreshold.IsNegative() {
+		return fmt.Errorf("phí theo kg và phí bảo hiểm không được âm")
+	}
+	return nil
+}
+
+// PricingService tính phí vận chuyển theo bậc quãng đường và khối lượng
+type PricingService struct {
+	rules PricingRules
+}
+
+// NewPricingService tạo PricingService với cấu hình cho trước
+func NewPricingService(rules PricingRules) *PricingService {
+	return &PricingService{rules: rules}
+}
+
+// Currency là tiền tệ của bảng phí
+func (s *PricingService) Currency() string {
+	return s.rules.Currency
+}
+
+// CalculateShippingFee triển khai domain.ShippingFeeCalculator, đơn hàng khác tiền tệ của bảng phí không được tính
+func (s *PricingService) CalculateShippingFee(order *domain.Order) (domain.ShippingQuote, bool) {
+	if order.Currency != s.rules.Currency {
+		return domain.ShippingQuote{}, false
+	}
+
+	distance := s.distanceKm(order)
+	weight := order.Totals.TotalWeight
+
+	fee := s.distanceFee(distance).Add(s.weightFee(weight))
+	if multiplier, ok := s.rules.ServiceLevels[order.ServiceLevel]; ok {
+		fee = fee.Mul(multiplier)
+	}
+	if declared := order.Totals.DeclaredValue; declared.GreaterThan(s.rules.InsuranceThreshold) {
+		fee = fee.Add(declared.Mul(s.rules.InsuranceRate))
+	}
+
+	return domain.ShippingQuote{
+		Fee:              fee.Round(domain.CurrencyDecimals(s.rules.Currency)),
+		Currency:         s.rules.Currency,
+		ChargeableWeight: weight,
+		DistanceKm:       distance,
+	}, true
+}
+
+// distanceKm là quãng đường đường chim bay từ điểm gửi tới điểm nhận, làm tròn 0.1 km
+func (s *PricingService) distanceKm(order *domain.Order) float64 {
+	if !order.Origin.HasCoordinates() || !order.Destination.HasCoordinates() {
+		return s.rules.UnknownDistanceKm
+	}
+	return math.Round(geo.DistanceKm(order.Origin.Point(), order.Destination.Point())*10) / 10
+}
+
+// distanceFee là phí của bậc quãng đường đầu tiên chứa distance, vượt mọi bậc thì dùng bậc cuối
+func (s *PricingService) distanceFee(distance float64) decimal.Decimal {
+	for _, tier := range s.rules.DistanceTiers {
+		if tier.UpToKm == 0 || distance <= tier.UpToKm {
+			return tier.Fee
+		}
+	}
+	return s.rules.DistanceTiers[len(s.rules.DistanceTiers)-1].Fee
+}
+
+// weightFee là phụ phí của bậc khối lượng đầu tiên chứa weight,
+// vượt bậc cuối thì cộng ExtraPerKg cho mỗi kg vượt (làm tròn lên)
+func (s *PricingService) weightFee(weight decimal.Decimal) decimal.Decimal {
+	tiers := s.rules.WeightTiers
+	if len(tiers) == 0 {
+		return s.rules.ExtraPerKg.Mul(weight.Ceil())
+	}
+	for _, tier := range tiers {
+		if weight.LessThanOrEqual(tier.UpToKg) {
+			return tier.Fee
+		}
+	}
+	last := tiers[len(tiers)-1]
+	return last.Fee.Add(s.rules.ExtraPerKg.Mul(weight.Sub(last.UpToKg).Ceil()))
+}
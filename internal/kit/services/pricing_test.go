@@ -0,0 +1,114 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// pricedOrder là đơn hàng VND từ TP.HCM đi Hà Nội (khoảng 1140 km) với tổng khối lượng và giá trị cho trước
+func pricedOrder(level domain.ServiceLevel, weight, value string) *domain.Order {
+	order := etaOrder(level, domain.OrderStatusCreated)
+	order.Currency = domain.DefaultCurrency
+	order.Totals = domain.CalculateTotals([]domain.OrderItem{
+		{Quantity: 1, Weight: decimal.RequireFromString(weight), Price: decimal.RequireFromString(value)},
+	})
+	return order
+}
+
+func TestPricingServiceCalculateShippingFee(t *testing.T) {
+	pricing := NewPricingService(DefaultPricingRules())
+
+	for name, tc := range map[string]struct {
+		order *domain.Order
+		fee   int64
+	}{
+		// 35.000 (trên 800 km) + 5.000 (tới 1 kg)
+		"standard": {pricedOrder(domain.ServiceLevelStandard, "1", "200000"), 40000},
+		"express":  {pricedOrder(domain.ServiceLevelExpress, "1", "200000"), 60000},
+		// 25.000 (tới 5 kg) + 3 kg vượt × 5.000
+		"heavy": {pricedOrder(domain.ServiceLevelStandard, "7.2", "200000"), 75000},
+		// Bảo hiểm 0,5% giá trị khai báo trên 1.000.000
+		"insured": {pricedOrder(domain.ServiceLevelStandard, "0.3", "3000000"), 50000},
+	} {
+		t.Run(name, func(t *testing.T) {
+			quote, ok := pricing.CalculateShippingFee(tc.order)
+			if !ok {
+				t.Fatal("không tính được phí vận chuyển")
+			}
+			if !quote.Fee.Equal(decimal.NewFromInt(tc.fee)) || quote.Currency != domain.DefaultCurrency {
+				t.Fatalf("phí = %s %s, muốn %d", quote.Fee, quote.Currency, tc.fee)
+			}
+			if quote.DistanceKm < 1130 || quote.DistanceKm > 1150 || !quote.ChargeableWeight.Equal(tc.order.Totals.TotalWeight) {
+				t.Fatalf("quote = %+v", quote)
+			}
+		})
+	}
+
+	// Thiếu tọa độ thì dùng quãng đường giả định 300 km
+	unknown := pricedOrder(domain.ServiceLevelStandard, "0.5", "100000")
+	unknown.Destination = domain.Location{City: "Hà Nội"}
+	if quote, _ := pricing.CalculateShippingFee(unknown); !quote.Fee.Equal(decimal.NewFromInt(25000)) || quote.DistanceKm != 300 {
+		t.Fatalf("quote khi thiếu tọa độ = %+v", quote)
+	}
+
+	foreign := pricedOrder(domain.ServiceLevelStandard, "1", "10")
+	foreign.Currency = "USD"
+	if _, ok := pricing.CalculateShippingFee(foreign); ok {
+		t.Fatal("đơn hàng khác tiền tệ của bảng phí không được tính phí")
+	}
+}
+
+func TestLoadPricingRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pricing.json")
+	override := `{
+		"currency": "USD",
+		"distance_tiers": [{"up_to_km": 50, "fee": "4.99"}, {"fee": 9.5}],
+		"service_levels": {"EXPRESS": "2"}
+	}`
+	if err := os.WriteFile(path, []byte(override), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadPricingRules(path)
+	if err != nil {
+		t.Fatalf("LoadPricingRules: %v", err)
+	}
+	if rules.Currency != "USD" || len(rules.DistanceTiers) != 2 || !rules.DistanceTiers[1].Fee.Equal(decimal.RequireFromString("9.5")) {
+		t.Fatalf("rules = %+v", rules)
+	}
+	if !rules.ServiceLevels[domain.ServiceLevelExpress].Equal(decimal.NewFromInt(2)) || !rules.ServiceLevels[domain.ServiceLevelStandard].Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("ServiceLevels = %v", rules.ServiceLevels)
+	}
+	if len(rules.WeightTiers) != len(DefaultPricingRules().WeightTiers) {
+		t.Fatalf("bậc khối lượng không khai báo phải giữ mặc định, có %d bậc", len(rules.WeightTiers))
+	}
+
+	// Phí USD được làm tròn tới cent
+	order := pricedOrder(domain.ServiceLevelExpress, "0.1", "10")
+	order.Currency = "USD"
+	order.Destination = domain.Location{City: "Thủ Đức", Latitude: 10.85, Longitude: 106.77}
+	quote, ok := NewPricingService(rules).CalculateShippingFee(order)
+	if !ok || quote.Fee.String() != "9.98" {
+		t.Fatalf("quote = %+v, muốn 9.98", quote)
+	}
+
+	for name, content := range map[string]string{
+		"currency":  `{"currency": "dong"}`,
+		"order":     `{"distance_tiers": [{"up_to_km": 100, "fee": 1}, {"up_to_km": 50, "fee": 2}]}`,
+		"negative":  `{"weight_tiers": [{"up_to_kg": 1, "fee": -1}]}`,
+		"level":     `{"service_levels": {"OVERNIGHT": 3}}`,
+		"malformed": `{"extra_per_kg": "abc"}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPricingRules(path); err == nil {
+			t.Fatalf("%s: cấu hình không hợp lệ phải trả về lỗi", name)
+		}
+	}
+}
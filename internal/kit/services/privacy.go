@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+)
+
+// PrivacyService xử lý các yêu cầu về quyền riêng tư của khách hàng
+type PrivacyService interface {
+	// ErasePersonalData xóa khóa dữ liệu của khách hàng khiến dữ liệu cá nhân trong các sự kiện
+	// không thể đọc được nữa, sau đó xây dựng lại read model và ẩn dữ liệu trong các projection
+	// của các đơn hàng liên quan.
+	// Trả về số đơn hàng đã được xây dựng lại.
+	ErasePersonalData(ctx context.Context, customerID string) (int, error)
+}
+
+// privacyService triển khai PrivacyService
+type privacyService struct {
+	eventStore  eventstore.EventStore
+	keyStore    eventstore.KeyStore
+	orderRepo   repository.OrderRepository
+	projections []projection.Redactor
+	orderOpts   []domain.OrderOption
+}
+
+// NewPrivacyService tạo một instance mới của PrivacyService.
+// projections là các projection giữ bản sao dữ liệu của đơn hàng, orderOpts giống như của OrderService.
+func NewPrivacyService(
+	eventStore eventstore.EventStore,
+	keyStore eventstore.KeyStore,
+	orderRepo repository.OrderRepository,
+	projections []projection.Redactor,
+	orderOpts ...domain.OrderOption,
+) PrivacyService {
+	return &privacyService{
+		eventStore:  eventStore,
+		keyStore:    keyStore,
+		orderRepo:   orderRepo,
+		projections: projections,
+		orderOpts:   orderOpts,
+	}
+}
+
+// ErasePersonalData xóa dữ liệu cá nhân của khách hàng bằng cách hủy khóa dữ liệu
+func (s *privacyService) ErasePersonalData(ctx context.Context, customerID string) (int, error) {
+	if customerID == "" {
+		return 0, fmt.Errorf("customer ID không được để trống")
+	}
+
+	aggregateIDs, err := s.keyStore.AggregatesOf(ctx, customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.keyStore.Shred(ctx, customerID); err != nil {
+		return 0, err
+	}
+
+	// Xây dựng lại read model để thay dữ liệu cá nhân bằng giá trị đã ẩn
+	for _, aggregateID := range aggregateIDs {
+		events, err := s.eventStore.GetEvents(ctx, aggregateID)
+		if err != nil {
+			return 0, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+		}
+
+		order := domain.RebuildFromEvents(events, s.orderOpts...)
+		if order == nil {
+			continue
+		}
+
+		if err := s.orderRepo.Save(ctx, order); err != nil {
+			return 0, fmt.Errorf("lỗi khi xây dựng lại đơn hàng %s: %w", aggregateID, err)
+		}
+	}
+
+	// Projection không đọc lại sự kiện đã áp dụng nên dữ liệu được ẩn trực tiếp
+	for _, p := range s.projections {
+		if err := p.Redact(ctx, aggregateIDs); err != nil {
+			return 0, fmt.Errorf("lỗi khi ẩn dữ liệu trong projection: %w", err)
+		}
+	}
+
+	return len(aggregateIDs), nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+func TestPrivacyServiceErasePersonalData(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := eventstore.NewSQLiteEventStore(ctx, "file:privacy_erase?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("NewSQLiteEventStore: %v", err)
+	}
+	defer sqlite.Close()
+	keys := eventstore.NewInMemoryKeyStore()
+	store, err := eventstore.NewPostgresEventStoreWithSerializers(sqlite.DB(), eventstore.FormatJSON,
+		eventstore.EncryptSerializers(eventstore.DefaultSerializers(eventstore.DefaultUpcasters()), keys))
+	if err != nil {
+		t.Fatalf("NewPostgresEventStoreWithSerializers: %v", err)
+	}
+
+	repo := repository.NewInMemoryOrderRepository()
+	tracking := projection.NewInMemoryTrackingProjection()
+	analytics := projection.NewInMemoryAnalyticsProjection()
+	locations := projection.NewInMemoryLocationProjection()
+	bus := eventbus.NewInMemoryEventBus()
+	for _, handler := range []eventbus.EventHandler{repo, tracking, analytics} {
+		if err := bus.Subscribe(handler, domain.EventTypesOf(domain.AggregateOrder)...); err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+	}
+	opts := domaintest.Options()
+	orders := NewOrderService(store, repo, bus, opts...)
+	privacy := NewPrivacyService(store, keys, repo, []projection.Redactor{tracking, analytics, locations}, opts...)
+
+	origin := domain.Location{Address: "12 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destination := domain.Location{Address: "1 Tràng Tiền", City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+	hub := domain.Location{Address: "Kho Đà Nẵng", City: "Đà Nẵng"}
+	items := []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}}
+
+	var ids []string
+	for _, customerID := range []string{"CUS-001", "CUS-002"} {
+		id, _, err := orders.CreateOrder(ctx, customerID, origin, destination, items, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		if err := orders.UpdateOrderStatus(ctx, id, domain.OrderStatusInTransit, &hub, "Gọi 0901234567"); err != nil {
+			t.Fatalf("UpdateOrderStatus: %v", err)
+		}
+		if err := orders.CancelOrder(ctx, id, "Chị Lan đổi địa chỉ"); err != nil {
+			t.Fatalf("CancelOrder: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	point := geo.Point{Latitude: 16.0544, Longitude: 108.2022}
+	err = locations.Save(ctx, projection.DriverLocation{DriverID: "DRV-001", Point: point, RecordedAt: domaintest.Now, ReceivedAt: domaintest.Now}, []projection.OrderLocation{
+		{OrderID: ids[0], DriverID: "DRV-001", Point: point, RecordedAt: domaintest.Now},
+		{OrderID: ids[1], DriverID: "DRV-001", Point: point, RecordedAt: domaintest.Now},
+	})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if _, err := privacy.ErasePersonalData(ctx, ""); err == nil {
+		t.Fatal("ErasePersonalData với customer ID rỗng phải lỗi")
+	}
+	if erased, err := privacy.ErasePersonalData(ctx, "CUS-001"); err != nil || erased != 1 {
+		t.Fatalf("ErasePersonalData = %d, %v", erased, err)
+	}
+
+	erased, err := repo.GetByID(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if erased.CustomerID != domain.RedactedValue || erased.Destination.Address != domain.RedactedValue || erased.Status != domain.OrderStatusCancelled {
+		t.Fatalf("đơn hàng sau khi xóa dữ liệu = %+v", erased)
+	}
+
+	info, err := tracking.GetByTrackingNumber(ctx, erased.TrackingNumber)
+	if err != nil {
+		t.Fatalf("GetByTrackingNumber: %v", err)
+	}
+	if info.OriginCity != domain.RedactedValue || info.DestinationCity != domain.RedactedValue || info.CurrentCity != domain.RedactedValue {
+		t.Fatalf("thông tin theo dõi sau khi xóa dữ liệu = %+v", info)
+	}
+	for i, update := range info.Updates {
+		if update.Location != "" && update.Location != domain.RedactedValue {
+			t.Fatalf("Updates[%d] = %+v", i, update)
+		}
+	}
+
+	cancellations, err := analytics.Cancellations(ctx)
+	if err != nil {
+		t.Fatalf("Cancellations: %v", err)
+	}
+	reasons := map[string]int{}
+	for _, reason := range cancellations.Reasons {
+		reasons[reason.Reason] = reason.Orders
+	}
+	if cancellations.Cancelled != 2 || reasons[domain.RedactedValue] != 1 || reasons["Chị Lan đổi địa chỉ"] != 1 {
+		t.Fatalf("Cancellations = %+v", cancellations)
+	}
+
+	located, err := locations.OrderLocations(ctx, ids)
+	if err != nil {
+		t.Fatalf("OrderLocations: %v", err)
+	}
+	if _, ok := located[ids[0]]; ok || len(located) != 1 {
+		t.Fatalf("OrderLocations = %+v", located)
+	}
+
+	// Đơn hàng của khách hàng khác giữ nguyên dữ liệu
+	other, err := repo.GetByID(ctx, ids[1])
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if other.CustomerID != "CUS-002" || other.Destination.Address != destination.Address {
+		t.Fatalf("đơn hàng của CUS-002 = %+v", other)
+	}
+	if info, err := tracking.GetByTrackingNumber(ctx, other.TrackingNumber); err != nil || info.OriginCity != origin.City {
+		t.Fatalf("thông tin theo dõi của CUS-002 = %+v, %v", info, err)
+	}
+}
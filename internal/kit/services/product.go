@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/quyenle-97/init/pkgs/utils"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// productReferenceAttempts là số lần thử lại khi mã sản phẩm bị trùng do tạo đồng thời
+const productReferenceAttempts = 3
+
+// ProductInput là dữ liệu tạo hoặc ghi đè sản phẩm
+type ProductInput struct {
+	Name       string
+	Price      decimal.Decimal
+	Quantity   int
+	Weight     float64 // kg
+	Status     models.ModelStatus
+	Categories []string // ID danh mục
+	CityID     string
+	SupplierID string
+}
+
+// ProductFilter là bộ lọc danh sách sản phẩm, trường rỗng không lọc
+type ProductFilter struct {
+	References []string
+	Names      []string // tên chứa một trong các chuỗi, không phân biệt hoa thường
+	AddFrom    *time.Time
+	AddTo      *time.Time
+	Statuses   []models.ModelStatus
+	Categories []string // thuộc ít nhất một danh mục
+	Cities     []string
+	Offset     int
+	Limit      int
+}
+
+// DistanceFrom là điểm bắt đầu tính khoảng cách: tọa độ, hoặc thành phố trong bảng cities khi Point nil
+type DistanceFrom struct {
+	Point  *geo.Point
+	CityID string
+}
+
+// ProductDistance là khoảng cách đường chim bay từ một điểm tới kho nhà cung cấp của sản phẩm
+type ProductDistance struct {
+	ProductID  string
+	From       models.GEO
+	To         models.GEO // vị trí kho nhà cung cấp
+	DistanceKm float64
+}
+
+// ProductService quản lý sản phẩm
+type ProductService interface {
+	ListProducts(ctx context.Context, filter ProductFilter) ([]models.Product, error)
+	GetProduct(ctx context.Context, id string) (*models.Product, error)
+	CreateProduct(ctx context.Context, input ProductInput) (*models.Product, error)
+	UpdateProduct(ctx context.Context, id string, input ProductInput) (*models.Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	ProductDistance(ctx context.Context, id string, from DistanceFrom) (*ProductDistance, error)
+}
+
+type productService struct {
+	db     *bun.DB
+	cities CityService
+}
+
+// NewProductService tạo service sản phẩm, cities dùng để tra tọa độ thành phố khi tính khoảng cách
+func NewProductService(db *bun.DB, cities CityService) ProductService {
+	return &productService{db: db, cities: cities}
+}
+
+// ListProducts lấy danh sách sản phẩm kèm danh mục, thành phố và nhà cung cấp, mới nhất trước
+func (s *productService) ListProducts(ctx context.Context, filter ProductFilter) ([]models.Product, error) {
+	offset, limit := catalogPage(filter.Offset, filter.Limit)
+	products := make([]models.Product, 0)
+	query := s.productQuery(&products).
+		OrderExpr("p.created_time DESC, p.id ASC").
+		Offset(offset).
+		Limit(limit)
+
+	if len(filter.References) > 0 {
+		query = query.Where("p.reference IN (?)", bun.In(filter.References))
+	}
+	if len(filter.Names) > 0 {
+		query = query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			for _, name := range filter.Names {
+				q = q.WhereOr(`LOWER(p.name) LIKE ? ESCAPE '!'`, containsPattern(name))
+			}
+			return q
+		})
+	}
+	if filter.AddFrom != nil {
+		query = query.Where("p.created_time >= ?", *filter.AddFrom)
+	}
+	if filter.AddTo != nil {
+		query = query.Where("p.created_time <= ?", *filter.AddTo)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("p.status IN (?)", bun.In(filter.Statuses))
+	}
+	if len(filter.Categories) > 0 {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM product_categories AS fpc WHERE fpc.product_id = p.id AND fpc.category_id IN (?))",
+			bun.In(filter.Categories),
+		)
+	}
+	if len(filter.Cities) > 0 {
+		query = query.Where("p.city_id IN (?)", bun.In(filter.Cities))
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn sản phẩm: %w", err)
+	}
+	return products, nil
+}
+
+// GetProduct lấy sản phẩm kèm danh mục, thành phố và nhà cung cấp
+func (s *productService) GetProduct(ctx context.Context, id string) (*models.Product, error) {
+	product := &models.Product{}
+	err := s.productQuery(product).
+		Where("p.id = ?", id).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("sản phẩm %s: %w", id, ErrRecordNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn sản phẩm: %w", err)
+	}
+	return product, nil
+}
+
+// CreateProduct tạo sản phẩm với mã PROD-YYYYMM-NNN tăng dần theo tháng tạo
+func (s *productService) CreateProduct(ctx context.Context, input ProductInput) (*models.Product, error) {
+	if err := validateProductInput(input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	product := &models.Product{
+		ID:          uuid.NewString(),
+		CreatedTime: now,
+	}
+	applyProductInput(product, input, now)
+
+	var err error
+	for attempt := 0; attempt < productReferenceAttempts; attempt++ {
+		err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := checkProductReferences(ctx, tx, input); err != nil {
+				return err
+			}
+			reference, err := nextProductReference(ctx, tx, now)
+			if err != nil {
+				return err
+			}
+			product.Reference = reference
+			if _, err := tx.NewInsert().Model(product).Exec(ctx); err != nil {
+				return err
+			}
+			return saveProductCategories(ctx, tx, product.ID, input.Categories)
+		})
+		if !utils.IsUniqueViolation(err) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidRecord) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lỗi khi tạo sản phẩm: %w", err)
+	}
+
+	return s.GetProduct(ctx, product.ID)
+}
+
+// UpdateProduct ghi đè thông tin và danh mục của sản phẩm, mã sản phẩm giữ nguyên
+func (s *productService) UpdateProduct(ctx context.Context, id string, input ProductInput) (*models.Product, error) {
+	if err := validateProductInput(input); err != nil {
+		return nil, err
+	}
+
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		product := &models.Product{}
+		err := tx.NewSelect().Model(product).Where("p.id = ?", id).Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("sản phẩm %s: %w", id, ErrRecordNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkProductReferences(ctx, tx, input); err != nil {
+			return err
+		}
+
+		applyProductInput(product, input, time.Now())
+		_, err = tx.NewUpdate().
+			Model(product).
+			Column("name", "status", "price", "quantity", "city_id", "supplier_id", "updated_time").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*models.ProductCategory)(nil)).
+			Where("product_id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return saveProductCategories(ctx, tx, id, input.Categories)
+	})
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) || errors.Is(err, ErrInvalidRecord) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lỗi khi cập nhật sản phẩm: %w", err)
+	}
+
+	return s.GetProduct(ctx, id)
+}
+
+// DeleteProduct xóa mềm sản phẩm
+func (s *productService) DeleteProduct(ctx context.Context, id string) error {
+	res, err := s.db.NewDelete().
+		Model((*models.Product)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi xóa sản phẩm: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("sản phẩm %s: %w", id, ErrRecordNotFound)
+	}
+	return nil
+}
+
+// ProductDistance tính khoảng cách từ điểm from tới kho nhà cung cấp của sản phẩm, làm tròn 0,1 km
+func (s *productService) ProductDistance(ctx context.Context, id string, from DistanceFrom) (*ProductDistance, error) {
+	product, err := s.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.Supplier == nil {
+		return nil, fmt.Errorf("nhà cung cấp %s của sản phẩm không còn tồn tại: %w", product.SupplierID, ErrInvalidRecord)
+	}
+	to, ok := product.Supplier.Location()
+	if !ok {
+		return nil, fmt.Errorf("nhà cung cấp %s chưa khai báo vị trí kho: %w", product.SupplierID, ErrInvalidRecord)
+	}
+
+	var origin models.GEO
+	if from.Point != nil {
+		origin = models.GEO{Latitude: from.Point.Latitude, Longitude: from.Point.Longitude}
+	} else if origin, err = s.cities.LocateCity(ctx, from.CityID); err != nil {
+		// Thành phố là tham số của request nên không tồn tại là dữ liệu không hợp lệ, không phải 404
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, fmt.Errorf("thành phố %s không tồn tại: %w", from.CityID, ErrInvalidRecord)
+		}
+		return nil, err
+	}
+
+	distance := geo.DistanceKm(origin.Point(), to.Point())
+	return &ProductDistance{
+		ProductID:  product.ID,
+		From:       origin,
+		To:         to,
+		DistanceKm: math.Round(distance*10) / 10,
+	}, nil
+}
+
+// productQuery tạo truy vấn sản phẩm kèm các quan hệ
+func (s *productService) productQuery(model interface{}) *bun.SelectQuery {
+	return s.db.NewSelect().
+		Model(model).
+		Relation("Categories", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.OrderExpr("cat.name ASC")
+		}).
+		Relation("City").
+		Relation("Supplier")
+}
+
+// validateProductInput kiểm tra các giá trị không phụ thuộc cơ sở dữ liệu
+func validateProductInput(input ProductInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("tên sản phẩm trống: %w", ErrInvalidRecord)
+	}
+	if input.Price.IsNegative() {
+		return fmt.Errorf("giá sản phẩm âm: %w", ErrInvalidRecord)
+	}
+	if input.Quantity < 0 {
+		return fmt.Errorf("số lượng sản phẩm âm: %w", ErrInvalidRecord)
+	}
+	if input.Weight < 0 {
+		return fmt.Errorf("khối lượng sản phẩm âm: %w", ErrInvalidRecord)
+	}
+	if input.Status != models.MSActive && input.Status != models.MSDeActive {
+		return fmt.Errorf("trạng thái %d không hợp lệ: %w", input.Status, ErrInvalidRecord)
+	}
+	if len(input.Categories) == 0 {
+		return fmt.Errorf("sản phẩm cần ít nhất một danh mục: %w", ErrInvalidRecord)
+	}
+	return nil
+}
+
+// applyProductInput gán dữ liệu ghi vào sản phẩm
+func applyProductInput(product *models.Product, input ProductInput, now time.Time) {
+	product.Name = strings.TrimSpace(input.Name)
+	product.Price = input.Price.Round(2)
+	product.Quantity = input.Quantity
+	product.Weight = input.Weight
+	product.Status = input.Status
+	product.CityID = input.CityID
+	product.SupplierID = input.SupplierID
+	product.UpdatedTime = now
+}
+
+// checkProductReferences kiểm tra thành phố, nhà cung cấp và danh mục tồn tại và chưa bị xóa
+func checkProductReferences(ctx context.Context, tx bun.Tx, input ProductInput) error {
+	exists, err := tx.NewSelect().Model((*models.City)(nil)).Where("ci.id = ?", input.CityID).Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("thành phố %s không tồn tại: %w", input.CityID, ErrInvalidRecord)
+	}
+
+	exists, err = tx.NewSelect().Model((*models.Supplier)(nil)).Where("sup.id = ?", input.SupplierID).Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("nhà cung cấp %s không tồn tại: %w", input.SupplierID, ErrInvalidRecord)
+	}
+
+	ids := uniqueStrings(input.Categories)
+	count, err := tx.NewSelect().Model((*models.Category)(nil)).Where("cat.id IN (?)", bun.In(ids)).Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count != len(ids) {
+		return fmt.Errorf("có danh mục không tồn tại trong %v: %w", ids, ErrInvalidRecord)
+	}
+	return nil
+}
+
+// nextProductReference sinh mã sản phẩm tiếp theo trong tháng, tính cả sản phẩm đã xóa để không dùng lại mã
+func nextProductReference(ctx context.Context, tx bun.Tx, now time.Time) (string, error) {
+	prefix := fmt.Sprintf("PROD-%s-", now.Format("200601"))
+	count, err := tx.NewSelect().
+		Model((*models.Product)(nil)).
+		WhereAllWithDeleted().
+		Where("p.reference LIKE ?", prefix+"%").
+		Count(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%03d", prefix, count+1), nil
+}
+
+// saveProductCategories gắn sản phẩm vào các danh mục
+func saveProductCategories(ctx context.Context, tx bun.Tx, productID string, categoryIDs []string) error {
+	ids := uniqueStrings(categoryIDs)
+	links := make([]models.ProductCategory, 0, len(ids))
+	for _, id := range ids {
+		links = append(links, models.ProductCategory{ProductID: productID, CategoryID: id})
+	}
+	_, err := tx.NewInsert().Model(&links).Exec(ctx)
+	return err
+}
+
+// uniqueStrings bỏ các phần tử trùng, giữ thứ tự xuất hiện đầu tiên
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
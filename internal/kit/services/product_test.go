@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/pkgs/cache"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+func TestProductService(t *testing.T) {
+	ctx := context.Background()
+	db := newCatalogDB(t)
+	categories := NewCategoryService(db)
+	cities := NewCityService(db, nil)
+	suppliers := NewSupplierService(db)
+	products := NewProductService(db, cities)
+
+	books, err := categories.CreateCategory(ctx, "Sách")
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	gifts, err := categories.CreateCategory(ctx, "Quà tặng")
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	hanoi, err := cities.CreateCity(ctx, models.GEO{Name: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542})
+	if err != nil {
+		t.Fatalf("CreateCity: %v", err)
+	}
+	hcm, err := cities.CreateCity(ctx, models.GEO{Name: "Hồ Chí Minh", Latitude: 10.8231, Longitude: 106.6297})
+	if err != nil {
+		t.Fatalf("CreateCity: %v", err)
+	}
+	supplier, err := suppliers.CreateSupplier(ctx, "Nhà sách Fahasa", nil)
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+
+	input := ProductInput{
+		Name:       "Dế mèn phiêu lưu ký",
+		Price:      decimal.RequireFromString("85000.5"),
+		Quantity:   20,
+		Status:     models.MSActive,
+		Categories: []string{books.ID, gifts.ID, books.ID},
+		CityID:     hanoi.ID,
+		SupplierID: supplier.ID,
+	}
+	first, err := products.CreateProduct(ctx, input)
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	prefix := fmt.Sprintf("PROD-%s-", time.Now().Format("200601"))
+	if first.Reference != prefix+"001" {
+		t.Fatalf("reference = %q, muốn %q", first.Reference, prefix+"001")
+	}
+	if len(first.Categories) != 2 || first.City == nil || first.City.Name != "Hà Nội" || first.Supplier == nil || first.Supplier.ID != supplier.ID {
+		t.Fatalf("product = %+v", first)
+	}
+	if !first.Price.Equal(decimal.RequireFromString("85000.5")) {
+		t.Fatalf("price = %s", first.Price)
+	}
+
+	// Mã sản phẩm không dùng lại mã của sản phẩm đã xóa
+	if err := products.DeleteProduct(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	if _, err := products.GetProduct(ctx, first.ID); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetProduct sau khi xóa: err = %v, muốn ErrRecordNotFound", err)
+	}
+	input.Name = "Tắt đèn"
+	input.Categories = []string{books.ID}
+	second, err := products.CreateProduct(ctx, input)
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if second.Reference != prefix+"002" {
+		t.Fatalf("reference = %q, muốn %q", second.Reference, prefix+"002")
+	}
+
+	// Ghi đè thay danh mục và thành phố, giữ nguyên mã
+	input.Categories = []string{gifts.ID}
+	input.CityID = hcm.ID
+	input.Status = models.MSDeActive
+	updated, err := products.UpdateProduct(ctx, second.ID, input)
+	if err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if updated.Reference != second.Reference || updated.CityID != hcm.ID || len(updated.Categories) != 1 || updated.Categories[0].ID != gifts.ID {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	third, err := products.CreateProduct(ctx, ProductInput{
+		Name: "Sổ tay", Price: decimal.NewFromInt(30000), Quantity: 5, Status: models.MSActive,
+		Categories: []string{books.ID}, CityID: hanoi.ID, SupplierID: supplier.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter ProductFilter
+		want   []string
+	}{
+		{"tất cả, mới nhất trước", ProductFilter{}, []string{third.ID, second.ID}},
+		{"theo mã", ProductFilter{References: []string{second.Reference}}, []string{second.ID}},
+		{"theo tên", ProductFilter{Names: []string{"SỔ", "không có"}}, []string{third.ID}},
+		{"theo trạng thái", ProductFilter{Statuses: []models.ModelStatus{models.MSDeActive}}, []string{second.ID}},
+		{"theo danh mục", ProductFilter{Categories: []string{books.ID}}, []string{third.ID}},
+		{"theo thành phố", ProductFilter{Cities: []string{hcm.ID}}, []string{second.ID}},
+		{"phân trang", ProductFilter{Offset: 1, Limit: 1}, []string{second.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := products.ListProducts(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListProducts: %v", err)
+			}
+			ids := make([]string, 0, len(got))
+			for _, p := range got {
+				ids = append(ids, p.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Fatalf("ids = %v, muốn %v", ids, tt.want)
+			}
+		})
+	}
+
+	// Tham chiếu tới bản ghi không tồn tại hoặc đã xóa bị từ chối
+	if err := categories.DeleteCategory(ctx, gifts.ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	invalid := []ProductInput{
+		{Name: "X", Price: decimal.NewFromInt(1), Status: models.MSActive, Categories: []string{gifts.ID}, CityID: hanoi.ID, SupplierID: supplier.ID},
+		{Name: "X", Price: decimal.NewFromInt(1), Status: models.MSActive, Categories: []string{books.ID}, CityID: "unknown", SupplierID: supplier.ID},
+		{Name: "X", Price: decimal.NewFromInt(-1), Status: models.MSActive, Categories: []string{books.ID}, CityID: hanoi.ID, SupplierID: supplier.ID},
+		{Name: "X", Price: decimal.NewFromInt(1), Status: 3, Categories: []string{books.ID}, CityID: hanoi.ID, SupplierID: supplier.ID},
+	}
+	for i, in := range invalid {
+		if _, err := products.CreateProduct(ctx, in); !errors.Is(err, ErrInvalidRecord) {
+			t.Fatalf("input %d: err = %v, muốn ErrInvalidRecord", i, err)
+		}
+	}
+	if _, err := products.UpdateProduct(ctx, first.ID, input); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("UpdateProduct đã xóa: err = %v, muốn ErrRecordNotFound", err)
+	}
+}
+
+func TestProductDistance(t *testing.T) {
+	ctx := context.Background()
+	db := newCatalogDB(t)
+	categories := NewCategoryService(db)
+	cities := NewCityService(db, cache.NewMemoryCache())
+	suppliers := NewSupplierService(db)
+	products := NewProductService(db, cities)
+
+	books, err := categories.CreateCategory(ctx, "Sách")
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	hanoi, err := cities.CreateCity(ctx, models.GEO{Name: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542})
+	if err != nil {
+		t.Fatalf("CreateCity: %v", err)
+	}
+	warehouse := &models.GEO{Name: "Kho Long Biên", Latitude: 21.0453, Longitude: 105.8896}
+	supplier, err := suppliers.CreateSupplier(ctx, "Nhà sách Fahasa", warehouse)
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+	noLocation, err := suppliers.CreateSupplier(ctx, "Nhà cung cấp chưa có kho", nil)
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+
+	newProduct := func(supplierID string) *models.Product {
+		product, err := products.CreateProduct(ctx, ProductInput{
+			Name: "Sổ tay", Price: decimal.NewFromInt(30000), Quantity: 5, Status: models.MSActive,
+			Categories: []string{books.ID}, CityID: hanoi.ID, SupplierID: supplierID,
+		})
+		if err != nil {
+			t.Fatalf("CreateProduct: %v", err)
+		}
+		return product
+	}
+	product := newProduct(supplier.ID)
+
+	// Từ tọa độ
+	got, err := products.ProductDistance(ctx, product.ID, DistanceFrom{Point: &geo.Point{Latitude: 21.0453, Longitude: 105.8896}})
+	if err != nil {
+		t.Fatalf("ProductDistance: %v", err)
+	}
+	if got.DistanceKm != 0 || got.To != *warehouse {
+		t.Fatalf("distance = %+v", got)
+	}
+
+	// Từ thành phố, tọa độ thành phố được cache
+	got, err = products.ProductDistance(ctx, product.ID, DistanceFrom{CityID: hanoi.ID})
+	if err != nil {
+		t.Fatalf("ProductDistance: %v", err)
+	}
+	want := math.Round(geo.DistanceKm(hanoi.Point(), warehouse.Point())*10) / 10
+	if got.DistanceKm != want || got.From.Name != "Hà Nội" {
+		t.Fatalf("distance = %+v, muốn %v km", got, want)
+	}
+
+	if _, err := db.NewUpdate().Model((*models.City)(nil)).Set("latitude = 0").Where("id = ?", hanoi.ID).Exec(ctx); err != nil {
+		t.Fatalf("sửa trực tiếp thành phố: %v", err)
+	}
+	if cached, err := cities.LocateCity(ctx, hanoi.ID); err != nil || cached.Latitude != 21.0285 {
+		t.Fatalf("LocateCity = %+v, %v, muốn đọc từ cache", cached, err)
+	}
+
+	// Cập nhật thành phố qua service xóa cache
+	latitude := 21.03
+	if _, err := cities.UpdateCity(ctx, hanoi.ID, nil, &latitude, nil); err != nil {
+		t.Fatalf("UpdateCity: %v", err)
+	}
+	if located, err := cities.LocateCity(ctx, hanoi.ID); err != nil || located.Latitude != 21.03 {
+		t.Fatalf("LocateCity sau khi cập nhật = %+v, %v", located, err)
+	}
+
+	if _, err := products.ProductDistance(ctx, product.ID, DistanceFrom{CityID: "unknown"}); !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("thành phố không tồn tại: err = %v, muốn ErrInvalidRecord", err)
+	}
+	if _, err := products.ProductDistance(ctx, newProduct(noLocation.ID).ID, DistanceFrom{CityID: hanoi.ID}); !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("nhà cung cấp chưa có kho: err = %v, muốn ErrInvalidRecord", err)
+	}
+	if _, err := products.ProductDistance(ctx, "unknown", DistanceFrom{CityID: hanoi.ID}); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("sản phẩm không tồn tại: err = %v, muốn ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// RoutePlanOptions là tùy chọn khi lập lộ trình giao hàng
+type RoutePlanOptions struct {
+	Start  *geo.Point         // điểm xuất phát, nil thì dùng vị trí mới nhất của tài xế
+	Status domain.OrderStatus // chỉ lập lộ trình cho đơn hàng ở trạng thái này, rỗng là mọi đơn hàng chưa hoàn thành
+}
+
+// RouteService lập lộ trình giao hàng cho các đơn hàng được phân công cho tài xế
+type RouteService interface {
+	// PlanRoute lập lộ trình theo tải trọng của phương tiện tài xế đang dùng và ghi lại vào luồng của tài xế
+	PlanRoute(ctx context.Context, driverID string, opts RoutePlanOptions) (*domain.Route, error)
+
+	// GetRoute lấy lộ trình được lập gần nhất của tài xế
+	GetRoute(ctx context.Context, driverID string) (*domain.Route, error)
+}
+
+type routeService struct {
+	eventStore eventstore.EventStore
+	repository repository.OrderRepository
+	eventBus   eventbus.EventBus
+	locations  projection.LocationProjection
+	orderOpts  []domain.OrderOption
+}
+
+// NewRouteService tạo service lập lộ trình, orderOpts giống như của OrderService
+func NewRouteService(
+	eventStore eventstore.EventStore,
+	repository repository.OrderRepository,
+	eventBus eventbus.EventBus,
+	locations projection.LocationProjection,
+	orderOpts ...domain.OrderOption,
+) RouteService {
+	return &routeService{
+		eventStore: eventStore,
+		repository: repository,
+		eventBus:   eventBus,
+		locations:  locations,
+		orderOpts:  orderOpts,
+	}
+}
+
+// PlanRoute lấy các đơn hàng chưa hoàn thành của tài xế, đơn hàng không có tọa độ điểm giao
+// được ghi nhận là không thể lập lộ trình
+func (s *routeService) PlanRoute(ctx context.Context, driverID string, opts RoutePlanOptions) (*domain.Route, error) {
+	driver, err := s.driver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	var vehicle *domain.Vehicle
+	if driver.VehicleID != "" {
+		events, err := s.eventStore.GetEvents(ctx, driver.VehicleID)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+		}
+		vehicle = domain.RebuildVehicle(events, s.orderOpts...)
+	}
+
+	start, err := s.start(ctx, driverID, opts.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.repository.ListByDriver(ctx, driverID, opts.Status)
+	if err != nil {
+		return nil, err
+	}
+	var destinations []domain.RouteDestination
+	var unrouted []string
+	for _, order := range orders {
+		if !order.CountsTowardsLoad() {
+			continue
+		}
+		if !order.Destination.HasCoordinates() {
+			unrouted = append(unrouted, order.ID)
+			continue
+		}
+		destinations = append(destinations, domain.RouteDestination{
+			OrderID:  order.ID,
+			Point:    order.Destination.Point(),
+			WeightKg: order.Totals.TotalWeight,
+		})
+	}
+
+	if err := driver.PlanRoute(vehicle, start, destinations, unrouted); err != nil {
+		return nil, fmt.Errorf("không thể lập lộ trình: %w", err)
+	}
+
+	events := driver.GetUncommittedEvents()
+	if err := s.eventStore.SaveEvents(ctx, driver.ID, events); err != nil {
+		return nil, fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+	}
+	for _, event := range events {
+		if err := s.eventBus.Publish(event); err != nil {
+			fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+		}
+	}
+	driver.ClearUncommittedEvents()
+
+	return driver.Route, nil
+}
+
+// start lấy điểm xuất phát được truyền vào hoặc vị trí mới nhất của tài xế
+func (s *routeService) start(ctx context.Context, driverID string, start *geo.Point) (geo.Point, error) {
+	if start != nil {
+		return *start, nil
+	}
+
+	location, err := s.locations.DriverLocation(ctx, driverID)
+	if err != nil {
+		return geo.Point{}, err
+	}
+	if location == nil {
+		return geo.Point{}, errors.New("chưa có vị trí của tài xế, cần truyền điểm xuất phát")
+	}
+	return location.Point, nil
+}
+
+// GetRoute lấy lộ trình được lập gần nhất của tài xế
+func (s *routeService) GetRoute(ctx context.Context, driverID string) (*domain.Route, error) {
+	driver, err := s.driver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if driver.Route == nil {
+		return nil, errors.New("tài xế chưa có lộ trình")
+	}
+	return driver.Route, nil
+}
+
+// driver xây dựng lại tài xế từ event store
+func (s *routeService) driver(ctx context.Context, driverID string) (*domain.Driver, error) {
+	events, err := s.eventStore.GetEvents(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+	}
+
+	driver := domain.RebuildDriver(events, s.orderOpts...)
+	if driver == nil {
+		return nil, errors.New("không tìm thấy tài xế")
+	}
+	return driver, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
+)
+
+func TestRouteServicePlanRoute(t *testing.T) {
+	ctx := context.Background()
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.EventTypesOf(domain.AggregateOrder)...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := domaintest.Options()
+	orders := NewOrderService(store, repo, bus, opts...)
+	fleet := NewFleetService(store, repo, bus, opts...)
+	locations := projection.NewInMemoryLocationProjection()
+	routes := NewRouteService(store, repo, bus, locations, opts...)
+
+	driverID := registerDriver(t, fleet, decimal.NewFromInt(20))
+	items := []domain.OrderItem{{ID: "ITEM-1", Quantity: 1, Weight: decimal.NewFromInt(4)}}
+	origin := domain.Location{Address: "1 Nguyễn Huệ", City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destinations := []domain.Location{
+		{Address: "Xa", City: "Hồ Chí Minh", Latitude: 10.83, Longitude: 106.70},
+		{Address: "Gần", City: "Hồ Chí Minh", Latitude: 10.79, Longitude: 106.70},
+		{Address: "Giữa", City: "Hồ Chí Minh", Latitude: 10.81, Longitude: 106.70},
+		{Address: "Chưa có tọa độ", City: "Hồ Chí Minh"},
+	}
+	var ids []string
+	for _, destination := range destinations {
+		id, _, err := orders.CreateOrder(ctx, "CUS-001", origin, destination, items, "", domain.PaymentTerms{})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		if err := fleet.AssignOrder(ctx, id, driverID); err != nil {
+			t.Fatalf("AssignOrder: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	// Đơn hàng đã giao không được đưa vào lộ trình
+	if err := orders.UpdateOrderStatus(ctx, ids[1], domain.OrderStatusDelivered, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+
+	if _, err := routes.GetRoute(ctx, driverID); err == nil {
+		t.Fatalf("GetRoute khi chưa lập lộ trình phải lỗi")
+	}
+	if _, err := routes.PlanRoute(ctx, "missing", RoutePlanOptions{}); err == nil {
+		t.Fatalf("PlanRoute với tài xế không tồn tại phải lỗi")
+	}
+	// Chưa có vị trí của tài xế và không truyền điểm xuất phát
+	if _, err := routes.PlanRoute(ctx, driverID, RoutePlanOptions{}); err == nil {
+		t.Fatalf("PlanRoute khi chưa có điểm xuất phát phải lỗi")
+	}
+
+	start := geo.Point{Latitude: 10.7769, Longitude: 106.7009}
+	if err := locations.Save(ctx, projection.DriverLocation{DriverID: driverID, Point: start, RecordedAt: domaintest.Now, ReceivedAt: domaintest.Now}, nil); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	route, err := routes.PlanRoute(ctx, driverID, RoutePlanOptions{})
+	if err != nil {
+		t.Fatalf("PlanRoute: %v", err)
+	}
+	if route.Start != start || len(route.Stops) != 2 || route.Stops[0].OrderID != ids[2] || route.Stops[1].OrderID != ids[0] {
+		t.Fatalf("Route = %+v", route)
+	}
+	if len(route.Unrouted) != 1 || route.Unrouted[0] != ids[3] {
+		t.Fatalf("Unrouted = %v", route.Unrouted)
+	}
+
+	// Điểm xuất phát truyền vào được ưu tiên hơn vị trí của tài xế
+	depot := geo.Point{Latitude: 10.85, Longitude: 106.70}
+	if route, err = routes.PlanRoute(ctx, driverID, RoutePlanOptions{Start: &depot}); err != nil {
+		t.Fatalf("PlanRoute: %v", err)
+	}
+	if route.Start != depot || route.Stops[0].OrderID != ids[0] {
+		t.Fatalf("Route = %+v", route)
+	}
+
+	if err := orders.UpdateOrderStatus(ctx, ids[2], domain.OrderStatusOutForDelivery, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	if route, err = routes.PlanRoute(ctx, driverID, RoutePlanOptions{Status: domain.OrderStatusOutForDelivery}); err != nil {
+		t.Fatalf("PlanRoute: %v", err)
+	}
+	if len(route.Stops) != 1 || route.Stops[0].OrderID != ids[2] || len(route.Unrouted) != 0 {
+		t.Fatalf("Route theo trạng thái = %+v", route)
+	}
+
+	saved, err := routes.GetRoute(ctx, driverID)
+	if err != nil {
+		t.Fatalf("GetRoute: %v", err)
+	}
+	if len(saved.Stops) != 1 || saved.DistanceKm != route.DistanceKm {
+		t.Fatalf("GetRoute = %+v", saved)
+	}
+
+	events, err := store.GetEvents(ctx, driverID)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	var planned int
+	for _, event := range events {
+		if event.GetType() == domain.RoutePlannedType {
+			planned++
+		}
+	}
+	if planned != 3 {
+		t.Fatalf("số sự kiện ROUTE_PLANNED = %d", planned)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+)
+
+// slaCheckBatch là số đơn hàng quá hạn được xử lý trong một lần lưu sự kiện
+const slaCheckBatch = 200
+
+// SLAMonitor định kỳ tìm các đơn hàng quá thời gian giao hàng cam kết và phát sự kiện SLABreached
+type SLAMonitor struct {
+	eventStore eventstore.EventStore
+	orderRepo  repository.OrderRepository
+	eventBus   eventbus.EventBus
+	clock      domain.Clock
+	orderOpts  []domain.OrderOption
+}
+
+// NewSLAMonitor tạo SLAMonitor, clock xác định thời điểm so sánh với thời gian cam kết
+// và được dùng thay cho clock trong orderOpts khi kiểm tra lại đơn hàng
+func NewSLAMonitor(
+	eventStore eventstore.EventStore,
+	orderRepo repository.OrderRepository,
+	eventBus eventbus.EventBus,
+	clock domain.Clock,
+	orderOpts ...domain.OrderOption,
+) *SLAMonitor {
+	return &SLAMonitor{
+		eventStore: eventStore,
+		orderRepo:  orderRepo,
+		eventBus:   eventBus,
+		clock:      clock,
+		// Clock của monitor đặt sau cùng để ghi đè WithClock trong orderOpts, tránh lệch với thời điểm truy vấn quá hạn
+		orderOpts: append(append([]domain.OrderOption{}, orderOpts...), domain.WithClock(clock)),
+	}
+}
+
+// Run kiểm tra theo chu kỳ interval cho đến khi ctx bị hủy
+func (m *SLAMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.CheckOverdue(ctx); err != nil {
+				fmt.Printf("lỗi khi kiểm tra SLA: %v\n", err)
+			}
+		}
+	}
+}
+
+// CheckOverdue ghi nhận vi phạm SLA cho mọi đơn hàng đang quá hạn, trả về số đơn hàng được ghi nhận
+func (m *SLAMonitor) CheckOverdue(ctx context.Context) (int, error) {
+	breached := 0
+	for {
+		ids, err := m.orderRepo.ListOverdue(ctx, m.clock.Now(), slaCheckBatch)
+		if err != nil {
+			return breached, err
+		}
+		if len(ids) == 0 {
+			return breached, nil
+		}
+
+		streams, err := m.eventStore.GetEventsBatch(ctx, ids)
+		if err != nil {
+			return breached, fmt.Errorf("lỗi khi lấy lịch sử sự kiện: %w", err)
+		}
+
+		// Read model có thể chậm hơn event store nên trạng thái được kiểm tra lại trên aggregate
+		var events []domain.Event
+		for _, id := range ids {
+			order := domain.RebuildFromEvents(streams[id], m.orderOpts...)
+			if order == nil || order.MarkSLABreached() != nil {
+				continue
+			}
+			events = append(events, order.GetUncommittedEvents()...)
+		}
+
+		// Không còn đơn hàng nào cần ghi nhận, tránh lặp lại mãi với read model chưa cập nhật
+		if len(events) == 0 {
+			return breached, nil
+		}
+
+		if err := m.eventStore.SaveEventsBatch(ctx, events); err != nil {
+			return breached, fmt.Errorf("lỗi khi lưu sự kiện: %w", err)
+		}
+		for _, event := range events {
+			if err := m.eventBus.Publish(event); err != nil {
+				fmt.Printf("lỗi khi phát sự kiện: %v\n", err)
+			}
+		}
+		breached += len(events)
+
+		if len(ids) < slaCheckBatch {
+			return breached, nil
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+)
+
+func TestSLAMonitorCheckOverdue(t *testing.T) {
+	ctx := context.Background()
+	now := domaintest.Now
+	clock := domain.ClockFunc(func() time.Time { return now })
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := []domain.OrderOption{
+		domain.WithClock(clock),
+		domain.WithIDGenerator(&domaintest.SequenceIDs{}),
+		domain.WithDeliveryEstimator(NewETAService(DefaultETARules())),
+	}
+	service := NewOrderService(store, repo, bus, opts...)
+	monitor := NewSLAMonitor(store, repo, bus, clock, opts...)
+
+	origin := domain.Location{City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destination := domain.Location{City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+	items := []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}}
+
+	expressID, _, err := service.CreateOrder(ctx, "CUS-001", origin, destination, items, domain.ServiceLevelExpress, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	standardID, _, err := service.CreateOrder(ctx, "CUS-001", origin, destination, items, domain.ServiceLevelStandard, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	deliveredID, _, err := service.CreateOrder(ctx, "CUS-002", origin, destination, items, domain.ServiceLevelExpress, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if err := service.UpdateOrderStatus(ctx, deliveredID, domain.OrderStatusDelivered, nil, ""); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+
+	express, err := service.GetOrder(ctx, expressID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if express.EstimatedDelivery == nil || express.PromisedDelivery == nil || express.ServiceLevel != domain.ServiceLevelExpress {
+		t.Fatalf("đơn hàng EXPRESS chưa có ETA: %+v", express)
+	}
+
+	if breached, err := monitor.CheckOverdue(ctx); err != nil || breached != 0 {
+		t.Fatalf("CheckOverdue trước hạn = %d, %v", breached, err)
+	}
+
+	// Qua thời gian cam kết của đơn EXPRESS nhưng chưa tới hạn của đơn STANDARD
+	now = express.PromisedDelivery.Add(time.Minute)
+	if breached, err := monitor.CheckOverdue(ctx); err != nil || breached != 1 {
+		t.Fatalf("CheckOverdue = %d, %v, muốn 1", breached, err)
+	}
+	if breached, err := monitor.CheckOverdue(ctx); err != nil || breached != 0 {
+		t.Fatalf("CheckOverdue lần hai = %d, %v, muốn 0", breached, err)
+	}
+
+	for id, want := range map[string]bool{expressID: true, standardID: false, deliveredID: false} {
+		order, err := service.GetOrder(ctx, id)
+		if err != nil {
+			t.Fatalf("GetOrder: %v", err)
+		}
+		if order.SLABreached != want {
+			t.Fatalf("đơn hàng %s SLABreached = %v, muốn %v", id, order.SLABreached, want)
+		}
+	}
+
+	events, err := store.GetEvents(ctx, expressID)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if last := events[len(events)-1]; last.GetType() != domain.SLABreachedType {
+		t.Fatalf("sự kiện cuối = %s, muốn %s", last.GetType(), domain.SLABreachedType)
+	}
+}
+
+func TestSLAMonitorOverridesOrderClock(t *testing.T) {
+	ctx := context.Background()
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	// orderOpts giữ clock cố định tại thời điểm tạo đơn hàng như khi nối dây ở server
+	opts := append(domaintest.Options(), domain.WithDeliveryEstimator(NewETAService(DefaultETARules())))
+	service := NewOrderService(store, repo, bus, opts...)
+
+	origin := domain.Location{City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destination := domain.Location{City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+	items := []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}}
+	id, _, err := service.CreateOrder(ctx, "CUS-001", origin, destination, items, domain.ServiceLevelExpress, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order, err := service.GetOrder(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.PromisedDelivery == nil {
+		t.Fatalf("đơn hàng chưa có thời gian cam kết: %+v", order)
+	}
+
+	late := order.PromisedDelivery.Add(time.Minute)
+	monitor := NewSLAMonitor(store, repo, bus, domain.FixedClock(late), opts...)
+	if breached, err := monitor.CheckOverdue(ctx); err != nil || breached != 1 {
+		t.Fatalf("CheckOverdue = %d, %v, muốn 1", breached, err)
+	}
+
+	events, err := store.GetEvents(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.GetType() != domain.SLABreachedType || !last.GetTimestamp().Equal(late) {
+		t.Fatalf("sự kiện cuối = %s lúc %s, muốn %s lúc %s", last.GetType(), last.GetTimestamp(), domain.SLABreachedType, late)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/uptrace/bun"
+)
+
+// analyticsRebuildBatch là số sự kiện được đọc mỗi lần khi xây dựng lại projection thống kê
+const analyticsRebuildBatch = 1000
+
+// StatisticsService cung cấp số liệu thống kê sản phẩm và đơn hàng
+type StatisticsService interface {
+	// ProductsPerCategory đếm sản phẩm chưa xóa theo ID danh mục, kể cả danh mục chưa có sản phẩm
+	ProductsPerCategory(ctx context.Context) (map[string]int, error)
+
+	// ProductsPerSupplier đếm sản phẩm chưa xóa theo ID nhà cung cấp, kể cả nhà cung cấp chưa có sản phẩm
+	ProductsPerSupplier(ctx context.Context) (map[string]int, error)
+
+	// OrdersPerDay lấy số đơn hàng được tạo theo ngày (UTC) trong khoảng [from, to], nil nghĩa là không giới hạn
+	OrdersPerDay(ctx context.Context, from, to *time.Time) ([]projection.DailyOrders, error)
+
+	// OrderStatuses lấy phân bố đơn hàng và thời gian trung bình ở mỗi trạng thái
+	OrderStatuses(ctx context.Context) ([]projection.StatusStats, error)
+
+	// Cancellations lấy tỷ lệ hủy đơn hàng theo lý do
+	Cancellations(ctx context.Context) (*projection.CancellationStats, error)
+
+	// RebuildAnalytics phát lại toàn bộ sự kiện vào projection thống kê, trả về số sự kiện đã phát lại
+	RebuildAnalytics(ctx context.Context) (int, error)
+}
+
+type statisticsService struct {
+	db         *bun.DB
+	eventStore eventstore.EventStore
+	analytics  projection.AnalyticsProjection
+}
+
+// NewStatisticsService tạo service thống kê
+func NewStatisticsService(db *bun.DB, eventStore eventstore.EventStore, analytics projection.AnalyticsProjection) StatisticsService {
+	return &statisticsService{
+		db:         db,
+		eventStore: eventStore,
+		analytics:  analytics,
+	}
+}
+
+// productCount là một dòng kết quả đếm sản phẩm theo khóa
+type productCount struct {
+	ID    string `bun:"id"`
+	Count int    `bun:"count"`
+}
+
+// ProductsPerCategory đếm sản phẩm chưa xóa theo danh mục chưa xóa
+func (s *statisticsService) ProductsPerCategory(ctx context.Context) (map[string]int, error) {
+	var rows []productCount
+	err := s.db.NewSelect().
+		Model((*models.Category)(nil)).
+		ColumnExpr("cat.id AS id, COUNT(p.id) AS count").
+		Join("LEFT JOIN product_categories AS pc ON pc.category_id = cat.id").
+		Join("LEFT JOIN products AS p ON p.id = pc.product_id AND p.deleted_time IS NULL").
+		GroupExpr("cat.id").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi đếm sản phẩm theo danh mục: %w", err)
+	}
+	return countsToMap(rows), nil
+}
+
+// ProductsPerSupplier đếm sản phẩm chưa xóa theo nhà cung cấp chưa xóa
+func (s *statisticsService) ProductsPerSupplier(ctx context.Context) (map[string]int, error) {
+	var rows []productCount
+	err := s.db.NewSelect().
+		Model((*models.Supplier)(nil)).
+		ColumnExpr("sup.id AS id, COUNT(p.id) AS count").
+		Join("LEFT JOIN products AS p ON p.supplier_id = sup.id AND p.deleted_time IS NULL").
+		GroupExpr("sup.id").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi đếm sản phẩm theo nhà cung cấp: %w", err)
+	}
+	return countsToMap(rows), nil
+}
+
+// OrdersPerDay lấy số đơn hàng được tạo theo ngày, cũ nhất trước
+func (s *statisticsService) OrdersPerDay(ctx context.Context, from, to *time.Time) ([]projection.DailyOrders, error) {
+	var fromDay, toDay string
+	if from != nil {
+		fromDay = from.UTC().Format(projection.DayLayout)
+	}
+	if to != nil {
+		toDay = to.UTC().Format(projection.DayLayout)
+	}
+	if fromDay != "" && toDay != "" && fromDay > toDay {
+		return nil, fmt.Errorf("%w: from phải trước hoặc bằng to", ErrInvalidRecord)
+	}
+	return s.analytics.OrdersPerDay(ctx, fromDay, toDay)
+}
+
+// OrderStatuses lấy phân bố đơn hàng và thời gian trung bình ở mỗi trạng thái
+func (s *statisticsService) OrderStatuses(ctx context.Context) ([]projection.StatusStats, error) {
+	return s.analytics.StatusStats(ctx)
+}
+
+// Cancellations lấy tỷ lệ hủy đơn hàng theo lý do
+func (s *statisticsService) Cancellations(ctx context.Context) (*projection.CancellationStats, error) {
+	return s.analytics.Cancellations(ctx)
+}
+
+// RebuildAnalytics phát lại sự kiện theo thứ tự thời gian, sự kiện đã áp dụng được projection bỏ qua
+func (s *statisticsService) RebuildAnalytics(ctx context.Context) (int, error) {
+	replayed := 0
+	for {
+		events, err := s.eventStore.GetAllEvents(ctx, replayed, analyticsRebuildBatch)
+		if err != nil {
+			return replayed, fmt.Errorf("lỗi khi lấy sự kiện: %w", err)
+		}
+
+		for _, event := range events {
+			if err := s.analytics.HandleEvent(event); err != nil {
+				return replayed, fmt.Errorf("lỗi khi cập nhật projection thống kê: %w", err)
+			}
+			replayed++
+		}
+
+		if len(events) < analyticsRebuildBatch {
+			return replayed, nil
+		}
+	}
+}
+
+func countsToMap(rows []productCount) map[string]int {
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts
+}
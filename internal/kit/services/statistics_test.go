@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/shopspring/decimal"
+)
+
+func TestProductStatistics(t *testing.T) {
+	ctx := context.Background()
+	db := newCatalogDB(t)
+	categories := NewCategoryService(db)
+	cities := NewCityService(db, nil)
+	suppliers := NewSupplierService(db)
+	products := NewProductService(db, cities)
+	s := NewStatisticsService(db, nil, projection.NewInMemoryAnalyticsProjection())
+
+	books, _ := categories.CreateCategory(ctx, "Sách")
+	gifts, _ := categories.CreateCategory(ctx, "Quà tặng")
+	empty, _ := categories.CreateCategory(ctx, "Đồ chơi")
+	removed, _ := categories.CreateCategory(ctx, "Ngừng kinh doanh")
+	hanoi, _ := cities.CreateCity(ctx, models.GEO{Name: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542})
+	fahasa, _ := suppliers.CreateSupplier(ctx, "Nhà sách Fahasa", nil)
+	tiki, _ := suppliers.CreateSupplier(ctx, "Tiki", nil)
+
+	create := func(name string, supplierID string, categoryIDs ...string) *models.Product {
+		product, err := products.CreateProduct(ctx, ProductInput{
+			Name:       name,
+			Price:      decimal.NewFromInt(100000),
+			Quantity:   1,
+			Status:     models.MSActive,
+			Categories: categoryIDs,
+			CityID:     hanoi.ID,
+			SupplierID: supplierID,
+		})
+		if err != nil {
+			t.Fatalf("CreateProduct %s: %v", name, err)
+		}
+		return product
+	}
+	create("Dế mèn phiêu lưu ký", fahasa.ID, books.ID, gifts.ID)
+	create("Tắt đèn", fahasa.ID, books.ID)
+	deleted := create("Số đỏ", tiki.ID, books.ID, removed.ID)
+
+	// Sản phẩm và danh mục đã xóa không được đếm
+	if err := products.DeleteProduct(ctx, deleted.ID); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	if err := categories.DeleteCategory(ctx, removed.ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+
+	perCategory, err := s.ProductsPerCategory(ctx)
+	if err != nil {
+		t.Fatalf("ProductsPerCategory: %v", err)
+	}
+	wantCategories := map[string]int{books.ID: 2, gifts.ID: 1, empty.ID: 0}
+	if !reflect.DeepEqual(perCategory, wantCategories) {
+		t.Fatalf("ProductsPerCategory = %v, muốn %v", perCategory, wantCategories)
+	}
+
+	perSupplier, err := s.ProductsPerSupplier(ctx)
+	if err != nil {
+		t.Fatalf("ProductsPerSupplier: %v", err)
+	}
+	wantSuppliers := map[string]int{fahasa.ID: 2, tiki.ID: 0}
+	if !reflect.DeepEqual(perSupplier, wantSuppliers) {
+		t.Fatalf("ProductsPerSupplier = %v, muốn %v", perSupplier, wantSuppliers)
+	}
+}
+
+func TestOrdersPerDayRange(t *testing.T) {
+	s := NewStatisticsService(nil, nil, projection.NewInMemoryAnalyticsProjection())
+
+	from := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, -1)
+	if _, err := s.OrdersPerDay(context.Background(), &from, &to); !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("err = %v, muốn ErrInvalidRecord", err)
+	}
+
+	days, err := s.OrdersPerDay(context.Background(), &from, nil)
+	if err != nil || len(days) != 0 {
+		t.Fatalf("OrdersPerDay = %+v, %v", days, err)
+	}
+}
+
+func TestStatisticsServiceRebuildLegacyEvents(t *testing.T) {
+	ctx := context.Background()
+	store := newLegacyEventStore(t, domaintest.Now, "order-1", "order-2")
+	statistics := NewStatisticsService(nil, store, projection.NewInMemoryAnalyticsProjection())
+
+	// Xây dựng lại hai lần không làm thay đổi kết quả
+	for i := 0; i < 2; i++ {
+		if replayed, err := statistics.RebuildAnalytics(ctx); err != nil || replayed != 8 {
+			t.Fatalf("RebuildAnalytics = %d, %v", replayed, err)
+		}
+	}
+
+	statuses, err := statistics.OrderStatuses(ctx)
+	if err != nil {
+		t.Fatalf("OrderStatuses: %v", err)
+	}
+	byStatus := make(map[domain.OrderStatus]projection.StatusStats, len(statuses))
+	for _, stats := range statuses {
+		byStatus[stats.Status] = stats
+	}
+	if got := byStatus[domain.OrderStatusCreated]; got.Orders != 0 || got.Exits != 2 || got.AverageSeconds != 3600 {
+		t.Fatalf("CREATED = %+v", got)
+	}
+	if got := byStatus[domain.OrderStatusInTransit]; got.Orders != 0 || got.Exits != 2 || got.AverageSeconds != 7200 {
+		t.Fatalf("IN_TRANSIT = %+v", got)
+	}
+	if got := byStatus[domain.OrderStatusCancelled]; got.Orders != 2 {
+		t.Fatalf("CANCELLED = %+v", got)
+	}
+
+	cancellations, err := statistics.Cancellations(ctx)
+	if err != nil {
+		t.Fatalf("Cancellations: %v", err)
+	}
+	if cancellations.TotalOrders != 2 || cancellations.Cancelled != 2 || len(cancellations.Reasons) != 1 || cancellations.Reasons[0].Reason != "Khách đổi ý" {
+		t.Fatalf("Cancellations = %+v", cancellations)
+	}
+}
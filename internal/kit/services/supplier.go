@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// SupplierService quản lý nhà cung cấp sản phẩm
+type SupplierService interface {
+	ListSuppliers(ctx context.Context, search string, offset, limit int) ([]models.Supplier, error)
+	CreateSupplier(ctx context.Context, name string, location *models.GEO) (*models.Supplier, error)
+	UpdateSupplier(ctx context.Context, id string, name *string, status *models.ModelStatus, location *models.GEO) (*models.Supplier, error)
+	DeleteSupplier(ctx context.Context, id string) error
+}
+
+type supplierService struct {
+	db *bun.DB
+}
+
+// NewSupplierService tạo service nhà cung cấp
+func NewSupplierService(db *bun.DB) SupplierService {
+	return &supplierService{db: db}
+}
+
+// ListSuppliers lấy danh sách nhà cung cấp có tên chứa search, mới nhất trước
+func (s *supplierService) ListSuppliers(ctx context.Context, search string, offset, limit int) ([]models.Supplier, error) {
+	offset, limit = catalogPage(offset, limit)
+	suppliers := make([]models.Supplier, 0)
+	query := s.db.NewSelect().
+		Model(&suppliers).
+		OrderExpr("sup.created_time DESC, sup.id ASC").
+		Offset(offset).
+		Limit(limit)
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where(`LOWER(sup.name) LIKE ? ESCAPE '!'`, containsPattern(search))
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn nhà cung cấp: %w", err)
+	}
+	return suppliers, nil
+}
+
+// CreateSupplier tạo nhà cung cấp đang hoạt động, location có thể nil
+func (s *supplierService) CreateSupplier(ctx context.Context, name string, location *models.GEO) (*models.Supplier, error) {
+	if location != nil {
+		if err := validateCoordinates(location.Latitude, location.Longitude); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	supplier := &models.Supplier{
+		ID:          uuid.NewString(),
+		Name:        strings.TrimSpace(name),
+		Status:      models.MSActive,
+		CreatedTime: now,
+		UpdatedTime: now,
+	}
+	supplier.SetLocation(location)
+	if _, err := s.db.NewInsert().Model(supplier).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi tạo nhà cung cấp: %w", err)
+	}
+	return supplier, nil
+}
+
+// UpdateSupplier cập nhật tên, trạng thái và vị trí kho của nhà cung cấp, trường nil giữ nguyên
+func (s *supplierService) UpdateSupplier(ctx context.Context, id string, name *string, status *models.ModelStatus, location *models.GEO) (*models.Supplier, error) {
+	if location != nil {
+		if err := validateCoordinates(location.Latitude, location.Longitude); err != nil {
+			return nil, err
+		}
+	}
+
+	supplier, err := s.getSupplier(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		supplier.Name = strings.TrimSpace(*name)
+	}
+	if status != nil {
+		supplier.Status = *status
+	}
+	if location != nil {
+		supplier.SetLocation(location)
+	}
+	supplier.UpdatedTime = time.Now()
+
+	_, err = s.db.NewUpdate().
+		Model(supplier).
+		Column("name", "status", "location_name", "location_latitude", "location_longitude", "updated_time").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi cập nhật nhà cung cấp: %w", err)
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier xóa mềm nhà cung cấp
+func (s *supplierService) DeleteSupplier(ctx context.Context, id string) error {
+	res, err := s.db.NewDelete().
+		Model((*models.Supplier)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi xóa nhà cung cấp: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("nhà cung cấp %s: %w", id, ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *supplierService) getSupplier(ctx context.Context, id string) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	err := s.db.NewSelect().
+		Model(supplier).
+		Where("sup.id = ?", id).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("nhà cung cấp %s: %w", id, ErrRecordNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn nhà cung cấp: %w", err)
+	}
+	return supplier, nil
+}
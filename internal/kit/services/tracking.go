@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+)
+
+// trackingRebuildBatch là số sự kiện được đọc mỗi lần khi xây dựng lại projection theo dõi
+const trackingRebuildBatch = 1000
+
+// TrackingService phục vụ trang theo dõi đơn hàng công khai
+type TrackingService interface {
+	// GetTracking lấy thông tin theo dõi theo số theo dõi
+	GetTracking(ctx context.Context, trackingNumber string) (*projection.TrackingInfo, error)
+
+	// RebuildTracking phát lại toàn bộ sự kiện vào projection theo dõi, trả về số sự kiện đã phát lại
+	RebuildTracking(ctx context.Context) (int, error)
+}
+
+// trackingService triển khai TrackingService
+type trackingService struct {
+	eventStore eventstore.EventStore
+	tracking   projection.TrackingProjection
+}
+
+// NewTrackingService tạo một instance mới của TrackingService
+func NewTrackingService(eventStore eventstore.EventStore, tracking projection.TrackingProjection) TrackingService {
+	return &trackingService{
+		eventStore: eventStore,
+		tracking:   tracking,
+	}
+}
+
+// GetTracking lấy thông tin theo dõi theo số theo dõi
+func (s *trackingService) GetTracking(ctx context.Context, trackingNumber string) (*projection.TrackingInfo, error) {
+	if trackingNumber == "" {
+		return nil, fmt.Errorf("số theo dõi không được để trống")
+	}
+
+	return s.tracking.GetByTrackingNumber(ctx, trackingNumber)
+}
+
+// RebuildTracking phát lại sự kiện theo thứ tự thời gian, sự kiện đã áp dụng được projection bỏ qua
+func (s *trackingService) RebuildTracking(ctx context.Context) (int, error) {
+	replayed := 0
+	for {
+		events, err := s.eventStore.GetAllEvents(ctx, replayed, trackingRebuildBatch)
+		if err != nil {
+			return replayed, fmt.Errorf("lỗi khi lấy sự kiện: %w", err)
+		}
+
+		for _, event := range events {
+			if err := s.tracking.HandleEvent(event); err != nil {
+				return replayed, fmt.Errorf("lỗi khi cập nhật projection theo dõi: %w", err)
+			}
+			replayed++
+		}
+
+		if len(events) < trackingRebuildBatch {
+			return replayed, nil
+		}
+	}
+}
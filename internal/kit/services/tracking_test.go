@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+)
+
+func TestTrackingServiceRebuild(t *testing.T) {
+	ctx := context.Background()
+
+	store := eventstore.NewInMemoryEventStore()
+	repo := repository.NewInMemoryOrderRepository()
+	bus := eventbus.NewInMemoryEventBus()
+	if err := bus.Subscribe(repo, domain.RegisteredEventTypes()...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	opts := []domain.OrderOption{
+		domain.WithClock(domain.FixedClock(domaintest.Now)),
+		domain.WithIDGenerator(&domaintest.SequenceIDs{}),
+		domain.WithDeliveryEstimator(NewETAService(DefaultETARules())),
+	}
+	orders := NewOrderService(store, repo, bus, opts...)
+
+	origin := domain.Location{City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009}
+	destination := domain.Location{City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412}
+	items := []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}}
+
+	id, trackingNumber, err := orders.CreateOrder(ctx, "CUS-001", origin, destination, items, domain.ServiceLevelExpress, domain.PaymentTerms{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if err := orders.UpdateOrderStatus(ctx, id, domain.OrderStatusInTransit, &destination, "Giao cho tài xế ca đêm"); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+
+	// Projection được tạo sau khi đã có sự kiện nên chỉ có dữ liệu sau khi xây dựng lại
+	tracking := NewTrackingService(store, projection.NewInMemoryTrackingProjection())
+	if _, err := tracking.GetTracking(ctx, trackingNumber); !errors.Is(err, projection.ErrTrackingNotFound) {
+		t.Fatalf("err = %v, muốn ErrTrackingNotFound", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		replayed, err := tracking.RebuildTracking(ctx)
+		if err != nil {
+			t.Fatalf("RebuildTracking: %v", err)
+		}
+		if replayed != 4 {
+			t.Fatalf("đã phát lại %d sự kiện, muốn 4", replayed)
+		}
+	}
+
+	info, err := tracking.GetTracking(ctx, trackingNumber)
+	if err != nil {
+		t.Fatalf("GetTracking: %v", err)
+	}
+	if info.Status != domain.OrderStatusInTransit || info.ServiceLevel != domain.ServiceLevelExpress || info.EstimatedDelivery == nil {
+		t.Fatalf("info = %+v", info)
+	}
+	if len(info.Updates) != 2 || info.Updates[1].Location != "Hà Nội" {
+		t.Fatalf("Updates = %+v", info.Updates)
+	}
+}
+
+// legacyOrderEvents tạo luồng sự kiện của đơn hàng được ghi trước khi sự kiện có phiên bản theo luồng,
+// mọi sự kiện đều mang phiên bản 1 trong dữ liệu
+func legacyOrderEvents(orderID string, at time.Time) []domain.Event {
+	base := func(i int, eventType domain.EventType) domain.BaseEvent {
+		return domain.BaseEvent{
+			ID:          fmt.Sprintf("%s-%d", orderID, i),
+			AggregateID: orderID,
+			Type:        eventType,
+			Timestamp:   at.Add(time.Duration(i) * time.Hour),
+			Version:     1,
+		}
+	}
+	hanoi := &domain.Location{Address: "Kho Hà Nội", City: "Hà Nội"}
+
+	return []domain.Event{
+		domain.OrderCreatedEvent{
+			BaseEvent:      base(0, domain.OrderCreatedType),
+			CustomerID:     "CUS-001",
+			TrackingNumber: "TRK-" + orderID,
+			Origin:         domain.Location{City: "Hồ Chí Minh"},
+			Destination:    domain.Location{City: "Hà Nội"},
+			Items:          []domain.OrderItem{{ID: "ITEM-1", Name: "Sách", Quantity: 1}},
+		},
+		domain.NewOrderStatusUpdatedEvent(base(1, ""), domain.OrderStatusCreated, domain.OrderStatusInTransit, hanoi, ""),
+		domain.NewOrderNoteAddedEvent(base(2, ""), "Gọi trước khi giao"),
+		domain.NewOrderCancelledEvent(base(3, ""), domain.OrderStatusInTransit, "Khách đổi ý"),
+	}
+}
+
+// newLegacyEventStore tạo event store SQLite chứa luồng sự kiện cũ của các đơn hàng
+func newLegacyEventStore(t *testing.T, at time.Time, orderIDs ...string) eventstore.EventStore {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	store, err := eventstore.NewSQLiteEventStore(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteEventStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for _, orderID := range orderIDs {
+		if err := store.SaveEvents(context.Background(), orderID, legacyOrderEvents(orderID, at)); err != nil {
+			t.Fatalf("SaveEvents: %v", err)
+		}
+	}
+	return store
+}
+
+func TestTrackingServiceRebuildLegacyEvents(t *testing.T) {
+	ctx := context.Background()
+	store := newLegacyEventStore(t, domaintest.Now, "order-1")
+	tracking := NewTrackingService(store, projection.NewInMemoryTrackingProjection())
+
+	if replayed, err := tracking.RebuildTracking(ctx); err != nil || replayed != 4 {
+		t.Fatalf("RebuildTracking = %d, %v", replayed, err)
+	}
+
+	info, err := tracking.GetTracking(ctx, "TRK-order-1")
+	if err != nil {
+		t.Fatalf("GetTracking: %v", err)
+	}
+	if info.Status != domain.OrderStatusCancelled || info.CurrentCity != "Hà Nội" || info.Version != 4 {
+		t.Fatalf("info = %+v", info)
+	}
+	if len(info.Updates) != 3 || info.Updates[1].Status != domain.OrderStatusInTransit || info.Updates[2].Status != domain.OrderStatusCancelled {
+		t.Fatalf("Updates = %+v", info.Updates)
+	}
+}
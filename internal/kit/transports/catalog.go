@@ -0,0 +1,201 @@
+package transports
+
+import (
+	"context"
+	"errors"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/utils"
+	"net/http"
+)
+
+// catalogOptions trả lỗi theo định dạng meta/data với HTTP status lấy từ mã lỗi
+var catalogOptions = []httptransport.ServerOption{
+	httptransport.ServerErrorEncoder(encodeCatalogError),
+}
+
+// encodeCatalogError giống utils.EncodeError nhưng dùng mã lỗi làm HTTP status: 422 khi dữ liệu không hợp lệ,
+// mã của utils.Message nếu nằm trong khoảng lỗi, còn lại 500
+func encodeCatalogError(ctx context.Context, err error, w http.ResponseWriter) {
+	msgResponse := utils.Message{Code: http.StatusInternalServerError, Message: "Internal Server Error"}
+	var fieldError validator.ValidationErrors
+	var message utils.Message
+	switch {
+	case errors.As(err, &fieldError):
+		msgResponse = utils.Message{Code: http.StatusUnprocessableEntity, Message: err.Error()}
+	case errors.As(err, &message):
+		msgResponse = message
+	}
+	status := msgResponse.Code
+	if status < http.StatusBadRequest || status > 599 {
+		status = http.StatusInternalServerError
+	}
+	utils.ResponseWriter(w, status, utils.SetDefaultResponse(ctx, msgResponse))
+}
+
+// MakeCategoryHandlers đăng ký các route quản lý danh mục
+func MakeCategoryHandlers(r *mux.Router, ep endpoints.CategoryEndpoints, basePath string) {
+	validate := validator.New()
+
+	// POST /categories/list - Danh sách danh mục
+	r.Methods("POST").Path(basePath + "categories/list").Handler(httptransport.NewServer(
+		ep.ListCategories,
+		transforms.DecodeSearchRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// POST /categories - Tạo danh mục
+	r.Methods("POST").Path(basePath + "categories").Handler(httptransport.NewServer(
+		ep.CreateCategory,
+		transforms.DecodeCreateCategoryRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// PUT /categories/{uid} - Cập nhật danh mục
+	r.Methods("PUT").Path(basePath + "categories/{uid}").Handler(httptransport.NewServer(
+		ep.UpdateCategory,
+		transforms.DecodeUpdateCategoryRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// DELETE /categories/{uid} - Xóa danh mục
+	r.Methods("DELETE").Path(basePath + "categories/{uid}").Handler(httptransport.NewServer(
+		ep.DeleteCategory,
+		transforms.DecodeRecordRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+}
+
+// MakeCityHandlers đăng ký các route quản lý thành phố
+func MakeCityHandlers(r *mux.Router, ep endpoints.CityEndpoints, basePath string) {
+	validate := validator.New()
+
+	// POST /cities/list - Danh sách thành phố
+	r.Methods("POST").Path(basePath + "cities/list").Handler(httptransport.NewServer(
+		ep.ListCities,
+		transforms.DecodeSearchRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// POST /cities - Tạo thành phố
+	r.Methods("POST").Path(basePath + "cities").Handler(httptransport.NewServer(
+		ep.CreateCity,
+		transforms.DecodeCreateCityRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// PUT /cities/{uid} - Cập nhật thành phố
+	r.Methods("PUT").Path(basePath + "cities/{uid}").Handler(httptransport.NewServer(
+		ep.UpdateCity,
+		transforms.DecodeUpdateCityRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// DELETE /cities/{uid} - Xóa thành phố
+	r.Methods("DELETE").Path(basePath + "cities/{uid}").Handler(httptransport.NewServer(
+		ep.DeleteCity,
+		transforms.DecodeRecordRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+}
+
+// MakeSupplierHandlers đăng ký các route quản lý nhà cung cấp
+func MakeSupplierHandlers(r *mux.Router, ep endpoints.SupplierEndpoints, basePath string) {
+	validate := validator.New()
+
+	// POST /suppliers/list - Danh sách nhà cung cấp
+	r.Methods("POST").Path(basePath + "suppliers/list").Handler(httptransport.NewServer(
+		ep.ListSuppliers,
+		transforms.DecodeSearchRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// POST /suppliers - Tạo nhà cung cấp
+	r.Methods("POST").Path(basePath + "suppliers").Handler(httptransport.NewServer(
+		ep.CreateSupplier,
+		transforms.DecodeCreateSupplierRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// PUT /suppliers/{uid} - Cập nhật nhà cung cấp
+	r.Methods("PUT").Path(basePath + "suppliers/{uid}").Handler(httptransport.NewServer(
+		ep.UpdateSupplier,
+		transforms.DecodeUpdateSupplierRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// DELETE /suppliers/{uid} - Xóa nhà cung cấp
+	r.Methods("DELETE").Path(basePath + "suppliers/{uid}").Handler(httptransport.NewServer(
+		ep.DeleteSupplier,
+		transforms.DecodeRecordRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+}
+
+// MakeProductHandlers đăng ký các route quản lý sản phẩm
+func MakeProductHandlers(r *mux.Router, ep endpoints.ProductEndpoints, basePath string) {
+	validate := validator.New()
+
+	// POST /products/list - Danh sách sản phẩm theo bộ lọc
+	r.Methods("POST").Path(basePath + "products/list").Handler(httptransport.NewServer(
+		ep.ListProducts,
+		transforms.DecodeProductSearchRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// POST /products - Tạo sản phẩm
+	r.Methods("POST").Path(basePath + "products").Handler(httptransport.NewServer(
+		ep.CreateProduct,
+		transforms.DecodeProductRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// GET /products/{uid} - Chi tiết sản phẩm
+	r.Methods("GET").Path(basePath + "products/{uid}").Handler(httptransport.NewServer(
+		ep.GetProduct,
+		transforms.DecodeRecordRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// GET /products/{uid}/distance?lat=&lng= hoặc ?city_id= - Khoảng cách tới kho nhà cung cấp của sản phẩm
+	r.Methods("GET").Path(basePath + "products/{uid}/distance").Handler(httptransport.NewServer(
+		ep.ProductDistance,
+		transforms.DecodeProductDistanceRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// PUT /products/{uid} - Ghi đè sản phẩm
+	r.Methods("PUT").Path(basePath + "products/{uid}").Handler(httptransport.NewServer(
+		ep.UpdateProduct,
+		transforms.DecodeProductRequest(validate),
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// DELETE /products/{uid} - Xóa sản phẩm
+	r.Methods("DELETE").Path(basePath + "products/{uid}").Handler(httptransport.NewServer(
+		ep.DeleteProduct,
+		transforms.DecodeRecordRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+}
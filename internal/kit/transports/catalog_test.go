@@ -0,0 +1,57 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+func TestEncodeCatalogError(t *testing.T) {
+	invalid := validator.New().Struct(struct {
+		Name string `validate:"required"`
+	}{})
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   int
+	}{
+		{"dữ liệu không hợp lệ", invalid, http.StatusUnprocessableEntity, http.StatusUnprocessableEntity},
+		{"không tìm thấy", utils.Message{Code: http.StatusNotFound, Message: "Không tìm thấy danh mục"}, http.StatusNotFound, http.StatusNotFound},
+		{"mã lỗi được bọc", fmt.Errorf("lỗi khi lấy danh mục: %w", utils.Message{Code: http.StatusConflict, Message: "Trùng mã"}), http.StatusConflict, http.StatusConflict},
+		{"mã ngoài khoảng lỗi", utils.Message{Code: http.StatusOK, Message: "OK"}, http.StatusInternalServerError, http.StatusOK},
+		{"lỗi khác", errors.New("mất kết nối"), http.StatusInternalServerError, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			encodeCatalogError(context.Background(), tt.err, w)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, muốn %d", w.Code, tt.status)
+			}
+			var body struct {
+				Meta struct {
+					Code int `json:"code"`
+				} `json:"meta"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Meta.Code != tt.code {
+				t.Fatalf("meta.code = %d, %v, muốn %d", body.Meta.Code, err, tt.code)
+			}
+		})
+	}
+
+	// utils.EncodeError dùng chung giữ HTTP status 500
+	w := httptest.NewRecorder()
+	utils.EncodeError(context.Background(), utils.Message{Code: http.StatusNotFound, Message: "Không tìm thấy"}, w)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("utils.EncodeError status = %d", w.Code)
+	}
+}
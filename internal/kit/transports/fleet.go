@@ -0,0 +1,106 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// MakeFleetHandlers đăng ký các API tài xế, phương tiện và phân công đơn hàng
+func MakeFleetHandlers(r *mux.Router, ep endpoints.FleetEndpoints, basePath string) {
+	validate := validator.New()
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+		httptransport.ServerBefore(populateRequestSource),
+	}
+
+	// POST /drivers - Đăng ký tài xế mới
+	r.Methods("POST").Path(basePath + "/drivers").Handler(httptransport.NewServer(
+		ep.RegisterDriver,
+		transforms.DecodeRegisterDriverRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// GET /drivers/{id} - Lấy thông tin tài xế
+	r.Methods("GET").Path(basePath + "/drivers/{id}").Handler(httptransport.NewServer(
+		ep.GetDriver,
+		transforms.DecodeFleetIDRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// POST /drivers/{id}/vehicle - Gán phương tiện cho tài xế
+	r.Methods("POST").Path(basePath + "/drivers/{id}/vehicle").Handler(httptransport.NewServer(
+		ep.AssignVehicle,
+		transforms.DecodeAssignVehicleRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /drivers/{id}/deactivate - Ngừng nhận đơn hàng mới cho tài xế
+	r.Methods("POST").Path(basePath + "/drivers/{id}/deactivate").Handler(httptransport.NewServer(
+		ep.DeactivateDriver,
+		transforms.DecodeFleetReasonRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /drivers/{id}/activate - Cho tài xế hoạt động trở lại
+	r.Methods("POST").Path(basePath + "/drivers/{id}/activate").Handler(httptransport.NewServer(
+		ep.ActivateDriver,
+		transforms.DecodeFleetIDRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /drivers/{id}/orders?status= - Đơn hàng được phân công cho tài xế và tải trọng hiện tại
+	r.Methods("GET").Path(basePath + "/drivers/{id}/orders").Handler(httptransport.NewServer(
+		ep.DriverOrders,
+		transforms.DecodeDriverOrdersRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// POST /vehicles - Đăng ký phương tiện mới
+	r.Methods("POST").Path(basePath + "/vehicles").Handler(httptransport.NewServer(
+		ep.RegisterVehicle,
+		transforms.DecodeRegisterVehicleRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// GET /vehicles/{id} - Lấy thông tin phương tiện
+	r.Methods("GET").Path(basePath + "/vehicles/{id}").Handler(httptransport.NewServer(
+		ep.GetVehicle,
+		transforms.DecodeFleetIDRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// POST /vehicles/{id}/retire - Ngừng sử dụng phương tiện
+	r.Methods("POST").Path(basePath + "/vehicles/{id}/retire").Handler(httptransport.NewServer(
+		ep.RetireVehicle,
+		transforms.DecodeFleetReasonRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /orders/{id}/assign - Phân công đơn hàng cho tài xế trong giới hạn tải trọng phương tiện
+	r.Methods("POST").Path(basePath + "/orders/{id}/assign").Handler(httptransport.NewServer(
+		ep.AssignOrder,
+		transforms.DecodeAssignOrderRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /orders/{id}/unassign - Hủy phân công tài xế của đơn hàng chưa được đưa đi giao
+	r.Methods("POST").Path(basePath + "/orders/{id}/unassign").Handler(httptransport.NewServer(
+		ep.UnassignOrder,
+		transforms.DecodeFleetReasonRequest(validate),
+		encodeResponse,
+		options...,
+	))
+}
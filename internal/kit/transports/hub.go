@@ -0,0 +1,82 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// MakeHubHandlers đăng ký các API hub, quét đơn hàng và cảnh báo đơn hàng nằm quá lâu tại hub
+func MakeHubHandlers(r *mux.Router, ep endpoints.HubEndpoints, basePath string) {
+	validate := validator.New()
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+		httptransport.ServerBefore(populateRequestSource),
+	}
+
+	// GET /hubs - Danh sách hub
+	r.Methods("GET").Path(basePath + "/hubs").Handler(httptransport.NewServer(
+		ep.ListHubs,
+		httptransport.NopRequestDecoder,
+		encodeResponse,
+		options...,
+	))
+
+	// POST /hubs - Tạo hub mới
+	r.Methods("POST").Path(basePath + "/hubs").Handler(httptransport.NewServer(
+		ep.CreateHub,
+		transforms.DecodeCreateHubRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// GET /hubs/alerts?hub= - Đơn hàng nằm tại hub quá giới hạn, phải đăng ký trước /hubs/{code}
+	r.Methods("GET").Path(basePath + "/hubs/alerts").Handler(httptransport.NewServer(
+		ep.DwellAlerts,
+		transforms.DecodeHubAlertsRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /hubs/{code} - Thông tin hub
+	r.Methods("GET").Path(basePath + "/hubs/{code}").Handler(httptransport.NewServer(
+		ep.GetHub,
+		transforms.DecodeHubCodeRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// PUT /hubs/{code} - Cập nhật hub
+	r.Methods("PUT").Path(basePath + "/hubs/{code}").Handler(httptransport.NewServer(
+		ep.UpdateHub,
+		transforms.DecodeUpdateHubRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// GET /hubs/{code}/inventory - Đơn hàng đang nằm tại hub
+	r.Methods("GET").Path(basePath + "/hubs/{code}/inventory").Handler(httptransport.NewServer(
+		ep.Inventory,
+		transforms.DecodeHubCodeRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// POST /hubs/{code}/scan-in - Quét đơn hàng vào hub
+	r.Methods("POST").Path(basePath + "/hubs/{code}/scan-in").Handler(httptransport.NewServer(
+		ep.ScanIn,
+		transforms.DecodeHubScanRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /hubs/{code}/scan-out - Quét đơn hàng ra khỏi hub
+	r.Methods("POST").Path(basePath + "/hubs/{code}/scan-out").Handler(httptransport.NewServer(
+		ep.ScanOut,
+		transforms.DecodeHubScanRequest(validate),
+		encodeResponse,
+		options...,
+	))
+}
@@ -0,0 +1,41 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+	"net/http"
+)
+
+// MakeImportHandlers đăng ký các route nhập đơn hàng, cần gọi trước MakeOrderHandlers
+func MakeImportHandlers(r *mux.Router, ep endpoints.ImportEndpoints, basePath string) {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+	}
+
+	// POST /orders/imports?format=csv|jsonl - Nhập đơn hàng từ file, trả về job để theo dõi
+	r.Methods("POST").Path(basePath + "/orders/imports").Handler(httptransport.NewServer(
+		ep.StartImport,
+		transforms.DecodeStartImportRequest,
+		encodeAcceptedResponse,
+		options...,
+	))
+
+	// GET /orders/imports/{id} - Lấy trạng thái và kết quả từng dòng của job nhập đơn hàng
+	r.Methods("GET").Path(basePath + "/orders/imports/{id}").Handler(httptransport.NewServer(
+		ep.GetImportJob,
+		transforms.DecodeGetImportJobRequest,
+		encodeResponse,
+		options...,
+	))
+}
+
+// encodeAcceptedResponse trả về 202 cho các tác vụ chạy nền
+func encodeAcceptedResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,40 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// MakeLocationHandlers đăng ký các API nhận và tra cứu vị trí GPS của tài xế
+func MakeLocationHandlers(r *mux.Router, ep endpoints.LocationEndpoints, basePath string) {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+		httptransport.ServerBefore(populateRequestSource),
+	}
+
+	// POST /drivers/{id}/locations - Tài xế gửi một hoặc nhiều vị trí GPS
+	r.Methods("POST").Path(basePath + "/drivers/{id}/locations").Handler(httptransport.NewServer(
+		ep.RecordPings,
+		transforms.DecodeRecordPingsRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /drivers/{id}/location - Vị trí mới nhất của tài xế
+	r.Methods("GET").Path(basePath + "/drivers/{id}/location").Handler(httptransport.NewServer(
+		ep.DriverLocation,
+		transforms.DecodeDriverLocationRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /orders/{id}/location - Vị trí hiện tại của đơn hàng theo tài xế được phân công
+	r.Methods("GET").Path(basePath + "/orders/{id}/location").Handler(httptransport.NewServer(
+		ep.OrderLocation,
+		transforms.DecodeOrderLocationRequest,
+		encodeResponse,
+		options...,
+	))
+}
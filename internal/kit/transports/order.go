@@ -3,18 +3,24 @@ package transports
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	httptransport "github.com/go-kit/kit/transport/http"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/kit/services"
 	"github.com/quyenle-97/init/internal/transforms"
+	"io"
+	"net"
 	"net/http"
+	"strings"
 )
 
 func MakeOrderHandlers(r *mux.Router, ep endpoints.OrderEndpoints, basePath string) {
 	validate := validator.New()
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(encodeError),
+		httptransport.ServerBefore(populateRequestSource),
 	}
 
 	// POST /orders - Create a new order
@@ -25,7 +31,31 @@ func MakeOrderHandlers(r *mux.Router, ep endpoints.OrderEndpoints, basePath stri
 		options...,
 	))
 
-	// GET /orders/{id} - Lấy thông tin chi tiết đơn hàng theo ID
+	// POST /orders/status:batch - Cập nhật trạng thái nhiều đơn hàng cùng lúc (quét tại hub)
+	r.Methods("POST").Path(basePath + "/orders/status:batch").Handler(httptransport.NewServer(
+		ep.BatchUpdateStatus,
+		transforms.DecodeBatchUpdateOrderStatusRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// GET /orders/export?format=csv|xlsx|ndjson&customer_id=&status=&include_events= - Xuất danh sách đơn hàng
+	r.Methods("GET").Path(basePath + "/orders/export").Handler(httptransport.NewServer(
+		ep.ExportOrders,
+		transforms.DecodeExportOrdersRequest,
+		encodeExportResponse,
+		options...,
+	))
+
+	// GET /orders/near?lat=&lng=&radius=&status=&limit= - Tìm đơn hàng quanh một tọa độ, radius tính theo km
+	r.Methods("GET").Path(basePath + "/orders/near").Handler(httptransport.NewServer(
+		ep.ListOrdersNear,
+		transforms.DecodeListOrdersNearRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /orders/{id}?as_of=<RFC3339|version> - Lấy thông tin chi tiết đơn hàng theo ID, tại một thời điểm nếu có as_of
 	r.Methods("GET").Path(basePath + "/orders/{id}").Handler(httptransport.NewServer(
 		ep.GetOrder,
 		transforms.DecodeGetOrderRequest,
@@ -65,6 +95,14 @@ func MakeOrderHandlers(r *mux.Router, ep endpoints.OrderEndpoints, basePath stri
 		options...,
 	))
 
+	// GET /orders/{id}/diff?from=&to= - So sánh trạng thái đơn hàng giữa hai thời điểm
+	r.Methods("GET").Path(basePath + "/orders/{id}/diff").Handler(httptransport.NewServer(
+		ep.DiffOrder,
+		transforms.DecodeDiffOrderRequest,
+		encodeResponse,
+		options...,
+	))
+
 	// GET /orders/{id}/history - Lấy lịch sử đơn hàng
 	r.Methods("GET").Path(basePath + "/orders/{id}/history").Handler(httptransport.NewServer(
 		ep.GetOrderHistory,
@@ -88,6 +126,37 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 	return json.NewEncoder(w).Encode(response)
 }
 
+// encodeExportResponse ghi file xuất trực tiếp vào response.
+// Lỗi trước khi ghi byte đầu tiên được trả về như lỗi thông thường,
+// lỗi giữa chừng thì ngắt kết nối để client không nhận một file thiếu dữ liệu mà tưởng là đầy đủ.
+func encodeExportResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(transforms.ExportOrdersResponse)
+	w.Header().Set("Content-Type", res.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, res.Filename))
+
+	cw := &countingWriter{w: w}
+	if err := res.Write(ctx, cw); err != nil {
+		if cw.n == 0 {
+			w.Header().Del("Content-Disposition")
+			return err
+		}
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+// countingWriter đếm số byte đã ghi vào response
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusInternalServerError)
@@ -96,3 +165,64 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		"error": err.Error(),
 	})
 }
+
+type trustedProxiesKey struct{}
+
+// TrustProxies cho phép đọc IP client từ X-Forwarded-For và X-Real-IP khi request đến từ một trong các proxy trusted,
+// không có proxy tin cậy thì IP client luôn là địa chỉ kết nối trực tiếp
+func TrustProxies(trusted []*net.IPNet) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), trustedProxiesKey{}, trusted)))
+		})
+	}
+}
+
+// populateRequestSource gắn IP của client vào ctx để ghi vào metadata sự kiện
+func populateRequestSource(ctx context.Context, r *http.Request) context.Context {
+	trusted, _ := ctx.Value(trustedProxiesKey{}).([]*net.IPNet)
+	ip, remote := clientIP(r, trusted)
+	return services.WithRequestSource(ctx, services.RequestSource{IP: ip, RemoteAddr: remote})
+}
+
+// clientIP trả về IP client và IP kết nối trực tiếp, header chuyển tiếp chỉ được tin khi kết nối trực tiếp là proxy tin cậy
+func clientIP(r *http.Request, trusted []*net.IPNet) (ip, remote string) {
+	remote = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !isTrustedProxy(remote, trusted) {
+		return remote, remote
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		// Client có thể tự thêm IP vào đầu header nên đi từ phải sang trái, bỏ qua các proxy tin cậy
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if i == 0 || !isTrustedProxy(hop, trusted) {
+				return hop, remote
+			}
+		}
+	}
+	if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); real != "" {
+		return real, remote
+	}
+	return remote, remote
+}
+
+func isTrustedProxy(addr string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package transports
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"client kết nối trực tiếp", "203.0.113.7:51000", nil, "203.0.113.7"},
+		{"client kết nối trực tiếp giả mạo X-Forwarded-For", "203.0.113.7:51000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.7"},
+		{"client kết nối trực tiếp giả mạo X-Real-IP", "203.0.113.7:51000", map[string]string{"X-Real-IP": "1.2.3.4"}, "203.0.113.7"},
+		{"qua proxy tin cậy", "10.0.0.5:443", map[string]string{"X-Forwarded-For": "198.51.100.20"}, "198.51.100.20"},
+		{"qua nhiều proxy tin cậy", "10.0.0.5:443", map[string]string{"X-Forwarded-For": "198.51.100.20, 10.0.0.9"}, "198.51.100.20"},
+		{"client thêm IP giả vào đầu header", "10.0.0.5:443", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.20"}, "198.51.100.20"},
+		{"X-Real-IP qua proxy tin cậy", "10.0.0.5:443", map[string]string{"X-Real-IP": "198.51.100.20"}, "198.51.100.20"},
+		{"proxy tin cậy không gửi header", "10.0.0.5:443", nil, "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			if ip, _ := clientIP(r, trusted); ip != tt.want {
+				t.Fatalf("clientIP = %q, muốn %q", ip, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustProxies(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	for _, tt := range []struct {
+		name    string
+		trusted []*net.IPNet
+		want    string
+	}{
+		{"chưa cấu hình proxy tin cậy", nil, "10.0.0.5"},
+		{"có proxy tin cậy", []*net.IPNet{proxies}, "198.51.100.20"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			r := mux.NewRouter()
+			r.Use(TrustProxies(tt.trusted))
+			r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				trusted, _ := r.Context().Value(trustedProxiesKey{}).([]*net.IPNet)
+				got, _ = clientIP(r, trusted)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.5:443"
+			req.Header.Set("X-Forwarded-For", "198.51.100.20")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Fatalf("IP client = %q, muốn %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// MakePaymentHandlers đăng ký các API thu hộ (COD) và đối soát với tài xế
+func MakePaymentHandlers(r *mux.Router, ep endpoints.PaymentEndpoints, basePath string) {
+	validate := validator.New()
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+		httptransport.ServerBefore(populateRequestSource),
+	}
+
+	// POST /orders/{id}/payment/collect - Tài xế ghi nhận số tiền đã thu khi giao hàng
+	r.Methods("POST").Path(basePath + "/orders/{id}/payment/collect").Handler(httptransport.NewServer(
+		ep.CollectPayment,
+		transforms.DecodeCollectPaymentRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /orders/{id}/payment/refund - Ghi nhận đã hoàn tiền cho đơn hàng đã thu tiền bị hủy
+	r.Methods("POST").Path(basePath + "/orders/{id}/payment/refund").Handler(httptransport.NewServer(
+		ep.RefundPayment,
+		transforms.DecodeRefundPaymentRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// POST /cod/remittances - Tài xế nộp tiền thu hộ, order_ids rỗng là nộp tất cả các khoản chưa nộp
+	r.Methods("POST").Path(basePath + "/cod/remittances").Handler(httptransport.NewServer(
+		ep.RemitPayments,
+		transforms.DecodeRemitPaymentsRequest(validate),
+		encodeResponse,
+		options...,
+	))
+
+	// GET /cod/balances?driver_id=&from=&to=&include_settled= - Đối soát thu hộ theo tài xế và ngày thu
+	r.Methods("GET").Path(basePath + "/cod/balances").Handler(httptransport.NewServer(
+		ep.CODBalances,
+		transforms.DecodeCODBalancesRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /cod/drivers/{driver_id}/outstanding - Các khoản tài xế đã thu nhưng chưa nộp
+	r.Methods("GET").Path(basePath + "/cod/drivers/{driver_id}/outstanding").Handler(httptransport.NewServer(
+		ep.OutstandingCOD,
+		transforms.DecodeOutstandingCODRequest,
+		encodeResponse,
+		options...,
+	))
+}
@@ -0,0 +1,22 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+func MakePrivacyHandlers(r *mux.Router, ep endpoints.PrivacyEndpoints, basePath string) {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+	}
+
+	// DELETE /customers/{id}/personal-data - Xóa dữ liệu cá nhân của khách hàng (crypto-shredding)
+	r.Methods("DELETE").Path(basePath + "/customers/{id}/personal-data").Handler(httptransport.NewServer(
+		ep.ErasePersonalData,
+		transforms.DecodeErasePersonalDataRequest,
+		encodeResponse,
+		options...,
+	))
+}
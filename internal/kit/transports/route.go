@@ -0,0 +1,32 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// MakeRouteHandlers đăng ký các API lập và tra cứu lộ trình giao hàng của tài xế
+func MakeRouteHandlers(r *mux.Router, ep endpoints.RouteEndpoints, basePath string) {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeError),
+		httptransport.ServerBefore(populateRequestSource),
+	}
+
+	// POST /drivers/{id}/route - Lập lộ trình cho các đơn hàng được phân công cho tài xế
+	r.Methods("POST").Path(basePath + "/drivers/{id}/route").Handler(httptransport.NewServer(
+		ep.PlanRoute,
+		transforms.DecodePlanRouteRequest,
+		encodeResponse,
+		options...,
+	))
+
+	// GET /drivers/{id}/route - Lộ trình được lập gần nhất của tài xế
+	r.Methods("GET").Path(basePath + "/drivers/{id}/route").Handler(httptransport.NewServer(
+		ep.GetRoute,
+		transforms.DecodeDriverRouteRequest,
+		encodeResponse,
+		options...,
+	))
+}
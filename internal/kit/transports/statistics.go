@@ -0,0 +1,52 @@
+package transports
+
+import (
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/transforms"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// MakeStatisticsHandlers đăng ký các route thống kê sản phẩm và đơn hàng
+func MakeStatisticsHandlers(r *mux.Router, ep endpoints.StatisticsEndpoints, basePath string) {
+	// GET /statistics/products-per-category - Số sản phẩm theo danh mục
+	r.Methods("GET").Path(basePath + "statistics/products-per-category").Handler(httptransport.NewServer(
+		ep.ProductsPerCategory,
+		transforms.DecodeEmptyRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// GET /statistics/products-per-supplier - Số sản phẩm theo nhà cung cấp
+	r.Methods("GET").Path(basePath + "statistics/products-per-supplier").Handler(httptransport.NewServer(
+		ep.ProductsPerSupplier,
+		transforms.DecodeEmptyRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// GET /statistics/orders-per-day?from=&to= - Số đơn hàng được tạo theo ngày
+	r.Methods("GET").Path(basePath + "statistics/orders-per-day").Handler(httptransport.NewServer(
+		ep.OrdersPerDay,
+		transforms.DecodeOrdersPerDayRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// GET /statistics/order-statuses - Phân bố và thời gian trung bình theo trạng thái đơn hàng
+	r.Methods("GET").Path(basePath + "statistics/order-statuses").Handler(httptransport.NewServer(
+		ep.OrderStatuses,
+		transforms.DecodeEmptyRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+
+	// GET /statistics/cancellations - Tỷ lệ hủy đơn hàng theo lý do
+	r.Methods("GET").Path(basePath + "statistics/cancellations").Handler(httptransport.NewServer(
+		ep.Cancellations,
+		transforms.DecodeEmptyRequest,
+		utils.EncodeResponseHTTP,
+		catalogOptions...,
+	))
+}
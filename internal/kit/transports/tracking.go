@@ -0,0 +1,49 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/endpoints"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/transforms"
+)
+
+// MakeTrackingHandlers đăng ký trang theo dõi công khai, không yêu cầu xác thực
+func MakeTrackingHandlers(r *mux.Router, ep endpoints.TrackingEndpoints, basePath string) {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(encodeTrackingError),
+		// Trang theo dõi có thể được nhúng vào website của người bán
+		httptransport.ServerAfter(func(ctx context.Context, w http.ResponseWriter) context.Context {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			return ctx
+		}),
+	}
+
+	// GET /tracking/{tracking_number} - Lấy thông tin theo dõi theo số theo dõi
+	r.Methods("GET").Path(basePath + "/tracking/{tracking_number}").Handler(httptransport.NewServer(
+		ep.GetTracking,
+		transforms.DecodeGetTrackingRequest,
+		encodeResponse,
+		options...,
+	))
+}
+
+// encodeTrackingError trả về 404 khi không tìm thấy số theo dõi
+func encodeTrackingError(ctx context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if !errors.Is(err, projection.ErrTrackingNotFound) {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/uptrace/bun"
+)
+
+// OrderStatsModel là trạng thái hiện tại của đơn hàng trong projection thống kê, ID trùng với ID đơn hàng
+type OrderStatsModel struct {
+	bun.BaseModel `bun:"table:order_stats_orders,alias:oso"`
+
+	ID           string             `bun:"id,pk"`
+	Status       domain.OrderStatus `bun:"status,notnull"`
+	StatusSince  time.Time          `bun:"status_since,notnull"`
+	Version      int                `bun:"version,notnull"`        // phiên bản sự kiện cuối cùng đã áp dụng
+	CancelReason string             `bun:"cancel_reason,nullzero"` // lý do hủy đã được cộng vào thống kê
+}
+
+// OrderStatsDailyModel là số đơn hàng được tạo theo ngày (UTC)
+type OrderStatsDailyModel struct {
+	bun.BaseModel `bun:"table:order_stats_daily,alias:osd"`
+
+	Day    string `bun:"day,pk"` // YYYY-MM-DD
+	Orders int    `bun:"orders,notnull"`
+}
+
+// OrderStatsStatusModel là phân bố đơn hàng và tổng thời gian ở mỗi trạng thái
+type OrderStatsStatusModel struct {
+	bun.BaseModel `bun:"table:order_stats_statuses,alias:oss"`
+
+	Status       domain.OrderStatus `bun:"status,pk"`
+	Orders       int                `bun:"orders,notnull"`        // số đơn hàng đang ở trạng thái
+	Exits        int                `bun:"exits,notnull"`         // số lần đơn hàng rời khỏi trạng thái
+	TotalSeconds float64            `bun:"total_seconds,notnull"` // tổng thời gian trước khi rời khỏi trạng thái
+}
+
+// OrderStatsCancellationModel là số đơn hàng bị hủy theo lý do
+type OrderStatsCancellationModel struct {
+	bun.BaseModel `bun:"table:order_stats_cancellations,alias:osc"`
+
+	Reason string `bun:"reason,pk"`
+	Orders int    `bun:"orders,notnull"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Category là danh mục sản phẩm
+type Category struct {
+	bun.BaseModel `bun:"table:categories,alias:cat"`
+
+	ID          string      `bun:"id,pk" json:"id"`
+	Name        string      `bun:"name,notnull" json:"name"`
+	Status      ModelStatus `bun:"status,notnull" json:"status"`
+	CreatedTime time.Time   `bun:"created_time,notnull" json:"created_time"`
+	UpdatedTime time.Time   `bun:"updated_time,notnull" json:"updated_time"`
+	DeletedTime *time.Time  `bun:"deleted_time,soft_delete,nullzero" json:"deleted_time"`
+}
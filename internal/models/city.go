@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// City là thành phố nơi đặt sản phẩm
+type City struct {
+	bun.BaseModel `bun:"table:cities,alias:ci"`
+
+	ID string `bun:"id,pk" json:"ID"`
+	GEO
+	CreatedTime time.Time  `bun:"created_time,notnull" json:"created_time"`
+	UpdatedTime time.Time  `bun:"updated_time,notnull" json:"updated_time"`
+	DeletedTime *time.Time `bun:"deleted_time,soft_delete,nullzero" json:"deleted_time"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/uptrace/bun"
+)
+
+// DefaultHubDwellLimit là thời gian tối đa một đơn hàng nằm tại hub khi hub không khai báo giới hạn
+const DefaultHubDwellLimit = 24 * time.Hour
+
+// Hub là hub, kho phân loại mà đơn hàng đi qua, mã hub được dùng trong các sự kiện quét
+type Hub struct {
+	bun.BaseModel `bun:"table:hubs,alias:hub"`
+
+	Code      string  `bun:"code,pk" json:"code"`
+	Name      string  `bun:"name,notnull" json:"name"`
+	Latitude  float64 `bun:"latitude,notnull" json:"latitude"`
+	Longitude float64 `bun:"longitude,notnull" json:"longitude"`
+
+	// Giờ hoạt động theo HH:MM, giờ đóng cửa nhỏ hơn giờ mở cửa nghĩa là hoạt động qua đêm, rỗng khi hoạt động cả ngày
+	OpensAt  string `bun:"opens_at,nullzero" json:"opens_at,omitempty"`
+	ClosesAt string `bun:"closes_at,nullzero" json:"closes_at,omitempty"`
+
+	// DwellLimitMinutes là số phút tối đa đơn hàng nằm tại hub trước khi bị cảnh báo, 0 dùng DefaultHubDwellLimit
+	DwellLimitMinutes int `bun:"dwell_limit_minutes,notnull" json:"dwell_limit_minutes"`
+
+	Status      ModelStatus `bun:"status,notnull" json:"status"`
+	CreatedTime time.Time   `bun:"created_time,notnull" json:"created_time"`
+	UpdatedTime time.Time   `bun:"updated_time,notnull" json:"updated_time"`
+}
+
+// Point trả về tọa độ của hub
+func (h Hub) Point() geo.Point {
+	return geo.Point{Latitude: h.Latitude, Longitude: h.Longitude}
+}
+
+// DwellLimit là thời gian tối đa đơn hàng nằm tại hub
+func (h Hub) DwellLimit() time.Duration {
+	if h.DwellLimitMinutes <= 0 {
+		return DefaultHubDwellLimit
+	}
+	return time.Duration(h.DwellLimitMinutes) * time.Minute
+}
+
+// HubParcelModel là đơn hàng đang nằm tại hub, xóa khi đơn hàng được quét ra hoặc đã giao
+type HubParcelModel struct {
+	bun.BaseModel `bun:"table:hub_parcels,alias:hp"`
+
+	OrderID     string    `bun:"order_id,pk"`
+	HubCode     string    `bun:"hub_code,notnull"`
+	ScannedInAt time.Time `bun:"scanned_in_at,notnull"`
+	Version     int       `bun:"version,notnull"` // phiên bản sự kiện quét vào
+}
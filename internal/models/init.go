@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/quyenle-97/init/pkgs/geo"
 	"github.com/uptrace/bun"
 )
 
@@ -16,11 +17,23 @@ type Location struct {
 	Longitude float64 `json:"longitude"`
 }
 
+// Point trả về tọa độ để tính toán với package geo
+func (l Location) Point() geo.Point {
+	return geo.Point{Latitude: l.Latitude, Longitude: l.Longitude}
+}
+
 type GEO struct {
 	Name      string  `json:"name"`
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 }
 
+// Point trả về tọa độ để tính toán với package geo
+func (g GEO) Point() geo.Point {
+	return geo.Point{Latitude: g.Latitude, Longitude: g.Longitude}
+}
+
+// Init đăng ký các model cần biết trước khi truy vấn, như bảng nối của quan hệ nhiều-nhiều
 func Init(db *bun.DB) {
+	db.RegisterModel((*ProductCategory)(nil))
 }
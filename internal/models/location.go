@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/uptrace/bun"
+)
+
+// DriverLocationModel là vị trí mới nhất đã nhận của tài xế
+type DriverLocationModel struct {
+	bun.BaseModel `bun:"table:driver_locations,alias:dl"`
+
+	DriverID   string    `bun:"driver_id,pk"`
+	Latitude   float64   `bun:"latitude,notnull"`
+	Longitude  float64   `bun:"longitude,notnull"`
+	RecordedAt time.Time `bun:"recorded_at,notnull"` // thời điểm thiết bị ghi nhận
+	ReceivedAt time.Time `bun:"received_at,notnull"` // thời điểm máy chủ nhận
+}
+
+// OrderLocationModel là vị trí hiện tại của đơn hàng theo vị trí của tài xế được phân công
+type OrderLocationModel struct {
+	bun.BaseModel `bun:"table:order_locations,alias:ol"`
+
+	OrderID    string    `bun:"order_id,pk"`
+	DriverID   string    `bun:"driver_id,notnull"`
+	Latitude   float64   `bun:"latitude,notnull"`
+	Longitude  float64   `bun:"longitude,notnull"`
+	RecordedAt time.Time `bun:"recorded_at,notnull"`
+
+	// Điểm hành trình gần nhất đã ghi vào luồng sự kiện của đơn hàng, NULL khi chưa ghi
+	TrailLatitude   *float64           `bun:"trail_latitude"`
+	TrailLongitude  *float64           `bun:"trail_longitude"`
+	TrailStatus     domain.OrderStatus `bun:"trail_status,nullzero"`
+	TrailRecordedAt *time.Time         `bun:"trail_recorded_at"`
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/quyenle-97/init/internal/domain"
+	"github.com/shopspring/decimal"
 	"github.com/uptrace/bun"
 	"time"
 )
@@ -20,4 +21,38 @@ type OrderModel struct {
 	NotesData       []byte             `bun:"notes_data,notnull"`
 	CreatedAt       time.Time          `bun:"created_at,notnull"`
 	UpdatedAt       time.Time          `bun:"updated_at,notnull"`
+
+	ServiceLevel      domain.ServiceLevel `bun:"service_level,notnull,default:'STANDARD'"`
+	EstimatedDelivery *time.Time          `bun:"estimated_delivery"`
+	PromisedDelivery  *time.Time          `bun:"promised_delivery"`
+	SLABreached       bool                `bun:"sla_breached,notnull,default:false"`
+
+	// Vị trí hiện tại (điểm gửi nếu chưa có vị trí cập nhật), NULL khi không có tọa độ
+	PositionLatitude  *float64 `bun:"position_latitude"`
+	PositionLongitude *float64 `bun:"position_longitude"`
+
+	// Tổng của đơn hàng, tính lại từ items_data khi đọc; shipping_fee NULL khi chưa được tính phí vận chuyển
+	Currency      string           `bun:"currency,notnull,default:'VND'"`
+	Subtotal      decimal.Decimal  `bun:"subtotal,type:decimal(20,2),notnull"`
+	TotalWeight   decimal.Decimal  `bun:"total_weight,type:decimal(15,3),notnull"`
+	DeclaredValue decimal.Decimal  `bun:"declared_value,type:decimal(20,2),notnull"`
+	ShippingFee   *decimal.Decimal `bun:"shipping_fee,type:decimal(20,2)"`
+
+	// Thu hộ, các cột NULL với đơn hàng không thu hộ; tiền tệ là tiền tệ của đơn hàng
+	PaymentMethod  domain.PaymentMethod `bun:"payment_method,nullzero"`
+	PaymentStatus  domain.PaymentStatus `bun:"payment_status,nullzero"`
+	CODAmount      *decimal.Decimal     `bun:"cod_amount,type:decimal(20,2)"`
+	CODCollected   *decimal.Decimal     `bun:"cod_collected,type:decimal(20,2)"`
+	CODDriverID    string               `bun:"cod_driver_id,nullzero"`
+	CODCollectedAt *time.Time           `bun:"cod_collected_at"`
+	CODRemittedAt  *time.Time           `bun:"cod_remitted_at"`
+	CODRefundedAt  *time.Time           `bun:"cod_refunded_at"`
+
+	// Tài xế và phương tiện được phân công, NULL khi chưa phân công
+	DriverID   string     `bun:"driver_id,nullzero"`
+	VehicleID  string     `bun:"vehicle_id,nullzero"`
+	AssignedAt *time.Time `bun:"assigned_at"`
+
+	// Mã hub đơn hàng đang nằm, NULL khi đơn hàng không ở hub nào
+	CurrentHub string `bun:"current_hub,nullzero"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// Product là sản phẩm thuộc một nhà cung cấp, đặt tại một thành phố và thuộc nhiều danh mục
+type Product struct {
+	bun.BaseModel `bun:"table:products,alias:p"`
+
+	ID          string          `bun:"id,pk" json:"id"`
+	Reference   string          `bun:"reference,notnull,unique" json:"reference"`
+	Name        string          `bun:"name,notnull" json:"name"`
+	Status      ModelStatus     `bun:"status,notnull" json:"status"`
+	Price       decimal.Decimal `bun:"price,type:decimal(15,2),notnull" json:"price"`
+	Quantity    int             `bun:"quantity,notnull" json:"quantity"`
+	Weight      float64         `bun:"weight,notnull" json:"weight"` // kg, 0 khi chưa khai báo
+	CityID      string          `bun:"city_id,notnull" json:"city_id"`
+	SupplierID  string          `bun:"supplier_id,notnull" json:"supplier_id"`
+	CreatedTime time.Time       `bun:"created_time,notnull" json:"created_time"`
+	UpdatedTime time.Time       `bun:"updated_time,notnull" json:"updated_time"`
+	DeletedTime *time.Time      `bun:"deleted_time,soft_delete,nullzero" json:"deleted_time"`
+
+	Categories []Category `bun:"m2m:product_categories,join:Product=Category" json:"categories"`
+	City       *City      `bun:"rel:belongs-to,join:city_id=id" json:"city,omitempty"`
+	Supplier   *Supplier  `bun:"rel:belongs-to,join:supplier_id=id" json:"supplier,omitempty"`
+}
+
+// ProductCategory là bảng nối sản phẩm với danh mục
+type ProductCategory struct {
+	bun.BaseModel `bun:"table:product_categories,alias:pc"`
+
+	ProductID  string    `bun:"product_id,pk"`
+	Product    *Product  `bun:"rel:belongs-to,join:product_id=id"`
+	CategoryID string    `bun:"category_id,pk"`
+	Category   *Category `bun:"rel:belongs-to,join:category_id=id"`
+}
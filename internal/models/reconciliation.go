@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// CODCollectionModel là khoản tiền thu hộ tài xế đã thu của một đơn hàng, ID trùng với ID đơn hàng
+type CODCollectionModel struct {
+	bun.BaseModel `bun:"table:cod_collections,alias:codc"`
+
+	ID          string          `bun:"id,pk"`
+	DriverID    string          `bun:"driver_id,notnull"`
+	Day         string          `bun:"day,notnull"` // ngày thu YYYY-MM-DD (UTC)
+	Amount      decimal.Decimal `bun:"amount,type:decimal(20,2),notnull"`
+	Currency    string          `bun:"currency,notnull"`
+	CollectedAt time.Time       `bun:"collected_at,notnull"`
+	RemittedAt  *time.Time      `bun:"remitted_at"` // NULL khi tài xế chưa nộp tiền
+}
+
+// CODBalanceModel là tổng tiền thu hộ tài xế đã thu và đã nộp theo ngày thu và tiền tệ
+type CODBalanceModel struct {
+	bun.BaseModel `bun:"table:cod_balances,alias:codb"`
+
+	DriverID        string          `bun:"driver_id,pk"`
+	Day             string          `bun:"day,pk"`
+	Currency        string          `bun:"currency,pk"`
+	CollectedOrders int             `bun:"collected_orders,notnull"`
+	CollectedAmount decimal.Decimal `bun:"collected_amount,type:decimal(20,2),notnull"`
+	RemittedOrders  int             `bun:"remitted_orders,notnull"`
+	RemittedAmount  decimal.Decimal `bun:"remitted_amount,type:decimal(20,2),notnull"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Supplier là nhà cung cấp sản phẩm
+type Supplier struct {
+	bun.BaseModel `bun:"table:suppliers,alias:sup"`
+
+	ID          string      `bun:"id,pk" json:"id"`
+	Name        string      `bun:"name,notnull" json:"name"`
+	Status      ModelStatus `bun:"status,notnull" json:"status"`
+	CreatedTime time.Time   `bun:"created_time,notnull" json:"created_time"`
+	UpdatedTime time.Time   `bun:"updated_time,notnull" json:"updated_time"`
+	DeletedTime *time.Time  `bun:"deleted_time,soft_delete,nullzero" json:"deleted_time"`
+
+	// Vị trí kho của nhà cung cấp để tính khoảng cách tới sản phẩm, NULL khi chưa khai báo
+	LocationName      string   `bun:"location_name,nullzero" json:"location_name,omitempty"`
+	LocationLatitude  *float64 `bun:"location_latitude" json:"location_latitude,omitempty"`
+	LocationLongitude *float64 `bun:"location_longitude" json:"location_longitude,omitempty"`
+}
+
+// Location trả về vị trí kho của nhà cung cấp, false khi chưa khai báo
+func (s Supplier) Location() (GEO, bool) {
+	if s.LocationLatitude == nil || s.LocationLongitude == nil {
+		return GEO{}, false
+	}
+	return GEO{Name: s.LocationName, Latitude: *s.LocationLatitude, Longitude: *s.LocationLongitude}, true
+}
+
+// SetLocation gán vị trí kho, nil để xóa vị trí
+func (s *Supplier) SetLocation(location *GEO) {
+	if location == nil {
+		s.LocationName, s.LocationLatitude, s.LocationLongitude = "", nil, nil
+		return
+	}
+	latitude, longitude := location.Latitude, location.Longitude
+	s.LocationName, s.LocationLatitude, s.LocationLongitude = location.Name, &latitude, &longitude
+}
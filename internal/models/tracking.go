@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// TrackingModel là thông tin theo dõi công khai của đơn hàng, ID trùng với ID đơn hàng
+type TrackingModel struct {
+	bun.BaseModel `bun:"table:tracking_info,alias:ti"`
+
+	ID                string              `bun:"id,pk"`
+	TrackingNumber    string              `bun:"tracking_number,notnull,unique"`
+	Status            domain.OrderStatus  `bun:"status,notnull"`
+	ServiceLevel      domain.ServiceLevel `bun:"service_level,notnull"`
+	OriginCity        string              `bun:"origin_city,notnull"`
+	DestinationCity   string              `bun:"destination_city,notnull"`
+	CurrentCity       string              `bun:"current_city,nullzero"`
+	EstimatedDelivery *time.Time          `bun:"estimated_delivery"`
+	Delayed           bool                `bun:"is_delayed,notnull"`
+	Version           int                 `bun:"version,notnull"` // phiên bản sự kiện cuối cùng đã áp dụng
+	CreatedAt         time.Time           `bun:"created_at,notnull"`
+	UpdatedAt         time.Time           `bun:"updated_at,notnull"`
+}
+
+// TrackingUpdateModel là một mục trong lịch trình theo dõi, ID trùng với ID sự kiện
+type TrackingUpdateModel struct {
+	bun.BaseModel `bun:"table:tracking_updates,alias:tu"`
+
+	ID         string             `bun:"id,pk"`
+	TrackingID string             `bun:"tracking_id,notnull"`
+	Timestamp  time.Time          `bun:"timestamp,notnull"`
+	Status     domain.OrderStatus `bun:"status,nullzero"`
+	Location   string             `bun:"location,nullzero"`
+	Message    string             `bun:"message,notnull"`
+}
@@ -0,0 +1,170 @@
+package projection
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// DayLayout là định dạng ngày (UTC) dùng làm khóa thống kê theo ngày
+const DayLayout = "2006-01-02"
+
+// UnspecifiedReason là lý do hủy được ghi nhận khi sự kiện hủy không kèm lý do
+const UnspecifiedReason = "unspecified"
+
+// DailyOrders là số đơn hàng được tạo trong một ngày
+type DailyOrders struct {
+	Day    string // theo DayLayout
+	Orders int
+}
+
+// StatusStats là thống kê của một trạng thái đơn hàng
+type StatusStats struct {
+	Status         domain.OrderStatus
+	Orders         int     // số đơn hàng đang ở trạng thái này
+	Exits          int     // số lần đơn hàng rời khỏi trạng thái này
+	AverageSeconds float64 // thời gian trung bình đơn hàng ở trạng thái này trước khi rời đi
+}
+
+// CancellationReason là số đơn hàng bị hủy theo một lý do
+type CancellationReason struct {
+	Reason string
+	Orders int
+	Rate   float64 // tỷ lệ trên tổng số đơn hàng đã tạo
+}
+
+// CancellationStats là tỷ lệ hủy đơn hàng, tổng và theo từng lý do
+type CancellationStats struct {
+	TotalOrders int
+	Cancelled   int
+	Rate        float64
+	Reasons     []CancellationReason // nhiều nhất trước
+}
+
+// AnalyticsProjection lắng nghe sự kiện đơn hàng và cộng dồn các chỉ số thống kê
+type AnalyticsProjection interface {
+	// OrdersPerDay lấy số đơn hàng được tạo theo ngày trong khoảng [from, to], cũ nhất trước.
+	// from hoặc to rỗng nghĩa là không giới hạn.
+	OrdersPerDay(ctx context.Context, from, to string) ([]DailyOrders, error)
+
+	// StatusStats lấy phân bố đơn hàng và thời gian trung bình theo trạng thái
+	StatusStats(ctx context.Context) ([]StatusStats, error)
+
+	// Cancellations lấy tỷ lệ hủy đơn hàng theo lý do
+	Cancellations(ctx context.Context) (*CancellationStats, error)
+
+	// HandleEvent cập nhật projection, sự kiện đã áp dụng (theo phiên bản) được bỏ qua nên có thể phát lại
+	HandleEvent(event domain.Event) error
+
+	// Redact chuyển đơn hàng đã hủy sang lý do domain.RedactedValue vì lý do hủy có thể chứa dữ liệu cá nhân
+	Redactor
+}
+
+// orderAnalytics là trạng thái của một đơn hàng cần để tính thống kê
+type orderAnalytics struct {
+	OrderID      string
+	Status       domain.OrderStatus
+	StatusSince  time.Time
+	Version      int
+	CancelReason string // lý do hủy đã được cộng vào thống kê, rỗng nếu đơn hàng chưa bị hủy
+}
+
+// analyticsChange là phần thay đổi của các chỉ số do một sự kiện tạo ra
+type analyticsChange struct {
+	Order       *orderAnalytics
+	CreatedDay  string             // khác rỗng khi đơn hàng vừa được tạo
+	From        domain.OrderStatus // trạng thái đơn hàng rời khỏi, rỗng khi vừa tạo
+	Spent       time.Duration      // thời gian đơn hàng ở trạng thái From
+	To          domain.OrderStatus
+	CancelledBy string // lý do hủy, khác rỗng khi đơn hàng bị hủy
+}
+
+// applyAnalytics áp dụng sự kiện lên trạng thái thống kê của đơn hàng (nil nếu chưa có).
+// Trả về nil nếu sự kiện không làm thay đổi chỉ số nào.
+func applyAnalytics(current *orderAnalytics, event domain.Event) *analyticsChange {
+	if e, ok := event.(domain.OrderCreatedEvent); ok {
+		if current != nil {
+			return nil
+		}
+		return &analyticsChange{
+			Order: &orderAnalytics{
+				OrderID:     e.AggregateID,
+				Status:      domain.OrderStatusCreated,
+				StatusSince: e.Timestamp,
+				Version:     e.Version,
+			},
+			CreatedDay: e.Timestamp.UTC().Format(DayLayout),
+			To:         domain.OrderStatusCreated,
+		}
+	}
+
+	if current == nil || event.GetVersion() <= current.Version {
+		return nil
+	}
+
+	change := &analyticsChange{}
+	switch e := event.(type) {
+	case domain.OrderStatusUpdatedEvent:
+		change.To = e.NewStatus
+	case domain.OrderCancelledEvent:
+		change.To = domain.OrderStatusCancelled
+		change.CancelledBy = e.Reason
+		if change.CancelledBy == "" {
+			change.CancelledBy = UnspecifiedReason
+		}
+	default:
+		return nil
+	}
+
+	order := *current
+	order.Version = event.GetVersion()
+	if change.To != current.Status {
+		change.From = current.Status
+		change.Spent = event.GetTimestamp().Sub(current.StatusSince)
+		if change.Spent < 0 {
+			change.Spent = 0
+		}
+		order.Status = change.To
+		order.StatusSince = event.GetTimestamp()
+	} else {
+		change.To = ""
+	}
+	if change.CancelledBy != "" {
+		order.CancelReason = change.CancelledBy
+	}
+	change.Order = &order
+	return change
+}
+
+// averageSeconds tính thời gian trung bình, làm tròn đến giây
+func averageSeconds(totalSeconds float64, exits int) float64 {
+	if exits == 0 {
+		return 0
+	}
+	return math.Round(totalSeconds / float64(exits))
+}
+
+// rate tính tỷ lệ, làm tròn đến 4 chữ số thập phân
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*10000) / 10000
+}
+
+// finishCancellations tính tỷ lệ và sắp xếp lý do hủy, nhiều nhất trước
+func finishCancellations(stats *CancellationStats) {
+	stats.Rate = rate(stats.Cancelled, stats.TotalOrders)
+	for i := range stats.Reasons {
+		stats.Reasons[i].Rate = rate(stats.Reasons[i].Orders, stats.TotalOrders)
+	}
+	sort.Slice(stats.Reasons, func(i, j int) bool {
+		if stats.Reasons[i].Orders != stats.Reasons[j].Orders {
+			return stats.Reasons[i].Orders > stats.Reasons[j].Orders
+		}
+		return stats.Reasons[i].Reason < stats.Reasons[j].Reason
+	})
+}
@@ -0,0 +1,225 @@
+package projection_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestInMemoryAnalyticsProjection(t *testing.T) {
+	runAnalyticsTests(t, func(t *testing.T) projection.AnalyticsProjection {
+		return projection.NewInMemoryAnalyticsProjection()
+	})
+}
+
+func TestSQLiteAnalyticsProjection(t *testing.T) {
+	runAnalyticsTests(t, func(t *testing.T) projection.AnalyticsProjection {
+		dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+		sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+		if err != nil {
+			t.Fatalf("sql.Open: %v", err)
+		}
+		sqldb.SetMaxOpenConns(1)
+		db := bun.NewDB(sqldb, sqlitedialect.New())
+		t.Cleanup(func() { db.Close() })
+
+		if err := (migrations.OrderStatsTables{}).Up(db); err != nil {
+			t.Fatalf("OrderStatsTables.Up: %v", err)
+		}
+		if err := (migrations.OrderStatsCancelReason{}).Up(db); err != nil {
+			t.Fatalf("OrderStatsCancelReason.Up: %v", err)
+		}
+		return projection.NewPostgresAnalyticsProjection(db)
+	})
+}
+
+// analyticsBase tạo phần chung cho sự kiện thứ version của đơn hàng orderID, lùi day ngày so với domaintest.Base
+func analyticsBase(orderID string, eventType domain.EventType, version, day int) domain.BaseEvent {
+	base := domaintest.Base(eventType, version)
+	base.ID = fmt.Sprintf("%s-%d", orderID, version)
+	base.AggregateID = orderID
+	base.Timestamp = base.Timestamp.AddDate(0, 0, day)
+	return base
+}
+
+func analyticsCreated(orderID string, day int) domain.OrderCreatedEvent {
+	event := domaintest.Created()
+	event.BaseEvent = analyticsBase(orderID, domain.OrderCreatedType, 1, day)
+	return event
+}
+
+// analyticsHistory gồm ba đơn hàng trên hai ngày, mỗi lần chuyển trạng thái cách nhau một phút:
+// order-1 bị hủy khi đang vận chuyển, order-2 giao thành công, order-3 bị hủy không rõ lý do
+func analyticsHistory() []domain.Event {
+	return []domain.Event{
+		analyticsCreated("order-1", 0),
+		domain.NewOrderStatusUpdatedEvent(analyticsBase("order-1", domain.OrderStatusUpdatedType, 2, 0), domain.OrderStatusCreated, domain.OrderStatusInTransit, nil, ""),
+		domain.NewOrderNoteAddedEvent(analyticsBase("order-1", domain.OrderNoteAddedType, 3, 0), "gọi trước khi giao"),
+		domain.NewOrderCancelledEvent(analyticsBase("order-1", domain.OrderCancelledType, 4, 0), domain.OrderStatusInTransit, "Khách hàng không nghe máy"),
+
+		analyticsCreated("order-2", 1),
+		domain.NewOrderStatusUpdatedEvent(analyticsBase("order-2", domain.OrderStatusUpdatedType, 2, 1), domain.OrderStatusCreated, domain.OrderStatusProcessing, nil, ""),
+		domain.NewOrderStatusUpdatedEvent(analyticsBase("order-2", domain.OrderStatusUpdatedType, 3, 1), domain.OrderStatusProcessing, domain.OrderStatusDelivered, nil, ""),
+
+		analyticsCreated("order-3", 1),
+		domain.NewOrderCancelledEvent(analyticsBase("order-3", domain.OrderCancelledType, 3, 1), domain.OrderStatusCreated, ""),
+	}
+}
+
+func runAnalyticsTests(t *testing.T, newProjection func(t *testing.T) projection.AnalyticsProjection) {
+	ctx := context.Background()
+
+	handle := func(t *testing.T, p projection.AnalyticsProjection, times int) {
+		for i := 0; i < times; i++ {
+			for _, event := range analyticsHistory() {
+				if err := p.HandleEvent(event); err != nil {
+					t.Fatalf("HandleEvent %s: %v", event.GetType(), err)
+				}
+			}
+		}
+	}
+
+	check := func(t *testing.T, p projection.AnalyticsProjection) {
+		days, err := p.OrdersPerDay(ctx, "", "")
+		if err != nil {
+			t.Fatalf("OrdersPerDay: %v", err)
+		}
+		wantDays := []projection.DailyOrders{{Day: "2025-03-16", Orders: 1}, {Day: "2025-03-17", Orders: 2}}
+		if !reflect.DeepEqual(days, wantDays) {
+			t.Fatalf("OrdersPerDay = %+v, muốn %+v", days, wantDays)
+		}
+
+		minute := time.Minute.Seconds()
+		statuses, err := p.StatusStats(ctx)
+		if err != nil {
+			t.Fatalf("StatusStats: %v", err)
+		}
+		wantStatuses := []projection.StatusStats{
+			{Status: domain.OrderStatusCancelled, Orders: 2},
+			// order-3 ở trạng thái CREATED hai phút trước khi bị hủy
+			{Status: domain.OrderStatusCreated, Orders: 0, Exits: 3, AverageSeconds: 80},
+			{Status: domain.OrderStatusDelivered, Orders: 1},
+			// order-1 ở IN_TRANSIT hai phút vì ghi chú không làm đổi trạng thái
+			{Status: domain.OrderStatusInTransit, Orders: 0, Exits: 1, AverageSeconds: 2 * minute},
+			{Status: domain.OrderStatusProcessing, Orders: 0, Exits: 1, AverageSeconds: minute},
+		}
+		if !reflect.DeepEqual(statuses, wantStatuses) {
+			t.Fatalf("StatusStats = %+v, muốn %+v", statuses, wantStatuses)
+		}
+
+		cancellations, err := p.Cancellations(ctx)
+		if err != nil {
+			t.Fatalf("Cancellations: %v", err)
+		}
+		wantCancellations := &projection.CancellationStats{
+			TotalOrders: 3,
+			Cancelled:   2,
+			Rate:        0.6667,
+			Reasons: []projection.CancellationReason{
+				{Reason: "Khách hàng không nghe máy", Orders: 1, Rate: 0.3333},
+				{Reason: projection.UnspecifiedReason, Orders: 1, Rate: 0.3333},
+			},
+		}
+		if !reflect.DeepEqual(cancellations, wantCancellations) {
+			t.Fatalf("Cancellations = %+v, muốn %+v", cancellations, wantCancellations)
+		}
+	}
+
+	t.Run("Aggregates", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 1)
+		check(t, p)
+	})
+
+	t.Run("ReplayIsIdempotent", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 2)
+		check(t, p)
+	})
+
+	t.Run("Redact", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 1)
+
+		// order-2 không bị hủy nên không ảnh hưởng, ẩn lại lần hai không đổi kết quả
+		for i := 0; i < 2; i++ {
+			if err := p.Redact(ctx, []string{"order-1", "order-2", "missing"}); err != nil {
+				t.Fatalf("Redact: %v", err)
+			}
+		}
+		cancellations, err := p.Cancellations(ctx)
+		if err != nil {
+			t.Fatalf("Cancellations: %v", err)
+		}
+		wantReasons := []projection.CancellationReason{
+			{Reason: domain.RedactedValue, Orders: 1, Rate: 0.3333},
+			{Reason: projection.UnspecifiedReason, Orders: 1, Rate: 0.3333},
+		}
+		if cancellations.Cancelled != 2 || !reflect.DeepEqual(cancellations.Reasons, wantReasons) {
+			t.Fatalf("Cancellations = %+v, muốn lý do %+v", cancellations, wantReasons)
+		}
+
+		// Phát lại sau khi ẩn không cộng lại lý do gốc
+		handle(t, p, 1)
+		if again, _ := p.Cancellations(ctx); !reflect.DeepEqual(again, cancellations) {
+			t.Fatalf("Cancellations sau khi phát lại = %+v", again)
+		}
+	})
+
+	t.Run("OrdersPerDayRange", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 1)
+
+		days, err := p.OrdersPerDay(ctx, "2025-03-17", "2025-03-31")
+		if err != nil {
+			t.Fatalf("OrdersPerDay: %v", err)
+		}
+		if len(days) != 1 || days[0].Day != "2025-03-17" || days[0].Orders != 2 {
+			t.Fatalf("OrdersPerDay = %+v", days)
+		}
+
+		days, err = p.OrdersPerDay(ctx, "", "2025-03-15")
+		if err != nil {
+			t.Fatalf("OrdersPerDay: %v", err)
+		}
+		if len(days) != 0 {
+			t.Fatalf("OrdersPerDay = %+v, muốn rỗng", days)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		p := newProjection(t)
+
+		// Sự kiện của đơn hàng chưa có trong projection bị bỏ qua
+		if err := p.HandleEvent(domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusInTransit)); err != nil {
+			t.Fatalf("HandleEvent: %v", err)
+		}
+
+		statuses, err := p.StatusStats(ctx)
+		if err != nil {
+			t.Fatalf("StatusStats: %v", err)
+		}
+		if len(statuses) != 0 {
+			t.Fatalf("StatusStats = %+v, muốn rỗng", statuses)
+		}
+		cancellations, err := p.Cancellations(ctx)
+		if err != nil {
+			t.Fatalf("Cancellations: %v", err)
+		}
+		if cancellations.TotalOrders != 0 || cancellations.Rate != 0 || len(cancellations.Reasons) != 0 {
+			t.Fatalf("Cancellations = %+v", cancellations)
+		}
+	})
+}
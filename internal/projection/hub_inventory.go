@@ -0,0 +1,67 @@
+package projection
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// HubParcel là đơn hàng đang nằm tại hub
+type HubParcel struct {
+	OrderID     string
+	HubCode     string
+	ScannedInAt time.Time
+	Version     int // phiên bản sự kiện quét vào
+}
+
+// HubParcelFilter lọc đơn hàng đang nằm tại hub, giá trị rỗng nghĩa là không lọc
+type HubParcelFilter struct {
+	HubCode string
+	// ScannedBefore chỉ lấy đơn hàng quét vào trước thời điểm này
+	ScannedBefore time.Time
+}
+
+// HubInventoryProjection lắng nghe sự kiện quét và theo dõi đơn hàng đang nằm tại mỗi hub
+type HubInventoryProjection interface {
+	// Parcels lấy các đơn hàng đang nằm tại hub, nằm lâu nhất trước
+	Parcels(ctx context.Context, filter HubParcelFilter) ([]HubParcel, error)
+
+	// HandleEvent cập nhật projection, sự kiện cũ hơn lần quét vào đã lưu được bỏ qua nên có thể phát lại
+	HandleEvent(event domain.Event) error
+}
+
+// applyHubInventory áp dụng sự kiện lên đơn hàng đang nằm tại hub (nil nếu không ở hub nào).
+// Trả về changed false nếu sự kiện không làm thay đổi projection, parcel nil nghĩa là đơn hàng đã rời hub.
+func applyHubInventory(current *HubParcel, event domain.Event) (parcel *HubParcel, changed bool) {
+	// Phiên bản là vị trí của sự kiện trong luồng do event store gán khi đọc, không phải phiên bản lưu trong dữ liệu
+	if current != nil && event.GetVersion() <= current.Version {
+		return nil, false
+	}
+
+	switch e := event.(type) {
+	case domain.ParcelScannedInEvent:
+		return &HubParcel{
+			OrderID:     e.AggregateID,
+			HubCode:     e.HubCode,
+			ScannedInAt: e.Timestamp,
+			Version:     e.Version,
+		}, true
+	case domain.ParcelScannedOutEvent:
+		return nil, current != nil && current.HubCode == e.HubCode
+	case domain.OrderStatusUpdatedEvent:
+		return nil, current != nil && e.NewStatus == domain.OrderStatusDelivered
+	}
+	return nil, false
+}
+
+// sortHubParcels sắp xếp đơn hàng nằm tại hub lâu nhất trước
+func sortHubParcels(parcels []HubParcel) {
+	sort.Slice(parcels, func(i, j int) bool {
+		if !parcels[i].ScannedInAt.Equal(parcels[j].ScannedInAt) {
+			return parcels[i].ScannedInAt.Before(parcels[j].ScannedInAt)
+		}
+		return parcels[i].OrderID < parcels[j].OrderID
+	})
+}
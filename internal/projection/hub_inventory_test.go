@@ -0,0 +1,232 @@
+package projection_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestInMemoryHubInventoryProjection(t *testing.T) {
+	runHubInventoryTests(t, func(t *testing.T) projection.HubInventoryProjection {
+		return projection.NewInMemoryHubInventoryProjection()
+	})
+}
+
+func TestSQLiteHubInventoryProjection(t *testing.T) {
+	runHubInventoryTests(t, func(t *testing.T) projection.HubInventoryProjection {
+		dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+		sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+		if err != nil {
+			t.Fatalf("sql.Open: %v", err)
+		}
+		sqldb.SetMaxOpenConns(1)
+		db := bun.NewDB(sqldb, sqlitedialect.New())
+		t.Cleanup(func() { db.Close() })
+
+		if err := (migrations.HubTables{}).Up(db); err != nil {
+			t.Fatalf("HubTables.Up: %v", err)
+		}
+		return projection.NewPostgresHubInventoryProjection(db)
+	})
+}
+
+// hubBase tạo BaseEvent của đơn hàng orderID ở phiên bản version, muộn hơn domaintest.Base hours giờ
+func hubBase(orderID string, eventType domain.EventType, version, hours int) domain.BaseEvent {
+	base := domaintest.Base(eventType, version)
+	base.ID = fmt.Sprintf("%s-%d", orderID, version)
+	base.AggregateID = orderID
+	base.Timestamp = base.Timestamp.Add(time.Duration(hours) * time.Hour)
+	return base
+}
+
+func scannedIn(orderID, hubCode string, version, hours int) domain.ParcelScannedInEvent {
+	return domain.NewParcelScannedInEvent(hubBase(orderID, domain.ParcelScannedInType, version, hours), hubCode, "", 10.8231, 106.6297)
+}
+
+func scannedOut(orderID, hubCode string, version, hours int) domain.ParcelScannedOutEvent {
+	return domain.NewParcelScannedOutEvent(hubBase(orderID, domain.ParcelScannedOutType, version, hours), hubCode)
+}
+
+// hubHistory: order-1 qua HCM-01 rồi tới HN-01, order-2 và order-3 nằm tại HCM-01,
+// order-4 được giao khi chưa quét ra khỏi HN-01
+func hubHistory() []domain.Event {
+	return []domain.Event{
+		scannedIn("order-1", "HCM-01", 2, 0),
+		scannedIn("order-2", "HCM-01", 2, 1),
+		scannedOut("order-1", "HCM-01", 3, 2),
+		scannedIn("order-3", "HCM-01", 2, 3),
+		scannedIn("order-1", "HN-01", 4, 20),
+		scannedIn("order-4", "HN-01", 2, 5),
+		domain.NewOrderStatusUpdatedEvent(hubBase("order-4", domain.OrderStatusUpdatedType, 3, 6), domain.OrderStatusInTransit, domain.OrderStatusDelivered, nil, ""),
+		// Quét ra ở hub khác và sự kiện của đơn hàng không ở hub nào bị bỏ qua
+		scannedOut("order-2", "HN-01", 3, 7),
+		scannedOut("order-5", "HCM-01", 3, 7),
+	}
+}
+
+func formatHubParcels(parcels []projection.HubParcel) string {
+	rows := make([]string, len(parcels))
+	for i, p := range parcels {
+		rows[i] = fmt.Sprintf("%s@%s v%d %s", p.OrderID, p.HubCode, p.Version, p.ScannedInAt.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// legacyHubStreams lưu vào event store các luồng mà mọi sự kiện đều mang phiên bản 1 trong dữ liệu như sự kiện cũ:
+// order-1 qua HCM-01 rồi tới HN-01, order-2 được giao khi đang ở HCM-01, order-3 nằm tại HCM-01
+func legacyHubStreams(t *testing.T) eventstore.EventStore {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s_events?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	store, err := eventstore.NewSQLiteEventStore(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteEventStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	legacy := func(orderID string, eventType domain.EventType, hours int) domain.BaseEvent {
+		return hubBase(orderID, eventType, 1, hours)
+	}
+	created := func(orderID string, hours int) domain.OrderCreatedEvent {
+		return domain.OrderCreatedEvent{BaseEvent: legacy(orderID, domain.OrderCreatedType, hours), CustomerID: "CUS-001"}
+	}
+	streams := map[string][]domain.Event{
+		"order-1": {
+			created("order-1", 0),
+			domain.NewOrderStatusUpdatedEvent(legacy("order-1", domain.OrderStatusUpdatedType, 1), domain.OrderStatusCreated, domain.OrderStatusInTransit, nil, ""),
+			domain.NewParcelScannedInEvent(legacy("order-1", domain.ParcelScannedInType, 2), "HCM-01", "", 10.8231, 106.6297),
+			domain.NewParcelScannedOutEvent(legacy("order-1", domain.ParcelScannedOutType, 3), "HCM-01"),
+			domain.NewParcelScannedInEvent(legacy("order-1", domain.ParcelScannedInType, 20), "HN-01", "", 21.0278, 105.8342),
+		},
+		"order-2": {
+			created("order-2", 0),
+			domain.NewParcelScannedInEvent(legacy("order-2", domain.ParcelScannedInType, 2), "HCM-01", "", 10.8231, 106.6297),
+			domain.NewOrderStatusUpdatedEvent(legacy("order-2", domain.OrderStatusUpdatedType, 4), domain.OrderStatusInTransit, domain.OrderStatusDelivered, nil, ""),
+		},
+		"order-3": {
+			created("order-3", 0),
+			domain.NewParcelScannedInEvent(legacy("order-3", domain.ParcelScannedInType, 5), "HCM-01", "", 10.8231, 106.6297),
+		},
+	}
+	for orderID, events := range streams {
+		// ID sự kiện phải khác nhau trong cùng luồng
+		for i, event := range events {
+			events[i] = withEventID(event, fmt.Sprintf("%s-e%d", orderID, i))
+		}
+		if err := store.SaveEvents(context.Background(), orderID, events); err != nil {
+			t.Fatalf("SaveEvents: %v", err)
+		}
+	}
+	return store
+}
+
+func withEventID(event domain.Event, id string) domain.Event {
+	switch e := event.(type) {
+	case domain.OrderCreatedEvent:
+		e.ID = id
+		return e
+	case domain.OrderStatusUpdatedEvent:
+		e.ID = id
+		return e
+	case domain.ParcelScannedInEvent:
+		e.ID = id
+		return e
+	case domain.ParcelScannedOutEvent:
+		e.ID = id
+		return e
+	}
+	return event
+}
+
+func runHubInventoryTests(t *testing.T, newProjection func(t *testing.T) projection.HubInventoryProjection) {
+	ctx := context.Background()
+
+	t.Run("Parcels", func(t *testing.T) {
+		p := newProjection(t)
+		for _, event := range hubHistory() {
+			if err := p.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent(%s): %v", event.GetType(), err)
+			}
+		}
+
+		all, err := p.Parcels(ctx, projection.HubParcelFilter{})
+		if err != nil {
+			t.Fatalf("Parcels: %v", err)
+		}
+		want := strings.Join([]string{
+			"order-2@HCM-01 v2 2025-03-16T10:02:00Z",
+			"order-3@HCM-01 v2 2025-03-16T12:02:00Z",
+			"order-1@HN-01 v4 2025-03-17T05:04:00Z",
+		}, "\n")
+		if got := formatHubParcels(all); got != want {
+			t.Fatalf("Parcels =\n%s\nwant\n%s", got, want)
+		}
+
+		dwelling, err := p.Parcels(ctx, projection.HubParcelFilter{HubCode: "HCM-01", ScannedBefore: domaintest.Now.Add(2 * time.Hour)})
+		if err != nil {
+			t.Fatalf("Parcels: %v", err)
+		}
+		if got := formatHubParcels(dwelling); got != "order-2@HCM-01 v2 2025-03-16T10:02:00Z" {
+			t.Fatalf("Parcels theo hub = %s", got)
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		p := newProjection(t)
+		history := hubHistory()
+		for _, event := range append(history, history...) {
+			if err := p.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent(%s): %v", event.GetType(), err)
+			}
+		}
+
+		parcels, err := p.Parcels(ctx, projection.HubParcelFilter{HubCode: "HN-01"})
+		if err != nil {
+			t.Fatalf("Parcels: %v", err)
+		}
+		if got := formatHubParcels(parcels); got != "order-1@HN-01 v4 2025-03-17T05:04:00Z" {
+			t.Fatalf("Parcels sau khi phát lại = %s", got)
+		}
+	})
+
+	t.Run("ReplayLegacyStreamsFromStore", func(t *testing.T) {
+		p := newProjection(t)
+		store := legacyHubStreams(t)
+		want := strings.Join([]string{
+			"order-3@HCM-01 v2 2025-03-16T14:01:00Z",
+			"order-1@HN-01 v5 2025-03-17T05:01:00Z",
+		}, "\n")
+
+		// Phiên bản lấy theo vị trí trong luồng nên phát lại lần hai không thêm đơn hàng và không bỏ lần quét nào
+		for i := 0; i < 2; i++ {
+			events, err := store.GetAllEvents(ctx, 0, 0)
+			if err != nil {
+				t.Fatalf("GetAllEvents: %v", err)
+			}
+			for _, event := range events {
+				if err := p.HandleEvent(event); err != nil {
+					t.Fatalf("HandleEvent(%s): %v", event.GetType(), err)
+				}
+			}
+
+			parcels, err := p.Parcels(ctx, projection.HubParcelFilter{})
+			if err != nil {
+				t.Fatalf("Parcels: %v", err)
+			}
+			if got := formatHubParcels(parcels); got != want {
+				t.Fatalf("Parcels sau lần phát lại %d =\n%s\nwant\n%s", i+1, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package projection
+
+import (
+	"context"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// DriverLocation là vị trí mới nhất đã nhận của tài xế
+type DriverLocation struct {
+	DriverID   string
+	Point      geo.Point
+	RecordedAt time.Time // thời điểm thiết bị ghi nhận
+	ReceivedAt time.Time // thời điểm máy chủ nhận
+}
+
+// TrailPoint là điểm hành trình gần nhất đã ghi vào luồng sự kiện của đơn hàng
+type TrailPoint struct {
+	Point      geo.Point
+	Status     domain.OrderStatus // trạng thái của đơn hàng khi ghi
+	RecordedAt time.Time
+}
+
+// OrderLocation là vị trí hiện tại của đơn hàng theo vị trí của tài xế được phân công
+type OrderLocation struct {
+	OrderID    string
+	DriverID   string
+	Point      geo.Point
+	RecordedAt time.Time
+	Trail      *TrailPoint // nil khi chưa ghi điểm hành trình nào
+}
+
+// LocationProjection lưu vị trí tài xế gửi lên và vị trí hiện tại của các đơn hàng được phân công.
+// Vị trí không đi qua event store, chỉ điểm hành trình đáng kể được ghi thành sự kiện của đơn hàng.
+type LocationProjection interface {
+	// DriverLocation lấy vị trí mới nhất của tài xế, nil nếu chưa có
+	DriverLocation(ctx context.Context, driverID string) (*DriverLocation, error)
+
+	// OrderLocations lấy vị trí hiện tại của các đơn hàng, đơn hàng chưa có vị trí không có trong kết quả
+	OrderLocations(ctx context.Context, orderIDs []string) (map[string]OrderLocation, error)
+
+	// Save ghi vị trí mới của tài xế cùng vị trí của các đơn hàng được phân công
+	Save(ctx context.Context, driver DriverLocation, orders []OrderLocation) error
+
+	// Redact xóa vị trí của đơn hàng vì tọa độ dẫn tới địa chỉ của người nhận
+	Redactor
+}
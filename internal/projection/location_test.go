@@ -0,0 +1,122 @@
+package projection_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestInMemoryLocationProjection(t *testing.T) {
+	runLocationTests(t, func(t *testing.T) projection.LocationProjection {
+		return projection.NewInMemoryLocationProjection()
+	})
+}
+
+func TestSQLiteLocationProjection(t *testing.T) {
+	runLocationTests(t, func(t *testing.T) projection.LocationProjection {
+		dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+		sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+		if err != nil {
+			t.Fatalf("sql.Open: %v", err)
+		}
+		sqldb.SetMaxOpenConns(1)
+		db := bun.NewDB(sqldb, sqlitedialect.New())
+		t.Cleanup(func() { db.Close() })
+
+		if err := (migrations.LocationTables{}).Up(db); err != nil {
+			t.Fatalf("LocationTables.Up: %v", err)
+		}
+		return projection.NewPostgresLocationProjection(db)
+	})
+}
+
+func runLocationTests(t *testing.T, newProjection func(t *testing.T) projection.LocationProjection) {
+	ctx := context.Background()
+	at := time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)
+	first := geo.Point{Latitude: 10.8231, Longitude: 106.6297}
+	second := geo.Point{Latitude: 10.7769, Longitude: 106.7009}
+
+	t.Run("SaveOverwrites", func(t *testing.T) {
+		p := newProjection(t)
+		if location, err := p.DriverLocation(ctx, "DRV-001"); err != nil || location != nil {
+			t.Fatalf("DriverLocation trước khi có vị trí = %+v, %v", location, err)
+		}
+
+		trail := &projection.TrailPoint{Point: first, Status: domain.OrderStatusOutForDelivery, RecordedAt: at}
+		err := p.Save(ctx, projection.DriverLocation{DriverID: "DRV-001", Point: first, RecordedAt: at, ReceivedAt: at}, []projection.OrderLocation{
+			{OrderID: "order-1", DriverID: "DRV-001", Point: first, RecordedAt: at, Trail: trail},
+			{OrderID: "order-2", DriverID: "DRV-001", Point: first, RecordedAt: at},
+		})
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		later := at.Add(time.Minute)
+		err = p.Save(ctx, projection.DriverLocation{DriverID: "DRV-001", Point: second, RecordedAt: later, ReceivedAt: later}, []projection.OrderLocation{
+			{OrderID: "order-1", DriverID: "DRV-001", Point: second, RecordedAt: later, Trail: trail},
+		})
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		driver, err := p.DriverLocation(ctx, "DRV-001")
+		if err != nil {
+			t.Fatalf("DriverLocation: %v", err)
+		}
+		if driver == nil || driver.Point != second || !driver.RecordedAt.Equal(later) {
+			t.Fatalf("DriverLocation = %+v", driver)
+		}
+
+		orders, err := p.OrderLocations(ctx, []string{"order-1", "order-2", "missing"})
+		if err != nil {
+			t.Fatalf("OrderLocations: %v", err)
+		}
+		if len(orders) != 2 {
+			t.Fatalf("OrderLocations = %+v", orders)
+		}
+		if got := orders["order-1"]; got.Point != second || got.Trail == nil || got.Trail.Point != first ||
+			got.Trail.Status != domain.OrderStatusOutForDelivery || !got.Trail.RecordedAt.Equal(at) {
+			t.Fatalf("order-1 = %+v, trail %+v", got, got.Trail)
+		}
+		if got := orders["order-2"]; got.Point != first || got.Trail != nil {
+			t.Fatalf("order-2 = %+v", got)
+		}
+	})
+
+	t.Run("Redact", func(t *testing.T) {
+		p := newProjection(t)
+		err := p.Save(ctx, projection.DriverLocation{DriverID: "DRV-001", Point: first, RecordedAt: at, ReceivedAt: at}, []projection.OrderLocation{
+			{OrderID: "order-1", DriverID: "DRV-001", Point: first, RecordedAt: at},
+			{OrderID: "order-2", DriverID: "DRV-001", Point: first, RecordedAt: at},
+		})
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		if err := p.Redact(ctx, []string{"order-1", "missing"}); err != nil {
+			t.Fatalf("Redact: %v", err)
+		}
+		orders, err := p.OrderLocations(ctx, []string{"order-1", "order-2"})
+		if err != nil {
+			t.Fatalf("OrderLocations: %v", err)
+		}
+		if _, ok := orders["order-1"]; ok || len(orders) != 1 {
+			t.Fatalf("OrderLocations sau khi ẩn = %+v", orders)
+		}
+		// Vị trí của tài xế không phải dữ liệu của khách hàng nên được giữ lại
+		if driver, err := p.DriverLocation(ctx, "DRV-001"); err != nil || driver == nil {
+			t.Fatalf("DriverLocation = %+v, %v", driver, err)
+		}
+	})
+}
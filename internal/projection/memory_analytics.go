@@ -0,0 +1,147 @@
+package projection
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// statusTotals là các chỉ số cộng dồn của một trạng thái
+type statusTotals struct {
+	orders       int
+	exits        int
+	totalSeconds float64
+}
+
+// inMemoryAnalyticsProjection lưu projection thống kê trong bộ nhớ, dùng cho kiểm thử và chạy local
+type inMemoryAnalyticsProjection struct {
+	mu            sync.RWMutex
+	orders        map[string]*orderAnalytics
+	daily         map[string]int
+	statuses      map[domain.OrderStatus]*statusTotals
+	cancellations map[string]int
+}
+
+// NewInMemoryAnalyticsProjection tạo projection thống kê trong bộ nhớ
+func NewInMemoryAnalyticsProjection() AnalyticsProjection {
+	return &inMemoryAnalyticsProjection{
+		orders:        make(map[string]*orderAnalytics),
+		daily:         make(map[string]int),
+		statuses:      make(map[domain.OrderStatus]*statusTotals),
+		cancellations: make(map[string]int),
+	}
+}
+
+// OrdersPerDay lấy số đơn hàng được tạo theo ngày, cũ nhất trước
+func (p *inMemoryAnalyticsProjection) OrdersPerDay(_ context.Context, from, to string) ([]DailyOrders, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	days := make([]DailyOrders, 0, len(p.daily))
+	for day, orders := range p.daily {
+		if (from != "" && day < from) || (to != "" && day > to) {
+			continue
+		}
+		days = append(days, DailyOrders{Day: day, Orders: orders})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Day < days[j].Day
+	})
+	return days, nil
+}
+
+// StatusStats lấy phân bố đơn hàng và thời gian trung bình theo trạng thái, sắp xếp theo tên trạng thái
+func (p *inMemoryAnalyticsProjection) StatusStats(_ context.Context) ([]StatusStats, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := make([]StatusStats, 0, len(p.statuses))
+	for status, totals := range p.statuses {
+		stats = append(stats, StatusStats{
+			Status:         status,
+			Orders:         totals.orders,
+			Exits:          totals.exits,
+			AverageSeconds: averageSeconds(totals.totalSeconds, totals.exits),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Status < stats[j].Status
+	})
+	return stats, nil
+}
+
+// Cancellations lấy tỷ lệ hủy đơn hàng theo lý do, nhiều nhất trước
+func (p *inMemoryAnalyticsProjection) Cancellations(_ context.Context) (*CancellationStats, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := &CancellationStats{Reasons: make([]CancellationReason, 0, len(p.cancellations))}
+	for _, orders := range p.daily {
+		stats.TotalOrders += orders
+	}
+	for reason, orders := range p.cancellations {
+		stats.Cancelled += orders
+		stats.Reasons = append(stats.Reasons, CancellationReason{Reason: reason, Orders: orders})
+	}
+	finishCancellations(stats)
+	return stats, nil
+}
+
+// HandleEvent áp dụng sự kiện lên projection
+func (p *inMemoryAnalyticsProjection) HandleEvent(event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change := applyAnalytics(p.orders[event.GetAggregateID()], event)
+	if change == nil {
+		return nil
+	}
+
+	p.orders[change.Order.OrderID] = change.Order
+	if change.CreatedDay != "" {
+		p.daily[change.CreatedDay]++
+	}
+	if change.From != "" {
+		totals := p.status(change.From)
+		totals.orders--
+		totals.exits++
+		totals.totalSeconds += change.Spent.Seconds()
+	}
+	if change.To != "" {
+		p.status(change.To).orders++
+	}
+	if change.CancelledBy != "" {
+		p.cancellations[change.CancelledBy]++
+	}
+	return nil
+}
+
+// Redact chuyển các đơn hàng đã hủy sang lý do domain.RedactedValue
+func (p *inMemoryAnalyticsProjection) Redact(_ context.Context, orderIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range orderIDs {
+		order, ok := p.orders[id]
+		if !ok || order.CancelReason == "" || order.CancelReason == domain.RedactedValue {
+			continue
+		}
+		if p.cancellations[order.CancelReason]--; p.cancellations[order.CancelReason] <= 0 {
+			delete(p.cancellations, order.CancelReason)
+		}
+		p.cancellations[domain.RedactedValue]++
+		order.CancelReason = domain.RedactedValue
+	}
+	return nil
+}
+
+func (p *inMemoryAnalyticsProjection) status(status domain.OrderStatus) *statusTotals {
+	totals, ok := p.statuses[status]
+	if !ok {
+		totals = &statusTotals{}
+		p.statuses[status] = totals
+	}
+	return totals
+}
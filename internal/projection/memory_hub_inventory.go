@@ -0,0 +1,55 @@
+package projection
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// inMemoryHubInventoryProjection lưu đơn hàng đang nằm tại hub trong bộ nhớ, dùng cho kiểm thử và chạy local
+type inMemoryHubInventoryProjection struct {
+	mu      sync.RWMutex
+	parcels map[string]*HubParcel
+}
+
+// NewInMemoryHubInventoryProjection tạo projection tồn kho hub trong bộ nhớ
+func NewInMemoryHubInventoryProjection() HubInventoryProjection {
+	return &inMemoryHubInventoryProjection{
+		parcels: make(map[string]*HubParcel),
+	}
+}
+
+// Parcels lấy các đơn hàng đang nằm tại hub theo bộ lọc, nằm lâu nhất trước
+func (p *inMemoryHubInventoryProjection) Parcels(_ context.Context, filter HubParcelFilter) ([]HubParcel, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	parcels := make([]HubParcel, 0)
+	for _, parcel := range p.parcels {
+		if (filter.HubCode != "" && parcel.HubCode != filter.HubCode) ||
+			(!filter.ScannedBefore.IsZero() && !parcel.ScannedInAt.Before(filter.ScannedBefore)) {
+			continue
+		}
+		parcels = append(parcels, *parcel)
+	}
+	sortHubParcels(parcels)
+	return parcels, nil
+}
+
+// HandleEvent áp dụng sự kiện lên projection
+func (p *inMemoryHubInventoryProjection) HandleEvent(event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	parcel, changed := applyHubInventory(p.parcels[event.GetAggregateID()], event)
+	if !changed {
+		return nil
+	}
+	if parcel == nil {
+		delete(p.parcels, event.GetAggregateID())
+		return nil
+	}
+	p.parcels[parcel.OrderID] = parcel
+	return nil
+}
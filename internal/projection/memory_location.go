@@ -0,0 +1,70 @@
+package projection
+
+import (
+	"context"
+	"sync"
+)
+
+// inMemoryLocationProjection lưu vị trí trong bộ nhớ, dùng cho kiểm thử và chạy local
+type inMemoryLocationProjection struct {
+	mu      sync.RWMutex
+	drivers map[string]DriverLocation
+	orders  map[string]OrderLocation
+}
+
+// NewInMemoryLocationProjection tạo projection vị trí trong bộ nhớ
+func NewInMemoryLocationProjection() LocationProjection {
+	return &inMemoryLocationProjection{
+		drivers: make(map[string]DriverLocation),
+		orders:  make(map[string]OrderLocation),
+	}
+}
+
+// DriverLocation lấy vị trí mới nhất của tài xế
+func (p *inMemoryLocationProjection) DriverLocation(_ context.Context, driverID string) (*DriverLocation, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	location, ok := p.drivers[driverID]
+	if !ok {
+		return nil, nil
+	}
+	return &location, nil
+}
+
+// OrderLocations lấy vị trí hiện tại của các đơn hàng
+func (p *inMemoryLocationProjection) OrderLocations(_ context.Context, orderIDs []string) (map[string]OrderLocation, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	locations := make(map[string]OrderLocation, len(orderIDs))
+	for _, id := range orderIDs {
+		if location, ok := p.orders[id]; ok {
+			locations[id] = location
+		}
+	}
+	return locations, nil
+}
+
+// Redact xóa vị trí của các đơn hàng
+func (p *inMemoryLocationProjection) Redact(_ context.Context, orderIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range orderIDs {
+		delete(p.orders, id)
+	}
+	return nil
+}
+
+// Save ghi vị trí của tài xế và các đơn hàng
+func (p *inMemoryLocationProjection) Save(_ context.Context, driver DriverLocation, orders []OrderLocation) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.drivers[driver.DriverID] = driver
+	for _, order := range orders {
+		p.orders[order.OrderID] = order
+	}
+	return nil
+}
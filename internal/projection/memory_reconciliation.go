@@ -0,0 +1,97 @@
+package projection
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// balanceKey là khóa của một dòng đối soát
+type balanceKey struct {
+	driverID string
+	day      string
+	currency string
+}
+
+// inMemoryReconciliationProjection lưu đối soát thu hộ trong bộ nhớ, dùng cho kiểm thử và chạy local
+type inMemoryReconciliationProjection struct {
+	mu          sync.RWMutex
+	collections map[string]*CODCollection
+	balances    map[balanceKey]*CODBalance
+}
+
+// NewInMemoryReconciliationProjection tạo projection đối soát thu hộ trong bộ nhớ
+func NewInMemoryReconciliationProjection() ReconciliationProjection {
+	return &inMemoryReconciliationProjection{
+		collections: make(map[string]*CODCollection),
+		balances:    make(map[balanceKey]*CODBalance),
+	}
+}
+
+// Balances lấy đối soát thu hộ theo bộ lọc, cũ nhất trước
+func (p *inMemoryReconciliationProjection) Balances(_ context.Context, filter CODBalanceFilter) ([]CODBalance, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	balances := make([]CODBalance, 0, len(p.balances))
+	for _, balance := range p.balances {
+		if (filter.DriverID != "" && balance.DriverID != filter.DriverID) ||
+			(filter.From != "" && balance.Day < filter.From) ||
+			(filter.To != "" && balance.Day > filter.To) ||
+			(!filter.IncludeSettled && balance.Outstanding().IsZero()) {
+			continue
+		}
+		balances = append(balances, *balance)
+	}
+	sortBalances(balances)
+	return balances, nil
+}
+
+// Outstanding lấy các khoản tài xế đã thu nhưng chưa nộp, cũ nhất trước
+func (p *inMemoryReconciliationProjection) Outstanding(_ context.Context, driverID string) ([]CODCollection, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var collections []CODCollection
+	for _, collection := range p.collections {
+		if collection.DriverID == driverID && collection.RemittedAt == nil {
+			collections = append(collections, *collection)
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].CollectedAt.Before(collections[j].CollectedAt)
+	})
+	return collections, nil
+}
+
+// HandleEvent áp dụng sự kiện lên projection
+func (p *inMemoryReconciliationProjection) HandleEvent(event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change := applyReconciliation(p.collections[event.GetAggregateID()], event)
+	if change == nil {
+		return nil
+	}
+
+	collection := change.Collection
+	p.collections[collection.OrderID] = collection
+
+	key := balanceKey{driverID: collection.DriverID, day: collection.Day, currency: collection.Currency}
+	balance, ok := p.balances[key]
+	if !ok {
+		balance = &CODBalance{DriverID: key.driverID, Day: key.day, Currency: key.currency}
+		p.balances[key] = balance
+	}
+	if change.Collected {
+		balance.CollectedOrders++
+		balance.Collected = balance.Collected.Add(collection.Amount)
+	}
+	if change.Remitted {
+		balance.RemittedOrders++
+		balance.Remitted = balance.Remitted.Add(collection.Amount)
+	}
+	return nil
+}
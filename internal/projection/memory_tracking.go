@@ -0,0 +1,74 @@
+package projection
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// inMemoryTrackingProjection lưu projection theo dõi trong bộ nhớ, dùng cho kiểm thử và chạy local
+type inMemoryTrackingProjection struct {
+	mu         sync.RWMutex
+	byOrder    map[string]*TrackingInfo
+	byTracking map[string]string // số theo dõi -> ID đơn hàng
+}
+
+// NewInMemoryTrackingProjection tạo projection theo dõi trong bộ nhớ
+func NewInMemoryTrackingProjection() TrackingProjection {
+	return &inMemoryTrackingProjection{
+		byOrder:    make(map[string]*TrackingInfo),
+		byTracking: make(map[string]string),
+	}
+}
+
+// GetByTrackingNumber lấy thông tin theo dõi kèm lịch trình
+func (p *inMemoryTrackingProjection) GetByTrackingNumber(_ context.Context, trackingNumber string) (*TrackingInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	info, ok := p.byOrder[p.byTracking[trackingNumber]]
+	if !ok {
+		return nil, ErrTrackingNotFound
+	}
+
+	clone := *info
+	clone.Updates = append([]TrackingUpdate{}, info.Updates...)
+	return &clone, nil
+}
+
+// HandleEvent áp dụng sự kiện lên projection
+func (p *inMemoryTrackingProjection) HandleEvent(event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := p.byOrder[event.GetAggregateID()]
+	info, update := applyTracking(current, event)
+	if info == nil {
+		return nil
+	}
+
+	if current != nil {
+		info.Updates = current.Updates
+	}
+	if update != nil {
+		info.Updates = append(info.Updates, *update)
+	}
+
+	p.byOrder[info.OrderID] = info
+	p.byTracking[info.TrackingNumber] = info.OrderID
+	return nil
+}
+
+// Redact ẩn các thành phố của đơn hàng
+func (p *inMemoryTrackingProjection) Redact(_ context.Context, orderIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range orderIDs {
+		if info, ok := p.byOrder[id]; ok {
+			redactTracking(info)
+		}
+	}
+	return nil
+}
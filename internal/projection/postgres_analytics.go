@@ -0,0 +1,259 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// postgresAnalyticsProjection cộng dồn chỉ số thống kê vào các bảng order_stats_*
+type postgresAnalyticsProjection struct {
+	db *bun.DB
+}
+
+// NewPostgresAnalyticsProjection tạo projection thống kê dùng cơ sở dữ liệu
+func NewPostgresAnalyticsProjection(db *bun.DB) AnalyticsProjection {
+	return &postgresAnalyticsProjection{
+		db: db,
+	}
+}
+
+// OrdersPerDay lấy số đơn hàng được tạo theo ngày, cũ nhất trước
+func (p *postgresAnalyticsProjection) OrdersPerDay(ctx context.Context, from, to string) ([]DailyOrders, error) {
+	var rows []models.OrderStatsDailyModel
+	query := p.db.NewSelect().
+		Model(&rows).
+		Order("day ASC")
+	if from != "" {
+		query = query.Where("day >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("day <= ?", to)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn thống kê đơn hàng theo ngày: %w", err)
+	}
+
+	days := make([]DailyOrders, len(rows))
+	for i, row := range rows {
+		days[i] = DailyOrders{Day: row.Day, Orders: row.Orders}
+	}
+	return days, nil
+}
+
+// StatusStats lấy phân bố đơn hàng và thời gian trung bình theo trạng thái, sắp xếp theo tên trạng thái
+func (p *postgresAnalyticsProjection) StatusStats(ctx context.Context) ([]StatusStats, error) {
+	var rows []models.OrderStatsStatusModel
+	err := p.db.NewSelect().
+		Model(&rows).
+		Order("status ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn thống kê trạng thái: %w", err)
+	}
+
+	stats := make([]StatusStats, len(rows))
+	for i, row := range rows {
+		stats[i] = StatusStats{
+			Status:         row.Status,
+			Orders:         row.Orders,
+			Exits:          row.Exits,
+			AverageSeconds: averageSeconds(row.TotalSeconds, row.Exits),
+		}
+	}
+	return stats, nil
+}
+
+// Cancellations lấy tỷ lệ hủy đơn hàng theo lý do, nhiều nhất trước
+func (p *postgresAnalyticsProjection) Cancellations(ctx context.Context) (*CancellationStats, error) {
+	stats := &CancellationStats{}
+	err := p.db.NewSelect().
+		Model((*models.OrderStatsDailyModel)(nil)).
+		ColumnExpr("COALESCE(SUM(orders), 0)").
+		Scan(ctx, &stats.TotalOrders)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn tổng số đơn hàng: %w", err)
+	}
+
+	var rows []models.OrderStatsCancellationModel
+	if err := p.db.NewSelect().Model(&rows).Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn thống kê hủy đơn hàng: %w", err)
+	}
+
+	stats.Reasons = make([]CancellationReason, len(rows))
+	for i, row := range rows {
+		stats.Cancelled += row.Orders
+		stats.Reasons[i] = CancellationReason{Reason: row.Reason, Orders: row.Orders}
+	}
+	finishCancellations(stats)
+	return stats, nil
+}
+
+// HandleEvent cập nhật trạng thái đơn hàng và cộng dồn các chỉ số trong cùng một transaction
+func (p *postgresAnalyticsProjection) HandleEvent(event domain.Event) error {
+	ctx := context.Background()
+
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current, err := p.findByOrderID(ctx, tx, event.GetAggregateID())
+		if err != nil {
+			return err
+		}
+
+		change := applyAnalytics(current, event)
+		if change == nil {
+			return nil
+		}
+
+		model := &models.OrderStatsModel{
+			ID:           change.Order.OrderID,
+			Status:       change.Order.Status,
+			StatusSince:  change.Order.StatusSince,
+			Version:      change.Order.Version,
+			CancelReason: change.Order.CancelReason,
+		}
+		if current == nil {
+			_, err = tx.NewInsert().Model(model).Exec(ctx)
+		} else {
+			_, err = tx.NewUpdate().Model(model).WherePK().Exec(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu trạng thái thống kê đơn hàng: %w", err)
+		}
+
+		if change.CreatedDay != "" {
+			err = increment(ctx, tx, &models.OrderStatsDailyModel{Day: change.CreatedDay, Orders: 1},
+				"day = ?", change.CreatedDay, "orders = orders + 1")
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thống kê theo ngày: %w", err)
+			}
+		}
+		if change.From != "" {
+			spent := change.Spent.Seconds()
+			err = increment(ctx, tx, &models.OrderStatsStatusModel{Status: change.From, Orders: -1, Exits: 1, TotalSeconds: spent},
+				"status = ?", change.From, "orders = orders - 1, exits = exits + 1, total_seconds = total_seconds + ?", spent)
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thống kê trạng thái: %w", err)
+			}
+		}
+		if change.To != "" {
+			err = increment(ctx, tx, &models.OrderStatsStatusModel{Status: change.To, Orders: 1},
+				"status = ?", change.To, "orders = orders + 1")
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thống kê trạng thái: %w", err)
+			}
+		}
+		if change.CancelledBy != "" {
+			err = increment(ctx, tx, &models.OrderStatsCancellationModel{Reason: change.CancelledBy, Orders: 1},
+				"reason = ?", change.CancelledBy, "orders = orders + 1")
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thống kê hủy đơn hàng: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// findByOrderID lấy trạng thái thống kê của đơn hàng, trả về nil nếu chưa tồn tại
+func (p *postgresAnalyticsProjection) findByOrderID(ctx context.Context, db bun.IDB, orderID string) (*orderAnalytics, error) {
+	model := &models.OrderStatsModel{}
+	err := db.NewSelect().
+		Model(model).
+		Where("id = ?", orderID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lỗi khi tìm trạng thái thống kê đơn hàng: %w", err)
+	}
+	return &orderAnalytics{
+		OrderID:      model.ID,
+		Status:       model.Status,
+		StatusSince:  model.StatusSince,
+		Version:      model.Version,
+		CancelReason: model.CancelReason,
+	}, nil
+}
+
+// Redact chuyển các đơn hàng đã hủy sang lý do domain.RedactedValue trong cùng một transaction
+func (p *postgresAnalyticsProjection) Redact(ctx context.Context, orderIDs []string) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var orders []models.OrderStatsModel
+		err := tx.NewSelect().
+			Model(&orders).
+			Where("id IN (?)", bun.In(orderIDs)).
+			Where("cancel_reason IS NOT NULL").
+			Where("cancel_reason <> ?", domain.RedactedValue).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi tìm đơn hàng đã hủy: %w", err)
+		}
+
+		for _, order := range orders {
+			_, err = tx.NewUpdate().
+				Model((*models.OrderStatsCancellationModel)(nil)).
+				Set("orders = orders - 1").
+				Where("reason = ?", order.CancelReason).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thống kê hủy đơn hàng: %w", err)
+			}
+			err = increment(ctx, tx, &models.OrderStatsCancellationModel{Reason: domain.RedactedValue, Orders: 1},
+				"reason = ?", domain.RedactedValue, "orders = orders + 1")
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thống kê hủy đơn hàng: %w", err)
+			}
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		// Lý do không còn đơn hàng nào bị xóa khỏi thống kê như projection trong bộ nhớ
+		_, err = tx.NewDelete().
+			Model((*models.OrderStatsCancellationModel)(nil)).
+			Where("orders <= 0").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi cập nhật thống kê hủy đơn hàng: %w", err)
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*models.OrderStatsModel)(nil)).
+			Set("cancel_reason = ?", domain.RedactedValue).
+			Where("id IN (?)", bun.In(orderIDs)).
+			Where("cancel_reason IS NOT NULL").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi ẩn lý do hủy đơn hàng: %w", err)
+		}
+		return nil
+	})
+}
+
+// increment cộng dồn vào dòng thống kê có khóa key, tạo dòng mới từ row nếu chưa tồn tại.
+// Cách này dùng được với mọi dialect, không phụ thuộc cú pháp upsert riêng.
+func increment(ctx context.Context, tx bun.Tx, row interface{}, where string, key interface{}, set string, args ...interface{}) error {
+	res, err := tx.NewUpdate().
+		Model(row).
+		Set(set, args...).
+		Where(where, key).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	_, err = tx.NewInsert().Model(row).Exec(ctx)
+	return err
+}
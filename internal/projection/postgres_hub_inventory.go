@@ -0,0 +1,122 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// postgresHubInventoryProjection lưu đơn hàng đang nằm tại hub vào bảng hub_parcels
+type postgresHubInventoryProjection struct {
+	db *bun.DB
+}
+
+// NewPostgresHubInventoryProjection tạo projection tồn kho hub dùng cơ sở dữ liệu
+func NewPostgresHubInventoryProjection(db *bun.DB) HubInventoryProjection {
+	return &postgresHubInventoryProjection{
+		db: db,
+	}
+}
+
+// Parcels lấy các đơn hàng đang nằm tại hub theo bộ lọc, nằm lâu nhất trước
+func (p *postgresHubInventoryProjection) Parcels(ctx context.Context, filter HubParcelFilter) ([]HubParcel, error) {
+	var rows []models.HubParcelModel
+	query := p.db.NewSelect().
+		Model(&rows).
+		Order("scanned_in_at ASC", "order_id ASC")
+	if filter.HubCode != "" {
+		query = query.Where("hub_code = ?", filter.HubCode)
+	}
+	if !filter.ScannedBefore.IsZero() {
+		query = query.Where("scanned_in_at < ?", filter.ScannedBefore)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn tồn kho hub: %w", err)
+	}
+
+	parcels := make([]HubParcel, len(rows))
+	for i, row := range rows {
+		parcels[i] = hubParcelFromModel(&row)
+	}
+	return parcels, nil
+}
+
+// HandleEvent thêm, cập nhật hoặc xóa đơn hàng của hub trong một transaction
+func (p *postgresHubInventoryProjection) HandleEvent(event domain.Event) error {
+	switch event.(type) {
+	case domain.ParcelScannedInEvent, domain.ParcelScannedOutEvent, domain.OrderStatusUpdatedEvent:
+	default:
+		return nil
+	}
+
+	ctx := context.Background()
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current, err := p.findByOrderID(ctx, tx, event.GetAggregateID())
+		if err != nil {
+			return err
+		}
+
+		parcel, changed := applyHubInventory(current, event)
+		if !changed {
+			return nil
+		}
+
+		if parcel == nil {
+			_, err = tx.NewDelete().
+				Model((*models.HubParcelModel)(nil)).
+				Where("order_id = ?", event.GetAggregateID()).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("lỗi khi xóa đơn hàng khỏi hub: %w", err)
+			}
+			return nil
+		}
+
+		model := &models.HubParcelModel{
+			OrderID:     parcel.OrderID,
+			HubCode:     parcel.HubCode,
+			ScannedInAt: parcel.ScannedInAt,
+			Version:     parcel.Version,
+		}
+		if current == nil {
+			_, err = tx.NewInsert().Model(model).Exec(ctx)
+		} else {
+			_, err = tx.NewUpdate().Model(model).WherePK().Exec(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu đơn hàng tại hub: %w", err)
+		}
+		return nil
+	})
+}
+
+// findByOrderID lấy hub đơn hàng đang nằm tại, trả về nil nếu đơn hàng không ở hub nào
+func (p *postgresHubInventoryProjection) findByOrderID(ctx context.Context, db bun.IDB, orderID string) (*HubParcel, error) {
+	model := &models.HubParcelModel{}
+	err := db.NewSelect().
+		Model(model).
+		Where("order_id = ?", orderID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lỗi khi tìm đơn hàng tại hub: %w", err)
+	}
+	parcel := hubParcelFromModel(model)
+	return &parcel, nil
+}
+
+func hubParcelFromModel(model *models.HubParcelModel) HubParcel {
+	return HubParcel{
+		OrderID:     model.OrderID,
+		HubCode:     model.HubCode,
+		ScannedInAt: model.ScannedInAt,
+		Version:     model.Version,
+	}
+}
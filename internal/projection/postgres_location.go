@@ -0,0 +1,146 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/uptrace/bun"
+)
+
+// postgresLocationProjection lưu vị trí vào các bảng driver_locations và order_locations
+type postgresLocationProjection struct {
+	db *bun.DB
+}
+
+// NewPostgresLocationProjection tạo projection vị trí dùng cơ sở dữ liệu
+func NewPostgresLocationProjection(db *bun.DB) LocationProjection {
+	return &postgresLocationProjection{
+		db: db,
+	}
+}
+
+// DriverLocation lấy vị trí mới nhất của tài xế
+func (p *postgresLocationProjection) DriverLocation(ctx context.Context, driverID string) (*DriverLocation, error) {
+	model := &models.DriverLocationModel{}
+	err := p.db.NewSelect().
+		Model(model).
+		Where("driver_id = ?", driverID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lỗi khi truy vấn vị trí tài xế: %w", err)
+	}
+
+	return &DriverLocation{
+		DriverID:   model.DriverID,
+		Point:      geo.Point{Latitude: model.Latitude, Longitude: model.Longitude},
+		RecordedAt: model.RecordedAt,
+		ReceivedAt: model.ReceivedAt,
+	}, nil
+}
+
+// OrderLocations lấy vị trí hiện tại của các đơn hàng trong một lần truy vấn
+func (p *postgresLocationProjection) OrderLocations(ctx context.Context, orderIDs []string) (map[string]OrderLocation, error) {
+	locations := make(map[string]OrderLocation, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return locations, nil
+	}
+
+	var rows []models.OrderLocationModel
+	err := p.db.NewSelect().
+		Model(&rows).
+		Where("order_id IN (?)", bun.In(orderIDs)).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn vị trí đơn hàng: %w", err)
+	}
+
+	for _, row := range rows {
+		location := OrderLocation{
+			OrderID:    row.OrderID,
+			DriverID:   row.DriverID,
+			Point:      geo.Point{Latitude: row.Latitude, Longitude: row.Longitude},
+			RecordedAt: row.RecordedAt,
+		}
+		if row.TrailLatitude != nil && row.TrailLongitude != nil && row.TrailRecordedAt != nil {
+			location.Trail = &TrailPoint{
+				Point:      geo.Point{Latitude: *row.TrailLatitude, Longitude: *row.TrailLongitude},
+				Status:     row.TrailStatus,
+				RecordedAt: *row.TrailRecordedAt,
+			}
+		}
+		locations[row.OrderID] = location
+	}
+	return locations, nil
+}
+
+// Redact xóa vị trí của các đơn hàng
+func (p *postgresLocationProjection) Redact(ctx context.Context, orderIDs []string) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	_, err := p.db.NewDelete().
+		Model((*models.OrderLocationModel)(nil)).
+		Where("order_id IN (?)", bun.In(orderIDs)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi xóa vị trí đơn hàng: %w", err)
+	}
+	return nil
+}
+
+// Save ghi đè vị trí của tài xế và các đơn hàng trong cùng một transaction
+func (p *postgresLocationProjection) Save(ctx context.Context, driver DriverLocation, orders []OrderLocation) error {
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(&models.DriverLocationModel{
+				DriverID:   driver.DriverID,
+				Latitude:   driver.Point.Latitude,
+				Longitude:  driver.Point.Longitude,
+				RecordedAt: driver.RecordedAt,
+				ReceivedAt: driver.ReceivedAt,
+			}).
+			On("CONFLICT (driver_id) DO UPDATE").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu vị trí tài xế: %w", err)
+		}
+
+		if len(orders) == 0 {
+			return nil
+		}
+
+		rows := make([]models.OrderLocationModel, len(orders))
+		for i, order := range orders {
+			rows[i] = models.OrderLocationModel{
+				OrderID:    order.OrderID,
+				DriverID:   order.DriverID,
+				Latitude:   order.Point.Latitude,
+				Longitude:  order.Point.Longitude,
+				RecordedAt: order.RecordedAt,
+			}
+			if trail := order.Trail; trail != nil {
+				latitude, longitude, recordedAt := trail.Point.Latitude, trail.Point.Longitude, trail.RecordedAt
+				rows[i].TrailLatitude = &latitude
+				rows[i].TrailLongitude = &longitude
+				rows[i].TrailStatus = trail.Status
+				rows[i].TrailRecordedAt = &recordedAt
+			}
+		}
+		_, err = tx.NewInsert().
+			Model(&rows).
+			On("CONFLICT (order_id) DO UPDATE").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu vị trí đơn hàng: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,189 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// postgresReconciliationProjection lưu khoản thu của từng đơn hàng và cộng dồn đối soát vào bảng cod_balances
+type postgresReconciliationProjection struct {
+	db *bun.DB
+}
+
+// NewPostgresReconciliationProjection tạo projection đối soát thu hộ dùng cơ sở dữ liệu
+func NewPostgresReconciliationProjection(db *bun.DB) ReconciliationProjection {
+	return &postgresReconciliationProjection{
+		db: db,
+	}
+}
+
+// Balances lấy đối soát thu hộ theo bộ lọc, cũ nhất trước
+func (p *postgresReconciliationProjection) Balances(ctx context.Context, filter CODBalanceFilter) ([]CODBalance, error) {
+	var rows []models.CODBalanceModel
+	query := p.db.NewSelect().
+		Model(&rows).
+		Order("day ASC", "driver_id ASC", "currency ASC")
+	if filter.DriverID != "" {
+		query = query.Where("driver_id = ?", filter.DriverID)
+	}
+	if filter.From != "" {
+		query = query.Where("day >= ?", filter.From)
+	}
+	if filter.To != "" {
+		query = query.Where("day <= ?", filter.To)
+	}
+	if !filter.IncludeSettled {
+		query = query.Where("collected_amount <> remitted_amount")
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn đối soát thu hộ: %w", err)
+	}
+
+	balances := make([]CODBalance, len(rows))
+	for i, row := range rows {
+		balances[i] = CODBalance{
+			DriverID:        row.DriverID,
+			Day:             row.Day,
+			Currency:        row.Currency,
+			CollectedOrders: row.CollectedOrders,
+			Collected:       row.CollectedAmount,
+			RemittedOrders:  row.RemittedOrders,
+			Remitted:        row.RemittedAmount,
+		}
+	}
+	return balances, nil
+}
+
+// Outstanding lấy các khoản tài xế đã thu nhưng chưa nộp, cũ nhất trước
+func (p *postgresReconciliationProjection) Outstanding(ctx context.Context, driverID string) ([]CODCollection, error) {
+	var rows []models.CODCollectionModel
+	err := p.db.NewSelect().
+		Model(&rows).
+		Where("driver_id = ?", driverID).
+		Where("remitted_at IS NULL").
+		Order("collected_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn khoản thu hộ chưa nộp: %w", err)
+	}
+
+	collections := make([]CODCollection, len(rows))
+	for i, row := range rows {
+		collections[i] = collectionFromModel(&row)
+	}
+	return collections, nil
+}
+
+// HandleEvent lưu khoản thu và cộng dồn đối soát trong cùng một transaction
+func (p *postgresReconciliationProjection) HandleEvent(event domain.Event) error {
+	switch event.(type) {
+	case domain.PaymentCollectedEvent, domain.PaymentRemittedEvent:
+	default:
+		return nil
+	}
+
+	ctx := context.Background()
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current, err := p.findByOrderID(ctx, tx, event.GetAggregateID())
+		if err != nil {
+			return err
+		}
+
+		change := applyReconciliation(current, event)
+		if change == nil {
+			return nil
+		}
+
+		collection := change.Collection
+		model := &models.CODCollectionModel{
+			ID:          collection.OrderID,
+			DriverID:    collection.DriverID,
+			Day:         collection.Day,
+			Amount:      collection.Amount,
+			Currency:    collection.Currency,
+			CollectedAt: collection.CollectedAt,
+			RemittedAt:  collection.RemittedAt,
+		}
+		if current == nil {
+			_, err = tx.NewInsert().Model(model).Exec(ctx)
+		} else {
+			_, err = tx.NewUpdate().Model(model).WherePK().Exec(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu khoản thu hộ: %w", err)
+		}
+
+		balance := &models.CODBalanceModel{
+			DriverID:        collection.DriverID,
+			Day:             collection.Day,
+			Currency:        collection.Currency,
+			CollectedAmount: decimal.Zero,
+			RemittedAmount:  decimal.Zero,
+		}
+		set := "remitted_orders = remitted_orders + 1, remitted_amount = remitted_amount + ?"
+		if change.Collected {
+			balance.CollectedOrders, balance.CollectedAmount = 1, collection.Amount
+			set = "collected_orders = collected_orders + 1, collected_amount = collected_amount + ?"
+		} else {
+			balance.RemittedOrders, balance.RemittedAmount = 1, collection.Amount
+		}
+		if err := incrementBalance(ctx, tx, balance, set, collection.Amount); err != nil {
+			return fmt.Errorf("lỗi khi cập nhật đối soát thu hộ: %w", err)
+		}
+		return nil
+	})
+}
+
+// findByOrderID lấy khoản thu của đơn hàng, trả về nil nếu tài xế chưa thu tiền
+func (p *postgresReconciliationProjection) findByOrderID(ctx context.Context, db bun.IDB, orderID string) (*CODCollection, error) {
+	model := &models.CODCollectionModel{}
+	err := db.NewSelect().
+		Model(model).
+		Where("id = ?", orderID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lỗi khi tìm khoản thu hộ: %w", err)
+	}
+	collection := collectionFromModel(model)
+	return &collection, nil
+}
+
+// incrementBalance cộng dồn vào dòng đối soát có cùng khóa chính với row, tạo dòng mới từ row nếu chưa tồn tại
+func incrementBalance(ctx context.Context, tx bun.Tx, row *models.CODBalanceModel, set string, amount decimal.Decimal) error {
+	res, err := tx.NewUpdate().
+		Model(row).
+		Set(set, amount).
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	_, err = tx.NewInsert().Model(row).Exec(ctx)
+	return err
+}
+
+func collectionFromModel(model *models.CODCollectionModel) CODCollection {
+	return CODCollection{
+		OrderID:     model.ID,
+		DriverID:    model.DriverID,
+		Day:         model.Day,
+		Amount:      model.Amount,
+		Currency:    model.Currency,
+		CollectedAt: model.CollectedAt,
+		RemittedAt:  model.RemittedAt,
+	}
+}
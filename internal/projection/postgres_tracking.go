@@ -0,0 +1,189 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// postgresTrackingProjection lưu projection theo dõi trong bảng tracking_info và tracking_updates
+type postgresTrackingProjection struct {
+	db *bun.DB
+}
+
+// NewPostgresTrackingProjection tạo projection theo dõi dùng cơ sở dữ liệu
+func NewPostgresTrackingProjection(db *bun.DB) TrackingProjection {
+	return &postgresTrackingProjection{
+		db: db,
+	}
+}
+
+// GetByTrackingNumber lấy thông tin theo dõi kèm lịch trình, cũ nhất trước
+func (p *postgresTrackingProjection) GetByTrackingNumber(ctx context.Context, trackingNumber string) (*TrackingInfo, error) {
+	model := &models.TrackingModel{}
+	err := p.db.NewSelect().
+		Model(model).
+		Where("tracking_number = ?", trackingNumber).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTrackingNotFound
+		}
+		return nil, fmt.Errorf("lỗi khi truy vấn thông tin theo dõi: %w", err)
+	}
+
+	var updates []models.TrackingUpdateModel
+	err = p.db.NewSelect().
+		Model(&updates).
+		Where("tracking_id = ?", model.ID).
+		Order("timestamp ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn lịch trình theo dõi: %w", err)
+	}
+
+	info := modelToTracking(model)
+	info.Updates = make([]TrackingUpdate, len(updates))
+	for i, update := range updates {
+		info.Updates[i] = TrackingUpdate{
+			ID:        update.ID,
+			Timestamp: update.Timestamp,
+			Status:    update.Status,
+			Location:  update.Location,
+			Message:   update.Message,
+		}
+	}
+	return info, nil
+}
+
+// HandleEvent cập nhật thông tin theo dõi và thêm mục lịch trình trong cùng một transaction
+func (p *postgresTrackingProjection) HandleEvent(event domain.Event) error {
+	ctx := context.Background()
+
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current, err := p.findByOrderID(ctx, tx, event.GetAggregateID())
+		if err != nil {
+			return err
+		}
+
+		info, update := applyTracking(current, event)
+		if info == nil {
+			return nil
+		}
+
+		model := trackingToModel(info)
+		if current == nil {
+			_, err = tx.NewInsert().Model(model).Exec(ctx)
+		} else {
+			_, err = tx.NewUpdate().Model(model).WherePK().Exec(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu thông tin theo dõi: %w", err)
+		}
+
+		if update == nil {
+			return nil
+		}
+		_, err = tx.NewInsert().
+			Model(&models.TrackingUpdateModel{
+				ID:         update.ID,
+				TrackingID: info.OrderID,
+				Timestamp:  update.Timestamp,
+				Status:     update.Status,
+				Location:   update.Location,
+				Message:    update.Message,
+			}).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu lịch trình theo dõi: %w", err)
+		}
+		return nil
+	})
+}
+
+// Redact ẩn các thành phố của đơn hàng và lịch trình trong cùng một transaction
+func (p *postgresTrackingProjection) Redact(ctx context.Context, orderIDs []string) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		query := tx.NewUpdate().
+			Model((*models.TrackingModel)(nil)).
+			Where("id IN (?)", bun.In(orderIDs))
+		// Thành phố rỗng vẫn để rỗng như redactTracking
+		for _, column := range []string{"origin_city", "destination_city", "current_city"} {
+			query = query.Set("? = CASE WHEN ? IS NULL OR ? = '' THEN ? ELSE ? END",
+				bun.Ident(column), bun.Ident(column), bun.Ident(column), bun.Ident(column), domain.RedactedValue)
+		}
+		if _, err := query.Exec(ctx); err != nil {
+			return fmt.Errorf("lỗi khi ẩn thông tin theo dõi: %w", err)
+		}
+
+		_, err := tx.NewUpdate().
+			Model((*models.TrackingUpdateModel)(nil)).
+			Set("location = ?", domain.RedactedValue).
+			Where("tracking_id IN (?)", bun.In(orderIDs)).
+			Where("location <> ''").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi ẩn lịch trình theo dõi: %w", err)
+		}
+		return nil
+	})
+}
+
+// findByOrderID lấy thông tin theo dõi (không kèm lịch trình), trả về nil nếu chưa tồn tại
+func (p *postgresTrackingProjection) findByOrderID(ctx context.Context, db bun.IDB, orderID string) (*TrackingInfo, error) {
+	model := &models.TrackingModel{}
+	err := db.NewSelect().
+		Model(model).
+		Where("id = ?", orderID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lỗi khi tìm thông tin theo dõi: %w", err)
+	}
+	return modelToTracking(model), nil
+}
+
+func trackingToModel(info *TrackingInfo) *models.TrackingModel {
+	return &models.TrackingModel{
+		ID:                info.OrderID,
+		TrackingNumber:    info.TrackingNumber,
+		Status:            info.Status,
+		ServiceLevel:      info.ServiceLevel,
+		OriginCity:        info.OriginCity,
+		DestinationCity:   info.DestinationCity,
+		CurrentCity:       info.CurrentCity,
+		EstimatedDelivery: info.EstimatedDelivery,
+		Delayed:           info.Delayed,
+		Version:           info.Version,
+		CreatedAt:         info.CreatedAt,
+		UpdatedAt:         info.UpdatedAt,
+	}
+}
+
+func modelToTracking(model *models.TrackingModel) *TrackingInfo {
+	return &TrackingInfo{
+		OrderID:           model.ID,
+		TrackingNumber:    model.TrackingNumber,
+		Status:            model.Status,
+		ServiceLevel:      model.ServiceLevel,
+		OriginCity:        model.OriginCity,
+		DestinationCity:   model.DestinationCity,
+		CurrentCity:       model.CurrentCity,
+		EstimatedDelivery: model.EstimatedDelivery,
+		Delayed:           model.Delayed,
+		Version:           model.Version,
+		CreatedAt:         model.CreatedAt,
+		UpdatedAt:         model.UpdatedAt,
+	}
+}
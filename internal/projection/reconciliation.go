@@ -0,0 +1,109 @@
+package projection
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// CODCollection là khoản tiền thu hộ tài xế đã thu của một đơn hàng
+type CODCollection struct {
+	OrderID     string
+	DriverID    string
+	Day         string // ngày thu theo DayLayout (UTC)
+	Amount      decimal.Decimal
+	Currency    string
+	CollectedAt time.Time
+	RemittedAt  *time.Time // nil khi tài xế chưa nộp tiền
+}
+
+// CODBalance là đối soát tiền thu hộ của một tài xế theo ngày thu và tiền tệ
+type CODBalance struct {
+	DriverID        string
+	Day             string
+	Currency        string
+	CollectedOrders int
+	Collected       decimal.Decimal
+	RemittedOrders  int
+	Remitted        decimal.Decimal
+}
+
+// Outstanding là số tiền tài xế đã thu nhưng chưa nộp
+func (b CODBalance) Outstanding() decimal.Decimal {
+	return b.Collected.Sub(b.Remitted)
+}
+
+// CODBalanceFilter lọc đối soát thu hộ, giá trị rỗng nghĩa là không lọc
+type CODBalanceFilter struct {
+	DriverID string
+	From     string // theo DayLayout
+	To       string
+	// IncludeSettled trả về cả các ngày tài xế đã nộp đủ tiền
+	IncludeSettled bool
+}
+
+// ReconciliationProjection lắng nghe sự kiện thanh toán và cộng dồn tiền thu hộ theo tài xế và ngày
+type ReconciliationProjection interface {
+	// Balances lấy đối soát thu hộ, cũ nhất trước
+	Balances(ctx context.Context, filter CODBalanceFilter) ([]CODBalance, error)
+
+	// Outstanding lấy các khoản tài xế đã thu nhưng chưa nộp, cũ nhất trước
+	Outstanding(ctx context.Context, driverID string) ([]CODCollection, error)
+
+	// HandleEvent cập nhật projection, mỗi đơn hàng chỉ được cộng một lần khi thu và một lần khi nộp nên có thể phát lại
+	HandleEvent(event domain.Event) error
+}
+
+// reconciliationChange là phần thay đổi của đối soát do một sự kiện tạo ra
+type reconciliationChange struct {
+	Collection *CODCollection
+	Collected  bool // khoản thu mới
+	Remitted   bool // khoản thu vừa được nộp
+}
+
+// applyReconciliation áp dụng sự kiện lên khoản thu của đơn hàng (nil nếu chưa thu).
+// Trả về nil nếu sự kiện không làm thay đổi đối soát.
+func applyReconciliation(current *CODCollection, event domain.Event) *reconciliationChange {
+	switch e := event.(type) {
+	case domain.PaymentCollectedEvent:
+		if current != nil {
+			return nil
+		}
+		return &reconciliationChange{
+			Collection: &CODCollection{
+				OrderID:     e.AggregateID,
+				DriverID:    e.DriverID,
+				Day:         e.Timestamp.UTC().Format(DayLayout),
+				Amount:      e.Amount,
+				Currency:    e.Currency,
+				CollectedAt: e.Timestamp,
+			},
+			Collected: true,
+		}
+	case domain.PaymentRemittedEvent:
+		if current == nil || current.RemittedAt != nil {
+			return nil
+		}
+		collection := *current
+		remittedAt := e.Timestamp
+		collection.RemittedAt = &remittedAt
+		return &reconciliationChange{Collection: &collection, Remitted: true}
+	}
+	return nil
+}
+
+// sortBalances sắp xếp đối soát theo ngày, tài xế rồi tiền tệ
+func sortBalances(balances []CODBalance) {
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Day != balances[j].Day {
+			return balances[i].Day < balances[j].Day
+		}
+		if balances[i].DriverID != balances[j].DriverID {
+			return balances[i].DriverID < balances[j].DriverID
+		}
+		return balances[i].Currency < balances[j].Currency
+	})
+}
@@ -0,0 +1,156 @@
+package projection_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestInMemoryReconciliationProjection(t *testing.T) {
+	runReconciliationTests(t, func(t *testing.T) projection.ReconciliationProjection {
+		return projection.NewInMemoryReconciliationProjection()
+	})
+}
+
+func TestSQLiteReconciliationProjection(t *testing.T) {
+	runReconciliationTests(t, func(t *testing.T) projection.ReconciliationProjection {
+		dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+		sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+		if err != nil {
+			t.Fatalf("sql.Open: %v", err)
+		}
+		sqldb.SetMaxOpenConns(1)
+		db := bun.NewDB(sqldb, sqlitedialect.New())
+		t.Cleanup(func() { db.Close() })
+
+		if err := (migrations.CODTables{}).Up(db); err != nil {
+			t.Fatalf("CODTables.Up: %v", err)
+		}
+		return projection.NewPostgresReconciliationProjection(db)
+	})
+}
+
+func codCollected(orderID, driverID, amount string, version, day int) domain.PaymentCollectedEvent {
+	return domain.NewPaymentCollectedEvent(analyticsBase(orderID, domain.PaymentCollectedType, version, day), driverID, decimal.RequireFromString(amount), "VND")
+}
+
+func codRemitted(orderID, driverID, amount string, version, day int) domain.PaymentRemittedEvent {
+	return domain.NewPaymentRemittedEvent(analyticsBase(orderID, domain.PaymentRemittedType, version, day), driverID, decimal.RequireFromString(amount), "VND", "PN-1")
+}
+
+// reconciliationHistory gồm hai tài xế trên hai ngày:
+// DRV-001 thu order-1, order-2 ngày 16 và order-3 ngày 17, mới nộp order-1;
+// DRV-002 thu và nộp đủ order-4
+func reconciliationHistory() []domain.Event {
+	return []domain.Event{
+		codCollected("order-1", "DRV-001", "250000", 4, 0),
+		codCollected("order-2", "DRV-001", "120000.5", 4, 0),
+		codCollected("order-3", "DRV-001", "80000", 4, 1),
+		codRemitted("order-1", "DRV-001", "250000", 5, 1),
+		codCollected("order-4", "DRV-002", "50000", 4, 1),
+		codRemitted("order-4", "DRV-002", "50000", 5, 1),
+		// Sự kiện khác và lần nộp của đơn hàng chưa thu bị bỏ qua
+		domain.NewPaymentRefundRequiredEvent(analyticsBase("order-1", domain.PaymentRefundRequiredType, 6, 1), decimal.NewFromInt(250000), "VND"),
+		codRemitted("order-5", "DRV-001", "10000", 5, 1),
+	}
+}
+
+// formatBalances mô tả đối soát dưới dạng chuỗi để so sánh số tiền không phụ thuộc cách lưu decimal
+func formatBalances(balances []projection.CODBalance) string {
+	rows := make([]string, len(balances))
+	for i, b := range balances {
+		rows[i] = fmt.Sprintf("%s %s %s %d/%s %d/%s còn %s", b.Day, b.DriverID, b.Currency,
+			b.CollectedOrders, b.Collected, b.RemittedOrders, b.Remitted, b.Outstanding())
+	}
+	return strings.Join(rows, "\n")
+}
+
+func runReconciliationTests(t *testing.T, newProjection func(t *testing.T) projection.ReconciliationProjection) {
+	ctx := context.Background()
+
+	handle := func(t *testing.T, p projection.ReconciliationProjection, times int) {
+		for i := 0; i < times; i++ {
+			for _, event := range reconciliationHistory() {
+				if err := p.HandleEvent(event); err != nil {
+					t.Fatalf("HandleEvent %s: %v", event.GetType(), err)
+				}
+			}
+		}
+	}
+
+	check := func(t *testing.T, p projection.ReconciliationProjection) {
+		balances, err := p.Balances(ctx, projection.CODBalanceFilter{})
+		if err != nil {
+			t.Fatalf("Balances: %v", err)
+		}
+		want := "2025-03-16 DRV-001 VND 2/370000.5 1/250000 còn 120000.5\n" +
+			"2025-03-17 DRV-001 VND 1/80000 0/0 còn 80000"
+		if got := formatBalances(balances); got != want {
+			t.Fatalf("Balances =\n%s\nmuốn\n%s", got, want)
+		}
+
+		outstanding, err := p.Outstanding(ctx, "DRV-001")
+		if err != nil {
+			t.Fatalf("Outstanding: %v", err)
+		}
+		if len(outstanding) != 2 || outstanding[0].OrderID != "order-2" || outstanding[1].OrderID != "order-3" {
+			t.Fatalf("Outstanding = %+v", outstanding)
+		}
+		if outstanding[0].Day != "2025-03-16" || outstanding[0].Amount.String() != "120000.5" || outstanding[0].RemittedAt != nil {
+			t.Fatalf("Outstanding[0] = %+v", outstanding[0])
+		}
+	}
+
+	t.Run("Aggregates", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 1)
+		check(t, p)
+	})
+
+	t.Run("ReplayIsIdempotent", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 2)
+		check(t, p)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		p := newProjection(t)
+		handle(t, p, 1)
+
+		balances, err := p.Balances(ctx, projection.CODBalanceFilter{IncludeSettled: true, From: "2025-03-17"})
+		if err != nil {
+			t.Fatalf("Balances: %v", err)
+		}
+		want := "2025-03-17 DRV-001 VND 1/80000 0/0 còn 80000\n" +
+			"2025-03-17 DRV-002 VND 1/50000 1/50000 còn 0"
+		if got := formatBalances(balances); got != want {
+			t.Fatalf("Balances =\n%s\nmuốn\n%s", got, want)
+		}
+
+		balances, err = p.Balances(ctx, projection.CODBalanceFilter{DriverID: "DRV-002"})
+		if err != nil {
+			t.Fatalf("Balances: %v", err)
+		}
+		if len(balances) != 0 {
+			t.Fatalf("tài xế đã nộp đủ không còn số dư, Balances = %s", formatBalances(balances))
+		}
+
+		outstanding, err := p.Outstanding(ctx, "DRV-002")
+		if err != nil {
+			t.Fatalf("Outstanding: %v", err)
+		}
+		if len(outstanding) != 0 {
+			t.Fatalf("Outstanding = %+v, muốn rỗng", outstanding)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+// Package projection xây dựng các khung nhìn đọc chuyên biệt từ sự kiện domain
+package projection
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// ErrTrackingNotFound được trả về khi không có thông tin theo dõi cho số theo dõi
+var ErrTrackingNotFound = errors.New("không tìm thấy thông tin theo dõi")
+
+// TrackingInfo là thông tin theo dõi công khai của đơn hàng.
+// Chỉ chứa dữ liệu an toàn để hiển thị cho bất kỳ ai có số theo dõi:
+// không có khách hàng, địa chỉ, tọa độ hay ghi chú nội bộ.
+type TrackingInfo struct {
+	OrderID           string
+	TrackingNumber    string
+	Status            domain.OrderStatus
+	ServiceLevel      domain.ServiceLevel
+	OriginCity        string
+	DestinationCity   string
+	CurrentCity       string
+	EstimatedDelivery *time.Time
+	Delayed           bool // đơn hàng đã quá thời gian giao hàng cam kết
+	Version           int  // phiên bản sự kiện cuối cùng đã áp dụng
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Updates           []TrackingUpdate // cũ nhất trước
+}
+
+// TrackingUpdate là một mục trong lịch trình theo dõi
+type TrackingUpdate struct {
+	ID        string // ID của sự kiện tạo ra mục này
+	Timestamp time.Time
+	Status    domain.OrderStatus
+	Location  string // thành phố
+	Message   string
+}
+
+// TrackingProjection lắng nghe sự kiện đơn hàng và phục vụ trang theo dõi công khai
+type TrackingProjection interface {
+	// GetByTrackingNumber lấy thông tin theo dõi kèm lịch trình, trả về ErrTrackingNotFound nếu không có
+	GetByTrackingNumber(ctx context.Context, trackingNumber string) (*TrackingInfo, error)
+
+	// HandleEvent cập nhật projection, sự kiện đã áp dụng (theo phiên bản) được bỏ qua nên có thể phát lại
+	HandleEvent(event domain.Event) error
+
+	// Redact ẩn các thành phố của đơn hàng trong thông tin theo dõi và lịch trình
+	Redactor
+}
+
+// Redactor được các projection giữ bản sao dữ liệu của đơn hàng triển khai
+// để ẩn dữ liệu đó khi khách hàng yêu cầu xóa dữ liệu cá nhân
+type Redactor interface {
+	// Redact thay dữ liệu của các đơn hàng bằng domain.RedactedValue hoặc xóa dữ liệu không thể thay thế
+	Redact(ctx context.Context, orderIDs []string) error
+}
+
+// statusMessages là thông điệp hiển thị cho khách hàng theo trạng thái, thay cho ghi chú nội bộ
+var statusMessages = map[domain.OrderStatus]string{
+	domain.OrderStatusCreated:        "Đơn hàng đã được tạo",
+	domain.OrderStatusProcessing:     "Đơn hàng đang được xử lý tại kho",
+	domain.OrderStatusInTransit:      "Đơn hàng đang được vận chuyển",
+	domain.OrderStatusOutForDelivery: "Đơn hàng đang được giao tới người nhận",
+	domain.OrderStatusDelivered:      "Đơn hàng đã được giao thành công",
+	domain.OrderStatusException:      "Đơn hàng gặp sự cố trong quá trình vận chuyển",
+	domain.OrderStatusCancelled:      "Đơn hàng đã bị hủy",
+}
+
+// applyTracking áp dụng sự kiện lên thông tin theo dõi hiện tại (nil nếu chưa có).
+// Trả về thông tin mới cùng mục lịch trình nếu sự kiện hiển thị cho khách hàng,
+// hoặc nil nếu sự kiện không làm thay đổi projection.
+func applyTracking(current *TrackingInfo, event domain.Event) (*TrackingInfo, *TrackingUpdate) {
+	if e, ok := event.(domain.OrderCreatedEvent); ok {
+		if current != nil {
+			return nil, nil
+		}
+		serviceLevel := e.ServiceLevel
+		if serviceLevel == "" {
+			serviceLevel = domain.ServiceLevelStandard
+		}
+		info := &TrackingInfo{
+			OrderID:         e.AggregateID,
+			TrackingNumber:  e.TrackingNumber,
+			Status:          domain.OrderStatusCreated,
+			ServiceLevel:    serviceLevel,
+			OriginCity:      e.Origin.City,
+			DestinationCity: e.Destination.City,
+			Version:         e.Version,
+			CreatedAt:       e.Timestamp,
+			UpdatedAt:       e.Timestamp,
+		}
+		return info, newTrackingUpdate(e, domain.OrderStatusCreated, e.Origin.City)
+	}
+
+	if current == nil || event.GetVersion() <= current.Version {
+		return nil, nil
+	}
+
+	info := *current
+	info.Updates = nil
+	info.Version = event.GetVersion()
+
+	var update *TrackingUpdate
+	switch e := event.(type) {
+	case domain.OrderStatusUpdatedEvent:
+		info.Status = e.NewStatus
+		info.UpdatedAt = e.Timestamp
+		if e.CurrentLocation != nil {
+			info.CurrentCity = e.CurrentLocation.City
+		}
+		update = newTrackingUpdate(e, e.NewStatus, info.CurrentCity)
+	case domain.OrderCancelledEvent:
+		info.Status = domain.OrderStatusCancelled
+		info.UpdatedAt = e.Timestamp
+		update = newTrackingUpdate(e, domain.OrderStatusCancelled, "")
+	case domain.DeliveryEstimatedEvent:
+		estimated := e.EstimatedDelivery
+		info.EstimatedDelivery = &estimated
+	case domain.SLABreachedEvent:
+		info.Delayed = true
+	default:
+		// Ghi chú và các sự kiện nội bộ khác không hiển thị cho khách hàng
+		return nil, nil
+	}
+
+	return &info, update
+}
+
+// redactTracking ẩn các thành phố trong thông tin theo dõi và lịch trình
+func redactTracking(info *TrackingInfo) {
+	info.OriginCity = redact(info.OriginCity)
+	info.DestinationCity = redact(info.DestinationCity)
+	info.CurrentCity = redact(info.CurrentCity)
+	for i := range info.Updates {
+		info.Updates[i].Location = redact(info.Updates[i].Location)
+	}
+}
+
+// redact trả về domain.RedactedValue thay cho giá trị khác rỗng
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return domain.RedactedValue
+}
+
+func newTrackingUpdate(event domain.Event, status domain.OrderStatus, location string) *TrackingUpdate {
+	return &TrackingUpdate{
+		ID:        event.GetID(),
+		Timestamp: event.GetTimestamp(),
+		Status:    status,
+		Location:  location,
+		Message:   statusMessages[status],
+	}
+}
@@ -0,0 +1,177 @@
+package projection_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/domain/domaintest"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/migrations"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestInMemoryTrackingProjection(t *testing.T) {
+	runTrackingTests(t, func(t *testing.T) projection.TrackingProjection {
+		return projection.NewInMemoryTrackingProjection()
+	})
+}
+
+func TestSQLiteTrackingProjection(t *testing.T) {
+	runTrackingTests(t, func(t *testing.T) projection.TrackingProjection {
+		dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+		sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+		if err != nil {
+			t.Fatalf("sql.Open: %v", err)
+		}
+		sqldb.SetMaxOpenConns(1)
+		db := bun.NewDB(sqldb, sqlitedialect.New())
+		t.Cleanup(func() { db.Close() })
+
+		if err := (migrations.TrackingTables{}).Up(db); err != nil {
+			t.Fatalf("TrackingTables.Up: %v", err)
+		}
+		return projection.NewPostgresTrackingProjection(db)
+	})
+}
+
+// trackingHistory là lịch sử một đơn hàng gồm ghi chú và lý do nội bộ không được lộ ra ngoài
+func trackingHistory() []domain.Event {
+	hub := &domain.Location{Address: "Kho 5, KCN Sóng Thần", City: "Bình Dương", Latitude: 10.9, Longitude: 106.75}
+	estimated := domaintest.Now.Add(48 * time.Hour)
+
+	return []domain.Event{
+		domaintest.Created(),
+		domain.NewDeliveryEstimatedEvent(domaintest.Base(domain.DeliveryEstimatedType, 2), estimated, estimated.Add(24*time.Hour)),
+		domain.NewOrderStatusUpdatedEvent(domaintest.Base(domain.OrderStatusUpdatedType, 3), domain.OrderStatusCreated, domain.OrderStatusInTransit, hub, "Tài xế Nguyễn Văn A, SĐT 0901234567"),
+		domain.NewOrderNoteAddedEvent(domaintest.Base(domain.OrderNoteAddedType, 4), "Khách hàng khó tính, gọi trước khi giao"),
+		domain.NewSLABreachedEvent(domaintest.Base(domain.SLABreachedType, 5), estimated.Add(24*time.Hour), domain.OrderStatusInTransit),
+		domain.NewOrderCancelledEvent(domaintest.Base(domain.OrderCancelledType, 6), domain.OrderStatusInTransit, "Khách hàng không nghe máy"),
+	}
+}
+
+func runTrackingTests(t *testing.T, newProjection func(t *testing.T) projection.TrackingProjection) {
+	ctx := context.Background()
+
+	t.Run("Timeline", func(t *testing.T) {
+		p := newProjection(t)
+		for _, event := range trackingHistory() {
+			if err := p.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent %s: %v", event.GetType(), err)
+			}
+		}
+
+		info, err := p.GetByTrackingNumber(ctx, domaintest.TrackingNumber)
+		if err != nil {
+			t.Fatalf("GetByTrackingNumber: %v", err)
+		}
+		if info.OrderID != domaintest.OrderID || info.Status != domain.OrderStatusCancelled || info.Version != 6 {
+			t.Fatalf("info = %+v", info)
+		}
+		if info.OriginCity != "Hồ Chí Minh" || info.DestinationCity != "Hà Nội" || info.CurrentCity != "Bình Dương" {
+			t.Fatalf("cities = %q, %q, %q", info.OriginCity, info.DestinationCity, info.CurrentCity)
+		}
+		if info.EstimatedDelivery == nil || !info.EstimatedDelivery.Equal(domaintest.Now.Add(48*time.Hour)) {
+			t.Fatalf("EstimatedDelivery = %v", info.EstimatedDelivery)
+		}
+		if !info.Delayed {
+			t.Fatal("Delayed = false, muốn true")
+		}
+
+		wantStatuses := []domain.OrderStatus{domain.OrderStatusCreated, domain.OrderStatusInTransit, domain.OrderStatusCancelled}
+		if len(info.Updates) != len(wantStatuses) {
+			t.Fatalf("có %d mục lịch trình, muốn %d: %+v", len(info.Updates), len(wantStatuses), info.Updates)
+		}
+		for i, update := range info.Updates {
+			if update.Status != wantStatuses[i] || update.Message == "" {
+				t.Fatalf("Updates[%d] = %+v", i, update)
+			}
+			// Ghi chú nội bộ và lý do hủy không được xuất hiện trên trang công khai
+			for _, secret := range []string{"0901234567", "khó tính", "không nghe máy", "Sóng Thần"} {
+				if strings.Contains(update.Message, secret) || strings.Contains(update.Location, secret) {
+					t.Fatalf("Updates[%d] chứa thông tin nội bộ %q: %+v", i, secret, update)
+				}
+			}
+		}
+		if info.Updates[1].Location != "Bình Dương" {
+			t.Fatalf("Updates[1].Location = %q", info.Updates[1].Location)
+		}
+	})
+
+	t.Run("ReplayIsIdempotent", func(t *testing.T) {
+		p := newProjection(t)
+		for i := 0; i < 2; i++ {
+			for _, event := range trackingHistory() {
+				if err := p.HandleEvent(event); err != nil {
+					t.Fatalf("HandleEvent %s: %v", event.GetType(), err)
+				}
+			}
+		}
+
+		info, err := p.GetByTrackingNumber(ctx, domaintest.TrackingNumber)
+		if err != nil {
+			t.Fatalf("GetByTrackingNumber: %v", err)
+		}
+		if len(info.Updates) != 3 || info.Version != 6 {
+			t.Fatalf("sau khi phát lại có %d mục lịch trình, version %d", len(info.Updates), info.Version)
+		}
+	})
+
+	t.Run("Redact", func(t *testing.T) {
+		p := newProjection(t)
+		for _, event := range trackingHistory() {
+			if err := p.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent %s: %v", event.GetType(), err)
+			}
+		}
+		if err := p.Redact(ctx, []string{domaintest.OrderID}); err != nil {
+			t.Fatalf("Redact: %v", err)
+		}
+		// Phát lại sau khi ẩn không khôi phục thành phố
+		for _, event := range trackingHistory() {
+			if err := p.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent %s: %v", event.GetType(), err)
+			}
+		}
+
+		info, err := p.GetByTrackingNumber(ctx, domaintest.TrackingNumber)
+		if err != nil {
+			t.Fatalf("GetByTrackingNumber: %v", err)
+		}
+		if info.OriginCity != domain.RedactedValue || info.DestinationCity != domain.RedactedValue || info.CurrentCity != domain.RedactedValue {
+			t.Fatalf("cities = %q, %q, %q", info.OriginCity, info.DestinationCity, info.CurrentCity)
+		}
+		// Mục hủy không có thành phố nên vẫn để rỗng
+		wantLocations := []string{domain.RedactedValue, domain.RedactedValue, ""}
+		if len(info.Updates) != len(wantLocations) {
+			t.Fatalf("Updates = %+v", info.Updates)
+		}
+		for i, update := range info.Updates {
+			if update.Location != wantLocations[i] || update.Status == "" {
+				t.Fatalf("Updates[%d] = %+v, muốn Location %q", i, update, wantLocations[i])
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		p := newProjection(t)
+		if _, err := p.GetByTrackingNumber(ctx, "TRK-UNKNOWN"); !errors.Is(err, projection.ErrTrackingNotFound) {
+			t.Fatalf("err = %v, muốn ErrTrackingNotFound", err)
+		}
+
+		// Sự kiện của đơn hàng chưa có trong projection bị bỏ qua
+		if err := p.HandleEvent(domaintest.StatusUpdated(2, domain.OrderStatusCreated, domain.OrderStatusInTransit)); err != nil {
+			t.Fatalf("HandleEvent: %v", err)
+		}
+		if _, err := p.GetByTrackingNumber(ctx, domaintest.TrackingNumber); !errors.Is(err, projection.ErrTrackingNotFound) {
+			t.Fatalf("err = %v, muốn ErrTrackingNotFound", err)
+		}
+	})
+}
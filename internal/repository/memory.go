@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+// inMemoryOrderRepository lưu read model đơn hàng trong bộ nhớ, dùng cho kiểm thử và chạy local
+type inMemoryOrderRepository struct {
+	mu     sync.RWMutex
+	orders map[string]*domain.Order
+}
+
+// NewInMemoryOrderRepository tạo repository đơn hàng trong bộ nhớ
+func NewInMemoryOrderRepository() OrderRepository {
+	return &inMemoryOrderRepository{
+		orders: make(map[string]*domain.Order),
+	}
+}
+
+// GetByID lấy đơn hàng theo ID
+func (r *inMemoryOrderRepository) GetByID(_ context.Context, id string) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, errors.New("không tìm thấy đơn hàng")
+	}
+	return cloneOrder(order), nil
+}
+
+// GetByTrackingNumber lấy đơn hàng theo số theo dõi
+func (r *inMemoryOrderRepository) GetByTrackingNumber(_ context.Context, trackingNumber string) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, order := range r.orders {
+		if order.TrackingNumber == trackingNumber {
+			return cloneOrder(order), nil
+		}
+	}
+	return nil, errors.New("không tìm thấy đơn hàng")
+}
+
+// ResolveTrackingNumbers trả về ánh xạ số theo dõi sang ID đơn hàng
+func (r *inMemoryOrderRepository) ResolveTrackingNumbers(_ context.Context, trackingNumbers []string) (map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(trackingNumbers))
+	for _, trackingNumber := range trackingNumbers {
+		wanted[trackingNumber] = true
+	}
+
+	ids := make(map[string]string, len(trackingNumbers))
+	for _, order := range r.orders {
+		if wanted[order.TrackingNumber] {
+			ids[order.TrackingNumber] = order.ID
+		}
+	}
+	return ids, nil
+}
+
+// ListOrders lấy danh sách đơn hàng theo các tiêu chí, mới nhất trước
+func (r *inMemoryOrderRepository) ListOrders(_ context.Context, customerID string, status domain.OrderStatus, offset, limit int) ([]*domain.Order, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*domain.Order, 0)
+	for _, order := range r.orders {
+		if customerID != "" && order.CustomerID != customerID {
+			continue
+		}
+		if status != "" && order.Status != status {
+			continue
+		}
+		matched = append(matched, order)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	total := len(matched)
+	if offset > total {
+		offset = total
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	orders := make([]*domain.Order, len(matched))
+	for i, order := range matched {
+		orders[i] = cloneOrder(order)
+	}
+	return orders, total, nil
+}
+
+// StreamOrders duyệt các đơn hàng theo bộ lọc, mới nhất trước
+func (r *inMemoryOrderRepository) StreamOrders(ctx context.Context, customerID string, status domain.OrderStatus, fn func(order *domain.Order) error) error {
+	orders, _, err := r.ListOrders(ctx, customerID, status, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListOverdue lấy ID các đơn hàng quá thời gian cam kết chưa được ghi nhận vi phạm SLA, hạn sớm nhất trước
+func (r *inMemoryOrderRepository) ListOverdue(_ context.Context, at time.Time, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	overdue := make([]*domain.Order, 0)
+	for _, order := range r.orders {
+		if !order.SLABreached && order.IsOverdue(at) {
+			overdue = append(overdue, order)
+		}
+	}
+
+	sort.Slice(overdue, func(i, j int) bool {
+		return overdue[i].PromisedDelivery.Before(*overdue[j].PromisedDelivery)
+	})
+	if limit > 0 && limit < len(overdue) {
+		overdue = overdue[:limit]
+	}
+
+	ids := make([]string, len(overdue))
+	for i, order := range overdue {
+		ids[i] = order.ID
+	}
+	return ids, nil
+}
+
+// ListNear lấy các đơn hàng trong bán kính radiusKm quanh center, gần nhất trước
+func (r *inMemoryOrderRepository) ListNear(_ context.Context, center geo.Point, radiusKm float64, status domain.OrderStatus, limit int) ([]NearbyOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nearby := make([]NearbyOrder, 0)
+	for _, order := range r.orders {
+		if status != "" && order.Status != status {
+			continue
+		}
+		position := order.Position()
+		if !position.HasCoordinates() {
+			continue
+		}
+		if distance := geo.DistanceKm(center, position.Point()); distance <= radiusKm {
+			nearby = append(nearby, NearbyOrder{Order: cloneOrder(order), DistanceKm: distance})
+		}
+	}
+
+	sort.Slice(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+	if limit > 0 && limit < len(nearby) {
+		nearby = nearby[:limit]
+	}
+	return nearby, nil
+}
+
+// ListByDriver lấy các đơn hàng đang được phân công cho tài xế, cũ nhất trước
+func (r *inMemoryOrderRepository) ListByDriver(_ context.Context, driverID string, status domain.OrderStatus) ([]*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := make([]*domain.Order, 0)
+	for _, order := range r.orders {
+		if order.DriverID != driverID {
+			continue
+		}
+		if status != "" && order.Status != status {
+			continue
+		}
+		orders = append(orders, cloneOrder(order))
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].AssignedAt.Equal(*orders[j].AssignedAt) {
+			return orders[i].AssignedAt.Before(*orders[j].AssignedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders, nil
+}
+
+// Save ghi đè read model của đơn hàng
+func (r *inMemoryOrderRepository) Save(_ context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.orders[order.ID] = cloneOrder(order)
+	return nil
+}
+
+// HandleEvent áp dụng sự kiện lên read model giống repository dùng cơ sở dữ liệu
+func (r *inMemoryOrderRepository) HandleEvent(event domain.Event) error {
+	descriptor, ok := domain.LookupEvent(event.GetType())
+	if !ok {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := cloneOrder(r.orders[event.GetAggregateID()])
+	order := descriptor.Apply(current, event)
+	if order == nil {
+		return nil
+	}
+
+	r.orders[order.ID] = cloneOrder(order)
+	return nil
+}
+
+// cloneOrder sao chép đơn hàng để người gọi không sửa trực tiếp dữ liệu trong repository
+func cloneOrder(order *domain.Order) *domain.Order {
+	if order == nil {
+		return nil
+	}
+
+	clone := *order
+	clone.Events = nil
+	if order.CurrentLocation != nil {
+		location := *order.CurrentLocation
+		clone.CurrentLocation = &location
+	}
+	if order.Payment != nil {
+		payment := *order.Payment
+		clone.Payment = &payment
+	}
+	clone.Items = append([]domain.OrderItem(nil), order.Items...)
+	clone.Notes = append([]string{}, order.Notes...)
+	return &clone
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/pkgs/geo"
+)
+
+func TestInMemoryOrderRepositoryHandlesEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryOrderRepository()
+	createdAt := time.Date(2025, 3, 16, 8, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"order-1", "order-2"} {
+		err := repo.HandleEvent(domain.OrderCreatedEvent{
+			BaseEvent: domain.BaseEvent{
+				ID:          id + "-created",
+				AggregateID: id,
+				Type:        domain.OrderCreatedType,
+				Timestamp:   createdAt.Add(time.Duration(i) * time.Hour),
+				Version:     1,
+			},
+			CustomerID:     "CUS-001",
+			TrackingNumber: "TRK-" + id,
+			Items:          []domain.OrderItem{{ID: "ITEM-1", Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("HandleEvent OrderCreated: %v", err)
+		}
+	}
+
+	err := repo.HandleEvent(domain.OrderStatusUpdatedEvent{
+		BaseEvent: domain.BaseEvent{
+			ID:          "order-1-updated",
+			AggregateID: "order-1",
+			Type:        domain.OrderStatusUpdatedType,
+			Timestamp:   createdAt.Add(2 * time.Hour),
+			Version:     1,
+		},
+		OldStatus: domain.OrderStatusCreated,
+		NewStatus: domain.OrderStatusInTransit,
+		Note:      "Đã rời kho",
+	})
+	if err != nil {
+		t.Fatalf("HandleEvent OrderStatusUpdated: %v", err)
+	}
+
+	// Sự kiện của đơn hàng chưa tồn tại bị bỏ qua
+	if err := repo.HandleEvent(domain.NewOrderNoteAddedEvent(domain.BaseEvent{ID: "missing-note", AggregateID: "missing"}, "ghi chú")); err != nil {
+		t.Fatalf("HandleEvent đơn hàng chưa tồn tại: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); err == nil {
+		t.Fatal("đơn hàng chưa tồn tại không được tạo từ sự kiện ghi chú")
+	}
+
+	order, err := repo.GetByTrackingNumber(ctx, "TRK-order-1")
+	if err != nil {
+		t.Fatalf("GetByTrackingNumber: %v", err)
+	}
+	if order.Status != domain.OrderStatusInTransit || len(order.Notes) != 1 {
+		t.Fatalf("order-1 = %+v", order)
+	}
+
+	// Sửa bản trả về không làm thay đổi dữ liệu trong repository
+	order.Notes[0] = "đã sửa"
+	if stored, _ := repo.GetByID(ctx, "order-1"); stored.Notes[0] != "Đã rời kho" {
+		t.Fatal("repository bị sửa qua bản sao trả về")
+	}
+
+	ids, err := repo.ResolveTrackingNumbers(ctx, []string{"TRK-order-2", "TRK-missing"})
+	if err != nil {
+		t.Fatalf("ResolveTrackingNumbers: %v", err)
+	}
+	if len(ids) != 1 || ids["TRK-order-2"] != "order-2" {
+		t.Fatalf("ResolveTrackingNumbers = %v", ids)
+	}
+
+	orders, total, err := repo.ListOrders(ctx, "CUS-001", "", 0, 1)
+	if err != nil {
+		t.Fatalf("ListOrders: %v", err)
+	}
+	if total != 2 || len(orders) != 1 || orders[0].ID != "order-2" {
+		t.Fatalf("ListOrders = %d đơn, tổng %d", len(orders), total)
+	}
+
+	orders, total, err = repo.ListOrders(ctx, "", domain.OrderStatusInTransit, 0, 10)
+	if err != nil {
+		t.Fatalf("ListOrders theo trạng thái: %v", err)
+	}
+	if total != 1 || orders[0].ID != "order-1" {
+		t.Fatalf("ListOrders theo trạng thái = %+v", orders)
+	}
+}
+
+func TestInMemoryOrderRepositoryListNear(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryOrderRepository()
+	hoanKiem := geo.Point{Latitude: 21.0285, Longitude: 105.8542}
+
+	orders := map[string]domain.Location{
+		"order-hanoi":   {City: "Hà Nội", Latitude: 21.0245, Longitude: 105.8412},
+		"order-caugiay": {City: "Hà Nội", Latitude: 21.0362, Longitude: 105.7906},
+		"order-hcm":     {City: "Hồ Chí Minh", Latitude: 10.7769, Longitude: 106.7009},
+		"order-unknown": {City: "Hà Nội"},
+	}
+	for id, origin := range orders {
+		err := repo.Save(ctx, &domain.Order{ID: id, Status: domain.OrderStatusCreated, Origin: origin})
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	nearby, err := repo.ListNear(ctx, hoanKiem, 10, "", 10)
+	if err != nil {
+		t.Fatalf("ListNear: %v", err)
+	}
+	if len(nearby) != 2 || nearby[0].Order.ID != "order-hanoi" || nearby[1].Order.ID != "order-caugiay" {
+		t.Fatalf("ListNear = %+v", nearby)
+	}
+	if nearby[0].DistanceKm > nearby[1].DistanceKm || nearby[1].DistanceKm > 10 {
+		t.Fatalf("khoảng cách = %v, %v", nearby[0].DistanceKm, nearby[1].DistanceKm)
+	}
+
+	if nearby, _ := repo.ListNear(ctx, hoanKiem, 10, domain.OrderStatusInTransit, 10); len(nearby) != 0 {
+		t.Fatalf("ListNear theo trạng thái = %+v", nearby)
+	}
+	if nearby, _ := repo.ListNear(ctx, hoanKiem, 10, "", 1); len(nearby) != 1 {
+		t.Fatalf("ListNear với limit 1 = %+v", nearby)
+	}
+}
@@ -2,14 +2,26 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/quyenle-97/init/internal/domain"
 	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/pkgs/geo"
+	"github.com/shopspring/decimal"
 	"github.com/uptrace/bun"
+	"math"
+	"sort"
+	"time"
 )
 
+// NearbyOrder là đơn hàng kèm khoảng cách từ vị trí hiện tại của nó tới điểm tìm kiếm
+type NearbyOrder struct {
+	Order      *domain.Order
+	DistanceKm float64
+}
+
 type OrderRepository interface {
 	// GetByID lấy đơn hàng theo ID
 	GetByID(ctx context.Context, id string) (*domain.Order, error)
@@ -17,14 +29,29 @@ type OrderRepository interface {
 	// GetByTrackingNumber lấy đơn hàng theo số theo dõi
 	GetByTrackingNumber(ctx context.Context, trackingNumber string) (*domain.Order, error)
 
+	// ResolveTrackingNumbers tìm ID đơn hàng của nhiều số theo dõi trong một lần truy vấn, số không tồn tại bị bỏ qua
+	ResolveTrackingNumbers(ctx context.Context, trackingNumbers []string) (map[string]string, error)
+
 	// ListOrders lấy danh sách đơn hàng
 	ListOrders(ctx context.Context, customerID string, status domain.OrderStatus, offset, limit int) ([]*domain.Order, int, error)
 
-	//// Save lưu đơn hàng mới
-	//Save(ctx context.Context, order *models.OrderModel) error
+	// StreamOrders duyệt lần lượt các đơn hàng theo bộ lọc của ListOrders, mới nhất trước,
+	// không tải tất cả vào bộ nhớ. fn trả về lỗi thì dừng duyệt.
+	StreamOrders(ctx context.Context, customerID string, status domain.OrderStatus, fn func(order *domain.Order) error) error
+
+	// ListOverdue lấy ID các đơn hàng chưa hoàn thành đã quá thời gian cam kết tại thời điểm at
+	// mà chưa được ghi nhận vi phạm SLA, hạn sớm nhất trước
+	ListOverdue(ctx context.Context, at time.Time, limit int) ([]string, error)
+
+	// ListNear lấy các đơn hàng có vị trí hiện tại nằm trong bán kính radiusKm quanh center,
+	// lọc theo trạng thái nếu có, gần nhất trước
+	ListNear(ctx context.Context, center geo.Point, radiusKm float64, status domain.OrderStatus, limit int) ([]NearbyOrder, error)
 
-	//// Update cập nhật đơn hàng
-	//Update(ctx context.Context, order *models.OrderModel) error
+	// ListByDriver lấy các đơn hàng đang được phân công cho tài xế, lọc theo trạng thái nếu có, cũ nhất trước
+	ListByDriver(ctx context.Context, driverID string, status domain.OrderStatus) ([]*domain.Order, error)
+
+	// Save ghi đè read model của đơn hàng, dùng khi xây dựng lại projection từ sự kiện
+	Save(ctx context.Context, order *domain.Order) error
 
 	HandleEvent(event domain.Event) error
 }
@@ -76,6 +103,32 @@ func (r *orderRepository) GetByTrackingNumber(ctx context.Context, trackingNumbe
 	return r.modelToDomain(model)
 }
 
+// ResolveTrackingNumbers trả về ánh xạ số theo dõi sang ID đơn hàng
+func (r *orderRepository) ResolveTrackingNumbers(ctx context.Context, trackingNumbers []string) (map[string]string, error) {
+	ids := make(map[string]string, len(trackingNumbers))
+	if len(trackingNumbers) == 0 {
+		return ids, nil
+	}
+
+	var rows []struct {
+		ID             string `bun:"id"`
+		TrackingNumber string `bun:"tracking_number"`
+	}
+	err := r.db.NewSelect().
+		Model((*models.OrderModel)(nil)).
+		Column("id", "tracking_number").
+		Where("tracking_number IN (?)", bun.In(trackingNumbers)).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn đơn hàng theo số theo dõi: %w", err)
+	}
+
+	for _, row := range rows {
+		ids[row.TrackingNumber] = row.ID
+	}
+	return ids, nil
+}
+
 // ListOrders lấy danh sách đơn hàng theo các tiêu chí
 func (r *orderRepository) ListOrders(ctx context.Context, customerID string, status domain.OrderStatus, offset, limit int) ([]*domain.Order, int, error) {
 	query := r.db.NewSelect().
@@ -122,220 +175,311 @@ func (r *orderRepository) ListOrders(ctx context.Context, customerID string, sta
 	return orders, count, nil
 }
 
-// HandleEvent xử lý các sự kiện để cập nhật read model
-func (r *orderRepository) HandleEvent(event domain.Event) error {
-	ctx := context.Background()
-	switch e := event.(type) {
-	case domain.OrderCreatedEvent:
-		return r.handleOrderCreated(ctx, e)
-	case domain.OrderStatusUpdatedEvent:
-		return r.handleOrderStatusUpdated(ctx, e)
-	case domain.OrderCancelledEvent:
-		return r.handleOrderCancelled(ctx, e)
-	case domain.OrderNoteAddedEvent:
-		return r.handleOrderNoteAdded(ctx, e)
-	default:
+// streamPageSize là số đơn hàng đọc trong mỗi truy vấn của StreamOrders
+const streamPageSize = 500
+
+// StreamOrders đọc bảng orders theo từng trang bằng keyset (created_at, id).
+// Kết nối được trả lại giữa các trang nên fn có thể truy vấn cơ sở dữ liệu khác.
+func (r *orderRepository) StreamOrders(ctx context.Context, customerID string, status domain.OrderStatus, fn func(order *domain.Order) error) error {
+	var last *models.OrderModel
+	for {
+		query := r.db.NewSelect().
+			Model((*models.OrderModel)(nil))
 
-		fmt.Println("12312312312", e)
+		if customerID != "" {
+			query = query.Where("customer_id = ?", customerID)
+		}
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if last != nil {
+			query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", last.CreatedAt, last.CreatedAt, last.ID)
+		}
+
+		var page []*models.OrderModel
+		err := query.
+			Model(&page).
+			Order("created_at DESC", "id DESC").
+			Limit(streamPageSize).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi truy vấn danh sách đơn hàng: %w", err)
+		}
 
-		return nil // Bỏ qua các sự kiện không quan tâm
+		for _, model := range page {
+			order, err := r.modelToDomain(model)
+			if err != nil {
+				return err
+			}
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < streamPageSize {
+			return nil
+		}
+		last = page[len(page)-1]
 	}
 }
 
-// handleOrderCreated xử lý sự kiện tạo đơn hàng
-func (r *orderRepository) handleOrderCreated(ctx context.Context, event domain.OrderCreatedEvent) error {
-	fmt.Println(333333)
-	fmt.Println(event)
-	// Serialize dữ liệu
-	originData, err := json.Marshal(event.Origin)
+// ListOverdue lấy ID các đơn hàng quá thời gian cam kết chưa được ghi nhận vi phạm SLA
+func (r *orderRepository) ListOverdue(ctx context.Context, at time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.NewSelect().
+		Model((*models.OrderModel)(nil)).
+		Column("id").
+		Where("promised_delivery < ?", at).
+		Where("sla_breached = ?", false).
+		Where("status NOT IN (?)", bun.In([]domain.OrderStatus{domain.OrderStatusDelivered, domain.OrderStatusCancelled})).
+		Order("promised_delivery ASC").
+		Limit(limit).
+		Scan(ctx, &ids)
 	if err != nil {
-		return fmt.Errorf("lỗi khi serialize origin: %w", err)
+		return nil, fmt.Errorf("lỗi khi truy vấn đơn hàng quá hạn: %w", err)
 	}
 
-	destData, err := json.Marshal(event.Destination)
-	if err != nil {
-		return fmt.Errorf("lỗi khi serialize destination: %w", err)
-	}
+	return ids, nil
+}
 
-	itemsData, err := json.Marshal(event.Items)
-	if err != nil {
-		return fmt.Errorf("lỗi khi serialize items: %w", err)
-	}
+// ListNear lọc đơn hàng trong khung bao quanh hình tròn bằng index idx_orders_position,
+// sắp xếp theo khoảng cách xấp xỉ trên mặt phẳng rồi tính lại khoảng cách chính xác
+func (r *orderRepository) ListNear(ctx context.Context, center geo.Point, radiusKm float64, status domain.OrderStatus, limit int) ([]NearbyOrder, error) {
+	min, max := geo.BoundingBox(center, radiusKm)
+	// Độ dài một độ kinh tuyến giảm theo cos của vĩ độ
+	scale := math.Cos(center.Latitude * math.Pi / 180)
 
-	notesData, err := json.Marshal([]string{})
-	if err != nil {
-		return fmt.Errorf("lỗi khi serialize notes: %w", err)
+	var rows []models.OrderModel
+	query := r.db.NewSelect().
+		Model(&rows).
+		Where("position_latitude BETWEEN ? AND ?", min.Latitude, max.Latitude).
+		Where("position_longitude BETWEEN ? AND ?", min.Longitude, max.Longitude).
+		OrderExpr("(position_latitude - ?) * (position_latitude - ?) + (position_longitude - ?) * (position_longitude - ?) * ? ASC",
+			center.Latitude, center.Latitude, center.Longitude, center.Longitude, scale*scale).
+		Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
 	}
 
-	// Tạo model đơn hàng mới
-	model := models.OrderModel{
-		ID:              event.AggregateID,
-		CustomerID:      event.CustomerID,
-		TrackingNumber:  event.TrackingNumber,
-		Status:          domain.OrderStatusCreated,
-		OriginData:      originData,
-		DestinationData: destData,
-		ItemsData:       itemsData,
-		NotesData:       notesData,
-		CreatedAt:       event.Timestamp,
-		UpdatedAt:       event.Timestamp,
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn đơn hàng lân cận: %w", err)
 	}
 
-	// Lưu vào cơ sở dữ liệu
-	_, err = r.db.NewInsert().
-		Model(&model).
-		Exec(ctx)
-
-	if err != nil {
-		return fmt.Errorf("lỗi khi lưu đơn hàng mới: %w", err)
+	nearby := make([]NearbyOrder, 0, len(rows))
+	for i := range rows {
+		order, err := r.modelToDomain(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		distance := geo.DistanceKm(center, order.Position().Point())
+		if distance > radiusKm {
+			continue
+		}
+		nearby = append(nearby, NearbyOrder{Order: order, DistanceKm: distance})
 	}
 
-	return nil
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+	return nearby, nil
 }
 
-// handleOrderStatusUpdated xử lý sự kiện cập nhật trạng thái đơn hàng
-func (r *orderRepository) handleOrderStatusUpdated(ctx context.Context, event domain.OrderStatusUpdatedEvent) error {
-	// Lấy thông tin đơn hàng hiện tại
-	var model models.OrderModel
-	err := r.db.NewSelect().
-		Model(&model).
-		Where("id = ?", event.AggregateID).
-		Scan(ctx)
-
-	if err != nil {
-		return fmt.Errorf("lỗi khi tìm đơn hàng: %w", err)
+// ListByDriver lấy các đơn hàng của tài xế bằng index idx_orders_driver_id
+func (r *orderRepository) ListByDriver(ctx context.Context, driverID string, status domain.OrderStatus) ([]*domain.Order, error) {
+	var rows []*models.OrderModel
+	query := r.db.NewSelect().
+		Model(&rows).
+		Where("driver_id = ?", driverID).
+		Order("assigned_at ASC", "id ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
 	}
 
-	// Cập nhật trạng thái
-	model.Status = event.NewStatus
-	model.UpdatedAt = event.Timestamp
-
-	// Cập nhật vị trí hiện tại nếu có
-	if event.CurrentLocation != nil {
-		currentLocData, err := json.Marshal(event.CurrentLocation)
-		if err != nil {
-			return fmt.Errorf("lỗi khi serialize current location: %w", err)
-		}
-		model.CurrentLocData = currentLocData
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("lỗi khi truy vấn đơn hàng của tài xế: %w", err)
 	}
 
-	// Cập nhật ghi chú nếu có
-	if event.Note != "" {
-		var notes []string
-		err = json.Unmarshal(model.NotesData, &notes)
-		if err != nil {
-			return fmt.Errorf("lỗi khi deserialize notes: %w", err)
-		}
-
-		notes = append(notes, event.Note)
-		notesData, err := json.Marshal(notes)
+	orders := make([]*domain.Order, len(rows))
+	for i, model := range rows {
+		order, err := r.modelToDomain(model)
 		if err != nil {
-			return fmt.Errorf("lỗi khi serialize notes: %w", err)
+			return nil, err
 		}
+		orders[i] = order
+	}
+	return orders, nil
+}
 
-		model.NotesData = notesData
+// Save ghi đè read model của đơn hàng, tạo mới nếu chưa tồn tại
+func (r *orderRepository) Save(ctx context.Context, order *domain.Order) error {
+	model, err := r.domainToModel(order)
+	if err != nil {
+		return err
 	}
 
-	// Lưu cập nhật vào cơ sở dữ liệu
-	_, err = r.db.NewUpdate().
-		Model(&model).
-		WherePK().
+	_, err = r.db.NewInsert().
+		Model(model).
+		On("CONFLICT (id) DO UPDATE").
 		Exec(ctx)
-
 	if err != nil {
-		return fmt.Errorf("lỗi khi cập nhật đơn hàng: %w", err)
+		return fmt.Errorf("lỗi khi lưu đơn hàng: %w", err)
 	}
 
 	return nil
 }
 
-// handleOrderCancelled xử lý sự kiện hủy đơn hàng
-func (r *orderRepository) handleOrderCancelled(ctx context.Context, event domain.OrderCancelledEvent) error {
-	// Lấy thông tin đơn hàng hiện tại
-	var model models.OrderModel
-	err := r.db.NewSelect().
-		Model(&model).
-		Where("id = ?", event.AggregateID).
-		Scan(ctx)
+// HandleEvent xử lý các sự kiện để cập nhật read model
+func (r *orderRepository) HandleEvent(event domain.Event) error {
+	ctx := context.Background()
+
+	// Bỏ qua các sự kiện không được đăng ký trong domain
+	descriptor, ok := domain.LookupEvent(event.GetType())
+	if !ok {
+		return nil
+	}
 
+	// Lấy thông tin đơn hàng hiện tại trong read model nếu có
+	current, err := r.findByID(ctx, event.GetAggregateID())
 	if err != nil {
-		return fmt.Errorf("lỗi khi tìm đơn hàng: %w", err)
+		return err
 	}
 
-	// Cập nhật trạng thái
-	model.Status = domain.OrderStatusCancelled
-	model.UpdatedAt = event.Timestamp
+	// Áp dụng sự kiện giống như khi xây dựng lại aggregate
+	order := descriptor.Apply(current, event)
+	if order == nil {
+		return nil
+	}
 
-	// Cập nhật ghi chú nếu có
-	if event.Reason != "" {
-		var notes []string
-		err = json.Unmarshal(model.NotesData, &notes)
-		if err != nil {
-			return fmt.Errorf("lỗi khi deserialize notes: %w", err)
-		}
+	model, err := r.domainToModel(order)
+	if err != nil {
+		return err
+	}
 
-		notes = append(notes, event.Reason)
-		notesData, err := json.Marshal(notes)
+	if current == nil {
+		_, err = r.db.NewInsert().
+			Model(model).
+			Exec(ctx)
 		if err != nil {
-			return fmt.Errorf("lỗi khi serialize notes: %w", err)
+			return fmt.Errorf("lỗi khi lưu đơn hàng mới: %w", err)
 		}
-
-		model.NotesData = notesData
+		return nil
 	}
 
-	// Lưu cập nhật vào cơ sở dữ liệu
 	_, err = r.db.NewUpdate().
-		Model(&model).
+		Model(model).
 		WherePK().
 		Exec(ctx)
-
 	if err != nil {
-		return fmt.Errorf("lỗi khi cập nhật đơn hàng hủy: %w", err)
+		return fmt.Errorf("lỗi khi cập nhật đơn hàng: %w", err)
 	}
 
 	return nil
 }
 
-// handleOrderNoteAdded xử lý sự kiện thêm ghi chú
-func (r *orderRepository) handleOrderNoteAdded(ctx context.Context, event domain.OrderNoteAddedEvent) error {
-	// Lấy thông tin đơn hàng hiện tại
-	var model models.OrderModel
+// findByID lấy đơn hàng trong read model, trả về nil nếu chưa tồn tại
+func (r *orderRepository) findByID(ctx context.Context, id string) (*domain.Order, error) {
+	model := &models.OrderModel{}
 	err := r.db.NewSelect().
-		Model(&model).
-		Where("id = ?", event.AggregateID).
+		Model(model).
+		Where("id = ?", id).
 		Scan(ctx)
 
 	if err != nil {
-		return fmt.Errorf("lỗi khi tìm đơn hàng: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lỗi khi tìm đơn hàng: %w", err)
 	}
 
-	// Cập nhật ghi chú
-	var notes []string
-	err = json.Unmarshal(model.NotesData, &notes)
+	return r.modelToDomain(model)
+}
+
+// domainToModel chuyển đổi domain thành model
+func (r *orderRepository) domainToModel(order *domain.Order) (*models.OrderModel, error) {
+	originData, err := json.Marshal(order.Origin)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi serialize origin: %w", err)
+	}
+
+	destData, err := json.Marshal(order.Destination)
 	if err != nil {
-		return fmt.Errorf("lỗi khi deserialize notes: %w", err)
+		return nil, fmt.Errorf("lỗi khi serialize destination: %w", err)
 	}
 
-	notes = append(notes, event.Note)
+	itemsData, err := json.Marshal(order.Items)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi serialize items: %w", err)
+	}
+
+	notes := order.Notes
+	if notes == nil {
+		notes = []string{}
+	}
 	notesData, err := json.Marshal(notes)
 	if err != nil {
-		return fmt.Errorf("lỗi khi serialize notes: %w", err)
+		return nil, fmt.Errorf("lỗi khi serialize notes: %w", err)
 	}
 
-	model.NotesData = notesData
-	model.UpdatedAt = event.Timestamp
+	var currentLocData []byte
+	if order.CurrentLocation != nil {
+		currentLocData, err = json.Marshal(order.CurrentLocation)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi serialize current location: %w", err)
+		}
+	}
 
-	// Lưu cập nhật vào cơ sở dữ liệu
-	_, err = r.db.NewUpdate().
-		Model(&model).
-		WherePK().
-		Exec(ctx)
+	var positionLatitude, positionLongitude *float64
+	if position := order.Position(); position.HasCoordinates() {
+		positionLatitude, positionLongitude = &position.Latitude, &position.Longitude
+	}
 
-	if err != nil {
-		return fmt.Errorf("lỗi khi cập nhật ghi chú đơn hàng: %w", err)
+	model := &models.OrderModel{
+		ID:              order.ID,
+		CustomerID:      order.CustomerID,
+		TrackingNumber:  order.TrackingNumber,
+		Status:          order.Status,
+		OriginData:      originData,
+		DestinationData: destData,
+		CurrentLocData:  currentLocData,
+		ItemsData:       itemsData,
+		NotesData:       notesData,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+
+		ServiceLevel:      order.ServiceLevel,
+		EstimatedDelivery: order.EstimatedDelivery,
+		PromisedDelivery:  order.PromisedDelivery,
+		SLABreached:       order.SLABreached,
+
+		PositionLatitude:  positionLatitude,
+		PositionLongitude: positionLongitude,
+
+		Currency:      order.Currency,
+		Subtotal:      order.Totals.Subtotal,
+		TotalWeight:   order.Totals.TotalWeight,
+		DeclaredValue: order.Totals.DeclaredValue,
+		ShippingFee:   order.ShippingFee,
+
+		DriverID:   order.DriverID,
+		VehicleID:  order.VehicleID,
+		AssignedAt: order.AssignedAt,
+
+		CurrentHub: order.CurrentHub,
 	}
 
-	return nil
+	if payment := order.Payment; payment != nil {
+		amount, collected := payment.Amount, payment.Collected
+		model.PaymentMethod = payment.Method
+		model.PaymentStatus = payment.Status
+		model.CODAmount = &amount
+		model.CODCollected = &collected
+		model.CODDriverID = payment.DriverID
+		model.CODCollectedAt = payment.CollectedAt
+		model.CODRemittedAt = payment.RemittedAt
+		model.CODRefundedAt = payment.RefundedAt
+	}
+
+	return model, nil
 }
 
 // modelToDomain chuyển đổi model thành domain
@@ -385,7 +529,44 @@ func (r *orderRepository) modelToDomain(model *models.OrderModel) (*domain.Order
 		Notes:           notes,
 		CreatedAt:       model.CreatedAt,
 		UpdatedAt:       model.UpdatedAt,
+
+		ServiceLevel:      model.ServiceLevel,
+		EstimatedDelivery: model.EstimatedDelivery,
+		PromisedDelivery:  model.PromisedDelivery,
+		SLABreached:       model.SLABreached,
+
+		Currency:    model.Currency,
+		Totals:      domain.CalculateTotals(items),
+		ShippingFee: model.ShippingFee,
+
+		DriverID:   model.DriverID,
+		VehicleID:  model.VehicleID,
+		AssignedAt: model.AssignedAt,
+
+		CurrentHub: model.CurrentHub,
+	}
+
+	if model.PaymentMethod != "" {
+		order.Payment = &domain.Payment{
+			Method:      model.PaymentMethod,
+			Status:      model.PaymentStatus,
+			Amount:      decimalOrZero(model.CODAmount),
+			Currency:    model.Currency,
+			Collected:   decimalOrZero(model.CODCollected),
+			DriverID:    model.CODDriverID,
+			CollectedAt: model.CODCollectedAt,
+			RemittedAt:  model.CODRemittedAt,
+			RefundedAt:  model.CODRefundedAt,
+		}
 	}
 
 	return order, nil
 }
+
+// decimalOrZero trả về giá trị của cột số tiền có thể NULL, 0 khi NULL
+func decimalOrZero(value *decimal.Decimal) decimal.Decimal {
+	if value == nil {
+		return decimal.Zero
+	}
+	return *value
+}
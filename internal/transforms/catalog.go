@@ -0,0 +1,56 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// DefaultCatalogLimit là số bản ghi mặc định của các API danh sách khi không truyền limit
+const DefaultCatalogLimit = 20
+
+// SearchRequest là payload tìm kiếm theo tên của các API danh sách
+type SearchRequest struct {
+	Search string `json:"search"`
+	Offset int    `json:"offset" validate:"gte=0"`
+	Limit  int    `json:"limit" validate:"gte=0,lte=100"`
+}
+
+// RecordRequest chỉ định bản ghi theo uid trên đường dẫn
+type RecordRequest struct {
+	ID string
+}
+
+// DecodeSearchRequest xử lý việc giải mã payload tìm kiếm, body rỗng được coi là không lọc
+func DecodeSearchRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req SearchRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		if req.Limit == 0 {
+			req.Limit = DefaultCatalogLimit
+		}
+		return req, nil
+	}
+}
+
+// DecodeRecordRequest xử lý việc giải mã uid của bản ghi
+func DecodeRecordRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return RecordRequest{ID: mux.Vars(r)["uid"]}, nil
+}
+
+// decodeCatalogBody giải mã và validate body JSON, lỗi giải mã trả về 400, lỗi validate trả về 422
+func decodeCatalogBody(r *http.Request, v interface{}, validate *validator.Validate) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return utils.Message{Code: http.StatusBadRequest, Message: "không thể decode request: " + err.Error()}
+	}
+	return validate.Struct(v)
+}
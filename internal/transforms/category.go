@@ -0,0 +1,46 @@
+package transforms
+
+import (
+	"context"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/models"
+)
+
+// CreateCategoryRequest tạo danh mục mới
+type CreateCategoryRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// UpdateCategoryRequest cập nhật danh mục, trường bỏ trống giữ nguyên
+type UpdateCategoryRequest struct {
+	ID     string              `json:"-"`
+	Name   *string             `json:"name" validate:"omitempty,min=1,max=255"`
+	Status *models.ModelStatus `json:"status" validate:"omitempty,oneof=1 2"`
+}
+
+// DecodeCreateCategoryRequest xử lý việc giải mã request tạo danh mục
+func DecodeCreateCategoryRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req CreateCategoryRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+}
+
+// DecodeUpdateCategoryRequest xử lý việc giải mã request cập nhật danh mục
+func DecodeUpdateCategoryRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req UpdateCategoryRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		req.ID = mux.Vars(r)["uid"]
+		return req, nil
+	}
+}
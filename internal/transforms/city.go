@@ -0,0 +1,48 @@
+package transforms
+
+import (
+	"context"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// CreateCityRequest tạo thành phố mới
+type CreateCityRequest struct {
+	Name      string   `json:"name" validate:"required,max=255"`
+	Latitude  *float64 `json:"latitude" validate:"required,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required,gte=-180,lte=180"`
+}
+
+// UpdateCityRequest cập nhật thành phố, trường bỏ trống giữ nguyên
+type UpdateCityRequest struct {
+	ID        string   `json:"-"`
+	Name      *string  `json:"name" validate:"omitempty,min=1,max=255"`
+	Latitude  *float64 `json:"latitude" validate:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"omitempty,gte=-180,lte=180"`
+}
+
+// DecodeCreateCityRequest xử lý việc giải mã request tạo thành phố
+func DecodeCreateCityRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req CreateCityRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+}
+
+// DecodeUpdateCityRequest xử lý việc giải mã request cập nhật thành phố
+func DecodeUpdateCityRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req UpdateCityRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		req.ID = mux.Vars(r)["uid"]
+		return req, nil
+	}
+}
@@ -0,0 +1,192 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// RegisterDriverRequest đăng ký tài xế mới
+type RegisterDriverRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// RegisterVehicleRequest đăng ký phương tiện mới
+type RegisterVehicleRequest struct {
+	PlateNumber string          `json:"plate_number" validate:"required"`
+	CapacityKg  decimal.Decimal `json:"capacity_kg"`
+}
+
+// FleetIDRequest là request chỉ có ID tài xế hoặc phương tiện trên đường dẫn
+type FleetIDRequest struct {
+	ID string
+}
+
+// AssignVehicleRequest gán phương tiện cho tài xế
+type AssignVehicleRequest struct {
+	DriverID  string `json:"driver_id" validate:"required"`
+	VehicleID string `json:"vehicle_id" validate:"required"`
+}
+
+// FleetReasonRequest ngừng hoạt động tài xế, ngừng sử dụng phương tiện hoặc hủy phân công kèm lý do
+type FleetReasonRequest struct {
+	ID     string `json:"id" validate:"required"`
+	Reason string `json:"reason"`
+}
+
+// AssignOrderRequest phân công đơn hàng cho tài xế
+type AssignOrderRequest struct {
+	OrderID  string `json:"order_id" validate:"required"`
+	DriverID string `json:"driver_id" validate:"required"`
+}
+
+// DriverOrdersRequest lấy đơn hàng của tài xế, lọc theo trạng thái nếu có
+type DriverOrdersRequest struct {
+	DriverID string
+	Status   domain.OrderStatus
+}
+
+// FleetResponse là kết quả của các lệnh trên tài xế, phương tiện và phân công
+type FleetResponse struct {
+	ID      string `json:"id,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// DriverResponse là thông tin tài xế
+type DriverResponse struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Status    domain.DriverStatus `json:"status"`
+	VehicleID string              `json:"vehicle_id,omitempty"`
+	CreatedAt string              `json:"created_at"`
+	UpdatedAt string              `json:"updated_at"`
+}
+
+// VehicleResponse là thông tin phương tiện
+type VehicleResponse struct {
+	ID          string               `json:"id"`
+	PlateNumber string               `json:"plate_number"`
+	CapacityKg  decimal.Decimal      `json:"capacity_kg"`
+	Status      domain.VehicleStatus `json:"status"`
+	CreatedAt   string               `json:"created_at"`
+	UpdatedAt   string               `json:"updated_at"`
+}
+
+// DriverOrdersResponse là các đơn hàng của tài xế kèm tải trọng hiện tại của phương tiện
+type DriverOrdersResponse struct {
+	Driver     DriverResponse         `json:"driver"`
+	Vehicle    *VehicleResponse       `json:"vehicle,omitempty"`
+	LoadKg     decimal.Decimal        `json:"load_kg"`
+	CapacityKg *decimal.Decimal       `json:"capacity_kg,omitempty"`
+	Items      []OrderSummaryResponse `json:"items"`
+}
+
+// DecodeRegisterDriverRequest xử lý việc giải mã request đăng ký tài xế
+func DecodeRegisterDriverRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req RegisterDriverRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeRegisterVehicleRequest xử lý việc giải mã request đăng ký phương tiện
+func DecodeRegisterVehicleRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req RegisterVehicleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeFleetIDRequest xử lý việc giải mã request chỉ có ID trên đường dẫn
+func DecodeFleetIDRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+	return FleetIDRequest{ID: id}, nil
+}
+
+// DecodeAssignVehicleRequest xử lý việc giải mã request gán phương tiện cho tài xế
+func DecodeAssignVehicleRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req AssignVehicleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+		req.DriverID = mux.Vars(r)["id"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeFleetReasonRequest xử lý việc giải mã request kèm lý do, body có thể rỗng
+func DecodeFleetReasonRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req FleetReasonRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, fmt.Errorf("không thể decode request: %w", err)
+			}
+		}
+		req.ID = mux.Vars(r)["id"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeAssignOrderRequest xử lý việc giải mã request phân công đơn hàng
+func DecodeAssignOrderRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req AssignOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+		req.OrderID = mux.Vars(r)["id"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeDriverOrdersRequest xử lý việc giải mã request lấy đơn hàng của tài xế
+func DecodeDriverOrdersRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+	return DriverOrdersRequest{
+		DriverID: id,
+		Status:   domain.OrderStatus(strings.ToUpper(r.URL.Query().Get("status"))),
+	}, nil
+}
@@ -0,0 +1,154 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/models"
+)
+
+// CreateHubRequest tạo hub mới
+type CreateHubRequest struct {
+	Code              string  `json:"code" validate:"required"`
+	Name              string  `json:"name" validate:"required,max=255"`
+	Latitude          float64 `json:"latitude"`
+	Longitude         float64 `json:"longitude"`
+	OpensAt           string  `json:"opens_at"`  // HH:MM, bỏ trống cùng closes_at nếu hub hoạt động cả ngày
+	ClosesAt          string  `json:"closes_at"` // HH:MM
+	DwellLimitMinutes int     `json:"dwell_limit_minutes" validate:"gte=0"`
+}
+
+// UpdateHubRequest cập nhật hub, trường bỏ trống giữ nguyên
+type UpdateHubRequest struct {
+	Code              string              `json:"-"`
+	Name              *string             `json:"name" validate:"omitempty,max=255"`
+	Latitude          *float64            `json:"latitude"`
+	Longitude         *float64            `json:"longitude"`
+	OpensAt           *string             `json:"opens_at"`
+	ClosesAt          *string             `json:"closes_at"`
+	DwellLimitMinutes *int                `json:"dwell_limit_minutes" validate:"omitempty,gte=0"`
+	Status            *models.ModelStatus `json:"status"`
+}
+
+// HubCodeRequest là request chỉ có mã hub trên đường dẫn
+type HubCodeRequest struct {
+	Code string
+}
+
+// HubAlertsRequest lấy cảnh báo đơn hàng nằm quá lâu tại hub, HubCode rỗng để lấy trên mọi hub
+type HubAlertsRequest struct {
+	HubCode string
+}
+
+// HubScanRequest quét đơn hàng vào hoặc ra khỏi hub
+type HubScanRequest struct {
+	HubCode string `json:"-"`
+	OrderID string `json:"order_id" validate:"required"`
+}
+
+// HubCommandResponse là kết quả của các lệnh quét
+type HubCommandResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// HubResponse là thông tin hub
+type HubResponse struct {
+	Code              string             `json:"code"`
+	Name              string             `json:"name"`
+	Latitude          float64            `json:"latitude"`
+	Longitude         float64            `json:"longitude"`
+	OpensAt           string             `json:"opens_at,omitempty"`
+	ClosesAt          string             `json:"closes_at,omitempty"`
+	DwellLimitMinutes int                `json:"dwell_limit_minutes"` // giới hạn đang áp dụng, kể cả giới hạn mặc định
+	Status            models.ModelStatus `json:"status"`
+	CreatedAt         string             `json:"created_at"`
+	UpdatedAt         string             `json:"updated_at"`
+}
+
+// HubListResponse là danh sách hub
+type HubListResponse struct {
+	Items []HubResponse `json:"items"`
+}
+
+// HubStayResponse là đơn hàng đang nằm tại hub
+type HubStayResponse struct {
+	OrderID      string `json:"order_id"`
+	HubCode      string `json:"hub_code"`
+	ScannedInAt  string `json:"scanned_in_at"`
+	DwellMinutes int    `json:"dwell_minutes"`
+	LimitMinutes int    `json:"limit_minutes"`
+	Overdue      bool   `json:"overdue"`
+}
+
+// HubStaysResponse là danh sách đơn hàng đang nằm tại hub, nằm lâu nhất trước
+type HubStaysResponse struct {
+	Count int               `json:"count"`
+	Items []HubStayResponse `json:"items"`
+}
+
+// DecodeCreateHubRequest xử lý việc giải mã request tạo hub
+func DecodeCreateHubRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req CreateHubRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeUpdateHubRequest xử lý việc giải mã request cập nhật hub
+func DecodeUpdateHubRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req UpdateHubRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+		req.Code = mux.Vars(r)["code"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeHubCodeRequest xử lý việc giải mã request chỉ có mã hub trên đường dẫn
+func DecodeHubCodeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	code, ok := mux.Vars(r)["code"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số code")
+	}
+	return HubCodeRequest{Code: code}, nil
+}
+
+// DecodeHubAlertsRequest xử lý việc giải mã request lấy cảnh báo, lọc theo hub nếu có
+func DecodeHubAlertsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return HubAlertsRequest{HubCode: r.URL.Query().Get("hub")}, nil
+}
+
+// DecodeHubScanRequest xử lý việc giải mã request quét đơn hàng vào hoặc ra khỏi hub
+func DecodeHubScanRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req HubScanRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+		req.HubCode = mux.Vars(r)["code"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
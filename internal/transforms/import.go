@@ -0,0 +1,107 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxImportSize là kích thước tối đa của file nhập đơn hàng
+const maxImportSize = 32 << 20
+
+// StartImportRequest yêu cầu nhập đơn hàng từ file
+type StartImportRequest struct {
+	Format string
+	Data   []byte
+}
+
+// GetImportJobRequest truy vấn trạng thái job nhập đơn hàng
+type GetImportJobRequest struct {
+	JobID string
+}
+
+// ImportRowResultResponse là kết quả nhập một dòng
+type ImportRowResultResponse struct {
+	Line           int    `json:"line"`
+	Reference      string `json:"reference,omitempty"`
+	Status         string `json:"status"`
+	OrderID        string `json:"order_id,omitempty"`
+	TrackingNumber string `json:"tracking_number,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ImportJobResponse là view model của job nhập đơn hàng
+type ImportJobResponse struct {
+	JobID      string                    `json:"job_id"`
+	Format     string                    `json:"format"`
+	Status     string                    `json:"status"`
+	Total      int                       `json:"total"`
+	Processed  int                       `json:"processed"`
+	Succeeded  int                       `json:"succeeded"`
+	Failed     int                       `json:"failed"`
+	Error      string                    `json:"error,omitempty"`
+	CreatedAt  string                    `json:"created_at"`
+	FinishedAt string                    `json:"finished_at,omitempty"`
+	Results    []ImportRowResultResponse `json:"results,omitempty"`
+}
+
+// DecodeStartImportRequest đọc file nhập từ body hoặc từ trường file của form multipart.
+// Định dạng lấy từ tham số format, phần mở rộng của file hoặc Content-Type.
+func DecodeStartImportRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxImportSize)
+	format := r.URL.Query().Get("format")
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var body io.Reader = r.Body
+	if contentType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("thiếu trường file: %w", err)
+		}
+		defer file.Close()
+
+		body = file
+		if format == "" {
+			format = services.ImportFormatFromPath(header.Filename)
+		}
+	}
+	if format == "" {
+		format = importFormatFromContentType(contentType)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("không xác định được định dạng file, dùng tham số format=csv|jsonl")
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("không thể đọc file nhập: %w", err)
+	}
+
+	return StartImportRequest{Format: format, Data: data}, nil
+}
+
+// DecodeGetImportJobRequest xử lý việc giải mã request lấy trạng thái job
+func DecodeGetImportJobRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+
+	return GetImportJobRequest{JobID: id}, nil
+}
+
+func importFormatFromContentType(contentType string) string {
+	switch contentType {
+	case "text/csv", "application/csv":
+		return services.ImportFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return services.ImportFormatJSONL
+	default:
+		return ""
+	}
+}
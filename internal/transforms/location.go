@@ -0,0 +1,105 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// LocationPingRequest là một vị trí thiết bị của tài xế ghi nhận, recorded_at rỗng nghĩa là lúc máy chủ nhận
+type LocationPingRequest struct {
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+// RecordPingsRequest gửi một vị trí ở cấp ngoài cùng hoặc nhiều vị trí trong pings
+type RecordPingsRequest struct {
+	DriverID string
+	LocationPingRequest
+	Pings []LocationPingRequest `json:"pings"`
+}
+
+// LocationResponse là một vị trí kèm thời điểm ghi nhận
+type LocationResponse struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	RecordedAt string  `json:"recorded_at"`
+}
+
+// RecordPingsResponse là kết quả nhận vị trí của tài xế
+type RecordPingsResponse struct {
+	Received int               `json:"received"`
+	Accepted int               `json:"accepted"` // số vị trí còn lại sau khi lấy mẫu
+	Orders   int               `json:"orders"`   // số đơn hàng được cập nhật vị trí
+	Trails   int               `json:"trails"`   // số điểm hành trình được ghi vào luồng đơn hàng
+	Location *LocationResponse `json:"location,omitempty"`
+}
+
+// DriverLocationRequest lấy vị trí mới nhất của tài xế
+type DriverLocationRequest struct {
+	DriverID string
+}
+
+// DriverLocationResponse là vị trí mới nhất của tài xế
+type DriverLocationResponse struct {
+	DriverID string `json:"driver_id"`
+	LocationResponse
+	ReceivedAt string `json:"received_at"`
+}
+
+// OrderLocationRequest lấy vị trí hiện tại của đơn hàng
+type OrderLocationRequest struct {
+	OrderID string
+}
+
+// TrailPointResponse là điểm hành trình gần nhất đã ghi vào luồng đơn hàng
+type TrailPointResponse struct {
+	LocationResponse
+	Status domain.OrderStatus `json:"status"`
+}
+
+// OrderLocationResponse là vị trí hiện tại của đơn hàng theo vị trí của tài xế được phân công
+type OrderLocationResponse struct {
+	OrderID  string `json:"order_id"`
+	DriverID string `json:"driver_id"`
+	LocationResponse
+	Trail *TrailPointResponse `json:"trail,omitempty"`
+}
+
+// DecodeRecordPingsRequest xử lý việc giải mã request gửi vị trí của tài xế
+func DecodeRecordPingsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req RecordPingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("không thể decode request: %w", err)
+	}
+	req.DriverID = mux.Vars(r)["id"]
+
+	if len(req.Pings) == 0 {
+		req.Pings = []LocationPingRequest{req.LocationPingRequest}
+	}
+	return req, nil
+}
+
+// DecodeDriverLocationRequest xử lý việc giải mã request lấy vị trí của tài xế
+func DecodeDriverLocationRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+	return DriverLocationRequest{DriverID: id}, nil
+}
+
+// DecodeOrderLocationRequest xử lý việc giải mã request lấy vị trí của đơn hàng
+func DecodeOrderLocationRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+	return OrderLocationRequest{OrderID: id}, nil
+}
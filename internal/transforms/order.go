@@ -8,8 +8,13 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/shopspring/decimal"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // CreateOrderRequest Request
@@ -18,6 +23,19 @@ type CreateOrderRequest struct {
 	Origin      domain.Location    `json:"origin,omitempty"`
 	Destination domain.Location    `json:"destination,omitempty"`
 	Items       []domain.OrderItem `json:"items,omitempty"`
+
+	// ServiceLevel là STANDARD (mặc định) hoặc EXPRESS
+	ServiceLevel domain.ServiceLevel `json:"service_level,omitempty"`
+
+	// PaymentMethod là PREPAID (mặc định) hoặc COD
+	PaymentMethod domain.PaymentMethod `json:"payment_method,omitempty"`
+	// CODAmount là số tiền thu hộ, bỏ trống thì thu tổng tiền hàng và phí vận chuyển
+	CODAmount *decimal.Decimal `json:"cod_amount,omitempty"`
+}
+
+// PaymentTerms là cách thanh toán của request
+func (r CreateOrderRequest) PaymentTerms() domain.PaymentTerms {
+	return domain.PaymentTerms{Method: r.PaymentMethod, Amount: r.CODAmount}
 }
 
 // CreateOrderResponse Responses
@@ -32,12 +50,15 @@ func DecodeCreateOrderRequest(_ context.Context, r *http.Request) (interface{},
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, err
 	}
+	req.ServiceLevel = domain.ServiceLevel(strings.ToUpper(string(req.ServiceLevel)))
+	req.PaymentMethod = domain.PaymentMethod(strings.ToUpper(string(req.PaymentMethod)))
 	return req, nil
 }
 
 // GetOrderRequest Các request
 type GetOrderRequest struct {
 	OrderID string
+	AsOf    domain.AsOf
 }
 
 type GetOrderResponse struct {
@@ -53,7 +74,54 @@ func DecodeGetOrderRequest(_ context.Context, r *http.Request) (interface{}, err
 		return nil, fmt.Errorf("thiếu tham số id")
 	}
 
-	return GetOrderRequest{OrderID: id}, nil
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		return nil, fmt.Errorf("as_of không hợp lệ: %w", err)
+	}
+
+	return GetOrderRequest{OrderID: id, AsOf: asOf}, nil
+}
+
+// DiffOrderRequest so sánh đơn hàng giữa hai thời điểm
+type DiffOrderRequest struct {
+	OrderID string
+	From    domain.AsOf
+	To      domain.AsOf
+}
+
+// DiffOrderResponse là view model của sự khác biệt giữa hai thời điểm
+type DiffOrderResponse struct {
+	OrderID string                         `json:"order_id"`
+	From    *domain.Order                  `json:"from"`
+	To      *domain.Order                  `json:"to"`
+	Changes []domain.FieldChange           `json:"changes"`
+	Events  []GetOrderHistoryEntryResponse `json:"events"`
+}
+
+// DecodeDiffOrderRequest xử lý việc giải mã request so sánh đơn hàng
+func DecodeDiffOrderRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+
+	q := r.URL.Query()
+	if q.Get("from") == "" {
+		return nil, fmt.Errorf("thiếu tham số from")
+	}
+
+	from, err := parseAsOf(q.Get("from"))
+	if err != nil {
+		return nil, fmt.Errorf("from không hợp lệ: %w", err)
+	}
+
+	to, err := parseAsOf(q.Get("to"))
+	if err != nil {
+		return nil, fmt.Errorf("to không hợp lệ: %w", err)
+	}
+
+	return DiffOrderRequest{OrderID: id, From: from, To: to}, nil
 }
 
 // ListOrdersRequest truy vấn danh sách đơn hàng
@@ -65,11 +133,28 @@ type ListOrdersRequest struct {
 }
 
 type OrderSummaryResponse struct {
-	ID             string             `json:"id"`
-	TrackingNumber string             `json:"tracking_number"`
-	Status         domain.OrderStatus `json:"status"`
-	CreatedAt      string             `json:"created_at"`
-	UpdatedAt      string             `json:"updated_at"`
+	ID                string              `json:"id"`
+	TrackingNumber    string              `json:"tracking_number"`
+	Status            domain.OrderStatus  `json:"status"`
+	ServiceLevel      domain.ServiceLevel `json:"service_level"`
+	EstimatedDelivery string              `json:"estimated_delivery,omitempty"`
+	SLABreached       bool                `json:"sla_breached"`
+	CreatedAt         string              `json:"created_at"`
+	UpdatedAt         string              `json:"updated_at"`
+
+	DistanceRemainingKm *float64 `json:"distance_remaining_km,omitempty"`
+	ProgressPct         *float64 `json:"progress_pct,omitempty"`
+
+	Currency    string           `json:"currency"`
+	Subtotal    decimal.Decimal  `json:"subtotal"`
+	ShippingFee *decimal.Decimal `json:"shipping_fee,omitempty"`
+	Total       *decimal.Decimal `json:"total,omitempty"` // tiền hàng cộng phí vận chuyển
+
+	// PaymentStatus có với đơn hàng COD và đơn hàng trả trước cần hoàn tiền, CODAmount chỉ có với đơn hàng COD
+	PaymentStatus domain.PaymentStatus `json:"payment_status,omitempty"`
+	CODAmount     *decimal.Decimal     `json:"cod_amount,omitempty"`
+
+	DriverID string `json:"driver_id,omitempty"` // tài xế được phân công
 }
 
 type ListOrdersResponse struct {
@@ -105,6 +190,63 @@ func DecodeListOrdersRequest(_ context.Context, r *http.Request) (interface{}, e
 	}, nil
 }
 
+// ListOrdersNearRequest yêu cầu tìm đơn hàng quanh một tọa độ
+type ListOrdersNearRequest struct {
+	Latitude  float64            `json:"lat"`
+	Longitude float64            `json:"lng"`
+	RadiusKm  float64            `json:"radius"`
+	Status    domain.OrderStatus `json:"status"`
+	Limit     int                `json:"limit"`
+}
+
+// NearbyOrderResponse là đơn hàng lân cận kèm khoảng cách tới điểm tìm kiếm
+type NearbyOrderResponse struct {
+	OrderSummaryResponse
+	DistanceKm float64 `json:"distance_km"`
+}
+
+type ListOrdersNearResponse struct {
+	Items []NearbyOrderResponse `json:"items"`
+	Count int                   `json:"count"`
+}
+
+// DecodeListOrdersNearRequest xử lý việc giải mã request tìm đơn hàng lân cận, radius tính theo km và mặc định 5 km
+func DecodeListOrdersNearRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	if q.Get("lat") == "" || q.Get("lng") == "" {
+		return nil, fmt.Errorf("thiếu tham số lat hoặc lng")
+	}
+
+	latitude, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("lat không hợp lệ: %w", err)
+	}
+	longitude, err := strconv.ParseFloat(q.Get("lng"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("lng không hợp lệ: %w", err)
+	}
+
+	radius := 5.0
+	if q.Get("radius") != "" {
+		if radius, err = strconv.ParseFloat(q.Get("radius"), 64); err != nil {
+			return nil, fmt.Errorf("radius không hợp lệ: %w", err)
+		}
+	}
+
+	limit, err := parseIntParam(q.Get("limit"), 50)
+	if err != nil {
+		return nil, fmt.Errorf("limit không hợp lệ: %w", err)
+	}
+
+	return ListOrdersNearRequest{
+		Latitude:  latitude,
+		Longitude: longitude,
+		RadiusKm:  radius,
+		Status:    domain.OrderStatus(strings.ToUpper(q.Get("status"))),
+		Limit:     limit,
+	}, nil
+}
+
 type UpdateOrderStatusRequest struct {
 	OrderID   string             `json:"order_id" validate:"required"`
 	NewStatus domain.OrderStatus `json:"new_status" validate:"required"`
@@ -225,6 +367,7 @@ type GetOrderHistoryEntryResponse struct {
 	Location   *domain.Location   `json:"location,omitempty"`
 	Note       string             `json:"note,omitempty"`
 	PrevStatus domain.OrderStatus `json:"prev_status,omitempty"`
+	Metadata   map[string]string  `json:"metadata,omitempty"` // ip, country, city của request tạo ra sự kiện
 }
 
 // GetOrderHistoryResponse là view model của lịch sử đơn hàng
@@ -260,6 +403,26 @@ func DecodeGetOrderByTrackingRequest(_ context.Context, r *http.Request) (interf
 	return GetOrderByTrackingRequest{TrackingNumber: trackingNumber}, nil
 }
 
+// parseAsOf chuyển đổi tham số thời điểm: số nguyên là phiên bản sự kiện, còn lại là thời gian RFC3339
+func parseAsOf(param string) (domain.AsOf, error) {
+	if param == "" {
+		return domain.AsOf{}, nil
+	}
+
+	if version, err := strconv.Atoi(param); err == nil {
+		if version < 1 {
+			return domain.AsOf{}, fmt.Errorf("phiên bản phải lớn hơn 0")
+		}
+		return domain.AsOf{Version: version}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return domain.AsOf{}, fmt.Errorf("cần số phiên bản hoặc thời gian RFC3339")
+	}
+	return domain.AsOf{Time: t}, nil
+}
+
 // parseIntParam chuyển đổi string thành int với giá trị mặc định
 func parseIntParam(param string, defaultValue int) (int, error) {
 	if param == "" {
@@ -273,3 +436,106 @@ func parseIntParam(param string, defaultValue int) (int, error) {
 
 	return value, nil
 }
+
+// BatchUpdateOrderStatusEntry là một cập nhật trạng thái trong lô, cần order_id hoặc tracking_number
+type BatchUpdateOrderStatusEntry struct {
+	OrderID        string             `json:"order_id"`
+	TrackingNumber string             `json:"tracking_number"`
+	NewStatus      domain.OrderStatus `json:"new_status"`
+	Location       *domain.Location   `json:"location"`
+	Note           string             `json:"note"`
+}
+
+// BatchUpdateOrderStatusRequest cập nhật trạng thái nhiều đơn hàng cùng lúc
+type BatchUpdateOrderStatusRequest struct {
+	Updates []BatchUpdateOrderStatusEntry `json:"updates" validate:"required,min=1"`
+}
+
+// BatchUpdateOrderStatusResult là kết quả của một cập nhật theo vị trí trong lô
+type BatchUpdateOrderStatusResult struct {
+	Index          int    `json:"index"`
+	OrderID        string `json:"order_id,omitempty"`
+	TrackingNumber string `json:"tracking_number,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+}
+
+type BatchUpdateOrderStatusResponse struct {
+	Total     int                            `json:"total"`
+	Succeeded int                            `json:"succeeded"`
+	Failed    int                            `json:"failed"`
+	Results   []BatchUpdateOrderStatusResult `json:"results"`
+}
+
+// DecodeBatchUpdateOrderStatusRequest xử lý việc giải mã request cập nhật trạng thái hàng loạt
+func DecodeBatchUpdateOrderStatusRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req BatchUpdateOrderStatusRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+
+		// Validate request, từng cập nhật được kiểm tra riêng trong service
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+
+		return req, nil
+	}
+}
+
+// exportContentTypes là Content-Type của từng định dạng xuất
+var exportContentTypes = map[string]string{
+	services.ExportFormatCSV:    "text/csv; charset=utf-8",
+	services.ExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	services.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// ExportOrdersRequest xuất đơn hàng theo bộ lọc của ListOrders
+type ExportOrdersRequest struct {
+	Format string
+	Filter services.ExportFilter
+}
+
+// ExportOrdersResponse ghi file xuất trực tiếp vào response
+type ExportOrdersResponse struct {
+	ContentType string
+	Filename    string
+	Write       func(ctx context.Context, w io.Writer) error
+}
+
+// DecodeExportOrdersRequest xử lý việc giải mã request xuất đơn hàng
+func DecodeExportOrdersRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = services.ExportFormatCSV
+	}
+	if _, ok := exportContentTypes[format]; !ok {
+		return nil, fmt.Errorf("format không hợp lệ: %q, hỗ trợ csv, xlsx, ndjson", format)
+	}
+
+	includeEvents := false
+	if value := q.Get("include_events"); value != "" {
+		var err error
+		if includeEvents, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("include_events không hợp lệ: %w", err)
+		}
+	}
+
+	return ExportOrdersRequest{
+		Format: format,
+		Filter: services.ExportFilter{
+			CustomerID:    q.Get("customer_id"),
+			Status:        domain.OrderStatus(q.Get("status")),
+			IncludeEvents: includeEvents,
+		},
+	}, nil
+}
+
+// ExportContentType trả về Content-Type của định dạng xuất
+func ExportContentType(format string) string {
+	return exportContentTypes[format]
+}
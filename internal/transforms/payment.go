@@ -0,0 +1,187 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/shopspring/decimal"
+)
+
+// CollectPaymentRequest ghi nhận tiền tài xế thu khi giao hàng
+type CollectPaymentRequest struct {
+	OrderID  string          `json:"order_id" validate:"required"`
+	DriverID string          `json:"driver_id" validate:"required"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+// RefundPaymentRequest ghi nhận đã hoàn tiền cho người nhận
+type RefundPaymentRequest struct {
+	OrderID   string `json:"order_id" validate:"required"`
+	Reference string `json:"reference"` // mã giao dịch hoàn tiền
+}
+
+// PaymentResponse là kết quả của lệnh thu hoặc hoàn tiền
+type PaymentResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// RemitPaymentsRequest ghi nhận tài xế nộp tiền thu hộ, order_ids rỗng nghĩa là tất cả các khoản chưa nộp
+type RemitPaymentsRequest struct {
+	DriverID  string   `json:"driver_id" validate:"required"`
+	OrderIDs  []string `json:"order_ids"`
+	Reference string   `json:"reference"` // mã phiếu nộp tiền
+}
+
+// RemitPaymentResult là kết quả nộp tiền của một đơn hàng
+type RemitPaymentResult struct {
+	OrderID  string           `json:"order_id"`
+	Status   string           `json:"status"`
+	Amount   *decimal.Decimal `json:"amount,omitempty"`
+	Currency string           `json:"currency,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// RemitPaymentsResponse tổng hợp số tiền đã nộp theo tiền tệ
+type RemitPaymentsResponse struct {
+	Total     int                        `json:"total"`
+	Succeeded int                        `json:"succeeded"`
+	Failed    int                        `json:"failed"`
+	Remitted  map[string]decimal.Decimal `json:"remitted"`
+	Results   []RemitPaymentResult       `json:"results"`
+}
+
+// CODBalancesRequest lọc đối soát thu hộ theo tài xế và khoảng ngày thu (YYYY-MM-DD)
+type CODBalancesRequest struct {
+	DriverID       string
+	From           *time.Time
+	To             *time.Time
+	IncludeSettled bool
+}
+
+// CODBalanceResponse là đối soát của một tài xế trong một ngày
+type CODBalanceResponse struct {
+	DriverID        string          `json:"driver_id"`
+	Day             string          `json:"day"`
+	Currency        string          `json:"currency"`
+	CollectedOrders int             `json:"collected_orders"`
+	Collected       decimal.Decimal `json:"collected"`
+	RemittedOrders  int             `json:"remitted_orders"`
+	Remitted        decimal.Decimal `json:"remitted"`
+	Outstanding     decimal.Decimal `json:"outstanding"`
+}
+
+// OutstandingCODRequest lấy các khoản tài xế chưa nộp
+type OutstandingCODRequest struct {
+	DriverID string
+}
+
+// CODCollectionResponse là một khoản tài xế đã thu nhưng chưa nộp
+type CODCollectionResponse struct {
+	OrderID     string          `json:"order_id"`
+	Day         string          `json:"day"`
+	Amount      decimal.Decimal `json:"amount"`
+	Currency    string          `json:"currency"`
+	CollectedAt string          `json:"collected_at"`
+}
+
+// OutstandingCODResponse là các khoản chưa nộp và tổng theo tiền tệ
+type OutstandingCODResponse struct {
+	DriverID    string                     `json:"driver_id"`
+	Outstanding map[string]decimal.Decimal `json:"outstanding"`
+	Items       []CODCollectionResponse    `json:"items"`
+}
+
+// DecodeCollectPaymentRequest xử lý việc giải mã request thu tiền
+func DecodeCollectPaymentRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req CollectPaymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+		req.OrderID = mux.Vars(r)["id"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeRefundPaymentRequest xử lý việc giải mã request hoàn tiền, body có thể rỗng
+func DecodeRefundPaymentRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req RefundPaymentRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, fmt.Errorf("không thể decode request: %w", err)
+			}
+		}
+		req.OrderID = mux.Vars(r)["id"]
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeRemitPaymentsRequest xử lý việc giải mã request nộp tiền thu hộ
+func DecodeRemitPaymentsRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req RemitPaymentsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("không thể decode request: %w", err)
+		}
+
+		if err := validate.Struct(req); err != nil {
+			return nil, fmt.Errorf("request không hợp lệ: %w", err)
+		}
+		return req, nil
+	}
+}
+
+// DecodeCODBalancesRequest xử lý việc giải mã bộ lọc đối soát trên query
+func DecodeCODBalancesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	req := CODBalancesRequest{DriverID: q.Get("driver_id")}
+
+	for name, target := range map[string]**time.Time{"from": &req.From, "to": &req.To} {
+		value := q.Get(name)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse(projection.DayLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s không hợp lệ, cần định dạng YYYY-MM-DD: %s", name, value)
+		}
+		*target = &day
+	}
+
+	if value := q.Get("include_settled"); value != "" {
+		includeSettled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("include_settled không hợp lệ: %s", value)
+		}
+		req.IncludeSettled = includeSettled
+	}
+
+	return req, nil
+}
+
+// DecodeOutstandingCODRequest xử lý việc giải mã request lấy các khoản chưa nộp
+func DecodeOutstandingCODRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	driverID, ok := mux.Vars(r)["driver_id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số driver_id")
+	}
+	return OutstandingCODRequest{DriverID: driverID}, nil
+}
@@ -0,0 +1,31 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+// ErasePersonalDataRequest yêu cầu xóa dữ liệu cá nhân của khách hàng
+type ErasePersonalDataRequest struct {
+	CustomerID string `json:"customer_id" validate:"required"`
+}
+
+// ErasePersonalDataResponse kết quả xóa dữ liệu cá nhân
+type ErasePersonalDataResponse struct {
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	OrdersRebuilt int    `json:"orders_rebuilt"`
+}
+
+// DecodeErasePersonalDataRequest xử lý việc giải mã request xóa dữ liệu cá nhân
+func DecodeErasePersonalDataRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+
+	return ErasePersonalDataRequest{CustomerID: id}, nil
+}
@@ -0,0 +1,138 @@
+package transforms
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/models"
+	"github.com/quyenle-97/init/pkgs/utils"
+	"github.com/shopspring/decimal"
+)
+
+// ProductRequest tạo hoặc ghi đè sản phẩm
+type ProductRequest struct {
+	ID         string             `json:"-"`
+	Name       string             `json:"name" validate:"required,max=255"`
+	Price      *decimal.Decimal   `json:"price" validate:"required"`
+	Quantity   *int               `json:"quantity" validate:"required,gte=0"`
+	Weight     float64            `json:"weight" validate:"gte=0"`
+	Status     models.ModelStatus `json:"status" validate:"required,oneof=1 2"`
+	Categories []string           `json:"categories" validate:"required,min=1,dive,required"`
+	CityID     string             `json:"city_id" validate:"required"`
+	SupplierID string             `json:"supplier_id" validate:"required"`
+}
+
+// ProductSearchRequest là bộ lọc danh sách sản phẩm, trường bỏ trống không lọc
+type ProductSearchRequest struct {
+	References []string             `json:"references"`
+	Names      []string             `json:"names"`
+	AddFrom    string               `json:"add_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	AddTo      string               `json:"add_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Status     []models.ModelStatus `json:"Status" validate:"dive,oneof=1 2"`
+	Categories []string             `json:"categories"`
+	Cities     []string             `json:"cities"`
+	Offset     int                  `json:"offset" validate:"gte=0"`
+	Limit      int                  `json:"limit" validate:"gte=0,lte=100"`
+}
+
+// AddFromTime trả về mốc thời gian tạo sớm nhất, nil khi không lọc
+func (r ProductSearchRequest) AddFromTime() *time.Time {
+	return parseOptionalTime(r.AddFrom)
+}
+
+// AddToTime trả về mốc thời gian tạo muộn nhất, nil khi không lọc
+func (r ProductSearchRequest) AddToTime() *time.Time {
+	return parseOptionalTime(r.AddTo)
+}
+
+// DecodeProductSearchRequest xử lý việc giải mã bộ lọc danh sách sản phẩm
+func DecodeProductSearchRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req ProductSearchRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		if req.Limit == 0 {
+			req.Limit = DefaultCatalogLimit
+		}
+		return req, nil
+	}
+}
+
+// DecodeProductRequest xử lý việc giải mã request tạo hoặc ghi đè sản phẩm
+func DecodeProductRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req ProductRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		if req.Price.IsNegative() {
+			return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: "price không được âm"}
+		}
+		req.ID = mux.Vars(r)["uid"]
+		return req, nil
+	}
+}
+
+// ProductDistanceRequest tính khoảng cách tới kho nhà cung cấp của sản phẩm từ tọa độ (lat, lng) hoặc thành phố (city_id)
+type ProductDistanceRequest struct {
+	ID        string
+	Latitude  *float64
+	Longitude *float64
+	CityID    string
+}
+
+// ProductDistanceResponse là khoảng cách đường chim bay từ điểm yêu cầu tới kho nhà cung cấp
+type ProductDistanceResponse struct {
+	ProductID  string     `json:"product_id"`
+	From       models.GEO `json:"from"`
+	To         models.GEO `json:"to"`
+	DistanceKm float64    `json:"distance_km"`
+}
+
+// DecodeProductDistanceRequest xử lý việc giải mã request tính khoảng cách, cần cả lat và lng hoặc city_id
+func DecodeProductDistanceRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	req := ProductDistanceRequest{ID: mux.Vars(r)["uid"], CityID: query.Get("city_id")}
+
+	lat, lng := query.Get("lat"), query.Get("lng")
+	switch {
+	case lat == "" && lng == "":
+		if req.CityID == "" {
+			return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: "cần lat và lng hoặc city_id"}
+		}
+		return req, nil
+	case lat == "" || lng == "":
+		return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: "cần cả lat và lng"}
+	case req.CityID != "":
+		return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: "chỉ truyền lat và lng hoặc city_id"}
+	}
+
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: "lat không hợp lệ: " + lat}
+	}
+	longitude, err := strconv.ParseFloat(lng, 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: "lng không hợp lệ: " + lng}
+	}
+	req.Latitude, req.Longitude = &latitude, &longitude
+	return req, nil
+}
+
+// parseOptionalTime chuyển chuỗi RFC3339 đã được validate thành thời gian, chuỗi rỗng trả về nil
+func parseOptionalTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
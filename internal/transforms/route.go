@@ -0,0 +1,74 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// PlanRouteRequest lập lộ trình cho tài xế, không truyền tọa độ thì xuất phát từ vị trí mới nhất của tài xế
+type PlanRouteRequest struct {
+	DriverID  string
+	Latitude  *float64           `json:"latitude,omitempty"`
+	Longitude *float64           `json:"longitude,omitempty"`
+	Status    domain.OrderStatus `json:"status,omitempty"` // chỉ lập lộ trình cho đơn hàng ở trạng thái này
+}
+
+// DriverRouteRequest lấy lộ trình gần nhất của tài xế
+type DriverRouteRequest struct {
+	DriverID string
+}
+
+// RouteStopResponse là một điểm dừng trong lộ trình
+type RouteStopResponse struct {
+	Sequence   int     `json:"sequence"` // thứ tự giao, bắt đầu từ 1
+	OrderID    string  `json:"order_id"`
+	Trip       int     `json:"trip"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	DistanceKm float64 `json:"distance_km"` // quãng đường từ điểm dừng trước
+}
+
+// RouteResponse là lộ trình giao hàng của tài xế
+type RouteResponse struct {
+	DriverID       string              `json:"driver_id"`
+	VehicleID      string              `json:"vehicle_id"`
+	StartLatitude  float64             `json:"start_latitude"`
+	StartLongitude float64             `json:"start_longitude"`
+	Trips          int                 `json:"trips"`
+	DistanceKm     float64             `json:"distance_km"`
+	Stops          []RouteStopResponse `json:"stops"`
+	Unrouted       []string            `json:"unrouted,omitempty"` // đơn hàng không có tọa độ điểm giao
+	PlannedAt      string              `json:"planned_at"`
+}
+
+// DecodePlanRouteRequest xử lý việc giải mã request lập lộ trình, body có thể để trống
+func DecodePlanRouteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req PlanRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("không thể decode request: %w", err)
+	}
+	req.DriverID = mux.Vars(r)["id"]
+	req.Status = domain.OrderStatus(strings.ToUpper(string(req.Status)))
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, errors.New("cần truyền cả latitude và longitude của điểm xuất phát")
+	}
+	return req, nil
+}
+
+// DecodeDriverRouteRequest xử lý việc giải mã request lấy lộ trình của tài xế
+func DecodeDriverRouteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số id")
+	}
+	return DriverRouteRequest{DriverID: id}, nil
+}
@@ -0,0 +1,67 @@
+package transforms
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/pkgs/utils"
+)
+
+// OrdersPerDayRequest lọc thống kê đơn hàng theo ngày tạo, nil nghĩa là không giới hạn
+type OrdersPerDayRequest struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// DailyOrdersResponse là số đơn hàng được tạo trong một ngày (UTC)
+type DailyOrdersResponse struct {
+	Day    string `json:"day"`
+	Orders int    `json:"orders"`
+}
+
+// OrderStatusStatsResponse là số đơn hàng đang ở một trạng thái và thời gian trung bình ở trạng thái đó
+type OrderStatusStatsResponse struct {
+	Status         string  `json:"status"`
+	Orders         int     `json:"orders"`
+	Exits          int     `json:"exits"`
+	AverageSeconds float64 `json:"average_seconds"`
+}
+
+// CancellationReasonResponse là số đơn hàng bị hủy và tỷ lệ hủy theo một lý do
+type CancellationReasonResponse struct {
+	Reason string  `json:"reason"`
+	Orders int     `json:"orders"`
+	Rate   float64 `json:"rate"`
+}
+
+// CancellationStatsResponse là tỷ lệ hủy đơn hàng trên tổng số đơn hàng đã tạo
+type CancellationStatsResponse struct {
+	TotalOrders int                          `json:"total_orders"`
+	Cancelled   int                          `json:"cancelled"`
+	Rate        float64                      `json:"rate"`
+	Reasons     []CancellationReasonResponse `json:"reasons"`
+}
+
+// DecodeOrdersPerDayRequest xử lý việc giải mã khoảng ngày from, to (YYYY-MM-DD) trên query
+func DecodeOrdersPerDayRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req OrdersPerDayRequest
+	for name, target := range map[string]**time.Time{"from": &req.From, "to": &req.To} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse(projection.DayLayout, value)
+		if err != nil {
+			return nil, utils.Message{Code: http.StatusUnprocessableEntity, Message: name + " không hợp lệ, cần định dạng YYYY-MM-DD: " + value}
+		}
+		*target = &day
+	}
+	return req, nil
+}
+
+// DecodeEmptyRequest dùng cho các API không có tham số
+func DecodeEmptyRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
@@ -0,0 +1,48 @@
+package transforms
+
+import (
+	"context"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/models"
+)
+
+// CreateSupplierRequest tạo nhà cung cấp mới
+type CreateSupplierRequest struct {
+	Name     string      `json:"name" validate:"required,max=255"`
+	Location *models.GEO `json:"location"` // vị trí kho, dùng để tính khoảng cách tới sản phẩm
+}
+
+// UpdateSupplierRequest cập nhật nhà cung cấp, trường bỏ trống giữ nguyên
+type UpdateSupplierRequest struct {
+	ID       string              `json:"-"`
+	Name     *string             `json:"name" validate:"omitempty,min=1,max=255"`
+	Status   *models.ModelStatus `json:"status" validate:"omitempty,oneof=1 2"`
+	Location *models.GEO         `json:"location"`
+}
+
+// DecodeCreateSupplierRequest xử lý việc giải mã request tạo nhà cung cấp
+func DecodeCreateSupplierRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req CreateSupplierRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+}
+
+// DecodeUpdateSupplierRequest xử lý việc giải mã request cập nhật nhà cung cấp
+func DecodeUpdateSupplierRequest(validate *validator.Validate) httptransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req UpdateSupplierRequest
+		if err := decodeCatalogBody(r, &req, validate); err != nil {
+			return nil, err
+		}
+		req.ID = mux.Vars(r)["uid"]
+		return req, nil
+	}
+}
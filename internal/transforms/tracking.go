@@ -0,0 +1,49 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/quyenle-97/init/internal/domain"
+)
+
+// GetTrackingRequest yêu cầu lấy thông tin theo dõi công khai
+type GetTrackingRequest struct {
+	TrackingNumber string `json:"tracking_number"`
+}
+
+// TrackingUpdateResponse là một mục trong lịch trình theo dõi
+type TrackingUpdateResponse struct {
+	Timestamp string             `json:"timestamp"`
+	Status    domain.OrderStatus `json:"status"`
+	Location  string             `json:"location,omitempty"`
+	Message   string             `json:"message"`
+}
+
+// TrackingResponse là thông tin theo dõi hiển thị cho khách hàng, không chứa dữ liệu cá nhân hay ghi chú nội bộ
+type TrackingResponse struct {
+	TrackingNumber    string                   `json:"tracking_number"`
+	Status            domain.OrderStatus       `json:"status"`
+	ServiceLevel      domain.ServiceLevel      `json:"service_level"`
+	OriginCity        string                   `json:"origin_city,omitempty"`
+	DestinationCity   string                   `json:"destination_city,omitempty"`
+	CurrentCity       string                   `json:"current_city,omitempty"`
+	EstimatedDelivery string                   `json:"estimated_delivery,omitempty"`
+	Delayed           bool                     `json:"delayed"`
+	CreatedAt         string                   `json:"created_at"`
+	UpdatedAt         string                   `json:"updated_at"`
+	Updates           []TrackingUpdateResponse `json:"updates"`
+}
+
+// DecodeGetTrackingRequest xử lý việc giải mã request lấy thông tin theo dõi
+func DecodeGetTrackingRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	trackingNumber, ok := vars["tracking_number"]
+	if !ok {
+		return nil, fmt.Errorf("thiếu tham số tracking_number")
+	}
+
+	return GetTrackingRequest{TrackingNumber: trackingNumber}, nil
+}
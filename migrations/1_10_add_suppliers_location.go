@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// SuppliersLocation thêm vị trí kho cho bảng suppliers để tính khoảng cách tới sản phẩm
+type SuppliersLocation struct {
+	Version int
+}
+
+func (m SuppliersLocation) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range []string{
+		"location_name VARCHAR(255)",
+		"location_latitude DOUBLE PRECISION",
+		"location_longitude DOUBLE PRECISION",
+	} {
+		_, err = addColumnIfNotExists(db, (*SupplierModel)(nil), column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m SuppliersLocation) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range []string{"location_name", "location_latitude", "location_longitude"} {
+		_, err = db.NewDropColumn().
+			Model((*SupplierModel)(nil)).
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m SuppliersLocation) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
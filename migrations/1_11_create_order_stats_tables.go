@@ -0,0 +1,102 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrderStatsModel là cấu trúc bảng order_stats_orders tại thời điểm tạo
+type OrderStatsModel struct {
+	bun.BaseModel `bun:"table:order_stats_orders,alias:oso"`
+
+	ID          string    `bun:"id,pk"`
+	Status      string    `bun:"status,notnull"`
+	StatusSince time.Time `bun:"status_since,notnull"`
+	Version     int       `bun:"version,notnull"`
+}
+
+// OrderStatsDailyModel là cấu trúc bảng order_stats_daily tại thời điểm tạo
+type OrderStatsDailyModel struct {
+	bun.BaseModel `bun:"table:order_stats_daily,alias:osd"`
+
+	Day    string `bun:"day,pk"`
+	Orders int    `bun:"orders,notnull"`
+}
+
+// OrderStatsStatusModel là cấu trúc bảng order_stats_statuses tại thời điểm tạo
+type OrderStatsStatusModel struct {
+	bun.BaseModel `bun:"table:order_stats_statuses,alias:oss"`
+
+	Status       string  `bun:"status,pk"`
+	Orders       int     `bun:"orders,notnull"`
+	Exits        int     `bun:"exits,notnull"`
+	TotalSeconds float64 `bun:"total_seconds,notnull"`
+}
+
+// OrderStatsCancellationModel là cấu trúc bảng order_stats_cancellations tại thời điểm tạo
+type OrderStatsCancellationModel struct {
+	bun.BaseModel `bun:"table:order_stats_cancellations,alias:osc"`
+
+	Reason string `bun:"reason,pk"`
+	Orders int    `bun:"orders,notnull"`
+}
+
+// OrderStatsTables tạo các bảng tổng hợp cho projection thống kê đơn hàng
+type OrderStatsTables struct {
+	Version int
+}
+
+// orderStatsModels là các bảng thống kê theo thứ tự tạo
+func orderStatsModels() []interface{} {
+	return []interface{}{
+		(*OrderStatsModel)(nil),
+		(*OrderStatsDailyModel)(nil),
+		(*OrderStatsStatusModel)(nil),
+		(*OrderStatsCancellationModel)(nil),
+	}
+}
+
+func (m OrderStatsTables) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range orderStatsModels() {
+		_, err = db.NewCreateTable().
+			Model(model).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrderStatsTables) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range orderStatsModels() {
+		_, err = db.NewDropTable().
+			Model(model).
+			IfExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrderStatsTables) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ProductsWeight thêm khối lượng (kg) cho bảng products để điền vào mặt hàng của đơn hàng
+type ProductsWeight struct {
+	Version int
+}
+
+func (m ProductsWeight) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = addColumnIfNotExists(db, (*ProductModel)(nil), "weight DOUBLE PRECISION NOT NULL DEFAULT 0").
+		Exec(ctx)
+	return err
+}
+
+func (m ProductsWeight) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropColumn().
+		Model((*ProductModel)(nil)).
+		ColumnExpr("weight").
+		Exec(ctx)
+	return err
+}
+
+func (m ProductsWeight) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,138 @@
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// OrdersTotals thêm tiền tệ, tổng tiền hàng, tổng khối lượng, giá trị khai báo và phí vận chuyển cho bảng orders
+type OrdersTotals struct {
+	Version int
+}
+
+// ordersTotalsBatch là số đơn hàng cũ được tính tổng trong một lần truy vấn
+const ordersTotalsBatch = 500
+
+// ordersTotalsColumns là các cột mới, đơn hàng cũ dùng tiền tệ mặc định VND và chưa có phí vận chuyển
+var ordersTotalsColumns = []string{
+	"currency VARCHAR(3) NOT NULL DEFAULT 'VND'",
+	"subtotal DECIMAL(20,2) NOT NULL DEFAULT 0",
+	"total_weight DECIMAL(15,3) NOT NULL DEFAULT 0",
+	"declared_value DECIMAL(20,2) NOT NULL DEFAULT 0",
+	"shipping_fee DECIMAL(20,2)",
+}
+
+// orderItemsRow là các cột cần để tính tổng của đơn hàng cũ
+type orderItemsRow struct {
+	ID        string `bun:"id"`
+	ItemsData []byte `bun:"items_data"`
+}
+
+// orderItemAmounts là phần số lượng, khối lượng và giá trong JSON mặt hàng,
+// giá và khối lượng cũ được lưu dạng số nên giải mã qua decimal để không mất độ chính xác
+type orderItemAmounts struct {
+	Quantity int             `json:"quantity"`
+	Weight   decimal.Decimal `json:"weight"`
+	Price    decimal.Decimal `json:"price"`
+	Product  *struct {
+		Price decimal.Decimal `json:"price"`
+	} `json:"product"`
+}
+
+func (m OrdersTotals) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	for _, column := range ordersTotalsColumns {
+		_, err = addColumnIfNotExists(db, (*OrderModel)(nil), column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.backfill(ctx, db)
+}
+
+// backfill tính tổng cho đơn hàng đã có, đơn hàng mới được read model cập nhật khi xử lý sự kiện
+func (m OrdersTotals) backfill(ctx context.Context, db *bun.DB) error {
+	last := ""
+	for {
+		var rows []orderItemsRow
+		err := db.NewSelect().
+			Model((*OrderModel)(nil)).
+			Column("id", "items_data").
+			Where("id > ?", last).
+			Order("id ASC").
+			Limit(ordersTotalsBatch).
+			Scan(ctx, &rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			var items []orderItemAmounts
+			if json.Unmarshal(row.ItemsData, &items) != nil {
+				continue
+			}
+
+			subtotal, weight, declared := decimal.Zero, decimal.Zero, decimal.Zero
+			for _, item := range items {
+				quantity := decimal.NewFromInt(int64(item.Quantity))
+				subtotal = subtotal.Add(item.Price.Mul(quantity))
+				weight = weight.Add(item.Weight.Mul(quantity))
+				if item.Product != nil {
+					declared = declared.Add(item.Product.Price.Mul(quantity))
+				} else {
+					declared = declared.Add(item.Price.Mul(quantity))
+				}
+			}
+
+			_, err = db.NewUpdate().
+				Table("orders").
+				Set("subtotal = ?", subtotal).
+				Set("total_weight = ?", weight).
+				Set("declared_value = ?", declared).
+				Where("id = ?", row.ID).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < ordersTotalsBatch {
+			return nil
+		}
+		last = rows[len(rows)-1].ID
+	}
+}
+
+func (m OrdersTotals) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range []string{"currency", "subtotal", "total_weight", "declared_value", "shipping_fee"} {
+		_, err = db.NewDropColumn().
+			Model((*OrderModel)(nil)).
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrdersTotals) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrdersPayment thêm trạng thái thu hộ (COD) cho bảng orders
+type OrdersPayment struct {
+	Version int
+}
+
+// ordersPaymentColumns là các cột được thêm, đơn hàng cũ không thu hộ nên để NULL
+var ordersPaymentColumns = []string{
+	"payment_method VARCHAR(16)",
+	"payment_status VARCHAR(20)",
+	"cod_amount DECIMAL(20,2)",
+	"cod_collected DECIMAL(20,2)",
+	"cod_driver_id VARCHAR(36)",
+	"cod_collected_at TIMESTAMP",
+	"cod_remitted_at TIMESTAMP",
+	"cod_refunded_at TIMESTAMP",
+}
+
+func (m OrdersPayment) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range ordersPaymentColumns {
+		_, err = addColumnIfNotExists(db, (*OrderModel)(nil), column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrdersPayment) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range []string{"payment_method", "payment_status", "cod_amount", "cod_collected", "cod_driver_id", "cod_collected_at", "cod_remitted_at", "cod_refunded_at"} {
+		_, err = db.NewDropColumn().
+			Model((*OrderModel)(nil)).
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrdersPayment) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
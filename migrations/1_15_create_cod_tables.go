@@ -0,0 +1,102 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// CODCollectionModel là cấu trúc bảng cod_collections tại thời điểm tạo
+type CODCollectionModel struct {
+	bun.BaseModel `bun:"table:cod_collections,alias:codc"`
+
+	ID          string          `bun:"id,pk"`
+	DriverID    string          `bun:"driver_id,notnull"`
+	Day         string          `bun:"day,notnull"`
+	Amount      decimal.Decimal `bun:"amount,type:decimal(20,2),notnull"`
+	Currency    string          `bun:"currency,notnull"`
+	CollectedAt time.Time       `bun:"collected_at,notnull"`
+	RemittedAt  *time.Time      `bun:"remitted_at"`
+}
+
+// CODBalanceModel là cấu trúc bảng cod_balances tại thời điểm tạo
+type CODBalanceModel struct {
+	bun.BaseModel `bun:"table:cod_balances,alias:codb"`
+
+	DriverID        string          `bun:"driver_id,pk"`
+	Day             string          `bun:"day,pk"`
+	Currency        string          `bun:"currency,pk"`
+	CollectedOrders int             `bun:"collected_orders,notnull"`
+	CollectedAmount decimal.Decimal `bun:"collected_amount,type:decimal(20,2),notnull"`
+	RemittedOrders  int             `bun:"remitted_orders,notnull"`
+	RemittedAmount  decimal.Decimal `bun:"remitted_amount,type:decimal(20,2),notnull"`
+}
+
+// CODTables tạo các bảng cho projection đối soát tiền thu hộ theo tài xế và ngày
+type CODTables struct {
+	Version int
+}
+
+// codModels là các bảng đối soát theo thứ tự tạo
+func codModels() []interface{} {
+	return []interface{}{
+		(*CODCollectionModel)(nil),
+		(*CODBalanceModel)(nil),
+	}
+}
+
+func (m CODTables) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range codModels() {
+		_, err = db.NewCreateTable().
+			Model(model).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Khoản chưa nộp được tìm theo tài xế
+	_, err = db.NewCreateIndex().
+		Model((*CODCollectionModel)(nil)).
+		Index("idx_cod_collections_driver_id").
+		Column("driver_id", "remitted_at").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m CODTables) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range codModels() {
+		_, err = db.NewDropTable().
+			Model(model).
+			IfExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m CODTables) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrdersDriver thêm tài xế và phương tiện được phân công cho bảng orders
+type OrdersDriver struct {
+	Version int
+}
+
+// ordersDriverColumns là các cột được thêm, đơn hàng cũ chưa được phân công nên để NULL
+var ordersDriverColumns = []string{
+	"driver_id VARCHAR(36)",
+	"vehicle_id VARCHAR(36)",
+	"assigned_at TIMESTAMP",
+}
+
+func (m OrdersDriver) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range ordersDriverColumns {
+		_, err = addColumnIfNotExists(db, (*OrderModel)(nil), column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Index phục vụ việc lấy đơn hàng của tài xế theo trạng thái
+	_, err = db.NewCreateIndex().
+		Model((*OrderModel)(nil)).
+		Index("idx_orders_driver_id").
+		Column("driver_id", "status").
+		IfNotExists().
+		Exec(ctx)
+
+	return err
+}
+
+func (m OrdersDriver) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropIndex().
+		Model((*OrderModel)(nil)).
+		Index("idx_orders_driver_id").
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{"driver_id", "vehicle_id", "assigned_at"} {
+		_, err = db.NewDropColumn().
+			Model((*OrderModel)(nil)).
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrdersDriver) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
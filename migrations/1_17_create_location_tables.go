@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// DriverLocationModel là cấu trúc bảng driver_locations tại thời điểm tạo
+type DriverLocationModel struct {
+	bun.BaseModel `bun:"table:driver_locations,alias:dl"`
+
+	DriverID   string    `bun:"driver_id,pk"`
+	Latitude   float64   `bun:"latitude,notnull"`
+	Longitude  float64   `bun:"longitude,notnull"`
+	RecordedAt time.Time `bun:"recorded_at,notnull"`
+	ReceivedAt time.Time `bun:"received_at,notnull"`
+}
+
+// OrderLocationModel là cấu trúc bảng order_locations tại thời điểm tạo
+type OrderLocationModel struct {
+	bun.BaseModel `bun:"table:order_locations,alias:ol"`
+
+	OrderID         string     `bun:"order_id,pk"`
+	DriverID        string     `bun:"driver_id,notnull"`
+	Latitude        float64    `bun:"latitude,notnull"`
+	Longitude       float64    `bun:"longitude,notnull"`
+	RecordedAt      time.Time  `bun:"recorded_at,notnull"`
+	TrailLatitude   *float64   `bun:"trail_latitude"`
+	TrailLongitude  *float64   `bun:"trail_longitude"`
+	TrailStatus     string     `bun:"trail_status,nullzero"`
+	TrailRecordedAt *time.Time `bun:"trail_recorded_at"`
+}
+
+// LocationTables tạo các bảng cho projection vị trí của tài xế và đơn hàng
+type LocationTables struct {
+	Version int
+}
+
+// locationModels là các bảng vị trí theo thứ tự tạo
+func locationModels() []interface{} {
+	return []interface{}{
+		(*DriverLocationModel)(nil),
+		(*OrderLocationModel)(nil),
+	}
+}
+
+func (m LocationTables) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range locationModels() {
+		_, err = db.NewCreateTable().
+			Model(model).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m LocationTables) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range locationModels() {
+		_, err = db.NewDropTable().
+			Model(model).
+			IfExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m LocationTables) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
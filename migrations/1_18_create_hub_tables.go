@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// HubModel là cấu trúc bảng hubs tại thời điểm tạo
+type HubModel struct {
+	bun.BaseModel `bun:"table:hubs,alias:hub"`
+
+	Code              string    `bun:"code,pk"`
+	Name              string    `bun:"name,notnull"`
+	Latitude          float64   `bun:"latitude,notnull"`
+	Longitude         float64   `bun:"longitude,notnull"`
+	OpensAt           string    `bun:"opens_at,nullzero"`
+	ClosesAt          string    `bun:"closes_at,nullzero"`
+	DwellLimitMinutes int       `bun:"dwell_limit_minutes,notnull"`
+	Status            int       `bun:"status,notnull"`
+	CreatedTime       time.Time `bun:"created_time,notnull"`
+	UpdatedTime       time.Time `bun:"updated_time,notnull"`
+}
+
+// HubParcelModel là cấu trúc bảng hub_parcels tại thời điểm tạo
+type HubParcelModel struct {
+	bun.BaseModel `bun:"table:hub_parcels,alias:hp"`
+
+	OrderID     string    `bun:"order_id,pk"`
+	HubCode     string    `bun:"hub_code,notnull"`
+	ScannedInAt time.Time `bun:"scanned_in_at,notnull"`
+	Version     int       `bun:"version,notnull"`
+}
+
+// HubTables tạo bảng hub và projection đơn hàng đang nằm tại hub
+type HubTables struct {
+	Version int
+}
+
+// hubModels là các bảng hub theo thứ tự tạo
+func hubModels() []interface{} {
+	return []interface{}{
+		(*HubModel)(nil),
+		(*HubParcelModel)(nil),
+	}
+}
+
+func (m HubTables) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range hubModels() {
+		_, err = db.NewCreateTable().
+			Model(model).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Tồn kho và cảnh báo được lấy theo hub, lâu nhất trước
+	_, err = db.NewCreateIndex().
+		Model((*HubParcelModel)(nil)).
+		Index("idx_hub_parcels_hub_code").
+		Column("hub_code", "scanned_in_at").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m HubTables) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range hubModels() {
+		_, err = db.NewDropTable().
+			Model(model).
+			IfExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m HubTables) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
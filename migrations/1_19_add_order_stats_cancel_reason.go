@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrderStatsCancelReason lưu lý do hủy của từng đơn hàng trong projection thống kê
+// để thống kê hủy đơn hàng được ẩn khi khách hàng yêu cầu xóa dữ liệu cá nhân.
+// Đơn hàng đã hủy trước đó chỉ có lý do sau khi làm rỗng các bảng order_stats_* và chạy analytics:rebuild.
+type OrderStatsCancelReason struct {
+	Version int
+}
+
+func (m OrderStatsCancelReason) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = addColumnIfNotExists(db, (*OrderStatsModel)(nil), "cancel_reason VARCHAR(255)").
+		Exec(ctx)
+	return err
+}
+
+func (m OrderStatsCancelReason) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropColumn().
+		Model((*OrderStatsModel)(nil)).
+		ColumnExpr("cancel_reason").
+		Exec(ctx)
+	return err
+}
+
+func (m OrderStatsCancelReason) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
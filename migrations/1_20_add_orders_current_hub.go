@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrdersCurrentHub thêm hub đơn hàng đang nằm cho bảng orders, NULL khi đơn hàng không ở hub nào
+type OrdersCurrentHub struct {
+	Version int
+}
+
+func (m OrdersCurrentHub) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = addColumnIfNotExists(db, (*OrderModel)(nil), "current_hub VARCHAR(32)").
+		Exec(ctx)
+
+	return err
+}
+
+func (m OrdersCurrentHub) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropColumn().
+		Model((*OrderModel)(nil)).
+		ColumnExpr("current_hub").
+		Exec(ctx)
+
+	return err
+}
+
+func (m OrdersCurrentHub) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -2,13 +2,29 @@ package migrations
 
 import (
 	"context"
-	"github.com/quyenle-97/init/internal/models"
 	"reflect"
 	"time"
 
 	"github.com/uptrace/bun"
 )
 
+// OrderModel là cấu trúc bảng orders tại thời điểm tạo, các cột thêm sau nằm trong migration riêng
+type OrderModel struct {
+	bun.BaseModel `bun:"table:orders,alias:o"`
+
+	ID              string    `bun:"id,pk"`
+	CustomerID      string    `bun:"customer_id,notnull"`
+	TrackingNumber  string    `bun:"tracking_number,notnull,unique"`
+	Status          string    `bun:"status,notnull"`
+	OriginData      []byte    `bun:"origin_data,notnull"`
+	DestinationData []byte    `bun:"destination_data,notnull"`
+	CurrentLocData  []byte    `bun:"current_location_data"`
+	ItemsData       []byte    `bun:"items_data,notnull"`
+	NotesData       []byte    `bun:"notes_data,notnull"`
+	CreatedAt       time.Time `bun:"created_at,notnull"`
+	UpdatedAt       time.Time `bun:"updated_at,notnull"`
+}
+
 // ProjectionsTable định nghĩa các bảng cho modelss
 type ProjectionsTable struct {
 	Version int
@@ -20,7 +36,7 @@ func (m ProjectionsTable) Up(db *bun.DB) (err error) {
 
 	// Tạo bảng orders cho models đơn hàng
 	_, err = db.NewCreateTable().
-		Model((*models.OrderModel)(nil)).
+		Model((*OrderModel)(nil)).
 		IfNotExists().
 		Exec(ctx)
 	if err != nil {
@@ -29,7 +45,7 @@ func (m ProjectionsTable) Up(db *bun.DB) (err error) {
 
 	// Tạo các index cho bảng orders
 	_, err = db.NewCreateIndex().
-		Model((*models.OrderModel)(nil)).
+		Model((*OrderModel)(nil)).
 		Index("idx_orders_customer_id").
 		Column("customer_id").
 		IfNotExists().
@@ -39,7 +55,7 @@ func (m ProjectionsTable) Up(db *bun.DB) (err error) {
 	}
 
 	_, err = db.NewCreateIndex().
-		Model((*models.OrderModel)(nil)).
+		Model((*OrderModel)(nil)).
 		Index("idx_orders_tracking_number").
 		Column("tracking_number").
 		Unique().
@@ -50,7 +66,7 @@ func (m ProjectionsTable) Up(db *bun.DB) (err error) {
 	}
 
 	_, err = db.NewCreateIndex().
-		Model((*models.OrderModel)(nil)).
+		Model((*OrderModel)(nil)).
 		Index("idx_orders_status").
 		Column("status").
 		IfNotExists().
@@ -59,67 +75,6 @@ func (m ProjectionsTable) Up(db *bun.DB) (err error) {
 		return err
 	}
 
-	//// Tạo bảng tracking_info cho models theo dõi
-	//_, err = db.NewCreateTable().
-	//	Model((*models.TrackingModel)(nil)).
-	//	IfNotExists().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//// Tạo các index cho bảng tracking_info
-	//_, err = db.NewCreateIndex().
-	//	Model((*models.TrackingModel)(nil)).
-	//	Index("idx_tracking_order_id").
-	//	Column("order_id").
-	//	IfNotExists().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//_, err = db.NewCreateIndex().
-	//	Model((*models.TrackingModel)(nil)).
-	//	Index("idx_tracking_tracking_number").
-	//	Column("tracking_number").
-	//	Unique().
-	//	IfNotExists().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//// Tạo bảng tracking_updates cho cập nhật theo dõi
-	//_, err = db.NewCreateTable().
-	//	Model((*models.TrackingUpdateModel)(nil)).
-	//	IfNotExists().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//// Tạo index cho bảng tracking_updates
-	//_, err = db.NewCreateIndex().
-	//	Model((*models.TrackingUpdateModel)(nil)).
-	//	Index("idx_tracking_updates_tracking_id").
-	//	Column("tracking_id").
-	//	IfNotExists().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//_, err = db.NewCreateIndex().
-	//	Model((*models.TrackingUpdateModel)(nil)).
-	//	Index("idx_tracking_updates_timestamp").
-	//	Column("timestamp").
-	//	IfNotExists().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-
 	return nil
 }
 
@@ -127,27 +82,8 @@ func (m ProjectionsTable) Down(db *bun.DB) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
-	// Xóa các bảng theo thứ tự ngược lại để tránh lỗi khóa ngoại
-	//_, err = db.NewDropTable().
-	//	Model((*models.TrackingUpdateModel)(nil)).
-	//	IfExists().
-	//	Cascade().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//_, err = db.NewDropTable().
-	//	Model((*models.TrackingModel)(nil)).
-	//	IfExists().
-	//	Cascade().
-	//	Exec(ctx)
-	//if err != nil {
-	//	return err
-	//}
-
 	_, err = db.NewDropTable().
-		Model((*models.OrderModel)(nil)).
+		Model((*OrderModel)(nil)).
 		IfExists().
 		Cascade().
 		Exec(ctx)
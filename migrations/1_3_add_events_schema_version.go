@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// EventsSchemaVersion thêm cột schema_version cho bảng events
+type EventsSchemaVersion struct {
+	Version int
+}
+
+func (m EventsSchemaVersion) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Các sự kiện đã lưu trước đó đều thuộc schema phiên bản 1
+	_, err = addColumnIfNotExists(db, (*EventModel)(nil), "schema_version INTEGER NOT NULL DEFAULT 1").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m EventsSchemaVersion) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropColumn().
+		Model((*EventModel)(nil)).
+		ColumnExpr("schema_version").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m EventsSchemaVersion) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
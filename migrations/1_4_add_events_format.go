@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// EventsFormat thêm cột format cho bảng events để lưu định dạng serialize của từng sự kiện
+type EventsFormat struct {
+	Version int
+}
+
+func (m EventsFormat) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Các sự kiện đã lưu trước đó đều ở định dạng JSON
+	_, err = addColumnIfNotExists(db, (*EventModel)(nil), "format VARCHAR(16) NOT NULL DEFAULT 'json'").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m EventsFormat) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropColumn().
+		Model((*EventModel)(nil)).
+		ColumnExpr("format").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m EventsFormat) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// CustomerKeysTable tạo các bảng lưu khóa mã hóa dữ liệu cá nhân theo khách hàng
+type CustomerKeysTable struct {
+	Version int
+}
+
+type CustomerKeyModel struct {
+	bun.BaseModel `bun:"table:customer_keys,alias:ck"`
+
+	CustomerID string     `bun:"customer_id,pk"`
+	WrappedKey []byte     `bun:"wrapped_key"`
+	CreatedAt  time.Time  `bun:"created_at,notnull,default:current_timestamp"`
+	ShreddedAt *time.Time `bun:"shredded_at"`
+}
+
+type CustomerKeySubjectModel struct {
+	bun.BaseModel `bun:"table:customer_key_subjects,alias:cks"`
+
+	AggregateID string `bun:"aggregate_id,pk"`
+	CustomerID  string `bun:"customer_id,notnull"`
+}
+
+func (m CustomerKeysTable) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Tạo bảng customer_keys
+	_, err = db.NewCreateTable().
+		Model((*CustomerKeyModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Tạo bảng customer_key_subjects liên kết aggregate với khách hàng
+	_, err = db.NewCreateTable().
+		Model((*CustomerKeySubjectModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Tạo index cho tìm kiếm aggregate theo khách hàng
+	_, err = db.NewCreateIndex().
+		Model((*CustomerKeySubjectModel)(nil)).
+		Index("idx_customer_key_subjects_customer_id").
+		Column("customer_id").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m CustomerKeysTable) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropIndex().
+		Model((*CustomerKeySubjectModel)(nil)).
+		Index("idx_customer_key_subjects_customer_id").
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewDropTable().
+		Model((*CustomerKeySubjectModel)(nil)).
+		IfExists().
+		Cascade().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewDropTable().
+		Model((*CustomerKeyModel)(nil)).
+		IfExists().
+		Cascade().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m CustomerKeysTable) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrdersDeliveryEstimate thêm mức dịch vụ, thời gian giao hàng dự kiến và cờ vi phạm SLA cho bảng orders
+type OrdersDeliveryEstimate struct {
+	Version int
+}
+
+// ordersDeliveryEstimateColumns là các cột được thêm, đơn hàng cũ mặc định là STANDARD và chưa có ETA
+var ordersDeliveryEstimateColumns = []string{
+	"service_level VARCHAR(16) NOT NULL DEFAULT 'STANDARD'",
+	"estimated_delivery TIMESTAMP",
+	"promised_delivery TIMESTAMP",
+	"sla_breached BOOLEAN NOT NULL DEFAULT FALSE",
+}
+
+func (m OrdersDeliveryEstimate) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, column := range ordersDeliveryEstimateColumns {
+		_, err = addColumnIfNotExists(db, (*OrderModel)(nil), column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Index phục vụ việc tìm các đơn hàng quá thời gian cam kết
+	_, err = db.NewCreateIndex().
+		Model((*OrderModel)(nil)).
+		Index("idx_orders_promised_delivery").
+		Column("promised_delivery").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m OrdersDeliveryEstimate) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropIndex().
+		Model((*OrderModel)(nil)).
+		Index("idx_orders_promised_delivery").
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{"service_level", "estimated_delivery", "promised_delivery", "sla_breached"} {
+		_, err = db.NewDropColumn().
+			Model((*OrderModel)(nil)).
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrdersDeliveryEstimate) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
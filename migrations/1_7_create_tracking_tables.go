@@ -0,0 +1,109 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// TrackingModel là cấu trúc bảng tracking_info tại thời điểm tạo
+type TrackingModel struct {
+	bun.BaseModel `bun:"table:tracking_info,alias:ti"`
+
+	ID                string     `bun:"id,pk"`
+	TrackingNumber    string     `bun:"tracking_number,notnull,unique"`
+	Status            string     `bun:"status,notnull"`
+	ServiceLevel      string     `bun:"service_level,notnull"`
+	OriginCity        string     `bun:"origin_city,notnull"`
+	DestinationCity   string     `bun:"destination_city,notnull"`
+	CurrentCity       string     `bun:"current_city,nullzero"`
+	EstimatedDelivery *time.Time `bun:"estimated_delivery"`
+	Delayed           bool       `bun:"is_delayed,notnull"`
+	Version           int        `bun:"version,notnull"`
+	CreatedAt         time.Time  `bun:"created_at,notnull"`
+	UpdatedAt         time.Time  `bun:"updated_at,notnull"`
+}
+
+// TrackingUpdateModel là cấu trúc bảng tracking_updates tại thời điểm tạo
+type TrackingUpdateModel struct {
+	bun.BaseModel `bun:"table:tracking_updates,alias:tu"`
+
+	ID         string    `bun:"id,pk"`
+	TrackingID string    `bun:"tracking_id,notnull"`
+	Timestamp  time.Time `bun:"timestamp,notnull"`
+	Status     string    `bun:"status,nullzero"`
+	Location   string    `bun:"location,nullzero"`
+	Message    string    `bun:"message,notnull"`
+}
+
+// TrackingTables tạo các bảng cho projection theo dõi đơn hàng công khai
+type TrackingTables struct {
+	Version int
+}
+
+func (m TrackingTables) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewCreateTable().
+		Model((*TrackingModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewCreateTable().
+		Model((*TrackingUpdateModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Lịch trình được đọc theo tracking_id và sắp xếp theo thời gian
+	_, err = db.NewCreateIndex().
+		Model((*TrackingUpdateModel)(nil)).
+		Index("idx_tracking_updates_tracking_id").
+		Column("tracking_id", "timestamp").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m TrackingTables) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropTable().
+		Model((*TrackingUpdateModel)(nil)).
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewDropTable().
+		Model((*TrackingModel)(nil)).
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m TrackingTables) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
@@ -0,0 +1,144 @@
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OrdersPosition thêm tọa độ vị trí hiện tại cho bảng orders để tìm đơn hàng lân cận
+type OrdersPosition struct {
+	Version int
+}
+
+// ordersPositionBatch là số đơn hàng cũ được điền tọa độ trong một lần truy vấn
+const ordersPositionBatch = 500
+
+// orderPositionRow là các cột cần để tính vị trí của đơn hàng cũ
+type orderPositionRow struct {
+	ID             string `bun:"id"`
+	OriginData     []byte `bun:"origin_data"`
+	CurrentLocData []byte `bun:"current_location_data"`
+}
+
+// coordinates là phần tọa độ trong JSON vị trí của đơn hàng
+type coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (m OrdersPosition) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	for _, column := range []string{"position_latitude DOUBLE PRECISION", "position_longitude DOUBLE PRECISION"} {
+		_, err = addColumnIfNotExists(db, (*OrderModel)(nil), column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Index phục vụ việc lọc đơn hàng trong khung vĩ độ, kinh độ
+	_, err = db.NewCreateIndex().
+		Model((*OrderModel)(nil)).
+		Index("idx_orders_position").
+		Column("position_latitude", "position_longitude").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.backfill(ctx, db)
+}
+
+// backfill điền tọa độ cho đơn hàng đã có, đơn hàng mới được read model cập nhật khi xử lý sự kiện
+func (m OrdersPosition) backfill(ctx context.Context, db *bun.DB) error {
+	last := ""
+	for {
+		var rows []orderPositionRow
+		err := db.NewSelect().
+			Model((*OrderModel)(nil)).
+			Column("id", "origin_data", "current_location_data").
+			Where("id > ?", last).
+			Order("id ASC").
+			Limit(ordersPositionBatch).
+			Scan(ctx, &rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			position, ok := rowPosition(row)
+			if !ok {
+				continue
+			}
+			_, err = db.NewUpdate().
+				Table("orders").
+				Set("position_latitude = ?", position.Latitude).
+				Set("position_longitude = ?", position.Longitude).
+				Where("id = ?", row.ID).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < ordersPositionBatch {
+			return nil
+		}
+		last = rows[len(rows)-1].ID
+	}
+}
+
+// rowPosition lấy vị trí hiện tại nếu có tọa độ, ngược lại là điểm gửi
+func rowPosition(row orderPositionRow) (coordinates, bool) {
+	for _, data := range [][]byte{row.CurrentLocData, row.OriginData} {
+		var c coordinates
+		if len(data) == 0 || json.Unmarshal(data, &c) != nil {
+			continue
+		}
+		if c.Latitude != 0 || c.Longitude != 0 {
+			return c, true
+		}
+	}
+	return coordinates{}, false
+}
+
+func (m OrdersPosition) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropIndex().
+		Model((*OrderModel)(nil)).
+		Index("idx_orders_position").
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{"position_latitude", "position_longitude"} {
+		_, err = db.NewDropColumn().
+			Model((*OrderModel)(nil)).
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrdersPosition) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
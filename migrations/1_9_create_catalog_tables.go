@@ -0,0 +1,184 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+// CategoryModel là cấu trúc bảng categories tại thời điểm tạo
+type CategoryModel struct {
+	bun.BaseModel `bun:"table:categories,alias:cat"`
+
+	ID          string     `bun:"id,pk"`
+	Name        string     `bun:"name,notnull"`
+	Status      int        `bun:"status,notnull"`
+	CreatedTime time.Time  `bun:"created_time,notnull"`
+	UpdatedTime time.Time  `bun:"updated_time,notnull"`
+	DeletedTime *time.Time `bun:"deleted_time,nullzero"`
+}
+
+// CityModel là cấu trúc bảng cities tại thời điểm tạo
+type CityModel struct {
+	bun.BaseModel `bun:"table:cities,alias:ci"`
+
+	ID          string     `bun:"id,pk"`
+	Name        string     `bun:"name,notnull"`
+	Latitude    float64    `bun:"latitude,notnull"`
+	Longitude   float64    `bun:"longitude,notnull"`
+	CreatedTime time.Time  `bun:"created_time,notnull"`
+	UpdatedTime time.Time  `bun:"updated_time,notnull"`
+	DeletedTime *time.Time `bun:"deleted_time,nullzero"`
+}
+
+// SupplierModel là cấu trúc bảng suppliers tại thời điểm tạo
+type SupplierModel struct {
+	bun.BaseModel `bun:"table:suppliers,alias:sup"`
+
+	ID          string     `bun:"id,pk"`
+	Name        string     `bun:"name,notnull"`
+	Status      int        `bun:"status,notnull"`
+	CreatedTime time.Time  `bun:"created_time,notnull"`
+	UpdatedTime time.Time  `bun:"updated_time,notnull"`
+	DeletedTime *time.Time `bun:"deleted_time,nullzero"`
+}
+
+// ProductModel là cấu trúc bảng products tại thời điểm tạo
+type ProductModel struct {
+	bun.BaseModel `bun:"table:products,alias:p"`
+
+	ID          string          `bun:"id,pk"`
+	Reference   string          `bun:"reference,notnull,unique"`
+	Name        string          `bun:"name,notnull"`
+	Status      int             `bun:"status,notnull"`
+	Price       decimal.Decimal `bun:"price,type:decimal(15,2),notnull"`
+	Quantity    int             `bun:"quantity,notnull"`
+	CityID      string          `bun:"city_id,notnull"`
+	SupplierID  string          `bun:"supplier_id,notnull"`
+	CreatedTime time.Time       `bun:"created_time,notnull"`
+	UpdatedTime time.Time       `bun:"updated_time,notnull"`
+	DeletedTime *time.Time      `bun:"deleted_time,nullzero"`
+}
+
+// ProductCategoryModel là cấu trúc bảng product_categories tại thời điểm tạo.
+// bun tìm bảng nối của quan hệ nhiều-nhiều theo tên bảng nên model này không khai báo tên product_categories,
+// tên bảng được chỉ định bằng productCategoriesTable khi tạo và xóa.
+type ProductCategoryModel struct {
+	bun.BaseModel `bun:"alias:pc"`
+
+	ProductID  string `bun:"product_id,pk"`
+	CategoryID string `bun:"category_id,pk"`
+}
+
+const productCategoriesTable = "product_categories"
+
+// CatalogTables tạo các bảng danh mục, thành phố, nhà cung cấp và sản phẩm
+type CatalogTables struct {
+	Version int
+}
+
+func (m CatalogTables) Up(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for _, model := range []interface{}{
+		(*CategoryModel)(nil),
+		(*CityModel)(nil),
+		(*SupplierModel)(nil),
+		(*ProductModel)(nil),
+	} {
+		_, err = db.NewCreateTable().
+			Model(model).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = db.NewCreateTable().
+		Model((*ProductCategoryModel)(nil)).
+		ModelTableExpr(productCategoriesTable).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Sản phẩm được lọc theo thành phố, nhà cung cấp và danh mục
+	indexes := []struct {
+		model   interface{}
+		name    string
+		columns []string
+	}{
+		{(*ProductModel)(nil), "idx_products_city_id", []string{"city_id"}},
+		{(*ProductModel)(nil), "idx_products_supplier_id", []string{"supplier_id"}},
+		{(*ProductModel)(nil), "idx_products_created_time", []string{"created_time"}},
+	}
+	for _, index := range indexes {
+		_, err = db.NewCreateIndex().
+			Model(index.model).
+			Index(index.name).
+			Column(index.columns...).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = db.NewCreateIndex().
+		Model((*ProductCategoryModel)(nil)).
+		ModelTableExpr(productCategoriesTable).
+		Index("idx_product_categories_category_id").
+		Column("category_id").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m CatalogTables) Down(db *bun.DB) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, err = db.NewDropTable().
+		Model((*ProductCategoryModel)(nil)).
+		ModelTableExpr(productCategoriesTable).
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{
+		(*ProductModel)(nil),
+		(*SupplierModel)(nil),
+		(*CityModel)(nil),
+		(*CategoryModel)(nil),
+	} {
+		_, err = db.NewDropTable().
+			Model(model).
+			IfExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m CatalogTables) GetStructName() string {
+	if t := reflect.TypeOf(m); t.Kind() == reflect.Ptr {
+		return t.Elem().Name()
+	} else {
+		return t.Name()
+	}
+}
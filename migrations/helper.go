@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/feature"
+)
+
+// addColumnIfNotExists tạo câu lệnh thêm cột, chỉ dùng IF NOT EXISTS khi dialect hỗ trợ (MySQL và SQLite không hỗ trợ)
+func addColumnIfNotExists(db *bun.DB, model interface{}, columnExpr string) *bun.AddColumnQuery {
+	query := db.NewAddColumn().
+		Model(model).
+		ColumnExpr(columnExpr)
+	if db.HasFeature(feature.AlterColumnExists) {
+		query = query.IfNotExists()
+	}
+	return query
+}
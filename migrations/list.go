@@ -6,5 +6,23 @@ func MigrationLists() []rdbms.MFile {
 	return []rdbms.MFile{
 		EventsTable{},
 		ProjectionsTable{},
+		EventsSchemaVersion{},
+		EventsFormat{},
+		CustomerKeysTable{},
+		OrdersDeliveryEstimate{},
+		TrackingTables{},
+		OrdersPosition{},
+		CatalogTables{},
+		SuppliersLocation{},
+		OrderStatsTables{},
+		ProductsWeight{},
+		OrdersTotals{},
+		OrdersPayment{},
+		CODTables{},
+		OrdersDriver{},
+		LocationTables{},
+		HubTables{},
+		OrderStatsCancelReason{},
+		OrdersCurrentHub{},
 	}
 }
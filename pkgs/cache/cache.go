@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache lưu giá trị dạng JSON theo key với thời gian sống
+type Cache interface {
+	// Get giải mã giá trị của key vào v, trả về false khi key không tồn tại hoặc đã hết hạn
+	Get(ctx context.Context, key string, v interface{}) (bool, error)
+	Set(ctx context.Context, key string, v interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type redisCache struct {
+	client redis.UniversalClient
+}
+
+// NewRedisCache tạo Cache trên Redis
+func NewRedisCache(client redis.UniversalClient) Cache {
+	return &redisCache{client: client}
+}
+
+func (c *redisCache) Get(ctx context.Context, key string, v interface{}) (bool, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, data, ttl).Err()
+}
+
+func (c *redisCache) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+type memoryCache struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entries map[string]memoryEntry
+}
+
+// NewMemoryCache tạo Cache trong bộ nhớ của tiến trình, dùng cho kiểm thử hoặc chạy local không có Redis
+func NewMemoryCache() Cache {
+	return &memoryCache{now: time.Now, entries: make(map[string]memoryEntry)}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string, v interface{}) (bool, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	entry := memoryEntry{data: data}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	return nil
+}
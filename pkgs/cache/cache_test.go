@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)
+	c := &memoryCache{now: func() time.Time { return now }, entries: make(map[string]memoryEntry)}
+
+	type point struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+	if err := c.Set(ctx, "city:1", point{21.0285, 105.8542}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set(ctx, "city:2", point{10.8231, 106.6297}, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var got point
+	if ok, err := c.Get(ctx, "city:1", &got); err != nil || !ok || got.Latitude != 21.0285 {
+		t.Fatalf("Get = %+v, %v, %v", got, ok, err)
+	}
+	if ok, _ := c.Get(ctx, "city:3", &got); ok {
+		t.Fatal("key không tồn tại trả về true")
+	}
+
+	// Hết hạn sau ttl, ttl 0 không hết hạn
+	now = now.Add(time.Minute)
+	if ok, _ := c.Get(ctx, "city:1", &got); ok {
+		t.Fatal("key hết hạn trả về true")
+	}
+	if ok, _ := c.Get(ctx, "city:2", &got); !ok {
+		t.Fatal("key không có ttl bị hết hạn")
+	}
+
+	if err := c.Delete(ctx, "city:2"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ok, _ := c.Get(ctx, "city:2", &got); ok {
+		t.Fatal("key đã xóa trả về true")
+	}
+}
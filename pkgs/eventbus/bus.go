@@ -53,12 +53,7 @@ func (b *InMemoryEventBus) Publish(event domain.Event) error {
 func (b *InMemoryEventBus) Subscribe(handler EventHandler, eventTypes ...domain.EventType) error {
 	// Nếu không có loại sự kiện nào được chỉ định, đăng ký cho tất cả các loại
 	if len(eventTypes) == 0 {
-		eventTypes = []domain.EventType{
-			domain.OrderCreatedType,
-			domain.OrderStatusUpdatedType,
-			domain.OrderCancelledType,
-			domain.OrderNoteAddedType,
-		}
+		eventTypes = domain.RegisteredEventTypes()
 	}
 
 	for _, eventType := range eventTypes {
@@ -75,12 +70,7 @@ func (b *InMemoryEventBus) Subscribe(handler EventHandler, eventTypes ...domain.
 func (b *InMemoryEventBus) Unsubscribe(handler EventHandler, eventTypes ...domain.EventType) error {
 	// Nếu không có loại sự kiện nào được chỉ định, hủy đăng ký khỏi tất cả các loại
 	if len(eventTypes) == 0 {
-		eventTypes = []domain.EventType{
-			domain.OrderCreatedType,
-			domain.OrderStatusUpdatedType,
-			domain.OrderCancelledType,
-			domain.OrderNoteAddedType,
-		}
+		eventTypes = domain.RegisteredEventTypes()
 	}
 
 	for _, eventType := range eventTypes {
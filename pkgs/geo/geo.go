@@ -0,0 +1,78 @@
+// Package geo chứa các phép tính khoảng cách, hướng và tiến độ hành trình trên bề mặt Trái Đất
+package geo
+
+import "math"
+
+// EarthRadiusKm là bán kính trung bình của Trái Đất
+const EarthRadiusKm = 6371.0
+
+// Point là một tọa độ theo độ
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// IsZero kiểm tra tọa độ chưa được khai báo (0, 0)
+func (p Point) IsZero() bool {
+	return p.Latitude == 0 && p.Longitude == 0
+}
+
+// Valid kiểm tra tọa độ nằm trong giới hạn vĩ độ và kinh độ
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// DistanceKm tính khoảng cách đường tròn lớn giữa hai điểm theo công thức haversine
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BearingDeg tính hướng ban đầu từ a tới b theo độ, 0 là hướng bắc, tăng theo chiều kim đồng hồ, trong khoảng [0, 360)
+func BearingDeg(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLon := radians(b.Longitude - a.Longitude)
+
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// ProgressPct tính phần trăm hành trình đã đi từ origin qua current tới destination.
+// Quãng đã đi được so với tổng quãng đã đi và quãng còn lại nên vị trí lệch khỏi đường thẳng
+// (đi vòng qua hub) không làm tiến độ vượt 100 hay giảm xuống dưới 0.
+func ProgressPct(origin, current, destination Point) float64 {
+	travelled := DistanceKm(origin, current)
+	remaining := DistanceKm(current, destination)
+	if travelled+remaining == 0 {
+		return 100
+	}
+	return travelled / (travelled + remaining) * 100
+}
+
+// BoundingBox trả về khung vĩ độ, kinh độ bao quanh hình tròn tâm center bán kính radiusKm,
+// dùng để lọc sơ bộ bằng index trước khi tính khoảng cách chính xác.
+// Khung không xử lý trường hợp vắt qua kinh tuyến 180.
+func BoundingBox(center Point, radiusKm float64) (min, max Point) {
+	dLat := degrees(radiusKm / EarthRadiusKm)
+	dLon := 180.0
+	if cos := math.Cos(radians(center.Latitude)); cos > 1e-9 {
+		dLon = math.Min(180, degrees(radiusKm/(EarthRadiusKm*cos)))
+	}
+
+	min = Point{Latitude: math.Max(-90, center.Latitude-dLat), Longitude: math.Max(-180, center.Longitude-dLon)}
+	max = Point{Latitude: math.Min(90, center.Latitude+dLat), Longitude: math.Min(180, center.Longitude+dLon)}
+	return min, max
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	hanoi := Point{Latitude: 21.0285, Longitude: 105.8542}
+	hcm := Point{Latitude: 10.8231, Longitude: 106.6297}
+
+	if got := DistanceKm(hanoi, hanoi); got != 0 {
+		t.Fatalf("khoảng cách tới chính nó = %v, muốn 0", got)
+	}
+
+	got := DistanceKm(hanoi, hcm)
+	if math.Abs(got-1137) > 5 {
+		t.Fatalf("Hà Nội - TP.HCM = %.1f km, muốn khoảng 1137 km", got)
+	}
+	if back := DistanceKm(hcm, hanoi); math.Abs(back-got) > 1e-9 {
+		t.Fatalf("khoảng cách không đối xứng: %v != %v", back, got)
+	}
+}
+
+func TestBearingDeg(t *testing.T) {
+	origin := Point{Latitude: 10, Longitude: 106}
+	cases := []struct {
+		name string
+		to   Point
+		want float64
+	}{
+		{"bắc", Point{Latitude: 11, Longitude: 106}, 0},
+		{"đông", Point{Latitude: 10, Longitude: 107}, 89.9},
+		{"nam", Point{Latitude: 9, Longitude: 106}, 180},
+		{"tây", Point{Latitude: 10, Longitude: 105}, 270.1},
+	}
+	for _, c := range cases {
+		if got := BearingDeg(origin, c.to); math.Abs(got-c.want) > 0.2 {
+			t.Errorf("hướng %s = %.2f, muốn khoảng %.1f", c.name, got, c.want)
+		}
+	}
+}
+
+func TestProgressPct(t *testing.T) {
+	origin := Point{Latitude: 10.7769, Longitude: 106.7009}
+	destination := Point{Latitude: 21.0245, Longitude: 105.8412}
+	midway := Point{Latitude: (origin.Latitude + destination.Latitude) / 2, Longitude: (origin.Longitude + destination.Longitude) / 2}
+
+	if got := ProgressPct(origin, origin, destination); got != 0 {
+		t.Fatalf("tại điểm gửi = %v, muốn 0", got)
+	}
+	if got := ProgressPct(origin, destination, destination); got != 100 {
+		t.Fatalf("tại điểm nhận = %v, muốn 100", got)
+	}
+	if got := ProgressPct(origin, midway, destination); math.Abs(got-50) > 1 {
+		t.Fatalf("giữa đường = %v, muốn khoảng 50", got)
+	}
+	if got := ProgressPct(origin, origin, origin); got != 100 {
+		t.Fatalf("điểm gửi trùng điểm nhận = %v, muốn 100", got)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	center := Point{Latitude: 21.0285, Longitude: 105.8542}
+	min, max := BoundingBox(center, 10)
+
+	for _, p := range []Point{
+		{Latitude: min.Latitude, Longitude: center.Longitude},
+		{Latitude: max.Latitude, Longitude: center.Longitude},
+		{Latitude: center.Latitude, Longitude: min.Longitude},
+		{Latitude: center.Latitude, Longitude: max.Longitude},
+	} {
+		if d := DistanceKm(center, p); math.Abs(d-10) > 0.05 {
+			t.Fatalf("cạnh khung cách tâm %.3f km, muốn 10 km", d)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package geoip
+
+import (
+	"strings"
+	"unicode"
+)
+
+// city là một mục trong bảng thành phố đi kèm, tọa độ là trung tâm hành chính
+type city struct {
+	Name      string
+	Aliases   []string
+	Latitude  float64
+	Longitude float64
+}
+
+// cities gồm 63 tỉnh, thành phố của Việt Nam cùng tên thường gặp của thành phố trực thuộc tỉnh
+var cities = []city{
+	{"Hà Nội", []string{"Hanoi", "HN"}, 21.0285, 105.8542},
+	{"Hồ Chí Minh", []string{"Ho Chi Minh City", "HCM", "TPHCM", "HCMC", "Sài Gòn", "Saigon"}, 10.8231, 106.6297},
+	{"Hải Phòng", []string{"Haiphong"}, 20.8449, 106.6881},
+	{"Đà Nẵng", []string{"Danang"}, 16.0544, 108.2022},
+	{"Cần Thơ", []string{"Cantho"}, 10.0452, 105.7469},
+	{"An Giang", []string{"Long Xuyên"}, 10.3864, 105.4352},
+	{"Bà Rịa - Vũng Tàu", []string{"Bà Rịa", "BRVT"}, 10.4963, 107.1684},
+	{"Vũng Tàu", []string{"Vungtau"}, 10.3460, 107.0843},
+	{"Bắc Giang", nil, 21.2731, 106.1946},
+	{"Bắc Kạn", []string{"Bắc Cạn"}, 22.1470, 105.8348},
+	{"Bạc Liêu", nil, 9.2941, 105.7278},
+	{"Bắc Ninh", nil, 21.1861, 106.0763},
+	{"Bến Tre", nil, 10.2415, 106.3759},
+	{"Bình Định", []string{"Quy Nhơn", "Qui Nhơn"}, 13.7830, 109.2197},
+	{"Bình Dương", []string{"Thủ Dầu Một"}, 10.9804, 106.6519},
+	{"Bình Phước", []string{"Đồng Xoài"}, 11.5349, 106.8832},
+	{"Bình Thuận", []string{"Phan Thiết"}, 10.9289, 108.1021},
+	{"Cà Mau", nil, 9.1769, 105.1524},
+	{"Cao Bằng", nil, 22.6657, 106.2577},
+	{"Đắk Lắk", []string{"Đắc Lắc", "Buôn Ma Thuột", "Ban Mê Thuột"}, 12.6667, 108.0500},
+	{"Đắk Nông", []string{"Gia Nghĩa"}, 12.0045, 107.6874},
+	{"Điện Biên", []string{"Điện Biên Phủ"}, 21.3860, 103.0230},
+	{"Đồng Nai", []string{"Biên Hòa"}, 10.9574, 106.8429},
+	{"Đồng Tháp", []string{"Cao Lãnh"}, 10.4602, 105.6329},
+	{"Gia Lai", []string{"Pleiku"}, 13.9833, 108.0000},
+	{"Hà Giang", nil, 22.8233, 104.9836},
+	{"Hà Nam", []string{"Phủ Lý"}, 20.5411, 105.9139},
+	{"Hà Tĩnh", nil, 18.3428, 105.9057},
+	{"Hải Dương", nil, 20.9373, 106.3146},
+	{"Hậu Giang", []string{"Vị Thanh"}, 9.7845, 105.4701},
+	{"Hòa Bình", nil, 20.8133, 105.3383},
+	{"Hưng Yên", nil, 20.6464, 106.0511},
+	{"Khánh Hòa", []string{"Nha Trang"}, 12.2388, 109.1967},
+	{"Kiên Giang", []string{"Rạch Giá"}, 10.0125, 105.0809},
+	{"Phú Quốc", nil, 10.2270, 103.9637},
+	{"Kon Tum", nil, 14.3545, 108.0076},
+	{"Lai Châu", nil, 22.3964, 103.4582},
+	{"Lâm Đồng", []string{"Đà Lạt", "Dalat"}, 11.9404, 108.4583},
+	{"Lạng Sơn", nil, 21.8537, 106.7615},
+	{"Lào Cai", []string{"Sa Pa", "Sapa"}, 22.4856, 103.9707},
+	{"Long An", []string{"Tân An"}, 10.5360, 106.4137},
+	{"Nam Định", nil, 20.4200, 106.1683},
+	{"Nghệ An", []string{"Vinh"}, 18.6796, 105.6813},
+	{"Ninh Bình", nil, 20.2506, 105.9745},
+	{"Ninh Thuận", []string{"Phan Rang", "Phan Rang - Tháp Chàm"}, 11.5646, 108.9886},
+	{"Phú Thọ", []string{"Việt Trì"}, 21.3227, 105.4019},
+	{"Phú Yên", []string{"Tuy Hòa"}, 13.0955, 109.3209},
+	{"Quảng Bình", []string{"Đồng Hới"}, 17.4689, 106.6223},
+	{"Quảng Nam", []string{"Tam Kỳ", "Hội An"}, 15.5736, 108.4740},
+	{"Quảng Ngãi", nil, 15.1214, 108.8044},
+	{"Quảng Ninh", []string{"Hạ Long", "Halong"}, 20.9599, 107.0425},
+	{"Quảng Trị", []string{"Đông Hà"}, 16.8163, 107.1003},
+	{"Sóc Trăng", nil, 9.6025, 105.9739},
+	{"Sơn La", nil, 21.3256, 103.9188},
+	{"Tây Ninh", nil, 11.3100, 106.0983},
+	{"Thái Bình", nil, 20.4463, 106.3366},
+	{"Thái Nguyên", nil, 21.5942, 105.8482},
+	{"Thanh Hóa", nil, 19.8067, 105.7852},
+	{"Thừa Thiên Huế", []string{"Huế", "Hue"}, 16.4637, 107.5909},
+	{"Tiền Giang", []string{"Mỹ Tho"}, 10.3600, 106.3600},
+	{"Trà Vinh", nil, 9.9347, 106.3453},
+	{"Tuyên Quang", nil, 21.8237, 105.2140},
+	{"Vĩnh Long", nil, 10.2396, 105.9572},
+	{"Vĩnh Phúc", []string{"Vĩnh Yên"}, 21.3089, 105.6049},
+	{"Yên Bái", nil, 21.7051, 104.8800},
+}
+
+// cityIndex tra cứu thành phố theo tên đã chuẩn hóa
+var cityIndex = buildCityIndex()
+
+func buildCityIndex() map[string]city {
+	index := make(map[string]city, len(cities)*2)
+	for _, c := range cities {
+		index[normalizeCity(c.Name)] = c
+		for _, alias := range c.Aliases {
+			index[normalizeCity(alias)] = c
+		}
+	}
+	return index
+}
+
+// LookupCity tra cứu tọa độ của thành phố trong bảng đi kèm, không phân biệt hoa thường, dấu
+// và tiền tố như "TP.", "Thành phố", "Tỉnh"
+func LookupCity(name string) (Place, bool) {
+	c, ok := cityIndex[normalizeCity(name)]
+	if !ok {
+		return Place{}, false
+	}
+	return Place{Country: "VN", City: c.Name, Latitude: c.Latitude, Longitude: c.Longitude}, true
+}
+
+// cityPrefixes là các tiền tố hành chính bị bỏ qua, đã bỏ dấu
+var cityPrefixes = []string{"thanh pho ", "tp. ", "tp.", "tp ", "tinh ", "city of "}
+
+// cityFold bỏ dấu tiếng Việt
+var cityFold = strings.NewReplacer(
+	"à", "a", "á", "a", "ạ", "a", "ả", "a", "ã", "a",
+	"â", "a", "ầ", "a", "ấ", "a", "ậ", "a", "ẩ", "a", "ẫ", "a",
+	"ă", "a", "ằ", "a", "ắ", "a", "ặ", "a", "ẳ", "a", "ẵ", "a",
+	"è", "e", "é", "e", "ẹ", "e", "ẻ", "e", "ẽ", "e",
+	"ê", "e", "ề", "e", "ế", "e", "ệ", "e", "ể", "e", "ễ", "e",
+	"ì", "i", "í", "i", "ị", "i", "ỉ", "i", "ĩ", "i",
+	"ò", "o", "ó", "o", "ọ", "o", "ỏ", "o", "õ", "o",
+	"ô", "o", "ồ", "o", "ố", "o", "ộ", "o", "ổ", "o", "ỗ", "o",
+	"ơ", "o", "ờ", "o", "ớ", "o", "ợ", "o", "ở", "o", "ỡ", "o",
+	"ù", "u", "ú", "u", "ụ", "u", "ủ", "u", "ũ", "u",
+	"ư", "u", "ừ", "u", "ứ", "u", "ự", "u", "ử", "u", "ữ", "u",
+	"ỳ", "y", "ý", "y", "ỵ", "y", "ỷ", "y", "ỹ", "y",
+	"đ", "d",
+)
+
+// normalizeCity chuẩn hóa tên thành phố: chữ thường, bỏ dấu, bỏ tiền tố hành chính và ký tự không phải chữ số
+func normalizeCity(name string) string {
+	name = cityFold.Replace(strings.ToLower(strings.TrimSpace(name)))
+	for _, prefix := range cityPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	name = strings.TrimSuffix(name, " city")
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,70 @@
+// Package geoip tra cứu vị trí theo địa chỉ IP (file MaxMind GeoIP2/GeoLite2 City)
+// hoặc theo tên thành phố (bảng thành phố đi kèm)
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Place là kết quả tra cứu vị trí
+type Place struct {
+	Country   string // mã quốc gia ISO 3166-1, ví dụ VN
+	City      string
+	Latitude  float64
+	Longitude float64
+}
+
+// HasCoordinates kiểm tra kết quả có tọa độ
+func (p Place) HasCoordinates() bool {
+	return p.Latitude != 0 || p.Longitude != 0
+}
+
+// cityLanguages là thứ tự ngôn ngữ được chọn cho tên thành phố
+var cityLanguages = []string{"vi", "en"}
+
+// Reader tra cứu vị trí của địa chỉ IP trong file MaxMind City
+type Reader struct {
+	db *geoip2.Reader
+}
+
+// Open mở file .mmdb, gọi Close khi không dùng nữa
+func Open(path string) (*Reader, error) {
+	db, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi mở cơ sở dữ liệu GeoIP: %w", err)
+	}
+	return &Reader{db: db}, nil
+}
+
+// LookupIP tra cứu vị trí của địa chỉ IP, trả về false khi không có trong cơ sở dữ liệu
+func (r *Reader) LookupIP(ip net.IP) (Place, bool) {
+	if ip == nil {
+		return Place{}, false
+	}
+
+	record, err := r.db.City(ip)
+	if err != nil || record.Country.IsoCode == "" {
+		return Place{}, false
+	}
+
+	place := Place{
+		Country:   record.Country.IsoCode,
+		Latitude:  record.Location.Latitude,
+		Longitude: record.Location.Longitude,
+	}
+	for _, language := range cityLanguages {
+		if name := record.City.Names[language]; name != "" {
+			place.City = name
+			break
+		}
+	}
+	return place, true
+}
+
+// Close giải phóng file cơ sở dữ liệu
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
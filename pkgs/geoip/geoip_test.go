@@ -0,0 +1,79 @@
+package geoip
+
+import (
+	"net"
+	"testing"
+)
+
+// testdata/GeoIP2-City-Test.mmdb được sinh bởi testdata/gen
+func openFixture(t *testing.T) *Reader {
+	t.Helper()
+	reader, err := Open("testdata/GeoIP2-City-Test.mmdb")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { reader.Close() })
+	return reader
+}
+
+func TestLookupIP(t *testing.T) {
+	reader := openFixture(t)
+
+	cases := []struct {
+		ip   string
+		want Place
+	}{
+		{"203.0.113.10", Place{Country: "VN", City: "Thành phố Hồ Chí Minh", Latitude: 10.8231, Longitude: 106.6297}},
+		{"198.51.100.7", Place{Country: "VN", City: "Hà Nội", Latitude: 21.0285, Longitude: 105.8542}},
+		{"192.0.2.1", Place{Country: "SG", City: "Singapore", Latitude: 1.2897, Longitude: 103.8501}},
+		{"192.0.2.200", Place{Country: "VN"}},
+		{"2001:db8::1", Place{Country: "VN", City: "Đà Nẵng", Latitude: 16.0544, Longitude: 108.2022}},
+	}
+	for _, c := range cases {
+		got, ok := reader.LookupIP(net.ParseIP(c.ip))
+		if !ok || got != c.want {
+			t.Errorf("LookupIP(%s) = %+v, %v, muốn %+v", c.ip, got, ok, c.want)
+		}
+	}
+
+	for _, ip := range []net.IP{net.ParseIP("8.8.8.8"), nil} {
+		if got, ok := reader.LookupIP(ip); ok {
+			t.Errorf("LookupIP(%v) = %+v, muốn không tìm thấy", ip, got)
+		}
+	}
+}
+
+func TestOpenRejectsMissingFile(t *testing.T) {
+	if _, err := Open("testdata/missing.mmdb"); err == nil {
+		t.Fatal("Open file không tồn tại không trả về lỗi")
+	}
+}
+
+func TestLookupCity(t *testing.T) {
+	for _, name := range []string{"Hồ Chí Minh", "TP. Hồ Chí Minh", "Thành phố Hồ Chí Minh", "ho chi minh city", "TPHCM", "Sài Gòn"} {
+		place, ok := LookupCity(name)
+		if !ok || place.City != "Hồ Chí Minh" || place.Country != "VN" || !place.HasCoordinates() {
+			t.Errorf("LookupCity(%q) = %+v, %v", name, place, ok)
+		}
+	}
+
+	if place, ok := LookupCity("Đà Lạt"); !ok || place.City != "Lâm Đồng" {
+		t.Errorf("LookupCity(Đà Lạt) = %+v, %v", place, ok)
+	}
+	if _, ok := LookupCity("Atlantis"); ok {
+		t.Error("LookupCity(Atlantis) tìm thấy thành phố không có trong bảng")
+	}
+}
+
+func TestCityTableHasNoConflictingNames(t *testing.T) {
+	seen := make(map[string]string)
+	for _, c := range cities {
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			key := normalizeCity(name)
+			if other, ok := seen[key]; ok && other != c.Name {
+				t.Errorf("%q của %s trùng với %s", name, c.Name, other)
+			}
+			seen[key] = c.Name
+		}
+	}
+}
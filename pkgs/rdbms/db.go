@@ -12,6 +12,7 @@ type DBDriver string
 const (
 	DBDriverPostgres DBDriver = "pg"
 	DBDriverMysql    DBDriver = "mysql"
+	DBDriverSQLite   DBDriver = "sqlite"
 )
 
 type DB struct {
@@ -35,6 +36,8 @@ func NewDB(driver string, cfg interface{}, debug bool) (*bun.DB, error) {
 		return MakePGConnect(c, debug)
 	case DBDriverMysql:
 		return MakeMysqlConnect(c, debug)
+	case DBDriverSQLite:
+		return MakeSQLiteConnect(c, debug)
 	default:
 		return nil, errors.New(fmt.Sprintf("%v driver not suppored", driver))
 	}
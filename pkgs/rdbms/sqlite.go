@@ -0,0 +1,41 @@
+package rdbms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"github.com/uptrace/bun/extra/bundebug"
+	"time"
+)
+
+// MakeSQLiteConnect mở cơ sở dữ liệu SQLite, DB_NAME là đường dẫn file hoặc ":memory:".
+// Chỉ dùng cho kiểm thử và chạy local.
+func MakeSQLiteConnect(cfg DB, debug bool) (*bun.DB, error) {
+	dsn := cfg.DBName
+	if dsn == "" {
+		dsn = "file::memory:?cache=shared"
+	}
+
+	sqlDB, err := sql.Open(sqliteshim.ShimName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite chỉ cho phép một kết nối ghi tại một thời điểm
+	sqlDB.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if debug {
+		db.AddQueryHook(bundebug.NewQueryHook(bundebug.WithVerbose(true)))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err = db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
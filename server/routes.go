@@ -1,44 +1,63 @@
 package server
 
 import (
+	"context"
 	"github.com/gorilla/mux"
 	"github.com/quyenle-97/init/cfg"
-	"github.com/quyenle-97/init/internal/domain"
-	"github.com/quyenle-97/init/internal/eventstore"
 	"github.com/quyenle-97/init/internal/kit/endpoints"
-	"github.com/quyenle-97/init/internal/kit/services"
 	"github.com/quyenle-97/init/internal/kit/transports"
-	"github.com/quyenle-97/init/internal/repository"
-	"github.com/quyenle-97/init/pkgs/eventbus"
 	"github.com/quyenle-97/init/pkgs/log"
+	"github.com/redis/go-redis/v9"
 	"github.com/uptrace/bun"
 	"net/http"
 )
 
 // SetupLogisticsRoutes cấu hình các route liên quan đến logistics
-func SetupLogisticsRoutes(r *mux.Router, db *bun.DB, logger *log.MultiLogger, c cfg.Config) *mux.Router {
-	// Khởi tạo event store
-	eventStore := eventstore.NewPostgresEventStore(db)
+func SetupLogisticsRoutes(r *mux.Router, db *bun.DB, logger *log.MultiLogger, c cfg.Config, rdb redis.UniversalClient) *mux.Router {
+	s, err := NewServices(db, c, rdb)
+	if err != nil {
+		panic(err)
+	}
 
-	// Khởi tạo event bus
-	bus := eventbus.NewInMemoryEventBus()
+	// Header chuyển tiếp IP client chỉ được tin khi request đi qua reverse proxy đã cấu hình
+	proxies, err := c.TrustedProxyNets()
+	if err != nil {
+		panic(err)
+	}
+	r.Use(transports.TrustProxies(proxies))
 
-	// Khởi tạo order repository (kết hợp cả repository và projection)
-	orderRepo := repository.NewOrderRepository(db)
-	//trackingProjection := projection.NewPostgresTrackingProjection(db)
-
-	// Đăng ký các projections với event bus
-	bus.Subscribe(orderRepo, domain.OrderCreatedType, domain.OrderStatusUpdatedType,
-		domain.OrderCancelledType, domain.OrderNoteAddedType)
-
-	// Khởi tạo service
-	orderService := services.NewOrderService(eventStore, orderRepo, bus)
+	// Kiểm tra định kỳ các đơn hàng quá thời gian giao hàng cam kết
+	if interval := c.SLACheckInterval(); interval > 0 {
+		go s.SLA.Run(context.Background(), interval)
+	}
 
 	// Khởi tạo endpoints
-	orderEndpoints := endpoints.NewOrderEndpoints(orderService)
+	orderEndpoints := endpoints.NewOrderEndpoints(s.Order)
+	importEndpoints := endpoints.NewImportEndpoints(s.Import)
+	trackingEndpoints := endpoints.NewTrackingEndpoints(s.Tracking)
 
-	// Đăng ký HTTP handlers
+	// Đăng ký HTTP handlers, route tĩnh dưới /orders phải đăng ký trước /orders/{id}
+	transports.MakeImportHandlers(r, importEndpoints, c.BasePath+"logistics")
+	transports.MakePaymentHandlers(r, endpoints.NewPaymentEndpoints(s.Payment), c.BasePath+"logistics")
+	transports.MakeFleetHandlers(r, endpoints.NewFleetEndpoints(s.Fleet), c.BasePath+"logistics")
+	transports.MakeLocationHandlers(r, endpoints.NewLocationEndpoints(s.Location), c.BasePath+"logistics")
+	transports.MakeRouteHandlers(r, endpoints.NewRouteEndpoints(s.Route), c.BasePath+"logistics")
+	transports.MakeHubHandlers(r, endpoints.NewHubEndpoints(s.Hub), c.BasePath+"logistics")
 	transports.MakeOrderHandlers(r, orderEndpoints, c.BasePath+"logistics")
+	transports.MakeTrackingHandlers(r, trackingEndpoints, c.BasePath+"logistics")
+
+	// Xóa dữ liệu cá nhân chỉ khả dụng khi dữ liệu cá nhân được mã hóa
+	if s.Privacy != nil {
+		privacyEndpoints := endpoints.NewPrivacyEndpoints(s.Privacy)
+		transports.MakePrivacyHandlers(r, privacyEndpoints, c.BasePath+"logistics")
+	}
+
+	// Danh mục, thành phố, nhà cung cấp và sản phẩm theo swagger.yaml
+	transports.MakeCategoryHandlers(r, endpoints.NewCategoryEndpoints(s.Category), c.BasePath)
+	transports.MakeCityHandlers(r, endpoints.NewCityEndpoints(s.City), c.BasePath)
+	transports.MakeSupplierHandlers(r, endpoints.NewSupplierEndpoints(s.Supplier), c.BasePath)
+	transports.MakeProductHandlers(r, endpoints.NewProductEndpoints(s.Product), c.BasePath)
+	transports.MakeStatisticsHandlers(r, endpoints.NewStatisticsEndpoints(s.Statistics), c.BasePath)
 
 	// Tạo subrouter cho logistics API
 
@@ -19,6 +19,10 @@ func MuxRecovery(logger *log.MultiLogger) Middleware {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					// Handler chủ động ngắt kết nối khi đang ghi dở response
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
 					utils.ResponseWriter(w, http.StatusInternalServerError, utils.SetDefaultResponse(req.Context(), utils.Message{Code: 500}))
 					logger.Log(logrus.ErrorLevel, "panic", err)
 					debug.PrintStack()
@@ -54,7 +58,7 @@ func Routing(c cfg.Config, db *bun.DB, log *log.MultiLogger, cache redis.Univers
 	//// Sử dụng gorilla/mux router để xử lý các route logistics
 	r := mux.NewRouter()
 	//// Kết hợp các handler từ logistics với các handler hiện tại
-	SetupLogisticsRoutes(r, db, log, c)
+	SetupLogisticsRoutes(r, db, log, c, cache)
 
 	return r
 }
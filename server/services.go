@@ -0,0 +1,175 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/quyenle-97/init/cfg"
+	"github.com/quyenle-97/init/internal/domain"
+	"github.com/quyenle-97/init/internal/eventstore"
+	"github.com/quyenle-97/init/internal/kit/services"
+	"github.com/quyenle-97/init/internal/projection"
+	"github.com/quyenle-97/init/internal/repository"
+	"github.com/quyenle-97/init/pkgs/cache"
+	"github.com/quyenle-97/init/pkgs/eventbus"
+	"github.com/quyenle-97/init/pkgs/geoip"
+	"github.com/redis/go-redis/v9"
+	"github.com/uptrace/bun"
+)
+
+// Services gom các thành phần logistics dùng chung giữa HTTP server và CLI
+type Services struct {
+	EventStore eventstore.EventStore
+	KeyStore   eventstore.KeyStore // nil khi dữ liệu cá nhân không được mã hóa
+	OrderRepo  repository.OrderRepository
+	Bus        eventbus.EventBus
+
+	Order    services.OrderService
+	Import   services.ImportService
+	Privacy  services.PrivacyService // nil khi dữ liệu cá nhân không được mã hóa
+	SLA      *services.SLAMonitor
+	Tracking services.TrackingService
+
+	Category services.CategoryService
+	City     services.CityService
+	Supplier services.SupplierService
+	Product  services.ProductService
+
+	Statistics services.StatisticsService
+	Payment    services.PaymentService
+	Fleet      services.FleetService
+	Location   services.LocationService
+	Route      services.RouteService
+	Hub        services.HubService
+}
+
+// NewServices khởi tạo event store, read model và các service theo cấu hình.
+// rdb là Redis dùng làm cache, nil khi không có Redis.
+func NewServices(db *bun.DB, c cfg.Config, rdb redis.UniversalClient) (*Services, error) {
+	// Khởi tạo event store với định dạng serialize theo cấu hình
+	serializers := eventstore.DefaultSerializers(eventstore.DefaultUpcasters())
+
+	// Mã hóa dữ liệu cá nhân trong sự kiện khi có cấu hình master key
+	masterKey, err := c.MasterKey()
+	if err != nil {
+		return nil, err
+	}
+	var keyStore eventstore.KeyStore
+	if masterKey != nil {
+		keyStore, err = eventstore.NewPostgresKeyStore(db, masterKey)
+		if err != nil {
+			return nil, err
+		}
+		serializers = eventstore.EncryptSerializers(serializers, keyStore)
+	}
+
+	eventStore, err := eventstore.NewPostgresEventStoreWithSerializers(db, c.EventFormat, serializers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Khởi tạo event bus
+	bus := eventbus.NewInMemoryEventBus()
+
+	// Khởi tạo order repository (kết hợp cả repository và projection)
+	orderRepo := repository.NewOrderRepository(db)
+	trackingProjection := projection.NewPostgresTrackingProjection(db)
+	analyticsProjection := projection.NewPostgresAnalyticsProjection(db)
+	reconciliationProjection := projection.NewPostgresReconciliationProjection(db)
+	hubInventoryProjection := projection.NewPostgresHubInventoryProjection(db)
+	locationProjection := projection.NewPostgresLocationProjection(db)
+
+	// Đăng ký các projections với event bus cho mọi loại sự kiện của đơn hàng đã đăng ký trong domain
+	orderEvents := domain.EventTypesOf(domain.AggregateOrder)
+	if err := bus.Subscribe(orderRepo, orderEvents...); err != nil {
+		return nil, fmt.Errorf("lỗi khi đăng ký projection: %w", err)
+	}
+	if err := bus.Subscribe(trackingProjection, orderEvents...); err != nil {
+		return nil, fmt.Errorf("lỗi khi đăng ký projection theo dõi: %w", err)
+	}
+	if err := bus.Subscribe(analyticsProjection, orderEvents...); err != nil {
+		return nil, fmt.Errorf("lỗi khi đăng ký projection thống kê: %w", err)
+	}
+	if err := bus.Subscribe(reconciliationProjection, domain.PaymentCollectedType, domain.PaymentRemittedType); err != nil {
+		return nil, fmt.Errorf("lỗi khi đăng ký projection đối soát: %w", err)
+	}
+	if err := bus.Subscribe(hubInventoryProjection, domain.ParcelScannedInType, domain.ParcelScannedOutType, domain.OrderStatusUpdatedType); err != nil {
+		return nil, fmt.Errorf("lỗi khi đăng ký projection tồn kho hub: %w", err)
+	}
+
+	// Khởi tạo service với clock hệ thống, bộ sinh ID, quy tắc tính ETA và bảng phí vận chuyển theo cấu hình
+	ids, err := domain.NewIDGenerator(c.IDGenerator, domain.SystemClock)
+	if err != nil {
+		return nil, err
+	}
+	etaRules := services.DefaultETARules()
+	if c.ETARulesFile != "" {
+		if etaRules, err = services.LoadETARules(c.ETARulesFile); err != nil {
+			return nil, err
+		}
+	}
+	pricingRules := services.DefaultPricingRules()
+	if c.PricingRulesFile != "" {
+		if pricingRules, err = services.LoadPricingRules(c.PricingRulesFile); err != nil {
+			return nil, err
+		}
+	}
+	pricing := services.NewPricingService(pricingRules)
+	orderOpts := []domain.OrderOption{
+		domain.WithClock(domain.SystemClock),
+		domain.WithIDGenerator(ids),
+		domain.WithDeliveryEstimator(services.NewETAService(etaRules)),
+		domain.WithCurrency(pricing.Currency()),
+		domain.WithShippingFeeCalculator(pricing),
+	}
+
+	// Tra cứu vị trí theo IP khi có cấu hình file GeoIP
+	var ips services.IPLocator
+	if c.GeoIPFile != "" {
+		reader, err := geoip.Open(c.GeoIPFile)
+		if err != nil {
+			return nil, err
+		}
+		ips = reader
+	}
+
+	// Cache tọa độ thành phố khi có Redis
+	var geoCache cache.Cache
+	if rdb != nil {
+		geoCache = cache.NewRedisCache(rdb)
+	}
+	cities := services.NewCityService(db, geoCache)
+	products := services.NewProductService(db, cities)
+
+	// Mặt hàng và điểm gửi được điền từ danh mục trước, sau đó mới bổ sung tọa độ còn thiếu
+	orders := services.NewGeoOrderService(services.NewOrderService(eventStore, orderRepo, bus, orderOpts...), ips)
+
+	s := &Services{
+		EventStore: eventStore,
+		KeyStore:   keyStore,
+		OrderRepo:  orderRepo,
+		Bus:        bus,
+		Order:      services.NewCatalogOrderService(orders, products),
+		Import:     services.NewImportService(eventStore, bus, orderOpts...),
+		SLA:        services.NewSLAMonitor(eventStore, orderRepo, bus, domain.SystemClock, orderOpts...),
+		Tracking:   services.NewTrackingService(eventStore, trackingProjection),
+		Category:   services.NewCategoryService(db),
+		City:       cities,
+		Supplier:   services.NewSupplierService(db),
+		Product:    products,
+		Statistics: services.NewStatisticsService(db, eventStore, analyticsProjection),
+		Payment:    services.NewPaymentService(eventStore, bus, reconciliationProjection, orderOpts...),
+		Fleet:      services.NewFleetService(eventStore, orderRepo, bus, orderOpts...),
+		Location: services.NewLocationService(eventStore, orderRepo, bus, locationProjection,
+			domain.SystemClock, services.DefaultLocationSampling(), orderOpts...),
+		Route: services.NewRouteService(eventStore, orderRepo, bus, locationProjection, orderOpts...),
+		Hub:   services.NewHubService(db, eventStore, bus, hubInventoryProjection, domain.SystemClock, orderOpts...),
+	}
+
+	// Xóa dữ liệu cá nhân chỉ khả dụng khi dữ liệu cá nhân được mã hóa
+	if keyStore != nil {
+		redactors := []projection.Redactor{trackingProjection, analyticsProjection, locationProjection}
+		s.Privacy = services.NewPrivacyService(eventStore, keyStore, orderRepo, redactors, orderOpts...)
+	}
+
+	return s, nil
+}